@@ -35,6 +35,7 @@ func main() {
 		Addr:    addr,
 		Handler: application.Router,
 	}
+	server.RegisterOnShutdown(application.CloseStreams)
 
 	go func() {
 		application.Logger.Info("server online",
@@ -3,13 +3,21 @@ package app
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/blob"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/config"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/events"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/handler"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/notify"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository/postgres"
 	routerpkg "github.com/edwaldo/test_blue_horn_tech/backend/internal/router"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/sweep"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
@@ -21,6 +29,13 @@ type Application struct {
 	Logger *zap.Logger
 	DB     *sqlx.DB
 	Router *gin.Engine
+
+	bus *events.Bus
+	// stopBackground ends the event listener, webhook dispatcher,
+	// notification worker and missed-dose sweep.
+	stopBackground context.CancelFunc
+	// closeNotifyOutput closes the file the local notification channel writes to.
+	closeNotifyOutput func() error
 }
 
 // New builds the application container.
@@ -40,30 +55,170 @@ func New(ctx context.Context, cfg config.Config) (*Application, error) {
 	authRepo := postgres.NewAuthRepository(database)
 	caregiverRepo := postgres.NewCaregiverRepository(database)
 	caregiverLogRepo := postgres.NewCaregiverLogRepository(database)
+	openShiftRepo := postgres.NewOpenShiftRepository(database)
+	assignmentRepo := postgres.NewAssignmentRepository(database)
+	evvRepo := postgres.NewEVVRepository(database)
+	correctionRepo := postgres.NewVisitCorrectionRepository(database)
+	timesheetRepo := postgres.NewTimesheetRepository(database)
+	mileageRepo := postgres.NewMileageRepository(database)
+	incidentRepo := postgres.NewIncidentRepository(database)
+	dashboardRepo := postgres.NewDashboardRepository(database)
+	webhookRepo := postgres.NewWebhookRepository(database)
+	notificationRepo := postgres.NewNotificationRepository(database)
+	familyRepo := postgres.NewFamilyRepository(database)
+	signoffRepo := postgres.NewSignoffRepository(database)
+	attachmentRepo := postgres.NewAttachmentRepository(database)
+	medicationRepo := postgres.NewMedicationRepository(database)
+	observationRepo := postgres.NewObservationRepository(database)
+
+	bus := events.NewBus()
+	var publisher usecase.EventPublisher = bus
+	background, stopBackground := context.WithCancel(context.Background())
+	if cfg.Events.PostgresNotify {
+		fanout := events.NewPostgresFanout(database, bus, cfg.Events.Channel, log)
+		if err := fanout.Listen(background, cfg.Database.DSN()); err != nil {
+			stopBackground()
+			_ = database.Close()
+			return nil, fmt.Errorf("listen for events: %w", err)
+		}
+		publisher = fanout
+	}
 
-	scheduleUC := usecase.NewScheduleUsecase(schedRepo, taskRepo)
+	zones := usecase.NewTimezoneResolver(caregiverRepo, cfg.Timezone)
+	scheduleUC := usecase.NewScheduleUsecase(schedRepo, taskRepo, zones)
+	scheduleUC.WithOvernightRule(domain.OvernightRule(cfg.OvernightRule))
+	scheduleUC.WithOnTimeGrace(cfg.OnTimeGrace)
+	if cfg.RequireShiftForVisits {
+		scheduleUC.WithShiftRequirement(caregiverLogRepo)
+	}
+	scheduleUC.WithEvents(publisher)
+	scheduleUC.WithSignoffs(signoffRepo)
+	medicationUC := usecase.NewMedicationUsecase(medicationRepo, schedRepo, cfg.Timezone)
+	scheduleUC.WithMedications(medicationUC)
+	observationUC := usecase.NewObservationUsecase(observationRepo, schedRepo, cfg.Timezone)
 	taskUC := usecase.NewTaskUsecase(taskRepo, schedRepo)
+	taskUC.WithEvents(publisher)
 	authUC := usecase.NewAuthUsecase(cfg.Auth, authRepo, caregiverRepo)
-	attendanceUC := usecase.NewCaregiverAttendanceUsecase(caregiverLogRepo)
+	authUC.WithFamilyMembers(familyRepo)
+	attendanceUC := usecase.NewCaregiverAttendanceUsecase(caregiverLogRepo, schedRepo, zones)
+	attendanceUC.WithOvernightRule(domain.OvernightRule(cfg.OvernightRule))
+	attendanceUC.WithBreakRequiredAfter(cfg.BreakRequiredAfter)
+	attendanceUC.WithEvents(publisher)
+	openShiftUC := usecase.NewOpenShiftUsecase(openShiftRepo, caregiverRepo)
+	assignmentUC := usecase.NewAssignmentUsecase(assignmentRepo, openShiftRepo, cfg.Timezone)
+	assignmentUC.WithEvents(publisher)
+	evvUC := usecase.NewEVVUsecase(evvRepo, cfg.Timezone)
+	correctionUC := usecase.NewVisitCorrectionUsecase(correctionRepo, schedRepo)
+	correctionUC.WithTimesheetLocks(timesheetRepo)
+	timesheetUC := usecase.NewTimesheetUsecase(timesheetRepo, caregiverLogRepo, schedRepo, zones, usecase.TimesheetPolicy{
+		Period: domain.TimesheetPeriod(cfg.Timesheet.Period),
+		Anchor: cfg.Timesheet.Anchor,
+		Overtime: domain.OvertimeRules{
+			Daily:  cfg.Timesheet.DailyOvertime,
+			Weekly: cfg.Timesheet.WeeklyOvertime,
+		},
+		Overnight: domain.OvernightRule(cfg.OvernightRule),
+	})
+	timesheetUC.WithMileage(mileageRepo)
+	mileageUC := usecase.NewMileageUsecase(mileageRepo, zones, cfg.MileageRatePerKm)
+	mileageUC.WithTimesheetLocks(timesheetRepo)
+	incidentUC := usecase.NewIncidentUsecase(incidentRepo, schedRepo)
+	dashboardUC := usecase.NewDashboardUsecase(dashboardRepo, incidentRepo, cfg.Timezone)
+	webhookUC := usecase.NewWebhookUsecase(webhookRepo)
+	familyUC := usecase.NewFamilyUsecase(familyRepo)
+	signoffUC := usecase.NewSignoffUsecase(signoffRepo)
+	blobStore, err := openBlobStore(cfg.Attachments)
+	if err != nil {
+		stopBackground()
+		_ = database.Close()
+		return nil, fmt.Errorf("open attachment store: %w", err)
+	}
+	attachmentUC := usecase.NewAttachmentUsecase(attachmentRepo, schedRepo, taskRepo, blobStore, usecase.AttachmentPolicy{
+		MaxBytes:  cfg.Attachments.MaxBytes,
+		URLTTL:    cfg.Attachments.URLTTL,
+		URLSecret: cfg.Attachments.URLSecret,
+		BaseURL:   cfg.Attachments.BaseURL,
+	})
+	notificationUC := usecase.NewNotificationUsecase(notificationRepo, zones, usecase.NotificationPolicy{
+		ReminderLead:  cfg.Notify.ReminderLead,
+		ClockOutGrace: cfg.Notify.ClockOutGrace,
+	})
+	// Until real providers are configured every channel goes to the local
+	// writer, so messages can be inspected during development.
+	localSender, closeNotifyOutput, err := notify.OpenWriterSender(cfg.Notify.Output)
+	if err != nil {
+		stopBackground()
+		_ = database.Close()
+		return nil, fmt.Errorf("open notification output: %w", err)
+	}
+	for _, channel := range domain.NotificationChannels {
+		notificationUC.WithSender(channel, localSender)
+	}
 
 	authHandler := handler.NewAuthHandler(authUC)
 	scheduleHandler := handler.NewScheduleHandler(scheduleUC)
 	taskHandler := handler.NewTaskHandler(taskUC, scheduleUC)
 	attendanceHandler := handler.NewCaregiverAttendanceHandler(attendanceUC)
+	openShiftHandler := handler.NewOpenShiftHandler(openShiftUC)
+	assignmentHandler := handler.NewAssignmentHandler(assignmentUC)
+	evvHandler := handler.NewEVVHandler(evvUC)
+	correctionHandler := handler.NewVisitCorrectionHandler(correctionUC)
+	timesheetHandler := handler.NewTimesheetHandler(timesheetUC)
+	mileageHandler := handler.NewMileageHandler(mileageUC)
+	incidentHandler := handler.NewIncidentHandler(incidentUC)
+	dashboardHandler := handler.NewDashboardHandler(dashboardUC)
+	eventsHandler := handler.NewEventsHandler(bus, cfg.Events.Heartbeat)
+	webhookHandler := handler.NewWebhookHandler(webhookUC)
+	notificationHandler := handler.NewNotificationHandler(notificationUC)
+	familyHandler := handler.NewFamilyHandler(authUC, familyUC)
+	signoffHandler := handler.NewSignoffHandler(signoffUC)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentUC)
+	medicationHandler := handler.NewMedicationHandler(medicationUC)
+	observationHandler := handler.NewObservationHandler(observationUC)
 	docsHandler := handler.NewDocsHandler()
 
-	router := routerpkg.NewRouter(log, authUC, authHandler, scheduleHandler, taskHandler, attendanceHandler, docsHandler, cfg.CORS)
+	if cfg.Webhooks.DispatchEnabled {
+		dispatcher := webhook.NewDispatcher(webhookRepo, webhook.Config{
+			Interval:    cfg.Webhooks.Interval,
+			Timeout:     cfg.Webhooks.Timeout,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			BackoffBase: cfg.Webhooks.BackoffBase,
+			BackoffMax:  cfg.Webhooks.BackoffMax,
+		}, log)
+		go dispatcher.Run(background)
+	}
+	if cfg.Notify.Enabled {
+		worker := notify.NewWorker(notificationUC, bus, cfg.Notify.ScanInterval, log)
+		go worker.Run(background)
+	}
+	if cfg.Medications.SweepEnabled {
+		marSweep := sweep.NewRunner("flag missed doses", medicationUC.FlagMissedDoses, cfg.Medications.SweepInterval, log)
+		go marSweep.Run(background)
+	}
+
+	router := routerpkg.NewRouter(log, authUC, authHandler, scheduleHandler, taskHandler, attendanceHandler, openShiftHandler, assignmentHandler, evvHandler, correctionHandler, timesheetHandler, mileageHandler, incidentHandler, dashboardHandler, eventsHandler, webhookHandler, notificationHandler, familyHandler, signoffHandler, attachmentHandler, medicationHandler, observationHandler, docsHandler, cfg.CORS)
 
 	return &Application{
 		Config: cfg,
 		Logger: log,
 		DB:     database,
 		Router: router,
+
+		bus:               bus,
+		stopBackground:    stopBackground,
+		closeNotifyOutput: closeNotifyOutput,
 	}, nil
 }
 
+// CloseStreams ends open event streams so the HTTP server can drain.
+func (a *Application) CloseStreams() {
+	a.bus.Close()
+}
+
 // Shutdown flushes resources cleanly.
 func (a *Application) Shutdown(ctx context.Context) error {
+	a.stopBackground()
+	_ = a.closeNotifyOutput()
 	if err := a.DB.Close(); err != nil {
 		return err
 	}
@@ -72,3 +227,18 @@ func (a *Application) Shutdown(ctx context.Context) error {
 	_ = a.Logger.Sync()
 	return nil
 }
+
+// openBlobStore returns the attachment store cfg selects.
+func openBlobStore(cfg config.AttachmentConfig) (usecase.BlobStore, error) {
+	if cfg.Store == "s3" {
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		}, &http.Client{Timeout: time.Minute})
+	}
+	return blob.NewLocalStore(cfg.Dir)
+}
@@ -14,13 +14,31 @@ import (
 
 // Config aggregates environment backed configuration for the API.
 type Config struct {
-	App       AppConfig
-	Database  DatabaseConfig
-	Auth      AuthConfig
-	Logging   LoggingConfig
-	CORS      CORSConfig
-	Timezone  *time.Location
-	StartTime time.Time
+	App         AppConfig
+	Database    DatabaseConfig
+	Auth        AuthConfig
+	Logging     LoggingConfig
+	CORS        CORSConfig
+	Timezone    *time.Location
+	StartTime   time.Time
+	Timesheet   TimesheetConfig
+	Events      EventsConfig
+	Webhooks    WebhookConfig
+	Notify      NotificationConfig
+	Attachments AttachmentConfig
+	Medications MedicationConfig
+
+	// OvernightRule is "split" or "start_day"; see domain.OvernightRule.
+	OvernightRule string
+	// BreakRequiredAfter is the longest a caregiver may work without a break.
+	BreakRequiredAfter time.Duration
+	// RequireShiftForVisits only lets visits start during an open attendance shift.
+	RequireShiftForVisits bool
+	// MileageRatePerKm is the reimbursement paid per claimed kilometre.
+	MileageRatePerKm float64
+	// OnTimeGrace is how late past its flexible window a visit may be clocked
+	// in and still count as an on-time arrival in metrics.
+	OnTimeGrace time.Duration
 }
 
 type AppConfig struct {
@@ -52,6 +70,92 @@ type AuthConfig struct {
 	AccessTokenTTL     time.Duration
 	IDTokenTTL         time.Duration
 	DefaultCaregiverID string
+	// FamilyAudience is the audience of family portal tokens, which are
+	// refused everywhere else.
+	FamilyAudience string
+	FamilyTokenTTL time.Duration
+}
+
+// TimesheetConfig describes pay periods and overtime thresholds.
+type TimesheetConfig struct {
+	// Period is "weekly" or "biweekly".
+	Period string
+	// Anchor is a date on which a pay period starts.
+	Anchor time.Time
+	// DailyOvertime and WeeklyOvertime are regular-time limits; zero disables a rule.
+	DailyOvertime  time.Duration
+	WeeklyOvertime time.Duration
+}
+
+// EventsConfig controls the real-time event stream.
+type EventsConfig struct {
+	// PostgresNotify fans events out to every replica through LISTEN/NOTIFY
+	// instead of delivering them only within this process.
+	PostgresNotify bool
+	// Channel is the Postgres notification channel.
+	Channel string
+	// Heartbeat is how often idle streams receive a keep-alive comment.
+	Heartbeat time.Duration
+}
+
+// WebhookConfig controls delivery of outbox events to webhook subscribers.
+type WebhookConfig struct {
+	// DispatchEnabled runs the dispatcher in this process.
+	DispatchEnabled bool
+	Interval        time.Duration
+	Timeout         time.Duration
+	// MaxAttempts is how many times a delivery is tried before it is moved
+	// to the dead-letter queue.
+	MaxAttempts int
+	// BackoffBase is the first retry delay; it doubles per attempt up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// NotificationConfig controls caregiver reminders and alerts.
+type NotificationConfig struct {
+	// Enabled runs the notification worker in this process.
+	Enabled bool
+	// ScanInterval is how often visits are checked for due reminders.
+	ScanInterval time.Duration
+	// ReminderLead is how long before a visit starts its reminder is sent.
+	ReminderLead time.Duration
+	// ClockOutGrace is how long after a visit's end a missing clock-out is nudged.
+	ClockOutGrace time.Duration
+	// Output is where the local channel writes messages: "stdout" or a file path.
+	Output string
+}
+
+// MedicationConfig controls the missed-dose sweep of medication
+// administration records.
+type MedicationConfig struct {
+	// SweepEnabled runs the missed-dose sweep in this process.
+	SweepEnabled bool
+	// SweepInterval is how often doses still due on visits that are over are
+	// flagged missed.
+	SweepInterval time.Duration
+}
+
+// AttachmentConfig controls where visit attachments are stored and how they
+// are downloaded.
+type AttachmentConfig struct {
+	// Store is "local" or "s3".
+	Store string
+	// Dir is where the local store keeps files.
+	Dir      string
+	MaxBytes int64
+	// URLTTL is how long a signed download link stays valid.
+	URLTTL    time.Duration
+	URLSecret string
+	// BaseURL prefixes download links; empty leaves them relative.
+	BaseURL string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
 }
 
 type LoggingConfig struct {
@@ -104,12 +208,144 @@ func Load() (Config, error) {
 		return Config{}, err
 	}
 
+	familyTTL, err := getDuration("AUTH_FAMILY_TOKEN_TTL", "60m")
+	if err != nil {
+		return Config{}, err
+	}
+
 	locationName := getString("APP_TIMEZONE", "UTC")
 	loc, err := time.LoadLocation(locationName)
 	if err != nil {
 		return Config{}, fmt.Errorf("invalid APP_TIMEZONE %q: %w", locationName, err)
 	}
 
+	breakAfter, err := getDuration("ATTENDANCE_BREAK_REQUIRED_AFTER", "6h")
+	if err != nil {
+		return Config{}, err
+	}
+
+	overnightRule := getString("ATTENDANCE_OVERNIGHT_RULE", "split")
+	if overnightRule != "split" && overnightRule != "start_day" {
+		return Config{}, fmt.Errorf("invalid ATTENDANCE_OVERNIGHT_RULE %q: want split or start_day", overnightRule)
+	}
+
+	timesheetPeriod := getString("TIMESHEET_PERIOD", "weekly")
+	if timesheetPeriod != "weekly" && timesheetPeriod != "biweekly" {
+		return Config{}, fmt.Errorf("invalid TIMESHEET_PERIOD %q: want weekly or biweekly", timesheetPeriod)
+	}
+
+	anchorValue := getString("TIMESHEET_PERIOD_ANCHOR", "2025-01-06")
+	anchor, err := time.Parse("2006-01-02", anchorValue)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid TIMESHEET_PERIOD_ANCHOR %q: %w", anchorValue, err)
+	}
+
+	dailyOvertime, err := getDuration("TIMESHEET_OVERTIME_DAILY", "0s")
+	if err != nil {
+		return Config{}, err
+	}
+
+	weeklyOvertime, err := getDuration("TIMESHEET_OVERTIME_WEEKLY", "40h")
+	if err != nil {
+		return Config{}, err
+	}
+
+	mileageRate, err := getFloat("MILEAGE_RATE_PER_KM", 0.40)
+	if err != nil {
+		return Config{}, err
+	}
+
+	onTimeGrace, err := getDuration("METRICS_ON_TIME_GRACE", "5m")
+	if err != nil {
+		return Config{}, err
+	}
+
+	eventsHeartbeat, err := getDuration("EVENTS_HEARTBEAT_INTERVAL", "15s")
+	if err != nil {
+		return Config{}, err
+	}
+	if eventsHeartbeat <= 0 {
+		return Config{}, fmt.Errorf("invalid EVENTS_HEARTBEAT_INTERVAL %s: want a positive duration", eventsHeartbeat)
+	}
+
+	webhookInterval, err := getDuration("WEBHOOK_DISPATCH_INTERVAL", "5s")
+	if err != nil {
+		return Config{}, err
+	}
+	if webhookInterval <= 0 {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_DISPATCH_INTERVAL %s: want a positive duration", webhookInterval)
+	}
+
+	webhookTimeout, err := getDuration("WEBHOOK_TIMEOUT", "10s")
+	if err != nil {
+		return Config{}, err
+	}
+
+	webhookAttempts, err := getInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return Config{}, err
+	}
+	if webhookAttempts < 1 {
+		return Config{}, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %d: want at least 1", webhookAttempts)
+	}
+
+	webhookBackoffBase, err := getDuration("WEBHOOK_BACKOFF_BASE", "30s")
+	if err != nil {
+		return Config{}, err
+	}
+
+	webhookBackoffMax, err := getDuration("WEBHOOK_BACKOFF_MAX", "6h")
+	if err != nil {
+		return Config{}, err
+	}
+
+	notifyScan, err := getDuration("NOTIFY_SCAN_INTERVAL", "1m")
+	if err != nil {
+		return Config{}, err
+	}
+	if notifyScan <= 0 {
+		return Config{}, fmt.Errorf("invalid NOTIFY_SCAN_INTERVAL %s: want a positive duration", notifyScan)
+	}
+
+	notifyLead, err := getDuration("NOTIFY_VISIT_REMINDER_LEAD", "30m")
+	if err != nil {
+		return Config{}, err
+	}
+
+	notifyGrace, err := getDuration("NOTIFY_CLOCK_OUT_GRACE", "15m")
+	if err != nil {
+		return Config{}, err
+	}
+
+	marSweep, err := getDuration("MAR_SWEEP_INTERVAL", "5m")
+	if err != nil {
+		return Config{}, err
+	}
+	if marSweep <= 0 {
+		return Config{}, fmt.Errorf("invalid MAR_SWEEP_INTERVAL %s: want a positive duration", marSweep)
+	}
+
+	attachmentStore := getString("ATTACHMENT_STORE", "local")
+	if attachmentStore != "local" && attachmentStore != "s3" {
+		return Config{}, fmt.Errorf("invalid ATTACHMENT_STORE %q: want local or s3", attachmentStore)
+	}
+
+	attachmentMax, err := getInt("ATTACHMENT_MAX_BYTES", 10<<20)
+	if err != nil {
+		return Config{}, err
+	}
+	if attachmentMax < 1 {
+		return Config{}, fmt.Errorf("invalid ATTACHMENT_MAX_BYTES %d: want a positive size", attachmentMax)
+	}
+
+	attachmentTTL, err := getDuration("ATTACHMENT_URL_TTL", "15m")
+	if err != nil {
+		return Config{}, err
+	}
+	if attachmentTTL <= 0 {
+		return Config{}, fmt.Errorf("invalid ATTACHMENT_URL_TTL %s: want a positive duration", attachmentTTL)
+	}
+
 	cfg := Config{
 		App: AppConfig{
 			Name: getString("APP_NAME", "care-shift-tracker"),
@@ -138,6 +374,8 @@ func Load() (Config, error) {
 			AccessTokenTTL:     accessTTL,
 			IDTokenTTL:         idTTL,
 			DefaultCaregiverID: getString("AUTH_DEFAULT_CAREGIVER_ID", ""),
+			FamilyAudience:     getString("AUTH_FAMILY_AUDIENCE", "family-portal"),
+			FamilyTokenTTL:     familyTTL,
 		},
 		Logging: LoggingConfig{
 			Level: getString("LOG_LEVEL", "info"),
@@ -150,8 +388,58 @@ func Load() (Config, error) {
 			AllowCredentials: getBool("CORS_ALLOW_CREDENTIALS", false),
 			MaxAgeSeconds:    getIntOrFallback("CORS_MAX_AGE_SECONDS", 43200),
 		},
-		Timezone:  loc,
-		StartTime: time.Now(),
+		Timesheet: TimesheetConfig{
+			Period:         timesheetPeriod,
+			Anchor:         anchor,
+			DailyOvertime:  dailyOvertime,
+			WeeklyOvertime: weeklyOvertime,
+		},
+		Events: EventsConfig{
+			PostgresNotify: getBool("EVENTS_POSTGRES_NOTIFY", false),
+			Channel:        getString("EVENTS_NOTIFY_CHANNEL", "care_events"),
+			Heartbeat:      eventsHeartbeat,
+		},
+		Webhooks: WebhookConfig{
+			DispatchEnabled: getBool("WEBHOOK_DISPATCH_ENABLED", true),
+			Interval:        webhookInterval,
+			Timeout:         webhookTimeout,
+			MaxAttempts:     webhookAttempts,
+			BackoffBase:     webhookBackoffBase,
+			BackoffMax:      webhookBackoffMax,
+		},
+		Notify: NotificationConfig{
+			Enabled:       getBool("NOTIFY_ENABLED", true),
+			ScanInterval:  notifyScan,
+			ReminderLead:  notifyLead,
+			ClockOutGrace: notifyGrace,
+			Output:        getString("NOTIFY_OUTPUT", "stdout"),
+		},
+		Attachments: AttachmentConfig{
+			Store:             attachmentStore,
+			Dir:               getString("ATTACHMENT_DIR", "data/attachments"),
+			MaxBytes:          int64(attachmentMax),
+			URLTTL:            attachmentTTL,
+			URLSecret:         getString("ATTACHMENT_URL_SECRET", "attachment-url-secret-placeholder"),
+			BaseURL:           getString("ATTACHMENT_BASE_URL", ""),
+			S3Endpoint:        getString("ATTACHMENT_S3_ENDPOINT", ""),
+			S3Region:          getString("ATTACHMENT_S3_REGION", "us-east-1"),
+			S3Bucket:          getString("ATTACHMENT_S3_BUCKET", ""),
+			S3AccessKeyID:     getString("ATTACHMENT_S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getString("ATTACHMENT_S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:       getBool("ATTACHMENT_S3_PATH_STYLE", true),
+		},
+		Medications: MedicationConfig{
+			SweepEnabled:  getBool("MAR_SWEEP_ENABLED", true),
+			SweepInterval: marSweep,
+		},
+		Timezone:      loc,
+		OvernightRule: overnightRule,
+		StartTime:     time.Now(),
+
+		BreakRequiredAfter:    breakAfter,
+		RequireShiftForVisits: getBool("ATTENDANCE_REQUIRE_SHIFT_FOR_VISITS", false),
+		MileageRatePerKm:      mileageRate,
+		OnTimeGrace:           onTimeGrace,
 	}
 
 	return cfg, nil
@@ -175,6 +463,17 @@ func getInt(key string, fallback int) (int, error) {
 	return fallback, nil
 }
 
+func getFloat(key string, fallback float64) (float64, error) {
+	if val, ok := os.LookupEnv(key); ok && val != "" {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		return parsed, nil
+	}
+	return fallback, nil
+}
+
 func getIntOrFallback(key string, fallback int) int {
 	val, err := getInt(key, fallback)
 	if err != nil {
@@ -215,9 +514,9 @@ func splitAndTrim(value string) []string {
 	return result
 }
 
-// NewPostgres connects to PostgreSQL using the provided configuration.
-func NewPostgres(ctx context.Context, cfg DatabaseConfig) (*sqlx.DB, error) {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
+// DSN returns the connection URL for the database.
+func (cfg DatabaseConfig) DSN() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.User,
 		cfg.Password,
 		cfg.Host,
@@ -225,8 +524,11 @@ func NewPostgres(ctx context.Context, cfg DatabaseConfig) (*sqlx.DB, error) {
 		cfg.Name,
 		cfg.SSLMode,
 	)
+}
 
-	db, err := sqlx.ConnectContext(ctx, "pgx", dsn)
+// NewPostgres connects to PostgreSQL using the provided configuration.
+func NewPostgres(ctx context.Context, cfg DatabaseConfig) (*sqlx.DB, error) {
+	db, err := sqlx.ConnectContext(ctx, "pgx", cfg.DSN())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,79 @@
+package domain
+
+import "time"
+
+// AssignmentProposalStatus tracks whether a proposal has been applied.
+type AssignmentProposalStatus string
+
+const (
+	AssignmentProposalDraft   AssignmentProposalStatus = "draft"
+	AssignmentProposalApplied AssignmentProposalStatus = "applied"
+)
+
+// CaregiverAvailability is a recurring weekly window in which a caregiver can work.
+// Minutes are counted from local midnight.
+type CaregiverAvailability struct {
+	CaregiverID string
+	Weekday     time.Weekday
+	StartMinute int
+	EndMinute   int
+}
+
+// Covers reports whether the window contains the local interval [start, end).
+func (a CaregiverAvailability) Covers(start, end time.Time) bool {
+	if start.Weekday() != a.Weekday {
+		return false
+	}
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := startMin + int(end.Sub(start).Minutes())
+	return startMin >= a.StartMinute && endMin <= a.EndMinute
+}
+
+// CommittedVisit is a visit already assigned to a caregiver.
+type CommittedVisit struct {
+	ScheduleID  string
+	CaregiverID string
+	ClientID    string
+	StartTime   time.Time
+	EndTime     time.Time
+	Latitude    float64
+	Longitude   float64
+}
+
+// ClientContinuity counts previous visits a caregiver made to a client.
+type ClientContinuity struct {
+	ClientID    string
+	CaregiverID string
+	Visits      int
+}
+
+// ProposedAssignment pairs an open visit with the chosen caregiver and the reasoning behind it.
+type ProposedAssignment struct {
+	ScheduleID    string
+	CaregiverID   string
+	CaregiverName string
+	StartTime     time.Time
+	Score         float64
+	TravelKm      float64
+	Reasons       []string
+}
+
+// UnassignedVisit records why no caregiver could be proposed for a visit.
+type UnassignedVisit struct {
+	ScheduleID string
+	StartTime  time.Time
+	Reasons    []string
+}
+
+// AssignmentProposal is an explainable set of assignments for a date range.
+type AssignmentProposal struct {
+	ID          string
+	From        time.Time
+	To          time.Time
+	Status      AssignmentProposalStatus
+	Assignments []ProposedAssignment
+	Unassigned  []UnassignedVisit
+	CreatedBy   string
+	CreatedAt   time.Time
+	AppliedAt   *time.Time
+}
@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// AttachmentVariant selects the stored file served for an attachment.
+type AttachmentVariant string
+
+const (
+	AttachmentOriginal  AttachmentVariant = "original"
+	AttachmentThumbnail AttachmentVariant = "thumbnail"
+)
+
+// Valid reports whether v is a known variant.
+func (v AttachmentVariant) Valid() bool {
+	return v == AttachmentOriginal || v == AttachmentThumbnail
+}
+
+// AttachmentContentTypes are the file types caregivers may upload, detected
+// from the file's content rather than its name.
+var AttachmentContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+// Attachment is a photo or document a caregiver attached to a visit, or to one
+// of its tasks.
+type Attachment struct {
+	ID         string
+	ScheduleID string
+	// TaskID is set when the file belongs to a task of the visit.
+	TaskID      *string
+	UploadedBy  string
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	// SHA256 is the hex digest of the stored file, after metadata was removed.
+	SHA256     string
+	StorageKey string
+	// ThumbnailKey is set for images.
+	ThumbnailKey *string
+	Caption      *string
+	CreatedAt    time.Time
+}
+
+// Key returns where variant is stored, or "" when the attachment has none.
+func (a Attachment) Key(variant AttachmentVariant) string {
+	switch variant {
+	case AttachmentOriginal:
+		return a.StorageKey
+	case AttachmentThumbnail:
+		if a.ThumbnailKey != nil {
+			return *a.ThumbnailKey
+		}
+	}
+	return ""
+}
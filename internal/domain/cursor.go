@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor is a keyset position in a list ordered by (Rank, Time, ID). Clients
+// receive it as an opaque token and pass it back to continue from the same
+// place, so rows inserted meanwhile neither repeat nor shift the next page.
+type Cursor struct {
+	// Rank is a leading sort key for lists grouped before they are ordered by
+	// time, such as schedules by status; it is zero otherwise.
+	Rank int
+	Time time.Time
+	ID   string
+}
+
+// Encode returns the opaque token for the cursor.
+func (c Cursor) Encode() string {
+	raw := strconv.Itoa(c.Rank) + "|" + c.Time.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// cursorID matches the UUID row ids cursors carry, so a forged token cannot
+// reach a UUID column as an invalid value.
+var cursorID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ParseCursor decodes a token produced by Cursor.Encode.
+func ParseCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrValidationFailure
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 || !cursorID.MatchString(parts[2]) {
+		return Cursor{}, ErrValidationFailure
+	}
+	rank, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Cursor{}, ErrValidationFailure
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return Cursor{}, ErrValidationFailure
+	}
+	return Cursor{Rank: rank, Time: t, ID: parts[2]}, nil
+}
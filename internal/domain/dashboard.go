@@ -0,0 +1,102 @@
+package domain
+
+import "time"
+
+// DashboardVisitState selects the visits a supervisor dashboard list shows.
+type DashboardVisitState string
+
+const (
+	// DashboardVisitLive lists visits in progress.
+	DashboardVisitLive DashboardVisitState = "live"
+	// DashboardVisitUnstarted lists scheduled visits without a clock-in that
+	// have not ended yet, late ones included.
+	DashboardVisitUnstarted DashboardVisitState = "unstarted"
+	// DashboardVisitMissed lists visits marked missed and scheduled visits
+	// that ended without a clock-in.
+	DashboardVisitMissed DashboardVisitState = "missed"
+)
+
+// Valid reports whether the state is known.
+func (s DashboardVisitState) Valid() bool {
+	switch s {
+	case DashboardVisitLive, DashboardVisitUnstarted, DashboardVisitMissed:
+		return true
+	}
+	return false
+}
+
+// DashboardVisit is a visit on the agency-wide dashboard.
+type DashboardVisit struct {
+	ScheduleID    string
+	CaregiverID   string
+	CaregiverName string
+	Region        *string
+	Team          *string
+	ClientID      string
+	ClientName    string
+	ServiceName   string
+	StartTime     time.Time
+	EndTime       time.Time
+	Status        ScheduleStatus
+	ClockInAt     *time.Time
+	// Late is set on unclocked visits whose start and flexible window have passed.
+	Late bool
+}
+
+// AttendanceState is where a caregiver is in their attendance shift.
+type AttendanceState string
+
+const (
+	AttendanceStateOff     AttendanceState = "off_shift"
+	AttendanceStateOnShift AttendanceState = "on_shift"
+	AttendanceStateOnBreak AttendanceState = "on_break"
+)
+
+// AttendanceStateAfter returns the state a caregiver is in after their last
+// attendance log ("" when there is none).
+func AttendanceStateAfter(last LogType) AttendanceState {
+	switch last {
+	case LogTypeClockIn, LogTypeBreakEnd:
+		return AttendanceStateOnShift
+	case LogTypeBreakStart:
+		return AttendanceStateOnBreak
+	default:
+		return AttendanceStateOff
+	}
+}
+
+// CaregiverDayStatus summarises a caregiver's day for supervisors.
+type CaregiverDayStatus struct {
+	CaregiverID string
+	Name        string
+	Region      *string
+	Team        *string
+	Attendance  AttendanceState
+	// LastEventAt is the time of the caregiver's latest attendance log.
+	LastEventAt *time.Time
+	// CurrentVisitID is the visit in progress, if any.
+	CurrentVisitID *string
+	// NextVisitAt is the start of the next unclocked visit of the day.
+	NextVisitAt   *time.Time
+	Visits        int
+	Completed     int
+	Missed        int
+	OpenIncidents int
+}
+
+// DashboardSummary counts the day's visits, open incidents and caregivers on
+// shift across the agency. Upcoming and Late split the unstarted visits, and
+// Missed counts the same visits as DashboardVisitMissed.
+type DashboardSummary struct {
+	Date              time.Time
+	Visits            int
+	InProgress        int
+	Upcoming          int
+	Late              int
+	Missed            int
+	Completed         int
+	Cancelled         int
+	OpenIncidents     int
+	CaregiversOnShift int
+	CaregiversOnBreak int
+}
@@ -17,4 +17,21 @@ var (
 
 	// ErrValidationFailure indicates invalid input payload.
 	ErrValidationFailure = errors.New("validation failure")
+
+	// ErrConflict indicates the resource was changed by a concurrent request.
+	ErrConflict = errors.New("conflict")
+
+	// ErrSignoffRequired indicates the client must acknowledge the visit at clock-out.
+	ErrSignoffRequired = errors.New("client signoff required")
+
+	// ErrInvalidScope indicates a token was requested with a scope its client
+	// is not registered for.
+	ErrInvalidScope = errors.New("invalid_scope")
+
+	// ErrSignoffPINLocked indicates too many wrong PINs were entered for the
+	// client since its PIN was set.
+	ErrSignoffPINLocked = errors.New("client signoff pin locked")
+
+	// ErrRecordOnMAR indicates a medication task is recorded on the MAR, not as a task.
+	ErrRecordOnMAR = errors.New("record this medication on the MAR")
 )
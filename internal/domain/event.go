@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// EventType names a change pushed to real-time subscribers.
+type EventType string
+
+const (
+	// EventScheduleStatusChanged follows a visit moving to another status.
+	EventScheduleStatusChanged EventType = "schedule.status_changed"
+	// EventTaskCreated and EventTaskUpdated follow changes to a visit's tasks.
+	EventTaskCreated EventType = "task.created"
+	EventTaskUpdated EventType = "task.updated"
+	// EventAttendanceLogged follows a clock-in, clock-out or break log.
+	EventAttendanceLogged EventType = "attendance.logged"
+	// EventScheduleAssigned follows a visit being given to a caregiver by a
+	// coordinator; CaregiverID is the caregiver now responsible for it.
+	EventScheduleAssigned EventType = "schedule.assigned"
+	// EventIncidentReported and EventIncidentResolved follow an incident's
+	// lifecycle; they are delivered to webhooks only.
+	EventIncidentReported EventType = "incident.reported"
+	EventIncidentResolved EventType = "incident.resolved"
+	// EventMedicationMissed follows a scheduled dose being flagged as missed;
+	// it is delivered to webhooks only.
+	EventMedicationMissed EventType = "medication.missed"
+	// EventObservationOutOfRange follows a reading outside its normal range;
+	// it is delivered to webhooks only.
+	EventObservationOutOfRange EventType = "observation.out_of_range"
+)
+
+// Event is a change that has happened, published to real-time subscribers.
+// CaregiverID is the caregiver the change belongs to and scopes who may see it.
+type Event struct {
+	ID          string                 `json:"id"`
+	Type        EventType              `json:"type"`
+	CaregiverID string                 `json:"caregiver_id"`
+	ScheduleID  string                 `json:"schedule_id,omitempty"`
+	OccurredAt  time.Time              `json:"occurred_at"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+}
+
+// Valid reports whether t is a known real-time event type.
+func (t EventType) Valid() bool {
+	switch t {
+	case EventScheduleStatusChanged, EventTaskCreated, EventTaskUpdated, EventAttendanceLogged, EventScheduleAssigned:
+		return true
+	}
+	return false
+}
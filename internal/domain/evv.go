@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EVVFormat is the file format of an EVV export batch.
+type EVVFormat string
+
+const (
+	EVVFormatCSV  EVVFormat = "csv"
+	EVVFormatJSON EVVFormat = "json"
+)
+
+// EVVAction tells the aggregator whether a record is new or replaces an earlier submission.
+type EVVAction string
+
+const (
+	EVVActionCreate EVVAction = "create"
+	EVVActionUpdate EVVAction = "update"
+)
+
+// EVVVisitStatus describes where a visit stands in the submission lifecycle.
+type EVVVisitStatus string
+
+const (
+	EVVVisitUnsubmitted EVVVisitStatus = "unsubmitted"
+	EVVVisitSubmitted   EVVVisitStatus = "submitted"
+	// EVVVisitCorrected marks a submitted visit whose data changed since submission.
+	EVVVisitCorrected EVVVisitStatus = "corrected"
+)
+
+// EVVVisit gathers the Electronic Visit Verification data points for a completed visit.
+type EVVVisit struct {
+	ScheduleID          string
+	ServiceCode         string
+	ServiceName         string
+	ClientID            string
+	ClientName          string
+	ClientMedicaidID    string
+	CaregiverID         string
+	CaregiverName       string
+	CaregiverEmployeeID string
+	ClockInAt           *time.Time
+	ClockInLat          *float64
+	ClockInLong         *float64
+	ClockOutAt          *time.Time
+	ClockOutLat         *float64
+	ClockOutLong        *float64
+	// ManuallyEdited marks visits whose clock times came from an approved correction.
+	ManuallyEdited bool
+	// SignoffRequired is set when the client must acknowledge each visit.
+	SignoffRequired    bool
+	SignoffMethod      SignoffMethod
+	SignoffAt          *time.Time
+	SignoffSummaryHash string
+
+	LastSubmittedHash *string
+	LastSubmittedAt   *time.Time
+}
+
+// Validate lists the EVV data points that are missing or inconsistent.
+func (v EVVVisit) Validate() []string {
+	var issues []string
+	if v.ServiceCode == "" {
+		issues = append(issues, "missing service code")
+	}
+	if v.ClientMedicaidID == "" {
+		issues = append(issues, "missing recipient medicaid id")
+	}
+	if v.CaregiverID == "" {
+		issues = append(issues, "missing caregiver")
+	} else if v.CaregiverEmployeeID == "" {
+		issues = append(issues, "missing caregiver employee id")
+	}
+	if v.ClockInAt == nil {
+		issues = append(issues, "missing start time")
+	}
+	if v.ClockOutAt == nil {
+		issues = append(issues, "missing end time")
+	}
+	if v.ClockInAt != nil && v.ClockOutAt != nil && !v.ClockOutAt.After(*v.ClockInAt) {
+		issues = append(issues, "end time is not after start time")
+	}
+	if v.ClockInLat == nil || v.ClockInLong == nil {
+		issues = append(issues, "missing start location")
+	}
+	if v.ClockOutLat == nil || v.ClockOutLong == nil {
+		issues = append(issues, "missing end location")
+	}
+	if v.SignoffRequired && v.SignoffMethod == "" {
+		issues = append(issues, "missing client signoff")
+	}
+	return issues
+}
+
+// Hash fingerprints the submitted data points so later corrections can be detected.
+func (v EVVVisit) Hash() string {
+	parts := []string{
+		v.ScheduleID, v.ServiceCode, v.ClientID, v.ClientMedicaidID, v.CaregiverID, v.CaregiverEmployeeID,
+		formatOptionalTime(v.ClockInAt), formatOptionalFloat(v.ClockInLat), formatOptionalFloat(v.ClockInLong),
+		formatOptionalTime(v.ClockOutAt), formatOptionalFloat(v.ClockOutLat), formatOptionalFloat(v.ClockOutLong),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return fmt.Sprintf("%x", sum[:])
+}
+
+// Status derives the submission status from the last submitted hash.
+func (v EVVVisit) Status() EVVVisitStatus {
+	switch {
+	case v.LastSubmittedHash == nil:
+		return EVVVisitUnsubmitted
+	case *v.LastSubmittedHash != v.Hash():
+		return EVVVisitCorrected
+	default:
+		return EVVVisitSubmitted
+	}
+}
+
+// EVVRecord is a visit included in an export batch.
+type EVVRecord struct {
+	Visit  EVVVisit
+	Action EVVAction
+	Hash   string
+}
+
+// EVVRejection explains why a visit was left out of a batch.
+type EVVRejection struct {
+	ScheduleID string
+	Issues     []string
+}
+
+// EVVBatch is a generated aggregator file covering a date range.
+type EVVBatch struct {
+	ID            string
+	From          time.Time
+	To            time.Time
+	Format        EVVFormat
+	RecordCount   int
+	RejectedCount int
+	Rejected      []EVVRejection
+	Payload       []byte
+	CreatedBy     string
+	CreatedAt     time.Time
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.6f", *f)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import "time"
+
+// FamilyMember is a relative or representative of a client who may follow the
+// client's care through the family portal. Family members have their own
+// credentials and never act as caregivers.
+type FamilyMember struct {
+	ID           string
+	ClientID     string
+	ClientName   string
+	Name         string
+	Email        string
+	Relationship string
+	SecretHash   string
+	Active       bool
+	CreatedAt    time.Time
+}
+
+// FamilyConsent records what a client has agreed to share with their family.
+// Nothing is shared until it is explicitly allowed.
+type FamilyConsent struct {
+	ClientID string
+	// ShareVisits shows upcoming and past visits: times, service, caregiver and status.
+	ShareVisits bool
+	// ShareTasks adds the tasks completed during each visit.
+	ShareTasks bool
+	// ShareConfirmations adds when the caregiver clocked in and out, and
+	// whether they did so at the client's address.
+	ShareConfirmations bool
+	UpdatedBy          *string
+	UpdatedAt          *time.Time
+}
+
+// FamilyVisit is the family portal's view of a visit. Internal notes,
+// coordinates and reasons for incomplete tasks are never part of it.
+type FamilyVisit struct {
+	ScheduleID    string
+	ServiceName   string
+	CaregiverName *string
+	StartTime     time.Time
+	EndTime       time.Time
+	Status        ScheduleStatus
+	// Tasks is set when the client shares tasks.
+	Tasks []FamilyTask
+	// Confirmation is set when the client shares confirmations.
+	Confirmation *VisitConfirmation
+}
+
+// FamilyTask is a task completed during a visit.
+type FamilyTask struct {
+	Title       string
+	CompletedAt time.Time
+}
+
+// VisitConfirmation shows that a visit took place.
+type VisitConfirmation struct {
+	ClockInAt  *time.Time
+	ClockOutAt *time.Time
+	// ClockInAtHome and ClockOutAtHome report whether the caregiver's
+	// location was near the client's address; nil when no location was taken.
+	ClockInAtHome  *bool
+	ClockOutAtHome *bool
+}
+
+// VisitConfirmationRadiusKm is how close to the client's address a clock-in or
+// clock-out must be to count as at home.
+const VisitConfirmationRadiusKm = 0.5
+
+// AtHome reports whether a clock location lies within VisitConfirmationRadiusKm
+// of the client, or nil when either location is unknown.
+func AtHome(lat, long, clientLat, clientLong *float64) *bool {
+	if lat == nil || long == nil || clientLat == nil || clientLong == nil {
+		return nil
+	}
+	near := DistanceKm(*lat, *long, *clientLat, *clientLong) <= VisitConfirmationRadiusKm
+	return &near
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// IncidentSeverity ranks how urgently a supervisor should look at an incident.
+type IncidentSeverity string
+
+const (
+	IncidentSeverityLow    IncidentSeverity = "low"
+	IncidentSeverityMedium IncidentSeverity = "medium"
+	IncidentSeverityHigh   IncidentSeverity = "high"
+)
+
+// Valid reports whether the severity is known.
+func (s IncidentSeverity) Valid() bool {
+	switch s {
+	case IncidentSeverityLow, IncidentSeverityMedium, IncidentSeverityHigh:
+		return true
+	}
+	return false
+}
+
+// IncidentStatus tracks an incident until a supervisor resolves it.
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen     IncidentStatus = "open"
+	IncidentStatusResolved IncidentStatus = "resolved"
+)
+
+// Incident is something that went wrong during care, such as a fall or a
+// medication error, reported by a caregiver for a supervisor to follow up.
+type Incident struct {
+	ID          string
+	CaregiverID string
+	// ScheduleID is the visit the incident happened on, if any.
+	ScheduleID  *string
+	Category    string
+	Severity    IncidentSeverity
+	Description string
+	Status      IncidentStatus
+	ReportedAt  time.Time
+	ResolvedBy  *string
+	ResolvedAt  *time.Time
+	Resolution  *string
+
+	// Read-only context joined for supervisors.
+	CaregiverName string
+	Region        *string
+	Team          *string
+	ClientName    *string
+}
@@ -6,20 +6,62 @@ import "time"
 type LogType string
 
 const (
-	LogTypeClockIn  LogType = "clock_in"
-	LogTypeClockOut LogType = "clock_out"
+	LogTypeClockIn    LogType = "clock_in"
+	LogTypeClockOut   LogType = "clock_out"
+	LogTypeBreakStart LogType = "break_start"
+	LogTypeBreakEnd   LogType = "break_end"
 )
 
+// Valid reports whether the log type is known.
+func (t LogType) Valid() bool {
+	switch t {
+	case LogTypeClockIn, LogTypeClockOut, LogTypeBreakStart, LogTypeBreakEnd:
+		return true
+	}
+	return false
+}
+
+// CanFollow reports whether a log of type t may be recorded after last, the
+// caregiver's previous log ("" when there is none). Shifts go clock_in, then
+// any number of break_start/break_end pairs, then clock_out; a caregiver must
+// end a break before clocking out.
+func (t LogType) CanFollow(last LogType) bool {
+	switch t {
+	case LogTypeClockIn:
+		return last == "" || last == LogTypeClockOut
+	case LogTypeClockOut, LogTypeBreakStart:
+		return last == LogTypeClockIn || last == LogTypeBreakEnd
+	case LogTypeBreakEnd:
+		return last == LogTypeBreakStart
+	}
+	return false
+}
+
+// BreakType distinguishes meal breaks from rest breaks.
+type BreakType string
+
+const (
+	BreakTypeMeal BreakType = "meal"
+	BreakTypeRest BreakType = "rest"
+)
+
+// Valid reports whether the break type is supported.
+func (t BreakType) Valid() bool {
+	return t == BreakTypeMeal || t == BreakTypeRest
+}
+
 // CaregiverLog models a caregiver attendance log entry.
 type CaregiverLog struct {
 	ID          string
 	CaregiverID string
 	LogType     LogType
-	Latitude    float64
-	Longitude   float64
-	Timestamp   time.Time
-	Notes       *string
-	CreatedAt   time.Time
+	// BreakType is only set on break_start logs.
+	BreakType *BreakType
+	Latitude  float64
+	Longitude float64
+	Timestamp time.Time
+	Notes     *string
+	CreatedAt time.Time
 }
 
 // CaregiverLogSummary is a lightweight projection for listing.
@@ -29,3 +71,140 @@ type CaregiverLogSummary struct {
 	LogType     LogType
 	Timestamp   time.Time
 }
+
+// AttendanceBreak pairs a break_start with the break_end that closed it.
+type AttendanceBreak struct {
+	Start CaregiverLog
+	End   *CaregiverLog
+}
+
+// AttendanceSession pairs a clock-in with the clock-out that closed it.
+type AttendanceSession struct {
+	ClockIn  CaregiverLog
+	ClockOut *CaregiverLog
+	Breaks   []AttendanceBreak
+}
+
+// Open reports whether the session has not been clocked out yet.
+func (s AttendanceSession) Open() bool {
+	return s.ClockOut == nil
+}
+
+// OnBreak reports whether the session's latest break is still running.
+func (s AttendanceSession) OnBreak() bool {
+	n := len(s.Breaks)
+	return n > 0 && s.Breaks[n-1].End == nil
+}
+
+// WorkedMinutes returns the session length less breaks; open sessions are
+// measured up to now.
+func (s AttendanceSession) WorkedMinutes(now time.Time) int {
+	return s.MinutesWithin(s.ClockIn.Timestamp, s.End(now), now)
+}
+
+// BreakMinutes returns the time spent on breaks; a running break counts up to now.
+func (s AttendanceSession) BreakMinutes(now time.Time) int {
+	return int(s.BreakWithin(s.ClockIn.Timestamp, s.End(now), now) / time.Minute)
+}
+
+// MinutesWithin returns the worked part of the session, less breaks, that
+// falls inside [from, to).
+func (s AttendanceSession) MinutesWithin(from, to, now time.Time) int {
+	return int(s.WorkedWithin(from, to, now) / time.Minute)
+}
+
+// WorkedWithin is MinutesWithin at full precision.
+func (s AttendanceSession) WorkedWithin(from, to, now time.Time) time.Duration {
+	worked := overlap(s.ClockIn.Timestamp, s.End(now), from, to) - s.BreakWithin(from, to, now)
+	if worked <= 0 {
+		return 0
+	}
+	return worked
+}
+
+// LongestStretch returns the longest period worked without a break.
+func (s AttendanceSession) LongestStretch(now time.Time) time.Duration {
+	var longest time.Duration
+	from := s.ClockIn.Timestamp
+	for _, b := range s.Breaks {
+		if d := b.Start.Timestamp.Sub(from); d > longest {
+			longest = d
+		}
+		if b.End == nil {
+			return longest
+		}
+		from = b.End.Timestamp
+	}
+	if d := s.End(now).Sub(from); d > longest {
+		longest = d
+	}
+	return longest
+}
+
+// End returns the clock-out time, or now while the session is open.
+func (s AttendanceSession) End(now time.Time) time.Time {
+	if s.ClockOut != nil {
+		return s.ClockOut.Timestamp
+	}
+	return now
+}
+
+// BreakWithin returns the break time that falls inside [from, to).
+func (s AttendanceSession) BreakWithin(from, to, now time.Time) time.Duration {
+	var total time.Duration
+	for _, b := range s.Breaks {
+		end := s.End(now)
+		if b.End != nil {
+			end = b.End.Timestamp
+		}
+		total += overlap(b.Start.Timestamp, end, from, to)
+	}
+	return total
+}
+
+// overlap returns how much of [start, end) falls inside [from, to).
+func overlap(start, end, from, to time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// PairSessions groups chronologically ordered logs into shift sessions with
+// their breaks. Logs that do not fit the open session's state (such as a
+// clock-out without a preceding clock-in) are ignored.
+func PairSessions(logs []CaregiverLog) []AttendanceSession {
+	var sessions []AttendanceSession
+	for i := range logs {
+		log := logs[i]
+		if log.LogType == LogTypeClockIn {
+			sessions = append(sessions, AttendanceSession{ClockIn: log})
+			continue
+		}
+
+		n := len(sessions)
+		if n == 0 || !sessions[n-1].Open() {
+			continue
+		}
+		current := &sessions[n-1]
+		switch log.LogType {
+		case LogTypeClockOut:
+			current.ClockOut = &log
+		case LogTypeBreakStart:
+			if !current.OnBreak() {
+				current.Breaks = append(current.Breaks, AttendanceBreak{Start: log})
+			}
+		case LogTypeBreakEnd:
+			if current.OnBreak() {
+				current.Breaks[len(current.Breaks)-1].End = &log
+			}
+		}
+	}
+	return sessions
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// MedicationRoute is how a medication is taken.
+type MedicationRoute string
+
+const (
+	MedicationRouteOral        MedicationRoute = "oral"
+	MedicationRouteSublingual  MedicationRoute = "sublingual"
+	MedicationRouteTopical     MedicationRoute = "topical"
+	MedicationRouteTransdermal MedicationRoute = "transdermal"
+	MedicationRouteInhaled     MedicationRoute = "inhaled"
+	MedicationRouteNasal       MedicationRoute = "nasal"
+	MedicationRouteOphthalmic  MedicationRoute = "ophthalmic"
+	MedicationRouteOtic        MedicationRoute = "otic"
+	MedicationRouteRectal      MedicationRoute = "rectal"
+	MedicationRouteInjection   MedicationRoute = "injection"
+)
+
+// Valid reports whether the route is known.
+func (r MedicationRoute) Valid() bool {
+	switch r {
+	case MedicationRouteOral, MedicationRouteSublingual, MedicationRouteTopical, MedicationRouteTransdermal,
+		MedicationRouteInhaled, MedicationRouteNasal, MedicationRouteOphthalmic, MedicationRouteOtic,
+		MedicationRouteRectal, MedicationRouteInjection:
+		return true
+	}
+	return false
+}
+
+// doseTimeLayout is the form of an order's times of day.
+const doseTimeLayout = "15:04"
+
+// ValidDoseTime reports whether s is a time of day such as "08:00".
+func ValidDoseTime(s string) bool {
+	_, err := time.Parse(doseTimeLayout, s)
+	return err == nil
+}
+
+// MedicationOrder is a client's prescription as the agency administers it.
+// Scheduled orders are due at Times each day from StartDate to EndDate; PRN
+// orders have no times and are given as needed.
+type MedicationOrder struct {
+	ID       string
+	ClientID string
+	Drug     string
+	Dose     string
+	Route    MedicationRoute
+	// Times are "HH:MM" times of day in the agency timezone.
+	Times        []string
+	PRN          bool
+	Instructions *string
+	// StartDate and EndDate are calendar dates; EndDate is inclusive and nil
+	// for an open-ended order.
+	StartDate time.Time
+	EndDate   *time.Time
+	Active    bool
+	CreatedBy string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Label names the order for people, e.g. "Lisinopril 10 mg (oral)".
+func (o MedicationOrder) Label() string {
+	return fmt.Sprintf("%s %s (%s)", o.Drug, o.Dose, o.Route)
+}
+
+// Covers reports whether the order runs on the calendar date of day.
+func (o MedicationOrder) Covers(day time.Time) bool {
+	date := CalendarDay(day, time.UTC)
+	if date.Before(CalendarDay(o.StartDate, time.UTC)) {
+		return false
+	}
+	return o.EndDate == nil || !date.After(CalendarDay(*o.EndDate, time.UTC))
+}
+
+// DueTimes returns the instants in [from, to) at which a scheduled order is
+// due, reading its times of day in loc. PRN and inactive orders are never due.
+func (o MedicationOrder) DueTimes(from, to time.Time, loc *time.Location) []time.Time {
+	if o.PRN || !o.Active || !to.After(from) {
+		return nil
+	}
+	var due []time.Time
+	for day, _ := DayBounds(from, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !o.Covers(day) {
+			continue
+		}
+		for _, s := range o.Times {
+			clock, err := time.Parse(doseTimeLayout, s)
+			if err != nil {
+				continue
+			}
+			at := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+			if !at.Before(from) && at.Before(to) {
+				due = append(due, at)
+			}
+		}
+	}
+	return due
+}
+
+// AdministrationStatus is where a dose stands on the MAR.
+type AdministrationStatus string
+
+const (
+	// AdministrationDue is a scheduled dose not yet recorded.
+	AdministrationDue     AdministrationStatus = "due"
+	AdministrationGiven   AdministrationStatus = "given"
+	AdministrationRefused AdministrationStatus = "refused"
+	AdministrationOmitted AdministrationStatus = "omitted"
+	// AdministrationMissed is a dose still due once its visit was over.
+	AdministrationMissed AdministrationStatus = "missed"
+)
+
+// Valid reports whether the status is known.
+func (s AdministrationStatus) Valid() bool {
+	switch s {
+	case AdministrationDue, AdministrationGiven, AdministrationRefused, AdministrationOmitted, AdministrationMissed:
+		return true
+	}
+	return false
+}
+
+// Recordable reports whether a caregiver may record a dose with the status.
+func (s AdministrationStatus) Recordable() bool {
+	return s == AdministrationGiven || s == AdministrationRefused || s == AdministrationOmitted
+}
+
+// MedicationAdministration is one entry of a client's medication
+// administration record (MAR): a dose due, or given as needed, during a visit.
+type MedicationAdministration struct {
+	ID         string
+	OrderID    string
+	ScheduleID string
+	// TaskID is the visit task standing for a scheduled dose.
+	TaskID *string
+	// DueAt is nil for PRN doses.
+	DueAt  *time.Time
+	Status AdministrationStatus
+	// Reason explains a refused or omitted dose, or why a PRN dose was given.
+	Reason         *string
+	AdministeredAt *time.Time
+	RecordedBy     *string
+	RecordedAt     *time.Time
+	CreatedAt      time.Time
+
+	// Drug, Dose, Route and PRN are read from the order.
+	Drug  string
+	Dose  string
+	Route MedicationRoute
+	PRN   bool
+}
+
+// Label names the dose's medication like MedicationOrder.Label.
+func (a MedicationAdministration) Label() string {
+	return MedicationOrder{Drug: a.Drug, Dose: a.Dose, Route: a.Route}.Label()
+}
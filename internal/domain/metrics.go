@@ -0,0 +1,92 @@
+package domain
+
+import "time"
+
+// MetricsGranularity is the size of a metrics trend bucket.
+type MetricsGranularity string
+
+const (
+	MetricsGranularityDay   MetricsGranularity = "day"
+	MetricsGranularityWeek  MetricsGranularity = "week"
+	MetricsGranularityMonth MetricsGranularity = "month"
+)
+
+// Valid reports whether the granularity is known.
+func (g MetricsGranularity) Valid() bool {
+	switch g {
+	case MetricsGranularityDay, MetricsGranularityWeek, MetricsGranularityMonth:
+		return true
+	}
+	return false
+}
+
+// BucketStart returns the first calendar day of the bucket containing day.
+// Weeks start on Monday, matching Postgres date_trunc.
+func (g MetricsGranularity) BucketStart(day time.Time) time.Time {
+	switch g {
+	case MetricsGranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case MetricsGranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// Next returns the first day of the bucket after the one starting on start.
+func (g MetricsGranularity) Next(start time.Time) time.Time {
+	switch g {
+	case MetricsGranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case MetricsGranularityMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// VisitMetrics aggregates the visits that started in a period. Rates are nil
+// when nothing in the period could be measured.
+type VisitMetrics struct {
+	Total      int `json:"total"`
+	Upcoming   int `json:"upcoming"`
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+	Cancelled  int `json:"cancelled"`
+	Missed     int `json:"missed"`
+
+	// Arrivals counts clocked-in visits; OnTime those clocked in no later than
+	// the start plus the visit's flexible window and the on-time grace.
+	Arrivals   int      `json:"arrivals"`
+	OnTime     int      `json:"on_time"`
+	OnTimeRate *float64 `json:"on_time_rate"`
+
+	// Durations cover visits with both a clock-in and a clock-out.
+	AvgVisitMinutes   *float64 `json:"avg_visit_minutes"`
+	AvgPlannedMinutes *float64 `json:"avg_planned_minutes"`
+	// DurationRatio is clocked time over planned time for those visits.
+	DurationRatio *float64 `json:"duration_ratio"`
+
+	// Tasks belong to visits that were not cancelled.
+	Tasks              int      `json:"tasks"`
+	TasksCompleted     int      `json:"tasks_completed"`
+	TaskCompletionRate *float64 `json:"task_completion_rate"`
+}
+
+// MetricsBucket holds the metrics of visits starting in one bucket. Start is
+// the bucket's first calendar day, at midnight UTC like other stored dates.
+type MetricsBucket struct {
+	Start   time.Time
+	Metrics VisitMetrics
+}
+
+// MetricsRange is a metrics trend: one bucket per period from From to To
+// (inclusive calendar dates) and the totals of the whole range.
+type MetricsRange struct {
+	From        time.Time
+	To          time.Time
+	Granularity MetricsGranularity
+	Buckets     []MetricsBucket
+	Summary     VisitMetrics
+}
@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// MileageSource records which coordinates a travel leg was measured from.
+type MileageSource string
+
+const (
+	// MileageSourceGPS measures from the clock-out position of one visit to the
+	// clock-in position of the next.
+	MileageSourceGPS MileageSource = "gps"
+	// MileageSourceClient falls back to the clients' addresses when a visit has
+	// no recorded position.
+	MileageSourceClient MileageSource = "client"
+)
+
+// MileageStop is a visit the caregiver clocked in to, with the positions used
+// to measure travel to and from it.
+type MileageStop struct {
+	ScheduleID   string
+	ClockInAt    time.Time
+	ClockOutAt   *time.Time
+	ClockInLat   *float64
+	ClockInLong  *float64
+	ClockOutLat  *float64
+	ClockOutLong *float64
+	ClientLat    *float64
+	ClientLong   *float64
+}
+
+// MileageLeg is the straight-line travel between two consecutive visits.
+type MileageLeg struct {
+	FromScheduleID string
+	ToScheduleID   string
+	DepartedAt     *time.Time
+	ArrivedAt      time.Time
+	DistanceKm     float64
+	Source         MileageSource
+}
+
+// MileageDay is the travel computed between a caregiver's visits on one day.
+type MileageDay struct {
+	Date       time.Time
+	Legs       []MileageLeg
+	ComputedKm float64
+	// Claim is the reimbursement claim submitted for the day, if any.
+	Claim *MileageClaim
+}
+
+// MileageClaimStatus tracks a reimbursement claim through review.
+type MileageClaimStatus string
+
+const (
+	MileageClaimStatusSubmitted MileageClaimStatus = "submitted"
+	MileageClaimStatusApproved  MileageClaimStatus = "approved"
+	// MileageClaimStatusRejected lets the caregiver submit the day again.
+	MileageClaimStatusRejected MileageClaimStatus = "rejected"
+)
+
+// MileageClaim asks for reimbursement of one day's travel between visits.
+type MileageClaim struct {
+	ID          string
+	CaregiverID string
+	// ClaimDate is the calendar day of the travel in the caregiver's timezone.
+	ClaimDate  time.Time
+	Legs       []MileageLeg
+	ComputedKm float64
+	// ClaimedKm differs from ComputedKm when the caregiver adjusted the distance,
+	// in which case AdjustmentReason explains why.
+	ClaimedKm        float64
+	AdjustmentReason *string
+	RatePerKm        float64
+	Amount           float64
+	Status           MileageClaimStatus
+	SubmittedAt      time.Time
+	ReviewedBy       *string
+	ReviewedAt       *time.Time
+	ReviewComment    *string
+}
+
+// Adjusted reports whether the claimed distance differs from the computed one.
+func (c MileageClaim) Adjusted() bool {
+	return c.ClaimedKm != c.ComputedKm
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationChannel is a way of reaching a caregiver.
+type NotificationChannel string
+
+const (
+	NotificationChannelPush  NotificationChannel = "push"
+	NotificationChannelSMS   NotificationChannel = "sms"
+	NotificationChannelEmail NotificationChannel = "email"
+)
+
+// NotificationChannels lists every channel in the order notifications are sent.
+var NotificationChannels = []NotificationChannel{NotificationChannelPush, NotificationChannelSMS, NotificationChannelEmail}
+
+// Valid reports whether the channel is known.
+func (c NotificationChannel) Valid() bool {
+	switch c {
+	case NotificationChannelPush, NotificationChannelSMS, NotificationChannelEmail:
+		return true
+	}
+	return false
+}
+
+// NotificationKind is the reason a caregiver is notified.
+type NotificationKind string
+
+const (
+	// NotificationVisitReminder is sent shortly before a visit starts.
+	NotificationVisitReminder NotificationKind = "visit_reminder"
+	// NotificationVisitAssigned is sent when a coordinator gives a visit to
+	// the caregiver.
+	NotificationVisitAssigned NotificationKind = "visit_assigned"
+	// NotificationClockOutReminder is sent when a visit ran past its end
+	// without a clock-out.
+	NotificationClockOutReminder NotificationKind = "clock_out_reminder"
+)
+
+// Valid reports whether the kind is known.
+func (k NotificationKind) Valid() bool {
+	switch k {
+	case NotificationVisitReminder, NotificationVisitAssigned, NotificationClockOutReminder:
+		return true
+	}
+	return false
+}
+
+// NotificationStatus is the outcome recorded in the delivery log.
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed"
+	// NotificationSuppressed notifications fell in the caregiver's quiet
+	// hours and were not sent.
+	NotificationSuppressed NotificationStatus = "suppressed"
+)
+
+// QuietHours is a daily window, in the caregiver's timezone, during which no
+// notifications are sent. Start and End are minutes after midnight; a window
+// with End before Start runs past midnight.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// Contains reports whether the clock time of t falls inside the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start <= q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// ParseClock parses "HH:MM" into minutes after midnight.
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatClock formats minutes after midnight as "HH:MM".
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// NotificationPreferences are a caregiver's choices about being notified.
+type NotificationPreferences struct {
+	CaregiverID string
+	// Channels are tried in NotificationChannels order; a channel without an
+	// address is skipped.
+	Channels      []NotificationChannel
+	DisabledKinds []NotificationKind
+	Phone         *string
+	PushToken     *string
+	// Email overrides the caregiver's account email.
+	Email      *string
+	QuietHours *QuietHours
+	UpdatedAt  *time.Time
+}
+
+// DefaultNotificationPreferences applies until a caregiver saves their own:
+// every kind on every channel they have an address for, no quiet hours.
+func DefaultNotificationPreferences(caregiverID string) NotificationPreferences {
+	return NotificationPreferences{
+		CaregiverID: caregiverID,
+		Channels:    append([]NotificationChannel(nil), NotificationChannels...),
+	}
+}
+
+// Wants reports whether the caregiver receives notifications of the kind.
+func (p NotificationPreferences) Wants(kind NotificationKind) bool {
+	for _, disabled := range p.DisabledKinds {
+		if disabled == kind {
+			return false
+		}
+	}
+	return true
+}
+
+// Uses reports whether the caregiver receives notifications on the channel.
+func (p NotificationPreferences) Uses(channel NotificationChannel) bool {
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationVisit is the visit a notification is about, with what the
+// message templates need.
+type NotificationVisit struct {
+	ScheduleID     string
+	CaregiverID    string
+	CaregiverName  string
+	CaregiverEmail string
+	ClientName     string
+	ServiceName    string
+	LocationLabel  string
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+// NotificationMessage is a rendered message ready for a channel.
+type NotificationMessage struct {
+	Channel     NotificationChannel
+	Kind        NotificationKind
+	CaregiverID string
+	// To is the device token, phone number or email address.
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notification is an entry in the delivery log. DedupeKey identifies what the
+// notification is about, so the same reminder is never sent twice.
+type Notification struct {
+	ID          string
+	CaregiverID string
+	Kind        NotificationKind
+	Channel     NotificationChannel
+	DedupeKey   string
+	ScheduleID  *string
+	Recipient   string
+	Subject     string
+	Body        string
+	Status      NotificationStatus
+	Error       *string
+	CreatedAt   time.Time
+	SentAt      *time.Time
+}
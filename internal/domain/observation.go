@@ -0,0 +1,212 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ObservationType is a kind of structured reading taken during a visit.
+type ObservationType string
+
+const (
+	// ObservationBloodPressure is systolic over diastolic pressure.
+	ObservationBloodPressure ObservationType = "blood_pressure"
+	ObservationHeartRate     ObservationType = "heart_rate"
+	ObservationTemperature   ObservationType = "temperature"
+	// ObservationOxygenSaturation is SpO2 from a pulse oximeter.
+	ObservationOxygenSaturation ObservationType = "oxygen_saturation"
+	ObservationWeight           ObservationType = "weight"
+	// ObservationMood is scored from 1 (very low) to 5 (very good).
+	ObservationMood ObservationType = "mood"
+)
+
+// ObservationTypes lists the observation types in display order.
+var ObservationTypes = []ObservationType{
+	ObservationBloodPressure,
+	ObservationHeartRate,
+	ObservationTemperature,
+	ObservationOxygenSaturation,
+	ObservationWeight,
+	ObservationMood,
+}
+
+// Valid reports whether the type is known.
+func (t ObservationType) Valid() bool {
+	_, ok := observationSpecs[t]
+	return ok
+}
+
+// Spec returns how readings of the type are measured and checked.
+func (t ObservationType) Spec() ObservationSpec {
+	return observationSpecs[t]
+}
+
+// ObservationRange is an inclusive range of readings. A nil bound is open.
+type ObservationRange struct {
+	Low  *float64
+	High *float64
+}
+
+// Contains reports whether v lies within the range.
+func (r ObservationRange) Contains(v float64) bool {
+	return (r.Low == nil || v >= *r.Low) && (r.High == nil || v <= *r.High)
+}
+
+// ObservationSpec is how readings of one observation type are taken: their
+// unit, the values accepted at all and the values considered normal.
+// Diastolic ranges apply to blood pressure only.
+type ObservationSpec struct {
+	Unit string
+	// Integer types, such as mood, accept whole numbers only.
+	Integer bool
+	// Plausible bounds a reading; anything outside is a recording mistake.
+	Plausible ObservationRange
+	// Normal bounds a reading that needs no attention.
+	Normal ObservationRange
+
+	DiastolicPlausible ObservationRange
+	DiastolicNormal    ObservationRange
+
+	// conversions turn readings in other units into Unit.
+	conversions map[string]func(float64) float64
+}
+
+// Canonical converts v measured in unit to the spec's unit. An empty unit is
+// the spec's own.
+func (s ObservationSpec) Canonical(v float64, unit string) (float64, bool) {
+	if unit == "" || unit == s.Unit {
+		return v, true
+	}
+	convert, ok := s.conversions[unit]
+	if !ok {
+		return 0, false
+	}
+	// Keep converted readings to one decimal like the instruments report them.
+	return math.Round(convert(v)*10) / 10, true
+}
+
+// Units lists the units readings may be recorded in, the spec's own first.
+func (s ObservationSpec) Units() []string {
+	others := make([]string, 0, len(s.conversions))
+	for unit := range s.conversions {
+		others = append(others, unit)
+	}
+	sort.Strings(others)
+	return append([]string{s.Unit}, others...)
+}
+
+func bound(v float64) *float64 { return &v }
+
+var observationSpecs = map[ObservationType]ObservationSpec{
+	ObservationBloodPressure: {
+		Unit:               "mmHg",
+		Integer:            true,
+		Plausible:          ObservationRange{Low: bound(50), High: bound(260)},
+		Normal:             ObservationRange{Low: bound(90), High: bound(140)},
+		DiastolicPlausible: ObservationRange{Low: bound(30), High: bound(160)},
+		DiastolicNormal:    ObservationRange{Low: bound(60), High: bound(90)},
+	},
+	ObservationHeartRate: {
+		Unit:      "bpm",
+		Integer:   true,
+		Plausible: ObservationRange{Low: bound(20), High: bound(250)},
+		Normal:    ObservationRange{Low: bound(50), High: bound(100)},
+	},
+	ObservationTemperature: {
+		Unit:      "C",
+		Plausible: ObservationRange{Low: bound(30), High: bound(45)},
+		Normal:    ObservationRange{Low: bound(36), High: bound(37.9)},
+		conversions: map[string]func(float64) float64{
+			"F": func(f float64) float64 { return (f - 32) * 5 / 9 },
+		},
+	},
+	ObservationOxygenSaturation: {
+		Unit:      "%",
+		Integer:   true,
+		Plausible: ObservationRange{Low: bound(50), High: bound(100)},
+		Normal:    ObservationRange{Low: bound(92)},
+	},
+	ObservationWeight: {
+		Unit:      "kg",
+		Plausible: ObservationRange{Low: bound(1), High: bound(400)},
+		conversions: map[string]func(float64) float64{
+			"lb": func(lb float64) float64 { return lb * 0.45359237 },
+		},
+	},
+	ObservationMood: {
+		Unit:      "score",
+		Integer:   true,
+		Plausible: ObservationRange{Low: bound(1), High: bound(5)},
+		Normal:    ObservationRange{Low: bound(3)},
+	},
+}
+
+// ObservationFlag marks a reading outside its normal range.
+type ObservationFlag string
+
+const (
+	ObservationFlagLow  ObservationFlag = "low"
+	ObservationFlagHigh ObservationFlag = "high"
+)
+
+// Observation is one structured reading taken by a caregiver during a visit.
+// Value is in the type's unit; for blood pressure it is the systolic pressure
+// and Diastolic is set.
+type Observation struct {
+	ID          string
+	ScheduleID  string
+	ClientID    string
+	CaregiverID string
+	Type        ObservationType
+	Value       float64
+	Diastolic   *float64
+	Unit        string
+	Note        *string
+	// Flag is set when the reading is outside the type's normal range.
+	Flag       *ObservationFlag
+	RecordedAt time.Time
+}
+
+// Check reports whether the reading is plausible for its type and sets Flag
+// when it is outside the normal range.
+func (o *Observation) Check() bool {
+	spec, ok := observationSpecs[o.Type]
+	if !ok || !spec.accepts(o.Value, spec.Plausible) {
+		return false
+	}
+	if (o.Type == ObservationBloodPressure) != (o.Diastolic != nil) {
+		return false
+	}
+	flag := spec.Normal.flag(o.Value)
+	if o.Diastolic != nil {
+		if !spec.accepts(*o.Diastolic, spec.DiastolicPlausible) || *o.Diastolic >= o.Value {
+			return false
+		}
+		if flag == nil {
+			flag = spec.DiastolicNormal.flag(*o.Diastolic)
+		}
+	}
+	o.Flag = flag
+	return true
+}
+
+func (s ObservationSpec) accepts(v float64, plausible ObservationRange) bool {
+	if math.IsNaN(v) || math.IsInf(v, 0) || (s.Integer && v != math.Trunc(v)) {
+		return false
+	}
+	return plausible.Contains(v)
+}
+
+func (r ObservationRange) flag(v float64) *ObservationFlag {
+	var flag ObservationFlag
+	switch {
+	case r.Low != nil && v < *r.Low:
+		flag = ObservationFlagLow
+	case r.High != nil && v > *r.High:
+		flag = ObservationFlagHigh
+	default:
+		return nil
+	}
+	return &flag
+}
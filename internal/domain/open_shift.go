@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// OpenShift is an unassigned visit offered to eligible caregivers.
+type OpenShift struct {
+	ScheduleID             string
+	ClientID               string
+	ClientName             string
+	ServiceName            string
+	LocationName           string
+	StartTime              time.Time
+	EndTime                time.Time
+	Latitude               float64
+	Longitude              float64
+	RequiredQualifications []string
+	// DistanceKm is the distance from the caregiver's home, nil when unknown.
+	DistanceKm *float64
+}
+
+// HasQualifications reports whether the given set covers every requirement of the shift.
+func (s OpenShift) HasQualifications(qualifications []string) bool {
+	held := make(map[string]struct{}, len(qualifications))
+	for _, q := range qualifications {
+		held[q] = struct{}{}
+	}
+	for _, req := range s.RequiredQualifications {
+		if _, ok := held[req]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// RouteStop is a visit on a caregiver's daily route.
+type RouteStop struct {
+	ScheduleID         string
+	ClientName         string
+	LocationName       string
+	Status             ScheduleStatus
+	StartTime          time.Time
+	EndTime            time.Time
+	Latitude           float64
+	Longitude          float64
+	FlexibleWindowMins int
+}
+
+// RouteLeg estimates travel between two consecutive stops.
+type RouteLeg struct {
+	FromScheduleID string
+	ToScheduleID   string
+	DistanceKm     float64
+	TravelMins     int
+	// AvailableMins is the gap between the end of one visit and the start of the next.
+	AvailableMins int
+	Feasible      bool
+}
+
+// DailyRoute is the chronological plan of a caregiver's visits for one day.
+type DailyRoute struct {
+	Date            time.Time
+	Stops           []RouteStop
+	Legs            []RouteLeg
+	TotalDistanceKm float64
+	InfeasibleLegs  int
+	// OptimisedOrder lists schedule IDs in a shorter feasible order when flexible
+	// visits allow one; nil when not requested or no improvement was found.
+	OptimisedOrder      []string
+	OptimisedDistanceKm *float64
+}
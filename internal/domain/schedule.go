@@ -15,6 +15,35 @@ const (
 	ScheduleStatusMissed     ScheduleStatus = "missed"
 )
 
+// Valid reports whether the status is known.
+func (s ScheduleStatus) Valid() bool {
+	switch s {
+	case ScheduleStatusScheduled, ScheduleStatusInProgress, ScheduleStatusCompleted,
+		ScheduleStatusCancelled, ScheduleStatusMissed:
+		return true
+	}
+	return false
+}
+
+// ListRank orders statuses in schedule listings: active visits first, then
+// upcoming, missed, completed and cancelled ones.
+func (s ScheduleStatus) ListRank() int {
+	switch s {
+	case ScheduleStatusInProgress:
+		return 0
+	case ScheduleStatusScheduled:
+		return 1
+	case ScheduleStatusMissed:
+		return 2
+	case ScheduleStatusCompleted:
+		return 3
+	case ScheduleStatusCancelled:
+		return 4
+	default:
+		return 5
+	}
+}
+
 // TaskStatus enumerates the completion status for each care activity.
 type TaskStatus string
 
@@ -26,9 +55,14 @@ const (
 
 // Caregiver represents the user of the application.
 type Caregiver struct {
-	ID    string
-	Name  string
-	Email string
+	ID             string
+	Name           string
+	Email          string
+	HomeLatitude   *float64
+	HomeLongitude  *float64
+	Qualifications []string
+	// Timezone is an IANA zone name; empty means the agency default.
+	Timezone string
 }
 
 // Client represents the care recipient tied to a schedule.
@@ -55,6 +89,9 @@ type Task struct {
 	NotCompletedReason *string
 	SortOrder          int32
 	UpdatedAt          time.Time
+	// MedicationAdministrationID links a task standing for a scheduled dose
+	// to its MAR entry.
+	MedicationAdministrationID *string
 }
 
 // Schedule aggregates visit metadata, client details, and tasks.
@@ -78,6 +115,15 @@ type Schedule struct {
 	Tasks         []Task
 	DurationMins  int
 	LocationLabel string
+
+	RequiredQualifications []string
+	ClaimedAt              *time.Time
+	FlexibleWindowMins     int
+	ServiceCode            string
+	// ManuallyEdited is set once an approved correction changed the clock times.
+	ManuallyEdited bool
+	// Signoff is the client's acknowledgement taken at clock-out, if any.
+	Signoff *VisitSignoff
 }
 
 // ScheduleSummary is a lightweight projection for listing.
@@ -90,6 +136,8 @@ type ScheduleSummary struct {
 	EndTime      time.Time
 	Status       ScheduleStatus
 	LocationName string
+
+	ManuallyEdited bool
 }
 
 // ScheduleMetrics aggregates dashboard counts.
@@ -100,6 +148,16 @@ type ScheduleMetrics struct {
 	Completed  int `json:"completed"`
 	InProgress int `json:"in_progress"`
 	Cancelled  int `json:"cancelled"`
+	// CarriedOver counts visits that started on an earlier day and run into
+	// this one; always zero under OvernightRuleStartDay.
+	CarriedOver int `json:"carried_over"`
+}
+
+// VisitInterval is the clocked time of a visit; End is nil while it is in progress.
+type VisitInterval struct {
+	ScheduleID string
+	Start      time.Time
+	End        *time.Time
 }
 
 // VisitEvent captures a geolocated clock event.
@@ -109,4 +167,6 @@ type VisitEvent struct {
 	Longitude  float64
 	Timestamp  time.Time
 	Notes      *string
+	// Signoff is the client's acknowledgement, only read at clock-out.
+	Signoff *SignoffCapture
 }
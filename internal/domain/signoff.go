@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// SignoffMethod is how the client or their representative acknowledged a visit.
+type SignoffMethod string
+
+const (
+	SignoffMethodSignature SignoffMethod = "signature"
+	SignoffMethodPIN       SignoffMethod = "pin"
+)
+
+// Valid reports whether m is a known sign-off method.
+func (m SignoffMethod) Valid() bool {
+	return m == SignoffMethodSignature || m == SignoffMethodPIN
+}
+
+// SignerRole identifies who acknowledged the visit.
+type SignerRole string
+
+const (
+	SignerRoleClient         SignerRole = "client"
+	SignerRoleRepresentative SignerRole = "representative"
+)
+
+// Valid reports whether r is a known signer role.
+func (r SignerRole) Valid() bool {
+	return r == SignerRoleClient || r == SignerRoleRepresentative
+}
+
+// SignatureFormat is the encoding of a captured signature.
+type SignatureFormat string
+
+const (
+	// SignatureFormatSVG is SVG path data, as drawn on a signature pad.
+	SignatureFormatSVG SignatureFormat = "svg"
+	// SignatureFormatPNG is a PNG image of the signature.
+	SignatureFormatPNG SignatureFormat = "png"
+)
+
+const (
+	// MaxSignatureSVGBytes bounds the size of SVG path data.
+	MaxSignatureSVGBytes = 64 << 10
+	// MaxSignaturePNGBytes bounds the size of a PNG signature.
+	MaxSignaturePNGBytes = 256 << 10
+)
+
+var (
+	pngMagic = []byte("\x89PNG\r\n\x1a\n")
+	// svgPathData accepts path commands and numbers only, so a stored
+	// signature can never carry markup or script.
+	svgPathData = regexp.MustCompile(`^\s*[Mm][MmLlHhVvCcSsQqTtAaZz0-9eE.,+\-\s]*$`)
+)
+
+// ValidSignature reports whether data is a well-formed signature in format.
+func ValidSignature(format SignatureFormat, data []byte) bool {
+	switch format {
+	case SignatureFormatSVG:
+		return len(data) <= MaxSignatureSVGBytes && svgPathData.Match(data)
+	case SignatureFormatPNG:
+		return len(data) <= MaxSignaturePNGBytes && bytes.HasPrefix(data, pngMagic)
+	default:
+		return false
+	}
+}
+
+// SignoffCapture is the acknowledgement collected by the caregiver at clock-out.
+type SignoffCapture struct {
+	Method     SignoffMethod
+	SignerName string
+	SignerRole SignerRole
+	// SignatureFormat and Signature are set for SignoffMethodSignature.
+	SignatureFormat SignatureFormat
+	Signature       []byte
+	// PIN is set for SignoffMethodPIN and is never stored.
+	PIN string
+}
+
+// VisitSignoff is the client's acknowledgement stored with a completed visit.
+type VisitSignoff struct {
+	ScheduleID      string
+	Method          SignoffMethod
+	SignerName      string
+	SignerRole      SignerRole
+	SignatureFormat SignatureFormat
+	Signature       []byte
+	// Summary is the visit summary the client acknowledged, as canonical JSON.
+	Summary []byte
+	// SummaryHash is the hex SHA-256 of Summary.
+	SummaryHash string
+	CapturedAt  time.Time
+}
+
+// ClientSignoffSettings configures visit acknowledgement for a client.
+type ClientSignoffSettings struct {
+	ClientID string
+	// Required rejects clock-outs that carry no acknowledgement.
+	Required bool
+	// PINHash is the client's hashed PIN; nil when no PIN is set.
+	PINHash *string
+	// PINSetAt is when the current PIN was set; wrong PINs entered since
+	// then count towards locking it.
+	PINSetAt  *time.Time
+	UpdatedBy *string
+	UpdatedAt *time.Time
+}
+
+// SignoffPINFailure is a wrong PIN entered at clock-out, kept for audit.
+type SignoffPINFailure struct {
+	ClientID    string
+	ScheduleID  string
+	CaregiverID string
+	AttemptedAt time.Time
+}
+
+// VisitSummary is what the client acknowledges at clock-out. Its JSON
+// encoding is fixed so the hash can be recomputed from the stored summary.
+type VisitSummary struct {
+	ScheduleID     string             `json:"schedule_id"`
+	ClientID       string             `json:"client_id"`
+	CaregiverID    string             `json:"caregiver_id"`
+	ServiceName    string             `json:"service_name"`
+	ScheduledStart time.Time          `json:"scheduled_start"`
+	ScheduledEnd   time.Time          `json:"scheduled_end"`
+	ClockInAt      *time.Time         `json:"clock_in_at"`
+	ClockOutAt     time.Time          `json:"clock_out_at"`
+	Tasks          []VisitSummaryTask `json:"tasks"`
+	Method         SignoffMethod      `json:"method"`
+	SignerName     string             `json:"signer_name"`
+	SignerRole     SignerRole         `json:"signer_role"`
+	// SignatureSHA256 binds the signature image to the summary; empty for a PIN.
+	SignatureSHA256 string `json:"signature_sha256"`
+}
+
+// VisitSummaryTask is a task and the status it had when the visit was signed.
+type VisitSummaryTask struct {
+	Title  string     `json:"title"`
+	Status TaskStatus `json:"status"`
+}
+
+// NewVisitSummary summarises schedule and its tasks as acknowledged by capture
+// at clockOut. Times are kept to the second in UTC.
+func NewVisitSummary(schedule Schedule, clockOut time.Time, capture SignoffCapture) VisitSummary {
+	utc := func(t time.Time) time.Time { return t.UTC().Truncate(time.Second) }
+	summary := VisitSummary{
+		ScheduleID:     schedule.ID,
+		ClientID:       schedule.Client.ID,
+		CaregiverID:    schedule.CaregiverID,
+		ServiceName:    schedule.ServiceName,
+		ScheduledStart: utc(schedule.StartTime),
+		ScheduledEnd:   utc(schedule.EndTime),
+		ClockOutAt:     utc(clockOut),
+		Tasks:          make([]VisitSummaryTask, len(schedule.Tasks)),
+		Method:         capture.Method,
+		SignerName:     capture.SignerName,
+		SignerRole:     capture.SignerRole,
+	}
+	if schedule.ClockInAt != nil {
+		in := utc(*schedule.ClockInAt)
+		summary.ClockInAt = &in
+	}
+	for i, t := range schedule.Tasks {
+		summary.Tasks[i] = VisitSummaryTask{Title: t.Title, Status: t.Status}
+	}
+	if len(capture.Signature) > 0 {
+		sum := sha256.Sum256(capture.Signature)
+		summary.SignatureSHA256 = fmt.Sprintf("%x", sum[:])
+	}
+	return summary
+}
+
+// Encode returns the canonical JSON of the summary and its hex SHA-256.
+func (s VisitSummary) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	return data, fmt.Sprintf("%x", sum[:]), nil
+}
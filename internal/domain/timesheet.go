@@ -0,0 +1,140 @@
+package domain
+
+import "time"
+
+// TimesheetPeriod is the length of a pay period.
+type TimesheetPeriod string
+
+const (
+	TimesheetPeriodWeekly   TimesheetPeriod = "weekly"
+	TimesheetPeriodBiweekly TimesheetPeriod = "biweekly"
+)
+
+// Valid reports whether the period is supported.
+func (p TimesheetPeriod) Valid() bool {
+	return p == TimesheetPeriodWeekly || p == TimesheetPeriodBiweekly
+}
+
+// Days returns the number of calendar days in the period.
+func (p TimesheetPeriod) Days() int {
+	if p == TimesheetPeriodBiweekly {
+		return 14
+	}
+	return 7
+}
+
+// TimesheetStatus tracks a timesheet through submit → approve → lock.
+type TimesheetStatus string
+
+const (
+	// TimesheetStatusDraft is a timesheet computed from attendance that has not
+	// been submitted; drafts are not stored.
+	TimesheetStatusDraft     TimesheetStatus = "draft"
+	TimesheetStatusSubmitted TimesheetStatus = "submitted"
+	TimesheetStatusApproved  TimesheetStatus = "approved"
+	// TimesheetStatusRejected sends a submission back to the caregiver, who
+	// may submit the period again.
+	TimesheetStatusRejected TimesheetStatus = "rejected"
+	// TimesheetStatusLocked is final: the timesheet and the attendance and
+	// visits in its period can no longer change.
+	TimesheetStatusLocked TimesheetStatus = "locked"
+)
+
+// Editable reports whether the period may still be (re)submitted.
+func (s TimesheetStatus) Editable() bool {
+	return s == TimesheetStatusDraft || s == TimesheetStatusRejected
+}
+
+// OvertimeRules define when worked time becomes overtime. A zero threshold
+// disables that rule.
+type OvertimeRules struct {
+	// Daily is the regular time allowed per calendar day.
+	Daily time.Duration
+	// Weekly is the regular time allowed per seven-day week of the period,
+	// after daily overtime has been taken out.
+	Weekly time.Duration
+}
+
+// Apply fills RegularMinutes and OvertimeMinutes of days, which must be
+// consecutive calendar days starting on the first day of a week.
+func (r OvertimeRules) Apply(days []TimesheetDay) {
+	daily := int(r.Daily / time.Minute)
+	weekly := int(r.Weekly / time.Minute)
+
+	weekRegular := 0
+	for i := range days {
+		if i%7 == 0 {
+			weekRegular = 0
+		}
+		day := &days[i]
+		day.RegularMinutes = day.WorkedMinutes
+		day.OvertimeMinutes = 0
+
+		if daily > 0 && day.RegularMinutes > daily {
+			day.OvertimeMinutes = day.RegularMinutes - daily
+			day.RegularMinutes = daily
+		}
+		if weekly > 0 && weekRegular+day.RegularMinutes > weekly {
+			excess := weekRegular + day.RegularMinutes - weekly
+			if excess > day.RegularMinutes {
+				excess = day.RegularMinutes
+			}
+			day.RegularMinutes -= excess
+			day.OvertimeMinutes += excess
+		}
+		weekRegular += day.RegularMinutes
+	}
+}
+
+// TimesheetDay totals one calendar day of a timesheet.
+type TimesheetDay struct {
+	// Date carries the calendar day: midnight in the caregiver's timezone when
+	// computed, midnight UTC when read back from storage.
+	Date            time.Time
+	WorkedMinutes   int
+	BreakMinutes    int
+	VisitMinutes    int
+	RegularMinutes  int
+	OvertimeMinutes int
+	CompletedVisits int
+}
+
+// Timesheet is a caregiver's pay period built from attendance and visits.
+type Timesheet struct {
+	ID          string
+	CaregiverID string
+	Period      TimesheetPeriod
+	// PeriodStart and PeriodEnd bound the period; PeriodEnd is exclusive.
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Status      TimesheetStatus
+	Days        []TimesheetDay
+
+	WorkedMinutes   int
+	BreakMinutes    int
+	VisitMinutes    int
+	RegularMinutes  int
+	OvertimeMinutes int
+	CompletedVisits int
+
+	SubmittedAt   *time.Time
+	ReviewedBy    *string
+	ReviewedAt    *time.Time
+	ReviewComment *string
+	LockedBy      *string
+	LockedAt      *time.Time
+}
+
+// Total recomputes the timesheet totals from its days.
+func (t *Timesheet) Total() {
+	t.WorkedMinutes, t.BreakMinutes, t.VisitMinutes = 0, 0, 0
+	t.RegularMinutes, t.OvertimeMinutes, t.CompletedVisits = 0, 0, 0
+	for _, day := range t.Days {
+		t.WorkedMinutes += day.WorkedMinutes
+		t.BreakMinutes += day.BreakMinutes
+		t.VisitMinutes += day.VisitMinutes
+		t.RegularMinutes += day.RegularMinutes
+		t.OvertimeMinutes += day.OvertimeMinutes
+		t.CompletedVisits += day.CompletedVisits
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// CalendarDay returns midnight in loc of the calendar date carried by t,
+// ignoring t's own location. It is used to reinterpret parsed YYYY-MM-DD values.
+func CalendarDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// DayBounds returns the [start, end) instants of the calendar day containing t
+// in loc. Days are built from calendar arithmetic, so DST transition days span
+// 23 or 25 hours.
+func DayBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	start := CalendarDay(t.In(loc), loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// OvernightRule decides which calendar day work crossing midnight counts towards.
+type OvernightRule string
+
+const (
+	// OvernightRuleSplit splits worked minutes at midnight and counts a visit on
+	// every day it touches.
+	OvernightRuleSplit OvernightRule = "split"
+	// OvernightRuleStartDay attributes the whole shift or visit to the day it started.
+	OvernightRuleStartDay OvernightRule = "start_day"
+)
+
+// Valid reports whether the rule is supported.
+func (r OvernightRule) Valid() bool {
+	return r == OvernightRuleSplit || r == OvernightRuleStartDay
+}
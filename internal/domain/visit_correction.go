@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// CorrectionReason classifies why a visit's clock times need adjusting.
+type CorrectionReason string
+
+const (
+	CorrectionReasonForgotClockIn  CorrectionReason = "forgot_clock_in"
+	CorrectionReasonForgotClockOut CorrectionReason = "forgot_clock_out"
+	CorrectionReasonDeviceIssue    CorrectionReason = "device_issue"
+	CorrectionReasonWrongTime      CorrectionReason = "wrong_time"
+	CorrectionReasonOther          CorrectionReason = "other"
+)
+
+// Valid reports whether r is a known reason code.
+func (r CorrectionReason) Valid() bool {
+	switch r {
+	case CorrectionReasonForgotClockIn, CorrectionReasonForgotClockOut,
+		CorrectionReasonDeviceIssue, CorrectionReasonWrongTime, CorrectionReasonOther:
+		return true
+	}
+	return false
+}
+
+// CorrectionStatus tracks the review state of a visit correction.
+type CorrectionStatus string
+
+const (
+	CorrectionStatusPending  CorrectionStatus = "pending"
+	CorrectionStatusApproved CorrectionStatus = "approved"
+	CorrectionStatusRejected CorrectionStatus = "rejected"
+)
+
+// VisitCorrection is a proposed adjustment to a visit's clock-in/clock-out
+// times. Once approved it also keeps the values it replaced.
+type VisitCorrection struct {
+	ID                 string
+	ScheduleID         string
+	RequestedBy        string
+	Reason             CorrectionReason
+	Comment            *string
+	ProposedClockInAt  *time.Time
+	ProposedClockOutAt *time.Time
+	Status             CorrectionStatus
+
+	OriginalClockInAt  *time.Time
+	OriginalClockOutAt *time.Time
+	OriginalStatus     *ScheduleStatus
+
+	ReviewedBy    *string
+	ReviewedAt    *time.Time
+	ReviewComment *string
+	CreatedAt     time.Time
+}
+
+// CorrectionApproval carries the visit values an approved correction writes.
+type CorrectionApproval struct {
+	CorrectionID string
+	ReviewerID   string
+	Comment      *string
+	ReviewedAt   time.Time
+	ClockInAt    *time.Time
+	ClockOutAt   *time.Time
+	Status       ScheduleStatus
+}
@@ -0,0 +1,128 @@
+package domain
+
+import "time"
+
+// WebhookEventTypes lists the events recorded in the outbox and delivered to
+// webhook subscribers.
+var WebhookEventTypes = []EventType{
+	EventScheduleStatusChanged,
+	EventTaskUpdated,
+	EventAttendanceLogged,
+	EventIncidentReported,
+	EventIncidentResolved,
+	EventMedicationMissed,
+	EventObservationOutOfRange,
+}
+
+// IsWebhookEvent reports whether subscribers can receive events of type t.
+func IsWebhookEvent(t EventType) bool {
+	for _, known := range WebhookEventTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// it describes, so subscribers hear of every committed change and nothing
+// else. Payload is the JSON encoded event data.
+type OutboxEvent struct {
+	ID          string
+	Type        EventType
+	CaregiverID string
+	ScheduleID  *string
+	Payload     []byte
+	OccurredAt  time.Time
+}
+
+// WebhookSubscription is an external system receiving signed POSTs for the
+// event types it subscribed to; no event types means every type.
+type WebhookSubscription struct {
+	ID          string
+	URL         string
+	Description string
+	// Secret signs every delivery; it is shown only when the subscription is
+	// created.
+	Secret     string
+	EventTypes []EventType
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Wants reports whether the subscription receives events of type t.
+func (s WebhookSubscription) Wants(t EventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, wanted := range s.EventTypes {
+		if wanted == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus tracks one event's delivery to one subscriber.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending deliveries are retried until they succeed or run
+	// out of attempts.
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead deliveries ran out of attempts and wait in the
+	// dead-letter queue to be replayed.
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// Valid reports whether the status is known.
+func (s WebhookDeliveryStatus) Valid() bool {
+	switch s {
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is the attempt history of one event sent to one subscriber.
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastError      *string
+	ResponseStatus *int
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
+
+// WebhookMessage is a delivery claimed for sending, with what is needed to
+// build and sign the request.
+type WebhookMessage struct {
+	DeliveryID string
+	Attempts   int
+	URL        string
+	Secret     string
+	Event      OutboxEvent
+}
+
+// WebhookBackoff is how long to wait before retrying after the given number of
+// failed attempts: base doubled for every earlier failure, capped at max.
+func WebhookBackoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
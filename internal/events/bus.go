@@ -0,0 +1,101 @@
+// Package events fans domain events out to real-time subscribers, either
+// within one process or across replicas through Postgres LISTEN/NOTIFY.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// SubscriberBuffer is how many events a subscriber may fall behind before it
+// starts missing them.
+const SubscriberBuffer = 64
+
+// Bus is an in-process publish/subscribe hub. A subscriber whose buffer is
+// full misses events instead of slowing down the request that published them.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+type subscription struct {
+	accept func(domain.Event) bool
+	ch     chan domain.Event
+}
+
+// NewBus constructs an empty bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*subscription]struct{})}
+}
+
+// Subscribe returns a channel receiving every later event accept returns true
+// for, and a function that ends the subscription and closes the channel.
+// After Close the channel is returned already closed.
+func (b *Bus) Subscribe(accept func(domain.Event) bool) (<-chan domain.Event, func()) {
+	sub := &subscription{accept: accept, ch: make(chan domain.Event, SubscriberBuffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close ends every subscription so open streams finish, for example when the
+// server shuts down.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Publish delivers the event to this process's subscribers, giving it an id
+// and time first when it has none.
+func (b *Bus) Publish(_ context.Context, event domain.Event) {
+	event = stamp(event)
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.accept != nil && !sub.accept(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// stamp fills in the id and time of an event about to be published.
+func stamp(event domain.Event) domain.Event {
+	if event.ID == "" {
+		var raw [16]byte
+		_, _ = rand.Read(raw[:])
+		event.ID = hex.EncodeToString(raw[:])
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	event.OccurredAt = event.OccurredAt.UTC()
+	return event
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+func TestBusDeliversToMatchingSubscribers(t *testing.T) {
+	bus := NewBus()
+	own, stopOwn := bus.Subscribe(func(e domain.Event) bool { return e.CaregiverID == "cg-1" })
+	defer stopOwn()
+	all, stopAll := bus.Subscribe(nil)
+	defer stopAll()
+
+	bus.Publish(context.Background(), domain.Event{Type: domain.EventTaskUpdated, CaregiverID: "cg-2"})
+	bus.Publish(context.Background(), domain.Event{Type: domain.EventTaskCreated, CaregiverID: "cg-1"})
+
+	got := <-own
+	if got.CaregiverID != "cg-1" || got.ID == "" || got.OccurredAt.IsZero() {
+		t.Fatalf("unexpected event %+v", got)
+	}
+	if len(own) != 0 {
+		t.Fatalf("expected the other caregiver's event to be filtered out")
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected unfiltered subscriber to receive both events, got %d", len(all))
+	}
+}
+
+func TestBusDropsEventsForSlowSubscribers(t *testing.T) {
+	bus := NewBus()
+	stream, stop := bus.Subscribe(nil)
+	defer stop()
+
+	for i := 0; i < SubscriberBuffer+10; i++ {
+		bus.Publish(context.Background(), domain.Event{Type: domain.EventTaskUpdated, CaregiverID: "cg-1"})
+	}
+	if len(stream) != SubscriberBuffer {
+		t.Fatalf("expected %d buffered events, got %d", SubscriberBuffer, len(stream))
+	}
+}
+
+func TestBusKeepsPublishedIDs(t *testing.T) {
+	bus := NewBus()
+	stream, stop := bus.Subscribe(nil)
+	defer stop()
+
+	bus.Publish(context.Background(), domain.Event{ID: "evt-1", Type: domain.EventAttendanceLogged, CaregiverID: "cg-1"})
+	if got := <-stream; got.ID != "evt-1" {
+		t.Fatalf("expected relayed event to keep its id, got %q", got.ID)
+	}
+}
+
+func TestBusCloseEndsSubscriptions(t *testing.T) {
+	bus := NewBus()
+	stream, stop := bus.Subscribe(nil)
+
+	bus.Close()
+	if _, open := <-stream; open {
+		t.Fatalf("expected stream to be closed")
+	}
+	stop()
+
+	late, _ := bus.Subscribe(nil)
+	if _, open := <-late; open {
+		t.Fatalf("expected subscription after close to be closed")
+	}
+	bus.Publish(context.Background(), domain.Event{Type: domain.EventTaskUpdated, CaregiverID: "cg-1"})
+}
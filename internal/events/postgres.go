@@ -0,0 +1,143 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// maxNotifyPayload is Postgres's limit on a NOTIFY payload, in bytes.
+const maxNotifyPayload = 8000
+
+// listenerPing is how often an idle listener checks its connection.
+const listenerPing = 90 * time.Second
+
+const (
+	// notifyQueue is how many events may wait to be notified before Publish
+	// delivers them locally instead.
+	notifyQueue = 256
+	// notifyTimeout bounds each NOTIFY round trip.
+	notifyTimeout = 2 * time.Second
+)
+
+// execer is the part of the database the fan-out notifies through.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// PostgresFanout publishes events with NOTIFY so every replica listening on
+// the channel, this one included, delivers them to its own bus.
+type PostgresFanout struct {
+	db      execer
+	bus     *Bus
+	channel string
+	logger  *zap.Logger
+	pending chan notification
+}
+
+type notification struct {
+	event   domain.Event
+	payload string
+}
+
+// NewPostgresFanout constructs a fan-out over channel. Events are notified
+// once Listen has started.
+func NewPostgresFanout(db *sqlx.DB, bus *Bus, channel string, logger *zap.Logger) *PostgresFanout {
+	return newPostgresFanout(db, bus, channel, logger)
+}
+
+func newPostgresFanout(db execer, bus *Bus, channel string, logger *zap.Logger) *PostgresFanout {
+	return &PostgresFanout{db: db, bus: bus, channel: channel, logger: logger, pending: make(chan notification, notifyQueue)}
+}
+
+// Publish queues the event to be sent to every replica and returns at once.
+// When the event cannot be queued or notified it is still delivered locally.
+func (f *PostgresFanout) Publish(ctx context.Context, event domain.Event) {
+	event = stamp(event)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		f.logger.Warn("encode event failed, delivering locally", zap.String("type", string(event.Type)), zap.Error(err))
+		f.bus.Publish(ctx, event)
+		return
+	}
+	if len(payload) > maxNotifyPayload {
+		f.logger.Warn("event too large to notify, delivering locally", zap.String("type", string(event.Type)), zap.Int("bytes", len(payload)))
+		f.bus.Publish(ctx, event)
+		return
+	}
+	select {
+	case f.pending <- notification{event: event, payload: string(payload)}:
+	default:
+		f.logger.Warn("notify queue full, delivering locally", zap.String("type", string(event.Type)))
+		f.bus.Publish(ctx, event)
+	}
+}
+
+// send notifies queued events until ctx is done.
+func (f *PostgresFanout) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-f.pending:
+			f.notify(ctx, n)
+		}
+	}
+}
+
+func (f *PostgresFanout) notify(ctx context.Context, n notification) {
+	notifyCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	if _, err := f.db.ExecContext(notifyCtx, `SELECT pg_notify($1, $2)`, f.channel, n.payload); err != nil {
+		f.logger.Warn("notify event failed, delivering locally", zap.String("type", string(n.event.Type)), zap.Error(err))
+		f.bus.Publish(ctx, n.event)
+	}
+}
+
+// Listen relays notifications on the channel to the bus, and sends the events
+// Publish queues, until ctx is done. Events notified while the listener is
+// reconnecting are lost.
+func (f *PostgresFanout) Listen(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			f.logger.Warn("event listener connection", zap.Int("event", int(ev)), zap.Error(err))
+		}
+	})
+	if err := listener.Listen(f.channel); err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	go f.send(ctx)
+	go func() {
+		defer listener.Close()
+		ping := time.NewTicker(listenerPing)
+		defer ping.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ping.C:
+				go func() { _ = listener.Ping() }()
+			case n := <-listener.Notify:
+				if n == nil {
+					// The connection was re-established.
+					continue
+				}
+				var event domain.Event
+				if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+					f.logger.Warn("discarding malformed event notification", zap.Error(err))
+					continue
+				}
+				f.bus.Publish(ctx, event)
+			}
+		}
+	}()
+	return nil
+}
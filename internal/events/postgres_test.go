@@ -0,0 +1,85 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// blockingDB stands in for a saturated pool: every call waits until released
+// or its context ends.
+type blockingDB struct {
+	calls   chan string
+	release chan struct{}
+	err     error
+}
+
+func (db *blockingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.calls <- query
+	select {
+	case <-db.release:
+		return nil, db.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestPostgresFanoutPublishDoesNotWaitForNotify(t *testing.T) {
+	db := &blockingDB{calls: make(chan string, notifyQueue+1), release: make(chan struct{})}
+	bus := NewBus()
+	local, stop := bus.Subscribe(nil)
+	defer stop()
+	fanout := newPostgresFanout(db, bus, "care_events", zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fanout.send(ctx)
+
+	start := time.Now()
+	for i := 0; i < notifyQueue+2; i++ {
+		fanout.Publish(context.Background(), domain.Event{Type: domain.EventTaskUpdated, CaregiverID: "cg-1"})
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("expected Publish to return at once, took %v", elapsed)
+	}
+	select {
+	case <-db.calls:
+	case <-time.After(time.Second):
+		t.Fatal("expected the queued event to be notified")
+	}
+	// The queue overflowed while the database was stuck, so the overflow was
+	// delivered locally rather than dropped.
+	select {
+	case <-local:
+	case <-time.After(time.Second):
+		t.Fatal("expected an overflowing event to be delivered locally")
+	}
+}
+
+func TestPostgresFanoutDeliversLocallyWhenNotifyFails(t *testing.T) {
+	db := &blockingDB{calls: make(chan string, 1), release: make(chan struct{}), err: errors.New("connection reset")}
+	close(db.release)
+	bus := NewBus()
+	local, stop := bus.Subscribe(nil)
+	defer stop()
+	fanout := newPostgresFanout(db, bus, "care_events", zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fanout.send(ctx)
+
+	fanout.Publish(context.Background(), domain.Event{Type: domain.EventTaskUpdated, CaregiverID: "cg-1"})
+	select {
+	case event := <-local:
+		if event.Type != domain.EventTaskUpdated || event.ID == "" {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the event to be delivered locally after a failed notify")
+	}
+}
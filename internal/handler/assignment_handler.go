@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// AssignmentsWriteScope grants running the assignment optimiser and applying its proposals.
+const AssignmentsWriteScope = "assignments.write"
+
+// AssignmentHandler exposes the coordinator assignment optimiser.
+type AssignmentHandler struct {
+	assignmentUC *usecase.AssignmentUsecase
+}
+
+// NewAssignmentHandler constructs the handler.
+func NewAssignmentHandler(assignmentUC *usecase.AssignmentUsecase) *AssignmentHandler {
+	return &AssignmentHandler{assignmentUC: assignmentUC}
+}
+
+type proposeAssignmentsRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// CreateProposal runs the optimiser for the requested date range.
+func (h *AssignmentHandler) CreateProposal(c *gin.Context) {
+	var req proposeAssignmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "from must be YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "to must be YYYY-MM-DD")
+		return
+	}
+
+	proposal, err := h.assignmentUC.ProposeAssignments(c, requesterID, from, to)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": assignmentProposalToResponse(proposal)})
+}
+
+// GetProposal returns a stored proposal.
+func (h *AssignmentHandler) GetProposal(c *gin.Context) {
+	proposal, err := h.assignmentUC.GetProposal(c, c.Param("proposalID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": assignmentProposalToResponse(proposal)})
+}
+
+// ApplyProposal assigns all proposed visits in one call.
+func (h *AssignmentHandler) ApplyProposal(c *gin.Context) {
+	proposal, err := h.assignmentUC.ApplyProposal(c, c.Param("proposalID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": assignmentProposalToResponse(proposal)})
+}
+
+func assignmentProposalToResponse(p domain.AssignmentProposal) gin.H {
+	assignments := make([]gin.H, 0, len(p.Assignments))
+	for _, a := range p.Assignments {
+		assignments = append(assignments, gin.H{
+			"schedule_id":    a.ScheduleID,
+			"caregiver_id":   a.CaregiverID,
+			"caregiver_name": a.CaregiverName,
+			"start_time":     a.StartTime,
+			"score":          a.Score,
+			"travel_km":      a.TravelKm,
+			"reasons":        a.Reasons,
+		})
+	}
+	unassigned := make([]gin.H, 0, len(p.Unassigned))
+	for _, u := range p.Unassigned {
+		unassigned = append(unassigned, gin.H{
+			"schedule_id": u.ScheduleID,
+			"start_time":  u.StartTime,
+			"reasons":     u.Reasons,
+		})
+	}
+	return gin.H{
+		"id":          p.ID,
+		"from":        p.From,
+		"to":          p.To,
+		"status":      p.Status,
+		"assignments": assignments,
+		"unassigned":  unassigned,
+		"created_by":  p.CreatedBy,
+		"created_at":  p.CreatedAt,
+		"applied_at":  p.AppliedAt,
+	}
+}
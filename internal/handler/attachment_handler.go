@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is room for the form fields and boundaries around the file.
+const multipartOverhead = 64 << 10
+
+// AttachmentHandler exposes photo and document uploads on visits.
+type AttachmentHandler struct {
+	attachmentUC *usecase.AttachmentUsecase
+}
+
+// NewAttachmentHandler constructs the handler.
+func NewAttachmentHandler(attachmentUC *usecase.AttachmentUsecase) *AttachmentHandler {
+	return &AttachmentHandler{attachmentUC: attachmentUC}
+}
+
+// Upload stores the multipart "file" field on the visit, or on the task named
+// by "task_id", with an optional "caption".
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	scheduleID := c.Param("scheduleID")
+	if !isUUID(scheduleID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+
+	maxBytes := h.attachmentUC.MaxUploadBytes()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, domain.ErrValidationFailure, "file is too large")
+			return
+		}
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "missing file")
+		return
+	}
+	if header.Size > maxBytes {
+		respondError(c, http.StatusRequestEntityTooLarge, domain.ErrValidationFailure, "file is too large")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	upload := usecase.AttachmentUpload{
+		ScheduleID: scheduleID,
+		FileName:   header.Filename,
+		Data:       data,
+	}
+	if taskID := strings.TrimSpace(c.PostForm("task_id")); taskID != "" {
+		if !isUUID(taskID) {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "invalid task_id")
+			return
+		}
+		upload.TaskID = &taskID
+	}
+	if caption, ok := c.GetPostForm("caption"); ok {
+		upload.Caption = &caption
+	}
+
+	view, err := h.attachmentUC.Upload(c, caregiverID, upload)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": attachmentToResponse(view)})
+}
+
+// List returns the visit's attachments with download links.
+func (h *AttachmentHandler) List(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	scheduleID := c.Param("scheduleID")
+	if !isUUID(scheduleID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+	views, err := h.attachmentUC.List(c, caregiverID, scheduleID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	data := make([]gin.H, len(views))
+	for i, view := range views {
+		data[i] = attachmentToResponse(view)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// SignURL issues a fresh download link (?variant=original|thumbnail).
+func (h *AttachmentHandler) SignURL(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	attachmentID := c.Param("attachmentID")
+	if !isUUID(attachmentID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+	variant := domain.AttachmentVariant(c.DefaultQuery("variant", string(domain.AttachmentOriginal)))
+	signed, err := h.attachmentUC.SignURL(c, caregiverID, attachmentID, variant)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": signedURLToResponse(signed)})
+}
+
+// Content serves the file behind a signed link; it needs no bearer token so
+// links work in image tags and downloads.
+func (h *AttachmentHandler) Content(c *gin.Context) {
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusForbidden, domain.ErrForbidden, "invalid link")
+		return
+	}
+	attachment, body, err := h.attachmentUC.Open(c, usecase.AttachmentLink{
+		AttachmentID: c.Param("attachmentID"),
+		Variant:      domain.AttachmentVariant(c.Query("variant")),
+		CaregiverID:  c.Query("caregiver"),
+		Expires:      expires,
+		Signature:    c.Query("signature"),
+	})
+	if err != nil {
+		if err == domain.ErrForbidden {
+			respondError(c, http.StatusForbidden, err, "link is invalid or expired")
+			return
+		}
+		handleDomainError(c, err)
+		return
+	}
+	defer body.Close()
+
+	contentType := attachment.ContentType
+	if domain.AttachmentVariant(c.Query("variant")) == domain.AttachmentThumbnail {
+		contentType = "image/jpeg"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, max-age="+strconv.FormatInt(max(expires-time.Now().Unix(), 0), 10))
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, body)
+}
+
+func attachmentToResponse(view usecase.AttachmentView) gin.H {
+	a := view.Attachment
+	var thumbnail gin.H
+	if view.ThumbnailURL != nil {
+		thumbnail = signedURLToResponse(*view.ThumbnailURL)
+	}
+	return gin.H{
+		"id":            a.ID,
+		"schedule_id":   a.ScheduleID,
+		"task_id":       a.TaskID,
+		"uploaded_by":   a.UploadedBy,
+		"file_name":     a.FileName,
+		"content_type":  a.ContentType,
+		"size_bytes":    a.SizeBytes,
+		"sha256":        a.SHA256,
+		"caption":       a.Caption,
+		"created_at":    a.CreatedAt,
+		"url":           signedURLToResponse(view.URL),
+		"thumbnail_url": thumbnail,
+	}
+}
+
+func signedURLToResponse(signed usecase.SignedURL) gin.H {
+	return gin.H{
+		"url":        signed.URL,
+		"expires_at": signed.ExpiresAt,
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -75,6 +76,57 @@ func (h *CaregiverAttendanceHandler) ClockOut(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": caregiverLogToResponse(log)})
 }
 
+type breakStartRequest struct {
+	attendanceClockRequest
+	BreakType string `json:"break_type" binding:"required"`
+}
+
+// StartBreak records the start of a meal or rest break.
+func (h *CaregiverAttendanceHandler) StartBreak(c *gin.Context) {
+	var req breakStartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	log, err := h.attendanceUC.StartBreak(c, caregiverID, domain.BreakType(req.BreakType), *req.Latitude, *req.Longitude, req.Notes)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": caregiverLogToResponse(log)})
+}
+
+// EndBreak records the end of the running break.
+func (h *CaregiverAttendanceHandler) EndBreak(c *gin.Context) {
+	var req attendanceClockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	log, err := h.attendanceUC.EndBreak(c, caregiverID, *req.Latitude, *req.Longitude, req.Notes)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": caregiverLogToResponse(log)})
+}
+
 // GetTodayStatus returns today's attendance status for the authenticated caregiver.
 func (h *CaregiverAttendanceHandler) GetTodayStatus(c *gin.Context) {
 	caregiverID, ok := caregiverID(c)
@@ -92,7 +144,9 @@ func (h *CaregiverAttendanceHandler) GetTodayStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": todayAttendanceStatusToResponse(status)})
 }
 
-// GetAttendanceHistory returns attendance history for the caregiver.
+// GetAttendanceHistory returns attendance history for the caregiver. The
+// default summary view pages individual logs by offset; view=detailed returns
+// full logs paired into shift sessions, grouped by day and paged by cursor.
 func (h *CaregiverAttendanceHandler) GetAttendanceHistory(c *gin.Context) {
 	caregiverID, ok := caregiverID(c)
 	if !ok {
@@ -100,23 +154,125 @@ func (h *CaregiverAttendanceHandler) GetAttendanceHistory(c *gin.Context) {
 		return
 	}
 
+	filter := historyFilter(c)
+	if filter.After, ok = cursorParam(c); !ok {
+		return
+	}
+
+	switch c.DefaultQuery("view", "summary") {
+	case "summary":
+	case "detailed":
+		h.detailedHistory(c, caregiverID, filter)
+		return
+	default:
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "view must be summary or detailed")
+		return
+	}
+
+	history, page, err := h.attendanceUC.GetAttendanceHistory(c, caregiverID, filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	total, err := h.attendanceUC.CountAttendanceHistory(c, caregiverID, filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	result := make([]gin.H, len(history))
+	for i, item := range history {
+		result[i] = caregiverLogSummaryToResponse(item)
+	}
+
+	pagination := pageInfoResponse(page)
+	pagination["total"] = total
+	pagination["limit"] = filter.Limit
+	pagination["offset"] = filter.Offset
+	c.JSON(http.StatusOK, gin.H{
+		"data":       result,
+		"pagination": pagination,
+	})
+}
+
+func (h *CaregiverAttendanceHandler) detailedHistory(c *gin.Context, caregiverID string, filter repository.CaregiverLogFilter) {
+	page, err := h.attendanceUC.GetDetailedHistory(c, caregiverID, filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	days := make([]gin.H, 0, len(page.Days))
+	for _, day := range page.Days {
+		sessions := make([]gin.H, 0, len(day.Sessions))
+		for _, session := range day.Sessions {
+			sessions = append(sessions, sessionDetailToResponse(session))
+		}
+		days = append(days, gin.H{
+			"date":           day.Date.Format("2006-01-02"),
+			"sessions":       sessions,
+			"worked_minutes": day.WorkedMinutes,
+			"break_minutes":  day.BreakMinutes,
+		})
+	}
+
+	pagination := pageInfoResponse(page.PageInfo)
+	pagination["total"] = page.TotalSessions
+	c.JSON(http.StatusOK, gin.H{
+		"data": days,
+		"totals": gin.H{
+			"worked_minutes": page.WorkedMinutes,
+			"break_minutes":  page.BreakMinutes,
+		},
+		"pagination": pagination,
+		"as_of":      page.AsOf,
+	})
+}
+
+// ExportAttendanceHistory downloads the caregiver's shift sessions between
+// start_date and end_date as CSV.
+func (h *CaregiverAttendanceHandler) ExportAttendanceHistory(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	filter := historyFilter(c)
+	if filter.StartDate == nil || filter.EndDate == nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "start_date and end_date are required")
+		return
+	}
+
+	payload, err := h.attendanceUC.ExportAttendanceHistory(c, caregiverID, filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	filename := fmt.Sprintf("attendance-%s-%s.csv", filter.StartDate.Format("20060102"), filter.EndDate.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/csv", payload)
+}
+
+// historyFilter reads the history query parameters; invalid values are ignored.
+func historyFilter(c *gin.Context) repository.CaregiverLogFilter {
 	filter := repository.CaregiverLogFilter{}
-	
+
 	// Parse log type
 	if logTypeStr := c.Query("log_type"); logTypeStr != "" {
 		logType := domain.LogType(logTypeStr)
-		if logType == domain.LogTypeClockIn || logType == domain.LogTypeClockOut {
+		if logType.Valid() {
 			filter.LogType = &logType
 		}
 	}
-	
+
 	// Parse date range
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
 		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
 			filter.StartDate = &startDate
 		}
 	}
-	
+
 	if endDateStr := c.Query("end_date"); endDateStr != "" {
 		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
 			filter.EndDate = &endDate
@@ -129,25 +285,14 @@ func (h *CaregiverAttendanceHandler) GetAttendanceHistory(c *gin.Context) {
 			filter.Limit = limit
 		}
 	}
-	
+
 	if offsetStr := c.Query("offset"); offsetStr != "" {
 		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
 			filter.Offset = offset
 		}
 	}
 
-	history, err := h.attendanceUC.GetAttendanceHistory(c, caregiverID, filter)
-	if err != nil {
-		handleDomainError(c, err)
-		return
-	}
-
-	result := make([]gin.H, len(history))
-	for i, item := range history {
-		result[i] = caregiverLogSummaryToResponse(item)
-	}
-
-	c.JSON(http.StatusOK, gin.H{"data": result})
+	return filter
 }
 
 func caregiverLogToResponse(log domain.CaregiverLog) gin.H {
@@ -155,6 +300,7 @@ func caregiverLogToResponse(log domain.CaregiverLog) gin.H {
 		"id":           log.ID,
 		"caregiver_id": log.CaregiverID,
 		"log_type":     log.LogType,
+		"break_type":   log.BreakType,
 		"latitude":     log.Latitude,
 		"longitude":    log.Longitude,
 		"timestamp":    log.Timestamp,
@@ -173,6 +319,29 @@ func caregiverLogSummaryToResponse(log domain.CaregiverLogSummary) gin.H {
 }
 
 func todayAttendanceStatusToResponse(status usecase.TodayAttendanceStatus) gin.H {
+	sessions := make([]gin.H, 0, len(status.Sessions))
+	for _, session := range status.Sessions {
+		item := gin.H{
+			"clock_in_at":    session.ClockIn.Timestamp,
+			"clock_in_lat":   session.ClockIn.Latitude,
+			"clock_in_long":  session.ClockIn.Longitude,
+			"clock_out_at":   nil,
+			"clock_out_lat":  nil,
+			"clock_out_long": nil,
+			"open":           session.Open(),
+			"worked_minutes": session.WorkedMins,
+			"break_minutes":  session.BreakMins,
+			"missed_break":   session.MissedBreak,
+			"breaks":         breaksToResponse(session.Breaks),
+		}
+		if session.ClockOut != nil {
+			item["clock_out_at"] = session.ClockOut.Timestamp
+			item["clock_out_lat"] = session.ClockOut.Latitude
+			item["clock_out_long"] = session.ClockOut.Longitude
+		}
+		sessions = append(sessions, item)
+	}
+
 	return gin.H{
 		"caregiver_id":    status.CaregiverID,
 		"date":            status.Date,
@@ -184,5 +353,49 @@ func todayAttendanceStatusToResponse(status usecase.TodayAttendanceStatus) gin.H
 		"clock_out_at":    status.ClockOutAt,
 		"clock_out_lat":   status.ClockOutLat,
 		"clock_out_long":  status.ClockOutLong,
+
+		"on_shift":             status.OnShift,
+		"sessions":             sessions,
+		"total_worked_minutes": status.TotalWorkedMinutes,
+
+		"on_break":            status.OnBreak,
+		"total_break_minutes": status.TotalBreakMinutes,
+		"missed_breaks":       status.MissedBreaks,
+
+		"visit_minutes":     status.VisitMinutes,
+		"non_visit_minutes": status.NonVisitMinutes,
+		"utilisation":       status.Utilisation,
+	}
+}
+
+func sessionDetailToResponse(session usecase.SessionSummary) gin.H {
+	var clockOut gin.H
+	if session.ClockOut != nil {
+		clockOut = caregiverLogToResponse(*session.ClockOut)
+	}
+	return gin.H{
+		"clock_in":       caregiverLogToResponse(session.ClockIn),
+		"clock_out":      clockOut,
+		"open":           session.Open(),
+		"worked_minutes": session.WorkedMins,
+		"break_minutes":  session.BreakMins,
+		"missed_break":   session.MissedBreak,
+		"breaks":         breaksToResponse(session.Breaks),
+	}
+}
+
+func breaksToResponse(breaks []domain.AttendanceBreak) []gin.H {
+	result := make([]gin.H, 0, len(breaks))
+	for _, b := range breaks {
+		var endedAt *time.Time
+		if b.End != nil {
+			endedAt = &b.End.Timestamp
+		}
+		result = append(result, gin.H{
+			"break_type": b.Start.BreakType,
+			"started_at": b.Start.Timestamp,
+			"ended_at":   endedAt,
+		})
 	}
+	return result
 }
\ No newline at end of file
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// SupervisorScope grants the agency-wide operations dashboard and incident
+// resolution across all caregivers.
+const SupervisorScope = "supervisor"
+
+// DashboardHandler exposes the supervisor operations dashboard.
+type DashboardHandler struct {
+	dashboardUC *usecase.DashboardUsecase
+}
+
+// NewDashboardHandler constructs the handler.
+func NewDashboardHandler(dashboardUC *usecase.DashboardUsecase) *DashboardHandler {
+	return &DashboardHandler{dashboardUC: dashboardUC}
+}
+
+// Summary returns the day's visit counts, open incidents and caregivers on shift.
+func (h *DashboardHandler) Summary(c *gin.Context) {
+	scope, ok := dashboardScope(c)
+	if !ok {
+		return
+	}
+	summary, err := h.dashboardUC.Summary(c, scope)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"date":                summary.Date.Format("2006-01-02"),
+		"visits":              summary.Visits,
+		"in_progress":         summary.InProgress,
+		"upcoming":            summary.Upcoming,
+		"late":                summary.Late,
+		"missed":              summary.Missed,
+		"completed":           summary.Completed,
+		"cancelled":           summary.Cancelled,
+		"open_incidents":      summary.OpenIncidents,
+		"caregivers_on_shift": summary.CaregiversOnShift,
+		"caregivers_on_break": summary.CaregiversOnBreak,
+	}})
+}
+
+// LiveVisits lists visits in progress.
+func (h *DashboardHandler) LiveVisits(c *gin.Context) {
+	h.visits(c, domain.DashboardVisitLive)
+}
+
+// UnstartedVisits lists the day's visits nobody has clocked in to yet.
+func (h *DashboardHandler) UnstartedVisits(c *gin.Context) {
+	h.visits(c, domain.DashboardVisitUnstarted)
+}
+
+// MissedVisits lists the day's missed visits.
+func (h *DashboardHandler) MissedVisits(c *gin.Context) {
+	h.visits(c, domain.DashboardVisitMissed)
+}
+
+func (h *DashboardHandler) visits(c *gin.Context, state domain.DashboardVisitState) {
+	scope, ok := dashboardScope(c)
+	if !ok {
+		return
+	}
+	visits, err := h.dashboardUC.Visits(c, scope, state)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	resp := make([]gin.H, 0, len(visits))
+	for _, v := range visits {
+		resp = append(resp, gin.H{
+			"schedule_id":    v.ScheduleID,
+			"caregiver_id":   v.CaregiverID,
+			"caregiver_name": v.CaregiverName,
+			"region":         v.Region,
+			"team":           v.Team,
+			"client_id":      v.ClientID,
+			"client_name":    v.ClientName,
+			"service_name":   v.ServiceName,
+			"start_time":     v.StartTime,
+			"end_time":       v.EndTime,
+			"status":         v.Status,
+			"clock_in_at":    v.ClockInAt,
+			"late":           v.Late,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// Caregivers returns each caregiver's attendance and visit progress for the day.
+func (h *DashboardHandler) Caregivers(c *gin.Context) {
+	scope, ok := dashboardScope(c)
+	if !ok {
+		return
+	}
+	statuses, err := h.dashboardUC.Caregivers(c, scope)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	resp := make([]gin.H, 0, len(statuses))
+	for _, s := range statuses {
+		resp = append(resp, gin.H{
+			"caregiver_id":     s.CaregiverID,
+			"name":             s.Name,
+			"region":           s.Region,
+			"team":             s.Team,
+			"attendance":       s.Attendance,
+			"last_event_at":    s.LastEventAt,
+			"current_visit_id": s.CurrentVisitID,
+			"next_visit_at":    s.NextVisitAt,
+			"visits":           s.Visits,
+			"completed":        s.Completed,
+			"missed":           s.Missed,
+			"open_incidents":   s.OpenIncidents,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// OpenIncidents lists unresolved incidents, most severe first.
+func (h *DashboardHandler) OpenIncidents(c *gin.Context) {
+	scope, ok := dashboardScope(c)
+	if !ok {
+		return
+	}
+	incidents, err := h.dashboardUC.OpenIncidents(c, scope)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	resp := make([]gin.H, 0, len(incidents))
+	for _, incident := range incidents {
+		resp = append(resp, incidentToResponse(incident))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// dashboardScope reads the region, team and date filters, responding 400 on
+// a malformed date.
+func dashboardScope(c *gin.Context) (usecase.DashboardScope, bool) {
+	scope := usecase.DashboardScope{
+		Region: c.Query("region"),
+		Team:   c.Query("team"),
+	}
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "date must be YYYY-MM-DD")
+			return usecase.DashboardScope{}, false
+		}
+		scope.Date = &parsed
+	}
+	return scope, true
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/events"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// EventsHandler streams domain events to clients as server-sent events.
+type EventsHandler struct {
+	bus       *events.Bus
+	heartbeat time.Duration
+}
+
+// NewEventsHandler constructs the handler. Idle streams receive a comment
+// every heartbeat so proxies keep the connection open.
+func NewEventsHandler(bus *events.Bus, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{bus: bus, heartbeat: heartbeat}
+}
+
+// Stream pushes events as they happen until the client disconnects.
+// Caregivers receive their own events; supervisors receive everyone's and
+// may narrow them with caregiver_id. types takes a comma separated list.
+func (h *EventsHandler) Stream(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	caregiver := c.Query("caregiver_id")
+	if !middleware.HasScope(c, SupervisorScope) {
+		if caregiver != "" && caregiver != requesterID {
+			handleDomainError(c, domain.ErrForbidden)
+			return
+		}
+		caregiver = requesterID
+	} else if caregiver != "" && !isUUID(caregiver) {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "caregiver_id must be a UUID")
+		return
+	}
+
+	var types map[domain.EventType]bool
+	if raw := c.Query("types"); raw != "" {
+		types = map[domain.EventType]bool{}
+		for _, name := range strings.Split(raw, ",") {
+			eventType := domain.EventType(strings.TrimSpace(name))
+			if !eventType.Valid() {
+				respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("unknown event type %q", eventType))
+				return
+			}
+			types[eventType] = true
+		}
+	}
+
+	stream, unsubscribe := h.bus.Subscribe(func(event domain.Event) bool {
+		if caregiver != "" && event.CaregiverID != caregiver {
+			return false
+		}
+		return types == nil || types[event.Type]
+	})
+	defer unsubscribe()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			w.Flush()
+		case event, open := <-stream:
+			if !open {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+			w.Flush()
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// EVVExportScope grants listing visits for EVV and creating and downloading exports.
+const EVVExportScope = "evv.export"
+
+// EVVHandler exposes Electronic Visit Verification exports.
+type EVVHandler struct {
+	evvUC *usecase.EVVUsecase
+}
+
+// NewEVVHandler constructs the handler.
+func NewEVVHandler(evvUC *usecase.EVVUsecase) *EVVHandler {
+	return &EVVHandler{evvUC: evvUC}
+}
+
+type createEVVExportRequest struct {
+	From   string `json:"from" binding:"required"`
+	To     string `json:"to" binding:"required"`
+	Format string `json:"format"`
+}
+
+// ListVisits returns completed visits in a date range with their EVV status.
+func (h *EVVHandler) ListVisits(c *gin.Context) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "from must be YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "to must be YYYY-MM-DD")
+		return
+	}
+
+	visits, err := h.evvUC.ListVisits(c, from, to)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	resp := make([]gin.H, 0, len(visits))
+	for _, v := range visits {
+		resp = append(resp, evvVisitToResponse(v))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// CreateExport generates a batch file for the requested date range.
+func (h *EVVHandler) CreateExport(c *gin.Context) {
+	var req createEVVExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "from must be YYYY-MM-DD")
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "to must be YYYY-MM-DD")
+		return
+	}
+	format := domain.EVVFormat(req.Format)
+	if format == "" {
+		format = domain.EVVFormatCSV
+	}
+	if format != domain.EVVFormatCSV && format != domain.EVVFormatJSON {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "format must be csv or json")
+		return
+	}
+
+	batch, err := h.evvUC.CreateExport(c, requesterID, from, to, format)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": evvBatchToResponse(batch)})
+}
+
+// GetExport returns batch metadata.
+func (h *EVVHandler) GetExport(c *gin.Context) {
+	batch, err := h.evvUC.GetBatch(c, c.Param("batchID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": evvBatchToResponse(batch)})
+}
+
+// DownloadExport streams the generated batch file.
+func (h *EVVHandler) DownloadExport(c *gin.Context) {
+	batch, err := h.evvUC.GetBatch(c, c.Param("batchID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	contentType := "text/csv"
+	if batch.Format == domain.EVVFormatJSON {
+		contentType = "application/json"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="evv-%s.%s"`, batch.ID, batch.Format))
+	c.Data(http.StatusOK, contentType, batch.Payload)
+}
+
+func evvVisitToResponse(v usecase.EVVVisitReview) gin.H {
+	issues := v.Issues
+	if issues == nil {
+		issues = []string{}
+	}
+	return gin.H{
+		"schedule_id":           v.Visit.ScheduleID,
+		"service_code":          v.Visit.ServiceCode,
+		"service_name":          v.Visit.ServiceName,
+		"client_id":             v.Visit.ClientID,
+		"client_name":           v.Visit.ClientName,
+		"client_medicaid_id":    v.Visit.ClientMedicaidID,
+		"caregiver_id":          v.Visit.CaregiverID,
+		"caregiver_name":        v.Visit.CaregiverName,
+		"caregiver_employee_id": v.Visit.CaregiverEmployeeID,
+		"clock_in_at":           v.Visit.ClockInAt,
+		"clock_out_at":          v.Visit.ClockOutAt,
+		"manually_edited":       v.Visit.ManuallyEdited,
+		"signoff_required":      v.Visit.SignoffRequired,
+		"client_signoff_method": v.Visit.SignoffMethod,
+		"client_signoff_at":     v.Visit.SignoffAt,
+		"status":                v.Status,
+		"last_submitted_at":     v.Visit.LastSubmittedAt,
+		"issues":                issues,
+	}
+}
+
+func evvBatchToResponse(b domain.EVVBatch) gin.H {
+	resp := gin.H{
+		"id":             b.ID,
+		"from":           b.From,
+		"to":             b.To,
+		"format":         b.Format,
+		"record_count":   b.RecordCount,
+		"rejected_count": b.RejectedCount,
+		"created_by":     b.CreatedBy,
+		"created_at":     b.CreatedAt,
+	}
+	if b.Rejected != nil {
+		rejected := make([]gin.H, 0, len(b.Rejected))
+		for _, r := range b.Rejected {
+			rejected = append(rejected, gin.H{
+				"schedule_id": r.ScheduleID,
+				"issues":      r.Issues,
+			})
+		}
+		resp["rejected"] = rejected
+	}
+	return resp
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// FamilyManageScope grants managing family members and client consent.
+const FamilyManageScope = "family.manage"
+
+// FamilyHandler exposes the read-only family portal and its administration.
+type FamilyHandler struct {
+	authUC   *usecase.AuthUsecase
+	familyUC *usecase.FamilyUsecase
+}
+
+// NewFamilyHandler constructs the handler.
+func NewFamilyHandler(authUC *usecase.AuthUsecase, familyUC *usecase.FamilyUsecase) *FamilyHandler {
+	return &FamilyHandler{authUC: authUC, familyUC: familyUC}
+}
+
+type familyTokenRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type addFamilyMemberRequest struct {
+	Name         string `json:"name" binding:"required"`
+	Email        string `json:"email" binding:"required"`
+	Relationship string `json:"relationship"`
+}
+
+type updateFamilyMemberRequest struct {
+	Active *bool `json:"active" binding:"required"`
+}
+
+type updateFamilyConsentRequest struct {
+	ShareVisits        *bool `json:"share_visits"`
+	ShareTasks         *bool `json:"share_tasks"`
+	ShareConfirmations *bool `json:"share_confirmations"`
+}
+
+// Token signs a family member in and issues a family portal access token.
+func (h *FamilyHandler) Token(c *gin.Context) {
+	var req familyTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	pair, member, err := h.authUC.IssueFamilyToken(c, req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) {
+			respondError(c, http.StatusUnauthorized, err, "invalid email or password")
+			return
+		}
+		handleDomainError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  pair.AccessToken,
+		"token_type":    pair.TokenType,
+		"expires_in":    pair.ExpiresIn,
+		"family_member": familyMemberToResponse(member),
+	})
+}
+
+// Me returns the signed-in family member and what their client shares.
+func (h *FamilyHandler) Me(c *gin.Context) {
+	memberID, ok := familyMemberID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	member, consent, err := h.familyUC.Me(c, memberID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"family_member": familyMemberToResponse(member),
+		"client":        gin.H{"id": member.ClientID, "name": member.ClientName},
+		"sharing":       familySharingToResponse(consent),
+	}})
+}
+
+// ListVisits returns the client's visits: ?when=upcoming (default) or past.
+func (h *FamilyHandler) ListVisits(c *gin.Context) {
+	memberID, ok := familyMemberID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	var upcoming bool
+	switch c.DefaultQuery("when", "upcoming") {
+	case "upcoming":
+		upcoming = true
+	case "past":
+		upcoming = false
+	default:
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "when must be upcoming or past")
+		return
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	visits, err := h.familyUC.ListVisits(c, memberID, upcoming, limit)
+	if err != nil {
+		handleFamilyError(c, err)
+		return
+	}
+	resp := make([]gin.H, 0, len(visits))
+	for _, v := range visits {
+		resp = append(resp, familyVisitToResponse(v))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetVisit returns one of the client's visits.
+func (h *FamilyHandler) GetVisit(c *gin.Context) {
+	memberID, ok := familyMemberID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	scheduleID := c.Param("scheduleID")
+	if !isUUID(scheduleID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+
+	visit, err := h.familyUC.GetVisit(c, memberID, scheduleID)
+	if err != nil {
+		handleFamilyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": familyVisitToResponse(visit)})
+}
+
+// ListMembers returns the client's family members.
+func (h *FamilyHandler) ListMembers(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	members, err := h.familyUC.ListMembers(c, clientID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	resp := make([]gin.H, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, familyMemberToResponse(m))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// AddMember gives a family member portal access; the password is only shown here.
+func (h *FamilyHandler) AddMember(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var req addFamilyMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	member, password, err := h.familyUC.AddMember(c, clientID, usecase.FamilyMemberInput{
+		Name:         req.Name,
+		Email:        req.Email,
+		Relationship: req.Relationship,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	resp := familyMemberToResponse(member)
+	resp["password"] = password
+	c.JSON(http.StatusCreated, gin.H{"data": resp})
+}
+
+// UpdateMember grants or withdraws a family member's access.
+func (h *FamilyHandler) UpdateMember(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var req updateFamilyMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	memberID := c.Param("memberID")
+	if !isUUID(memberID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+
+	member, err := h.familyUC.SetMemberActive(c, clientID, memberID, *req.Active)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": familyMemberToResponse(member)})
+}
+
+// GetConsent returns what the client shares with their family.
+func (h *FamilyHandler) GetConsent(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	consent, err := h.familyUC.GetConsent(c, clientID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": familyConsentToResponse(consent)})
+}
+
+// UpdateConsent changes the consent flags present in the body.
+func (h *FamilyHandler) UpdateConsent(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var req updateFamilyConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	updaterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	consent, err := h.familyUC.UpdateConsent(c, clientID, updaterID, usecase.FamilyConsentUpdate{
+		ShareVisits:        req.ShareVisits,
+		ShareTasks:         req.ShareTasks,
+		ShareConfirmations: req.ShareConfirmations,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": familyConsentToResponse(consent)})
+}
+
+// familyMemberID extracts the family member id from the request context.
+func familyMemberID(c *gin.Context) (string, bool) {
+	val, ok := c.Get(middleware.ContextFamilyMemberIDKey)
+	if !ok {
+		return "", false
+	}
+	id, ok := val.(string)
+	return id, ok
+}
+
+// familyClientID reads the :clientID path parameter, responding 404 when it
+// cannot name a client.
+func familyClientID(c *gin.Context) (string, bool) {
+	clientID := c.Param("clientID")
+	if !isUUID(clientID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return "", false
+	}
+	return clientID, true
+}
+
+// handleFamilyError explains a refusal caused by the client's consent.
+func handleFamilyError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrForbidden) {
+		respondError(c, http.StatusForbidden, err, "the client has not agreed to share visits, or your access was withdrawn")
+		return
+	}
+	handleDomainError(c, err)
+}
+
+func familyMemberToResponse(m domain.FamilyMember) gin.H {
+	return gin.H{
+		"id":           m.ID,
+		"client_id":    m.ClientID,
+		"name":         m.Name,
+		"email":        m.Email,
+		"relationship": m.Relationship,
+		"active":       m.Active,
+		"created_at":   m.CreatedAt,
+	}
+}
+
+func familySharingToResponse(consent domain.FamilyConsent) gin.H {
+	return gin.H{
+		"visits":        consent.ShareVisits,
+		"tasks":         consent.ShareTasks,
+		"confirmations": consent.ShareConfirmations,
+	}
+}
+
+func familyConsentToResponse(consent domain.FamilyConsent) gin.H {
+	return gin.H{
+		"client_id":           consent.ClientID,
+		"share_visits":        consent.ShareVisits,
+		"share_tasks":         consent.ShareTasks,
+		"share_confirmations": consent.ShareConfirmations,
+		"updated_by":          consent.UpdatedBy,
+		"updated_at":          consent.UpdatedAt,
+	}
+}
+
+func familyVisitToResponse(v domain.FamilyVisit) gin.H {
+	resp := gin.H{
+		"id":             v.ScheduleID,
+		"service_name":   v.ServiceName,
+		"caregiver_name": v.CaregiverName,
+		"start_time":     v.StartTime,
+		"end_time":       v.EndTime,
+		"status":         v.Status,
+	}
+	if v.Tasks != nil {
+		tasks := make([]gin.H, 0, len(v.Tasks))
+		for _, t := range v.Tasks {
+			tasks = append(tasks, gin.H{"title": t.Title, "completed_at": t.CompletedAt})
+		}
+		resp["completed_tasks"] = tasks
+	}
+	if v.Confirmation != nil {
+		resp["confirmation"] = gin.H{
+			"clock_in_at":       v.Confirmation.ClockInAt,
+			"clock_out_at":      v.Confirmation.ClockOutAt,
+			"clock_in_at_home":  v.Confirmation.ClockInAtHome,
+			"clock_out_at_home": v.Confirmation.ClockOutAtHome,
+		}
+	}
+	return resp
+}
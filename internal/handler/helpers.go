@@ -2,9 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"regexp"
 
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
 
@@ -35,13 +37,54 @@ func handleDomainError(c *gin.Context, err error) {
 		return
 	case domain.ErrNotFound:
 		respondError(c, http.StatusNotFound, err, "")
-	case domain.ErrInvalidStatusTransition:
+	case domain.ErrInvalidStatusTransition, domain.ErrSignoffRequired, domain.ErrRecordOnMAR:
 		respondError(c, http.StatusUnprocessableEntity, err, "")
 	case domain.ErrValidationFailure:
 		respondError(c, http.StatusBadRequest, err, "")
 	case domain.ErrForbidden:
 		respondError(c, http.StatusForbidden, err, "")
+	case domain.ErrInvalidScope:
+		respondError(c, http.StatusForbidden, err, "requested scope is not granted to this client")
+	case domain.ErrConflict:
+		respondError(c, http.StatusConflict, err, "")
+	case domain.ErrSignoffPINLocked:
+		respondError(c, http.StatusLocked, err, "too many wrong PINs; a coordinator must set a new PIN for this client")
 	default:
 		respondError(c, http.StatusInternalServerError, err, "internal server error")
 	}
 }
+
+// cursorParam reads the optional cursor query parameter, responding 400 when
+// it is not a token issued by a previous page.
+func cursorParam(c *gin.Context) (*domain.Cursor, bool) {
+	token := c.Query("cursor")
+	if token == "" {
+		return nil, true
+	}
+	cursor, err := domain.ParseCursor(token)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err, "invalid cursor")
+		return nil, false
+	}
+	return &cursor, true
+}
+
+// pageInfoResponse renders where a listing page ends; next_cursor is null on
+// the last page.
+func pageInfoResponse(page usecase.PageInfo) gin.H {
+	var nextCursor *string
+	if page.HasMore {
+		nextCursor = &page.NextCursor
+	}
+	return gin.H{
+		"has_more":    page.HasMore,
+		"next_cursor": nextCursor,
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isUUID reports whether s is a textual UUID, so it can be bound to a UUID column.
+func isUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
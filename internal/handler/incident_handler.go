@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// IncidentHandler exposes incident reporting and resolution.
+type IncidentHandler struct {
+	incidentUC *usecase.IncidentUsecase
+}
+
+// NewIncidentHandler constructs the handler.
+func NewIncidentHandler(incidentUC *usecase.IncidentUsecase) *IncidentHandler {
+	return &IncidentHandler{incidentUC: incidentUC}
+}
+
+type reportIncidentRequest struct {
+	ScheduleID  string `json:"schedule_id"`
+	Category    string `json:"category" binding:"required"`
+	Severity    string `json:"severity" binding:"required"`
+	Description string `json:"description" binding:"required"`
+}
+
+type resolveIncidentRequest struct {
+	Resolution string `json:"resolution" binding:"required"`
+}
+
+// ReportIncident records an incident for the authenticated caregiver.
+func (h *IncidentHandler) ReportIncident(c *gin.Context) {
+	var req reportIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	if req.ScheduleID != "" && !isUUID(req.ScheduleID) {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "schedule_id must be a UUID")
+		return
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	incident, err := h.incidentUC.ReportIncident(c, requesterID, usecase.IncidentReport{
+		ScheduleID:  req.ScheduleID,
+		Category:    req.Category,
+		Severity:    domain.IncidentSeverity(req.Severity),
+		Description: req.Description,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": incidentToResponse(incident)})
+}
+
+// GetIncident returns an incident to its reporter or a supervisor.
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	if !isUUID(c.Param("incidentID")) {
+		handleDomainError(c, domain.ErrNotFound)
+		return
+	}
+
+	incident, err := h.incidentUC.GetIncident(c, c.Param("incidentID"), requesterID, middleware.HasScope(c, SupervisorScope))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": incidentToResponse(incident)})
+}
+
+// ResolveIncident closes an open incident with a resolution note.
+func (h *IncidentHandler) ResolveIncident(c *gin.Context) {
+	var req resolveIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	resolverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	if !isUUID(c.Param("incidentID")) {
+		handleDomainError(c, domain.ErrNotFound)
+		return
+	}
+
+	incident, err := h.incidentUC.ResolveIncident(c, c.Param("incidentID"), resolverID, req.Resolution)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": incidentToResponse(incident)})
+}
+
+func incidentToResponse(i domain.Incident) gin.H {
+	return gin.H{
+		"id":             i.ID,
+		"caregiver_id":   i.CaregiverID,
+		"caregiver_name": i.CaregiverName,
+		"region":         i.Region,
+		"team":           i.Team,
+		"schedule_id":    i.ScheduleID,
+		"client_name":    i.ClientName,
+		"category":       i.Category,
+		"severity":       i.Severity,
+		"description":    i.Description,
+		"status":         i.Status,
+		"reported_at":    i.ReportedAt,
+		"resolved_by":    i.ResolvedBy,
+		"resolved_at":    i.ResolvedAt,
+		"resolution":     i.Resolution,
+	}
+}
@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// MedicationsManageScope grants managing client medication orders and reading
+// their medication administration records.
+const MedicationsManageScope = "medications.manage"
+
+// MedicationHandler exposes medication orders and the medication
+// administration record (MAR).
+type MedicationHandler struct {
+	medicationUC *usecase.MedicationUsecase
+}
+
+// NewMedicationHandler constructs the handler.
+func NewMedicationHandler(medicationUC *usecase.MedicationUsecase) *MedicationHandler {
+	return &MedicationHandler{medicationUC: medicationUC}
+}
+
+type recordDoseRequest struct {
+	Status         domain.AdministrationStatus `json:"status" binding:"required"`
+	Reason         *string                     `json:"reason"`
+	AdministeredAt *time.Time                  `json:"administered_at"`
+}
+
+type givePRNRequest struct {
+	OrderID        string     `json:"order_id" binding:"required"`
+	Reason         *string    `json:"reason"`
+	AdministeredAt *time.Time `json:"administered_at"`
+}
+
+type createMedicationOrderRequest struct {
+	Drug         string                 `json:"drug" binding:"required"`
+	Dose         string                 `json:"dose" binding:"required"`
+	Route        domain.MedicationRoute `json:"route" binding:"required"`
+	Times        []string               `json:"times"`
+	PRN          bool                   `json:"prn"`
+	Instructions *string                `json:"instructions"`
+	StartDate    *string                `json:"start_date"`
+	EndDate      *string                `json:"end_date"`
+}
+
+type updateMedicationOrderRequest struct {
+	Instructions *string `json:"instructions"`
+	// EndDate sets the last day of the order; an empty string removes it.
+	EndDate *string `json:"end_date"`
+	Active  *bool   `json:"active"`
+}
+
+// VisitRecord returns the visit's MAR and the as-needed medications that may
+// be given.
+func (h *MedicationHandler) VisitRecord(c *gin.Context) {
+	caregiverID, scheduleID, ok := medicationVisit(c)
+	if !ok {
+		return
+	}
+	mar, err := h.medicationUC.VisitRecord(c, caregiverID, scheduleID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	doses := make([]gin.H, len(mar.Doses))
+	for i, dose := range mar.Doses {
+		doses[i] = administrationToResponse(dose)
+	}
+	prn := make([]gin.H, len(mar.PRNOrders))
+	for i, order := range mar.PRNOrders {
+		prn[i] = medicationOrderToResponse(order)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"doses":      doses,
+		"prn_orders": prn,
+	}})
+}
+
+// RecordDose records a scheduled dose as given, refused or omitted.
+func (h *MedicationHandler) RecordDose(c *gin.Context) {
+	caregiverID, scheduleID, ok := medicationVisit(c)
+	if !ok {
+		return
+	}
+	administrationID := c.Param("administrationID")
+	if !isUUID(administrationID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+	var req recordDoseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	dose, err := h.medicationUC.RecordDose(c, caregiverID, scheduleID, administrationID, usecase.DoseRecord{
+		Status:         req.Status,
+		Reason:         req.Reason,
+		AdministeredAt: req.AdministeredAt,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": administrationToResponse(dose)})
+}
+
+// GivePRN records an as-needed dose given during the visit.
+func (h *MedicationHandler) GivePRN(c *gin.Context) {
+	caregiverID, scheduleID, ok := medicationVisit(c)
+	if !ok {
+		return
+	}
+	var req givePRNRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	if !isUUID(req.OrderID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+
+	dose, err := h.medicationUC.GivePRN(c, caregiverID, scheduleID, usecase.PRNDose{
+		OrderID:        req.OrderID,
+		Reason:         req.Reason,
+		AdministeredAt: req.AdministeredAt,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": administrationToResponse(dose)})
+}
+
+// ListOrders returns the client's medication orders (?include_inactive=true
+// adds discontinued ones).
+func (h *MedicationHandler) ListOrders(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	includeInactive, _ := strconv.ParseBool(c.Query("include_inactive"))
+	orders, err := h.medicationUC.ListOrders(c, clientID, includeInactive)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	data := make([]gin.H, len(orders))
+	for i, order := range orders {
+		data[i] = medicationOrderToResponse(order)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// CreateOrder records a new medication order for the client.
+func (h *MedicationHandler) CreateOrder(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var req createMedicationOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	creatorID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	input := usecase.MedicationOrderInput{
+		Drug:         req.Drug,
+		Dose:         req.Dose,
+		Route:        req.Route,
+		Times:        req.Times,
+		PRN:          req.PRN,
+		Instructions: req.Instructions,
+	}
+	dates := []struct {
+		name   string
+		value  *string
+		target **time.Time
+	}{{"start_date", req.StartDate, &input.StartDate}, {"end_date", req.EndDate, &input.EndDate}}
+	for _, date := range dates {
+		if date.value == nil {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", *date.value)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("%s must be a YYYY-MM-DD date", date.name))
+			return
+		}
+		*date.target = &parsed
+	}
+
+	order, err := h.medicationUC.CreateOrder(c, clientID, creatorID, input)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": medicationOrderToResponse(order)})
+}
+
+// UpdateOrder changes the order's instructions, end date or active flag.
+func (h *MedicationHandler) UpdateOrder(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	orderID := c.Param("orderID")
+	if !isUUID(orderID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+	var req updateMedicationOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	update := usecase.MedicationOrderUpdate{Instructions: req.Instructions, Active: req.Active}
+	if req.EndDate != nil {
+		if *req.EndDate == "" {
+			update.ClearEndDate = true
+		} else {
+			parsed, err := time.Parse("2006-01-02", *req.EndDate)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "end_date must be a YYYY-MM-DD date")
+				return
+			}
+			update.EndDate = &parsed
+		}
+	}
+
+	order, err := h.medicationUC.UpdateOrder(c, clientID, orderID, update)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": medicationOrderToResponse(order)})
+}
+
+// ClientRecord returns the client's MAR (?from=&to= dates, &status=missed,...,
+// &limit=).
+func (h *MedicationHandler) ClientRecord(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var filter repository.MARFilter
+	dates := []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}}
+	for _, date := range dates {
+		if value := c.Query(date.name); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("%s must be a YYYY-MM-DD date", date.name))
+				return
+			}
+			*date.target = &parsed
+		}
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "from must not be after to")
+		return
+	}
+	if statusParam := c.Query("status"); statusParam != "" {
+		for _, s := range strings.Split(statusParam, ",") {
+			status := domain.AdministrationStatus(strings.ToLower(strings.TrimSpace(s)))
+			if !status.Valid() {
+				respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("unknown status %q", s))
+				return
+			}
+			filter.Status = append(filter.Status, status)
+		}
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	doses, err := h.medicationUC.ClientRecord(c, clientID, filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	data := make([]gin.H, len(doses))
+	for i, dose := range doses {
+		data[i] = administrationToResponse(dose)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// medicationVisit reads the caregiver and the :scheduleID of a visit MAR
+// request, responding when either is missing.
+func medicationVisit(c *gin.Context) (string, string, bool) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return "", "", false
+	}
+	scheduleID := c.Param("scheduleID")
+	if !isUUID(scheduleID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return "", "", false
+	}
+	return caregiverID, scheduleID, true
+}
+
+func medicationOrderToResponse(order domain.MedicationOrder) gin.H {
+	var endDate *string
+	if order.EndDate != nil {
+		formatted := order.EndDate.Format("2006-01-02")
+		endDate = &formatted
+	}
+	return gin.H{
+		"id":           order.ID,
+		"client_id":    order.ClientID,
+		"drug":         order.Drug,
+		"dose":         order.Dose,
+		"route":        order.Route,
+		"times":        order.Times,
+		"prn":          order.PRN,
+		"instructions": order.Instructions,
+		"start_date":   order.StartDate.Format("2006-01-02"),
+		"end_date":     endDate,
+		"active":       order.Active,
+		"created_at":   order.CreatedAt,
+		"updated_at":   order.UpdatedAt,
+	}
+}
+
+func administrationToResponse(dose domain.MedicationAdministration) gin.H {
+	return gin.H{
+		"id":              dose.ID,
+		"order_id":        dose.OrderID,
+		"schedule_id":     dose.ScheduleID,
+		"task_id":         dose.TaskID,
+		"medication":      dose.Label(),
+		"drug":            dose.Drug,
+		"dose":            dose.Dose,
+		"route":           dose.Route,
+		"prn":             dose.PRN,
+		"due_at":          dose.DueAt,
+		"status":          dose.Status,
+		"reason":          dose.Reason,
+		"administered_at": dose.AdministeredAt,
+		"recorded_by":     dose.RecordedBy,
+		"recorded_at":     dose.RecordedAt,
+	}
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// MileageApproveScope grants reviewing any caregiver's mileage claims.
+const MileageApproveScope = "mileage.approve"
+
+// MileageHandler exposes travel mileage and reimbursement claims.
+type MileageHandler struct {
+	mileageUC *usecase.MileageUsecase
+}
+
+// NewMileageHandler constructs the handler.
+func NewMileageHandler(mileageUC *usecase.MileageUsecase) *MileageHandler {
+	return &MileageHandler{mileageUC: mileageUC}
+}
+
+type submitMileageClaimRequest struct {
+	Date             string   `json:"date" binding:"required"`
+	ClaimedKm        *float64 `json:"claimed_km"`
+	AdjustmentReason string   `json:"adjustment_reason"`
+}
+
+type reviewMileageClaimRequest struct {
+	Comment string `json:"comment"`
+}
+
+// DailyMileage returns the computed travel between visits for ?date (default today).
+func (h *MileageHandler) DailyMileage(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	var date *time.Time
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "date must be YYYY-MM-DD")
+			return
+		}
+		date = &parsed
+	}
+
+	day, err := h.mileageUC.DailyMileage(c, requesterID, date)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	resp := gin.H{
+		"date":        day.Date.Format("2006-01-02"),
+		"legs":        mileageLegsToResponse(day.Legs),
+		"computed_km": day.ComputedKm,
+		"claim":       nil,
+	}
+	if day.Claim != nil {
+		resp["claim"] = mileageClaimToResponse(*day.Claim)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// SubmitClaim claims reimbursement for a day's travel, optionally adjusting the distance.
+func (h *MileageHandler) SubmitClaim(c *gin.Context) {
+	var req submitMileageClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "date must be YYYY-MM-DD")
+		return
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	claim, err := h.mileageUC.SubmitClaim(c, requesterID, usecase.MileageClaimRequest{
+		Date:      date,
+		ClaimedKm: req.ClaimedKm,
+		Reason:    req.AdjustmentReason,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": mileageClaimToResponse(claim)})
+}
+
+// ListClaims returns the caregiver's mileage claims.
+func (h *MileageHandler) ListClaims(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	claims, err := h.mileageUC.ListClaims(c, requesterID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mileageClaimsToResponse(claims)})
+}
+
+// GetClaim returns a mileage claim with its legs.
+func (h *MileageHandler) GetClaim(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	claim, err := h.mileageUC.GetClaim(c, c.Param("claimID"), requesterID, middleware.HasScope(c, MileageApproveScope))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mileageClaimToResponse(claim)})
+}
+
+// ListPending returns mileage claims awaiting review.
+func (h *MileageHandler) ListPending(c *gin.Context) {
+	claims, err := h.mileageUC.ListPending(c)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mileageClaimsToResponse(claims)})
+}
+
+// Approve accepts a mileage claim for payment.
+func (h *MileageHandler) Approve(c *gin.Context) {
+	h.review(c, h.mileageUC.ApproveClaim)
+}
+
+// Reject returns a mileage claim to its caregiver; a comment is required.
+func (h *MileageHandler) Reject(c *gin.Context) {
+	h.review(c, h.mileageUC.RejectClaim)
+}
+
+func (h *MileageHandler) review(c *gin.Context, decide func(ctx context.Context, claimID, reviewerID, comment string) (domain.MileageClaim, error)) {
+	var req reviewMileageClaimRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+			return
+		}
+	}
+	reviewerID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	claim, err := decide(c, c.Param("claimID"), reviewerID, req.Comment)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mileageClaimToResponse(claim)})
+}
+
+func mileageClaimsToResponse(claims []domain.MileageClaim) []gin.H {
+	resp := make([]gin.H, 0, len(claims))
+	for _, claim := range claims {
+		resp = append(resp, mileageClaimToResponse(claim))
+	}
+	return resp
+}
+
+func mileageClaimToResponse(m domain.MileageClaim) gin.H {
+	resp := gin.H{
+		"id":                m.ID,
+		"caregiver_id":      m.CaregiverID,
+		"date":              m.ClaimDate.Format("2006-01-02"),
+		"computed_km":       m.ComputedKm,
+		"claimed_km":        m.ClaimedKm,
+		"adjusted":          m.Adjusted(),
+		"adjustment_reason": m.AdjustmentReason,
+		"rate_per_km":       m.RatePerKm,
+		"amount":            m.Amount,
+		"status":            m.Status,
+		"submitted_at":      m.SubmittedAt,
+		"reviewed_by":       m.ReviewedBy,
+		"reviewed_at":       m.ReviewedAt,
+		"review_comment":    m.ReviewComment,
+	}
+	if m.Legs != nil {
+		resp["legs"] = mileageLegsToResponse(m.Legs)
+	}
+	return resp
+}
+
+func mileageLegsToResponse(legs []domain.MileageLeg) []gin.H {
+	resp := make([]gin.H, 0, len(legs))
+	for _, leg := range legs {
+		resp = append(resp, gin.H{
+			"from_schedule_id": leg.FromScheduleID,
+			"to_schedule_id":   leg.ToScheduleID,
+			"departed_at":      leg.DepartedAt,
+			"arrived_at":       leg.ArrivedAt,
+			"distance_km":      leg.DistanceKm,
+			"source":           leg.Source,
+		})
+	}
+	return resp
+}
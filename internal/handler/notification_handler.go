@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler exposes a caregiver's notification preferences and log.
+type NotificationHandler struct {
+	notificationUC *usecase.NotificationUsecase
+}
+
+// NewNotificationHandler constructs the handler.
+func NewNotificationHandler(notificationUC *usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{notificationUC: notificationUC}
+}
+
+type updateNotificationPreferencesRequest struct {
+	Channels      *[]domain.NotificationChannel `json:"channels"`
+	DisabledKinds *[]domain.NotificationKind    `json:"disabled_kinds"`
+	Phone         *string                       `json:"phone"`
+	PushToken     *string                       `json:"push_token"`
+	Email         *string                       `json:"email"`
+	QuietStart    *string                       `json:"quiet_start"`
+	QuietEnd      *string                       `json:"quiet_end"`
+}
+
+// GetPreferences returns the caregiver's notification preferences.
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	prefs, err := h.notificationUC.GetPreferences(c, requesterID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": notificationPreferencesToResponse(prefs)})
+}
+
+// UpdatePreferences changes the fields present in the body.
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req updateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	prefs, err := h.notificationUC.UpdatePreferences(c, requesterID, usecase.NotificationPreferencesUpdate{
+		Channels:      req.Channels,
+		DisabledKinds: req.DisabledKinds,
+		Phone:         req.Phone,
+		PushToken:     req.PushToken,
+		Email:         req.Email,
+		QuietStart:    req.QuietStart,
+		QuietEnd:      req.QuietEnd,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": notificationPreferencesToResponse(prefs)})
+}
+
+// ListNotifications returns the caregiver's delivery log, newest first.
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+
+	notifications, err := h.notificationUC.ListNotifications(c, requesterID, limit)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	resp := make([]gin.H, 0, len(notifications))
+	for _, n := range notifications {
+		resp = append(resp, notificationToResponse(n))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+func notificationPreferencesToResponse(p domain.NotificationPreferences) gin.H {
+	disabled := p.DisabledKinds
+	if disabled == nil {
+		disabled = []domain.NotificationKind{}
+	}
+	channels := p.Channels
+	if channels == nil {
+		channels = []domain.NotificationChannel{}
+	}
+	var quietHours gin.H
+	if p.QuietHours != nil {
+		quietHours = gin.H{
+			"start": domain.FormatClock(p.QuietHours.Start),
+			"end":   domain.FormatClock(p.QuietHours.End),
+		}
+	}
+	return gin.H{
+		"caregiver_id":   p.CaregiverID,
+		"channels":       channels,
+		"disabled_kinds": disabled,
+		"phone":          p.Phone,
+		"push_token":     p.PushToken,
+		"email":          p.Email,
+		"quiet_hours":    quietHours,
+		"updated_at":     p.UpdatedAt,
+	}
+}
+
+func notificationToResponse(n domain.Notification) gin.H {
+	return gin.H{
+		"id":          n.ID,
+		"kind":        n.Kind,
+		"channel":     n.Channel,
+		"schedule_id": n.ScheduleID,
+		"recipient":   n.Recipient,
+		"subject":     n.Subject,
+		"body":        n.Body,
+		"status":      n.Status,
+		"error":       n.Error,
+		"created_at":  n.CreatedAt,
+		"sent_at":     n.SentAt,
+	}
+}
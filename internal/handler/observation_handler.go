@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// ObservationsReadScope grants reading clients' observation trends.
+const ObservationsReadScope = "observations.read"
+
+// ObservationHandler exposes structured observations such as vital signs.
+type ObservationHandler struct {
+	observationUC *usecase.ObservationUsecase
+}
+
+// NewObservationHandler constructs the handler.
+func NewObservationHandler(observationUC *usecase.ObservationUsecase) *ObservationHandler {
+	return &ObservationHandler{observationUC: observationUC}
+}
+
+type observationReadingRequest struct {
+	Type      domain.ObservationType `json:"type" binding:"required"`
+	Value     *float64               `json:"value" binding:"required"`
+	Diastolic *float64               `json:"diastolic"`
+	Unit      string                 `json:"unit"`
+	Note      *string                `json:"note"`
+}
+
+type recordObservationsRequest struct {
+	Observations []observationReadingRequest `json:"observations" binding:"required,dive"`
+}
+
+// ListTypes describes every observation type: its unit, the other units it
+// may be recorded in, and its plausible and normal ranges.
+func (h *ObservationHandler) ListTypes(c *gin.Context) {
+	data := make([]gin.H, len(domain.ObservationTypes))
+	for i, t := range domain.ObservationTypes {
+		spec := t.Spec()
+		item := gin.H{
+			"type":            t,
+			"unit":            spec.Unit,
+			"units":           spec.Units(),
+			"integer":         spec.Integer,
+			"plausible_range": observationRangeToResponse(spec.Plausible),
+			"normal_range":    observationRangeToResponse(spec.Normal),
+		}
+		if t == domain.ObservationBloodPressure {
+			item["diastolic_plausible_range"] = observationRangeToResponse(spec.DiastolicPlausible)
+			item["diastolic_normal_range"] = observationRangeToResponse(spec.DiastolicNormal)
+		}
+		data[i] = item
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// RecordObservations stores readings taken during the caregiver's
+// in-progress visit.
+func (h *ObservationHandler) RecordObservations(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	scheduleID := c.Param("scheduleID")
+	if !isUUID(scheduleID) {
+		respondError(c, http.StatusNotFound, domain.ErrNotFound, "")
+		return
+	}
+	var req recordObservationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+
+	readings := make([]usecase.ObservationReading, len(req.Observations))
+	for i, r := range req.Observations {
+		if !r.Type.Valid() {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("unknown observation type %q", r.Type))
+			return
+		}
+		readings[i] = usecase.ObservationReading{
+			Type:      r.Type,
+			Value:     *r.Value,
+			Diastolic: r.Diastolic,
+			Unit:      r.Unit,
+			Note:      r.Note,
+		}
+	}
+
+	observations, err := h.observationUC.Record(c, caregiverID, scheduleID, readings)
+	if err == domain.ErrValidationFailure {
+		respondError(c, http.StatusBadRequest, err, "each reading must be in a unit of its type and within its plausible range (see /api/observation-types); blood pressure needs a diastolic below the systolic value")
+		return
+	}
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	data := make([]gin.H, len(observations))
+	for i, o := range observations {
+		data[i] = observationToResponse(o)
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": data})
+}
+
+// ClientTrends returns the client's observations as one time series per type
+// (?from=&to= dates, &type=blood_pressure,...).
+func (h *ObservationHandler) ClientTrends(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var query usecase.TrendQuery
+	dates := []struct {
+		name   string
+		target **time.Time
+	}{{"from", &query.From}, {"to", &query.To}}
+	for _, date := range dates {
+		if value := c.Query(date.name); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("%s must be a YYYY-MM-DD date", date.name))
+				return
+			}
+			*date.target = &parsed
+		}
+	}
+	if typeParam := c.Query("type"); typeParam != "" {
+		for _, s := range strings.Split(typeParam, ",") {
+			t := domain.ObservationType(strings.ToLower(strings.TrimSpace(s)))
+			if !t.Valid() {
+				respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, fmt.Sprintf("unknown observation type %q", s))
+				return
+			}
+			query.Types = append(query.Types, t)
+		}
+	}
+
+	trends, err := h.observationUC.ClientTrends(c, clientID, query)
+	if err == domain.ErrValidationFailure {
+		respondError(c, http.StatusBadRequest, err, "from must not be after to, and the window may span at most 366 days")
+		return
+	}
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	series := make([]gin.H, len(trends.Series))
+	for i, s := range trends.Series {
+		points := make([]gin.H, len(s.Points))
+		for j, p := range s.Points {
+			points[j] = gin.H{
+				"observation_id": p.ID,
+				"schedule_id":    p.ScheduleID,
+				"recorded_at":    p.RecordedAt,
+				"value":          p.Value,
+				"diastolic":      p.Diastolic,
+				"flag":           p.Flag,
+			}
+		}
+		item := gin.H{
+			"type":         s.Type,
+			"unit":         s.Spec.Unit,
+			"normal_range": observationRangeToResponse(s.Spec.Normal),
+			"points":       points,
+			"count":        len(s.Points),
+			"flagged":      s.Flagged,
+			"value":        observationStatsToResponse(s.Value),
+		}
+		if s.Type == domain.ObservationBloodPressure {
+			item["diastolic_normal_range"] = observationRangeToResponse(s.Spec.DiastolicNormal)
+			item["diastolic"] = observationStatsToResponse(s.Diastolic)
+		}
+		series[i] = item
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"client_id": clientID,
+		"from":      trends.From.Format("2006-01-02"),
+		"to":        trends.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		"series":    series,
+	}})
+}
+
+func observationToResponse(o domain.Observation) gin.H {
+	return gin.H{
+		"id":           o.ID,
+		"schedule_id":  o.ScheduleID,
+		"client_id":    o.ClientID,
+		"caregiver_id": o.CaregiverID,
+		"type":         o.Type,
+		"value":        o.Value,
+		"diastolic":    o.Diastolic,
+		"unit":         o.Unit,
+		"note":         o.Note,
+		"flag":         o.Flag,
+		"recorded_at":  o.RecordedAt,
+	}
+}
+
+func observationRangeToResponse(r domain.ObservationRange) gin.H {
+	return gin.H{"low": r.Low, "high": r.High}
+}
+
+func observationStatsToResponse(stats *usecase.ObservationStats) interface{} {
+	if stats == nil {
+		return nil
+	}
+	return gin.H{"min": stats.Min, "max": stats.Max, "mean": stats.Mean}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenShiftHandler exposes the open shift marketplace.
+type OpenShiftHandler struct {
+	openShiftUC *usecase.OpenShiftUsecase
+}
+
+// NewOpenShiftHandler constructs the handler.
+func NewOpenShiftHandler(openShiftUC *usecase.OpenShiftUsecase) *OpenShiftHandler {
+	return &OpenShiftHandler{openShiftUC: openShiftUC}
+}
+
+// ListOpenShifts returns unassigned visits the caregiver is eligible for.
+func (h *OpenShiftHandler) ListOpenShifts(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	var radiusKm float64
+	if radiusStr := c.Query("radius_km"); radiusStr != "" {
+		parsed, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || parsed <= 0 {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "radius_km must be a positive number")
+			return
+		}
+		radiusKm = parsed
+	}
+
+	shifts, err := h.openShiftUC.ListOpenShifts(c, caregiverID, radiusKm)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	data := make([]gin.H, 0, len(shifts))
+	for _, s := range shifts {
+		data = append(data, openShiftToResponse(s))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// ClaimOpenShift assigns the open shift to the authenticated caregiver.
+func (h *OpenShiftHandler) ClaimOpenShift(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	scheduleID := c.Param("scheduleID")
+	if scheduleID == "" {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "missing schedule id")
+		return
+	}
+
+	shift, err := h.openShiftUC.ClaimOpenShift(c, caregiverID, scheduleID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": openShiftToResponse(shift)})
+}
+
+func openShiftToResponse(shift domain.OpenShift) gin.H {
+	return gin.H{
+		"schedule_id":             shift.ScheduleID,
+		"client_id":               shift.ClientID,
+		"client_name":             shift.ClientName,
+		"service_name":            shift.ServiceName,
+		"location_name":           shift.LocationName,
+		"start_time":              shift.StartTime,
+		"end_time":                shift.EndTime,
+		"latitude":                shift.Latitude,
+		"longitude":               shift.Longitude,
+		"required_qualifications": shift.RequiredQualifications,
+		"distance_km":             shift.DistanceKm,
+	}
+}
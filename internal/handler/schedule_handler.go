@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -30,37 +33,16 @@ func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
 		return
 	}
 
-	filter := repository.ScheduleFilter{}
-	if statusParam := c.Query("status"); statusParam != "" {
-		statuses := strings.Split(statusParam, ",")
-		for _, s := range statuses {
-			filter.Status = append(filter.Status, domain.ScheduleStatus(strings.ToLower(strings.TrimSpace(s))))
-		}
-	}
-	if dateStr := c.Query("date"); dateStr != "" {
-		if parsed, err := time.Parse("2006-01-02", dateStr); err == nil {
-			filter.Date = &parsed
-		}
-	}
-	
-	// Parse pagination parameters
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			filter.Limit = limit
-		}
-	}
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-			filter.Offset = offset
-		}
+	filter, err := scheduleFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
 	}
-	
-	// Set default limit if not provided
-	if filter.Limit == 0 {
-		filter.Limit = 20
+	if filter.After, ok = cursorParam(c); !ok {
+		return
 	}
 
-	summaries, err := h.scheduleUC.ListSchedules(c, caregiverID, filter)
+	summaries, page, err := h.scheduleUC.ListSchedules(c, caregiverID, filter)
 	if err != nil {
 		handleDomainError(c, err)
 		return
@@ -69,29 +51,103 @@ func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
 	data := make([]gin.H, 0, len(summaries))
 	for _, s := range summaries {
 		data = append(data, gin.H{
-			"id":            s.ID,
-			"client_name":   s.ClientName,
-			"service_name":  s.ServiceName,
-			"start_time":    s.StartTime,
-			"end_time":      s.EndTime,
-			"status":        s.Status,
-			"location_name": s.LocationName,
+			"id":              s.ID,
+			"client_name":     s.ClientName,
+			"service_name":    s.ServiceName,
+			"start_time":      s.StartTime,
+			"end_time":        s.EndTime,
+			"status":          s.Status,
+			"location_name":   s.LocationName,
+			"manually_edited": s.ManuallyEdited,
 		})
 	}
 
 	// Include pagination info in response
+	pagination := pageInfoResponse(page)
+	pagination["limit"] = filter.Limit
+	pagination["offset"] = filter.Offset
+	pagination["hasMore"] = page.HasMore // Deprecated alias of has_more
 	response := gin.H{
-		"data": data,
-		"pagination": gin.H{
-			"limit":  filter.Limit,
-			"offset": filter.Offset,
-			"hasMore": len(data) == filter.Limit, // If we got exactly limit items, there might be more
-		},
+		"data":       data,
+		"pagination": pagination,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// maxScheduleSearch bounds the free-text search term.
+const maxScheduleSearch = 100
+
+// scheduleFilter reads the schedule listing query parameters, rejecting
+// values it cannot interpret.
+func scheduleFilter(c *gin.Context) (repository.ScheduleFilter, error) {
+	filter := repository.ScheduleFilter{
+		ClientID:    c.Query("client_id"),
+		ServiceName: strings.TrimSpace(c.Query("service_name")),
+		Search:      strings.TrimSpace(c.Query("search")),
+		Sort:        repository.ScheduleSort(c.Query("sort")),
+		// Default page size
+		Limit: 20,
+	}
+
+	if statusParam := c.Query("status"); statusParam != "" {
+		for _, s := range strings.Split(statusParam, ",") {
+			status := domain.ScheduleStatus(strings.ToLower(strings.TrimSpace(s)))
+			if !status.Valid() {
+				return filter, fmt.Errorf("unknown status %q", s)
+			}
+			filter.Status = append(filter.Status, status)
+		}
+	}
+
+	dates := []struct {
+		name   string
+		target **time.Time
+	}{{"date", &filter.Date}, {"from", &filter.From}, {"to", &filter.To}}
+	for _, date := range dates {
+		if value := c.Query(date.name); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be a YYYY-MM-DD date", date.name)
+			}
+			*date.target = &parsed
+		}
+	}
+	if filter.Date != nil && (filter.From != nil || filter.To != nil) {
+		return filter, errors.New("date cannot be combined with from or to")
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, errors.New("from must not be after to")
+	}
+
+	if filter.ClientID != "" && !isUUID(filter.ClientID) {
+		return filter, errors.New("client_id must be a UUID")
+	}
+	if len(filter.Search) > maxScheduleSearch {
+		return filter, fmt.Errorf("search must be at most %d characters", maxScheduleSearch)
+	}
+	if !filter.Sort.Valid() {
+		return filter, errors.New("sort must be status, start_time or -start_time")
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return filter, errors.New("offset must be a non-negative integer")
+		}
+		filter.Offset = offset
+	}
+
+	return filter, nil
+}
+
 // GetSchedule returns a single schedule detail.
 func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
 	caregiverID, ok := caregiverID(c)
@@ -120,6 +176,43 @@ type clockEventRequest struct {
 	Latitude  *float64 `json:"latitude" binding:"required"`
 	Longitude *float64 `json:"longitude" binding:"required"`
 	Notes     *string  `json:"notes"`
+	// Signoff is the client's acknowledgement, accepted at clock-out only.
+	Signoff *signoffRequest `json:"client_signoff"`
+}
+
+type signoffRequest struct {
+	Method     string `json:"method" binding:"required"`
+	SignerName string `json:"signer_name" binding:"required"`
+	SignerRole string `json:"signer_role"`
+	// SignatureSVG is SVG path data; SignaturePNG is a base64 PNG image.
+	SignatureSVG *string `json:"signature_svg"`
+	SignaturePNG []byte  `json:"signature_png"`
+	PIN          string  `json:"pin"`
+}
+
+// capture converts the request, leaving the checks on method and signature
+// to the usecase.
+func (r signoffRequest) capture() (*domain.SignoffCapture, bool) {
+	capture := &domain.SignoffCapture{
+		Method:     domain.SignoffMethod(r.Method),
+		SignerName: strings.TrimSpace(r.SignerName),
+		SignerRole: domain.SignerRole(r.SignerRole),
+		PIN:        r.PIN,
+	}
+	if capture.SignerRole == "" {
+		capture.SignerRole = domain.SignerRoleClient
+	}
+	switch {
+	case r.SignatureSVG != nil && r.SignaturePNG != nil:
+		return nil, false
+	case r.SignatureSVG != nil:
+		capture.SignatureFormat = domain.SignatureFormatSVG
+		capture.Signature = []byte(*r.SignatureSVG)
+	case r.SignaturePNG != nil:
+		capture.SignatureFormat = domain.SignatureFormatPNG
+		capture.Signature = r.SignaturePNG
+	}
+	return capture, true
 }
 
 // StartSchedule logs clock in event.
@@ -178,6 +271,14 @@ func (h *ScheduleHandler) EndSchedule(c *gin.Context) {
 		Longitude:  *req.Longitude,
 		Notes:      req.Notes,
 	}
+	if req.Signoff != nil {
+		capture, ok := req.Signoff.capture()
+		if !ok {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "send either signature_svg or signature_png")
+			return
+		}
+		event.Signoff = capture
+	}
 	schedule, err := h.scheduleUC.EndSchedule(c, scheduleID, caregiverID, event)
 	if err != nil {
 		handleDomainError(c, err)
@@ -196,11 +297,14 @@ func (h *ScheduleHandler) TodaySchedules(c *gin.Context) {
 		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
 		return
 	}
-	today := time.Now().Format("2006-01-02")
-	date, _ := time.Parse("2006-01-02", today)
+	date, err := h.scheduleUC.Today(c, caregiverID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
 
 	filter := repository.ScheduleFilter{Date: &date}
-	summaries, err := h.scheduleUC.ListSchedules(c, caregiverID, filter)
+	summaries, _, err := h.scheduleUC.ListSchedules(c, caregiverID, filter)
 	if err != nil {
 		handleDomainError(c, err)
 		return
@@ -215,13 +319,14 @@ func (h *ScheduleHandler) TodaySchedules(c *gin.Context) {
 	data := make([]gin.H, 0, len(summaries))
 	for _, s := range summaries {
 		data = append(data, gin.H{
-			"id":            s.ID,
-			"client_name":   s.ClientName,
-			"service_name":  s.ServiceName,
-			"start_time":    s.StartTime,
-			"end_time":      s.EndTime,
-			"status":        s.Status,
-			"location_name": s.LocationName,
+			"id":              s.ID,
+			"client_name":     s.ClientName,
+			"service_name":    s.ServiceName,
+			"start_time":      s.StartTime,
+			"end_time":        s.EndTime,
+			"status":          s.Status,
+			"location_name":   s.LocationName,
+			"manually_edited": s.ManuallyEdited,
 		})
 	}
 
@@ -231,6 +336,74 @@ func (h *ScheduleHandler) TodaySchedules(c *gin.Context) {
 	})
 }
 
+// TodayRoute returns today's visits in visiting order with travel legs.
+func (h *ScheduleHandler) TodayRoute(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+	date, err := h.scheduleUC.Today(c, caregiverID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	optimise := false
+	if optimiseStr := c.Query("optimise"); optimiseStr != "" {
+		parsed, err := strconv.ParseBool(optimiseStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "optimise must be a boolean")
+			return
+		}
+		optimise = parsed
+	}
+
+	route, err := h.scheduleUC.GetDailyRoute(c, caregiverID, date, optimise)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	stops := make([]gin.H, 0, len(route.Stops))
+	for _, s := range route.Stops {
+		stops = append(stops, gin.H{
+			"schedule_id":          s.ScheduleID,
+			"client_name":          s.ClientName,
+			"location_name":        s.LocationName,
+			"status":               s.Status,
+			"start_time":           s.StartTime,
+			"end_time":             s.EndTime,
+			"latitude":             s.Latitude,
+			"longitude":            s.Longitude,
+			"flexible_window_mins": s.FlexibleWindowMins,
+		})
+	}
+	legs := make([]gin.H, 0, len(route.Legs))
+	for _, l := range route.Legs {
+		legs = append(legs, gin.H{
+			"from_schedule_id": l.FromScheduleID,
+			"to_schedule_id":   l.ToScheduleID,
+			"distance_km":      l.DistanceKm,
+			"travel_mins":      l.TravelMins,
+			"available_mins":   l.AvailableMins,
+			"feasible":         l.Feasible,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"date":                  route.Date.Format("2006-01-02"),
+			"stops":                 stops,
+			"legs":                  legs,
+			"total_distance_km":     route.TotalDistanceKm,
+			"infeasible_legs":       route.InfeasibleLegs,
+			"optimised_order":       route.OptimisedOrder,
+			"optimised_distance_km": route.OptimisedDistanceKm,
+		},
+	})
+}
+
 // Metrics returns aggregate schedule counts for a given date.
 func (h *ScheduleHandler) Metrics(c *gin.Context) {
 	caregiverID, ok := caregiverID(c)
@@ -239,11 +412,18 @@ func (h *ScheduleHandler) Metrics(c *gin.Context) {
 		return
 	}
 
-	date := time.Now()
+	date, err := h.scheduleUC.Today(c, caregiverID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
 	if dateStr := c.Query("date"); dateStr != "" {
-		if parsed, err := time.Parse("2006-01-02", dateStr); err == nil {
-			date = parsed
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "date must be a YYYY-MM-DD date")
+			return
 		}
+		date = parsed
 	}
 
 	metrics, err := h.scheduleUC.GetMetrics(c, caregiverID, date)
@@ -255,6 +435,53 @@ func (h *ScheduleHandler) Metrics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": metrics})
 }
 
+// MetricsRange returns visit metrics between from and to, bucketed by
+// granularity (day, week or month).
+func (h *ScheduleHandler) MetricsRange(c *gin.Context) {
+	caregiverID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "from must be a YYYY-MM-DD date")
+		return
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "to must be a YYYY-MM-DD date")
+		return
+	}
+	granularity := domain.MetricsGranularity(c.DefaultQuery("granularity", string(domain.MetricsGranularityDay)))
+	if !granularity.Valid() {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "granularity must be day, week or month")
+		return
+	}
+
+	metrics, err := h.scheduleUC.GetMetricsRange(c, caregiverID, from, to, granularity)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	buckets := make([]gin.H, 0, len(metrics.Buckets))
+	for _, bucket := range metrics.Buckets {
+		buckets = append(buckets, gin.H{
+			"start":   bucket.Start.Format("2006-01-02"),
+			"metrics": bucket.Metrics,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"from":        metrics.From.Format("2006-01-02"),
+		"to":          metrics.To.Format("2006-01-02"),
+		"granularity": metrics.Granularity,
+		"buckets":     buckets,
+		"summary":     metrics.Summary,
+	}})
+}
+
 func scheduleToResponse(schedule domain.Schedule) gin.H {
 	tasks := make([]gin.H, 0, len(schedule.Tasks))
 	for _, t := range schedule.Tasks {
@@ -266,10 +493,12 @@ func scheduleToResponse(schedule domain.Schedule) gin.H {
 			"not_completed_reason": t.NotCompletedReason,
 			"sort_order":           t.SortOrder,
 			"updated_at":           t.UpdatedAt,
+
+			"medication_administration_id": t.MedicationAdministrationID,
 		})
 	}
 
-	return gin.H{
+	response := gin.H{
 		"id":           schedule.ID,
 		"caregiver_id": schedule.CaregiverID,
 		"service_name": schedule.ServiceName,
@@ -298,5 +527,29 @@ func scheduleToResponse(schedule domain.Schedule) gin.H {
 		"tasks":          tasks,
 		"location_label": schedule.LocationLabel,
 		"duration_mins":  schedule.DurationMins,
+
+		"manually_edited": schedule.ManuallyEdited,
+	}
+	if schedule.Signoff != nil {
+		response["client_signoff"] = signoffToResponse(*schedule.Signoff)
+	}
+	return response
+}
+
+func signoffToResponse(signoff domain.VisitSignoff) gin.H {
+	response := gin.H{
+		"method":       signoff.Method,
+		"signer_name":  signoff.SignerName,
+		"signer_role":  signoff.SignerRole,
+		"summary":      json.RawMessage(signoff.Summary),
+		"summary_hash": signoff.SummaryHash,
+		"captured_at":  signoff.CapturedAt,
+	}
+	switch signoff.SignatureFormat {
+	case domain.SignatureFormatSVG:
+		response["signature_svg"] = string(signoff.Signature)
+	case domain.SignatureFormatPNG:
+		response["signature_png"] = signoff.Signature
 	}
+	return response
 }
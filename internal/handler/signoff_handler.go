@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// SignoffManageScope grants configuring how clients acknowledge visits.
+const SignoffManageScope = "signoffs.manage"
+
+// SignoffHandler exposes per-client visit sign-off settings.
+type SignoffHandler struct {
+	signoffUC *usecase.SignoffUsecase
+}
+
+// NewSignoffHandler constructs the handler.
+func NewSignoffHandler(signoffUC *usecase.SignoffUsecase) *SignoffHandler {
+	return &SignoffHandler{signoffUC: signoffUC}
+}
+
+type updateSignoffSettingsRequest struct {
+	Required *bool `json:"required"`
+	// PIN sets the client's PIN; an empty string removes it.
+	PIN *string `json:"pin"`
+}
+
+// GetSettings returns the client's sign-off settings.
+func (h *SignoffHandler) GetSettings(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	settings, err := h.signoffUC.GetSettings(c, clientID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": signoffSettingsToResponse(settings)})
+}
+
+// UpdateSettings changes the settings present in the body.
+func (h *SignoffHandler) UpdateSettings(c *gin.Context) {
+	clientID, ok := familyClientID(c)
+	if !ok {
+		return
+	}
+	var req updateSignoffSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	updaterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	settings, err := h.signoffUC.UpdateSettings(c, clientID, updaterID, usecase.SignoffSettingsUpdate{
+		Required: req.Required,
+		PIN:      req.PIN,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": signoffSettingsToResponse(settings)})
+}
+
+// signoffSettingsToResponse never includes the PIN hash.
+func signoffSettingsToResponse(settings domain.ClientSignoffSettings) gin.H {
+	return gin.H{
+		"client_id":  settings.ClientID,
+		"required":   settings.Required,
+		"pin_set":    settings.PINHash != nil,
+		"updated_by": settings.UpdatedBy,
+		"updated_at": settings.UpdatedAt,
+	}
+}
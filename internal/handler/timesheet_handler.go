@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// TimesheetApproveScope grants reviewing, locking and exporting any caregiver's timesheets.
+const TimesheetApproveScope = "timesheets.approve"
+
+// TimesheetHandler exposes timesheets and their approval workflow.
+type TimesheetHandler struct {
+	timesheetUC *usecase.TimesheetUsecase
+}
+
+// NewTimesheetHandler constructs the handler.
+func NewTimesheetHandler(timesheetUC *usecase.TimesheetUsecase) *TimesheetHandler {
+	return &TimesheetHandler{timesheetUC: timesheetUC}
+}
+
+type submitTimesheetRequest struct {
+	PeriodDate string `json:"period_date"`
+}
+
+type reviewTimesheetRequest struct {
+	Comment string `json:"comment"`
+}
+
+// ListTimesheets returns the caregiver's submitted timesheets.
+func (h *TimesheetHandler) ListTimesheets(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	timesheets, err := h.timesheetUC.ListTimesheets(c, requesterID)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": timesheetsToResponse(timesheets)})
+}
+
+// CurrentTimesheet returns the timesheet for the period containing ?date
+// (default today), computed live until it is submitted.
+func (h *TimesheetHandler) CurrentTimesheet(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	var date *time.Time
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "date must be YYYY-MM-DD")
+			return
+		}
+		date = &parsed
+	}
+
+	timesheet, err := h.timesheetUC.Current(c, requesterID, date)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": timesheetToResponse(timesheet)})
+}
+
+// SubmitTimesheet submits the period containing period_date (default the last
+// ended period) for approval.
+func (h *TimesheetHandler) SubmitTimesheet(c *gin.Context) {
+	var req submitTimesheetRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+			return
+		}
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	var date *time.Time
+	if req.PeriodDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.PeriodDate)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "period_date must be YYYY-MM-DD")
+			return
+		}
+		date = &parsed
+	}
+
+	timesheet, err := h.timesheetUC.Submit(c, requesterID, date)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": timesheetToResponse(timesheet)})
+}
+
+// GetTimesheet returns a stored timesheet with its days.
+func (h *TimesheetHandler) GetTimesheet(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	timesheet, err := h.timesheetUC.GetTimesheet(c, c.Param("timesheetID"), requesterID, middleware.HasScope(c, TimesheetApproveScope))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": timesheetToResponse(timesheet)})
+}
+
+// ExportTimesheet streams a stored timesheet as CSV (default) or payroll JSON.
+func (h *TimesheetHandler) ExportTimesheet(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	export, err := h.timesheetUC.Export(c, c.Param("timesheetID"), requesterID, middleware.HasScope(c, TimesheetApproveScope), format)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Data(http.StatusOK, export.ContentType, export.Payload)
+}
+
+// ListForReview returns timesheets waiting to be approved or locked.
+func (h *TimesheetHandler) ListForReview(c *gin.Context) {
+	timesheets, err := h.timesheetUC.ListForReview(c)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": timesheetsToResponse(timesheets)})
+}
+
+// Approve accepts a submitted timesheet.
+func (h *TimesheetHandler) Approve(c *gin.Context) {
+	h.review(c, h.timesheetUC.Approve)
+}
+
+// Reject returns a submitted timesheet to its caregiver; a comment is required.
+func (h *TimesheetHandler) Reject(c *gin.Context) {
+	h.review(c, h.timesheetUC.Reject)
+}
+
+// Lock finalises an approved timesheet.
+func (h *TimesheetHandler) Lock(c *gin.Context) {
+	h.review(c, func(ctx context.Context, timesheetID, actorID, _ string) (domain.Timesheet, error) {
+		return h.timesheetUC.Lock(ctx, timesheetID, actorID)
+	})
+}
+
+func (h *TimesheetHandler) review(c *gin.Context, decide func(ctx context.Context, timesheetID, reviewerID, comment string) (domain.Timesheet, error)) {
+	var req reviewTimesheetRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+			return
+		}
+	}
+	reviewerID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	timesheet, err := decide(c, c.Param("timesheetID"), reviewerID, req.Comment)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": timesheetToResponse(timesheet)})
+}
+
+func timesheetsToResponse(timesheets []domain.Timesheet) []gin.H {
+	resp := make([]gin.H, 0, len(timesheets))
+	for _, timesheet := range timesheets {
+		resp = append(resp, timesheetToResponse(timesheet))
+	}
+	return resp
+}
+
+func timesheetToResponse(t domain.Timesheet) gin.H {
+	resp := gin.H{
+		"id":               t.ID,
+		"caregiver_id":     t.CaregiverID,
+		"period":           t.Period,
+		"period_start":     t.PeriodStart,
+		"period_end":       t.PeriodEnd,
+		"status":           t.Status,
+		"worked_minutes":   t.WorkedMinutes,
+		"break_minutes":    t.BreakMinutes,
+		"visit_minutes":    t.VisitMinutes,
+		"regular_minutes":  t.RegularMinutes,
+		"overtime_minutes": t.OvertimeMinutes,
+		"completed_visits": t.CompletedVisits,
+		"submitted_at":     t.SubmittedAt,
+		"reviewed_by":      t.ReviewedBy,
+		"reviewed_at":      t.ReviewedAt,
+		"review_comment":   t.ReviewComment,
+		"locked_by":        t.LockedBy,
+		"locked_at":        t.LockedAt,
+	}
+	if t.Days != nil {
+		days := make([]gin.H, 0, len(t.Days))
+		for _, day := range t.Days {
+			days = append(days, gin.H{
+				"date":             day.Date.Format("2006-01-02"),
+				"worked_minutes":   day.WorkedMinutes,
+				"break_minutes":    day.BreakMinutes,
+				"visit_minutes":    day.VisitMinutes,
+				"regular_minutes":  day.RegularMinutes,
+				"overtime_minutes": day.OvertimeMinutes,
+				"completed_visits": day.CompletedVisits,
+			})
+		}
+		resp["days"] = days
+	}
+	return resp
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// CorrectionReviewScope grants reviewing visit corrections and proposing them for any visit.
+const CorrectionReviewScope = "corrections.review"
+
+// VisitCorrectionHandler exposes the visit correction workflow.
+type VisitCorrectionHandler struct {
+	correctionUC *usecase.VisitCorrectionUsecase
+}
+
+// NewVisitCorrectionHandler constructs the handler.
+func NewVisitCorrectionHandler(correctionUC *usecase.VisitCorrectionUsecase) *VisitCorrectionHandler {
+	return &VisitCorrectionHandler{correctionUC: correctionUC}
+}
+
+type createCorrectionRequest struct {
+	ReasonCode string     `json:"reason_code" binding:"required"`
+	Comment    string     `json:"comment"`
+	ClockInAt  *time.Time `json:"clock_in_at"`
+	ClockOutAt *time.Time `json:"clock_out_at"`
+}
+
+type reviewCorrectionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// CreateCorrection proposes adjusted clock times for a visit.
+func (h *VisitCorrectionHandler) CreateCorrection(c *gin.Context) {
+	var req createCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	correction, err := h.correctionUC.RequestCorrection(c, requesterID, middleware.HasScope(c, CorrectionReviewScope), usecase.CorrectionRequest{
+		ScheduleID: c.Param("scheduleID"),
+		Reason:     domain.CorrectionReason(req.ReasonCode),
+		Comment:    req.Comment,
+		ClockInAt:  req.ClockInAt,
+		ClockOutAt: req.ClockOutAt,
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": correctionToResponse(correction)})
+}
+
+// ListScheduleCorrections returns the audit history of corrections for a visit.
+func (h *VisitCorrectionHandler) ListScheduleCorrections(c *gin.Context) {
+	requesterID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	corrections, err := h.correctionUC.ListCorrections(c, c.Param("scheduleID"), requesterID, middleware.HasScope(c, CorrectionReviewScope))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": correctionsToResponse(corrections)})
+}
+
+// ListPending returns corrections awaiting review.
+func (h *VisitCorrectionHandler) ListPending(c *gin.Context) {
+	corrections, err := h.correctionUC.ListPending(c)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": correctionsToResponse(corrections)})
+}
+
+// Approve applies a pending correction to its visit.
+func (h *VisitCorrectionHandler) Approve(c *gin.Context) {
+	h.review(c, h.correctionUC.ApproveCorrection)
+}
+
+// Reject closes a pending correction; a comment is required.
+func (h *VisitCorrectionHandler) Reject(c *gin.Context) {
+	h.review(c, h.correctionUC.RejectCorrection)
+}
+
+func (h *VisitCorrectionHandler) review(c *gin.Context, decide func(ctx context.Context, correctionID, reviewerID, comment string) (domain.VisitCorrection, error)) {
+	var req reviewCorrectionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+			return
+		}
+	}
+	reviewerID, ok := caregiverID(c)
+	if !ok {
+		respondError(c, http.StatusUnauthorized, domain.ErrUnauthorized, "")
+		return
+	}
+
+	correction, err := decide(c, c.Param("correctionID"), reviewerID, req.Comment)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": correctionToResponse(correction)})
+}
+
+func correctionsToResponse(corrections []domain.VisitCorrection) []gin.H {
+	resp := make([]gin.H, 0, len(corrections))
+	for _, correction := range corrections {
+		resp = append(resp, correctionToResponse(correction))
+	}
+	return resp
+}
+
+func correctionToResponse(v domain.VisitCorrection) gin.H {
+	return gin.H{
+		"id":                    v.ID,
+		"schedule_id":           v.ScheduleID,
+		"requested_by":          v.RequestedBy,
+		"reason_code":           v.Reason,
+		"comment":               v.Comment,
+		"proposed_clock_in_at":  v.ProposedClockInAt,
+		"proposed_clock_out_at": v.ProposedClockOutAt,
+		"status":                v.Status,
+		"original_clock_in_at":  v.OriginalClockInAt,
+		"original_clock_out_at": v.OriginalClockOutAt,
+		"original_status":       v.OriginalStatus,
+		"reviewed_by":           v.ReviewedBy,
+		"reviewed_at":           v.ReviewedAt,
+		"review_comment":        v.ReviewComment,
+		"created_at":            v.CreatedAt,
+	}
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhooksManageScope grants managing webhook subscriptions and replaying
+// their deliveries.
+const WebhooksManageScope = "webhooks.manage"
+
+// WebhookHandler exposes webhook subscription and delivery administration.
+type WebhookHandler struct {
+	webhookUC *usecase.WebhookUsecase
+}
+
+// NewWebhookHandler constructs the handler.
+func NewWebhookHandler(webhookUC *usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{webhookUC: webhookUC}
+}
+
+type createWebhookSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+}
+
+type updateWebhookSubscriptionRequest struct {
+	URL         *string   `json:"url"`
+	Description *string   `json:"description"`
+	EventTypes  *[]string `json:"event_types"`
+	Active      *bool     `json:"active"`
+}
+
+// CreateSubscription registers a subscriber; the response is the only time
+// its signing secret is shown.
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req createWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	subscription, err := h.webhookUC.CreateSubscription(c, usecase.WebhookSubscriptionInput{
+		URL:         req.URL,
+		Description: req.Description,
+		EventTypes:  toEventTypes(req.EventTypes),
+	})
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	response := webhookSubscriptionToResponse(subscription)
+	response["secret"] = subscription.Secret
+	c.JSON(http.StatusCreated, gin.H{"data": response})
+}
+
+// ListSubscriptions returns every subscription.
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.webhookUC.ListSubscriptions(c)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	data := make([]gin.H, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		data = append(data, webhookSubscriptionToResponse(s))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// GetSubscription returns one subscription.
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	if !isUUID(c.Param("subscriptionID")) {
+		handleDomainError(c, domain.ErrNotFound)
+		return
+	}
+	subscription, err := h.webhookUC.GetSubscription(c, c.Param("subscriptionID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": webhookSubscriptionToResponse(subscription)})
+}
+
+// UpdateSubscription changes the URL, description, event types or active flag.
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	if !isUUID(c.Param("subscriptionID")) {
+		handleDomainError(c, domain.ErrNotFound)
+		return
+	}
+	var req updateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, err.Error())
+		return
+	}
+	update := usecase.WebhookSubscriptionUpdate{
+		URL:         req.URL,
+		Description: req.Description,
+		Active:      req.Active,
+	}
+	if req.EventTypes != nil {
+		types := toEventTypes(*req.EventTypes)
+		update.EventTypes = &types
+	}
+	subscription, err := h.webhookUC.UpdateSubscription(c, c.Param("subscriptionID"), update)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": webhookSubscriptionToResponse(subscription)})
+}
+
+// ReplayDeadLetters requeues every dead delivery of a subscription.
+func (h *WebhookHandler) ReplayDeadLetters(c *gin.Context) {
+	if !isUUID(c.Param("subscriptionID")) {
+		handleDomainError(c, domain.ErrNotFound)
+		return
+	}
+	replayed, err := h.webhookUC.ReplayDeadLetters(c, c.Param("subscriptionID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"replayed": replayed}})
+}
+
+// ListDeliveries returns recent deliveries, optionally for one subscription
+// or in one status; status=dead lists the dead-letter queue.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	filter := repository.WebhookDeliveryFilter{
+		SubscriptionID: c.Query("subscription_id"),
+		Status:         domain.WebhookDeliveryStatus(c.Query("status")),
+	}
+	if filter.SubscriptionID != "" && !isUUID(filter.SubscriptionID) {
+		respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "subscription_id must be a UUID")
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			respondError(c, http.StatusBadRequest, domain.ErrValidationFailure, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+	deliveries, err := h.webhookUC.ListDeliveries(c, filter)
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	data := make([]gin.H, 0, len(deliveries))
+	for _, d := range deliveries {
+		data = append(data, webhookDeliveryToResponse(d))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// ReplayDelivery sends a delivery again with a fresh set of attempts.
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	if !isUUID(c.Param("deliveryID")) {
+		handleDomainError(c, domain.ErrNotFound)
+		return
+	}
+	delivery, err := h.webhookUC.ReplayDelivery(c, c.Param("deliveryID"))
+	if err != nil {
+		handleDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": webhookDeliveryToResponse(delivery)})
+}
+
+func toEventTypes(names []string) []domain.EventType {
+	types := make([]domain.EventType, 0, len(names))
+	for _, name := range names {
+		types = append(types, domain.EventType(name))
+	}
+	return types
+}
+
+func webhookSubscriptionToResponse(s domain.WebhookSubscription) gin.H {
+	types := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		types = append(types, string(t))
+	}
+	return gin.H{
+		"id":          s.ID,
+		"url":         s.URL,
+		"description": s.Description,
+		"event_types": types,
+		"active":      s.Active,
+		"created_at":  s.CreatedAt,
+		"updated_at":  s.UpdatedAt,
+	}
+}
+
+func webhookDeliveryToResponse(d domain.WebhookDelivery) gin.H {
+	return gin.H{
+		"id":              d.ID,
+		"subscription_id": d.SubscriptionID,
+		"event_id":        d.EventID,
+		"event_type":      d.EventType,
+		"status":          d.Status,
+		"attempts":        d.Attempts,
+		"next_attempt_at": d.NextAttemptAt,
+		"last_error":      d.LastError,
+		"response_status": d.ResponseStatus,
+		"delivered_at":    d.DeliveredAt,
+		"created_at":      d.CreatedAt,
+	}
+}
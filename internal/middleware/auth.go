@@ -7,6 +7,7 @@ import (
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
@@ -14,6 +15,9 @@ const (
 	ContextUserClaimsKey = "user_claims"
 	// ContextCaregiverIDKey stores the caregiver identifier extracted from the token.
 	ContextCaregiverIDKey = "caregiver_id"
+	// ContextFamilyMemberIDKey stores the family member identifier extracted
+	// from a family portal token.
+	ContextFamilyMemberIDKey = "family_member_id"
 )
 
 // Authenticated ensures the incoming request presents a valid bearer token.
@@ -49,6 +53,69 @@ func Authenticated(authUC *usecase.AuthUsecase) gin.HandlerFunc {
 	}
 }
 
+// FamilyAuthenticated ensures the incoming request presents a valid family
+// portal bearer token. Caregiver tokens are refused.
+func FamilyAuthenticated(authUC *usecase.AuthUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			unauthorized(c)
+			return
+		}
+
+		claims, err := authUC.ParseFamilyToken(parts[1])
+		if err != nil {
+			unauthorized(c)
+			return
+		}
+
+		sub, ok := claims["sub"].(string)
+		if !ok || sub == "" {
+			unauthorized(c)
+			return
+		}
+
+		c.Set(ContextUserClaimsKey, claims)
+		c.Set(ContextFamilyMemberIDKey, sub)
+		c.Next()
+	}
+}
+
+// RequireScope ensures the authenticated token grants the given scope.
+// It must run after Authenticated.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ContextUserClaimsKey); !ok {
+			unauthorized(c)
+			return
+		}
+		if HasScope(c, scope) {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":   domain.ErrForbidden.Error(),
+			"message": "token is missing required scope " + scope,
+		})
+	}
+}
+
+// HasScope reports whether the authenticated token grants the given scope.
+func HasScope(c *gin.Context, scope string) bool {
+	val, _ := c.Get(ContextUserClaimsKey)
+	claims, ok := val.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	granted, _ := claims["scope"].(string)
+	for _, s := range strings.Fields(granted) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 func unauthorized(c *gin.Context) {
 	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 		"error":   domain.ErrUnauthorized.Error(),
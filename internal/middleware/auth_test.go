@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/config"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/handler"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/middleware"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// demoSecretHash is the SHA-256 of "secret", a form verifySecret accepts.
+const demoSecretHash = "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+
+type authClientsStub map[string]domain.AuthClient
+
+func (s authClientsStub) GetClientByID(ctx context.Context, clientID string) (domain.AuthClient, error) {
+	client, ok := s[clientID]
+	if !ok {
+		return domain.AuthClient{}, domain.ErrNotFound
+	}
+	return client, nil
+}
+
+type caregiversStub struct{}
+
+func (caregiversStub) GetByID(ctx context.Context, caregiverID string) (domain.Caregiver, error) {
+	return domain.Caregiver{ID: caregiverID, Name: "Louis"}, nil
+}
+
+func (s caregiversStub) GetProfile(ctx context.Context, caregiverID string) (domain.Caregiver, error) {
+	return s.GetByID(ctx, caregiverID)
+}
+
+func TestRequireScopeRefusesCaregiverTokensOnSupervisorRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authUC := usecase.NewAuthUsecase(config.AuthConfig{
+		AccessTokenSecret: "access-secret",
+		IDTokenSecret:     "id-secret",
+		AccessTokenTTL:    time.Minute,
+		IDTokenTTL:        time.Minute,
+	}, authClientsStub{
+		"caregiver-app":      {ID: "caregiver-app", SecretHash: demoSecretHash, CaregiverID: "care-1", Scopes: []string{"schedules.read"}},
+		"supervisor-console": {ID: "supervisor-console", SecretHash: demoSecretHash, CaregiverID: "care-2", Scopes: []string{"schedules.read", handler.SupervisorScope}},
+	}, caregiversStub{})
+
+	r := gin.New()
+	admin := r.Group("/api/admin", middleware.Authenticated(authUC), middleware.RequireScope(handler.SupervisorScope))
+	admin.GET("/dashboard", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	token := func(clientID, scope string) (string, error) {
+		pair, _, err := authUC.IssueToken(context.Background(), usecase.TokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     clientID,
+			ClientSecret: "secret",
+			Scope:        scope,
+		})
+		return pair.AccessToken, err
+	}
+	get := func(accessToken string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/dashboard", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if _, err := token("caregiver-app", handler.SupervisorScope); err != domain.ErrInvalidScope {
+		t.Fatalf("expected a caregiver client to be refused the supervisor scope, got %v", err)
+	}
+	caregiverToken, err := token("caregiver-app", "")
+	if err != nil {
+		t.Fatalf("issue token error: %v", err)
+	}
+	if code := get(caregiverToken); code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a caregiver token, got %d", code)
+	}
+
+	supervisorToken, err := token("supervisor-console", "")
+	if err != nil {
+		t.Fatalf("issue token error: %v", err)
+	}
+	if code := get(supervisorToken); code != http.StatusOK {
+		t.Fatalf("expected 200 for a supervisor token, got %d", code)
+	}
+}
+
+func TestRequireScopeGrantsAssignmentRoutesToCoordinators(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authUC := usecase.NewAuthUsecase(config.AuthConfig{
+		AccessTokenSecret: "access-secret",
+		IDTokenSecret:     "id-secret",
+		AccessTokenTTL:    time.Minute,
+		IDTokenTTL:        time.Minute,
+	}, authClientsStub{
+		"caregiver-app":       {ID: "caregiver-app", SecretHash: demoSecretHash, CaregiverID: "care-1", Scopes: []string{"schedules.read"}},
+		"coordinator-console": {ID: "coordinator-console", SecretHash: demoSecretHash, CaregiverID: "care-2", Scopes: []string{"schedules.read", handler.AssignmentsWriteScope}},
+	}, caregiversStub{})
+
+	r := gin.New()
+	assignments := r.Group("/api/assignments", middleware.Authenticated(authUC), middleware.RequireScope(handler.AssignmentsWriteScope))
+	assignments.POST("/proposals", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	post := func(clientID string) int {
+		pair, _, err := authUC.IssueToken(context.Background(), usecase.TokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     clientID,
+			ClientSecret: "secret",
+		})
+		if err != nil {
+			t.Fatalf("issue token error: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/assignments/proposals", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := post("caregiver-app"); code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a caregiver token, got %d", code)
+	}
+	if code := post("coordinator-console"); code != http.StatusOK {
+		t.Fatalf("expected 200 for a coordinator token, got %d", code)
+	}
+}
@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/events"
+	"go.uber.org/zap"
+)
+
+func TestWriterSenderWritesJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	sender := NewWriterSender(&out)
+	sender.now = func() time.Time { return time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC) }
+
+	for _, to := range []string{"+15550100", "maria@example.com"} {
+		if err := sender.Send(context.Background(), domain.NotificationMessage{
+			Channel:     domain.NotificationChannelSMS,
+			Kind:        domain.NotificationVisitReminder,
+			CaregiverID: "cg-1",
+			To:          to,
+			Body:        "Your visit starts at 09:20.",
+		}); err != nil {
+			t.Fatalf("Send error: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per message, got %q", out.String())
+	}
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if first["to"] != "+15550100" || first["channel"] != "sms" || first["sent_at"] != "2025-01-15T12:00:00Z" {
+		t.Fatalf("unexpected message %v", first)
+	}
+	if _, ok := first["subject"]; ok {
+		t.Fatalf("expected an empty subject to be omitted, got %v", first)
+	}
+}
+
+type notifierStub struct {
+	mu     sync.Mutex
+	scans  int
+	events []domain.Event
+	seen   chan struct{}
+}
+
+func (n *notifierStub) SendDueReminders(ctx context.Context) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.scans++
+	return 0, nil
+}
+
+func (n *notifierStub) HandleEvent(ctx context.Context, event domain.Event) error {
+	n.mu.Lock()
+	n.events = append(n.events, event)
+	n.mu.Unlock()
+	n.seen <- struct{}{}
+	return nil
+}
+
+func TestWorkerHandlesAssignmentEvents(t *testing.T) {
+	bus := events.NewBus()
+	notifier := &notifierStub{seen: make(chan struct{}, 1)}
+	worker := NewWorker(notifier, bus, time.Hour, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	// Wait for the subscription before publishing.
+	deadline := time.Now().Add(time.Second)
+	for {
+		notifier.mu.Lock()
+		scanned := notifier.scans > 0
+		notifier.mu.Unlock()
+		if scanned || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	bus.Publish(ctx, domain.Event{Type: domain.EventScheduleStatusChanged, CaregiverID: "cg-1", ScheduleID: "sched-1"})
+	bus.Publish(ctx, domain.Event{Type: domain.EventScheduleAssigned, CaregiverID: "cg-1", ScheduleID: "sched-1"})
+
+	select {
+	case <-notifier.seen:
+	case <-time.After(time.Second):
+		t.Fatal("expected the assignment to reach the notifier")
+	}
+	cancel()
+	<-done
+
+	if len(notifier.events) != 1 || notifier.events[0].Type != domain.EventScheduleAssigned {
+		t.Fatalf("expected only the assignment, got %+v", notifier.events)
+	}
+	if notifier.scans != 1 {
+		t.Fatalf("expected one scan on start, got %d", notifier.scans)
+	}
+}
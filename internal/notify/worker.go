@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"go.uber.org/zap"
+)
+
+// Notifier is the part of the notification usecase the worker drives.
+type Notifier interface {
+	SendDueReminders(ctx context.Context) (int, error)
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
+
+// EventSource delivers domain events, such as events.Bus.
+type EventSource interface {
+	Subscribe(accept func(domain.Event) bool) (<-chan domain.Event, func())
+}
+
+// Worker triggers notifications from schedule timing, by scanning for due
+// reminders every interval, and from domain events as they are published.
+type Worker struct {
+	notifier Notifier
+	events   EventSource
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewWorker constructs a worker; events may be nil to only send timed reminders.
+func NewWorker(notifier Notifier, events EventSource, interval time.Duration, logger *zap.Logger) *Worker {
+	return &Worker{notifier: notifier, events: events, interval: interval, logger: logger}
+}
+
+// Run works until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	var incoming <-chan domain.Event
+	if w.events != nil {
+		ch, unsubscribe := w.events.Subscribe(func(e domain.Event) bool {
+			return e.Type == domain.EventScheduleAssigned
+		})
+		defer unsubscribe()
+		incoming = ch
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	w.scan(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.scan(ctx)
+		case event, ok := <-incoming:
+			if !ok {
+				// The bus was closed; keep sending timed reminders.
+				incoming = nil
+				continue
+			}
+			if err := w.notifier.HandleEvent(ctx, event); err != nil && ctx.Err() == nil {
+				w.logger.Error("notify on event", zap.String("event_id", event.ID), zap.String("type", string(event.Type)), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (w *Worker) scan(ctx context.Context) {
+	sent, err := w.notifier.SendDueReminders(ctx)
+	if err != nil && ctx.Err() == nil {
+		w.logger.Error("send due reminders", zap.Error(err))
+	}
+	if sent > 0 {
+		w.logger.Info("sent reminders", zap.Int("count", sent))
+	}
+}
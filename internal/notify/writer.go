@@ -0,0 +1,72 @@
+// Package notify runs caregiver notifications in the background and provides
+// a channel implementation that writes messages out locally.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// WriterSender "delivers" notifications by writing each one as a JSON line,
+// for local development and testing in place of a real push, SMS or email
+// provider.
+type WriterSender struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+// NewWriterSender writes notifications to out.
+func NewWriterSender(out io.Writer) *WriterSender {
+	return &WriterSender{out: out, now: time.Now}
+}
+
+// OpenWriterSender writes notifications to stdout when target is "stdout" or
+// empty, and appends them to the file at target otherwise. The returned
+// function closes the file.
+func OpenWriterSender(target string) (*WriterSender, func() error, error) {
+	if target == "" || target == "stdout" {
+		return NewWriterSender(os.Stdout), func() error { return nil }, nil
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewWriterSender(file), file.Close, nil
+}
+
+type writtenMessage struct {
+	SentAt      time.Time                  `json:"sent_at"`
+	Channel     domain.NotificationChannel `json:"channel"`
+	Kind        domain.NotificationKind    `json:"kind"`
+	CaregiverID string                     `json:"caregiver_id"`
+	To          string                     `json:"to"`
+	Subject     string                     `json:"subject,omitempty"`
+	Body        string                     `json:"body"`
+}
+
+// Send writes the message as one JSON line.
+func (s *WriterSender) Send(_ context.Context, message domain.NotificationMessage) error {
+	line, err := json.Marshal(writtenMessage{
+		SentAt:      s.now().UTC(),
+		Channel:     message.Channel,
+		Kind:        message.Kind,
+		CaregiverID: message.CaregiverID,
+		To:          message.To,
+		Subject:     message.Subject,
+		Body:        message.Body,
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.out.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// AssignmentRepository provides the inputs and persistence for the assignment optimiser.
+type AssignmentRepository interface {
+	// ListCaregivers returns every caregiver profile eligible for assignment.
+	ListCaregivers(ctx context.Context) ([]domain.Caregiver, error)
+
+	// ListAvailability returns the weekly availability windows of all caregivers.
+	ListAvailability(ctx context.Context) ([]domain.CaregiverAvailability, error)
+
+	// ListCommittedVisits returns assigned, non-cancelled visits overlapping [from, to).
+	ListCommittedVisits(ctx context.Context, from, to time.Time) ([]domain.CommittedVisit, error)
+
+	// ListContinuity returns completed visit counts per caregiver for the given clients.
+	ListContinuity(ctx context.Context, clientIDs []string) ([]domain.ClientContinuity, error)
+
+	// SaveProposal persists a draft proposal and returns its identifier.
+	SaveProposal(ctx context.Context, proposal domain.AssignmentProposal) (string, error)
+
+	// GetProposal loads a proposal with its assignments and unassigned visits.
+	GetProposal(ctx context.Context, proposalID string) (domain.AssignmentProposal, error)
+
+	// ApplyProposal assigns every proposed visit atomically. It returns
+	// domain.ErrConflict if any visit is no longer open, leaving all visits untouched.
+	ApplyProposal(ctx context.Context, proposalID string, appliedAt time.Time) error
+}
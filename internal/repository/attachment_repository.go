@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// AttachmentRepository persists the records of files attached to visits.
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment domain.Attachment) (domain.Attachment, error)
+	Get(ctx context.Context, attachmentID string) (domain.Attachment, error)
+	// ListBySchedule returns a visit's attachments, oldest first.
+	ListBySchedule(ctx context.Context, scheduleID string) ([]domain.Attachment, error)
+}
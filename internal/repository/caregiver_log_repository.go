@@ -9,33 +9,47 @@ import (
 
 // CaregiverLogRepository defines the interface for caregiver log data operations.
 type CaregiverLogRepository interface {
-	// CreateLog creates a new caregiver log entry.
+	// CreateLog creates a new caregiver log entry. It returns
+	// domain.ErrValidationFailure, under a lock on the caregiver's logs, when
+	// the entry cannot follow the caregiver's latest log.
 	CreateLog(ctx context.Context, log domain.CaregiverLog) (string, error)
 	
-	// GetLogsByCaregiver retrieves logs for a caregiver with pagination.
+	// GetLogsByCaregiver retrieves logs for a caregiver with pagination, newest first.
 	GetLogsByCaregiver(ctx context.Context, caregiverID string, filter CaregiverLogFilter) ([]domain.CaregiverLogSummary, error)
 	
-	// GetTodayLogs retrieves today's logs for a caregiver.
-	GetTodayLogs(ctx context.Context, caregiverID string) ([]domain.CaregiverLog, error)
+	// ListLogs retrieves full log records matching the filter, newest first.
+	ListLogs(ctx context.Context, caregiverID string, filter CaregiverLogFilter) ([]domain.CaregiverLog, error)
+	
+	// CountLogs counts the logs matching the filter, ignoring paging.
+	CountLogs(ctx context.Context, caregiverID string, filter CaregiverLogFilter) (int, error)
+	
+	// GetSessionLogs retrieves logs from the clock-in at from up to the first
+	// clock-in after through, oldest first, so every session that started in
+	// [from, through] is returned whole.
+	GetSessionLogs(ctx context.Context, caregiverID string, from, through time.Time) ([]domain.CaregiverLog, error)
+	
+	// GetLogsBetween retrieves a caregiver's logs in [from, to), oldest first.
+	GetLogsBetween(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.CaregiverLog, error)
+	
+	// GetLastLog retrieves the most recent log of any type for a caregiver.
+	GetLastLog(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error)
 	
 	// GetLastClockIn retrieves the last clock-in log for a caregiver.
 	GetLastClockIn(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error)
 	
 	// GetLastClockOut retrieves the last clock-out log for a caregiver.
 	GetLastClockOut(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error)
-	
-	// HasClockedInToday checks if caregiver has clocked in today.
-	HasClockedInToday(ctx context.Context, caregiverID string) (bool, error)
-	
-	// HasClockedOutToday checks if caregiver has clocked out today.
-	HasClockedOutToday(ctx context.Context, caregiverID string) (bool, error)
 }
 
 // CaregiverLogFilter defines filtering options for caregiver log queries.
 type CaregiverLogFilter struct {
 	LogType   *domain.LogType
 	StartDate *time.Time
+	// EndDate is exclusive.
 	EndDate   *time.Time
 	Limit     int
 	Offset    int
+	// After continues a newest-first listing from the row after this
+	// position; Offset is ignored when it is set.
+	After *domain.Cursor
 }
\ No newline at end of file
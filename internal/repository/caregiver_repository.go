@@ -9,4 +9,6 @@ import (
 // CaregiverRepository exposes read operations for caregivers.
 type CaregiverRepository interface {
 	GetByID(ctx context.Context, caregiverID string) (domain.Caregiver, error)
+	// GetProfile returns the caregiver including home location, qualifications and timezone.
+	GetProfile(ctx context.Context, caregiverID string) (domain.Caregiver, error)
 }
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// DashboardFilter scopes the agency-wide dashboard to visits starting in
+// [From, To) and, when set, to caregivers of one region and team. Now decides
+// which visits are late or missed.
+type DashboardFilter struct {
+	From   time.Time
+	To     time.Time
+	Now    time.Time
+	Region string
+	Team   string
+}
+
+// DashboardRepository aggregates visits, attendance and incidents across all
+// caregivers for supervisors.
+type DashboardRepository interface {
+	GetSummary(ctx context.Context, filter DashboardFilter) (domain.DashboardSummary, error)
+	// ListVisits returns the visits in the state ordered by start time.
+	ListVisits(ctx context.Context, filter DashboardFilter, state domain.DashboardVisitState) ([]domain.DashboardVisit, error)
+	// ListCaregiverStatus returns every caregiver in the region and team
+	// ordered by name, whether or not they have visits in the range.
+	ListCaregiverStatus(ctx context.Context, filter DashboardFilter) ([]domain.CaregiverDayStatus, error)
+}
+
+// IncidentRepository persists incidents reported by caregivers.
+type IncidentRepository interface {
+	Create(ctx context.Context, incident domain.Incident) (domain.Incident, error)
+	GetByID(ctx context.Context, incidentID string) (domain.Incident, error)
+	// ListOpen returns unresolved incidents of caregivers in the filter's
+	// region and team, highest severity first. The time range is ignored.
+	ListOpen(ctx context.Context, filter DashboardFilter) ([]domain.Incident, error)
+	// Resolve closes an open incident; it returns domain.ErrConflict when the
+	// incident has already been resolved.
+	Resolve(ctx context.Context, incidentID, resolverID, resolution string, at time.Time) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// EVVRepository provides visit data and submission tracking for EVV exports.
+type EVVRepository interface {
+	// ListVisits returns completed visits that started in [from, to), including
+	// the hash of their latest submission if any.
+	ListVisits(ctx context.Context, from, to time.Time) ([]domain.EVVVisit, error)
+
+	// SaveBatch stores the batch file and records a submission for each record atomically.
+	SaveBatch(ctx context.Context, batch domain.EVVBatch, records []domain.EVVRecord) error
+
+	// GetBatch returns a stored batch including its payload.
+	GetBatch(ctx context.Context, batchID string) (domain.EVVBatch, error)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// FamilyVisitFilter selects a client's visits for the family portal.
+type FamilyVisitFilter struct {
+	// Upcoming selects visits not yet over, soonest first; otherwise visits
+	// that have started and are over, latest first.
+	Upcoming bool
+	Now      time.Time
+	Limit    int
+}
+
+// FamilyRepository persists family members and client consent, and reads
+// visits as the family portal shows them.
+type FamilyRepository interface {
+	// CreateMember returns domain.ErrConflict when the email is taken.
+	CreateMember(ctx context.Context, member domain.FamilyMember) (domain.FamilyMember, error)
+	GetMember(ctx context.Context, memberID string) (domain.FamilyMember, error)
+	GetMemberByEmail(ctx context.Context, email string) (domain.FamilyMember, error)
+	ListMembers(ctx context.Context, clientID string) ([]domain.FamilyMember, error)
+	SetMemberActive(ctx context.Context, clientID, memberID string, active bool) (domain.FamilyMember, error)
+
+	// GetConsent returns domain.ErrNotFound for an unknown client, and a
+	// consent sharing nothing when the client has not recorded one.
+	GetConsent(ctx context.Context, clientID string) (domain.FamilyConsent, error)
+	SaveConsent(ctx context.Context, consent domain.FamilyConsent) error
+
+	// ListVisits and GetVisit return visits with their confirmation but without tasks.
+	ListVisits(ctx context.Context, clientID string, filter FamilyVisitFilter) ([]domain.FamilyVisit, error)
+	GetVisit(ctx context.Context, clientID, scheduleID string) (domain.FamilyVisit, error)
+	// CompletedTasks returns the completed tasks of each visit, keyed by schedule id.
+	CompletedTasks(ctx context.Context, scheduleIDs []string) (map[string][]domain.FamilyTask, error)
+}
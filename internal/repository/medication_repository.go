@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// MARFilter narrows a client's medication administration record. From and To
+// bound the due time, or the administration time of PRN doses.
+type MARFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Status []domain.AdministrationStatus
+	Limit  int
+}
+
+// MedicationRepository persists medication orders and the medication
+// administration record (MAR).
+type MedicationRepository interface {
+	// CreateOrder returns domain.ErrNotFound for an unknown client.
+	CreateOrder(ctx context.Context, order domain.MedicationOrder) (domain.MedicationOrder, error)
+	GetOrder(ctx context.Context, orderID string) (domain.MedicationOrder, error)
+	// UpdateOrder saves the order's instructions, end date and active flag.
+	UpdateOrder(ctx context.Context, order domain.MedicationOrder) (domain.MedicationOrder, error)
+	ListOrders(ctx context.Context, clientID string, activeOnly bool) ([]domain.MedicationOrder, error)
+
+	// ListVisitsForDoses returns the visits overlapping [from, to) that are
+	// not completed or cancelled, of clients with active scheduled orders.
+	// Only ID, CaregiverID, Client.ID, StartTime, EndTime and Status are set.
+	ListVisitsForDoses(ctx context.Context, from, to time.Time) ([]domain.Schedule, error)
+	// AddDue stores the scheduled doses not yet on the MAR, each with a
+	// pending visit task standing for it, and returns how many were added.
+	AddDue(ctx context.Context, doses []domain.MedicationAdministration) (int, error)
+	// AddPRN stores a dose given as needed.
+	AddPRN(ctx context.Context, dose domain.MedicationAdministration) (domain.MedicationAdministration, error)
+	GetAdministration(ctx context.Context, administrationID string) (domain.MedicationAdministration, error)
+	ListBySchedule(ctx context.Context, scheduleID string) ([]domain.MedicationAdministration, error)
+	ListByClient(ctx context.Context, clientID string, filter MARFilter) ([]domain.MedicationAdministration, error)
+	// Record saves the outcome of a scheduled dose and closes its task.
+	Record(ctx context.Context, dose domain.MedicationAdministration) error
+	// MarkMissed flags the doses still due on the visit, or on every visit
+	// that is over by before when scheduleID is empty, and returns them.
+	MarkMissed(ctx context.Context, scheduleID string, before time.Time) ([]domain.MedicationAdministration, error)
+}
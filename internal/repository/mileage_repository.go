@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// MileageRepository reads visit positions and persists mileage reimbursement claims.
+type MileageRepository interface {
+	// ListVisitStops returns non-cancelled visits clocked in during [from, to),
+	// ordered by clock-in.
+	ListVisitStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.MileageStop, error)
+
+	// SaveClaim stores the claim and its legs with status submitted and returns
+	// its id. Resubmitting a rejected day replaces it; any other existing claim
+	// for the day yields domain.ErrConflict.
+	SaveClaim(ctx context.Context, claim domain.MileageClaim) (string, error)
+	// GetClaim returns the claim including its legs.
+	GetClaim(ctx context.Context, claimID string) (domain.MileageClaim, error)
+	// FindByDate returns the caregiver's claim for the calendar day, with legs.
+	FindByDate(ctx context.Context, caregiverID string, day time.Time) (domain.MileageClaim, error)
+	// ListByCaregiver returns the caregiver's claims without legs, newest first.
+	ListByCaregiver(ctx context.Context, caregiverID string) ([]domain.MileageClaim, error)
+	// ListByStatus returns claims in the status without legs, oldest first.
+	ListByStatus(ctx context.Context, status domain.MileageClaimStatus) ([]domain.MileageClaim, error)
+	// ListApproved returns the caregiver's approved claims for calendar days in
+	// [from, to), without legs, oldest first.
+	ListApproved(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.MileageClaim, error)
+
+	// Approve and Reject decide a submitted claim; they return domain.ErrConflict
+	// when it has already been decided.
+	Approve(ctx context.Context, claimID, reviewerID string, comment *string, at time.Time) error
+	Reject(ctx context.Context, claimID, reviewerID string, comment *string, at time.Time) error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// NotificationRepository persists notification preferences and the delivery
+// log, and finds the visits notifications are about.
+type NotificationRepository interface {
+	// GetPreferences returns domain.ErrNotFound until the caregiver saves some.
+	GetPreferences(ctx context.Context, caregiverID string) (domain.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, prefs domain.NotificationPreferences) error
+
+	// GetVisit returns an assigned visit, or domain.ErrNotFound.
+	GetVisit(ctx context.Context, scheduleID string) (domain.NotificationVisit, error)
+	// ListVisitsStarting returns scheduled, assigned visits starting after from
+	// and no later than to.
+	ListVisitsStarting(ctx context.Context, from, to time.Time) ([]domain.NotificationVisit, error)
+	// ListVisitsNotClockedOut returns in-progress visits without a clock-out
+	// that ended after from and no later than to.
+	ListVisitsNotClockedOut(ctx context.Context, from, to time.Time) ([]domain.NotificationVisit, error)
+
+	// Record adds an entry to the delivery log. It returns false, and records
+	// nothing, when the caregiver already has one for the same kind, channel
+	// and dedupe key.
+	Record(ctx context.Context, notification domain.Notification) (string, bool, error)
+	// Finish sets the outcome of a pending entry.
+	Finish(ctx context.Context, notificationID string, status domain.NotificationStatus, failure *string, at time.Time) error
+	// ListByCaregiver returns the caregiver's log, newest first.
+	ListByCaregiver(ctx context.Context, caregiverID string, limit int) ([]domain.Notification, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// ObservationFilter narrows a client's observations to [From, To) and, when
+// Types is set, to those types.
+type ObservationFilter struct {
+	From  time.Time
+	To    time.Time
+	Types []domain.ObservationType
+}
+
+// ObservationRepository persists structured observations taken during visits.
+type ObservationRepository interface {
+	// Create stores the readings and records an observation.out_of_range
+	// outbox event for each flagged one in the same transaction.
+	Create(ctx context.Context, observations []domain.Observation) ([]domain.Observation, error)
+	// ListByClient returns the client's observations oldest first.
+	ListByClient(ctx context.Context, clientID string, filter ObservationFilter) ([]domain.Observation, error)
+}
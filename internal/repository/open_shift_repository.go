@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// OpenShiftFilter captures optional parameters for listing open shifts.
+type OpenShiftFilter struct {
+	// From excludes shifts starting before this instant.
+	From time.Time
+	// To, when set, excludes shifts starting at or after this instant.
+	To *time.Time
+	// Qualifications restricts results to shifts whose requirements are covered by this set.
+	Qualifications []string
+	// FreeCaregiverID, when set, excludes shifts overlapping this caregiver's committed visits.
+	FreeCaregiverID string
+	Limit           int
+}
+
+// OpenShiftRepository defines persistence for unassigned visits.
+type OpenShiftRepository interface {
+	// ListOpenShifts returns unassigned, still scheduled visits ordered by start time.
+	ListOpenShifts(ctx context.Context, filter OpenShiftFilter) ([]domain.OpenShift, error)
+
+	// GetOpenShift returns a single unassigned visit or domain.ErrNotFound.
+	GetOpenShift(ctx context.Context, scheduleID string) (domain.OpenShift, error)
+
+	// ClaimOpenShift assigns the visit to the caregiver if it is still unassigned
+	// and does not overlap one of their committed visits. It returns
+	// domain.ErrConflict when another caregiver claimed it first or on overlap.
+	ClaimOpenShift(ctx context.Context, scheduleID, caregiverID string, claimedAt time.Time) error
+}
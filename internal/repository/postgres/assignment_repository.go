@@ -0,0 +1,316 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AssignmentRepository implements repository.AssignmentRepository backed by Postgres.
+type AssignmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewAssignmentRepository creates a new repository.
+func NewAssignmentRepository(db *sqlx.DB) *AssignmentRepository {
+	return &AssignmentRepository{db: db}
+}
+
+func (r *AssignmentRepository) ListCaregivers(ctx context.Context) ([]domain.Caregiver, error) {
+	query := `
+		SELECT id, name, email, home_latitude, home_longitude, qualifications
+		FROM caregivers
+		ORDER BY name ASC
+	`
+	rows := []caregiverProfileRow{}
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	result := make([]domain.Caregiver, len(rows))
+	for i, row := range rows {
+		result[i] = mapCaregiverProfile(row)
+	}
+	return result, nil
+}
+
+type availabilityRow struct {
+	CaregiverID string `db:"caregiver_id"`
+	Weekday     int    `db:"weekday"`
+	StartMinute int    `db:"start_minute"`
+	EndMinute   int    `db:"end_minute"`
+}
+
+func (r *AssignmentRepository) ListAvailability(ctx context.Context) ([]domain.CaregiverAvailability, error) {
+	query := `
+		SELECT caregiver_id, weekday, start_minute, end_minute
+		FROM caregiver_availability
+		ORDER BY caregiver_id, weekday, start_minute
+	`
+	rows := []availabilityRow{}
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+	result := make([]domain.CaregiverAvailability, len(rows))
+	for i, row := range rows {
+		result[i] = domain.CaregiverAvailability{
+			CaregiverID: row.CaregiverID,
+			Weekday:     time.Weekday(row.Weekday),
+			StartMinute: row.StartMinute,
+			EndMinute:   row.EndMinute,
+		}
+	}
+	return result, nil
+}
+
+type committedVisitRow struct {
+	ScheduleID  string          `db:"id"`
+	CaregiverID string          `db:"caregiver_id"`
+	ClientID    string          `db:"client_id"`
+	StartTime   time.Time       `db:"start_time"`
+	EndTime     time.Time       `db:"end_time"`
+	Latitude    sql.NullFloat64 `db:"latitude"`
+	Longitude   sql.NullFloat64 `db:"longitude"`
+}
+
+func (r *AssignmentRepository) ListCommittedVisits(ctx context.Context, from, to time.Time) ([]domain.CommittedVisit, error) {
+	query := `
+		SELECT s.id, s.caregiver_id, s.client_id, s.start_time, s.end_time, c.latitude, c.longitude
+		FROM schedules s
+		INNER JOIN clients c ON c.id = s.client_id
+		WHERE s.caregiver_id IS NOT NULL
+		  AND s.status <> 'cancelled'
+		  AND s.start_time < $2
+		  AND s.end_time > $1
+		ORDER BY s.start_time ASC
+	`
+	rows := []committedVisitRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, from, to); err != nil {
+		return nil, err
+	}
+	result := make([]domain.CommittedVisit, len(rows))
+	for i, row := range rows {
+		result[i] = domain.CommittedVisit{
+			ScheduleID:  row.ScheduleID,
+			CaregiverID: row.CaregiverID,
+			ClientID:    row.ClientID,
+			StartTime:   row.StartTime,
+			EndTime:     row.EndTime,
+			Latitude:    row.Latitude.Float64,
+			Longitude:   row.Longitude.Float64,
+		}
+	}
+	return result, nil
+}
+
+func (r *AssignmentRepository) ListContinuity(ctx context.Context, clientIDs []string) ([]domain.ClientContinuity, error) {
+	if len(clientIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT client_id, caregiver_id, COUNT(*) AS visits
+		FROM schedules
+		WHERE status = 'completed'
+		  AND caregiver_id IS NOT NULL
+		  AND client_id = ANY($1)
+		GROUP BY client_id, caregiver_id
+	`
+	type row struct {
+		ClientID    string `db:"client_id"`
+		CaregiverID string `db:"caregiver_id"`
+		Visits      int    `db:"visits"`
+	}
+	rows := []row{}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(clientIDs)); err != nil {
+		return nil, err
+	}
+	result := make([]domain.ClientContinuity, len(rows))
+	for i, rw := range rows {
+		result[i] = domain.ClientContinuity{ClientID: rw.ClientID, CaregiverID: rw.CaregiverID, Visits: rw.Visits}
+	}
+	return result, nil
+}
+
+func (r *AssignmentRepository) SaveProposal(ctx context.Context, proposal domain.AssignmentProposal) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO assignment_proposals (range_from, range_to, status, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, proposal.From, proposal.To, proposal.Status, nullable(proposal.CreatedBy), proposal.CreatedAt).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	itemQuery := `
+		INSERT INTO assignment_proposal_items (proposal_id, schedule_id, caregiver_id, start_time, score, travel_km, reasons)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, a := range proposal.Assignments {
+		if _, err := tx.ExecContext(ctx, itemQuery, id, a.ScheduleID, a.CaregiverID, a.StartTime, a.Score, a.TravelKm, pq.Array(a.Reasons)); err != nil {
+			return "", err
+		}
+	}
+	for _, u := range proposal.Unassigned {
+		if _, err := tx.ExecContext(ctx, itemQuery, id, u.ScheduleID, nil, u.StartTime, 0, 0, pq.Array(u.Reasons)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+type proposalRow struct {
+	ID        string         `db:"id"`
+	RangeFrom time.Time      `db:"range_from"`
+	RangeTo   time.Time      `db:"range_to"`
+	Status    string         `db:"status"`
+	CreatedBy sql.NullString `db:"created_by"`
+	CreatedAt time.Time      `db:"created_at"`
+	AppliedAt sql.NullTime   `db:"applied_at"`
+}
+
+type proposalItemRow struct {
+	ScheduleID    string         `db:"schedule_id"`
+	CaregiverID   sql.NullString `db:"caregiver_id"`
+	CaregiverName sql.NullString `db:"caregiver_name"`
+	StartTime     time.Time      `db:"start_time"`
+	Score         float64        `db:"score"`
+	TravelKm      float64        `db:"travel_km"`
+	Reasons       pq.StringArray `db:"reasons"`
+}
+
+func (r *AssignmentRepository) GetProposal(ctx context.Context, proposalID string) (domain.AssignmentProposal, error) {
+	var row proposalRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT id, range_from, range_to, status, created_by, created_at, applied_at
+		FROM assignment_proposals
+		WHERE id = $1
+	`, proposalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.AssignmentProposal{}, domain.ErrNotFound
+		}
+		return domain.AssignmentProposal{}, err
+	}
+
+	items := []proposalItemRow{}
+	err = r.db.SelectContext(ctx, &items, `
+		SELECT i.schedule_id, i.caregiver_id, cg.name AS caregiver_name, i.start_time, i.score, i.travel_km, i.reasons
+		FROM assignment_proposal_items i
+		LEFT JOIN caregivers cg ON cg.id = i.caregiver_id
+		WHERE i.proposal_id = $1
+		ORDER BY i.start_time ASC
+	`, proposalID)
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+
+	proposal := domain.AssignmentProposal{
+		ID:        row.ID,
+		From:      row.RangeFrom,
+		To:        row.RangeTo,
+		Status:    domain.AssignmentProposalStatus(row.Status),
+		CreatedBy: row.CreatedBy.String,
+		CreatedAt: row.CreatedAt,
+	}
+	if row.AppliedAt.Valid {
+		t := row.AppliedAt.Time
+		proposal.AppliedAt = &t
+	}
+	for _, item := range items {
+		if !item.CaregiverID.Valid {
+			proposal.Unassigned = append(proposal.Unassigned, domain.UnassignedVisit{
+				ScheduleID: item.ScheduleID,
+				StartTime:  item.StartTime,
+				Reasons:    []string(item.Reasons),
+			})
+			continue
+		}
+		proposal.Assignments = append(proposal.Assignments, domain.ProposedAssignment{
+			ScheduleID:    item.ScheduleID,
+			CaregiverID:   item.CaregiverID.String,
+			CaregiverName: item.CaregiverName.String,
+			StartTime:     item.StartTime,
+			Score:         item.Score,
+			TravelKm:      item.TravelKm,
+			Reasons:       []string(item.Reasons),
+		})
+	}
+	return proposal, nil
+}
+
+func (r *AssignmentRepository) ApplyProposal(ctx context.Context, proposalID string, appliedAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	err = tx.GetContext(ctx, &status, `SELECT status FROM assignment_proposals WHERE id = $1 FOR UPDATE`, proposalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	if domain.AssignmentProposalStatus(status) != domain.AssignmentProposalDraft {
+		return domain.ErrInvalidStatusTransition
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE schedules s
+		SET caregiver_id = i.caregiver_id,
+		    claimed_at = $2,
+		    updated_at = NOW()
+		FROM assignment_proposal_items i
+		WHERE i.proposal_id = $1
+		  AND i.caregiver_id IS NOT NULL
+		  AND s.id = i.schedule_id
+		  AND s.caregiver_id IS NULL
+		  AND s.status = 'scheduled'
+	`, proposalID, appliedAt)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	var expected int64
+	err = tx.GetContext(ctx, &expected, `
+		SELECT COUNT(*) FROM assignment_proposal_items
+		WHERE proposal_id = $1 AND caregiver_id IS NOT NULL
+	`, proposalID)
+	if err != nil {
+		return err
+	}
+	if updated != expected {
+		return domain.ErrConflict
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE assignment_proposals
+		SET status = 'applied', applied_at = $2
+		WHERE id = $1
+	`, proposalID, appliedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
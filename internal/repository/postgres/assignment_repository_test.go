@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestAssignmentRepositoryApplyProposalConflictRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAssignmentRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM assignment_proposals WHERE id = \\$1 FOR UPDATE").
+		WithArgs("proposal-1").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectExec("UPDATE schedules s[\\s\\S]+s\\.caregiver_id IS NULL").
+		WithArgs("proposal-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM assignment_proposal_items").
+		WithArgs("proposal-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	if err := repo.ApplyProposal(context.Background(), "proposal-1", now); err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
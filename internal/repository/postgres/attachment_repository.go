@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// AttachmentRepository implements repository.AttachmentRepository.
+type AttachmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewAttachmentRepository constructs the repository.
+func NewAttachmentRepository(db *sqlx.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+const attachmentColumns = `
+	id, schedule_id, task_id, uploaded_by, file_name, content_type, size_bytes, sha256,
+	storage_key, thumbnail_key, caption, created_at
+`
+
+type attachmentRow struct {
+	ID           string         `db:"id"`
+	ScheduleID   string         `db:"schedule_id"`
+	TaskID       sql.NullString `db:"task_id"`
+	UploadedBy   string         `db:"uploaded_by"`
+	FileName     string         `db:"file_name"`
+	ContentType  string         `db:"content_type"`
+	SizeBytes    int64          `db:"size_bytes"`
+	SHA256       string         `db:"sha256"`
+	StorageKey   string         `db:"storage_key"`
+	ThumbnailKey sql.NullString `db:"thumbnail_key"`
+	Caption      sql.NullString `db:"caption"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment domain.Attachment) (domain.Attachment, error) {
+	var row attachmentRow
+	err := r.db.GetContext(ctx, &row, `
+		INSERT INTO attachments (id, schedule_id, task_id, uploaded_by, file_name, content_type, size_bytes, sha256,
+		                         storage_key, thumbnail_key, caption, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING`+attachmentColumns,
+		attachment.ID, attachment.ScheduleID, attachment.TaskID, attachment.UploadedBy, attachment.FileName,
+		attachment.ContentType, attachment.SizeBytes, attachment.SHA256, attachment.StorageKey,
+		attachment.ThumbnailKey, attachment.Caption, attachment.CreatedAt)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	return mapAttachment(row), nil
+}
+
+func (r *AttachmentRepository) Get(ctx context.Context, attachmentID string) (domain.Attachment, error) {
+	var row attachmentRow
+	err := r.db.GetContext(ctx, &row, `SELECT`+attachmentColumns+`FROM attachments WHERE id = $1`, attachmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Attachment{}, domain.ErrNotFound
+		}
+		return domain.Attachment{}, err
+	}
+	return mapAttachment(row), nil
+}
+
+func (r *AttachmentRepository) ListBySchedule(ctx context.Context, scheduleID string) ([]domain.Attachment, error) {
+	rows := []attachmentRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT`+attachmentColumns+`
+		FROM attachments
+		WHERE schedule_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.Attachment, len(rows))
+	for i, row := range rows {
+		result[i] = mapAttachment(row)
+	}
+	return result, nil
+}
+
+func mapAttachment(row attachmentRow) domain.Attachment {
+	return domain.Attachment{
+		ID:           row.ID,
+		ScheduleID:   row.ScheduleID,
+		TaskID:       nullStringPtr(row.TaskID),
+		UploadedBy:   row.UploadedBy,
+		FileName:     row.FileName,
+		ContentType:  row.ContentType,
+		SizeBytes:    row.SizeBytes,
+		SHA256:       row.SHA256,
+		StorageKey:   row.StorageKey,
+		ThumbnailKey: nullStringPtr(row.ThumbnailKey),
+		Caption:      nullStringPtr(row.Caption),
+		CreatedAt:    row.CreatedAt,
+	}
+}
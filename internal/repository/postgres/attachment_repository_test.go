@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+var attachmentTestColumns = []string{"id", "schedule_id", "task_id", "uploaded_by", "file_name", "content_type", "size_bytes", "sha256", "storage_key", "thumbnail_key", "caption", "created_at"}
+
+func TestAttachmentRepositoryCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAttachmentRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	thumb := "attachments/sched-1/att-1-thumb.jpg"
+	attachment := domain.Attachment{
+		ID:           "att-1",
+		ScheduleID:   "sched-1",
+		UploadedBy:   "cg-1",
+		FileName:     "wound.jpg",
+		ContentType:  "image/jpeg",
+		SizeBytes:    2048,
+		SHA256:       "abc",
+		StorageKey:   "attachments/sched-1/att-1.jpg",
+		ThumbnailKey: &thumb,
+		CreatedAt:    now,
+	}
+
+	mock.ExpectQuery(`INSERT INTO attachments .+ RETURNING`).
+		WithArgs("att-1", "sched-1", nil, "cg-1", "wound.jpg", "image/jpeg", int64(2048), "abc",
+			"attachments/sched-1/att-1.jpg", &thumb, nil, now).
+		WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+			AddRow("att-1", "sched-1", nil, "cg-1", "wound.jpg", "image/jpeg", 2048, "abc",
+				"attachments/sched-1/att-1.jpg", thumb, nil, now))
+
+	created, err := repo.Create(context.Background(), attachment)
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if created.TaskID != nil || created.ThumbnailKey == nil || *created.ThumbnailKey != thumb {
+		t.Fatalf("unexpected attachment: %+v", created)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAttachmentRepositoryGetAndList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAttachmentRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM attachments WHERE id = \$1`).
+		WithArgs("att-1").
+		WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+			AddRow("att-1", "sched-1", "task-1", "cg-1", "plan.pdf", "application/pdf", 512, "abc",
+				"attachments/sched-1/att-1.pdf", nil, "Care plan", now))
+	mock.ExpectQuery(`FROM attachments WHERE id = \$1`).
+		WithArgs("att-2").
+		WillReturnRows(sqlmock.NewRows(attachmentTestColumns))
+	mock.ExpectQuery(`FROM attachments\s+WHERE schedule_id = \$1\s+ORDER BY created_at ASC, id ASC`).
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+			AddRow("att-1", "sched-1", "task-1", "cg-1", "plan.pdf", "application/pdf", 512, "abc",
+				"attachments/sched-1/att-1.pdf", nil, "Care plan", now))
+
+	attachment, err := repo.Get(context.Background(), "att-1")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if attachment.TaskID == nil || *attachment.TaskID != "task-1" || attachment.ThumbnailKey != nil || *attachment.Caption != "Care plan" {
+		t.Fatalf("unexpected attachment: %+v", attachment)
+	}
+	if _, err := repo.Get(context.Background(), "att-2"); err != domain.ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	list, err := repo.ListBySchedule(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("ListBySchedule error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "att-1" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
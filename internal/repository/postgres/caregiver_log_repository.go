@@ -26,6 +26,7 @@ type caregiverLogRow struct {
 	ID          string         `db:"id"`
 	CaregiverID string         `db:"caregiver_id"`
 	LogType     string         `db:"log_type"`
+	BreakType   sql.NullString `db:"break_type"`
 	Latitude    float64        `db:"latitude"`
 	Longitude   float64        `db:"longitude"`
 	Timestamp   time.Time      `db:"timestamp"`
@@ -35,13 +36,39 @@ type caregiverLogRow struct {
 
 func (r *CaregiverLogRepository) CreateLog(ctx context.Context, log domain.CaregiverLog) (string, error) {
 	query := `
-		INSERT INTO logs_caregivers (caregiver_id, log_type, latitude, longitude, timestamp, notes)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO logs_caregivers (caregiver_id, log_type, latitude, longitude, timestamp, notes, break_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Serialise the caregiver's attendance writes so that two requests cannot
+	// both pass the transition check against the same latest log.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, log.CaregiverID); err != nil {
+		return "", err
+	}
+	var lastType string
+	err = tx.GetContext(ctx, &lastType, `
+		SELECT log_type
+		FROM logs_caregivers
+		WHERE caregiver_id = $1
+		ORDER BY timestamp DESC
+		LIMIT 1
+	`, log.CaregiverID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	if !log.LogType.CanFollow(domain.LogType(lastType)) {
+		return "", domain.ErrValidationFailure
+	}
+
 	var id string
-	err := r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		log.CaregiverID,
@@ -50,87 +77,131 @@ func (r *CaregiverLogRepository) CreateLog(ctx context.Context, log domain.Careg
 		log.Longitude,
 		log.Timestamp,
 		log.Notes,
+		log.BreakType,
 	).Scan(&id)
 
 	if err != nil {
 		return "", err
 	}
 
+	data := map[string]interface{}{"log_id": id, "log_type": log.LogType, "timestamp": log.Timestamp}
+	if log.BreakType != nil {
+		data["break_type"] = *log.BreakType
+	}
+	err = writeOutbox(ctx, tx, outboxEntry{
+		Type:        domain.EventAttendanceLogged,
+		CaregiverID: log.CaregiverID,
+		Data:        data,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
 	return id, nil
 }
 
 func (r *CaregiverLogRepository) GetLogsByCaregiver(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]domain.CaregiverLogSummary, error) {
-	query := `
+	query, args := filterLogs(`
 		SELECT id, caregiver_id, log_type, timestamp
 		FROM logs_caregivers
 		WHERE caregiver_id = $1
-	`
-	args := []interface{}{caregiverID}
-	argPosition := 2
+	`, caregiverID, filter)
+	query, args = pageLogs(query, args, filter)
 
-	if filter.LogType != nil {
-		query += " AND log_type = $" + itoa(argPosition)
-		args = append(args, *filter.LogType)
-		argPosition++
+	rows := []caregiverLogRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
 	}
 
-	if filter.StartDate != nil {
-		query += " AND timestamp >= $" + itoa(argPosition)
-		args = append(args, *filter.StartDate)
-		argPosition++
+	result := make([]domain.CaregiverLogSummary, len(rows))
+	for i, row := range rows {
+		result[i] = domain.CaregiverLogSummary{
+			ID:          row.ID,
+			CaregiverID: row.CaregiverID,
+			LogType:     domain.LogType(row.LogType),
+			Timestamp:   row.Timestamp,
+		}
 	}
 
-	if filter.EndDate != nil {
-		query += " AND timestamp <= $" + itoa(argPosition)
-		args = append(args, *filter.EndDate)
-		argPosition++
+	return result, nil
+}
+
+func (r *CaregiverLogRepository) ListLogs(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]domain.CaregiverLog, error) {
+	query, args := filterLogs(`
+		SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
+		FROM logs_caregivers
+		WHERE caregiver_id = $1
+	`, caregiverID, filter)
+
+	query, args = pageLogs(query, args, filter)
+
+	rows := []caregiverLogRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.CaregiverLog, len(rows))
+	for i, row := range rows {
+		result[i] = mapCaregiverLog(row)
 	}
 
-	query += " ORDER BY timestamp DESC"
+	return result, nil
+}
 
-	if filter.Limit > 0 {
-		query += " LIMIT $" + itoa(argPosition)
-		args = append(args, filter.Limit)
-		argPosition++
+func (r *CaregiverLogRepository) CountLogs(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) (int, error) {
+	query, args := filterLogs(`
+		SELECT COUNT(*)
+		FROM logs_caregivers
+		WHERE caregiver_id = $1
+	`, caregiverID, filter)
 
-		if filter.Offset > 0 {
-			query += " OFFSET $" + itoa(argPosition)
-			args = append(args, filter.Offset)
-		}
+	var total int
+	if err := r.db.GetContext(ctx, &total, query, args...); err != nil {
+		return 0, err
 	}
 
+	return total, nil
+}
+
+func (r *CaregiverLogRepository) GetSessionLogs(ctx context.Context, caregiverID string, from, through time.Time) ([]domain.CaregiverLog, error) {
+	query := `
+		SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
+		FROM logs_caregivers
+		WHERE caregiver_id = $1 AND timestamp >= $2
+		AND timestamp < COALESCE((
+			SELECT MIN(timestamp) FROM logs_caregivers
+			WHERE caregiver_id = $1 AND log_type = 'clock_in' AND timestamp > $3
+		), 'infinity')
+		ORDER BY timestamp ASC
+	`
+
 	rows := []caregiverLogRow{}
-	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+	err := r.db.SelectContext(ctx, &rows, query, caregiverID, from, through)
+	if err != nil {
 		return nil, err
 	}
 
-	result := make([]domain.CaregiverLogSummary, len(rows))
+	result := make([]domain.CaregiverLog, len(rows))
 	for i, row := range rows {
-		result[i] = domain.CaregiverLogSummary{
-			ID:          row.ID,
-			CaregiverID: row.CaregiverID,
-			LogType:     domain.LogType(row.LogType),
-			Timestamp:   row.Timestamp,
-		}
+		result[i] = mapCaregiverLog(row)
 	}
 
 	return result, nil
 }
 
-func (r *CaregiverLogRepository) GetTodayLogs(ctx context.Context, caregiverID string) ([]domain.CaregiverLog, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	tomorrow := today.Add(24 * time.Hour)
-
+func (r *CaregiverLogRepository) GetLogsBetween(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.CaregiverLog, error) {
 	query := `
-		SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+		SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
 		FROM logs_caregivers
 		WHERE caregiver_id = $1 AND timestamp >= $2 AND timestamp < $3
 		ORDER BY timestamp ASC
 	`
 
 	rows := []caregiverLogRow{}
-	err := r.db.SelectContext(ctx, &rows, query, caregiverID, today, tomorrow)
+	err := r.db.SelectContext(ctx, &rows, query, caregiverID, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -143,11 +214,11 @@ func (r *CaregiverLogRepository) GetTodayLogs(ctx context.Context, caregiverID s
 	return result, nil
 }
 
-func (r *CaregiverLogRepository) GetLastClockIn(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
+func (r *CaregiverLogRepository) GetLastLog(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
 	query := `
-		SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+		SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
 		FROM logs_caregivers
-		WHERE caregiver_id = $1 AND log_type = 'clock_in'
+		WHERE caregiver_id = $1
 		ORDER BY timestamp DESC
 		LIMIT 1
 	`
@@ -165,11 +236,11 @@ func (r *CaregiverLogRepository) GetLastClockIn(ctx context.Context, caregiverID
 	return &log, nil
 }
 
-func (r *CaregiverLogRepository) GetLastClockOut(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
+func (r *CaregiverLogRepository) GetLastClockIn(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
 	query := `
-		SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+		SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
 		FROM logs_caregivers
-		WHERE caregiver_id = $1 AND log_type = 'clock_out'
+		WHERE caregiver_id = $1 AND log_type = 'clock_in'
 		ORDER BY timestamp DESC
 		LIMIT 1
 	`
@@ -187,48 +258,26 @@ func (r *CaregiverLogRepository) GetLastClockOut(ctx context.Context, caregiverI
 	return &log, nil
 }
 
-func (r *CaregiverLogRepository) HasClockedInToday(ctx context.Context, caregiverID string) (bool, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	tomorrow := today.Add(24 * time.Hour)
-
-	query := `
-		SELECT EXISTS(
-			SELECT 1 FROM logs_caregivers
-			WHERE caregiver_id = $1 AND log_type = 'clock_in'
-			AND timestamp >= $2 AND timestamp < $3
-		)
-	`
-
-	var exists bool
-	err := r.db.GetContext(ctx, &exists, query, caregiverID, today, tomorrow)
-	if err != nil {
-		return false, err
-	}
-
-	return exists, nil
-}
-
-func (r *CaregiverLogRepository) HasClockedOutToday(ctx context.Context, caregiverID string) (bool, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	tomorrow := today.Add(24 * time.Hour)
-
+func (r *CaregiverLogRepository) GetLastClockOut(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
 	query := `
-		SELECT EXISTS(
-			SELECT 1 FROM logs_caregivers
-			WHERE caregiver_id = $1 AND log_type = 'clock_out'
-			AND timestamp >= $2 AND timestamp < $3
-		)
+		SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
+		FROM logs_caregivers
+		WHERE caregiver_id = $1 AND log_type = 'clock_out'
+		ORDER BY timestamp DESC
+		LIMIT 1
 	`
 
-	var exists bool
-	err := r.db.GetContext(ctx, &exists, query, caregiverID, today, tomorrow)
+	var row caregiverLogRow
+	err := r.db.GetContext(ctx, &row, query, caregiverID)
 	if err != nil {
-		return false, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
 	}
 
-	return exists, nil
+	log := mapCaregiverLog(row)
+	return &log, nil
 }
 
 func mapCaregiverLog(row caregiverLogRow) domain.CaregiverLog {
@@ -238,14 +287,68 @@ func mapCaregiverLog(row caregiverLogRow) domain.CaregiverLog {
 		notes = &val
 	}
 
+	var breakType *domain.BreakType
+	if row.BreakType.Valid {
+		val := domain.BreakType(row.BreakType.String)
+		breakType = &val
+	}
+
 	return domain.CaregiverLog{
 		ID:          row.ID,
 		CaregiverID: row.CaregiverID,
 		LogType:     domain.LogType(row.LogType),
+		BreakType:   breakType,
 		Latitude:    row.Latitude,
 		Longitude:   row.Longitude,
 		Timestamp:   row.Timestamp,
 		Notes:       notes,
 		CreatedAt:   row.CreatedAt,
 	}
-}
\ No newline at end of file
+}
+
+// filterLogs appends the filter's type and date conditions to a query whose
+// only placeholder so far is the caregiver ID.
+func filterLogs(query, caregiverID string, filter repository.CaregiverLogFilter) (string, []interface{}) {
+	args := []interface{}{caregiverID}
+
+	if filter.LogType != nil {
+		args = append(args, *filter.LogType)
+		query += " AND log_type = $" + itoa(len(args))
+	}
+
+	if filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		query += " AND timestamp >= $" + itoa(len(args))
+	}
+
+	if filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		query += " AND timestamp < $" + itoa(len(args))
+	}
+
+	return query, args
+}
+
+// pageLogs orders a filtered query newest first and applies the filter's
+// cursor, or its offset when there is no cursor, and limit.
+func pageLogs(query string, args []interface{}, filter repository.CaregiverLogFilter) (string, []interface{}) {
+	if filter.After != nil {
+		// Keyset continuation; id breaks ties between logs with equal timestamps.
+		query += " AND (timestamp, id) < ($" + itoa(len(args)+1) + ", $" + itoa(len(args)+2) + ")"
+		args = append(args, filter.After.Time, filter.After.ID)
+	}
+
+	query += " ORDER BY timestamp DESC, id DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT $" + itoa(len(args)+1)
+		args = append(args, filter.Limit)
+
+		if filter.Offset > 0 && filter.After == nil {
+			query += " OFFSET $" + itoa(len(args)+1)
+			args = append(args, filter.Offset)
+		}
+	}
+
+	return query, args
+}
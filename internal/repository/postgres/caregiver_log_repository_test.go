@@ -33,13 +33,24 @@ func TestCaregiverLogRepositoryCreateLog(t *testing.T) {
 		Notes:       nil,
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+		WithArgs("caregiver-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT log_type\s+FROM logs_caregivers`).
+		WithArgs("caregiver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"log_type"}).AddRow("clock_out"))
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        INSERT INTO logs_caregivers (caregiver_id, log_type, latitude, longitude, timestamp, notes)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO logs_caregivers (caregiver_id, log_type, latitude, longitude, timestamp, notes, break_type)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
         RETURNING id
     `)).
-		WithArgs(log.CaregiverID, log.LogType, log.Latitude, log.Longitude, log.Timestamp, log.Notes).
+		WithArgs(log.CaregiverID, log.LogType, log.Latitude, log.Longitude, log.Timestamp, log.Notes, log.BreakType).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("log-123"))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventAttendanceLogged, "caregiver-1", nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	id, err := repo.CreateLog(context.Background(), log)
 	if err != nil {
@@ -75,13 +86,24 @@ func TestCaregiverLogRepositoryCreateLogWithNotes(t *testing.T) {
 		Notes:       &notes,
 	}
 
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+		WithArgs("caregiver-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT log_type\s+FROM logs_caregivers`).
+		WithArgs("caregiver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"log_type"}).AddRow("clock_in"))
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        INSERT INTO logs_caregivers (caregiver_id, log_type, latitude, longitude, timestamp, notes)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO logs_caregivers (caregiver_id, log_type, latitude, longitude, timestamp, notes, break_type)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
         RETURNING id
     `)).
-		WithArgs(log.CaregiverID, log.LogType, log.Latitude, log.Longitude, log.Timestamp, log.Notes).
+		WithArgs(log.CaregiverID, log.LogType, log.Latitude, log.Longitude, log.Timestamp, log.Notes, log.BreakType).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("log-456"))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventAttendanceLogged, "caregiver-1", nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	id, err := repo.CreateLog(context.Background(), log)
 	if err != nil {
@@ -96,6 +118,37 @@ func TestCaregiverLogRepositoryCreateLogWithNotes(t *testing.T) {
 	}
 }
 
+func TestCaregiverLogRepositoryCreateLogRejectsInvalidTransition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewCaregiverLogRepository(sqlx.NewDb(db, "pgx"))
+	log := domain.CaregiverLog{
+		CaregiverID: "caregiver-1",
+		LogType:     domain.LogTypeBreakStart,
+		Timestamp:   time.Now(),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+		WithArgs("caregiver-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT log_type\s+FROM logs_caregivers`).
+		WithArgs("caregiver-1").
+		WillReturnRows(sqlmock.NewRows([]string{"log_type"}))
+	mock.ExpectRollback()
+
+	if _, err := repo.CreateLog(context.Background(), log); err != domain.ErrValidationFailure {
+		t.Fatalf("expected a break without a shift to be rejected, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestCaregiverLogRepositoryGetLogsByCaregiver(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -111,7 +164,7 @@ func TestCaregiverLogRepositoryGetLogsByCaregiver(t *testing.T) {
 		AddRow("log-1", "caregiver-1", "clock_in", now).
 		AddRow("log-2", "caregiver-1", "clock_out", now.Add(time.Hour))
 
-	mock.ExpectQuery("SELECT id, caregiver_id, log_type, timestamp FROM logs_caregivers WHERE caregiver_id = \\$1 ORDER BY timestamp DESC").
+	mock.ExpectQuery("SELECT id, caregiver_id, log_type, timestamp FROM logs_caregivers WHERE caregiver_id = \\$1 ORDER BY timestamp DESC, id DESC").
 		WithArgs("caregiver-1").
 		WillReturnRows(rows)
 
@@ -156,7 +209,7 @@ func TestCaregiverLogRepositoryGetLogsByCaregiverWithFilter(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "log_type", "timestamp"}).
 		AddRow("log-1", "caregiver-1", "clock_in", now)
 
-	mock.ExpectQuery("SELECT id, caregiver_id, log_type, timestamp FROM logs_caregivers WHERE caregiver_id = \\$1 AND log_type = \\$2 AND timestamp >= \\$3 AND timestamp <= \\$4 ORDER BY timestamp DESC LIMIT \\$5 OFFSET \\$6").
+	mock.ExpectQuery("SELECT id, caregiver_id, log_type, timestamp FROM logs_caregivers WHERE caregiver_id = \\$1 AND log_type = \\$2 AND timestamp >= \\$3 AND timestamp < \\$4 ORDER BY timestamp DESC, id DESC LIMIT \\$5 OFFSET \\$6").
 		WithArgs("caregiver-1", *filter.LogType, *filter.StartDate, *filter.EndDate, filter.Limit, filter.Offset).
 		WillReturnRows(rows)
 
@@ -173,7 +226,7 @@ func TestCaregiverLogRepositoryGetLogsByCaregiverWithFilter(t *testing.T) {
 	}
 }
 
-func TestCaregiverLogRepositoryGetTodayLogs(t *testing.T) {
+func TestCaregiverLogRepositoryGetLogsBetween(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("sqlmock init: %v", err)
@@ -184,24 +237,24 @@ func TestCaregiverLogRepositoryGetTodayLogs(t *testing.T) {
 	repo := NewCaregiverLogRepository(sqlxDB)
 
 	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	_ = today.Add(24 * time.Hour) // tomorrow calculation for reference
+	today := time.Date(2025, 1, 15, 6, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
 
 	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "log_type", "latitude", "longitude", "timestamp", "notes", "created_at"}).
 		AddRow("log-1", "caregiver-1", "clock_in", 40.7128, -74.0060, today.Add(9*time.Hour), nil, now)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+        SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
         FROM logs_caregivers
         WHERE caregiver_id = $1 AND timestamp >= $2 AND timestamp < $3
         ORDER BY timestamp ASC
     `)).
-		WithArgs("caregiver-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs("caregiver-1", today, tomorrow).
 		WillReturnRows(rows)
 
-	logs, err := repo.GetTodayLogs(context.Background(), "caregiver-1")
+	logs, err := repo.GetLogsBetween(context.Background(), "caregiver-1", today, tomorrow)
 	if err != nil {
-		t.Fatalf("GetTodayLogs error: %v", err)
+		t.Fatalf("GetLogsBetween error: %v", err)
 	}
 	if len(logs) != 1 {
 		t.Fatalf("expected 1 log, got %d", len(logs))
@@ -230,7 +283,7 @@ func TestCaregiverLogRepositoryGetLastClockIn(t *testing.T) {
 		AddRow("log-1", "caregiver-1", "clock_in", 40.7128, -74.0060, now, nil, now)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+        SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
         FROM logs_caregivers
         WHERE caregiver_id = $1 AND log_type = 'clock_in'
         ORDER BY timestamp DESC
@@ -263,7 +316,7 @@ func TestCaregiverLogRepositoryGetLastClockInNotFound(t *testing.T) {
 	repo := NewCaregiverLogRepository(sqlxDB)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+        SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
         FROM logs_caregivers
         WHERE caregiver_id = $1 AND log_type = 'clock_in'
         ORDER BY timestamp DESC
@@ -297,7 +350,7 @@ func TestCaregiverLogRepositoryGetLastClockOut(t *testing.T) {
 		AddRow("log-2", "caregiver-1", "clock_out", 40.7128, -74.0060, now, nil, now)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT id, caregiver_id, log_type, latitude, longitude, timestamp, notes, created_at
+        SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
         FROM logs_caregivers
         WHERE caregiver_id = $1 AND log_type = 'clock_out'
         ORDER BY timestamp DESC
@@ -319,7 +372,7 @@ func TestCaregiverLogRepositoryGetLastClockOut(t *testing.T) {
 	}
 }
 
-func TestCaregiverLogRepositoryHasClockedInToday(t *testing.T) {
+func TestCaregiverLogRepositoryGetLastLog(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("sqlmock init: %v", err)
@@ -329,24 +382,26 @@ func TestCaregiverLogRepositoryHasClockedInToday(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "pgx")
 	repo := NewCaregiverLogRepository(sqlxDB)
 
-	rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "log_type", "latitude", "longitude", "timestamp", "notes", "created_at"}).
+		AddRow("log-2", "caregiver-1", "clock_out", 40.7128, -74.0060, now, nil, now)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT EXISTS(
-            SELECT 1 FROM logs_caregivers
-            WHERE caregiver_id = $1 AND log_type = 'clock_in'
-            AND timestamp >= $2 AND timestamp < $3
-        )
+        SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
+        FROM logs_caregivers
+        WHERE caregiver_id = $1
+        ORDER BY timestamp DESC
+        LIMIT 1
     `)).
-		WithArgs("caregiver-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs("caregiver-1").
 		WillReturnRows(rows)
 
-	hasClockedIn, err := repo.HasClockedInToday(context.Background(), "caregiver-1")
+	log, err := repo.GetLastLog(context.Background(), "caregiver-1")
 	if err != nil {
-		t.Fatalf("HasClockedInToday error: %v", err)
+		t.Fatalf("GetLastLog error: %v", err)
 	}
-	if !hasClockedIn {
-		t.Fatalf("expected hasClockedIn to be true")
+	if log == nil || log.ID != "log-2" || log.LogType != domain.LogTypeClockOut {
+		t.Fatalf("unexpected log: %+v", log)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -354,7 +409,7 @@ func TestCaregiverLogRepositoryHasClockedInToday(t *testing.T) {
 	}
 }
 
-func TestCaregiverLogRepositoryHasClockedOutToday(t *testing.T) {
+func TestCaregiverLogRepositoryGetLastLogBreak(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("sqlmock init: %v", err)
@@ -364,27 +419,127 @@ func TestCaregiverLogRepositoryHasClockedOutToday(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "pgx")
 	repo := NewCaregiverLogRepository(sqlxDB)
 
-	rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "log_type", "break_type", "latitude", "longitude", "timestamp", "notes", "created_at"}).
+		AddRow("log-3", "caregiver-1", "break_start", "meal", 40.7128, -74.0060, now, nil, now)
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT EXISTS(
-            SELECT 1 FROM logs_caregivers
-            WHERE caregiver_id = $1 AND log_type = 'clock_out'
-            AND timestamp >= $2 AND timestamp < $3
-        )
+        SELECT id, caregiver_id, log_type, break_type, latitude, longitude, timestamp, notes, created_at
+        FROM logs_caregivers
+        WHERE caregiver_id = $1
+        ORDER BY timestamp DESC
+        LIMIT 1
     `)).
-		WithArgs("caregiver-1", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs("caregiver-1").
+		WillReturnRows(rows)
+
+	log, err := repo.GetLastLog(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("GetLastLog error: %v", err)
+	}
+	if log.LogType != domain.LogTypeBreakStart || log.BreakType == nil || *log.BreakType != domain.BreakTypeMeal {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCaregiverLogRepositoryListLogsAfterCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+	repo := NewCaregiverLogRepository(sqlxDB)
+
+	now := time.Now()
+	logType := domain.LogTypeClockIn
+	cursor := domain.Cursor{Time: now, ID: "log-9"}
+	filter := repository.CaregiverLogFilter{LogType: &logType, After: &cursor, Limit: 3}
+
+	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "log_type", "break_type", "latitude", "longitude", "timestamp", "notes", "created_at"}).
+		AddRow("log-8", "caregiver-1", "clock_in", nil, 40.7, -74.0, now.Add(-time.Hour), "front door", now)
+
+	mock.ExpectQuery("FROM logs_caregivers WHERE caregiver_id = \\$1 AND log_type = \\$2 AND \\(timestamp, id\\) < \\(\\$3, \\$4\\) ORDER BY timestamp DESC, id DESC LIMIT \\$5").
+		WithArgs("caregiver-1", logType, cursor.Time, cursor.ID, 3).
+		WillReturnRows(rows)
+
+	logs, err := repo.ListLogs(context.Background(), "caregiver-1", filter)
+	if err != nil {
+		t.Fatalf("ListLogs error: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Notes == nil || *logs[0].Notes != "front door" || logs[0].Latitude != 40.7 {
+		t.Fatalf("expected full log records, got %+v", logs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCaregiverLogRepositoryCountLogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+	repo := NewCaregiverLogRepository(sqlxDB)
+
+	startDate := time.Now().Add(-24 * time.Hour)
+	filter := repository.CaregiverLogFilter{StartDate: &startDate, Limit: 10, Offset: 20}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM logs_caregivers WHERE caregiver_id = \\$1 AND timestamp >= \\$2$").
+		WithArgs("caregiver-1", startDate).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	total, err := repo.CountLogs(context.Background(), "caregiver-1", filter)
+	if err != nil {
+		t.Fatalf("CountLogs error: %v", err)
+	}
+	if total != 42 {
+		t.Fatalf("expected 42, got %d", total)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCaregiverLogRepositoryGetSessionLogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+	repo := NewCaregiverLogRepository(sqlxDB)
+
+	from := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	through := time.Date(2025, 1, 16, 22, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "log_type", "break_type", "latitude", "longitude", "timestamp", "notes", "created_at"}).
+		AddRow("log-1", "caregiver-1", "clock_in", nil, 0.0, 0.0, from, nil, from).
+		AddRow("log-2", "caregiver-1", "clock_out", nil, 0.0, 0.0, through.Add(4*time.Hour), nil, from)
+
+	mock.ExpectQuery("WHERE caregiver_id = \\$1 AND timestamp >= \\$2 AND timestamp < COALESCE\\(\\( SELECT MIN\\(timestamp\\) FROM logs_caregivers WHERE caregiver_id = \\$1 AND log_type = 'clock_in' AND timestamp > \\$3 \\), 'infinity'\\) ORDER BY timestamp ASC").
+		WithArgs("caregiver-1", from, through).
 		WillReturnRows(rows)
 
-	hasClockedOut, err := repo.HasClockedOutToday(context.Background(), "caregiver-1")
+	logs, err := repo.GetSessionLogs(context.Background(), "caregiver-1", from, through)
 	if err != nil {
-		t.Fatalf("HasClockedOutToday error: %v", err)
+		t.Fatalf("GetSessionLogs error: %v", err)
 	}
-	if hasClockedOut {
-		t.Fatalf("expected hasClockedOut to be false")
+	if len(logs) != 2 || logs[1].LogType != domain.LogTypeClockOut {
+		t.Fatalf("unexpected logs: %+v", logs)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Fatalf("unmet expectations: %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // CaregiverRepository implements repository.CaregiverRepository.
@@ -34,3 +35,45 @@ func (r *CaregiverRepository) GetByID(ctx context.Context, caregiverID string) (
 	}
 	return caregiver, nil
 }
+
+type caregiverProfileRow struct {
+	ID             string          `db:"id"`
+	Name           string          `db:"name"`
+	Email          string          `db:"email"`
+	HomeLatitude   sql.NullFloat64 `db:"home_latitude"`
+	HomeLongitude  sql.NullFloat64 `db:"home_longitude"`
+	Qualifications pq.StringArray  `db:"qualifications"`
+	Timezone       sql.NullString  `db:"timezone"`
+}
+
+func (r *CaregiverRepository) GetProfile(ctx context.Context, caregiverID string) (domain.Caregiver, error) {
+	query := `
+		SELECT id, name, email, home_latitude, home_longitude, qualifications, timezone
+		FROM caregivers
+		WHERE id = $1
+	`
+	var row caregiverProfileRow
+	if err := r.db.GetContext(ctx, &row, query, caregiverID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Caregiver{}, domain.ErrNotFound
+		}
+		return domain.Caregiver{}, err
+	}
+	return mapCaregiverProfile(row), nil
+}
+
+func mapCaregiverProfile(row caregiverProfileRow) domain.Caregiver {
+	caregiver := domain.Caregiver{
+		ID:             row.ID,
+		Name:           row.Name,
+		Email:          row.Email,
+		Qualifications: []string(row.Qualifications),
+		Timezone:       row.Timezone.String,
+	}
+	if row.HomeLatitude.Valid && row.HomeLongitude.Valid {
+		lat, long := row.HomeLatitude.Float64, row.HomeLongitude.Float64
+		caregiver.HomeLatitude = &lat
+		caregiver.HomeLongitude = &long
+	}
+	return caregiver
+}
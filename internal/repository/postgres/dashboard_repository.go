@@ -0,0 +1,283 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// DashboardRepository implements repository.DashboardRepository.
+type DashboardRepository struct {
+	db *sqlx.DB
+}
+
+// NewDashboardRepository constructs the repository.
+func NewDashboardRepository(db *sqlx.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// dashboardBounds binds the filter's range and clock once so every dashboard
+// query can read them from b whether or not it uses all three.
+const dashboardBounds = `bounds AS (SELECT $1::timestamptz AS from_at, $2::timestamptz AS to_at, $3::timestamptz AS now_at)`
+
+// Visit states shared by the summary and the lists, over schedules s and bounds b.
+const (
+	dashboardInRange   = `s.start_time >= b.from_at AND s.start_time < b.to_at`
+	dashboardUnstarted = `s.status = 'scheduled' AND s.clock_in_at IS NULL AND s.end_time > b.now_at`
+	dashboardLate      = `s.start_time + make_interval(mins => s.flexible_window_mins) < b.now_at`
+	dashboardMissed    = `(s.status = 'missed' OR (s.status = 'scheduled' AND s.clock_in_at IS NULL AND s.end_time <= b.now_at))`
+)
+
+// caregiverScope returns the conditions restricting caregivers aliased cg to
+// the filter's region and team, appending their values to args.
+func caregiverScope(filter repository.DashboardFilter, args []interface{}) ([]string, []interface{}) {
+	var conds []string
+	if filter.Region != "" {
+		args = append(args, filter.Region)
+		conds = append(conds, "cg.region = $"+itoa(len(args)))
+	}
+	if filter.Team != "" {
+		args = append(args, filter.Team)
+		conds = append(conds, "cg.team = $"+itoa(len(args)))
+	}
+	return conds, args
+}
+
+type dashboardSummaryRow struct {
+	Visits            int `db:"visits"`
+	InProgress        int `db:"in_progress"`
+	Upcoming          int `db:"upcoming"`
+	Late              int `db:"late"`
+	Missed            int `db:"missed"`
+	Completed         int `db:"completed"`
+	Cancelled         int `db:"cancelled"`
+	OpenIncidents     int `db:"open_incidents"`
+	CaregiversOnShift int `db:"caregivers_on_shift"`
+	CaregiversOnBreak int `db:"caregivers_on_break"`
+}
+
+func (r *DashboardRepository) GetSummary(ctx context.Context, filter repository.DashboardFilter) (domain.DashboardSummary, error) {
+	conds, args := caregiverScope(filter, []interface{}{filter.From, filter.To, filter.Now})
+	scope := ""
+	if len(conds) > 0 {
+		scope = " AND " + strings.Join(conds, " AND ")
+	}
+
+	// Visits in progress are counted even when they started before the range,
+	// so overnight visits stay on the dashboard.
+	query := `
+		WITH ` + dashboardBounds + `,
+		latest_logs AS (
+			SELECT DISTINCT ON (l.caregiver_id) l.log_type
+			FROM logs_caregivers l
+			INNER JOIN caregivers cg ON cg.id = l.caregiver_id
+			CROSS JOIN bounds b
+			WHERE l.timestamp <= b.now_at` + scope + `
+			ORDER BY l.caregiver_id, l.timestamp DESC, l.id DESC
+		)
+		SELECT COUNT(*) FILTER (WHERE ` + dashboardInRange + `) AS visits,
+		       COUNT(*) FILTER (WHERE s.status = 'in_progress') AS in_progress,
+		       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND ` + dashboardUnstarted + ` AND NOT (` + dashboardLate + `)) AS upcoming,
+		       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND ` + dashboardUnstarted + ` AND ` + dashboardLate + `) AS late,
+		       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND ` + dashboardMissed + `) AS missed,
+		       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND s.status = 'completed') AS completed,
+		       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND s.status = 'cancelled') AS cancelled,
+		       (
+		           SELECT COUNT(*)
+		           FROM incidents i
+		           INNER JOIN caregivers cg ON cg.id = i.caregiver_id
+		           WHERE i.status = 'open'` + scope + `
+		       ) AS open_incidents,
+		       (SELECT COUNT(*) FROM latest_logs WHERE log_type IN ('clock_in','break_end')) AS caregivers_on_shift,
+		       (SELECT COUNT(*) FROM latest_logs WHERE log_type = 'break_start') AS caregivers_on_break
+		FROM schedules s
+		INNER JOIN caregivers cg ON cg.id = s.caregiver_id
+		CROSS JOIN bounds b
+		WHERE ((` + dashboardInRange + `) OR s.status = 'in_progress')` + scope
+
+	var row dashboardSummaryRow
+	if err := r.db.GetContext(ctx, &row, query, args...); err != nil {
+		return domain.DashboardSummary{}, err
+	}
+	return domain.DashboardSummary{
+		Visits:            row.Visits,
+		InProgress:        row.InProgress,
+		Upcoming:          row.Upcoming,
+		Late:              row.Late,
+		Missed:            row.Missed,
+		Completed:         row.Completed,
+		Cancelled:         row.Cancelled,
+		OpenIncidents:     row.OpenIncidents,
+		CaregiversOnShift: row.CaregiversOnShift,
+		CaregiversOnBreak: row.CaregiversOnBreak,
+	}, nil
+}
+
+type dashboardVisitRow struct {
+	ID            string         `db:"id"`
+	CaregiverID   string         `db:"caregiver_id"`
+	CaregiverName string         `db:"caregiver_name"`
+	Region        sql.NullString `db:"region"`
+	Team          sql.NullString `db:"team"`
+	ClientID      string         `db:"client_id"`
+	ClientName    string         `db:"client_name"`
+	ServiceName   string         `db:"service_name"`
+	StartTime     time.Time      `db:"start_time"`
+	EndTime       time.Time      `db:"end_time"`
+	Status        string         `db:"status"`
+	ClockInAt     sql.NullTime   `db:"clock_in_at"`
+	Late          bool           `db:"late"`
+}
+
+func (r *DashboardRepository) ListVisits(ctx context.Context, filter repository.DashboardFilter, state domain.DashboardVisitState) ([]domain.DashboardVisit, error) {
+	var where string
+	switch state {
+	case domain.DashboardVisitLive:
+		// Like the summary, live visits include those started before the range.
+		where = `s.status = 'in_progress'`
+	case domain.DashboardVisitUnstarted:
+		where = dashboardInRange + ` AND ` + dashboardUnstarted
+	case domain.DashboardVisitMissed:
+		where = dashboardInRange + ` AND ` + dashboardMissed
+	default:
+		return nil, domain.ErrValidationFailure
+	}
+	conds, args := caregiverScope(filter, []interface{}{filter.From, filter.To, filter.Now})
+	for _, cond := range conds {
+		where += " AND " + cond
+	}
+
+	query := `
+		WITH ` + dashboardBounds + `
+		SELECT s.id,
+		       s.caregiver_id,
+		       cg.name AS caregiver_name,
+		       cg.region,
+		       cg.team,
+		       s.client_id,
+		       c.full_name AS client_name,
+		       s.service_name,
+		       s.start_time,
+		       s.end_time,
+		       s.status,
+		       s.clock_in_at,
+		       (` + dashboardUnstarted + ` AND ` + dashboardLate + `) AS late
+		FROM schedules s
+		INNER JOIN caregivers cg ON cg.id = s.caregiver_id
+		INNER JOIN clients c ON c.id = s.client_id
+		CROSS JOIN bounds b
+		WHERE ` + where + `
+		ORDER BY s.start_time ASC, s.id ASC
+	`
+
+	rows := []dashboardVisitRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	visits := make([]domain.DashboardVisit, 0, len(rows))
+	for _, row := range rows {
+		visits = append(visits, domain.DashboardVisit{
+			ScheduleID:    row.ID,
+			CaregiverID:   row.CaregiverID,
+			CaregiverName: row.CaregiverName,
+			Region:        nullStringPtr(row.Region),
+			Team:          nullStringPtr(row.Team),
+			ClientID:      row.ClientID,
+			ClientName:    row.ClientName,
+			ServiceName:   row.ServiceName,
+			StartTime:     row.StartTime,
+			EndTime:       row.EndTime,
+			Status:        domain.ScheduleStatus(row.Status),
+			ClockInAt:     nullTimePtr(row.ClockInAt),
+			Late:          row.Late,
+		})
+	}
+	return visits, nil
+}
+
+type caregiverDayStatusRow struct {
+	ID             string         `db:"id"`
+	Name           string         `db:"name"`
+	Region         sql.NullString `db:"region"`
+	Team           sql.NullString `db:"team"`
+	LastLogType    sql.NullString `db:"last_log_type"`
+	LastEventAt    sql.NullTime   `db:"last_event_at"`
+	CurrentVisitID sql.NullString `db:"current_visit_id"`
+	NextVisitAt    sql.NullTime   `db:"next_visit_at"`
+	Visits         int            `db:"visits"`
+	Completed      int            `db:"completed"`
+	Missed         int            `db:"missed"`
+	OpenIncidents  int            `db:"open_incidents"`
+}
+
+func (r *DashboardRepository) ListCaregiverStatus(ctx context.Context, filter repository.DashboardFilter) ([]domain.CaregiverDayStatus, error) {
+	conds, args := caregiverScope(filter, []interface{}{filter.From, filter.To, filter.Now})
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	query := `
+		WITH ` + dashboardBounds + `
+		SELECT cg.id,
+		       cg.name,
+		       cg.region,
+		       cg.team,
+		       l.log_type AS last_log_type,
+		       l.timestamp AS last_event_at,
+		       v.current_visit_id,
+		       v.next_visit_at,
+		       COALESCE(v.visits, 0) AS visits,
+		       COALESCE(v.completed, 0) AS completed,
+		       COALESCE(v.missed, 0) AS missed,
+		       (SELECT COUNT(*) FROM incidents i WHERE i.caregiver_id = cg.id AND i.status = 'open') AS open_incidents
+		FROM caregivers cg
+		CROSS JOIN bounds b
+		LEFT JOIN LATERAL (
+			SELECT log_type, timestamp
+			FROM logs_caregivers
+			WHERE caregiver_id = cg.id AND timestamp <= b.now_at
+			ORDER BY timestamp DESC, id DESC
+			LIMIT 1
+		) l ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) FILTER (WHERE ` + dashboardInRange + `) AS visits,
+			       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND s.status = 'completed') AS completed,
+			       COUNT(*) FILTER (WHERE ` + dashboardInRange + ` AND ` + dashboardMissed + `) AS missed,
+			       (array_agg(s.id::text ORDER BY s.start_time) FILTER (WHERE s.status = 'in_progress'))[1] AS current_visit_id,
+			       MIN(s.start_time) FILTER (WHERE ` + dashboardInRange + ` AND ` + dashboardUnstarted + `) AS next_visit_at
+			FROM schedules s
+			WHERE s.caregiver_id = cg.id AND ((` + dashboardInRange + `) OR s.status = 'in_progress')
+		) v ON TRUE
+		` + where + `
+		ORDER BY cg.name ASC, cg.id ASC
+	`
+
+	rows := []caregiverDayStatusRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	statuses := make([]domain.CaregiverDayStatus, 0, len(rows))
+	for _, row := range rows {
+		statuses = append(statuses, domain.CaregiverDayStatus{
+			CaregiverID:    row.ID,
+			Name:           row.Name,
+			Region:         nullStringPtr(row.Region),
+			Team:           nullStringPtr(row.Team),
+			Attendance:     domain.AttendanceStateAfter(domain.LogType(row.LastLogType.String)),
+			LastEventAt:    nullTimePtr(row.LastEventAt),
+			CurrentVisitID: nullStringPtr(row.CurrentVisitID),
+			NextVisitAt:    nullTimePtr(row.NextVisitAt),
+			Visits:         row.Visits,
+			Completed:      row.Completed,
+			Missed:         row.Missed,
+			OpenIncidents:  row.OpenIncidents,
+		})
+	}
+	return statuses, nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+func dashboardTestFilter() repository.DashboardFilter {
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	return repository.DashboardFilter{
+		From:   from,
+		To:     from.AddDate(0, 0, 1),
+		Now:    from.Add(10 * time.Hour),
+		Region: "Minneapolis",
+		Team:   "North",
+	}
+}
+
+func TestDashboardRepositoryGetSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDashboardRepository(sqlx.NewDb(db, "pgx"))
+	filter := dashboardTestFilter()
+	rows := sqlmock.NewRows([]string{"visits", "in_progress", "upcoming", "late", "missed", "completed", "cancelled",
+		"open_incidents", "caregivers_on_shift", "caregivers_on_break"}).
+		AddRow(9, 2, 3, 1, 1, 1, 1, 2, 4, 1)
+	// The region and team scope every part of the summary.
+	mock.ExpectQuery(`WHERE l\.timestamp <= b\.now_at AND cg\.region = \$4 AND cg\.team = \$5[\s\S]+WHERE i\.status = 'open' AND cg\.region = \$4 AND cg\.team = \$5[\s\S]+OR s\.status = 'in_progress'\) AND cg\.region = \$4 AND cg\.team = \$5$`).
+		WithArgs(filter.From, filter.To, filter.Now, "Minneapolis", "North").
+		WillReturnRows(rows)
+
+	summary, err := repo.GetSummary(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("GetSummary error: %v", err)
+	}
+	if summary.Visits != 9 || summary.Late != 1 || summary.OpenIncidents != 2 || summary.CaregiversOnBreak != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestDashboardRepositoryListVisits(t *testing.T) {
+	filter := dashboardTestFilter()
+	filter.Team = ""
+	cases := []struct {
+		state domain.DashboardVisitState
+		where string
+	}{
+		{domain.DashboardVisitLive, `WHERE s\.status = 'in_progress' AND cg\.region = \$4\s+ORDER BY`},
+		{domain.DashboardVisitUnstarted, `WHERE s\.start_time >= b\.from_at AND s\.start_time < b\.to_at AND s\.status = 'scheduled' AND s\.clock_in_at IS NULL AND s\.end_time > b\.now_at AND cg\.region = \$4`},
+		{domain.DashboardVisitMissed, `WHERE s\.start_time >= b\.from_at AND s\.start_time < b\.to_at AND \(s\.status = 'missed' OR`},
+	}
+
+	for _, tc := range cases {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock init: %v", err)
+		}
+
+		repo := NewDashboardRepository(sqlx.NewDb(db, "pgx"))
+		start := filter.From.Add(9 * time.Hour)
+		rows := sqlmock.NewRows([]string{"id", "caregiver_id", "caregiver_name", "region", "team", "client_id", "client_name",
+			"service_name", "start_time", "end_time", "status", "clock_in_at", "late"}).
+			AddRow("sched-1", "cg-1", "Louis Carewell", "Minneapolis", nil, "client-1", "Melisa Adam",
+				"Home Care", start, start.Add(time.Hour), "scheduled", nil, true)
+		mock.ExpectQuery(tc.where).
+			WithArgs(filter.From, filter.To, filter.Now, "Minneapolis").
+			WillReturnRows(rows)
+
+		visits, err := repo.ListVisits(context.Background(), filter, tc.state)
+		if err != nil {
+			t.Fatalf("%s: ListVisits error: %v", tc.state, err)
+		}
+		if len(visits) != 1 || !visits[0].Late || visits[0].Team != nil || *visits[0].Region != "Minneapolis" {
+			t.Fatalf("%s: unexpected visits %+v", tc.state, visits)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("%s: unmet expectations: %v", tc.state, err)
+		}
+		db.Close()
+	}
+}
+
+func TestDashboardRepositoryListCaregiverStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDashboardRepository(sqlx.NewDb(db, "pgx"))
+	filter := dashboardTestFilter()
+	filter.Region, filter.Team = "", ""
+	lastEvent := filter.Now.Add(-time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "name", "region", "team", "last_log_type", "last_event_at", "current_visit_id",
+		"next_visit_at", "visits", "completed", "missed", "open_incidents"}).
+		AddRow("cg-1", "Louis Carewell", nil, nil, "break_start", lastEvent, "sched-2", nil, 3, 1, 0, 1).
+		AddRow("cg-2", "Ana Lopez", nil, nil, nil, nil, nil, nil, 0, 0, 0, 0)
+	mock.ExpectQuery(`\) v ON TRUE\s+ORDER BY cg\.name ASC, cg\.id ASC`).
+		WithArgs(filter.From, filter.To, filter.Now).
+		WillReturnRows(rows)
+
+	statuses, err := repo.ListCaregiverStatus(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("ListCaregiverStatus error: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 caregivers, got %d", len(statuses))
+	}
+	if statuses[0].Attendance != domain.AttendanceStateOnBreak || *statuses[0].CurrentVisitID != "sched-2" || statuses[0].OpenIncidents != 1 {
+		t.Fatalf("unexpected status %+v", statuses[0])
+	}
+	if statuses[1].Attendance != domain.AttendanceStateOff || statuses[1].LastEventAt != nil {
+		t.Fatalf("unexpected status %+v", statuses[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
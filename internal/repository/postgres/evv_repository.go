@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// EVVRepository implements repository.EVVRepository backed by Postgres.
+type EVVRepository struct {
+	db *sqlx.DB
+}
+
+// NewEVVRepository creates a new repository.
+func NewEVVRepository(db *sqlx.DB) *EVVRepository {
+	return &EVVRepository{db: db}
+}
+
+type evvVisitRow struct {
+	ScheduleID          string          `db:"id"`
+	ServiceCode         sql.NullString  `db:"service_code"`
+	ServiceName         string          `db:"service_name"`
+	ClientID            string          `db:"client_id"`
+	ClientName          string          `db:"client_name"`
+	ClientMedicaidID    sql.NullString  `db:"client_medicaid_id"`
+	CaregiverID         sql.NullString  `db:"caregiver_id"`
+	CaregiverName       sql.NullString  `db:"caregiver_name"`
+	CaregiverEmployeeID sql.NullString  `db:"caregiver_employee_id"`
+	ClockInAt           sql.NullTime    `db:"clock_in_at"`
+	ClockInLat          sql.NullFloat64 `db:"clock_in_lat"`
+	ClockInLong         sql.NullFloat64 `db:"clock_in_long"`
+	ClockOutAt          sql.NullTime    `db:"clock_out_at"`
+	ClockOutLat         sql.NullFloat64 `db:"clock_out_lat"`
+	ClockOutLong        sql.NullFloat64 `db:"clock_out_long"`
+	ManuallyEdited      bool            `db:"manually_edited"`
+	SignoffRequired     bool            `db:"signoff_required"`
+	SignoffMethod       sql.NullString  `db:"signoff_method"`
+	SignoffAt           sql.NullTime    `db:"signoff_at"`
+	SignoffSummaryHash  sql.NullString  `db:"signoff_summary_hash"`
+	LastSubmittedHash   sql.NullString  `db:"last_submitted_hash"`
+	LastSubmittedAt     sql.NullTime    `db:"last_submitted_at"`
+}
+
+func (r *EVVRepository) ListVisits(ctx context.Context, from, to time.Time) ([]domain.EVVVisit, error) {
+	query := `
+		SELECT s.id,
+		       s.service_code,
+		       s.service_name,
+		       s.client_id,
+		       c.full_name AS client_name,
+		       c.medicaid_id AS client_medicaid_id,
+		       s.caregiver_id,
+		       cg.name AS caregiver_name,
+		       cg.employee_id AS caregiver_employee_id,
+		       s.clock_in_at,
+		       s.clock_in_lat,
+		       s.clock_in_long,
+		       s.clock_out_at,
+		       s.clock_out_lat,
+		       s.clock_out_long,
+		       s.manually_edited,
+		       COALESCE(ss.required, FALSE) AS signoff_required,
+		       vs.method AS signoff_method,
+		       vs.captured_at AS signoff_at,
+		       vs.summary_hash AS signoff_summary_hash,
+		       sub.visit_hash AS last_submitted_hash,
+		       sub.submitted_at AS last_submitted_at
+		FROM schedules s
+		INNER JOIN clients c ON c.id = s.client_id
+		LEFT JOIN caregivers cg ON cg.id = s.caregiver_id
+		LEFT JOIN client_signoff_settings ss ON ss.client_id = s.client_id
+		LEFT JOIN visit_signoffs vs ON vs.schedule_id = s.id
+		LEFT JOIN LATERAL (
+			SELECT e.visit_hash, e.submitted_at
+			FROM evv_submissions e
+			WHERE e.schedule_id = s.id
+			ORDER BY e.submitted_at DESC
+			LIMIT 1
+		) sub ON TRUE
+		WHERE s.status = 'completed'
+		  AND s.start_time >= $1
+		  AND s.start_time < $2
+		ORDER BY s.start_time ASC
+	`
+	rows := []evvVisitRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, from, to); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.EVVVisit, len(rows))
+	for i, row := range rows {
+		result[i] = domain.EVVVisit{
+			ScheduleID:          row.ScheduleID,
+			ServiceCode:         row.ServiceCode.String,
+			ServiceName:         row.ServiceName,
+			ClientID:            row.ClientID,
+			ClientName:          row.ClientName,
+			ClientMedicaidID:    row.ClientMedicaidID.String,
+			CaregiverID:         row.CaregiverID.String,
+			CaregiverName:       row.CaregiverName.String,
+			CaregiverEmployeeID: row.CaregiverEmployeeID.String,
+			ClockInAt:           nullTimePtr(row.ClockInAt),
+			ClockInLat:          nullFloatPtr(row.ClockInLat),
+			ClockInLong:         nullFloatPtr(row.ClockInLong),
+			ClockOutAt:          nullTimePtr(row.ClockOutAt),
+			ClockOutLat:         nullFloatPtr(row.ClockOutLat),
+			ClockOutLong:        nullFloatPtr(row.ClockOutLong),
+			ManuallyEdited:      row.ManuallyEdited,
+			SignoffRequired:     row.SignoffRequired,
+			SignoffMethod:       domain.SignoffMethod(row.SignoffMethod.String),
+			SignoffAt:           nullTimePtr(row.SignoffAt),
+			SignoffSummaryHash:  row.SignoffSummaryHash.String,
+			LastSubmittedHash:   nullStringPtr(row.LastSubmittedHash),
+			LastSubmittedAt:     nullTimePtr(row.LastSubmittedAt),
+		}
+	}
+	return result, nil
+}
+
+func (r *EVVRepository) SaveBatch(ctx context.Context, batch domain.EVVBatch, records []domain.EVVRecord) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO evv_batches (id, range_from, range_to, format, record_count, rejected_count, payload, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, batch.ID, batch.From, batch.To, batch.Format, batch.RecordCount, batch.RejectedCount, batch.Payload, nullable(batch.CreatedBy), batch.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO evv_submissions (batch_id, schedule_id, action, visit_hash, submitted_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, batch.ID, rec.Visit.ScheduleID, rec.Action, rec.Hash, batch.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+type evvBatchRow struct {
+	ID            string         `db:"id"`
+	RangeFrom     time.Time      `db:"range_from"`
+	RangeTo       time.Time      `db:"range_to"`
+	Format        string         `db:"format"`
+	RecordCount   int            `db:"record_count"`
+	RejectedCount int            `db:"rejected_count"`
+	Payload       []byte         `db:"payload"`
+	CreatedBy     sql.NullString `db:"created_by"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
+
+func (r *EVVRepository) GetBatch(ctx context.Context, batchID string) (domain.EVVBatch, error) {
+	var row evvBatchRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT id, range_from, range_to, format, record_count, rejected_count, payload, created_by, created_at
+		FROM evv_batches
+		WHERE id = $1
+	`, batchID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.EVVBatch{}, domain.ErrNotFound
+		}
+		return domain.EVVBatch{}, err
+	}
+	return domain.EVVBatch{
+		ID:            row.ID,
+		From:          row.RangeFrom,
+		To:            row.RangeTo,
+		Format:        domain.EVVFormat(row.Format),
+		RecordCount:   row.RecordCount,
+		RejectedCount: row.RejectedCount,
+		Payload:       row.Payload,
+		CreatedBy:     row.CreatedBy.String,
+		CreatedAt:     row.CreatedAt,
+	}, nil
+}
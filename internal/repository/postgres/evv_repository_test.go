@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestEVVRepositorySaveBatchRecordsSubmissions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewEVVRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)
+	batch := domain.EVVBatch{
+		ID:          "batch-1",
+		From:        now.AddDate(0, 0, -1),
+		To:          now,
+		Format:      domain.EVVFormatCSV,
+		RecordCount: 2,
+		Payload:     []byte("action\n"),
+		CreatedBy:   "cg-1",
+		CreatedAt:   now,
+	}
+	records := []domain.EVVRecord{
+		{Visit: domain.EVVVisit{ScheduleID: "visit-1"}, Action: domain.EVVActionCreate, Hash: "h1"},
+		{Visit: domain.EVVVisit{ScheduleID: "visit-2"}, Action: domain.EVVActionUpdate, Hash: "h2"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO evv_batches").
+		WithArgs("batch-1", batch.From, batch.To, domain.EVVFormatCSV, 2, 0, batch.Payload, "cg-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO evv_submissions").
+		WithArgs("batch-1", "visit-1", domain.EVVActionCreate, "h1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO evv_submissions").
+		WithArgs("batch-1", "visit-2", domain.EVVActionUpdate, "h2", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.SaveBatch(context.Background(), batch, records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// FamilyRepository implements repository.FamilyRepository.
+type FamilyRepository struct {
+	db *sqlx.DB
+}
+
+// NewFamilyRepository constructs the repository.
+func NewFamilyRepository(db *sqlx.DB) *FamilyRepository {
+	return &FamilyRepository{db: db}
+}
+
+const familyMemberSelect = `
+	SELECT m.id, m.client_id, c.full_name AS client_name, m.name, m.email, m.relationship,
+	       m.secret_hash, m.active, m.created_at
+	FROM family_members m
+	INNER JOIN clients c ON c.id = m.client_id
+`
+
+type familyMemberRow struct {
+	ID           string    `db:"id"`
+	ClientID     string    `db:"client_id"`
+	ClientName   string    `db:"client_name"`
+	Name         string    `db:"name"`
+	Email        string    `db:"email"`
+	Relationship string    `db:"relationship"`
+	SecretHash   string    `db:"secret_hash"`
+	Active       bool      `db:"active"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+func (r *FamilyRepository) CreateMember(ctx context.Context, member domain.FamilyMember) (domain.FamilyMember, error) {
+	var id string
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO family_members (client_id, name, email, relationship, secret_hash, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, TRUE, $6, $6)
+		ON CONFLICT DO NOTHING
+		RETURNING id
+	`, member.ClientID, member.Name, member.Email, member.Relationship, member.SecretHash, member.CreatedAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.FamilyMember{}, domain.ErrConflict
+		}
+		return domain.FamilyMember{}, err
+	}
+	return r.GetMember(ctx, id)
+}
+
+func (r *FamilyRepository) GetMember(ctx context.Context, memberID string) (domain.FamilyMember, error) {
+	return r.getMember(ctx, familyMemberSelect+` WHERE m.id = $1`, memberID)
+}
+
+func (r *FamilyRepository) GetMemberByEmail(ctx context.Context, email string) (domain.FamilyMember, error) {
+	return r.getMember(ctx, familyMemberSelect+` WHERE lower(m.email) = lower($1)`, email)
+}
+
+func (r *FamilyRepository) getMember(ctx context.Context, query string, arg string) (domain.FamilyMember, error) {
+	var row familyMemberRow
+	if err := r.db.GetContext(ctx, &row, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.FamilyMember{}, domain.ErrNotFound
+		}
+		return domain.FamilyMember{}, err
+	}
+	return mapFamilyMember(row), nil
+}
+
+func (r *FamilyRepository) ListMembers(ctx context.Context, clientID string) ([]domain.FamilyMember, error) {
+	rows := []familyMemberRow{}
+	if err := r.db.SelectContext(ctx, &rows, familyMemberSelect+`
+		WHERE m.client_id = $1
+		ORDER BY m.created_at ASC, m.id ASC
+	`, clientID); err != nil {
+		return nil, err
+	}
+	members := make([]domain.FamilyMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, mapFamilyMember(row))
+	}
+	return members, nil
+}
+
+func (r *FamilyRepository) SetMemberActive(ctx context.Context, clientID, memberID string, active bool) (domain.FamilyMember, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE family_members
+		SET active = $3, updated_at = NOW()
+		WHERE id = $1 AND client_id = $2
+	`, memberID, clientID, active)
+	if err != nil {
+		return domain.FamilyMember{}, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.FamilyMember{}, domain.ErrNotFound
+	}
+	return r.GetMember(ctx, memberID)
+}
+
+type familyConsentRow struct {
+	ClientID           string         `db:"client_id"`
+	ShareVisits        bool           `db:"share_visits"`
+	ShareTasks         bool           `db:"share_tasks"`
+	ShareConfirmations bool           `db:"share_confirmations"`
+	UpdatedBy          sql.NullString `db:"updated_by"`
+	UpdatedAt          sql.NullTime   `db:"updated_at"`
+}
+
+func (r *FamilyRepository) GetConsent(ctx context.Context, clientID string) (domain.FamilyConsent, error) {
+	var row familyConsentRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT c.id AS client_id,
+		       COALESCE(fc.share_visits, FALSE) AS share_visits,
+		       COALESCE(fc.share_tasks, FALSE) AS share_tasks,
+		       COALESCE(fc.share_confirmations, FALSE) AS share_confirmations,
+		       fc.updated_by, fc.updated_at
+		FROM clients c
+		LEFT JOIN client_family_consent fc ON fc.client_id = c.id
+		WHERE c.id = $1
+	`, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.FamilyConsent{}, domain.ErrNotFound
+		}
+		return domain.FamilyConsent{}, err
+	}
+	return domain.FamilyConsent{
+		ClientID:           row.ClientID,
+		ShareVisits:        row.ShareVisits,
+		ShareTasks:         row.ShareTasks,
+		ShareConfirmations: row.ShareConfirmations,
+		UpdatedBy:          nullStringPtr(row.UpdatedBy),
+		UpdatedAt:          nullTimePtr(row.UpdatedAt),
+	}, nil
+}
+
+func (r *FamilyRepository) SaveConsent(ctx context.Context, consent domain.FamilyConsent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO client_family_consent (client_id, share_visits, share_tasks, share_confirmations, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (client_id) DO UPDATE
+		SET share_visits = EXCLUDED.share_visits,
+		    share_tasks = EXCLUDED.share_tasks,
+		    share_confirmations = EXCLUDED.share_confirmations,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = EXCLUDED.updated_at
+	`, consent.ClientID, consent.ShareVisits, consent.ShareTasks, consent.ShareConfirmations, consent.UpdatedBy, consent.UpdatedAt)
+	return err
+}
+
+// familyVisitSelect never reads schedule notes; the portal must not show them.
+const familyVisitSelect = `
+	SELECT s.id, s.service_name, cg.name AS caregiver_name, s.start_time, s.end_time, s.status,
+	       s.clock_in_at, s.clock_in_lat, s.clock_in_long, s.clock_out_at, s.clock_out_lat, s.clock_out_long,
+	       c.latitude AS client_lat, c.longitude AS client_long
+	FROM schedules s
+	INNER JOIN clients c ON c.id = s.client_id
+	LEFT JOIN caregivers cg ON cg.id = s.caregiver_id
+`
+
+type familyVisitRow struct {
+	ID            string          `db:"id"`
+	ServiceName   string          `db:"service_name"`
+	CaregiverName sql.NullString  `db:"caregiver_name"`
+	StartTime     time.Time       `db:"start_time"`
+	EndTime       time.Time       `db:"end_time"`
+	Status        string          `db:"status"`
+	ClockInAt     sql.NullTime    `db:"clock_in_at"`
+	ClockInLat    sql.NullFloat64 `db:"clock_in_lat"`
+	ClockInLong   sql.NullFloat64 `db:"clock_in_long"`
+	ClockOutAt    sql.NullTime    `db:"clock_out_at"`
+	ClockOutLat   sql.NullFloat64 `db:"clock_out_lat"`
+	ClockOutLong  sql.NullFloat64 `db:"clock_out_long"`
+	ClientLat     sql.NullFloat64 `db:"client_lat"`
+	ClientLong    sql.NullFloat64 `db:"client_long"`
+}
+
+func (r *FamilyRepository) ListVisits(ctx context.Context, clientID string, filter repository.FamilyVisitFilter) ([]domain.FamilyVisit, error) {
+	query := familyVisitSelect + `
+		WHERE s.client_id = $1 AND s.start_time <= $2
+		  AND NOT (s.end_time > $2 AND s.status IN ('scheduled', 'in_progress'))
+		ORDER BY s.start_time DESC, s.id DESC
+		LIMIT $3
+	`
+	if filter.Upcoming {
+		query = familyVisitSelect + `
+			WHERE s.client_id = $1 AND s.end_time > $2 AND s.status IN ('scheduled', 'in_progress')
+			ORDER BY s.start_time ASC, s.id ASC
+			LIMIT $3
+		`
+	}
+	rows := []familyVisitRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, clientID, filter.Now, filter.Limit); err != nil {
+		return nil, err
+	}
+	visits := make([]domain.FamilyVisit, 0, len(rows))
+	for _, row := range rows {
+		visits = append(visits, mapFamilyVisit(row))
+	}
+	return visits, nil
+}
+
+func (r *FamilyRepository) GetVisit(ctx context.Context, clientID, scheduleID string) (domain.FamilyVisit, error) {
+	var row familyVisitRow
+	if err := r.db.GetContext(ctx, &row, familyVisitSelect+` WHERE s.id = $1 AND s.client_id = $2`, scheduleID, clientID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.FamilyVisit{}, domain.ErrNotFound
+		}
+		return domain.FamilyVisit{}, err
+	}
+	return mapFamilyVisit(row), nil
+}
+
+type familyTaskRow struct {
+	ScheduleID  string    `db:"schedule_id"`
+	Title       string    `db:"title"`
+	CompletedAt time.Time `db:"updated_at"`
+}
+
+func (r *FamilyRepository) CompletedTasks(ctx context.Context, scheduleIDs []string) (map[string][]domain.FamilyTask, error) {
+	tasks := make(map[string][]domain.FamilyTask, len(scheduleIDs))
+	if len(scheduleIDs) == 0 {
+		return tasks, nil
+	}
+	rows := []familyTaskRow{}
+	if err := r.db.SelectContext(ctx, &rows, `
+		SELECT schedule_id, title, updated_at
+		FROM schedule_tasks
+		WHERE schedule_id = ANY($1) AND status = 'completed'
+		ORDER BY schedule_id, sort_order ASC, id ASC
+	`, pq.Array(scheduleIDs)); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tasks[row.ScheduleID] = append(tasks[row.ScheduleID], domain.FamilyTask{Title: row.Title, CompletedAt: row.CompletedAt})
+	}
+	return tasks, nil
+}
+
+func mapFamilyMember(row familyMemberRow) domain.FamilyMember {
+	return domain.FamilyMember{
+		ID:           row.ID,
+		ClientID:     row.ClientID,
+		ClientName:   row.ClientName,
+		Name:         row.Name,
+		Email:        row.Email,
+		Relationship: row.Relationship,
+		SecretHash:   row.SecretHash,
+		Active:       row.Active,
+		CreatedAt:    row.CreatedAt,
+	}
+}
+
+func mapFamilyVisit(row familyVisitRow) domain.FamilyVisit {
+	clientLat, clientLong := nullFloatPtr(row.ClientLat), nullFloatPtr(row.ClientLong)
+	return domain.FamilyVisit{
+		ScheduleID:    row.ID,
+		ServiceName:   row.ServiceName,
+		CaregiverName: nullStringPtr(row.CaregiverName),
+		StartTime:     row.StartTime,
+		EndTime:       row.EndTime,
+		Status:        domain.ScheduleStatus(row.Status),
+		Confirmation: &domain.VisitConfirmation{
+			ClockInAt:      nullTimePtr(row.ClockInAt),
+			ClockOutAt:     nullTimePtr(row.ClockOutAt),
+			ClockInAtHome:  domain.AtHome(nullFloatPtr(row.ClockInLat), nullFloatPtr(row.ClockInLong), clientLat, clientLong),
+			ClockOutAtHome: domain.AtHome(nullFloatPtr(row.ClockOutLat), nullFloatPtr(row.ClockOutLong), clientLat, clientLong),
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestFamilyRepositoryGetConsent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewFamilyRepository(sqlx.NewDb(db, "pgx"))
+	mock.ExpectQuery(`FROM clients c\s+LEFT JOIN client_family_consent fc ON fc.client_id = c.id\s+WHERE c.id = \$1`).
+		WithArgs("client-1").
+		WillReturnRows(sqlmock.NewRows([]string{"client_id", "share_visits", "share_tasks", "share_confirmations", "updated_by", "updated_at"}).
+			AddRow("client-1", false, false, false, nil, nil))
+	mock.ExpectQuery(`FROM clients c`).
+		WithArgs("client-2").
+		WillReturnRows(sqlmock.NewRows([]string{"client_id"}))
+
+	consent, err := repo.GetConsent(context.Background(), "client-1")
+	if err != nil {
+		t.Fatalf("GetConsent error: %v", err)
+	}
+	if consent.ShareVisits || consent.ShareTasks || consent.ShareConfirmations || consent.UpdatedAt != nil {
+		t.Fatalf("expected nothing shared by default, got %+v", consent)
+	}
+	if _, err := repo.GetConsent(context.Background(), "client-2"); err != domain.ErrNotFound {
+		t.Fatalf("expected not found for an unknown client, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestFamilyRepositoryListVisits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewFamilyRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	start := now.Add(-3 * time.Hour)
+	columns := []string{"id", "service_name", "caregiver_name", "start_time", "end_time", "status",
+		"clock_in_at", "clock_in_lat", "clock_in_long", "clock_out_at", "clock_out_lat", "clock_out_long", "client_lat", "client_long"}
+	mock.ExpectQuery(`WHERE s.client_id = \$1 AND s.start_time <= \$2[\s\S]+ORDER BY s.start_time DESC, s.id DESC\s+LIMIT \$3`).
+		WithArgs("client-1", now, 20).
+		WillReturnRows(sqlmock.NewRows(columns).
+			// Clocked in at the door, clocked out about 2 km away.
+			AddRow("sched-1", "Personal care", "Louis", start, start.Add(time.Hour), "completed",
+				start, 44.9727, -93.2354, start.Add(time.Hour), 44.9900, -93.2354, 44.9728, -93.2355))
+
+	visits, err := repo.ListVisits(context.Background(), "client-1", repository.FamilyVisitFilter{Now: now, Limit: 20})
+	if err != nil {
+		t.Fatalf("ListVisits error: %v", err)
+	}
+	if len(visits) != 1 || visits[0].Confirmation == nil {
+		t.Fatalf("unexpected visits %+v", visits)
+	}
+	c := visits[0].Confirmation
+	if c.ClockInAtHome == nil || !*c.ClockInAtHome || c.ClockOutAtHome == nil || *c.ClockOutAtHome {
+		t.Fatalf("expected clock-in at home and clock-out away, got %+v", c)
+	}
+
+	mock.ExpectQuery(`WHERE s.client_id = \$1 AND s.end_time > \$2 AND s.status IN \('scheduled', 'in_progress'\)[\s\S]+ORDER BY s.start_time ASC`).
+		WithArgs("client-1", now, 5).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("sched-2", "Companionship", nil, now.Add(time.Hour), now.Add(2*time.Hour), "scheduled",
+				nil, nil, nil, nil, nil, nil, 44.9728, -93.2355))
+	upcoming, err := repo.ListVisits(context.Background(), "client-1", repository.FamilyVisitFilter{Upcoming: true, Now: now, Limit: 5})
+	if err != nil {
+		t.Fatalf("ListVisits error: %v", err)
+	}
+	if len(upcoming) != 1 || upcoming[0].CaregiverName != nil || upcoming[0].Confirmation.ClockInAtHome != nil {
+		t.Fatalf("unexpected upcoming visits %+v", upcoming)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
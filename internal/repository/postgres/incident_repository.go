@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// IncidentRepository implements repository.IncidentRepository.
+type IncidentRepository struct {
+	db *sqlx.DB
+}
+
+// NewIncidentRepository constructs the repository.
+func NewIncidentRepository(db *sqlx.DB) *IncidentRepository {
+	return &IncidentRepository{db: db}
+}
+
+const incidentSelect = `
+	SELECT i.id, i.caregiver_id, i.schedule_id, i.category, i.severity, i.description, i.status,
+	       i.reported_at, i.resolved_by, i.resolved_at, i.resolution,
+	       cg.name AS caregiver_name, cg.region, cg.team, c.full_name AS client_name
+	FROM incidents i
+	INNER JOIN caregivers cg ON cg.id = i.caregiver_id
+	LEFT JOIN schedules s ON s.id = i.schedule_id
+	LEFT JOIN clients c ON c.id = s.client_id
+`
+
+type incidentRow struct {
+	ID            string         `db:"id"`
+	CaregiverID   string         `db:"caregiver_id"`
+	ScheduleID    sql.NullString `db:"schedule_id"`
+	Category      string         `db:"category"`
+	Severity      string         `db:"severity"`
+	Description   string         `db:"description"`
+	Status        string         `db:"status"`
+	ReportedAt    time.Time      `db:"reported_at"`
+	ResolvedBy    sql.NullString `db:"resolved_by"`
+	ResolvedAt    sql.NullTime   `db:"resolved_at"`
+	Resolution    sql.NullString `db:"resolution"`
+	CaregiverName string         `db:"caregiver_name"`
+	Region        sql.NullString `db:"region"`
+	Team          sql.NullString `db:"team"`
+	ClientName    sql.NullString `db:"client_name"`
+}
+
+// Create stores the incident and an incident.reported outbox event.
+func (r *IncidentRepository) Create(ctx context.Context, incident domain.Incident) (domain.Incident, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id string
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO incidents (caregiver_id, schedule_id, category, severity, description, status, reported_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 'open', $6, $6, $6)
+		RETURNING id
+	`, incident.CaregiverID, incident.ScheduleID, incident.Category, incident.Severity, incident.Description, incident.ReportedAt).Scan(&id)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	err = writeOutbox(ctx, tx, outboxEntry{
+		Type:        domain.EventIncidentReported,
+		CaregiverID: incident.CaregiverID,
+		ScheduleID:  stringValue(incident.ScheduleID),
+		Data: map[string]interface{}{
+			"incident_id": id,
+			"category":    incident.Category,
+			"severity":    incident.Severity,
+			"reported_at": incident.ReportedAt,
+		},
+	})
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Incident{}, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *IncidentRepository) GetByID(ctx context.Context, incidentID string) (domain.Incident, error) {
+	var row incidentRow
+	if err := r.db.GetContext(ctx, &row, incidentSelect+` WHERE i.id = $1`, incidentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Incident{}, domain.ErrNotFound
+		}
+		return domain.Incident{}, err
+	}
+	return mapIncident(row), nil
+}
+
+func (r *IncidentRepository) ListOpen(ctx context.Context, filter repository.DashboardFilter) ([]domain.Incident, error) {
+	conds, args := caregiverScope(filter, nil)
+	query := incidentSelect + ` WHERE ` + strings.Join(append([]string{"i.status = 'open'"}, conds...), " AND ") + `
+		ORDER BY CASE i.severity WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, i.reported_at ASC, i.id ASC
+	`
+
+	rows := []incidentRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	incidents := make([]domain.Incident, 0, len(rows))
+	for _, row := range rows {
+		incidents = append(incidents, mapIncident(row))
+	}
+	return incidents, nil
+}
+
+// Resolve closes the incident and records an incident.resolved outbox event.
+func (r *IncidentRepository) Resolve(ctx context.Context, incidentID, resolverID, resolution string, at time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var resolved struct {
+		CaregiverID string         `db:"caregiver_id"`
+		ScheduleID  sql.NullString `db:"schedule_id"`
+	}
+	err = tx.GetContext(ctx, &resolved, `
+		UPDATE incidents
+		SET status = 'resolved',
+		    resolved_by = $2,
+		    resolved_at = $3,
+		    resolution = $4,
+		    updated_at = $3
+		WHERE id = $1 AND status = 'open'
+		RETURNING caregiver_id, schedule_id
+	`, incidentID, resolverID, at, resolution)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	err = writeOutbox(ctx, tx, outboxEntry{
+		Type:        domain.EventIncidentResolved,
+		CaregiverID: resolved.CaregiverID,
+		ScheduleID:  resolved.ScheduleID.String,
+		Data: map[string]interface{}{
+			"incident_id": incidentID,
+			"resolved_by": resolverID,
+			"resolved_at": at,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func mapIncident(row incidentRow) domain.Incident {
+	return domain.Incident{
+		ID:            row.ID,
+		CaregiverID:   row.CaregiverID,
+		ScheduleID:    nullStringPtr(row.ScheduleID),
+		Category:      row.Category,
+		Severity:      domain.IncidentSeverity(row.Severity),
+		Description:   row.Description,
+		Status:        domain.IncidentStatus(row.Status),
+		ReportedAt:    row.ReportedAt,
+		ResolvedBy:    nullStringPtr(row.ResolvedBy),
+		ResolvedAt:    nullTimePtr(row.ResolvedAt),
+		Resolution:    nullStringPtr(row.Resolution),
+		CaregiverName: row.CaregiverName,
+		Region:        nullStringPtr(row.Region),
+		Team:          nullStringPtr(row.Team),
+		ClientName:    nullStringPtr(row.ClientName),
+	}
+}
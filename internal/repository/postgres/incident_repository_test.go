@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+var incidentColumns = []string{"id", "caregiver_id", "schedule_id", "category", "severity", "description", "status",
+	"reported_at", "resolved_by", "resolved_at", "resolution", "caregiver_name", "region", "team", "client_name"}
+
+func TestIncidentRepositoryListOpen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIncidentRepository(sqlx.NewDb(db, "pgx"))
+	reported := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+	rows := sqlmock.NewRows(incidentColumns).
+		AddRow("incident-1", "cg-1", "sched-1", "fall", "high", "Client slipped.", "open",
+			reported, nil, nil, nil, "Louis Carewell", "Minneapolis", "North", "Melisa Adam")
+	mock.ExpectQuery(`WHERE i\.status = 'open' AND cg\.team = \$1\s+ORDER BY CASE i\.severity WHEN 'high' THEN 0`).
+		WithArgs("North").
+		WillReturnRows(rows)
+
+	incidents, err := repo.ListOpen(context.Background(), repository.DashboardFilter{Team: "North"})
+	if err != nil {
+		t.Fatalf("ListOpen error: %v", err)
+	}
+	if len(incidents) != 1 || incidents[0].Severity != domain.IncidentSeverityHigh || *incidents[0].ClientName != "Melisa Adam" {
+		t.Fatalf("unexpected incidents %+v", incidents)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestIncidentRepositoryResolveConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIncidentRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE incidents[\s\S]+WHERE id = \$1 AND status = 'open'`).
+		WithArgs("incident-1", "sup-1", now, "Handled.").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "schedule_id"}))
+	mock.ExpectRollback()
+
+	if err := repo.Resolve(context.Background(), "incident-1", "sup-1", "Handled.", now); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,477 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// MedicationRepository implements repository.MedicationRepository.
+type MedicationRepository struct {
+	db *sqlx.DB
+}
+
+// NewMedicationRepository constructs the repository.
+func NewMedicationRepository(db *sqlx.DB) *MedicationRepository {
+	return &MedicationRepository{db: db}
+}
+
+// missedDoseReason is given to the task of a dose flagged as missed.
+const missedDoseReason = "Dose missed"
+
+const medicationOrderColumns = `
+	id, client_id, drug, dose, route, times, prn, instructions, start_date, end_date, active,
+	created_by, created_at, updated_at
+`
+
+type medicationOrderRow struct {
+	ID           string         `db:"id"`
+	ClientID     string         `db:"client_id"`
+	Drug         string         `db:"drug"`
+	Dose         string         `db:"dose"`
+	Route        string         `db:"route"`
+	Times        pq.StringArray `db:"times"`
+	PRN          bool           `db:"prn"`
+	Instructions sql.NullString `db:"instructions"`
+	StartDate    time.Time      `db:"start_date"`
+	EndDate      sql.NullTime   `db:"end_date"`
+	Active       bool           `db:"active"`
+	CreatedBy    sql.NullString `db:"created_by"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+}
+
+const administrationSelect = `
+	SELECT a.id, a.order_id, a.schedule_id, t.id AS task_id, a.due_at, a.status, a.reason,
+	       a.administered_at, a.recorded_by, a.recorded_at, a.created_at,
+	       o.drug, o.dose, o.route, o.prn
+	FROM medication_administrations a
+	JOIN medication_orders o ON o.id = a.order_id
+	LEFT JOIN schedule_tasks t ON t.medication_administration_id = a.id
+`
+
+type administrationRow struct {
+	ID             string         `db:"id"`
+	OrderID        string         `db:"order_id"`
+	ScheduleID     string         `db:"schedule_id"`
+	TaskID         sql.NullString `db:"task_id"`
+	DueAt          sql.NullTime   `db:"due_at"`
+	Status         string         `db:"status"`
+	Reason         sql.NullString `db:"reason"`
+	AdministeredAt sql.NullTime   `db:"administered_at"`
+	RecordedBy     sql.NullString `db:"recorded_by"`
+	RecordedAt     sql.NullTime   `db:"recorded_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	Drug           string         `db:"drug"`
+	Dose           string         `db:"dose"`
+	Route          string         `db:"route"`
+	PRN            bool           `db:"prn"`
+}
+
+func (r *MedicationRepository) CreateOrder(ctx context.Context, order domain.MedicationOrder) (domain.MedicationOrder, error) {
+	var row medicationOrderRow
+	err := r.db.GetContext(ctx, &row, `
+		INSERT INTO medication_orders (client_id, drug, dose, route, times, prn, instructions, start_date, end_date,
+		                               active, created_by, created_at, updated_at)
+		SELECT c.id, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12
+		FROM clients c
+		WHERE c.id = $1
+		RETURNING`+medicationOrderColumns,
+		order.ClientID, order.Drug, order.Dose, order.Route, pq.Array(order.Times), order.PRN, order.Instructions,
+		order.StartDate, order.EndDate, order.Active, nullable(order.CreatedBy), order.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MedicationOrder{}, domain.ErrNotFound
+		}
+		return domain.MedicationOrder{}, err
+	}
+	return mapMedicationOrder(row), nil
+}
+
+func (r *MedicationRepository) GetOrder(ctx context.Context, orderID string) (domain.MedicationOrder, error) {
+	var row medicationOrderRow
+	err := r.db.GetContext(ctx, &row, `SELECT`+medicationOrderColumns+`FROM medication_orders WHERE id = $1`, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MedicationOrder{}, domain.ErrNotFound
+		}
+		return domain.MedicationOrder{}, err
+	}
+	return mapMedicationOrder(row), nil
+}
+
+func (r *MedicationRepository) UpdateOrder(ctx context.Context, order domain.MedicationOrder) (domain.MedicationOrder, error) {
+	var row medicationOrderRow
+	err := r.db.GetContext(ctx, &row, `
+		UPDATE medication_orders
+		SET instructions = $2, end_date = $3, active = $4, updated_at = $5
+		WHERE id = $1
+		RETURNING`+medicationOrderColumns,
+		order.ID, order.Instructions, order.EndDate, order.Active, order.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MedicationOrder{}, domain.ErrNotFound
+		}
+		return domain.MedicationOrder{}, err
+	}
+	return mapMedicationOrder(row), nil
+}
+
+func (r *MedicationRepository) ListOrders(ctx context.Context, clientID string, activeOnly bool) ([]domain.MedicationOrder, error) {
+	rows := []medicationOrderRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT`+medicationOrderColumns+`
+		FROM medication_orders
+		WHERE client_id = $1 AND (active OR NOT $2)
+		ORDER BY prn ASC, drug ASC, created_at ASC, id ASC
+	`, clientID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.MedicationOrder, len(rows))
+	for i, row := range rows {
+		result[i] = mapMedicationOrder(row)
+	}
+	return result, nil
+}
+
+func (r *MedicationRepository) ListVisitsForDoses(ctx context.Context, from, to time.Time) ([]domain.Schedule, error) {
+	rows := []struct {
+		ID          string    `db:"id"`
+		CaregiverID string    `db:"caregiver_id"`
+		ClientID    string    `db:"client_id"`
+		StartTime   time.Time `db:"start_time"`
+		EndTime     time.Time `db:"end_time"`
+		Status      string    `db:"status"`
+	}{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT s.id, s.caregiver_id, s.client_id, s.start_time, s.end_time, s.status
+		FROM schedules s
+		WHERE s.start_time < $2 AND s.end_time > $1
+		  AND s.status IN ('scheduled', 'in_progress', 'missed')
+		  AND EXISTS (
+		      SELECT 1 FROM medication_orders o
+		      WHERE o.client_id = s.client_id AND o.active AND NOT o.prn
+		  )
+		ORDER BY s.start_time ASC, s.id ASC
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.Schedule, len(rows))
+	for i, row := range rows {
+		result[i] = domain.Schedule{
+			ID:          row.ID,
+			CaregiverID: row.CaregiverID,
+			Client:      domain.Client{ID: row.ClientID},
+			StartTime:   row.StartTime,
+			EndTime:     row.EndTime,
+			Status:      domain.ScheduleStatus(row.Status),
+		}
+	}
+	return result, nil
+}
+
+func (r *MedicationRepository) AddDue(ctx context.Context, doses []domain.MedicationAdministration) (int, error) {
+	if len(doses) == 0 {
+		return 0, nil
+	}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	added := 0
+	for _, dose := range doses {
+		var id string
+		err := tx.QueryRowxContext(ctx, `
+			INSERT INTO medication_administrations (order_id, schedule_id, due_at, status, created_at)
+			VALUES ($1, $2, $3, 'due', $4)
+			ON CONFLICT (order_id, schedule_id, due_at) DO NOTHING
+			RETURNING id
+		`, dose.OrderID, dose.ScheduleID, dose.DueAt, dose.CreatedAt).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO schedule_tasks (schedule_id, title, status, sort_order, medication_administration_id)
+			VALUES ($1, $2, 'pending', 0, $3)
+		`, dose.ScheduleID, dose.Label(), id)
+		if err != nil {
+			return 0, err
+		}
+		added++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+func (r *MedicationRepository) AddPRN(ctx context.Context, dose domain.MedicationAdministration) (domain.MedicationAdministration, error) {
+	var id string
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO medication_administrations (order_id, schedule_id, status, reason, administered_at,
+		                                        recorded_by, recorded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING id
+	`, dose.OrderID, dose.ScheduleID, dose.Status, dose.Reason, dose.AdministeredAt, dose.RecordedBy, dose.RecordedAt).Scan(&id)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	return r.GetAdministration(ctx, id)
+}
+
+func (r *MedicationRepository) GetAdministration(ctx context.Context, administrationID string) (domain.MedicationAdministration, error) {
+	var row administrationRow
+	if err := r.db.GetContext(ctx, &row, administrationSelect+` WHERE a.id = $1`, administrationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MedicationAdministration{}, domain.ErrNotFound
+		}
+		return domain.MedicationAdministration{}, err
+	}
+	return mapAdministration(row), nil
+}
+
+func (r *MedicationRepository) ListBySchedule(ctx context.Context, scheduleID string) ([]domain.MedicationAdministration, error) {
+	return r.listAdministrations(ctx, administrationSelect+`
+		WHERE a.schedule_id = $1
+		ORDER BY COALESCE(a.due_at, a.administered_at) ASC, o.drug ASC, a.id ASC
+	`, scheduleID)
+}
+
+func (r *MedicationRepository) ListByClient(ctx context.Context, clientID string, filter repository.MARFilter) ([]domain.MedicationAdministration, error) {
+	conds := []string{"o.client_id = $1"}
+	args := []interface{}{clientID}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conds = append(conds, fmt.Sprintf("COALESCE(a.due_at, a.administered_at) >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conds = append(conds, fmt.Sprintf("COALESCE(a.due_at, a.administered_at) < $%d", len(args)))
+	}
+	if len(filter.Status) > 0 {
+		statuses := make([]string, len(filter.Status))
+		for i, status := range filter.Status {
+			statuses[i] = string(status)
+		}
+		args = append(args, pq.Array(statuses))
+		conds = append(conds, fmt.Sprintf("a.status = ANY($%d)", len(args)))
+	}
+	query := administrationSelect + ` WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY COALESCE(a.due_at, a.administered_at) ASC, o.drug ASC, a.id ASC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return r.listAdministrations(ctx, query, args...)
+}
+
+func (r *MedicationRepository) listAdministrations(ctx context.Context, query string, args ...interface{}) ([]domain.MedicationAdministration, error) {
+	rows := []administrationRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	result := make([]domain.MedicationAdministration, len(rows))
+	for i, row := range rows {
+		result[i] = mapAdministration(row)
+	}
+	return result, nil
+}
+
+// Record saves the dose's outcome, closes its task and records a task.updated
+// outbox event, all in one transaction. Missed doses cannot be recorded.
+func (r *MedicationRepository) Record(ctx context.Context, dose domain.MedicationAdministration) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE medication_administrations
+		SET status = $2, reason = $3, administered_at = $4, recorded_by = $5, recorded_at = $6
+		WHERE id = $1 AND status <> 'missed'
+	`, dose.ID, dose.Status, dose.Reason, dose.AdministeredAt, dose.RecordedBy, dose.RecordedAt)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrInvalidStatusTransition
+	}
+
+	status, reason := domain.TaskStatusCompleted, (*string)(nil)
+	if dose.Status != domain.AdministrationGiven {
+		status, reason = domain.TaskStatusNotCompleted, dose.Reason
+	}
+	var task struct {
+		ID          string         `db:"id"`
+		ScheduleID  string         `db:"schedule_id"`
+		CaregiverID sql.NullString `db:"caregiver_id"`
+	}
+	err = tx.GetContext(ctx, &task, `
+		UPDATE schedule_tasks t
+		SET status = $2, not_completed_reason = $3, updated_at = NOW()
+		FROM schedules s
+		WHERE t.medication_administration_id = $1 AND s.id = t.schedule_id
+		RETURNING t.id, t.schedule_id, s.caregiver_id
+	`, dose.ID, status, reason)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return tx.Commit()
+	case err != nil:
+		return err
+	}
+
+	data := map[string]interface{}{"task_id": task.ID, "schedule_id": task.ScheduleID, "status": status}
+	if reason != nil {
+		data["reason"] = *reason
+	}
+	err = writeOutbox(ctx, tx, outboxEntry{
+		Type:        domain.EventTaskUpdated,
+		CaregiverID: task.CaregiverID.String,
+		ScheduleID:  task.ScheduleID,
+		Data:        data,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MarkMissed flags doses and closes their pending tasks, recording a
+// medication.missed outbox event for each dose in the same transaction.
+func (r *MedicationRepository) MarkMissed(ctx context.Context, scheduleID string, before time.Time) ([]domain.MedicationAdministration, error) {
+	scope := `a.schedule_id = $1`
+	arg := interface{}(scheduleID)
+	if scheduleID == "" {
+		scope = `(s.status IN ('completed', 'cancelled', 'missed') OR (s.status = 'scheduled' AND s.end_time <= $1))`
+		arg = before
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows := []struct {
+		administrationRow
+		ClientID    string         `db:"client_id"`
+		CaregiverID sql.NullString `db:"caregiver_id"`
+	}{}
+	err = tx.SelectContext(ctx, &rows, `
+		WITH missed AS (
+		    UPDATE medication_administrations a
+		    SET status = 'missed'
+		    FROM schedules s
+		    WHERE s.id = a.schedule_id AND a.status = 'due' AND `+scope+`
+		    RETURNING a.*, s.caregiver_id
+		)
+		SELECT m.id, m.order_id, m.schedule_id, t.id AS task_id, m.due_at, m.status, m.reason,
+		       m.administered_at, m.recorded_by, m.recorded_at, m.created_at,
+		       o.drug, o.dose, o.route, o.prn, o.client_id, m.caregiver_id
+		FROM missed m
+		JOIN medication_orders o ON o.id = m.order_id
+		LEFT JOIN schedule_tasks t ON t.medication_administration_id = m.id
+		ORDER BY m.due_at ASC, m.id ASC
+	`, arg)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(rows))
+	result := make([]domain.MedicationAdministration, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+		result[i] = mapAdministration(row.administrationRow)
+		err := writeOutbox(ctx, tx, outboxEntry{
+			Type:        domain.EventMedicationMissed,
+			CaregiverID: row.CaregiverID.String,
+			ScheduleID:  row.ScheduleID,
+			Data: map[string]interface{}{
+				"administration_id": row.ID,
+				"order_id":          row.OrderID,
+				"client_id":         row.ClientID,
+				"medication":        result[i].Label(),
+				"due_at":            result[i].DueAt,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE schedule_tasks
+		SET status = 'not_completed', not_completed_reason = $2, updated_at = NOW()
+		WHERE medication_administration_id = ANY($1) AND status = 'pending'
+	`, pq.Array(ids), missedDoseReason)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func mapMedicationOrder(row medicationOrderRow) domain.MedicationOrder {
+	times := []string(row.Times)
+	if times == nil {
+		times = []string{}
+	}
+	return domain.MedicationOrder{
+		ID:           row.ID,
+		ClientID:     row.ClientID,
+		Drug:         row.Drug,
+		Dose:         row.Dose,
+		Route:        domain.MedicationRoute(row.Route),
+		Times:        times,
+		PRN:          row.PRN,
+		Instructions: nullStringPtr(row.Instructions),
+		StartDate:    row.StartDate,
+		EndDate:      nullTimePtr(row.EndDate),
+		Active:       row.Active,
+		CreatedBy:    row.CreatedBy.String,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
+
+func mapAdministration(row administrationRow) domain.MedicationAdministration {
+	return domain.MedicationAdministration{
+		ID:             row.ID,
+		OrderID:        row.OrderID,
+		ScheduleID:     row.ScheduleID,
+		TaskID:         nullStringPtr(row.TaskID),
+		DueAt:          nullTimePtr(row.DueAt),
+		Status:         domain.AdministrationStatus(row.Status),
+		Reason:         nullStringPtr(row.Reason),
+		AdministeredAt: nullTimePtr(row.AdministeredAt),
+		RecordedBy:     nullStringPtr(row.RecordedBy),
+		RecordedAt:     nullTimePtr(row.RecordedAt),
+		CreatedAt:      row.CreatedAt,
+		Drug:           row.Drug,
+		Dose:           row.Dose,
+		Route:          domain.MedicationRoute(row.Route),
+		PRN:            row.PRN,
+	}
+}
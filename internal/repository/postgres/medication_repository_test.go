@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+var missedDoseTestColumns = []string{"id", "order_id", "schedule_id", "task_id", "due_at", "status", "reason",
+	"administered_at", "recorded_by", "recorded_at", "created_at", "drug", "dose", "route", "prn", "client_id", "caregiver_id"}
+
+func TestMedicationRepositoryCreateOrderUnknownClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMedicationRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`INSERT INTO medication_orders[\s\S]+FROM clients c\s+WHERE c.id = \$1\s+RETURNING`).
+		WithArgs("client-9", "Lisinopril", "10 mg", domain.MedicationRouteOral, sqlmock.AnyArg(), false, nil,
+			now, nil, true, "cg-1", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.CreateOrder(context.Background(), domain.MedicationOrder{
+		ClientID:  "client-9",
+		Drug:      "Lisinopril",
+		Dose:      "10 mg",
+		Route:     domain.MedicationRouteOral,
+		Times:     []string{"08:00"},
+		StartDate: now,
+		Active:    true,
+		CreatedBy: "cg-1",
+		CreatedAt: now,
+	})
+	if err != domain.ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMedicationRepositoryAddDueSkipsExistingDoses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMedicationRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	morning := time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)
+	noon := time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC)
+	dose := func(due *time.Time) domain.MedicationAdministration {
+		return domain.MedicationAdministration{
+			OrderID:    "order-1",
+			ScheduleID: "sched-1",
+			DueAt:      due,
+			CreatedAt:  now,
+			Drug:       "Lisinopril",
+			Dose:       "10 mg",
+			Route:      domain.MedicationRouteOral,
+		}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO medication_administrations[\s\S]+ON CONFLICT \(order_id, schedule_id, due_at\) DO NOTHING\s+RETURNING id`).
+		WithArgs("order-1", "sched-1", &morning, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`INSERT INTO medication_administrations`).
+		WithArgs("order-1", "sched-1", &noon, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("dose-2"))
+	mock.ExpectExec(`INSERT INTO schedule_tasks \(schedule_id, title, status, sort_order, medication_administration_id\)`).
+		WithArgs("sched-1", "Lisinopril 10 mg (oral)", "dose-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := repo.AddDue(context.Background(), []domain.MedicationAdministration{dose(&morning), dose(&noon)})
+	if err != nil {
+		t.Fatalf("AddDue error: %v", err)
+	}
+	if added != 1 {
+		t.Fatalf("expected 1 dose added, got %d", added)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMedicationRepositoryRecordClosesTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMedicationRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 14, 5, 0, 0, time.UTC)
+	reason := "Client declined"
+	caregiver := "cg-1"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE medication_administrations[\s\S]+WHERE id = \$1 AND status <> 'missed'`).
+		WithArgs("dose-1", domain.AdministrationRefused, &reason, nil, &caregiver, &now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE schedule_tasks t[\s\S]+WHERE t.medication_administration_id = \$1`).
+		WithArgs("dose-1", domain.TaskStatusNotCompleted, &reason).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "schedule_id", "caregiver_id"}).AddRow("task-1", "sched-1", "cg-1"))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventTaskUpdated, "cg-1", "sched-1",
+			`{"reason":"Client declined","schedule_id":"sched-1","status":"not_completed","task_id":"task-1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.Record(context.Background(), domain.MedicationAdministration{
+		ID:         "dose-1",
+		Status:     domain.AdministrationRefused,
+		Reason:     &reason,
+		RecordedBy: &caregiver,
+		RecordedAt: &now,
+	})
+	if err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMedicationRepositoryRecordMissedDose(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMedicationRepository(sqlx.NewDb(db, "pgx"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE medication_administrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.Record(context.Background(), domain.MedicationAdministration{ID: "dose-1", Status: domain.AdministrationGiven})
+	if err != domain.ErrInvalidStatusTransition {
+		t.Fatalf("expected invalid status transition, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMedicationRepositoryMarkMissed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMedicationRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+	due := time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`WITH missed AS \([\s\S]+s.status = 'scheduled' AND s.end_time <= \$1`).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows(missedDoseTestColumns).
+			AddRow("dose-1", "order-1", "sched-1", "task-1", due, "missed", nil, nil, nil, nil, due,
+				"Lisinopril", "10 mg", "oral", false, "client-1", "cg-1"))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventMedicationMissed, "cg-1", "sched-1",
+			`{"administration_id":"dose-1","client_id":"client-1","due_at":"2025-01-15T14:00:00Z","medication":"Lisinopril 10 mg (oral)","order_id":"order-1"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE schedule_tasks[\s\S]+WHERE medication_administration_id = ANY\(\$1\) AND status = 'pending'`).
+		WithArgs(sqlmock.AnyArg(), missedDoseReason).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	missed, err := repo.MarkMissed(context.Background(), "", now)
+	if err != nil {
+		t.Fatalf("MarkMissed error: %v", err)
+	}
+	if len(missed) != 1 || missed[0].Status != domain.AdministrationMissed || missed[0].TaskID == nil {
+		t.Fatalf("unexpected missed doses: %+v", missed)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// MileageRepository implements repository.MileageRepository backed by Postgres.
+type MileageRepository struct {
+	db *sqlx.DB
+}
+
+// NewMileageRepository creates a new repository.
+func NewMileageRepository(db *sqlx.DB) *MileageRepository {
+	return &MileageRepository{db: db}
+}
+
+const mileageClaimColumns = `
+	id, caregiver_id, claim_date, computed_km, claimed_km, adjustment_reason, rate_per_km, amount,
+	status, submitted_at, reviewed_by, reviewed_at, review_comment
+`
+
+type mileageStopRow struct {
+	ScheduleID   string          `db:"id"`
+	ClockInAt    time.Time       `db:"clock_in_at"`
+	ClockOutAt   sql.NullTime    `db:"clock_out_at"`
+	ClockInLat   sql.NullFloat64 `db:"clock_in_lat"`
+	ClockInLong  sql.NullFloat64 `db:"clock_in_long"`
+	ClockOutLat  sql.NullFloat64 `db:"clock_out_lat"`
+	ClockOutLong sql.NullFloat64 `db:"clock_out_long"`
+	ClientLat    sql.NullFloat64 `db:"client_lat"`
+	ClientLong   sql.NullFloat64 `db:"client_long"`
+}
+
+type mileageClaimRow struct {
+	ID               string         `db:"id"`
+	CaregiverID      string         `db:"caregiver_id"`
+	ClaimDate        time.Time      `db:"claim_date"`
+	ComputedKm       float64        `db:"computed_km"`
+	ClaimedKm        float64        `db:"claimed_km"`
+	AdjustmentReason sql.NullString `db:"adjustment_reason"`
+	RatePerKm        float64        `db:"rate_per_km"`
+	Amount           float64        `db:"amount"`
+	Status           string         `db:"status"`
+	SubmittedAt      time.Time      `db:"submitted_at"`
+	ReviewedBy       sql.NullString `db:"reviewed_by"`
+	ReviewedAt       sql.NullTime   `db:"reviewed_at"`
+	ReviewComment    sql.NullString `db:"review_comment"`
+}
+
+type mileageLegRow struct {
+	FromScheduleID string       `db:"from_schedule_id"`
+	ToScheduleID   string       `db:"to_schedule_id"`
+	DepartedAt     sql.NullTime `db:"departed_at"`
+	ArrivedAt      time.Time    `db:"arrived_at"`
+	DistanceKm     float64      `db:"distance_km"`
+	Source         string       `db:"source"`
+}
+
+func (r *MileageRepository) ListVisitStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.MileageStop, error) {
+	var rows []mileageStopRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT s.id,
+		       s.clock_in_at,
+		       s.clock_out_at,
+		       s.clock_in_lat,
+		       s.clock_in_long,
+		       s.clock_out_lat,
+		       s.clock_out_long,
+		       c.latitude AS client_lat,
+		       c.longitude AS client_long
+		FROM schedules s
+		INNER JOIN clients c ON c.id = s.client_id
+		WHERE s.caregiver_id = $1
+		  AND s.status <> 'cancelled'
+		  AND s.clock_in_at >= $2
+		  AND s.clock_in_at < $3
+		ORDER BY s.clock_in_at ASC
+	`, caregiverID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.MileageStop, len(rows))
+	for i, row := range rows {
+		result[i] = domain.MileageStop{
+			ScheduleID:   row.ScheduleID,
+			ClockInAt:    row.ClockInAt,
+			ClockOutAt:   nullTimePtr(row.ClockOutAt),
+			ClockInLat:   nullFloatPtr(row.ClockInLat),
+			ClockInLong:  nullFloatPtr(row.ClockInLong),
+			ClockOutLat:  nullFloatPtr(row.ClockOutLat),
+			ClockOutLong: nullFloatPtr(row.ClockOutLong),
+			ClientLat:    nullFloatPtr(row.ClientLat),
+			ClientLong:   nullFloatPtr(row.ClientLong),
+		}
+	}
+	return result, nil
+}
+
+func (r *MileageRepository) SaveClaim(ctx context.Context, claim domain.MileageClaim) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Only a rejected claim may be replaced; otherwise the upsert matches no row
+	// and the day is reported as a conflict.
+	var id string
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO mileage_claims (
+			caregiver_id, claim_date, computed_km, claimed_km, adjustment_reason, rate_per_km, amount,
+			status, submitted_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'submitted', $8, $8, $8)
+		ON CONFLICT (caregiver_id, claim_date) DO UPDATE SET
+			computed_km = EXCLUDED.computed_km,
+			claimed_km = EXCLUDED.claimed_km,
+			adjustment_reason = EXCLUDED.adjustment_reason,
+			rate_per_km = EXCLUDED.rate_per_km,
+			amount = EXCLUDED.amount,
+			status = 'submitted',
+			submitted_at = EXCLUDED.submitted_at,
+			reviewed_by = NULL,
+			reviewed_at = NULL,
+			review_comment = NULL,
+			updated_at = EXCLUDED.updated_at
+		WHERE mileage_claims.status = 'rejected'
+		RETURNING id
+	`, claim.CaregiverID, claim.ClaimDate.Format("2006-01-02"), claim.ComputedKm, claim.ClaimedKm,
+		claim.AdjustmentReason, claim.RatePerKm, claim.Amount, claim.SubmittedAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrConflict
+		}
+		return "", err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mileage_legs WHERE claim_id = $1`, id); err != nil {
+		return "", err
+	}
+	for i, leg := range claim.Legs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO mileage_legs (
+				claim_id, position, from_schedule_id, to_schedule_id, departed_at, arrived_at, distance_km, source
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, id, i, leg.FromScheduleID, leg.ToScheduleID, leg.DepartedAt, leg.ArrivedAt, leg.DistanceKm, leg.Source); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *MileageRepository) GetClaim(ctx context.Context, claimID string) (domain.MileageClaim, error) {
+	return r.getWithLegs(ctx, `SELECT `+mileageClaimColumns+` FROM mileage_claims WHERE id = $1`, claimID)
+}
+
+func (r *MileageRepository) FindByDate(ctx context.Context, caregiverID string, day time.Time) (domain.MileageClaim, error) {
+	return r.getWithLegs(ctx, `SELECT `+mileageClaimColumns+` FROM mileage_claims WHERE caregiver_id = $1 AND claim_date = $2`, caregiverID, day.Format("2006-01-02"))
+}
+
+func (r *MileageRepository) ListByCaregiver(ctx context.Context, caregiverID string) ([]domain.MileageClaim, error) {
+	return r.list(ctx, `
+		SELECT `+mileageClaimColumns+`
+		FROM mileage_claims
+		WHERE caregiver_id = $1
+		ORDER BY claim_date DESC
+	`, caregiverID)
+}
+
+func (r *MileageRepository) ListByStatus(ctx context.Context, status domain.MileageClaimStatus) ([]domain.MileageClaim, error) {
+	return r.list(ctx, `
+		SELECT `+mileageClaimColumns+`
+		FROM mileage_claims
+		WHERE status = $1
+		ORDER BY claim_date ASC, submitted_at ASC
+	`, status)
+}
+
+func (r *MileageRepository) ListApproved(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.MileageClaim, error) {
+	return r.list(ctx, `
+		SELECT `+mileageClaimColumns+`
+		FROM mileage_claims
+		WHERE caregiver_id = $1
+		  AND status = 'approved'
+		  AND claim_date >= $2
+		  AND claim_date < $3
+		ORDER BY claim_date ASC
+	`, caregiverID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+func (r *MileageRepository) Approve(ctx context.Context, claimID, reviewerID string, comment *string, at time.Time) error {
+	return r.decide(ctx, claimID, domain.MileageClaimStatusApproved, reviewerID, comment, at)
+}
+
+func (r *MileageRepository) Reject(ctx context.Context, claimID, reviewerID string, comment *string, at time.Time) error {
+	return r.decide(ctx, claimID, domain.MileageClaimStatusRejected, reviewerID, comment, at)
+}
+
+func (r *MileageRepository) decide(ctx context.Context, claimID string, status domain.MileageClaimStatus, reviewerID string, comment *string, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE mileage_claims
+		SET status = $2,
+		    reviewed_by = $3,
+		    reviewed_at = $4,
+		    review_comment = $5,
+		    updated_at = $4
+		WHERE id = $1 AND status = 'submitted'
+	`, claimID, status, reviewerID, at, comment)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func (r *MileageRepository) list(ctx context.Context, query string, args ...interface{}) ([]domain.MileageClaim, error) {
+	var rows []mileageClaimRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	result := make([]domain.MileageClaim, len(rows))
+	for i, row := range rows {
+		result[i] = mapMileageClaim(row)
+	}
+	return result, nil
+}
+
+func (r *MileageRepository) getWithLegs(ctx context.Context, query string, args ...interface{}) (domain.MileageClaim, error) {
+	var row mileageClaimRow
+	if err := r.db.GetContext(ctx, &row, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.MileageClaim{}, domain.ErrNotFound
+		}
+		return domain.MileageClaim{}, err
+	}
+	claim := mapMileageClaim(row)
+
+	var legs []mileageLegRow
+	err := r.db.SelectContext(ctx, &legs, `
+		SELECT from_schedule_id, to_schedule_id, departed_at, arrived_at, distance_km, source
+		FROM mileage_legs
+		WHERE claim_id = $1
+		ORDER BY position ASC
+	`, row.ID)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	claim.Legs = make([]domain.MileageLeg, len(legs))
+	for i, leg := range legs {
+		claim.Legs[i] = domain.MileageLeg{
+			FromScheduleID: leg.FromScheduleID,
+			ToScheduleID:   leg.ToScheduleID,
+			DepartedAt:     nullTimePtr(leg.DepartedAt),
+			ArrivedAt:      leg.ArrivedAt,
+			DistanceKm:     leg.DistanceKm,
+			Source:         domain.MileageSource(leg.Source),
+		}
+	}
+	return claim, nil
+}
+
+func mapMileageClaim(row mileageClaimRow) domain.MileageClaim {
+	return domain.MileageClaim{
+		ID:               row.ID,
+		CaregiverID:      row.CaregiverID,
+		ClaimDate:        row.ClaimDate,
+		ComputedKm:       row.ComputedKm,
+		ClaimedKm:        row.ClaimedKm,
+		AdjustmentReason: nullStringPtr(row.AdjustmentReason),
+		RatePerKm:        row.RatePerKm,
+		Amount:           row.Amount,
+		Status:           domain.MileageClaimStatus(row.Status),
+		SubmittedAt:      row.SubmittedAt,
+		ReviewedBy:       nullStringPtr(row.ReviewedBy),
+		ReviewedAt:       nullTimePtr(row.ReviewedAt),
+		ReviewComment:    nullStringPtr(row.ReviewComment),
+	}
+}
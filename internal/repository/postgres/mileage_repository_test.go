@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestMileageRepositoryListVisitStops(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMileageRepository(sqlx.NewDb(db, "pgx"))
+	from := time.Date(2025, 1, 15, 6, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	clockIn := from.Add(3 * time.Hour)
+
+	mock.ExpectQuery("SELECT s.id,[\\s\\S]+FROM schedules s[\\s\\S]+s.clock_in_at >= \\$2[\\s\\S]+ORDER BY s.clock_in_at ASC").
+		WithArgs("cg-1", from, to).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "clock_in_at", "clock_out_at", "clock_in_lat", "clock_in_long",
+			"clock_out_lat", "clock_out_long", "client_lat", "client_long",
+		}).AddRow("sched-1", clockIn, nil, 44.97, -93.26, nil, nil, 44.98, -93.27))
+
+	stops, err := repo.ListVisitStops(context.Background(), "cg-1", from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stops) != 1 || stops[0].ClockOutAt != nil || stops[0].ClockOutLat != nil || *stops[0].ClientLat != 44.98 {
+		t.Fatalf("unexpected stops: %+v", stops)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMileageRepositorySaveClaimConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMileageRepository(sqlx.NewDb(db, "pgx"))
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	now := day.Add(18 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO mileage_claims[\\s\\S]+WHERE mileage_claims.status = 'rejected'").
+		WithArgs("cg-1", "2025-01-15", 14.03, 14.03, nil, 0.4, 5.61, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err = repo.SaveClaim(context.Background(), domain.MileageClaim{
+		CaregiverID: "cg-1",
+		ClaimDate:   day,
+		ComputedKm:  14.03,
+		ClaimedKm:   14.03,
+		RatePerKm:   0.4,
+		Amount:      5.61,
+		SubmittedAt: now,
+	})
+	if err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMileageRepositoryApproveDecidedClaim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMileageRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE mileage_claims[\\s\\S]+WHERE id = \\$1 AND status = 'submitted'").
+		WithArgs("claim-1", domain.MileageClaimStatusApproved, "coordinator-1", now, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.Approve(context.Background(), "claim-1", "coordinator-1", nil, now); err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
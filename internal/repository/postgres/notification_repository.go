@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// NotificationRepository implements repository.NotificationRepository.
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewNotificationRepository constructs the repository.
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+type notificationPreferencesRow struct {
+	CaregiverID   string         `db:"caregiver_id"`
+	Channels      pq.StringArray `db:"channels"`
+	DisabledKinds pq.StringArray `db:"disabled_kinds"`
+	Phone         sql.NullString `db:"phone"`
+	PushToken     sql.NullString `db:"push_token"`
+	Email         sql.NullString `db:"email"`
+	QuietStart    sql.NullInt64  `db:"quiet_start"`
+	QuietEnd      sql.NullInt64  `db:"quiet_end"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func (r *NotificationRepository) GetPreferences(ctx context.Context, caregiverID string) (domain.NotificationPreferences, error) {
+	var row notificationPreferencesRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT caregiver_id, channels, disabled_kinds, phone, push_token, email, quiet_start, quiet_end, updated_at
+		FROM notification_preferences
+		WHERE caregiver_id = $1
+	`, caregiverID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NotificationPreferences{}, domain.ErrNotFound
+		}
+		return domain.NotificationPreferences{}, err
+	}
+
+	prefs := domain.NotificationPreferences{
+		CaregiverID: row.CaregiverID,
+		Phone:       nullStringPtr(row.Phone),
+		PushToken:   nullStringPtr(row.PushToken),
+		Email:       nullStringPtr(row.Email),
+		UpdatedAt:   &row.UpdatedAt,
+	}
+	for _, c := range row.Channels {
+		prefs.Channels = append(prefs.Channels, domain.NotificationChannel(c))
+	}
+	for _, k := range row.DisabledKinds {
+		prefs.DisabledKinds = append(prefs.DisabledKinds, domain.NotificationKind(k))
+	}
+	if row.QuietStart.Valid && row.QuietEnd.Valid {
+		prefs.QuietHours = &domain.QuietHours{Start: int(row.QuietStart.Int64), End: int(row.QuietEnd.Int64)}
+	}
+	return prefs, nil
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, prefs domain.NotificationPreferences) error {
+	channels := make([]string, 0, len(prefs.Channels))
+	for _, c := range prefs.Channels {
+		channels = append(channels, string(c))
+	}
+	kinds := make([]string, 0, len(prefs.DisabledKinds))
+	for _, k := range prefs.DisabledKinds {
+		kinds = append(kinds, string(k))
+	}
+	var quietStart, quietEnd *int
+	if prefs.QuietHours != nil {
+		quietStart, quietEnd = &prefs.QuietHours.Start, &prefs.QuietHours.End
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_preferences (caregiver_id, channels, disabled_kinds, phone, push_token, email, quiet_start, quiet_end, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (caregiver_id) DO UPDATE
+		SET channels = EXCLUDED.channels,
+		    disabled_kinds = EXCLUDED.disabled_kinds,
+		    phone = EXCLUDED.phone,
+		    push_token = EXCLUDED.push_token,
+		    email = EXCLUDED.email,
+		    quiet_start = EXCLUDED.quiet_start,
+		    quiet_end = EXCLUDED.quiet_end,
+		    updated_at = NOW()
+	`, prefs.CaregiverID, pq.Array(channels), pq.Array(kinds), prefs.Phone, prefs.PushToken, prefs.Email, quietStart, quietEnd)
+	return err
+}
+
+const notificationVisitSelect = `
+	SELECT s.id AS schedule_id, s.caregiver_id, cg.name AS caregiver_name, cg.email AS caregiver_email,
+	       c.full_name AS client_name, s.service_name, COALESCE(s.location_label, '') AS location_label, s.start_time, s.end_time
+	FROM schedules s
+	INNER JOIN caregivers cg ON cg.id = s.caregiver_id
+	INNER JOIN clients c ON c.id = s.client_id
+`
+
+type notificationVisitRow struct {
+	ScheduleID     string    `db:"schedule_id"`
+	CaregiverID    string    `db:"caregiver_id"`
+	CaregiverName  string    `db:"caregiver_name"`
+	CaregiverEmail string    `db:"caregiver_email"`
+	ClientName     string    `db:"client_name"`
+	ServiceName    string    `db:"service_name"`
+	LocationLabel  string    `db:"location_label"`
+	StartTime      time.Time `db:"start_time"`
+	EndTime        time.Time `db:"end_time"`
+}
+
+func (r *NotificationRepository) GetVisit(ctx context.Context, scheduleID string) (domain.NotificationVisit, error) {
+	var row notificationVisitRow
+	if err := r.db.GetContext(ctx, &row, notificationVisitSelect+` WHERE s.id = $1`, scheduleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NotificationVisit{}, domain.ErrNotFound
+		}
+		return domain.NotificationVisit{}, err
+	}
+	return mapNotificationVisit(row), nil
+}
+
+func (r *NotificationRepository) ListVisitsStarting(ctx context.Context, from, to time.Time) ([]domain.NotificationVisit, error) {
+	return r.listVisits(ctx, notificationVisitSelect+`
+		WHERE s.status = 'scheduled' AND s.start_time > $1 AND s.start_time <= $2
+		ORDER BY s.start_time ASC, s.id ASC
+	`, from, to)
+}
+
+func (r *NotificationRepository) ListVisitsNotClockedOut(ctx context.Context, from, to time.Time) ([]domain.NotificationVisit, error) {
+	return r.listVisits(ctx, notificationVisitSelect+`
+		WHERE s.status = 'in_progress' AND s.clock_out_at IS NULL AND s.end_time > $1 AND s.end_time <= $2
+		ORDER BY s.end_time ASC, s.id ASC
+	`, from, to)
+}
+
+func (r *NotificationRepository) listVisits(ctx context.Context, query string, args ...interface{}) ([]domain.NotificationVisit, error) {
+	rows := []notificationVisitRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	visits := make([]domain.NotificationVisit, 0, len(rows))
+	for _, row := range rows {
+		visits = append(visits, mapNotificationVisit(row))
+	}
+	return visits, nil
+}
+
+func (r *NotificationRepository) Record(ctx context.Context, n domain.Notification) (string, bool, error) {
+	var id string
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO notification_log (caregiver_id, kind, channel, dedupe_key, schedule_id, recipient, subject, body, status, error, created_at, sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (caregiver_id, kind, channel, dedupe_key) DO NOTHING
+		RETURNING id
+	`, n.CaregiverID, n.Kind, n.Channel, n.DedupeKey, n.ScheduleID, n.Recipient, n.Subject, n.Body, n.Status, n.Error, n.CreatedAt, n.SentAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return id, true, nil
+}
+
+func (r *NotificationRepository) Finish(ctx context.Context, notificationID string, status domain.NotificationStatus, failure *string, at time.Time) error {
+	var sentAt *time.Time
+	if status == domain.NotificationSent {
+		sentAt = &at
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_log
+		SET status = $2, error = $3, sent_at = $4
+		WHERE id = $1 AND status = 'pending'
+	`, notificationID, status, failure, sentAt)
+	return err
+}
+
+type notificationRow struct {
+	ID          string         `db:"id"`
+	CaregiverID string         `db:"caregiver_id"`
+	Kind        string         `db:"kind"`
+	Channel     string         `db:"channel"`
+	DedupeKey   string         `db:"dedupe_key"`
+	ScheduleID  sql.NullString `db:"schedule_id"`
+	Recipient   string         `db:"recipient"`
+	Subject     string         `db:"subject"`
+	Body        string         `db:"body"`
+	Status      string         `db:"status"`
+	Error       sql.NullString `db:"error"`
+	CreatedAt   time.Time      `db:"created_at"`
+	SentAt      sql.NullTime   `db:"sent_at"`
+}
+
+func (r *NotificationRepository) ListByCaregiver(ctx context.Context, caregiverID string, limit int) ([]domain.Notification, error) {
+	rows := []notificationRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT id, caregiver_id, kind, channel, dedupe_key, schedule_id, recipient, subject, body, status, error, created_at, sent_at
+		FROM notification_log
+		WHERE caregiver_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, caregiverID, limit)
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]domain.Notification, 0, len(rows))
+	for _, row := range rows {
+		notifications = append(notifications, domain.Notification{
+			ID:          row.ID,
+			CaregiverID: row.CaregiverID,
+			Kind:        domain.NotificationKind(row.Kind),
+			Channel:     domain.NotificationChannel(row.Channel),
+			DedupeKey:   row.DedupeKey,
+			ScheduleID:  nullStringPtr(row.ScheduleID),
+			Recipient:   row.Recipient,
+			Subject:     row.Subject,
+			Body:        row.Body,
+			Status:      domain.NotificationStatus(row.Status),
+			Error:       nullStringPtr(row.Error),
+			CreatedAt:   row.CreatedAt,
+			SentAt:      nullTimePtr(row.SentAt),
+		})
+	}
+	return notifications, nil
+}
+
+func mapNotificationVisit(row notificationVisitRow) domain.NotificationVisit {
+	return domain.NotificationVisit{
+		ScheduleID:     row.ScheduleID,
+		CaregiverID:    row.CaregiverID,
+		CaregiverName:  row.CaregiverName,
+		CaregiverEmail: row.CaregiverEmail,
+		ClientName:     row.ClientName,
+		ServiceName:    row.ServiceName,
+		LocationLabel:  row.LocationLabel,
+		StartTime:      row.StartTime,
+		EndTime:        row.EndTime,
+	}
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestNotificationRepositoryGetPreferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "pgx"))
+	updated := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`FROM notification_preferences\s+WHERE caregiver_id = \$1`).
+		WithArgs("cg-1").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "channels", "disabled_kinds", "phone", "push_token", "email", "quiet_start", "quiet_end", "updated_at"}).
+			AddRow("cg-1", "{sms,email}", "{visit_assigned}", "+15550100", nil, nil, 1320, 420, updated))
+	mock.ExpectQuery(`FROM notification_preferences`).
+		WithArgs("cg-2").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id"}))
+
+	prefs, err := repo.GetPreferences(context.Background(), "cg-1")
+	if err != nil {
+		t.Fatalf("GetPreferences error: %v", err)
+	}
+	if len(prefs.Channels) != 2 || prefs.Channels[0] != domain.NotificationChannelSMS || prefs.Wants(domain.NotificationVisitAssigned) {
+		t.Fatalf("unexpected preferences %+v", prefs)
+	}
+	if prefs.Phone == nil || prefs.PushToken != nil || prefs.QuietHours == nil || *prefs.QuietHours != (domain.QuietHours{Start: 1320, End: 420}) {
+		t.Fatalf("unexpected contact details %+v", prefs)
+	}
+
+	if _, err := repo.GetPreferences(context.Background(), "cg-2"); err != domain.ErrNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestNotificationRepositoryRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	scheduleID := "sched-1"
+	n := domain.Notification{
+		CaregiverID: "cg-1",
+		Kind:        domain.NotificationVisitReminder,
+		Channel:     domain.NotificationChannelEmail,
+		DedupeKey:   "visit:sched-1",
+		ScheduleID:  &scheduleID,
+		Recipient:   "maria@example.com",
+		Subject:     "Upcoming visit",
+		Body:        "Hi Maria",
+		Status:      domain.NotificationPending,
+		CreatedAt:   now,
+	}
+	insert := regexp.QuoteMeta(`ON CONFLICT (caregiver_id, kind, channel, dedupe_key) DO NOTHING`)
+	mock.ExpectQuery(insert).
+		WithArgs("cg-1", domain.NotificationVisitReminder, domain.NotificationChannelEmail, "visit:sched-1", &scheduleID, "maria@example.com", "Upcoming visit", "Hi Maria", domain.NotificationPending, nil, now, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("n-1"))
+	mock.ExpectQuery(insert).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`UPDATE notification_log\s+SET status = \$2, error = \$3, sent_at = \$4\s+WHERE id = \$1 AND status = 'pending'`).
+		WithArgs("n-1", domain.NotificationSent, nil, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	id, created, err := repo.Record(context.Background(), n)
+	if err != nil || !created || id != "n-1" {
+		t.Fatalf("expected a new entry, got %q, %v, %v", id, created, err)
+	}
+	if _, created, err := repo.Record(context.Background(), n); err != nil || created {
+		t.Fatalf("expected the duplicate to be skipped, got %v, %v", created, err)
+	}
+	if err := repo.Finish(context.Background(), "n-1", domain.NotificationSent, nil, now); err != nil {
+		t.Fatalf("Finish error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestNotificationRepositoryListVisitsNotClockedOut(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "pgx"))
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := from.Add(12 * time.Hour)
+	mock.ExpectQuery(`WHERE s.status = 'in_progress' AND s.clock_out_at IS NULL AND s.end_time > \$1 AND s.end_time <= \$2`).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "caregiver_id", "caregiver_name", "caregiver_email", "client_name", "service_name", "location_label", "start_time", "end_time"}).
+			AddRow("sched-1", "cg-1", "Maria", "maria@example.com", "Mr Jones", "Personal care", "", to.Add(-2*time.Hour), to.Add(-time.Hour)))
+
+	visits, err := repo.ListVisitsNotClockedOut(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("ListVisitsNotClockedOut error: %v", err)
+	}
+	if len(visits) != 1 || visits[0].ClientName != "Mr Jones" || visits[0].CaregiverEmail != "maria@example.com" {
+		t.Fatalf("unexpected visits %+v", visits)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ObservationRepository implements repository.ObservationRepository.
+type ObservationRepository struct {
+	db *sqlx.DB
+}
+
+// NewObservationRepository constructs the repository.
+func NewObservationRepository(db *sqlx.DB) *ObservationRepository {
+	return &ObservationRepository{db: db}
+}
+
+type observationRow struct {
+	ID          string          `db:"id"`
+	ScheduleID  string          `db:"schedule_id"`
+	ClientID    string          `db:"client_id"`
+	CaregiverID string          `db:"caregiver_id"`
+	Type        string          `db:"type"`
+	Value       float64         `db:"value"`
+	Diastolic   sql.NullFloat64 `db:"diastolic"`
+	Unit        string          `db:"unit"`
+	Note        sql.NullString  `db:"note"`
+	Flag        sql.NullString  `db:"flag"`
+	RecordedAt  time.Time       `db:"recorded_at"`
+}
+
+// Create stores the readings and an observation.out_of_range outbox event for
+// each flagged one.
+func (r *ObservationRepository) Create(ctx context.Context, observations []domain.Observation) ([]domain.Observation, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	created := make([]domain.Observation, len(observations))
+	for i, o := range observations {
+		err := tx.QueryRowxContext(ctx, `
+			INSERT INTO observations (schedule_id, client_id, caregiver_id, type, value, diastolic, unit, note, flag, recorded_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+		`, o.ScheduleID, o.ClientID, o.CaregiverID, o.Type, o.Value, o.Diastolic, o.Unit, o.Note, o.Flag, o.RecordedAt).Scan(&o.ID)
+		if err != nil {
+			return nil, err
+		}
+		created[i] = o
+		if o.Flag == nil {
+			continue
+		}
+
+		data := map[string]interface{}{
+			"observation_id": o.ID,
+			"client_id":      o.ClientID,
+			"type":           o.Type,
+			"value":          o.Value,
+			"unit":           o.Unit,
+			"flag":           *o.Flag,
+			"recorded_at":    o.RecordedAt,
+		}
+		if o.Diastolic != nil {
+			data["diastolic"] = *o.Diastolic
+		}
+		err = writeOutbox(ctx, tx, outboxEntry{
+			Type:        domain.EventObservationOutOfRange,
+			CaregiverID: o.CaregiverID,
+			ScheduleID:  o.ScheduleID,
+			Data:        data,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (r *ObservationRepository) ListByClient(ctx context.Context, clientID string, filter repository.ObservationFilter) ([]domain.Observation, error) {
+	types := make([]string, len(filter.Types))
+	for i, t := range filter.Types {
+		types[i] = string(t)
+	}
+	rows := []observationRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT id, schedule_id, client_id, caregiver_id, type, value, diastolic, unit, note, flag, recorded_at
+		FROM observations
+		WHERE client_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+		  AND (cardinality($4::text[]) = 0 OR type = ANY($4))
+		ORDER BY recorded_at ASC, id ASC
+	`, clientID, filter.From, filter.To, pq.Array(types))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.Observation, len(rows))
+	for i, row := range rows {
+		result[i] = mapObservation(row)
+	}
+	return result, nil
+}
+
+func mapObservation(row observationRow) domain.Observation {
+	var flag *domain.ObservationFlag
+	if row.Flag.Valid {
+		f := domain.ObservationFlag(row.Flag.String)
+		flag = &f
+	}
+	return domain.Observation{
+		ID:          row.ID,
+		ScheduleID:  row.ScheduleID,
+		ClientID:    row.ClientID,
+		CaregiverID: row.CaregiverID,
+		Type:        domain.ObservationType(row.Type),
+		Value:       row.Value,
+		Diastolic:   nullFloatPtr(row.Diastolic),
+		Unit:        row.Unit,
+		Note:        nullStringPtr(row.Note),
+		Flag:        flag,
+		RecordedAt:  row.RecordedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestObservationRepositoryCreateAlertsOnFlaggedReadings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewObservationRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 16, 0, 0, 0, time.UTC)
+	high := domain.ObservationFlagHigh
+	diastolic := 82.0
+	bp := domain.Observation{
+		ScheduleID: "sched-1", ClientID: "client-1", CaregiverID: "cg-1",
+		Type: domain.ObservationBloodPressure, Value: 152, Diastolic: &diastolic, Unit: "mmHg", Flag: &high, RecordedAt: now,
+	}
+	mood := domain.Observation{
+		ScheduleID: "sched-1", ClientID: "client-1", CaregiverID: "cg-1",
+		Type: domain.ObservationMood, Value: 4, Unit: "score", RecordedAt: now,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO observations .+ RETURNING id`).
+		WithArgs("sched-1", "client-1", "cg-1", domain.ObservationBloodPressure, 152.0, &diastolic, "mmHg", nil, &high, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("obs-1"))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventObservationOutOfRange, "cg-1", "sched-1",
+			`{"client_id":"client-1","diastolic":82,"flag":"high","observation_id":"obs-1","recorded_at":"2025-01-15T16:00:00Z","type":"blood_pressure","unit":"mmHg","value":152}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO observations`).
+		WithArgs("sched-1", "client-1", "cg-1", domain.ObservationMood, 4.0, nil, "score", nil, nil, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("obs-2"))
+	mock.ExpectCommit()
+
+	created, err := repo.Create(context.Background(), []domain.Observation{bp, mood})
+	if err != nil {
+		t.Fatalf("Create error: %v", err)
+	}
+	if len(created) != 2 || created[0].ID != "obs-1" || created[1].ID != "obs-2" {
+		t.Fatalf("unexpected observations: %+v", created)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestObservationRepositoryListByClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewObservationRepository(sqlx.NewDb(db, "pgx"))
+	from := time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 16, 6, 0, 0, 0, time.UTC)
+	at := time.Date(2025, 1, 15, 16, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM observations\s+WHERE client_id = \$1 AND recorded_at >= \$2 AND recorded_at < \$3[\s\S]+ORDER BY recorded_at ASC, id ASC`).
+		WithArgs("client-1", from, to, `{"blood_pressure"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "schedule_id", "client_id", "caregiver_id", "type", "value", "diastolic", "unit", "note", "flag", "recorded_at"}).
+			AddRow("obs-1", "sched-1", "client-1", "cg-1", "blood_pressure", 152.0, 82.0, "mmHg", nil, "high", at))
+
+	observations, err := repo.ListByClient(context.Background(), "client-1", repository.ObservationFilter{
+		From:  from,
+		To:    to,
+		Types: []domain.ObservationType{domain.ObservationBloodPressure},
+	})
+	if err != nil {
+		t.Fatalf("ListByClient error: %v", err)
+	}
+	if len(observations) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(observations))
+	}
+	o := observations[0]
+	if o.Diastolic == nil || *o.Diastolic != 82 || o.Flag == nil || *o.Flag != domain.ObservationFlagHigh || o.Note != nil {
+		t.Fatalf("unexpected observation %+v", o)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// OpenShiftRepository implements repository.OpenShiftRepository backed by Postgres.
+type OpenShiftRepository struct {
+	db *sqlx.DB
+}
+
+// NewOpenShiftRepository creates a new repository.
+func NewOpenShiftRepository(db *sqlx.DB) *OpenShiftRepository {
+	return &OpenShiftRepository{db: db}
+}
+
+type openShiftRow struct {
+	ID                     string          `db:"id"`
+	ClientID               string          `db:"client_id"`
+	ClientName             string          `db:"client_name"`
+	ServiceName            string          `db:"service_name"`
+	LocationName           sql.NullString  `db:"location_label"`
+	StartTime              time.Time       `db:"start_time"`
+	EndTime                time.Time       `db:"end_time"`
+	Latitude               sql.NullFloat64 `db:"latitude"`
+	Longitude              sql.NullFloat64 `db:"longitude"`
+	RequiredQualifications pq.StringArray  `db:"required_qualifications"`
+}
+
+const openShiftColumns = `
+		SELECT s.id,
+		       s.client_id,
+		       c.full_name AS client_name,
+		       s.service_name,
+		       s.location_label,
+		       s.start_time,
+		       s.end_time,
+		       c.latitude,
+		       c.longitude,
+		       s.required_qualifications
+		FROM schedules s
+		INNER JOIN clients c ON c.id = s.client_id
+		WHERE s.caregiver_id IS NULL AND s.status = 'scheduled'
+`
+
+// caregiverFree matches open shifts s that do not overlap a committed visit of
+// the caregiver bound to param, as ListCommittedVisits counts them.
+func caregiverFree(param string) string {
+	return `NOT EXISTS (
+			SELECT 1 FROM schedules busy
+			WHERE busy.caregiver_id = ` + param + `
+			  AND busy.status <> 'cancelled'
+			  AND busy.start_time < s.end_time
+			  AND busy.end_time > s.start_time
+		)`
+}
+
+func (r *OpenShiftRepository) ListOpenShifts(ctx context.Context, filter repository.OpenShiftFilter) ([]domain.OpenShift, error) {
+	query := openShiftColumns + " AND s.start_time >= $1"
+	args := []interface{}{filter.From}
+	argPosition := 2
+
+	if filter.To != nil {
+		query += " AND s.start_time < $" + itoa(argPosition)
+		args = append(args, *filter.To)
+		argPosition++
+	}
+
+	if filter.Qualifications != nil {
+		query += " AND s.required_qualifications <@ $" + itoa(argPosition)
+		args = append(args, pq.Array(filter.Qualifications))
+		argPosition++
+	}
+
+	if filter.FreeCaregiverID != "" {
+		query += " AND " + caregiverFree("$"+itoa(argPosition))
+		args = append(args, filter.FreeCaregiverID)
+		argPosition++
+	}
+
+	query += " ORDER BY s.start_time ASC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT $" + itoa(argPosition)
+		args = append(args, filter.Limit)
+	}
+
+	rows := []openShiftRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.OpenShift, len(rows))
+	for i, row := range rows {
+		result[i] = mapOpenShift(row)
+	}
+	return result, nil
+}
+
+func (r *OpenShiftRepository) GetOpenShift(ctx context.Context, scheduleID string) (domain.OpenShift, error) {
+	var row openShiftRow
+	if err := r.db.GetContext(ctx, &row, openShiftColumns+" AND s.id = $1", scheduleID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.OpenShift{}, domain.ErrNotFound
+		}
+		return domain.OpenShift{}, err
+	}
+	return mapOpenShift(row), nil
+}
+
+// ClaimOpenShift relies on the conditional UPDATE taking a row lock: the first
+// transaction to commit wins and later ones match zero rows. Claims by the
+// same caregiver are serialised so two overlapping shifts cannot both pass
+// the overlap check.
+func (r *OpenShiftRepository) ClaimOpenShift(ctx context.Context, scheduleID, caregiverID string, claimedAt time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('claim:' || $1))`, caregiverID); err != nil {
+		return err
+	}
+	query := `
+		UPDATE schedules s
+		SET caregiver_id = $2,
+		    claimed_at = $3,
+		    updated_at = NOW()
+		WHERE s.id = $1 AND s.caregiver_id IS NULL AND s.status = 'scheduled'
+		  AND ` + caregiverFree("$2")
+	res, err := tx.ExecContext(ctx, query, scheduleID, caregiverID, claimedAt)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConflict
+	}
+	return tx.Commit()
+}
+
+func mapOpenShift(row openShiftRow) domain.OpenShift {
+	shift := domain.OpenShift{
+		ScheduleID:             row.ID,
+		ClientID:               row.ClientID,
+		ClientName:             row.ClientName,
+		ServiceName:            row.ServiceName,
+		StartTime:              row.StartTime,
+		EndTime:                row.EndTime,
+		RequiredQualifications: []string(row.RequiredQualifications),
+	}
+	if row.LocationName.Valid {
+		shift.LocationName = row.LocationName.String
+	}
+	if row.Latitude.Valid {
+		shift.Latitude = row.Latitude.Float64
+	}
+	if row.Longitude.Valid {
+		shift.Longitude = row.Longitude.Float64
+	}
+	return shift
+}
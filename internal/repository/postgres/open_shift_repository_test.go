@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestOpenShiftRepositoryListOpenShifts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOpenShiftRepository(sqlx.NewDb(db, "pgx"))
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{
+		"id", "client_id", "client_name", "service_name", "location_label", "start_time", "end_time",
+		"latitude", "longitude", "required_qualifications",
+	}).AddRow("sched-1", "client-1", "Client A", "Service", "Location", now, now.Add(time.Hour), 1.5, 2.5, "{personal_care}")
+
+	mock.ExpectQuery("WHERE s\\.caregiver_id IS NULL[\\s\\S]+required_qualifications <@ \\$2[\\s\\S]+NOT EXISTS[\\s\\S]+busy\\.caregiver_id = \\$3[\\s\\S]+ORDER BY s\\.start_time ASC").
+		WithArgs(now, sqlmock.AnyArg(), "cg-1").
+		WillReturnRows(rows)
+
+	shifts, err := repo.ListOpenShifts(context.Background(), repository.OpenShiftFilter{From: now, Qualifications: []string{"personal_care"}, FreeCaregiverID: "cg-1"})
+	if err != nil {
+		t.Fatalf("ListOpenShifts error: %v", err)
+	}
+	if len(shifts) != 1 || shifts[0].ScheduleID != "sched-1" {
+		t.Fatalf("unexpected shifts: %+v", shifts)
+	}
+	if len(shifts[0].RequiredQualifications) != 1 || shifts[0].RequiredQualifications[0] != "personal_care" {
+		t.Fatalf("unexpected qualifications: %v", shifts[0].RequiredQualifications)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestOpenShiftRepositoryClaimConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOpenShiftRepository(sqlx.NewDb(db, "pgx"))
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext('claim:' || $1))`)).
+		WithArgs("cg-2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE schedules s[\\s\\S]+WHERE s\\.id = \\$1 AND s\\.caregiver_id IS NULL[\\s\\S]+NOT EXISTS[\\s\\S]+busy\\.caregiver_id = \\$2").
+		WithArgs("sched-1", "cg-2", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := repo.ClaimOpenShift(context.Background(), "sched-1", "cg-2", now); err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestOpenShiftRepositoryClaim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewOpenShiftRepository(sqlx.NewDb(db, "pgx"))
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext('claim:' || $1))`)).
+		WithArgs("cg-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE schedules s[\\s\\S]+NOT EXISTS").
+		WithArgs("sched-1", "cg-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.ClaimOpenShift(context.Background(), "sched-1", "cg-1", now); err != nil {
+		t.Fatalf("ClaimOpenShift error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// outboxEntry is a domain event about a change being written in a transaction.
+type outboxEntry struct {
+	Type        domain.EventType
+	CaregiverID string
+	ScheduleID  string
+	Data        map[string]interface{}
+}
+
+const insertOutboxEvent = `
+	INSERT INTO outbox_events (event_type, caregiver_id, schedule_id, payload)
+	VALUES ($1, $2, $3, $4::jsonb)
+`
+
+// writeOutbox records entry in tx so the event is stored if, and only if, the
+// change it describes commits.
+func writeOutbox(ctx context.Context, tx *sqlx.Tx, entry outboxEntry) error {
+	payload, err := json.Marshal(entry.Data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertOutboxEvent, entry.Type, nullable(entry.CaregiverID), nullable(entry.ScheduleID), string(payload))
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
@@ -32,8 +33,20 @@ type scheduleSummaryRow struct {
 	EndTime      time.Time `db:"end_time"`
 	Status       string    `db:"status"`
 	LocationName string    `db:"location_label"`
+
+	ManuallyEdited bool `db:"manually_edited"`
 }
 
+// scheduleListRank mirrors domain.ScheduleStatus.ListRank.
+const scheduleListRank = `CASE s.status
+		WHEN 'in_progress' THEN 0
+		WHEN 'scheduled' THEN 1
+		WHEN 'missed' THEN 2
+		WHEN 'completed' THEN 3
+		WHEN 'cancelled' THEN 4
+		ELSE 5
+	END`
+
 func (r *ScheduleRepository) ListSchedules(ctx context.Context, caregiverID string, filter repository.ScheduleFilter) ([]domain.ScheduleSummary, error) {
 	query := `
 		SELECT s.id,
@@ -43,7 +56,8 @@ func (r *ScheduleRepository) ListSchedules(ctx context.Context, caregiverID stri
 		       s.start_time,
 		       s.end_time,
 		       s.status,
-		       s.location_label
+		       s.location_label,
+		       s.manually_edited
 		FROM schedules s
 		INNER JOIN clients c ON c.id = s.client_id
 		WHERE s.caregiver_id = $1
@@ -62,26 +76,75 @@ func (r *ScheduleRepository) ListSchedules(ctx context.Context, caregiverID stri
 	}
 
 	if filter.Date != nil {
-		start := filter.Date.Truncate(24 * time.Hour)
-		end := start.Add(24 * time.Hour)
+		// The day is the calendar date of filter.Date in its own location.
+		start, end := domain.DayBounds(*filter.Date, filter.Date.Location())
+		query += " AND s.start_time >= $" + itoa(argPosition)
+		args = append(args, start)
+		argPosition++
+		query += " AND s.start_time < $" + itoa(argPosition)
+		args = append(args, end)
+		argPosition++
+	}
+
+	if filter.From != nil {
+		start, _ := domain.DayBounds(*filter.From, filter.From.Location())
 		query += " AND s.start_time >= $" + itoa(argPosition)
 		args = append(args, start)
 		argPosition++
+	}
+
+	if filter.To != nil {
+		_, end := domain.DayBounds(*filter.To, filter.To.Location())
 		query += " AND s.start_time < $" + itoa(argPosition)
 		args = append(args, end)
 		argPosition++
 	}
 
-	query += ` ORDER BY 
-		CASE s.status 
-			WHEN 'in_progress' THEN 0
-			WHEN 'scheduled' THEN 1
-			WHEN 'missed' THEN 2
-			WHEN 'completed' THEN 3
-			WHEN 'cancelled' THEN 4
-			ELSE 5
-		END,
-		s.start_time DESC`
+	if filter.ClientID != "" {
+		query += " AND s.client_id = $" + itoa(argPosition)
+		args = append(args, filter.ClientID)
+		argPosition++
+	}
+
+	if filter.ServiceName != "" {
+		query += " AND LOWER(s.service_name) = LOWER($" + itoa(argPosition) + ")"
+		args = append(args, filter.ServiceName)
+		argPosition++
+	}
+
+	if filter.Search != "" {
+		query += " AND (c.full_name ILIKE $" + itoa(argPosition) + " OR s.location_label ILIKE $" + itoa(argPosition) + ")"
+		args = append(args, containsPattern(filter.Search))
+		argPosition++
+	}
+
+	// Keyset continuation in listing order; id breaks ties between visits
+	// starting at the same time.
+	switch filter.Sort {
+	case repository.ScheduleSortStartAsc:
+		if filter.After != nil {
+			query += " AND (s.start_time, s.id) > ($" + itoa(argPosition) + ", $" + itoa(argPosition+1) + ")"
+			args = append(args, filter.After.Time, filter.After.ID)
+			argPosition += 2
+		}
+		query += " ORDER BY s.start_time ASC, s.id ASC"
+	case repository.ScheduleSortStartDesc:
+		if filter.After != nil {
+			query += " AND (s.start_time, s.id) < ($" + itoa(argPosition) + ", $" + itoa(argPosition+1) + ")"
+			args = append(args, filter.After.Time, filter.After.ID)
+			argPosition += 2
+		}
+		query += " ORDER BY s.start_time DESC, s.id DESC"
+	default:
+		if filter.After != nil {
+			query += " AND (" + scheduleListRank + " > $" + itoa(argPosition) +
+				" OR (" + scheduleListRank + " = $" + itoa(argPosition) +
+				" AND (s.start_time, s.id) < ($" + itoa(argPosition+1) + ", $" + itoa(argPosition+2) + ")))"
+			args = append(args, filter.After.Rank, filter.After.Time, filter.After.ID)
+			argPosition += 3
+		}
+		query += " ORDER BY " + scheduleListRank + ", s.start_time DESC, s.id DESC"
+	}
 
 	// Add pagination if limit is specified
 	if filter.Limit > 0 {
@@ -89,7 +152,7 @@ func (r *ScheduleRepository) ListSchedules(ctx context.Context, caregiverID stri
 		args = append(args, filter.Limit)
 		argPosition++
 
-		if filter.Offset > 0 {
+		if filter.Offset > 0 && filter.After == nil {
 			query += " OFFSET $" + itoa(argPosition)
 			args = append(args, filter.Offset)
 		}
@@ -111,6 +174,8 @@ func (r *ScheduleRepository) ListSchedules(ctx context.Context, caregiverID stri
 			EndTime:      row.EndTime,
 			Status:       domain.ScheduleStatus(row.Status),
 			LocationName: row.LocationName,
+
+			ManuallyEdited: row.ManuallyEdited,
 		}
 	}
 	return result, nil
@@ -118,7 +183,7 @@ func (r *ScheduleRepository) ListSchedules(ctx context.Context, caregiverID stri
 
 type scheduleRow struct {
 	ID           string          `db:"id"`
-	CaregiverID  string          `db:"caregiver_id"`
+	CaregiverID  sql.NullString  `db:"caregiver_id"`
 	ClientID     string          `db:"client_id"`
 	ServiceName  string          `db:"service_name"`
 	LocationName sql.NullString  `db:"location_label"`
@@ -135,6 +200,12 @@ type scheduleRow struct {
 	CreatedAt    time.Time       `db:"created_at"`
 	UpdatedAt    time.Time       `db:"updated_at"`
 
+	RequiredQualifications pq.StringArray `db:"required_qualifications"`
+	ClaimedAt              sql.NullTime   `db:"claimed_at"`
+	FlexibleWindowMins     int            `db:"flexible_window_mins"`
+	ServiceCode            sql.NullString `db:"service_code"`
+	ManuallyEdited         bool           `db:"manually_edited"`
+
 	ClientFullName string          `db:"client_full_name"`
 	ClientEmail    sql.NullString  `db:"client_email"`
 	ClientPhone    sql.NullString  `db:"client_phone"`
@@ -194,7 +265,24 @@ func (r *ScheduleRepository) LogClockIn(ctx context.Context, scheduleID string,
 	return err
 }
 
-func (r *ScheduleRepository) LogClockOut(ctx context.Context, scheduleID string, event domain.VisitEvent) error {
+// CompleteVisit records the clock-out, stores the sign-off when there is one
+// and marks the visit completed in one transaction, so a visit is never left
+// clocked out without its acknowledgement. The visit must be in progress.
+func (r *ScheduleRepository) CompleteVisit(ctx context.Context, scheduleID string, event domain.VisitEvent, signoff *domain.VisitSignoff) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := lockScheduleStatus(ctx, tx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if domain.ScheduleStatus(current.Status) != domain.ScheduleStatusInProgress {
+		return domain.ErrInvalidStatusTransition
+	}
+
 	query := `
 		UPDATE schedules
 		SET clock_out_at = $2,
@@ -204,45 +292,118 @@ func (r *ScheduleRepository) LogClockOut(ctx context.Context, scheduleID string,
 		    updated_at = NOW()
 		WHERE id = $1
 	`
-	_, err := r.db.ExecContext(ctx, query, scheduleID, event.Timestamp, event.Latitude, event.Longitude, event.Notes)
-	return err
+	if _, err := tx.ExecContext(ctx, query, scheduleID, event.Timestamp, event.Latitude, event.Longitude, event.Notes); err != nil {
+		return err
+	}
+	if signoff != nil {
+		if err := saveVisitSignoff(ctx, tx, *signoff); err != nil {
+			return err
+		}
+	}
+	if err := setScheduleStatus(ctx, tx, scheduleID, current, domain.ScheduleStatusCompleted); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// UpdateStatus changes the visit's status and, when it differs from the
+// current one, records a schedule.status_changed outbox event.
 func (r *ScheduleRepository) UpdateStatus(ctx context.Context, scheduleID string, status domain.ScheduleStatus) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	current, err := lockScheduleStatus(ctx, tx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if err := setScheduleStatus(ctx, tx, scheduleID, current, status); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type scheduleStatusRow struct {
+	CaregiverID sql.NullString `db:"caregiver_id"`
+	ClientID    string         `db:"client_id"`
+	Status      string         `db:"status"`
+}
+
+// lockScheduleStatus locks the visit's row for the rest of tx and returns its
+// current status.
+func lockScheduleStatus(ctx context.Context, tx *sqlx.Tx, scheduleID string) (scheduleStatusRow, error) {
+	var current scheduleStatusRow
+	err := tx.GetContext(ctx, &current, `SELECT caregiver_id, client_id, status FROM schedules WHERE id = $1 FOR UPDATE`, scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return scheduleStatusRow{}, domain.ErrNotFound
+		}
+		return scheduleStatusRow{}, err
+	}
+	return current, nil
+}
+
+// setScheduleStatus changes the locked visit's status and, when it differs
+// from current, records a schedule.status_changed outbox event.
+func setScheduleStatus(ctx context.Context, tx *sqlx.Tx, scheduleID string, current scheduleStatusRow, status domain.ScheduleStatus) error {
 	query := `
 		UPDATE schedules
 		SET status = $2,
 		    updated_at = NOW()
 		WHERE id = $1
 	`
-	_, err := r.db.ExecContext(ctx, query, scheduleID, status)
-	return err
+	if _, err := tx.ExecContext(ctx, query, scheduleID, status); err != nil {
+		return err
+	}
+	if domain.ScheduleStatus(current.Status) == status {
+		return nil
+	}
+	return writeOutbox(ctx, tx, outboxEntry{
+		Type:        domain.EventScheduleStatusChanged,
+		CaregiverID: current.CaregiverID.String,
+		ScheduleID:  scheduleID,
+		Data: map[string]interface{}{
+			"schedule_id": scheduleID,
+			"client_id":   current.ClientID,
+			"from":        current.Status,
+			"status":      status,
+		},
+	})
 }
 
-func (r *ScheduleRepository) GetMetrics(ctx context.Context, caregiverID string, day time.Time) (domain.ScheduleMetrics, error) {
-	start := day.Truncate(24 * time.Hour)
-	end := start.Add(24 * time.Hour)
+func (r *ScheduleRepository) GetMetrics(ctx context.Context, caregiverID string, day time.Time, rule domain.OvernightRule) (domain.ScheduleMetrics, error) {
+	start, end := domain.DayBounds(day, day.Location())
 
 	type counts struct {
-		Total      int `db:"total"`
-		Scheduled  int `db:"scheduled"`
-		InProgress int `db:"in_progress"`
-		Completed  int `db:"completed"`
-		Cancelled  int `db:"cancelled"`
-		Missed     int `db:"missed"`
+		Total       int `db:"total"`
+		Scheduled   int `db:"scheduled"`
+		InProgress  int `db:"in_progress"`
+		Completed   int `db:"completed"`
+		Cancelled   int `db:"cancelled"`
+		Missed      int `db:"missed"`
+		CarriedOver int `db:"carried_over"`
+	}
+
+	// Visits belong to the day they start in; under the split rule a visit
+	// running past midnight is also counted on the following day(s).
+	window := "s.start_time >= $2 AND s.start_time < $3"
+	if rule == domain.OvernightRuleSplit {
+		window = "s.start_time < $3 AND (s.start_time >= $2 OR s.end_time > $2)"
 	}
 
 	query := `
 		SELECT
-			COUNT(*) FILTER (WHERE s.start_time >= $2 AND s.start_time < $3) AS total,
-			COUNT(*) FILTER (WHERE s.status = 'scheduled' AND s.start_time >= $2 AND s.start_time < $3) AS scheduled,
-			COUNT(*) FILTER (WHERE s.status = 'in_progress' AND s.start_time >= $2 AND s.start_time < $3) AS in_progress,
-			COUNT(*) FILTER (WHERE s.status = 'completed' AND s.start_time >= $2 AND s.start_time < $3) AS completed,
-			COUNT(*) FILTER (WHERE s.status = 'cancelled' AND s.start_time >= $2 AND s.start_time < $3) AS cancelled,
-			COUNT(*) FILTER (WHERE s.status = 'missed' AND s.start_time >= $2 AND s.start_time < $3) AS missed
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE s.status = 'scheduled') AS scheduled,
+			COUNT(*) FILTER (WHERE s.status = 'in_progress') AS in_progress,
+			COUNT(*) FILTER (WHERE s.status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE s.status = 'cancelled') AS cancelled,
+			COUNT(*) FILTER (WHERE s.status = 'missed') AS missed,
+			COUNT(*) FILTER (WHERE s.start_time < $2) AS carried_over
 		FROM schedules s
-		WHERE s.caregiver_id = $1
-	`
+		WHERE s.caregiver_id = $1 AND ` + window
 
 	var c counts
 	if err := r.db.GetContext(ctx, &c, query, caregiverID, start, end); err != nil {
@@ -250,15 +411,207 @@ func (r *ScheduleRepository) GetMetrics(ctx context.Context, caregiverID string,
 	}
 
 	return domain.ScheduleMetrics{
-		Total:      c.Total,
-		Upcoming:   c.Scheduled,
-		InProgress: c.InProgress,
-		Completed:  c.Completed,
-		Cancelled:  c.Cancelled,
-		Missed:     c.Missed,
+		Total:       c.Total,
+		Upcoming:    c.Scheduled,
+		InProgress:  c.InProgress,
+		Completed:   c.Completed,
+		Cancelled:   c.Cancelled,
+		Missed:      c.Missed,
+		CarriedOver: c.CarriedOver,
 	}, nil
 }
 
+type metricsRangeRow struct {
+	Bucket             sql.NullTime    `db:"bucket"`
+	IsTotal            int             `db:"is_total"`
+	Total              int             `db:"total"`
+	Scheduled          int             `db:"scheduled"`
+	InProgress         int             `db:"in_progress"`
+	Completed          int             `db:"completed"`
+	Cancelled          int             `db:"cancelled"`
+	Missed             int             `db:"missed"`
+	Arrivals           int             `db:"arrivals"`
+	OnTime             int             `db:"on_time"`
+	OnTimeRate         sql.NullFloat64 `db:"on_time_rate"`
+	AvgVisitMinutes    sql.NullFloat64 `db:"avg_visit_minutes"`
+	AvgPlannedMinutes  sql.NullFloat64 `db:"avg_planned_minutes"`
+	DurationRatio      sql.NullFloat64 `db:"duration_ratio"`
+	Tasks              int             `db:"tasks"`
+	TasksCompleted     int             `db:"tasks_completed"`
+	TaskCompletionRate sql.NullFloat64 `db:"task_completion_rate"`
+}
+
+func (r *ScheduleRepository) GetMetricsRange(ctx context.Context, caregiverID string, q repository.MetricsRangeQuery) ([]domain.MetricsBucket, domain.VisitMetrics, error) {
+	// Each visit is aggregated once, into the bucket of its start date in the
+	// caregiver's timezone; the empty grouping set adds the range totals.
+	query := `
+		WITH visits AS (
+			SELECT date_trunc($4, s.start_time AT TIME ZONE $5)::date AS bucket,
+			       s.status,
+			       s.clock_in_at,
+			       s.clock_in_at <= s.start_time + make_interval(mins => s.flexible_window_mins) + make_interval(secs => $6) AS on_time,
+			       CASE WHEN s.clock_out_at IS NOT NULL THEN EXTRACT(EPOCH FROM s.clock_out_at - s.clock_in_at) / 60 END AS visit_minutes,
+			       CASE WHEN s.clock_out_at IS NOT NULL THEN EXTRACT(EPOCH FROM s.end_time - s.start_time) / 60 END AS planned_minutes,
+			       t.tasks,
+			       t.tasks_completed
+			FROM schedules s
+			LEFT JOIN LATERAL (
+				SELECT COUNT(*) AS tasks,
+				       COUNT(*) FILTER (WHERE st.status = 'completed') AS tasks_completed
+				FROM schedule_tasks st
+				WHERE st.schedule_id = s.id
+			) t ON s.status <> 'cancelled'
+			WHERE s.caregiver_id = $1 AND s.start_time >= $2 AND s.start_time < $3
+		),
+		totals AS (
+			SELECT bucket,
+			       GROUPING(bucket) AS is_total,
+			       COUNT(*) AS total,
+			       COUNT(*) FILTER (WHERE status = 'scheduled') AS scheduled,
+			       COUNT(*) FILTER (WHERE status = 'in_progress') AS in_progress,
+			       COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			       COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled,
+			       COUNT(*) FILTER (WHERE status = 'missed') AS missed,
+			       COUNT(clock_in_at) AS arrivals,
+			       COUNT(*) FILTER (WHERE on_time) AS on_time,
+			       COUNT(visit_minutes) AS timed,
+			       SUM(visit_minutes) AS visit_minutes,
+			       SUM(planned_minutes) AS planned_minutes,
+			       COALESCE(SUM(tasks), 0)::bigint AS tasks,
+			       COALESCE(SUM(tasks_completed), 0)::bigint AS tasks_completed
+			FROM visits
+			GROUP BY GROUPING SETS ((bucket), ())
+		)
+		SELECT bucket, is_total, total, scheduled, in_progress, completed, cancelled, missed,
+		       arrivals, on_time,
+		       ROUND(on_time::numeric / NULLIF(arrivals, 0), 4)::float8 AS on_time_rate,
+		       ROUND(visit_minutes / NULLIF(timed, 0), 1)::float8 AS avg_visit_minutes,
+		       ROUND(planned_minutes / NULLIF(timed, 0), 1)::float8 AS avg_planned_minutes,
+		       ROUND(visit_minutes / NULLIF(planned_minutes, 0), 4)::float8 AS duration_ratio,
+		       tasks, tasks_completed,
+		       ROUND(tasks_completed::numeric / NULLIF(tasks, 0), 4)::float8 AS task_completion_rate
+		FROM totals
+		ORDER BY is_total, bucket
+	`
+
+	rows := []metricsRangeRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, caregiverID, q.From, q.To, string(q.Granularity), q.TimeZone, q.OnTimeGrace.Seconds()); err != nil {
+		return nil, domain.VisitMetrics{}, err
+	}
+
+	var buckets []domain.MetricsBucket
+	var summary domain.VisitMetrics
+	for _, row := range rows {
+		metrics := domain.VisitMetrics{
+			Total:              row.Total,
+			Upcoming:           row.Scheduled,
+			InProgress:         row.InProgress,
+			Completed:          row.Completed,
+			Cancelled:          row.Cancelled,
+			Missed:             row.Missed,
+			Arrivals:           row.Arrivals,
+			OnTime:             row.OnTime,
+			OnTimeRate:         nullFloatPtr(row.OnTimeRate),
+			AvgVisitMinutes:    nullFloatPtr(row.AvgVisitMinutes),
+			AvgPlannedMinutes:  nullFloatPtr(row.AvgPlannedMinutes),
+			DurationRatio:      nullFloatPtr(row.DurationRatio),
+			Tasks:              row.Tasks,
+			TasksCompleted:     row.TasksCompleted,
+			TaskCompletionRate: nullFloatPtr(row.TaskCompletionRate),
+		}
+		if row.IsTotal == 1 {
+			summary = metrics
+			continue
+		}
+		buckets = append(buckets, domain.MetricsBucket{Start: row.Bucket.Time, Metrics: metrics})
+	}
+	return buckets, summary, nil
+}
+
+type routeStopRow struct {
+	ID                 string          `db:"id"`
+	ClientName         string          `db:"client_name"`
+	LocationName       sql.NullString  `db:"location_label"`
+	Status             string          `db:"status"`
+	StartTime          time.Time       `db:"start_time"`
+	EndTime            time.Time       `db:"end_time"`
+	Latitude           sql.NullFloat64 `db:"latitude"`
+	Longitude          sql.NullFloat64 `db:"longitude"`
+	FlexibleWindowMins int             `db:"flexible_window_mins"`
+}
+
+func (r *ScheduleRepository) ListRouteStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.RouteStop, error) {
+	query := `
+		SELECT s.id,
+		       c.full_name AS client_name,
+		       s.location_label,
+		       s.status,
+		       s.start_time,
+		       s.end_time,
+		       c.latitude,
+		       c.longitude,
+		       s.flexible_window_mins
+		FROM schedules s
+		INNER JOIN clients c ON c.id = s.client_id
+		WHERE s.caregiver_id = $1
+		  AND s.status <> 'cancelled'
+		  AND s.start_time >= $2
+		  AND s.start_time < $3
+		ORDER BY s.start_time ASC
+	`
+	rows := []routeStopRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, caregiverID, from, to); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.RouteStop, len(rows))
+	for i, row := range rows {
+		result[i] = domain.RouteStop{
+			ScheduleID:         row.ID,
+			ClientName:         row.ClientName,
+			LocationName:       row.LocationName.String,
+			Status:             domain.ScheduleStatus(row.Status),
+			StartTime:          row.StartTime,
+			EndTime:            row.EndTime,
+			Latitude:           row.Latitude.Float64,
+			Longitude:          row.Longitude.Float64,
+			FlexibleWindowMins: row.FlexibleWindowMins,
+		}
+	}
+	return result, nil
+}
+
+func (r *ScheduleRepository) ListVisitIntervals(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.VisitInterval, error) {
+	query := `
+		SELECT id, clock_in_at, clock_out_at
+		FROM schedules
+		WHERE caregiver_id = $1
+		  AND clock_in_at IS NOT NULL
+		  AND clock_in_at < $3
+		  AND (clock_out_at IS NULL OR clock_out_at > $2)
+		ORDER BY clock_in_at ASC
+	`
+
+	var rows []struct {
+		ID         string       `db:"id"`
+		ClockInAt  time.Time    `db:"clock_in_at"`
+		ClockOutAt sql.NullTime `db:"clock_out_at"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, caregiverID, from, to); err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.VisitInterval, len(rows))
+	for i, row := range rows {
+		result[i] = domain.VisitInterval{
+			ScheduleID: row.ID,
+			Start:      row.ClockInAt,
+			End:        nullTimePtr(row.ClockOutAt),
+		}
+	}
+	return result, nil
+}
+
 func mapSchedule(row scheduleRow) domain.Schedule {
 	var clockInAt, clockOutAt *time.Time
 	if row.ClockInAt.Valid {
@@ -330,9 +683,15 @@ func mapSchedule(row scheduleRow) domain.Schedule {
 		location = row.LocationName.String
 	}
 
+	var claimedAt *time.Time
+	if row.ClaimedAt.Valid {
+		t := row.ClaimedAt.Time
+		claimedAt = &t
+	}
+
 	return domain.Schedule{
 		ID:            row.ID,
-		CaregiverID:   row.CaregiverID,
+		CaregiverID:   row.CaregiverID.String,
 		Client:        client,
 		ServiceName:   row.ServiceName,
 		LocationLabel: location,
@@ -349,9 +708,22 @@ func mapSchedule(row scheduleRow) domain.Schedule {
 		CreatedAt:     row.CreatedAt,
 		UpdatedAt:     row.UpdatedAt,
 		DurationMins:  duration,
+
+		RequiredQualifications: []string(row.RequiredQualifications),
+		ClaimedAt:              claimedAt,
+		FlexibleWindowMins:     row.FlexibleWindowMins,
+		ServiceCode:            row.ServiceCode.String,
+		ManuallyEdited:         row.ManuallyEdited,
 	}
 }
 
+// containsPattern returns an ILIKE pattern matching values that contain term
+// literally.
+func containsPattern(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+	return "%" + escaped + "%"
+}
+
 func itoa(i int) string {
 	return strconv.Itoa(i)
 }
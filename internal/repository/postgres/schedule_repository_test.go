@@ -45,6 +45,83 @@ func TestScheduleRepositoryListSchedules(t *testing.T) {
 	}
 }
 
+func TestScheduleRepositoryListSchedulesAfterCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+	repo := NewScheduleRepository(sqlxDB)
+
+	now := time.Now()
+	cursor := domain.Cursor{Rank: 1, Time: now, ID: "sched-1"}
+	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "client_name", "service_name", "start_time", "end_time", "status", "location_label"}).
+		AddRow("sched-2", "cg-1", "Client A", "Service", now.Add(-time.Hour), now, "scheduled", "Location")
+
+	// The offset is superseded by the cursor.
+	mock.ExpectQuery("WHERE s\\.caregiver_id = \\$1 AND \\(CASE s\\.status[\\s\\S]+END > \\$2 OR \\(CASE s\\.status[\\s\\S]+END = \\$2 AND \\(s\\.start_time, s\\.id\\) < \\(\\$3, \\$4\\)\\)\\) ORDER BY CASE s\\.status[\\s\\S]+END, s\\.start_time DESC, s\\.id DESC LIMIT \\$5$").
+		WithArgs("cg-1", 1, now, "sched-1", 21).
+		WillReturnRows(rows)
+
+	summaries, err := repo.ListSchedules(context.Background(), "cg-1", repository.ScheduleFilter{After: &cursor, Limit: 21, Offset: 40})
+	if err != nil {
+		t.Fatalf("ListSchedules error: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].ID != "sched-2" {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduleRepositoryListSchedulesSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "pgx")
+	repo := NewScheduleRepository(sqlxDB)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	cursor := domain.Cursor{Time: from.Add(9 * time.Hour), ID: "sched-1"}
+	filter := repository.ScheduleFilter{
+		From:        &from,
+		To:          &to,
+		ClientID:    "8b1c6f6e-0a52-4a0e-9d53-0d1f0c7a9e11",
+		ServiceName: "Personal Care",
+		Search:      "50%_oak",
+		Sort:        repository.ScheduleSortStartAsc,
+		After:       &cursor,
+		Limit:       11,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "caregiver_id", "client_name", "service_name", "start_time", "end_time", "status", "location_label"}).
+		AddRow("sched-2", "cg-1", "Client A", "Personal Care", from.Add(10*time.Hour), from.Add(11*time.Hour), "scheduled", "50%_oak street")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`AND s.start_time >= $2 AND s.start_time < $3 AND s.client_id = $4 AND LOWER(s.service_name) = LOWER($5) AND (c.full_name ILIKE $6 OR s.location_label ILIKE $6) AND (s.start_time, s.id) > ($7, $8) ORDER BY s.start_time ASC, s.id ASC LIMIT $9`)).
+		WithArgs("cg-1", from, time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), filter.ClientID, "Personal Care", `%50\%\_oak%`, cursor.Time, cursor.ID, 11).
+		WillReturnRows(rows)
+
+	summaries, err := repo.ListSchedules(context.Background(), "cg-1", filter)
+	if err != nil {
+		t.Fatalf("ListSchedules error: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].ID != "sched-2" {
+		t.Fatalf("unexpected summaries: %+v", summaries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
 func TestScheduleRepositoryGetScheduleForCaregiver(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -117,3 +194,245 @@ func TestScheduleRepositoryLogClockIn(t *testing.T) {
 		t.Fatalf("unmet expectations: %v", err)
 	}
 }
+
+func TestScheduleRepositoryGetMetricsOvernightRule(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		rule   domain.OvernightRule
+		window string
+	}{
+		{domain.OvernightRuleSplit, "WHERE s.caregiver_id = $1 AND s.start_time < $3 AND (s.start_time >= $2 OR s.end_time > $2)"},
+		{domain.OvernightRuleStartDay, "WHERE s.caregiver_id = $1 AND s.start_time >= $2 AND s.start_time < $3"},
+	}
+
+	for _, tc := range cases {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock init: %v", err)
+		}
+
+		repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+		rows := sqlmock.NewRows([]string{"total", "scheduled", "in_progress", "completed", "cancelled", "missed", "carried_over"}).
+			AddRow(3, 1, 1, 1, 0, 0, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(tc.window)+"$").
+			WithArgs("cg-1", day, day.AddDate(0, 0, 1)).
+			WillReturnRows(rows)
+
+		metrics, err := repo.GetMetrics(context.Background(), "cg-1", day, tc.rule)
+		if err != nil {
+			t.Fatalf("%s: GetMetrics error: %v", tc.rule, err)
+		}
+		if metrics.Total != 3 || metrics.CarriedOver != 1 {
+			t.Fatalf("%s: unexpected metrics %+v", tc.rule, metrics)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("%s: unmet expectations: %v", tc.rule, err)
+		}
+		db.Close()
+	}
+}
+
+func TestScheduleRepositoryGetMetricsRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	week := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	columns := []string{"bucket", "is_total", "total", "scheduled", "in_progress", "completed", "cancelled", "missed",
+		"arrivals", "on_time", "on_time_rate", "avg_visit_minutes", "avg_planned_minutes", "duration_ratio",
+		"tasks", "tasks_completed", "task_completion_rate"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(week, 0, 4, 1, 0, 2, 1, 0, 2, 1, 0.5, 55.0, 60.0, 0.9167, 6, 4, 0.6667).
+		AddRow(nil, 1, 4, 1, 0, 2, 1, 0, 2, 1, 0.5, 55.0, 60.0, 0.9167, 6, 4, 0.6667)
+	mock.ExpectQuery(`date_trunc\(\$4, s\.start_time AT TIME ZONE \$5\).*GROUP BY GROUPING SETS \(\(bucket\), \(\)\)`).
+		WithArgs("cg-1", from, to, "week", "America/New_York", 300.0).
+		WillReturnRows(rows)
+
+	buckets, summary, err := repo.GetMetricsRange(context.Background(), "cg-1", repository.MetricsRangeQuery{
+		From:        from,
+		To:          to,
+		Granularity: domain.MetricsGranularityWeek,
+		TimeZone:    "America/New_York",
+		OnTimeGrace: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("GetMetricsRange error: %v", err)
+	}
+	if len(buckets) != 1 || !buckets[0].Start.Equal(week) || buckets[0].Metrics.Upcoming != 1 {
+		t.Fatalf("unexpected buckets %+v", buckets)
+	}
+	if summary.Total != 4 || summary.OnTimeRate == nil || *summary.OnTimeRate != 0.5 || summary.TasksCompleted != 4 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduleRepositoryListVisitIntervals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	rows := sqlmock.NewRows([]string{"id", "clock_in_at", "clock_out_at"}).
+		AddRow("sched-1", from.Add(9*time.Hour), from.Add(10*time.Hour)).
+		AddRow("sched-2", from.Add(11*time.Hour), nil)
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		SELECT id, clock_in_at, clock_out_at
+		FROM schedules
+		WHERE caregiver_id = $1
+		  AND clock_in_at IS NOT NULL
+		  AND clock_in_at < $3
+		  AND (clock_out_at IS NULL OR clock_out_at > $2)
+		ORDER BY clock_in_at ASC
+	`)).
+		WithArgs("cg-1", from, to).
+		WillReturnRows(rows)
+
+	visits, err := repo.ListVisitIntervals(context.Background(), "cg-1", from, to)
+	if err != nil {
+		t.Fatalf("ListVisitIntervals error: %v", err)
+	}
+	if len(visits) != 2 || visits[0].End == nil || visits[1].End != nil {
+		t.Fatalf("unexpected visits: %+v", visits)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduleRepositoryUpdateStatusWritesOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT caregiver_id, client_id, status FROM schedules WHERE id = $1 FOR UPDATE`)).
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "client_id", "status"}).AddRow("cg-1", "client-1", "in_progress"))
+	mock.ExpectExec(`UPDATE schedules\s+SET status = \$2`).
+		WithArgs("sched-1", domain.ScheduleStatusCompleted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventScheduleStatusChanged, "cg-1", "sched-1",
+			`{"client_id":"client-1","from":"in_progress","schedule_id":"sched-1","status":"completed"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.UpdateStatus(context.Background(), "sched-1", domain.ScheduleStatusCompleted); err != nil {
+		t.Fatalf("UpdateStatus error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduleRepositoryUpdateStatusUnchangedSkipsOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM schedules WHERE id = \$1 FOR UPDATE`).
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "client_id", "status"}).AddRow("cg-1", "client-1", "completed"))
+	mock.ExpectExec(`UPDATE schedules\s+SET status = \$2`).
+		WithArgs("sched-1", domain.ScheduleStatusCompleted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.UpdateStatus(context.Background(), "sched-1", domain.ScheduleStatusCompleted); err != nil {
+		t.Fatalf("UpdateStatus error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduleRepositoryCompleteVisitWritesAllInOneTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+	at := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	event := domain.VisitEvent{Timestamp: at, Latitude: 1.5, Longitude: 2.5}
+	signoff := domain.VisitSignoff{
+		ScheduleID:  "sched-1",
+		Method:      domain.SignoffMethodPIN,
+		SignerName:  "John Doe",
+		SignerRole:  domain.SignerRoleRepresentative,
+		Summary:     []byte(`{}`),
+		SummaryHash: "hash",
+		CapturedAt:  at,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM schedules WHERE id = \$1 FOR UPDATE`).
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "client_id", "status"}).AddRow("cg-1", "client-1", "in_progress"))
+	mock.ExpectExec(`UPDATE schedules\s+SET clock_out_at = \$2`).
+		WithArgs("sched-1", at, 1.5, 2.5, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO visit_signoffs`).
+		WithArgs("sched-1", domain.SignoffMethodPIN, "John Doe", domain.SignerRoleRepresentative, nil, sqlmock.AnyArg(), `{}`, "hash", at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE schedules\s+SET status = \$2`).
+		WithArgs("sched-1", domain.ScheduleStatusCompleted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventScheduleStatusChanged, "cg-1", "sched-1",
+			`{"client_id":"client-1","from":"in_progress","schedule_id":"sched-1","status":"completed"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := repo.CompleteVisit(context.Background(), "sched-1", event, &signoff); err != nil {
+		t.Fatalf("CompleteVisit error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestScheduleRepositoryCompleteVisitRequiresInProgress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewScheduleRepository(sqlx.NewDb(db, "pgx"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM schedules WHERE id = \$1 FOR UPDATE`).
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows([]string{"caregiver_id", "client_id", "status"}).AddRow("cg-1", "client-1", "completed"))
+	mock.ExpectRollback()
+
+	err = repo.CompleteVisit(context.Background(), "sched-1", domain.VisitEvent{Timestamp: time.Now()}, nil)
+	if err != domain.ErrInvalidStatusTransition {
+		t.Fatalf("expected invalid status transition, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// SignoffRepository implements repository.SignoffRepository.
+type SignoffRepository struct {
+	db *sqlx.DB
+}
+
+// NewSignoffRepository constructs the repository.
+func NewSignoffRepository(db *sqlx.DB) *SignoffRepository {
+	return &SignoffRepository{db: db}
+}
+
+type signoffSettingsRow struct {
+	ClientID  string         `db:"client_id"`
+	Required  bool           `db:"required"`
+	PINHash   sql.NullString `db:"pin_hash"`
+	PINSetAt  sql.NullTime   `db:"pin_set_at"`
+	UpdatedBy sql.NullString `db:"updated_by"`
+	UpdatedAt sql.NullTime   `db:"updated_at"`
+}
+
+func (r *SignoffRepository) GetSettings(ctx context.Context, clientID string) (domain.ClientSignoffSettings, error) {
+	var row signoffSettingsRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT c.id AS client_id,
+		       COALESCE(ss.required, FALSE) AS required,
+		       ss.pin_hash, ss.pin_set_at, ss.updated_by, ss.updated_at
+		FROM clients c
+		LEFT JOIN client_signoff_settings ss ON ss.client_id = c.id
+		WHERE c.id = $1
+	`, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ClientSignoffSettings{}, domain.ErrNotFound
+		}
+		return domain.ClientSignoffSettings{}, err
+	}
+	return domain.ClientSignoffSettings{
+		ClientID:  row.ClientID,
+		Required:  row.Required,
+		PINHash:   nullStringPtr(row.PINHash),
+		PINSetAt:  nullTimePtr(row.PINSetAt),
+		UpdatedBy: nullStringPtr(row.UpdatedBy),
+		UpdatedAt: nullTimePtr(row.UpdatedAt),
+	}, nil
+}
+
+func (r *SignoffRepository) SaveSettings(ctx context.Context, settings domain.ClientSignoffSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO client_signoff_settings (client_id, required, pin_hash, pin_set_at, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (client_id) DO UPDATE
+		SET required = EXCLUDED.required,
+		    pin_hash = EXCLUDED.pin_hash,
+		    pin_set_at = EXCLUDED.pin_set_at,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = EXCLUDED.updated_at
+	`, settings.ClientID, settings.Required, settings.PINHash, settings.PINSetAt, settings.UpdatedBy, settings.UpdatedAt)
+	return err
+}
+
+func (r *SignoffRepository) ReservePINAttempt(ctx context.Context, attempt domain.SignoffPINFailure, since time.Time, limit int) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Concurrent attempts on the client wait here, so each sees the ones
+	// before it and no more than limit PINs are ever checked.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, attempt.ClientID); err != nil {
+		return "", err
+	}
+	var count int
+	err = tx.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM signoff_pin_failures WHERE client_id = $1 AND attempted_at >= $2
+	`, attempt.ClientID, since)
+	if err != nil {
+		return "", err
+	}
+	if count >= limit {
+		return "", domain.ErrSignoffPINLocked
+	}
+	var id string
+	err = tx.GetContext(ctx, &id, `
+		INSERT INTO signoff_pin_failures (client_id, schedule_id, caregiver_id, attempted_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, attempt.ClientID, attempt.ScheduleID, nullable(attempt.CaregiverID), attempt.AttemptedAt)
+	if err != nil {
+		return "", err
+	}
+	return id, tx.Commit()
+}
+
+func (r *SignoffRepository) ClearPINAttempt(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM signoff_pin_failures WHERE id = $1`, id)
+	return err
+}
+
+func (r *SignoffRepository) Save(ctx context.Context, signoff domain.VisitSignoff) error {
+	return saveVisitSignoff(ctx, r.db, signoff)
+}
+
+// saveVisitSignoff stores the sign-off through db, which may be a transaction.
+func saveVisitSignoff(ctx context.Context, db sqlx.ExecerContext, signoff domain.VisitSignoff) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO visit_signoffs (schedule_id, method, signer_name, signer_role, signature_format, signature, summary, summary_hash, captured_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (schedule_id) DO UPDATE
+		SET method = EXCLUDED.method,
+		    signer_name = EXCLUDED.signer_name,
+		    signer_role = EXCLUDED.signer_role,
+		    signature_format = EXCLUDED.signature_format,
+		    signature = EXCLUDED.signature,
+		    summary = EXCLUDED.summary,
+		    summary_hash = EXCLUDED.summary_hash,
+		    captured_at = EXCLUDED.captured_at
+	`, signoff.ScheduleID, signoff.Method, signoff.SignerName, signoff.SignerRole,
+		nullable(string(signoff.SignatureFormat)), signoff.Signature, string(signoff.Summary), signoff.SummaryHash, signoff.CapturedAt)
+	return err
+}
+
+type visitSignoffRow struct {
+	ScheduleID      string         `db:"schedule_id"`
+	Method          string         `db:"method"`
+	SignerName      string         `db:"signer_name"`
+	SignerRole      string         `db:"signer_role"`
+	SignatureFormat sql.NullString `db:"signature_format"`
+	Signature       []byte         `db:"signature"`
+	Summary         string         `db:"summary"`
+	SummaryHash     string         `db:"summary_hash"`
+	CapturedAt      time.Time      `db:"captured_at"`
+}
+
+func (r *SignoffRepository) Get(ctx context.Context, scheduleID string) (domain.VisitSignoff, error) {
+	var row visitSignoffRow
+	err := r.db.GetContext(ctx, &row, `
+		SELECT schedule_id, method, signer_name, signer_role, signature_format, signature, summary, summary_hash, captured_at
+		FROM visit_signoffs
+		WHERE schedule_id = $1
+	`, scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.VisitSignoff{}, domain.ErrNotFound
+		}
+		return domain.VisitSignoff{}, err
+	}
+	return domain.VisitSignoff{
+		ScheduleID:      row.ScheduleID,
+		Method:          domain.SignoffMethod(row.Method),
+		SignerName:      row.SignerName,
+		SignerRole:      domain.SignerRole(row.SignerRole),
+		SignatureFormat: domain.SignatureFormat(row.SignatureFormat.String),
+		Signature:       row.Signature,
+		Summary:         []byte(row.Summary),
+		SummaryHash:     row.SummaryHash,
+		CapturedAt:      row.CapturedAt,
+	}, nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSignoffRepositoryGetSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSignoffRepository(sqlx.NewDb(db, "pgx"))
+	mock.ExpectQuery(`FROM clients c\s+LEFT JOIN client_signoff_settings ss ON ss.client_id = c.id\s+WHERE c.id = \$1`).
+		WithArgs("client-1").
+		WillReturnRows(sqlmock.NewRows([]string{"client_id", "required", "pin_hash", "pin_set_at", "updated_by", "updated_at"}).
+			AddRow("client-1", false, nil, nil, nil, nil))
+	mock.ExpectQuery(`FROM clients c`).
+		WithArgs("client-2").
+		WillReturnRows(sqlmock.NewRows([]string{"client_id"}))
+
+	settings, err := repo.GetSettings(context.Background(), "client-1")
+	if err != nil {
+		t.Fatalf("GetSettings error: %v", err)
+	}
+	if settings.Required || settings.PINHash != nil || settings.UpdatedAt != nil {
+		t.Fatalf("expected optional signoff without a pin by default, got %+v", settings)
+	}
+	if _, err := repo.GetSettings(context.Background(), "client-2"); err != domain.ErrNotFound {
+		t.Fatalf("expected not found for an unknown client, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSignoffRepositorySaveAndGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSignoffRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	pinSignoff := domain.VisitSignoff{
+		ScheduleID:  "sched-1",
+		Method:      domain.SignoffMethodPIN,
+		SignerName:  "Jane Doe",
+		SignerRole:  domain.SignerRoleClient,
+		Summary:     []byte(`{"schedule_id":"sched-1"}`),
+		SummaryHash: "abc",
+		CapturedAt:  now,
+	}
+
+	mock.ExpectExec(`INSERT INTO visit_signoffs .+ ON CONFLICT \(schedule_id\) DO UPDATE`).
+		WithArgs("sched-1", domain.SignoffMethodPIN, "Jane Doe", domain.SignerRoleClient, nil, []byte(nil), `{"schedule_id":"sched-1"}`, "abc", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM visit_signoffs\s+WHERE schedule_id = \$1`).
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "method", "signer_name", "signer_role", "signature_format", "signature", "summary", "summary_hash", "captured_at"}).
+			AddRow("sched-1", "signature", "Jane Doe", "client", "svg", []byte("M 1 1 L 2 2"), `{"schedule_id":"sched-1"}`, "abc", now))
+	mock.ExpectQuery(`FROM visit_signoffs`).
+		WithArgs("sched-2").
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id"}))
+
+	if err := repo.Save(context.Background(), pinSignoff); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	signoff, err := repo.Get(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if signoff.SignatureFormat != domain.SignatureFormatSVG || string(signoff.Signature) != "M 1 1 L 2 2" || string(signoff.Summary) != `{"schedule_id":"sched-1"}` {
+		t.Fatalf("unexpected signoff: %+v", signoff)
+	}
+	if _, err := repo.Get(context.Background(), "sched-2"); err != domain.ErrNotFound {
+		t.Fatalf("expected not found without a signoff, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSignoffRepositoryReservePINAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewSignoffRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	since := now.Add(-24 * time.Hour)
+	attempt := domain.SignoffPINFailure{ClientID: "client-1", ScheduleID: "sched-1", CaregiverID: "cg-1", AttemptedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+		WithArgs("client-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM signoff_pin_failures WHERE client_id = \$1 AND attempted_at >= \$2`).
+		WithArgs("client-1", since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	mock.ExpectQuery(`INSERT INTO signoff_pin_failures \(client_id, schedule_id, caregiver_id, attempted_at\)`).
+		WithArgs("client-1", "sched-1", "cg-1", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("attempt-1"))
+	mock.ExpectCommit()
+
+	id, err := repo.ReservePINAttempt(context.Background(), attempt, since, 5)
+	if err != nil {
+		t.Fatalf("ReservePINAttempt error: %v", err)
+	}
+	if id != "attempt-1" {
+		t.Fatalf("expected attempt-1, got %s", id)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock(hashtext($1))`)).
+		WithArgs("client-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM signoff_pin_failures`).
+		WithArgs("client-1", since).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectRollback()
+
+	if _, err := repo.ReservePINAttempt(context.Background(), attempt, since, 5); !errors.Is(err, domain.ErrSignoffPINLocked) {
+		t.Fatalf("expected the pin locked, got %v", err)
+	}
+
+	mock.ExpectExec(`DELETE FROM signoff_pin_failures WHERE id = \$1`).
+		WithArgs("attempt-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := repo.ClearPINAttempt(context.Background(), "attempt-1"); err != nil {
+		t.Fatalf("ClearPINAttempt error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
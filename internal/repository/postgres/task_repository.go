@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
@@ -28,6 +29,8 @@ type taskRow struct {
 	NotCompletedReason sql.NullString `db:"not_completed_reason"`
 	SortOrder          int32          `db:"sort_order"`
 	UpdatedAt          sql.NullTime   `db:"updated_at"`
+
+	MedicationAdministrationID sql.NullString `db:"medication_administration_id"`
 }
 
 // ListBySchedule returns all tasks belonging to the schedule ordered by sort order.
@@ -40,7 +43,8 @@ func (r *TaskRepository) ListBySchedule(ctx context.Context, scheduleID string)
 		       status,
 		       not_completed_reason,
 		       sort_order,
-		       updated_at
+		       updated_at,
+		       medication_administration_id
 		FROM schedule_tasks
 		WHERE schedule_id = $1
 		ORDER BY sort_order ASC, created_at ASC
@@ -75,6 +79,8 @@ func (r *TaskRepository) ListBySchedule(ctx context.Context, scheduleID string)
 			NotCompletedReason: reason,
 			SortOrder:          row.SortOrder,
 			UpdatedAt:          updatedAt,
+
+			MedicationAdministrationID: nullStringPtr(row.MedicationAdministrationID),
 		}
 	}
 	return result, nil
@@ -82,15 +88,46 @@ func (r *TaskRepository) ListBySchedule(ctx context.Context, scheduleID string)
 
 // UpdateTaskStatus changes the task status and optional reason.
 func (r *TaskRepository) UpdateTaskStatus(ctx context.Context, taskID string, status domain.TaskStatus, reason *string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
-		UPDATE schedule_tasks
+		UPDATE schedule_tasks t
 		SET status = $2,
 		    not_completed_reason = $3,
 		    updated_at = NOW()
-		WHERE id = $1
+		FROM schedules s
+		WHERE t.id = $1 AND s.id = t.schedule_id
+		RETURNING t.schedule_id, s.caregiver_id
 	`
-	_, err := r.db.ExecContext(ctx, query, taskID, status, reason)
-	return err
+	var owner struct {
+		ScheduleID  string         `db:"schedule_id"`
+		CaregiverID sql.NullString `db:"caregiver_id"`
+	}
+	if err := tx.GetContext(ctx, &owner, query, taskID, status, reason); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	data := map[string]interface{}{"task_id": taskID, "schedule_id": owner.ScheduleID, "status": status}
+	if reason != nil {
+		data["reason"] = *reason
+	}
+	err = writeOutbox(ctx, tx, outboxEntry{
+		Type:        domain.EventTaskUpdated,
+		CaregiverID: owner.CaregiverID.String,
+		ScheduleID:  owner.ScheduleID,
+		Data:        data,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // CreateTask inserts a new task record.
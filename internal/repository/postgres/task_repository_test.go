@@ -54,15 +54,22 @@ func TestTaskRepositoryUpdateTaskStatus(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "pgx")
 	repo := NewTaskRepository(sqlxDB)
 
-	mock.ExpectExec(regexp.QuoteMeta(`
-        UPDATE schedule_tasks
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`
+        UPDATE schedule_tasks t
         SET status = $2,
             not_completed_reason = $3,
             updated_at = NOW()
-        WHERE id = $1
+        FROM schedules s
+        WHERE t.id = $1 AND s.id = t.schedule_id
+        RETURNING t.schedule_id, s.caregiver_id
     `)).
 		WithArgs("task-1", domain.TaskStatusCompleted, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "caregiver_id"}).AddRow("sched-1", "cg-1"))
+	mock.ExpectExec(regexp.QuoteMeta(insertOutboxEvent)).
+		WithArgs(domain.EventTaskUpdated, "cg-1", "sched-1", `{"schedule_id":"sched-1","status":"completed","task_id":"task-1"}`).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	if err := repo.UpdateTaskStatus(context.Background(), "task-1", domain.TaskStatusCompleted, nil); err != nil {
 		t.Fatalf("UpdateTaskStatus error: %v", err)
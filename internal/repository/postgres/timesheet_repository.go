@@ -0,0 +1,300 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// TimesheetRepository implements repository.TimesheetRepository backed by Postgres.
+type TimesheetRepository struct {
+	db *sqlx.DB
+}
+
+// NewTimesheetRepository creates a new repository.
+func NewTimesheetRepository(db *sqlx.DB) *TimesheetRepository {
+	return &TimesheetRepository{db: db}
+}
+
+const timesheetColumns = `
+	id, caregiver_id, period, period_start, period_end, status,
+	worked_minutes, break_minutes, visit_minutes, regular_minutes, overtime_minutes, completed_visits,
+	submitted_at, reviewed_by, reviewed_at, review_comment, locked_by, locked_at
+`
+
+type timesheetRow struct {
+	ID              string         `db:"id"`
+	CaregiverID     string         `db:"caregiver_id"`
+	Period          string         `db:"period"`
+	PeriodStart     time.Time      `db:"period_start"`
+	PeriodEnd       time.Time      `db:"period_end"`
+	Status          string         `db:"status"`
+	WorkedMinutes   int            `db:"worked_minutes"`
+	BreakMinutes    int            `db:"break_minutes"`
+	VisitMinutes    int            `db:"visit_minutes"`
+	RegularMinutes  int            `db:"regular_minutes"`
+	OvertimeMinutes int            `db:"overtime_minutes"`
+	CompletedVisits int            `db:"completed_visits"`
+	SubmittedAt     time.Time      `db:"submitted_at"`
+	ReviewedBy      sql.NullString `db:"reviewed_by"`
+	ReviewedAt      sql.NullTime   `db:"reviewed_at"`
+	ReviewComment   sql.NullString `db:"review_comment"`
+	LockedBy        sql.NullString `db:"locked_by"`
+	LockedAt        sql.NullTime   `db:"locked_at"`
+}
+
+type timesheetDayRow struct {
+	WorkDate        time.Time `db:"work_date"`
+	WorkedMinutes   int       `db:"worked_minutes"`
+	BreakMinutes    int       `db:"break_minutes"`
+	VisitMinutes    int       `db:"visit_minutes"`
+	RegularMinutes  int       `db:"regular_minutes"`
+	OvertimeMinutes int       `db:"overtime_minutes"`
+	CompletedVisits int       `db:"completed_visits"`
+}
+
+func (r *TimesheetRepository) SaveSubmission(ctx context.Context, timesheet domain.Timesheet) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Only a rejected timesheet may be replaced; otherwise the upsert matches no
+	// row and the period is reported as a conflict.
+	var id string
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO timesheets (
+			caregiver_id, period, period_start, period_end, status,
+			worked_minutes, break_minutes, visit_minutes, regular_minutes, overtime_minutes, completed_visits,
+			submitted_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, 'submitted', $5, $6, $7, $8, $9, $10, $11, $11, $11)
+		ON CONFLICT (caregiver_id, period_start) DO UPDATE SET
+			period = EXCLUDED.period,
+			period_end = EXCLUDED.period_end,
+			status = 'submitted',
+			worked_minutes = EXCLUDED.worked_minutes,
+			break_minutes = EXCLUDED.break_minutes,
+			visit_minutes = EXCLUDED.visit_minutes,
+			regular_minutes = EXCLUDED.regular_minutes,
+			overtime_minutes = EXCLUDED.overtime_minutes,
+			completed_visits = EXCLUDED.completed_visits,
+			submitted_at = EXCLUDED.submitted_at,
+			reviewed_by = NULL,
+			reviewed_at = NULL,
+			review_comment = NULL,
+			updated_at = EXCLUDED.updated_at
+		WHERE timesheets.status = 'rejected'
+		RETURNING id
+	`, timesheet.CaregiverID, timesheet.Period, timesheet.PeriodStart, timesheet.PeriodEnd,
+		timesheet.WorkedMinutes, timesheet.BreakMinutes, timesheet.VisitMinutes,
+		timesheet.RegularMinutes, timesheet.OvertimeMinutes, timesheet.CompletedVisits,
+		timesheet.SubmittedAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrConflict
+		}
+		return "", err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM timesheet_days WHERE timesheet_id = $1`, id); err != nil {
+		return "", err
+	}
+	for _, day := range timesheet.Days {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO timesheet_days (
+				timesheet_id, work_date, worked_minutes, break_minutes, visit_minutes,
+				regular_minutes, overtime_minutes, completed_visits
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, id, day.Date.Format("2006-01-02"), day.WorkedMinutes, day.BreakMinutes, day.VisitMinutes,
+			day.RegularMinutes, day.OvertimeMinutes, day.CompletedVisits); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *TimesheetRepository) GetTimesheet(ctx context.Context, timesheetID string) (domain.Timesheet, error) {
+	return r.getWithDays(ctx, `SELECT `+timesheetColumns+` FROM timesheets WHERE id = $1`, timesheetID)
+}
+
+func (r *TimesheetRepository) FindByPeriod(ctx context.Context, caregiverID string, periodStart time.Time) (domain.Timesheet, error) {
+	return r.getWithDays(ctx, `SELECT `+timesheetColumns+` FROM timesheets WHERE caregiver_id = $1 AND period_start = $2`, caregiverID, periodStart)
+}
+
+func (r *TimesheetRepository) ListByCaregiver(ctx context.Context, caregiverID string) ([]domain.Timesheet, error) {
+	var rows []timesheetRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+timesheetColumns+`
+		FROM timesheets
+		WHERE caregiver_id = $1
+		ORDER BY period_start DESC
+	`, caregiverID)
+	if err != nil {
+		return nil, err
+	}
+	return mapTimesheets(rows), nil
+}
+
+func (r *TimesheetRepository) ListByStatus(ctx context.Context, statuses []domain.TimesheetStatus) ([]domain.Timesheet, error) {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+
+	var rows []timesheetRow
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+timesheetColumns+`
+		FROM timesheets
+		WHERE status = ANY($1)
+		ORDER BY period_start ASC, submitted_at ASC
+	`, pq.Array(values))
+	if err != nil {
+		return nil, err
+	}
+	return mapTimesheets(rows), nil
+}
+
+func (r *TimesheetRepository) Approve(ctx context.Context, timesheetID, reviewerID string, comment *string, at time.Time) error {
+	return r.transition(ctx, `
+		UPDATE timesheets
+		SET status = 'approved',
+		    reviewed_by = $2,
+		    reviewed_at = $3,
+		    review_comment = $4,
+		    updated_at = $3
+		WHERE id = $1 AND status = 'submitted'
+	`, timesheetID, reviewerID, at, comment)
+}
+
+func (r *TimesheetRepository) Reject(ctx context.Context, timesheetID, reviewerID string, comment *string, at time.Time) error {
+	return r.transition(ctx, `
+		UPDATE timesheets
+		SET status = 'rejected',
+		    reviewed_by = $2,
+		    reviewed_at = $3,
+		    review_comment = $4,
+		    updated_at = $3
+		WHERE id = $1 AND status = 'submitted'
+	`, timesheetID, reviewerID, at, comment)
+}
+
+func (r *TimesheetRepository) Lock(ctx context.Context, timesheetID, actorID string, at time.Time) error {
+	return r.transition(ctx, `
+		UPDATE timesheets
+		SET status = 'locked',
+		    locked_by = $2,
+		    locked_at = $3,
+		    updated_at = $3
+		WHERE id = $1 AND status = 'approved'
+	`, timesheetID, actorID, at)
+}
+
+func (r *TimesheetRepository) IsLocked(ctx context.Context, caregiverID string, at time.Time) (bool, error) {
+	var locked bool
+	err := r.db.GetContext(ctx, &locked, `
+		SELECT EXISTS(
+			SELECT 1 FROM timesheets
+			WHERE caregiver_id = $1 AND status = 'locked'
+			AND period_start <= $2 AND period_end > $2
+		)
+	`, caregiverID, at)
+	if err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+func (r *TimesheetRepository) transition(ctx context.Context, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func (r *TimesheetRepository) getWithDays(ctx context.Context, query string, args ...interface{}) (domain.Timesheet, error) {
+	var row timesheetRow
+	if err := r.db.GetContext(ctx, &row, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Timesheet{}, domain.ErrNotFound
+		}
+		return domain.Timesheet{}, err
+	}
+	timesheet := mapTimesheet(row)
+
+	var days []timesheetDayRow
+	err := r.db.SelectContext(ctx, &days, `
+		SELECT work_date, worked_minutes, break_minutes, visit_minutes,
+		       regular_minutes, overtime_minutes, completed_visits
+		FROM timesheet_days
+		WHERE timesheet_id = $1
+		ORDER BY work_date ASC
+	`, row.ID)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	timesheet.Days = make([]domain.TimesheetDay, len(days))
+	for i, day := range days {
+		timesheet.Days[i] = domain.TimesheetDay{
+			Date:            day.WorkDate,
+			WorkedMinutes:   day.WorkedMinutes,
+			BreakMinutes:    day.BreakMinutes,
+			VisitMinutes:    day.VisitMinutes,
+			RegularMinutes:  day.RegularMinutes,
+			OvertimeMinutes: day.OvertimeMinutes,
+			CompletedVisits: day.CompletedVisits,
+		}
+	}
+	return timesheet, nil
+}
+
+func mapTimesheets(rows []timesheetRow) []domain.Timesheet {
+	result := make([]domain.Timesheet, len(rows))
+	for i, row := range rows {
+		result[i] = mapTimesheet(row)
+	}
+	return result
+}
+
+func mapTimesheet(row timesheetRow) domain.Timesheet {
+	submittedAt := row.SubmittedAt
+	return domain.Timesheet{
+		ID:              row.ID,
+		CaregiverID:     row.CaregiverID,
+		Period:          domain.TimesheetPeriod(row.Period),
+		PeriodStart:     row.PeriodStart,
+		PeriodEnd:       row.PeriodEnd,
+		Status:          domain.TimesheetStatus(row.Status),
+		WorkedMinutes:   row.WorkedMinutes,
+		BreakMinutes:    row.BreakMinutes,
+		VisitMinutes:    row.VisitMinutes,
+		RegularMinutes:  row.RegularMinutes,
+		OvertimeMinutes: row.OvertimeMinutes,
+		CompletedVisits: row.CompletedVisits,
+		SubmittedAt:     &submittedAt,
+		ReviewedBy:      nullStringPtr(row.ReviewedBy),
+		ReviewedAt:      nullTimePtr(row.ReviewedAt),
+		ReviewComment:   nullStringPtr(row.ReviewComment),
+		LockedBy:        nullStringPtr(row.LockedBy),
+		LockedAt:        nullTimePtr(row.LockedAt),
+	}
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTimesheetRepositorySaveSubmissionReplacesDays(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTimesheetRepository(sqlx.NewDb(db, "pgx"))
+	start := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	submittedAt := start.AddDate(0, 0, 8)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO timesheets[\\s\\S]+ON CONFLICT \\(caregiver_id, period_start\\) DO UPDATE[\\s\\S]+WHERE timesheets.status = 'rejected'").
+		WithArgs("cg-1", domain.TimesheetPeriodWeekly, start, start.AddDate(0, 0, 7), 570, 30, 120, 480, 90, 1, &submittedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("ts-1"))
+	mock.ExpectExec("DELETE FROM timesheet_days WHERE timesheet_id = \\$1").
+		WithArgs("ts-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO timesheet_days").
+		WithArgs("ts-1", "2025-01-13", 570, 30, 120, 480, 90, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	id, err := repo.SaveSubmission(context.Background(), domain.Timesheet{
+		CaregiverID: "cg-1",
+		Period:      domain.TimesheetPeriodWeekly,
+		PeriodStart: start,
+		PeriodEnd:   start.AddDate(0, 0, 7),
+		Days: []domain.TimesheetDay{
+			{Date: start, WorkedMinutes: 570, BreakMinutes: 30, VisitMinutes: 120, RegularMinutes: 480, OvertimeMinutes: 90, CompletedVisits: 1},
+		},
+		WorkedMinutes:   570,
+		BreakMinutes:    30,
+		VisitMinutes:    120,
+		RegularMinutes:  480,
+		OvertimeMinutes: 90,
+		CompletedVisits: 1,
+		SubmittedAt:     &submittedAt,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "ts-1" {
+		t.Fatalf("unexpected id %q", id)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTimesheetRepositorySaveSubmissionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTimesheetRepository(sqlx.NewDb(db, "pgx"))
+	start := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO timesheets").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err = repo.SaveSubmission(context.Background(), domain.Timesheet{
+		CaregiverID: "cg-1",
+		Period:      domain.TimesheetPeriodWeekly,
+		PeriodStart: start,
+		PeriodEnd:   start.AddDate(0, 0, 7),
+	})
+	if err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTimesheetRepositoryLockRequiresApproval(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTimesheetRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE timesheets[\\s\\S]+SET status = 'locked'[\\s\\S]+WHERE id = \\$1 AND status = 'approved'").
+		WithArgs("ts-1", "coordinator-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := repo.Lock(context.Background(), "ts-1", "coordinator-1", now); err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestTimesheetRepositoryGetTimesheetWithDays(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewTimesheetRepository(sqlx.NewDb(db, "pgx"))
+	start := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	lockedAt := start.AddDate(0, 0, 10)
+
+	mock.ExpectQuery("SELECT[\\s\\S]+FROM timesheets WHERE id = \\$1").
+		WithArgs("ts-1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "caregiver_id", "period", "period_start", "period_end", "status",
+			"worked_minutes", "break_minutes", "visit_minutes", "regular_minutes", "overtime_minutes", "completed_visits",
+			"submitted_at", "reviewed_by", "reviewed_at", "review_comment", "locked_by", "locked_at",
+		}).AddRow(
+			"ts-1", "cg-1", "weekly", start, start.AddDate(0, 0, 7), "locked",
+			570, 30, 120, 480, 90, 1,
+			start.AddDate(0, 0, 8), "coordinator-1", start.AddDate(0, 0, 9), nil, "coordinator-1", lockedAt,
+		))
+	mock.ExpectQuery("SELECT work_date[\\s\\S]+FROM timesheet_days[\\s\\S]+ORDER BY work_date ASC").
+		WithArgs("ts-1").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"work_date", "worked_minutes", "break_minutes", "visit_minutes", "regular_minutes", "overtime_minutes", "completed_visits",
+		}).AddRow(start, 570, 30, 120, 480, 90, 1))
+
+	timesheet, err := repo.GetTimesheet(context.Background(), "ts-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timesheet.Status != domain.TimesheetStatusLocked || timesheet.LockedAt == nil || !timesheet.LockedAt.Equal(lockedAt) {
+		t.Fatalf("unexpected timesheet: %+v", timesheet)
+	}
+	if timesheet.ReviewComment != nil || len(timesheet.Days) != 1 || timesheet.Days[0].OvertimeMinutes != 90 {
+		t.Fatalf("unexpected timesheet details: %+v", timesheet)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
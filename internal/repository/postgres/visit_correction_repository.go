@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// VisitCorrectionRepository implements repository.VisitCorrectionRepository backed by Postgres.
+type VisitCorrectionRepository struct {
+	db *sqlx.DB
+}
+
+// NewVisitCorrectionRepository creates a new repository.
+func NewVisitCorrectionRepository(db *sqlx.DB) *VisitCorrectionRepository {
+	return &VisitCorrectionRepository{db: db}
+}
+
+const visitCorrectionColumns = `
+	id, schedule_id, requested_by, reason_code, comment,
+	proposed_clock_in_at, proposed_clock_out_at, status,
+	original_clock_in_at, original_clock_out_at, original_status,
+	reviewed_by, reviewed_at, review_comment, created_at
+`
+
+type visitCorrectionRow struct {
+	ID                 string         `db:"id"`
+	ScheduleID         string         `db:"schedule_id"`
+	RequestedBy        string         `db:"requested_by"`
+	ReasonCode         string         `db:"reason_code"`
+	Comment            sql.NullString `db:"comment"`
+	ProposedClockInAt  sql.NullTime   `db:"proposed_clock_in_at"`
+	ProposedClockOutAt sql.NullTime   `db:"proposed_clock_out_at"`
+	Status             string         `db:"status"`
+	OriginalClockInAt  sql.NullTime   `db:"original_clock_in_at"`
+	OriginalClockOutAt sql.NullTime   `db:"original_clock_out_at"`
+	OriginalStatus     sql.NullString `db:"original_status"`
+	ReviewedBy         sql.NullString `db:"reviewed_by"`
+	ReviewedAt         sql.NullTime   `db:"reviewed_at"`
+	ReviewComment      sql.NullString `db:"review_comment"`
+	CreatedAt          time.Time      `db:"created_at"`
+}
+
+func (r *VisitCorrectionRepository) CreateCorrection(ctx context.Context, correction domain.VisitCorrection) (string, error) {
+	// The NOT EXISTS guard turns a second pending correction into zero rows
+	// rather than a unique violation; the partial index backs it up under races.
+	var id string
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO visit_corrections (schedule_id, requested_by, reason_code, comment, proposed_clock_in_at, proposed_clock_out_at, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE NOT EXISTS (
+			SELECT 1 FROM visit_corrections WHERE schedule_id = $1 AND status = 'pending'
+		)
+		RETURNING id
+	`, correction.ScheduleID, correction.RequestedBy, correction.Reason, correction.Comment,
+		correction.ProposedClockInAt, correction.ProposedClockOutAt, correction.CreatedAt).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrConflict
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *VisitCorrectionRepository) GetCorrection(ctx context.Context, correctionID string) (domain.VisitCorrection, error) {
+	var row visitCorrectionRow
+	err := r.db.GetContext(ctx, &row, `SELECT `+visitCorrectionColumns+` FROM visit_corrections WHERE id = $1`, correctionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.VisitCorrection{}, domain.ErrNotFound
+		}
+		return domain.VisitCorrection{}, err
+	}
+	return mapVisitCorrection(row), nil
+}
+
+func (r *VisitCorrectionRepository) ListBySchedule(ctx context.Context, scheduleID string) ([]domain.VisitCorrection, error) {
+	rows := []visitCorrectionRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+visitCorrectionColumns+`
+		FROM visit_corrections
+		WHERE schedule_id = $1
+		ORDER BY created_at ASC
+	`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	return mapVisitCorrections(rows), nil
+}
+
+func (r *VisitCorrectionRepository) ListPending(ctx context.Context) ([]domain.VisitCorrection, error) {
+	rows := []visitCorrectionRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+visitCorrectionColumns+`
+		FROM visit_corrections
+		WHERE status = 'pending'
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return mapVisitCorrections(rows), nil
+}
+
+func (r *VisitCorrectionRepository) ApproveCorrection(ctx context.Context, approval domain.CorrectionApproval) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var scheduleID string
+	err = tx.QueryRowxContext(ctx, `
+		SELECT schedule_id FROM visit_corrections WHERE id = $1 AND status = 'pending' FOR UPDATE
+	`, approval.CorrectionID).Scan(&scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+
+	var original struct {
+		ClockInAt  sql.NullTime `db:"clock_in_at"`
+		ClockOutAt sql.NullTime `db:"clock_out_at"`
+		Status     string       `db:"status"`
+	}
+	err = tx.GetContext(ctx, &original, `
+		SELECT clock_in_at, clock_out_at, status FROM schedules WHERE id = $1 FOR UPDATE
+	`, scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE schedules
+		SET clock_in_at = $2,
+		    clock_out_at = $3,
+		    status = $4,
+		    manually_edited = TRUE,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, scheduleID, approval.ClockInAt, approval.ClockOutAt, approval.Status)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE visit_corrections
+		SET status = 'approved',
+		    original_clock_in_at = $2,
+		    original_clock_out_at = $3,
+		    original_status = $4,
+		    reviewed_by = $5,
+		    reviewed_at = $6,
+		    review_comment = $7
+		WHERE id = $1
+	`, approval.CorrectionID, original.ClockInAt, original.ClockOutAt, original.Status,
+		approval.ReviewerID, approval.ReviewedAt, approval.Comment)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *VisitCorrectionRepository) RejectCorrection(ctx context.Context, correctionID, reviewerID string, comment *string, reviewedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE visit_corrections
+		SET status = 'rejected',
+		    reviewed_by = $2,
+		    reviewed_at = $3,
+		    review_comment = $4
+		WHERE id = $1 AND status = 'pending'
+	`, correctionID, reviewerID, reviewedAt, comment)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+func mapVisitCorrections(rows []visitCorrectionRow) []domain.VisitCorrection {
+	result := make([]domain.VisitCorrection, len(rows))
+	for i, row := range rows {
+		result[i] = mapVisitCorrection(row)
+	}
+	return result
+}
+
+func mapVisitCorrection(row visitCorrectionRow) domain.VisitCorrection {
+	var originalStatus *domain.ScheduleStatus
+	if row.OriginalStatus.Valid {
+		s := domain.ScheduleStatus(row.OriginalStatus.String)
+		originalStatus = &s
+	}
+	return domain.VisitCorrection{
+		ID:                 row.ID,
+		ScheduleID:         row.ScheduleID,
+		RequestedBy:        row.RequestedBy,
+		Reason:             domain.CorrectionReason(row.ReasonCode),
+		Comment:            nullStringPtr(row.Comment),
+		ProposedClockInAt:  nullTimePtr(row.ProposedClockInAt),
+		ProposedClockOutAt: nullTimePtr(row.ProposedClockOutAt),
+		Status:             domain.CorrectionStatus(row.Status),
+		OriginalClockInAt:  nullTimePtr(row.OriginalClockInAt),
+		OriginalClockOutAt: nullTimePtr(row.OriginalClockOutAt),
+		OriginalStatus:     originalStatus,
+		ReviewedBy:         nullStringPtr(row.ReviewedBy),
+		ReviewedAt:         nullTimePtr(row.ReviewedAt),
+		ReviewComment:      nullStringPtr(row.ReviewComment),
+		CreatedAt:          row.CreatedAt,
+	}
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestVisitCorrectionRepositoryCreateConflictsWhenPendingExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewVisitCorrectionRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Now()
+	clockOut := now.Add(-time.Hour)
+
+	mock.ExpectQuery("INSERT INTO visit_corrections[\\s\\S]+WHERE NOT EXISTS").
+		WithArgs("sched-1", "cg-1", domain.CorrectionReasonForgotClockOut, nil, nil, &clockOut, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = repo.CreateCorrection(context.Background(), domain.VisitCorrection{
+		ScheduleID:         "sched-1",
+		RequestedBy:        "cg-1",
+		Reason:             domain.CorrectionReasonForgotClockOut,
+		ProposedClockOutAt: &clockOut,
+		CreatedAt:          now,
+	})
+	if err != domain.ErrConflict {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestVisitCorrectionRepositoryApproveSnapshotsOriginals(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewVisitCorrectionRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Now()
+	clockIn := now.Add(-3 * time.Hour)
+	clockOut := now.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT schedule_id FROM visit_corrections WHERE id = \\$1 AND status = 'pending' FOR UPDATE").
+		WithArgs("corr-1").
+		WillReturnRows(sqlmock.NewRows([]string{"schedule_id"}).AddRow("sched-1"))
+	mock.ExpectQuery("SELECT clock_in_at, clock_out_at, status FROM schedules WHERE id = \\$1 FOR UPDATE").
+		WithArgs("sched-1").
+		WillReturnRows(sqlmock.NewRows([]string{"clock_in_at", "clock_out_at", "status"}).AddRow(clockIn, nil, "in_progress"))
+	mock.ExpectExec("UPDATE schedules[\\s\\S]+manually_edited = TRUE").
+		WithArgs("sched-1", &clockIn, &clockOut, domain.ScheduleStatusCompleted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE visit_corrections[\\s\\S]+status = 'approved'").
+		WithArgs("corr-1", sqlmock.AnyArg(), sqlmock.AnyArg(), "in_progress", "supervisor-1", now, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.ApproveCorrection(context.Background(), domain.CorrectionApproval{
+		CorrectionID: "corr-1",
+		ReviewerID:   "supervisor-1",
+		ReviewedAt:   now,
+		ClockInAt:    &clockIn,
+		ClockOutAt:   &clockOut,
+		Status:       domain.ScheduleStatusCompleted,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// WebhookRepository implements repository.WebhookRepository.
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookRepository constructs the repository.
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookSubscriptionSelect = `
+	SELECT id, url, description, secret, event_types, active, created_at, updated_at
+	FROM webhook_subscriptions
+`
+
+type webhookSubscriptionRow struct {
+	ID          string         `db:"id"`
+	URL         string         `db:"url"`
+	Description string         `db:"description"`
+	Secret      string         `db:"secret"`
+	EventTypes  pq.StringArray `db:"event_types"`
+	Active      bool           `db:"active"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	var id string
+	err := r.db.QueryRowxContext(ctx, `
+		INSERT INTO webhook_subscriptions (url, description, secret, event_types, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, subscription.URL, subscription.Description, subscription.Secret, pq.Array(eventTypeStrings(subscription.EventTypes)), subscription.Active).Scan(&id)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return r.GetSubscription(ctx, id)
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, subscriptionID string) (domain.WebhookSubscription, error) {
+	var row webhookSubscriptionRow
+	if err := r.db.GetContext(ctx, &row, webhookSubscriptionSelect+` WHERE id = $1`, subscriptionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WebhookSubscription{}, domain.ErrNotFound
+		}
+		return domain.WebhookSubscription{}, err
+	}
+	return mapWebhookSubscription(row), nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows := []webhookSubscriptionRow{}
+	if err := r.db.SelectContext(ctx, &rows, webhookSubscriptionSelect+` ORDER BY created_at ASC, id ASC`); err != nil {
+		return nil, err
+	}
+	subscriptions := make([]domain.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		subscriptions = append(subscriptions, mapWebhookSubscription(row))
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, subscription domain.WebhookSubscription) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2,
+		    description = $3,
+		    event_types = $4,
+		    active = $5,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, subscription.ID, subscription.URL, subscription.Description, pq.Array(eventTypeStrings(subscription.EventTypes)), subscription.Active)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) QueueOutbox(ctx context.Context, limit int, now time.Time) (int, error) {
+	// SKIP LOCKED lets every replica run the dispatcher without taking the
+	// same events twice.
+	res, err := r.db.ExecContext(ctx, `
+		WITH batch AS (
+			SELECT id, event_type
+			FROM outbox_events
+			WHERE dispatched_at IS NULL
+			ORDER BY occurred_at ASC, id ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), queued AS (
+			INSERT INTO webhook_deliveries (subscription_id, event_id, status, next_attempt_at, created_at, updated_at)
+			SELECT ws.id, b.id, 'pending', $2, $2, $2
+			FROM batch b
+			INNER JOIN webhook_subscriptions ws
+			        ON ws.active AND (cardinality(ws.event_types) = 0 OR b.event_type = ANY(ws.event_types))
+			ON CONFLICT (subscription_id, event_id) DO NOTHING
+		)
+		UPDATE outbox_events o
+		SET dispatched_at = $2
+		FROM batch b
+		WHERE o.id = b.id
+	`, limit, now)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
+
+type webhookMessageRow struct {
+	DeliveryID  string         `db:"delivery_id"`
+	Attempts    int            `db:"attempts"`
+	URL         string         `db:"url"`
+	Secret      string         `db:"secret"`
+	EventID     string         `db:"event_id"`
+	EventType   string         `db:"event_type"`
+	CaregiverID sql.NullString `db:"caregiver_id"`
+	ScheduleID  sql.NullString `db:"schedule_id"`
+	Payload     []byte         `db:"payload"`
+	OccurredAt  time.Time      `db:"occurred_at"`
+}
+
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookMessage, error) {
+	rows := []webhookMessageRow{}
+	err := r.db.SelectContext(ctx, &rows, `
+		WITH due AS (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at ASC, id ASC
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE webhook_deliveries d
+			SET next_attempt_at = $3
+			FROM due
+			WHERE d.id = due.id
+			RETURNING d.id, d.subscription_id, d.event_id, d.attempts
+		)
+		SELECT c.id AS delivery_id, c.attempts, ws.url, ws.secret,
+		       o.id AS event_id, o.event_type, o.caregiver_id, o.schedule_id, o.payload::text AS payload, o.occurred_at
+		FROM claimed c
+		INNER JOIN webhook_subscriptions ws ON ws.id = c.subscription_id
+		INNER JOIN outbox_events o ON o.id = c.event_id
+		ORDER BY o.occurred_at ASC, c.id ASC
+	`, now, limit, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]domain.WebhookMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, domain.WebhookMessage{
+			DeliveryID: row.DeliveryID,
+			Attempts:   row.Attempts,
+			URL:        row.URL,
+			Secret:     row.Secret,
+			Event: domain.OutboxEvent{
+				ID:          row.EventID,
+				Type:        domain.EventType(row.EventType),
+				CaregiverID: row.CaregiverID.String,
+				ScheduleID:  nullStringPtr(row.ScheduleID),
+				Payload:     row.Payload,
+				OccurredAt:  row.OccurredAt,
+			},
+		})
+	}
+	return messages, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, deliveryID string, responseStatus int, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered',
+		    attempts = attempts + 1,
+		    response_status = $2,
+		    last_error = NULL,
+		    next_attempt_at = NULL,
+		    delivered_at = $3,
+		    updated_at = $3
+		WHERE id = $1
+	`, deliveryID, responseStatus, at)
+	return err
+}
+
+func (r *WebhookRepository) MarkFailed(ctx context.Context, deliveryID string, responseStatus *int, reason string, retryAt *time.Time, at time.Time) error {
+	status := domain.WebhookDeliveryPending
+	if retryAt == nil {
+		status = domain.WebhookDeliveryDead
+	}
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = attempts + 1,
+		    response_status = $3,
+		    last_error = $4,
+		    next_attempt_at = $5,
+		    updated_at = $6
+		WHERE id = $1
+	`, deliveryID, status, responseStatus, reason, retryAt, at)
+	return err
+}
+
+const webhookDeliverySelect = `
+	SELECT d.id, d.subscription_id, d.event_id, o.event_type, d.status, d.attempts, d.next_attempt_at,
+	       d.last_error, d.response_status, d.delivered_at, d.created_at
+	FROM webhook_deliveries d
+	INNER JOIN outbox_events o ON o.id = d.event_id
+`
+
+type webhookDeliveryRow struct {
+	ID             string         `db:"id"`
+	SubscriptionID string         `db:"subscription_id"`
+	EventID        string         `db:"event_id"`
+	EventType      string         `db:"event_type"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  sql.NullTime   `db:"next_attempt_at"`
+	LastError      sql.NullString `db:"last_error"`
+	ResponseStatus sql.NullInt64  `db:"response_status"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	conds := []string{}
+	args := []interface{}{}
+	if filter.SubscriptionID != "" {
+		args = append(args, filter.SubscriptionID)
+		conds = append(conds, "d.subscription_id = $"+itoa(len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, "d.status = $"+itoa(len(args)))
+	}
+	query := webhookDeliverySelect
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += ` ORDER BY d.created_at DESC, d.id DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += ` LIMIT $` + itoa(len(args))
+	}
+
+	rows := []webhookDeliveryRow{}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	deliveries := make([]domain.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, mapWebhookDelivery(row))
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, deliveryID string) (domain.WebhookDelivery, error) {
+	var row webhookDeliveryRow
+	if err := r.db.GetContext(ctx, &row, webhookDeliverySelect+` WHERE d.id = $1`, deliveryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.WebhookDelivery{}, domain.ErrNotFound
+		}
+		return domain.WebhookDelivery{}, err
+	}
+	return mapWebhookDelivery(row), nil
+}
+
+func (r *WebhookRepository) Replay(ctx context.Context, deliveryID string, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending',
+		    attempts = 0,
+		    next_attempt_at = $2,
+		    updated_at = $2
+		WHERE id = $1
+	`, deliveryID, now)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ReplayDead(ctx context.Context, subscriptionID string, now time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending',
+		    attempts = 0,
+		    next_attempt_at = $2,
+		    updated_at = $2
+		WHERE subscription_id = $1 AND status = 'dead'
+	`, subscriptionID, now)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
+
+func mapWebhookSubscription(row webhookSubscriptionRow) domain.WebhookSubscription {
+	types := make([]domain.EventType, 0, len(row.EventTypes))
+	for _, t := range row.EventTypes {
+		types = append(types, domain.EventType(t))
+	}
+	return domain.WebhookSubscription{
+		ID:          row.ID,
+		URL:         row.URL,
+		Description: row.Description,
+		Secret:      row.Secret,
+		EventTypes:  types,
+		Active:      row.Active,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func mapWebhookDelivery(row webhookDeliveryRow) domain.WebhookDelivery {
+	var responseStatus *int
+	if row.ResponseStatus.Valid {
+		status := int(row.ResponseStatus.Int64)
+		responseStatus = &status
+	}
+	return domain.WebhookDelivery{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		EventID:        row.EventID,
+		EventType:      domain.EventType(row.EventType),
+		Status:         domain.WebhookDeliveryStatus(row.Status),
+		Attempts:       row.Attempts,
+		NextAttemptAt:  nullTimePtr(row.NextAttemptAt),
+		LastError:      nullStringPtr(row.LastError),
+		ResponseStatus: responseStatus,
+		DeliveredAt:    nullTimePtr(row.DeliveredAt),
+		CreatedAt:      row.CreatedAt,
+	}
+}
+
+func eventTypeStrings(types []domain.EventType) []string {
+	out := make([]string, 0, len(types))
+	for _, t := range types {
+		out = append(out, string(t))
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWebhookRepositoryQueueOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	mock.ExpectExec(`WHERE dispatched_at IS NULL[\s\S]+FOR UPDATE SKIP LOCKED[\s\S]+INSERT INTO webhook_deliveries[\s\S]+ON CONFLICT \(subscription_id, event_id\) DO NOTHING[\s\S]+UPDATE outbox_events o\s+SET dispatched_at = \$2`).
+		WithArgs(100, now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	queued, err := repo.QueueOutbox(context.Background(), 100, now)
+	if err != nil {
+		t.Fatalf("QueueOutbox error: %v", err)
+	}
+	if queued != 3 {
+		t.Fatalf("expected 3 events queued, got %d", queued)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookRepositoryClaimDue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	occurred := now.Add(-time.Minute)
+	rows := sqlmock.NewRows([]string{"delivery_id", "attempts", "url", "secret", "event_id", "event_type", "caregiver_id", "schedule_id", "payload", "occurred_at"}).
+		AddRow("delivery-1", 2, "https://billing.example.com/hooks", "whsec_1", "event-1", "incident.reported", "cg-1", nil, `{"severity":"high"}`, occurred)
+	mock.ExpectQuery(`WHERE status = 'pending' AND next_attempt_at <= \$1[\s\S]+SET next_attempt_at = \$3`).
+		WithArgs(now, 10, now.Add(time.Minute)).
+		WillReturnRows(rows)
+
+	messages, err := repo.ClaimDue(context.Background(), now, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDue error: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.Attempts != 2 || msg.Secret != "whsec_1" || msg.Event.Type != domain.EventIncidentReported || msg.Event.ScheduleID != nil {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if string(msg.Event.Payload) != `{"severity":"high"}` {
+		t.Fatalf("unexpected payload %s", msg.Event.Payload)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookRepositoryMarkFailedDeadLetters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(sqlx.NewDb(db, "pgx"))
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	status := 500
+	mock.ExpectExec(`UPDATE webhook_deliveries[\s\S]+attempts = attempts \+ 1`).
+		WithArgs("delivery-1", domain.WebhookDeliveryDead, &status, "subscriber responded 500", nil, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.MarkFailed(context.Background(), "delivery-1", &status, "subscriber responded 500", nil, now); err != nil {
+		t.Fatalf("MarkFailed error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestWebhookRepositoryListDeliveriesFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock init: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWebhookRepository(sqlx.NewDb(db, "pgx"))
+	created := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "status", "attempts", "next_attempt_at",
+		"last_error", "response_status", "delivered_at", "created_at"}).
+		AddRow("delivery-1", "sub-1", "event-1", "schedule.status_changed", "dead", 8, nil, "subscriber responded 502", 502, nil, created)
+	mock.ExpectQuery(`WHERE d\.subscription_id = \$1 AND d\.status = \$2 ORDER BY d\.created_at DESC, d\.id DESC LIMIT \$3`).
+		WithArgs("sub-1", domain.WebhookDeliveryDead, 50).
+		WillReturnRows(rows)
+
+	deliveries, err := repo.ListDeliveries(context.Background(), repository.WebhookDeliveryFilter{SubscriptionID: "sub-1", Status: domain.WebhookDeliveryDead, Limit: 50})
+	if err != nil {
+		t.Fatalf("ListDeliveries error: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].ResponseStatus == nil || *deliveries[0].ResponseStatus != 502 || deliveries[0].Attempts != 8 {
+		t.Fatalf("unexpected deliveries %+v", deliveries)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
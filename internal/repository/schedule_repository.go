@@ -9,13 +9,44 @@ import (
 
 // ScheduleFilter captures optional parameters for listing schedules.
 type ScheduleFilter struct {
-	Status     []domain.ScheduleStatus
-	Date       *time.Time
-	TodayOnly  bool
-	WithClient bool
+	Status []domain.ScheduleStatus
+	Date   *time.Time
+	// From and To bound the visit's start by calendar date, inclusive. Like
+	// Date they are read in their own location.
+	From     *time.Time
+	To       *time.Time
+	ClientID string
+	// ServiceName matches the service name case-insensitively.
+	ServiceName string
+	// Search matches visits whose client name or location label contains it.
+	Search string
+	Sort   ScheduleSort
 	// Pagination parameters
-	Limit      int
-	Offset     int
+	Limit  int
+	Offset int
+	// After continues the listing from the row after this position; Offset
+	// is ignored when it is set.
+	After *domain.Cursor
+}
+
+// ScheduleSort orders schedule listings.
+type ScheduleSort string
+
+const (
+	// ScheduleSortStatus lists visits by domain.ScheduleStatus.ListRank, then
+	// newest start first. It is the default.
+	ScheduleSortStatus    ScheduleSort = "status"
+	ScheduleSortStartAsc  ScheduleSort = "start_time"
+	ScheduleSortStartDesc ScheduleSort = "-start_time"
+)
+
+// Valid reports whether the sort is known; empty means ScheduleSortStatus.
+func (s ScheduleSort) Valid() bool {
+	switch s {
+	case "", ScheduleSortStatus, ScheduleSortStartAsc, ScheduleSortStartDesc:
+		return true
+	}
+	return false
 }
 
 // ScheduleRepository defines the persistence contract for schedule operations.
@@ -24,7 +55,27 @@ type ScheduleRepository interface {
 	GetSchedule(ctx context.Context, scheduleID string) (domain.Schedule, error)
 	GetScheduleForCaregiver(ctx context.Context, scheduleID, caregiverID string) (domain.Schedule, error)
 	LogClockIn(ctx context.Context, scheduleID string, event domain.VisitEvent) error
-	LogClockOut(ctx context.Context, scheduleID string, event domain.VisitEvent) error
+	// CompleteVisit records the clock-out and the sign-off, when there is
+	// one, and marks the in-progress visit completed, all or nothing.
+	CompleteVisit(ctx context.Context, scheduleID string, event domain.VisitEvent, signoff *domain.VisitSignoff) error
 	UpdateStatus(ctx context.Context, scheduleID string, status domain.ScheduleStatus) error
-	GetMetrics(ctx context.Context, caregiverID string, day time.Time) (domain.ScheduleMetrics, error)
+	GetMetrics(ctx context.Context, caregiverID string, day time.Time, rule domain.OvernightRule) (domain.ScheduleMetrics, error)
+	// ListRouteStops returns non-cancelled visits starting in [from, to) ordered by start time.
+	ListRouteStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.RouteStop, error)
+	// ListVisitIntervals returns clocked visits overlapping [from, to).
+	ListVisitIntervals(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.VisitInterval, error)
+	// GetMetricsRange aggregates visits starting in [query.From, query.To) by
+	// bucket, oldest first, and over the whole range. Buckets without visits
+	// are omitted.
+	GetMetricsRange(ctx context.Context, caregiverID string, query MetricsRangeQuery) ([]domain.MetricsBucket, domain.VisitMetrics, error)
+}
+
+// MetricsRangeQuery selects the visits and bucketing of a metrics trend.
+type MetricsRangeQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity domain.MetricsGranularity
+	// TimeZone is the IANA name buckets are cut in.
+	TimeZone    string
+	OnTimeGrace time.Duration
 }
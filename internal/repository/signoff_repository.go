@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// SignoffRepository persists client sign-off settings and the acknowledgements
+// taken at clock-out.
+type SignoffRepository interface {
+	// GetSettings returns domain.ErrNotFound for an unknown client, and
+	// optional sign-off without a PIN when the client has no settings.
+	GetSettings(ctx context.Context, clientID string) (domain.ClientSignoffSettings, error)
+	SaveSettings(ctx context.Context, settings domain.ClientSignoffSettings) error
+
+	// ReservePINAttempt records a PIN entered at clock-out as wrong before it
+	// is checked, and returns the record's ID. Attempts on a client are
+	// serialised: once limit wrong PINs have been entered since since it
+	// records nothing and returns domain.ErrSignoffPINLocked.
+	ReservePINAttempt(ctx context.Context, attempt domain.SignoffPINFailure, since time.Time, limit int) (string, error)
+	// ClearPINAttempt removes a reserved attempt whose PIN was right.
+	ClearPINAttempt(ctx context.Context, id string) error
+
+	// Save stores the sign-off of a visit, replacing an earlier one.
+	Save(ctx context.Context, signoff domain.VisitSignoff) error
+	Get(ctx context.Context, scheduleID string) (domain.VisitSignoff, error)
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// TimesheetRepository persists submitted timesheets and their review workflow.
+type TimesheetRepository interface {
+	// SaveSubmission stores the timesheet and its days with status submitted and
+	// returns its id. Resubmitting a rejected period replaces it; any other
+	// existing timesheet for the period yields domain.ErrConflict.
+	SaveSubmission(ctx context.Context, timesheet domain.Timesheet) (string, error)
+	// GetTimesheet returns the timesheet including its days.
+	GetTimesheet(ctx context.Context, timesheetID string) (domain.Timesheet, error)
+	// FindByPeriod returns the caregiver's timesheet starting at periodStart, with days.
+	FindByPeriod(ctx context.Context, caregiverID string, periodStart time.Time) (domain.Timesheet, error)
+	// ListByCaregiver returns the caregiver's timesheets without days, newest first.
+	ListByCaregiver(ctx context.Context, caregiverID string) ([]domain.Timesheet, error)
+	// ListByStatus returns timesheets in any of the statuses without days, oldest first.
+	ListByStatus(ctx context.Context, statuses []domain.TimesheetStatus) ([]domain.Timesheet, error)
+
+	// Approve, Reject and Lock move a timesheet on from submitted, submitted and
+	// approved respectively; they return domain.ErrConflict when it has moved on.
+	Approve(ctx context.Context, timesheetID, reviewerID string, comment *string, at time.Time) error
+	Reject(ctx context.Context, timesheetID, reviewerID string, comment *string, at time.Time) error
+	Lock(ctx context.Context, timesheetID, actorID string, at time.Time) error
+
+	// IsLocked reports whether at falls inside a locked timesheet of the caregiver.
+	IsLocked(ctx context.Context, caregiverID string, at time.Time) (bool, error)
+}
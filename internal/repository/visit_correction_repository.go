@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// VisitCorrectionRepository persists visit corrections and their audit history.
+type VisitCorrectionRepository interface {
+	// CreateCorrection stores a pending correction and returns its id. It returns
+	// domain.ErrConflict when the visit already has a pending correction.
+	CreateCorrection(ctx context.Context, correction domain.VisitCorrection) (string, error)
+	GetCorrection(ctx context.Context, correctionID string) (domain.VisitCorrection, error)
+	// ListBySchedule returns every correction for a visit, oldest first.
+	ListBySchedule(ctx context.Context, scheduleID string) ([]domain.VisitCorrection, error)
+	ListPending(ctx context.Context) ([]domain.VisitCorrection, error)
+
+	// ApproveCorrection snapshots the visit's current values onto the correction,
+	// writes the approved values and flags the visit as manually edited in one
+	// transaction. It returns domain.ErrConflict if the correction is no longer pending.
+	ApproveCorrection(ctx context.Context, approval domain.CorrectionApproval) error
+	// RejectCorrection returns domain.ErrConflict if the correction is no longer pending.
+	RejectCorrection(ctx context.Context, correctionID, reviewerID string, comment *string, reviewedAt time.Time) error
+}
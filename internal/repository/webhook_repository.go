@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// WebhookDeliveryFilter narrows a delivery listing; zero values match everything.
+type WebhookDeliveryFilter struct {
+	SubscriptionID string
+	Status         domain.WebhookDeliveryStatus
+	Limit          int
+}
+
+// WebhookRepository persists webhook subscriptions and the delivery of outbox
+// events to them.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (domain.WebhookSubscription, error)
+	// ListSubscriptions returns every subscription, oldest first.
+	ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	// UpdateSubscription saves the URL, description, event types and active flag.
+	UpdateSubscription(ctx context.Context, subscription domain.WebhookSubscription) error
+
+	// QueueOutbox takes up to limit undispatched outbox events, oldest first,
+	// queues a pending delivery for every active subscription that wants each
+	// one and marks them dispatched. It returns how many events it took.
+	QueueOutbox(ctx context.Context, limit int, now time.Time) (int, error)
+	// ClaimDue returns up to limit pending deliveries due at now and pushes their
+	// next attempt back by lease, so other replicas skip them while they are sent.
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookMessage, error)
+	// MarkDelivered records a successful attempt.
+	MarkDelivered(ctx context.Context, deliveryID string, responseStatus int, at time.Time) error
+	// MarkFailed records a failed attempt, to be retried at retryAt or, when
+	// retryAt is nil, moved to the dead-letter queue.
+	MarkFailed(ctx context.Context, deliveryID string, responseStatus *int, reason string, retryAt *time.Time, at time.Time) error
+
+	// ListDeliveries returns deliveries newest first.
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]domain.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, deliveryID string) (domain.WebhookDelivery, error)
+	// Replay queues a delivery to be sent again at now with a fresh attempt count.
+	Replay(ctx context.Context, deliveryID string, now time.Time) error
+	// ReplayDead requeues every dead delivery of the subscription and returns how many.
+	ReplayDead(ctx context.Context, subscriptionID string, now time.Time) (int, error)
+}
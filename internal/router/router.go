@@ -21,6 +21,22 @@ func NewRouter(
 	scheduleHandler *handler.ScheduleHandler,
 	taskHandler *handler.TaskHandler,
 	attendanceHandler *handler.CaregiverAttendanceHandler,
+	openShiftHandler *handler.OpenShiftHandler,
+	assignmentHandler *handler.AssignmentHandler,
+	evvHandler *handler.EVVHandler,
+	correctionHandler *handler.VisitCorrectionHandler,
+	timesheetHandler *handler.TimesheetHandler,
+	mileageHandler *handler.MileageHandler,
+	incidentHandler *handler.IncidentHandler,
+	dashboardHandler *handler.DashboardHandler,
+	eventsHandler *handler.EventsHandler,
+	webhookHandler *handler.WebhookHandler,
+	notificationHandler *handler.NotificationHandler,
+	familyHandler *handler.FamilyHandler,
+	signoffHandler *handler.SignoffHandler,
+	attachmentHandler *handler.AttachmentHandler,
+	medicationHandler *handler.MedicationHandler,
+	observationHandler *handler.ObservationHandler,
 	docsHandler *handler.DocsHandler,
 	corsCfg config.CORSConfig,
 ) *gin.Engine {
@@ -48,16 +64,40 @@ func NewRouter(
 	api := r.Group("/api")
 	{
 		api.POST("/auth/token", authHandler.Token)
+
+		// Read-only family portal, signed in with family portal tokens only
+		api.POST("/family/token", familyHandler.Token)
+		family := api.Group("/family")
+		family.Use(middleware.FamilyAuthenticated(authUC))
+		family.GET("/me", familyHandler.Me)
+		family.GET("/visits", familyHandler.ListVisits)
+		family.GET("/visits/:scheduleID", familyHandler.GetVisit)
+
+		// Signed attachment links carry their own grant instead of a bearer token
+		api.GET("/attachments/:attachmentID/content", attachmentHandler.Content)
+
 		protected := api.Group("/")
 		protected.Use(middleware.Authenticated(authUC))
 
 		protected.GET("/schedules", scheduleHandler.ListSchedules)
 		protected.GET("/schedules/today", scheduleHandler.TodaySchedules)
+		protected.GET("/schedules/today/route", scheduleHandler.TodayRoute)
 		protected.GET("/schedules/metrics", scheduleHandler.Metrics)
+		protected.GET("/schedules/metrics/range", scheduleHandler.MetricsRange)
 		protected.GET("/schedules/:scheduleID", scheduleHandler.GetSchedule)
 		protected.POST("/schedules/:scheduleID/start", scheduleHandler.StartSchedule)
 		protected.POST("/schedules/:scheduleID/end", scheduleHandler.EndSchedule)
 		protected.POST("/schedules/:scheduleID/tasks", taskHandler.CreateTask)
+		protected.GET("/schedules/:scheduleID/corrections", correctionHandler.ListScheduleCorrections)
+		protected.POST("/schedules/:scheduleID/corrections", correctionHandler.CreateCorrection)
+		protected.GET("/schedules/:scheduleID/attachments", attachmentHandler.List)
+		protected.POST("/schedules/:scheduleID/attachments", attachmentHandler.Upload)
+		protected.GET("/attachments/:attachmentID/url", attachmentHandler.SignURL)
+		protected.GET("/schedules/:scheduleID/medications", medicationHandler.VisitRecord)
+		protected.POST("/schedules/:scheduleID/medications", medicationHandler.GivePRN)
+		protected.PATCH("/schedules/:scheduleID/medications/:administrationID", medicationHandler.RecordDose)
+		protected.POST("/schedules/:scheduleID/observations", observationHandler.RecordObservations)
+		protected.GET("/observation-types", observationHandler.ListTypes)
 
 		protected.PATCH("/tasks/:taskID", taskHandler.UpdateTaskStatus)
 
@@ -65,7 +105,126 @@ func NewRouter(
 		protected.GET("/attendance/today/status", attendanceHandler.GetTodayStatus)
 		protected.POST("/attendance/clock-in", attendanceHandler.ClockIn)
 		protected.POST("/attendance/clock-out", attendanceHandler.ClockOut)
+		protected.POST("/attendance/break/start", attendanceHandler.StartBreak)
+		protected.POST("/attendance/break/end", attendanceHandler.EndBreak)
 		protected.GET("/attendance/history", attendanceHandler.GetAttendanceHistory)
+		protected.GET("/attendance/history/export", attendanceHandler.ExportAttendanceHistory)
+
+		// Timesheets
+		protected.GET("/timesheets", timesheetHandler.ListTimesheets)
+		protected.GET("/timesheets/current", timesheetHandler.CurrentTimesheet)
+		protected.POST("/timesheets/submit", timesheetHandler.SubmitTimesheet)
+		protected.GET("/timesheets/:timesheetID", timesheetHandler.GetTimesheet)
+		protected.GET("/timesheets/:timesheetID/export", timesheetHandler.ExportTimesheet)
+
+		// Mileage between visits
+		protected.GET("/mileage/daily", mileageHandler.DailyMileage)
+		protected.GET("/mileage/claims", mileageHandler.ListClaims)
+		protected.POST("/mileage/claims", mileageHandler.SubmitClaim)
+		protected.GET("/mileage/claims/:claimID", mileageHandler.GetClaim)
+
+		// Incident reporting
+		protected.POST("/incidents", incidentHandler.ReportIncident)
+		protected.GET("/incidents/:incidentID", incidentHandler.GetIncident)
+
+		// Caregiver notifications
+		protected.GET("/notifications", notificationHandler.ListNotifications)
+		protected.GET("/notifications/preferences", notificationHandler.GetPreferences)
+		protected.PATCH("/notifications/preferences", notificationHandler.UpdatePreferences)
+
+		// Real-time event stream
+		protected.GET("/events/stream", eventsHandler.Stream)
+
+		// Open shift marketplace
+		protected.GET("/open-shifts", openShiftHandler.ListOpenShifts)
+		protected.POST("/open-shifts/:scheduleID/claim", openShiftHandler.ClaimOpenShift)
+
+		// Coordinator assignment optimiser
+		assignments := protected.Group("/assignments")
+		assignments.Use(middleware.RequireScope(handler.AssignmentsWriteScope))
+		assignments.POST("/proposals", assignmentHandler.CreateProposal)
+		assignments.GET("/proposals/:proposalID", assignmentHandler.GetProposal)
+		assignments.POST("/proposals/:proposalID/apply", assignmentHandler.ApplyProposal)
+
+		// EVV aggregator exports
+		evv := protected.Group("/evv")
+		evv.Use(middleware.RequireScope(handler.EVVExportScope))
+		evv.GET("/visits", evvHandler.ListVisits)
+		evv.POST("/exports", evvHandler.CreateExport)
+		evv.GET("/exports/:batchID", evvHandler.GetExport)
+		evv.GET("/exports/:batchID/download", evvHandler.DownloadExport)
+
+		// Visit correction review
+		corrections := protected.Group("/corrections")
+		corrections.Use(middleware.RequireScope(handler.CorrectionReviewScope))
+		corrections.GET("", correctionHandler.ListPending)
+		corrections.POST("/:correctionID/approve", correctionHandler.Approve)
+		corrections.POST("/:correctionID/reject", correctionHandler.Reject)
+
+		// Timesheet approval
+		timesheetReviews := protected.Group("/timesheet-reviews")
+		timesheetReviews.Use(middleware.RequireScope(handler.TimesheetApproveScope))
+		timesheetReviews.GET("", timesheetHandler.ListForReview)
+		timesheetReviews.POST("/:timesheetID/approve", timesheetHandler.Approve)
+		timesheetReviews.POST("/:timesheetID/reject", timesheetHandler.Reject)
+		timesheetReviews.POST("/:timesheetID/lock", timesheetHandler.Lock)
+
+		// Mileage claim approval
+		mileageReviews := protected.Group("/mileage-reviews")
+		mileageReviews.Use(middleware.RequireScope(handler.MileageApproveScope))
+		mileageReviews.GET("", mileageHandler.ListPending)
+		mileageReviews.POST("/:claimID/approve", mileageHandler.Approve)
+		mileageReviews.POST("/:claimID/reject", mileageHandler.Reject)
+
+		// Outbound webhook administration
+		webhooks := protected.Group("/webhooks")
+		webhooks.Use(middleware.RequireScope(handler.WebhooksManageScope))
+		webhooks.GET("/subscriptions", webhookHandler.ListSubscriptions)
+		webhooks.POST("/subscriptions", webhookHandler.CreateSubscription)
+		webhooks.GET("/subscriptions/:subscriptionID", webhookHandler.GetSubscription)
+		webhooks.PATCH("/subscriptions/:subscriptionID", webhookHandler.UpdateSubscription)
+		webhooks.POST("/subscriptions/:subscriptionID/replay-dead", webhookHandler.ReplayDeadLetters)
+		webhooks.GET("/deliveries", webhookHandler.ListDeliveries)
+		webhooks.POST("/deliveries/:deliveryID/replay", webhookHandler.ReplayDelivery)
+
+		// Family portal administration
+		clientFamily := protected.Group("/clients/:clientID")
+		clientFamily.Use(middleware.RequireScope(handler.FamilyManageScope))
+		clientFamily.GET("/family-members", familyHandler.ListMembers)
+		clientFamily.POST("/family-members", familyHandler.AddMember)
+		clientFamily.PATCH("/family-members/:memberID", familyHandler.UpdateMember)
+		clientFamily.GET("/family-consent", familyHandler.GetConsent)
+		clientFamily.PATCH("/family-consent", familyHandler.UpdateConsent)
+
+		// Client acknowledgement of visits at clock-out
+		clientSignoff := protected.Group("/clients/:clientID")
+		clientSignoff.Use(middleware.RequireScope(handler.SignoffManageScope))
+		clientSignoff.GET("/signoff-settings", signoffHandler.GetSettings)
+		clientSignoff.PATCH("/signoff-settings", signoffHandler.UpdateSettings)
+
+		// Medication orders and administration records
+		clientMedications := protected.Group("/clients/:clientID")
+		clientMedications.Use(middleware.RequireScope(handler.MedicationsManageScope))
+		clientMedications.GET("/medication-orders", medicationHandler.ListOrders)
+		clientMedications.POST("/medication-orders", medicationHandler.CreateOrder)
+		clientMedications.PATCH("/medication-orders/:orderID", medicationHandler.UpdateOrder)
+		clientMedications.GET("/mar", medicationHandler.ClientRecord)
+
+		// Client observation trends
+		clientObservations := protected.Group("/clients/:clientID")
+		clientObservations.Use(middleware.RequireScope(handler.ObservationsReadScope))
+		clientObservations.GET("/observations", observationHandler.ClientTrends)
+
+		// Supervisor operations dashboard
+		admin := protected.Group("/admin")
+		admin.Use(middleware.RequireScope(handler.SupervisorScope))
+		admin.GET("/dashboard", dashboardHandler.Summary)
+		admin.GET("/dashboard/visits/live", dashboardHandler.LiveVisits)
+		admin.GET("/dashboard/visits/unstarted", dashboardHandler.UnstartedVisits)
+		admin.GET("/dashboard/visits/missed", dashboardHandler.MissedVisits)
+		admin.GET("/dashboard/caregivers", dashboardHandler.Caregivers)
+		admin.GET("/dashboard/incidents", dashboardHandler.OpenIncidents)
+		admin.POST("/incidents/:incidentID/resolve", incidentHandler.ResolveIncident)
 	}
 
 	return r
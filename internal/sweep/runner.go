@@ -0,0 +1,51 @@
+// Package sweep runs periodic maintenance work in the background, such as
+// flagging missed medication doses.
+package sweep
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Func does one pass of the work and returns how many records it changed.
+type Func func(ctx context.Context) (int, error)
+
+// Runner runs a Func on start and then every interval.
+type Runner struct {
+	name     string
+	run      Func
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewRunner constructs a runner that logs under name.
+func NewRunner(name string, run Func, interval time.Duration, logger *zap.Logger) *Runner {
+	return &Runner{name: name, run: run, interval: interval, logger: logger}
+}
+
+// Run works until ctx is done.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	r.sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.sweep(ctx)
+		}
+	}
+}
+
+func (r *Runner) sweep(ctx context.Context) {
+	changed, err := r.run(ctx)
+	if err != nil && ctx.Err() == nil {
+		r.logger.Error(r.name, zap.Error(err))
+	}
+	if changed > 0 {
+		r.logger.Info(r.name, zap.Int("count", changed))
+	}
+}
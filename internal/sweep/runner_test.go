@@ -0,0 +1,43 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestRunnerSweepsOnStartAndEveryInterval(t *testing.T) {
+	runs := make(chan struct{}, 3)
+	runner := NewRunner("flag missed doses", func(ctx context.Context) (int, error) {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+		return 0, errors.New("database unavailable")
+	}, 10*time.Millisecond, zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+
+	// A failed pass is logged and the next one still runs.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("expected sweep %d to run", i+1)
+		}
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the runner to stop with its context")
+	}
+}
@@ -0,0 +1,253 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// assignmentWeights tunes how the greedy solver scores candidate caregivers.
+type assignmentWeights struct {
+	// ContinuityPerVisit rewards each previous visit to the same client, up to ContinuityCap visits.
+	ContinuityPerVisit float64
+	ContinuityCap      int
+	// TravelPerKm penalises distance from the caregiver's previous location.
+	TravelPerKm float64
+	// LoadPerVisit penalises caregivers who already carry visits in the range, spreading work.
+	LoadPerVisit float64
+	// TravelSpeedKmh estimates drive time when checking that consecutive visits are reachable.
+	TravelSpeedKmh float64
+}
+
+var defaultAssignmentWeights = assignmentWeights{
+	ContinuityPerVisit: 10,
+	ContinuityCap:      5,
+	TravelPerKm:        1,
+	LoadPerVisit:       2,
+	TravelSpeedKmh:     averageTravelSpeedKmh,
+}
+
+// assignmentInput is the snapshot the solver works on.
+type assignmentInput struct {
+	visits       []domain.OpenShift
+	caregivers   []domain.Caregiver
+	availability map[string][]domain.CaregiverAvailability
+	committed    map[string][]domain.CommittedVisit
+	// continuity maps client ID to caregiver ID to completed visit count.
+	continuity map[string]map[string]int
+	loc        *time.Location
+}
+
+const (
+	rejectQualification = "lack the required qualifications"
+	rejectAvailability  = "are not available at that time"
+	rejectOverlap       = "are already booked at that time"
+	rejectTravel        = "cannot travel between consecutive visits in time"
+)
+
+// solveAssignments greedily assigns the most constrained visits first, choosing
+// the highest scoring feasible caregiver for each and recording the reasons.
+func solveAssignments(in assignmentInput, w assignmentWeights) ([]domain.ProposedAssignment, []domain.UnassignedVisit) {
+	loc := in.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	timelines := make(map[string][]domain.CommittedVisit, len(in.caregivers))
+	for _, cg := range in.caregivers {
+		visits := append([]domain.CommittedVisit(nil), in.committed[cg.ID]...)
+		sort.Slice(visits, func(i, j int) bool { return visits[i].StartTime.Before(visits[j].StartTime) })
+		timelines[cg.ID] = visits
+	}
+
+	visits := append([]domain.OpenShift(nil), in.visits...)
+	qualifiedCount := make(map[string]int, len(visits))
+	for _, v := range visits {
+		for _, cg := range in.caregivers {
+			if v.HasQualifications(cg.Qualifications) {
+				qualifiedCount[v.ScheduleID]++
+			}
+		}
+	}
+	sort.SliceStable(visits, func(i, j int) bool {
+		ci, cj := qualifiedCount[visits[i].ScheduleID], qualifiedCount[visits[j].ScheduleID]
+		if ci != cj {
+			return ci < cj
+		}
+		return visits[i].StartTime.Before(visits[j].StartTime)
+	})
+
+	var assigned []domain.ProposedAssignment
+	var unassigned []domain.UnassignedVisit
+
+	for _, visit := range visits {
+		var best *domain.ProposedAssignment
+		rejections := map[string]int{}
+
+		for _, cg := range in.caregivers {
+			candidate, reject := evaluateCandidate(visit, cg, in, timelines[cg.ID], loc, w)
+			if reject != "" {
+				rejections[reject]++
+				continue
+			}
+			if best == nil || candidate.Score > best.Score ||
+				(candidate.Score == best.Score && candidate.CaregiverID < best.CaregiverID) {
+				c := candidate
+				best = &c
+			}
+		}
+
+		if best == nil {
+			unassigned = append(unassigned, domain.UnassignedVisit{
+				ScheduleID: visit.ScheduleID,
+				StartTime:  visit.StartTime,
+				Reasons:    summariseRejections(rejections, len(in.caregivers)),
+			})
+			continue
+		}
+
+		assigned = append(assigned, *best)
+		timelines[best.CaregiverID] = insertCommitted(timelines[best.CaregiverID], domain.CommittedVisit{
+			ScheduleID:  visit.ScheduleID,
+			CaregiverID: best.CaregiverID,
+			ClientID:    visit.ClientID,
+			StartTime:   visit.StartTime,
+			EndTime:     visit.EndTime,
+			Latitude:    visit.Latitude,
+			Longitude:   visit.Longitude,
+		})
+	}
+
+	sort.SliceStable(assigned, func(i, j int) bool { return assigned[i].StartTime.Before(assigned[j].StartTime) })
+	sort.SliceStable(unassigned, func(i, j int) bool { return unassigned[i].StartTime.Before(unassigned[j].StartTime) })
+	return assigned, unassigned
+}
+
+func evaluateCandidate(
+	visit domain.OpenShift,
+	cg domain.Caregiver,
+	in assignmentInput,
+	timeline []domain.CommittedVisit,
+	loc *time.Location,
+	w assignmentWeights,
+) (domain.ProposedAssignment, string) {
+	if !visit.HasQualifications(cg.Qualifications) {
+		return domain.ProposedAssignment{}, rejectQualification
+	}
+
+	localStart, localEnd := visit.StartTime.In(loc), visit.EndTime.In(loc)
+	var window *domain.CaregiverAvailability
+	for i, a := range in.availability[cg.ID] {
+		if a.Covers(localStart, localEnd) {
+			window = &in.availability[cg.ID][i]
+			break
+		}
+	}
+	if window == nil {
+		return domain.ProposedAssignment{}, rejectAvailability
+	}
+
+	var prev, next *domain.CommittedVisit
+	for i := range timeline {
+		c := &timeline[i]
+		if c.StartTime.Before(visit.EndTime) && visit.StartTime.Before(c.EndTime) {
+			return domain.ProposedAssignment{}, rejectOverlap
+		}
+		if !c.EndTime.After(visit.StartTime) {
+			prev = c
+		} else if next == nil && !c.StartTime.Before(visit.EndTime) {
+			next = c
+		}
+	}
+
+	reasons := []string{
+		"qualified for the visit",
+		fmt.Sprintf("available %s %s-%s", window.Weekday, formatMinute(window.StartMinute), formatMinute(window.EndMinute)),
+	}
+
+	var travelKm float64
+	switch {
+	case prev != nil && sameDay(prev.EndTime.In(loc), localStart):
+		travelKm = domain.DistanceKm(prev.Latitude, prev.Longitude, visit.Latitude, visit.Longitude)
+		if prev.EndTime.Add(travelDuration(travelKm, w.TravelSpeedKmh)).After(visit.StartTime) {
+			return domain.ProposedAssignment{}, rejectTravel
+		}
+		reasons = append(reasons, fmt.Sprintf("%.1f km from previous visit", travelKm))
+	case cg.HomeLatitude != nil && cg.HomeLongitude != nil:
+		travelKm = domain.DistanceKm(*cg.HomeLatitude, *cg.HomeLongitude, visit.Latitude, visit.Longitude)
+		reasons = append(reasons, fmt.Sprintf("%.1f km from home", travelKm))
+	}
+
+	if next != nil && sameDay(next.StartTime.In(loc), localEnd) {
+		onward := domain.DistanceKm(visit.Latitude, visit.Longitude, next.Latitude, next.Longitude)
+		if visit.EndTime.Add(travelDuration(onward, w.TravelSpeedKmh)).After(next.StartTime) {
+			return domain.ProposedAssignment{}, rejectTravel
+		}
+	}
+
+	score := -travelKm * w.TravelPerKm
+	if seen := in.continuity[visit.ClientID][cg.ID]; seen > 0 {
+		capped := seen
+		if w.ContinuityCap > 0 && capped > w.ContinuityCap {
+			capped = w.ContinuityCap
+		}
+		score += float64(capped) * w.ContinuityPerVisit
+		reasons = append(reasons, fmt.Sprintf("continuity: visited client %d time(s) before", seen))
+	}
+	if load := len(timeline); load > 0 {
+		score -= float64(load) * w.LoadPerVisit
+		reasons = append(reasons, fmt.Sprintf("already has %d visit(s) in range", load))
+	}
+
+	return domain.ProposedAssignment{
+		ScheduleID:    visit.ScheduleID,
+		CaregiverID:   cg.ID,
+		CaregiverName: cg.Name,
+		StartTime:     visit.StartTime,
+		Score:         math.Round(score*100) / 100,
+		TravelKm:      math.Round(travelKm*100) / 100,
+		Reasons:       reasons,
+	}, ""
+}
+
+func summariseRejections(rejections map[string]int, total int) []string {
+	if total == 0 {
+		return []string{"no caregivers available"}
+	}
+	order := []string{rejectQualification, rejectAvailability, rejectOverlap, rejectTravel}
+	reasons := make([]string, 0, len(rejections))
+	for _, key := range order {
+		if n := rejections[key]; n > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d caregiver(s) %s", n, key))
+		}
+	}
+	return reasons
+}
+
+func insertCommitted(timeline []domain.CommittedVisit, visit domain.CommittedVisit) []domain.CommittedVisit {
+	idx := sort.Search(len(timeline), func(i int) bool { return timeline[i].StartTime.After(visit.StartTime) })
+	timeline = append(timeline, domain.CommittedVisit{})
+	copy(timeline[idx+1:], timeline[idx:])
+	timeline[idx] = visit
+	return timeline
+}
+
+func travelDuration(km, speedKmh float64) time.Duration {
+	if speedKmh <= 0 {
+		return 0
+	}
+	return time.Duration(km / speedKmh * float64(time.Hour))
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func formatMinute(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
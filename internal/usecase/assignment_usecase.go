@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// maxAssignmentRangeDays bounds a single optimiser run.
+const maxAssignmentRangeDays = 31
+
+// AssignmentUsecase proposes and applies caregiver assignments for open visits.
+type AssignmentUsecase struct {
+	assignments repository.AssignmentRepository
+	openShifts  repository.OpenShiftRepository
+	loc         *time.Location
+	weights     assignmentWeights
+	events      EventPublisher
+	now         func() time.Time
+}
+
+// NewAssignmentUsecase constructs an AssignmentUsecase. Availability windows and
+// date ranges are interpreted in loc.
+func NewAssignmentUsecase(
+	assignments repository.AssignmentRepository,
+	openShifts repository.OpenShiftRepository,
+	loc *time.Location,
+) *AssignmentUsecase {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &AssignmentUsecase{
+		assignments: assignments,
+		openShifts:  openShifts,
+		loc:         loc,
+		weights:     defaultAssignmentWeights,
+		events:      noEvents{},
+		now:         time.Now,
+	}
+}
+
+// WithEvents announces applied assignments to events.
+func (uc *AssignmentUsecase) WithEvents(events EventPublisher) {
+	if events != nil {
+		uc.events = events
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *AssignmentUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// ProposeAssignments runs the optimiser over open visits starting between the
+// from and to dates (inclusive) and stores the result as a draft proposal.
+func (uc *AssignmentUsecase) ProposeAssignments(ctx context.Context, requestedBy string, fromDate, toDate time.Time) (domain.AssignmentProposal, error) {
+	from := time.Date(fromDate.Year(), fromDate.Month(), fromDate.Day(), 0, 0, 0, 0, uc.loc)
+	to := time.Date(toDate.Year(), toDate.Month(), toDate.Day(), 0, 0, 0, 0, uc.loc).AddDate(0, 0, 1)
+	if !to.After(from) || to.Sub(from) > maxAssignmentRangeDays*24*time.Hour {
+		return domain.AssignmentProposal{}, domain.ErrValidationFailure
+	}
+
+	now := uc.now()
+	if from.Before(now) {
+		from = now
+	}
+
+	visits, err := uc.openShifts.ListOpenShifts(ctx, repository.OpenShiftFilter{From: from, To: &to})
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+	caregivers, err := uc.assignments.ListCaregivers(ctx)
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+	windows, err := uc.assignments.ListAvailability(ctx)
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+	// Widen by a day so travel from the previous evening's visit is accounted for.
+	committed, err := uc.assignments.ListCommittedVisits(ctx, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+
+	clientIDs := make([]string, 0, len(visits))
+	seen := map[string]bool{}
+	for _, v := range visits {
+		if !seen[v.ClientID] {
+			seen[v.ClientID] = true
+			clientIDs = append(clientIDs, v.ClientID)
+		}
+	}
+	history, err := uc.assignments.ListContinuity(ctx, clientIDs)
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+
+	in := assignmentInput{
+		visits:       visits,
+		caregivers:   caregivers,
+		availability: map[string][]domain.CaregiverAvailability{},
+		committed:    map[string][]domain.CommittedVisit{},
+		continuity:   map[string]map[string]int{},
+		loc:          uc.loc,
+	}
+	for _, w := range windows {
+		in.availability[w.CaregiverID] = append(in.availability[w.CaregiverID], w)
+	}
+	for _, c := range committed {
+		in.committed[c.CaregiverID] = append(in.committed[c.CaregiverID], c)
+	}
+	for _, h := range history {
+		if in.continuity[h.ClientID] == nil {
+			in.continuity[h.ClientID] = map[string]int{}
+		}
+		in.continuity[h.ClientID][h.CaregiverID] = h.Visits
+	}
+
+	assigned, unassigned := solveAssignments(in, uc.weights)
+
+	proposal := domain.AssignmentProposal{
+		From:        from,
+		To:          to,
+		Status:      domain.AssignmentProposalDraft,
+		Assignments: assigned,
+		Unassigned:  unassigned,
+		CreatedBy:   requestedBy,
+		CreatedAt:   now,
+	}
+	id, err := uc.assignments.SaveProposal(ctx, proposal)
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+	proposal.ID = id
+	return proposal, nil
+}
+
+// GetProposal returns a stored proposal.
+func (uc *AssignmentUsecase) GetProposal(ctx context.Context, proposalID string) (domain.AssignmentProposal, error) {
+	return uc.assignments.GetProposal(ctx, proposalID)
+}
+
+// ApplyProposal assigns every visit in a draft proposal in a single transaction.
+func (uc *AssignmentUsecase) ApplyProposal(ctx context.Context, proposalID string) (domain.AssignmentProposal, error) {
+	proposal, err := uc.assignments.GetProposal(ctx, proposalID)
+	if err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+	if proposal.Status != domain.AssignmentProposalDraft {
+		return domain.AssignmentProposal{}, domain.ErrInvalidStatusTransition
+	}
+
+	appliedAt := uc.now()
+	if err := uc.assignments.ApplyProposal(ctx, proposalID, appliedAt); err != nil {
+		return domain.AssignmentProposal{}, err
+	}
+
+	proposal.Status = domain.AssignmentProposalApplied
+	proposal.AppliedAt = &appliedAt
+	for _, a := range proposal.Assignments {
+		uc.events.Publish(ctx, domain.Event{
+			Type:        domain.EventScheduleAssigned,
+			CaregiverID: a.CaregiverID,
+			ScheduleID:  a.ScheduleID,
+			OccurredAt:  appliedAt,
+			Data:        map[string]interface{}{"proposal_id": proposal.ID, "start_time": a.StartTime},
+		})
+	}
+	return proposal, nil
+}
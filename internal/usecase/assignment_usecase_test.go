@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.AssignmentRepository = (*assignmentRepoStub)(nil)
+
+type assignmentRepoStub struct {
+	caregivers   []domain.Caregiver
+	availability []domain.CaregiverAvailability
+	committed    []domain.CommittedVisit
+	continuity   []domain.ClientContinuity
+	saved        *domain.AssignmentProposal
+	applied      bool
+}
+
+func (a *assignmentRepoStub) ListCaregivers(ctx context.Context) ([]domain.Caregiver, error) {
+	return a.caregivers, nil
+}
+
+func (a *assignmentRepoStub) ListAvailability(ctx context.Context) ([]domain.CaregiverAvailability, error) {
+	return a.availability, nil
+}
+
+func (a *assignmentRepoStub) ListCommittedVisits(ctx context.Context, from, to time.Time) ([]domain.CommittedVisit, error) {
+	return a.committed, nil
+}
+
+func (a *assignmentRepoStub) ListContinuity(ctx context.Context, clientIDs []string) ([]domain.ClientContinuity, error) {
+	return a.continuity, nil
+}
+
+func (a *assignmentRepoStub) SaveProposal(ctx context.Context, proposal domain.AssignmentProposal) (string, error) {
+	proposal.ID = "proposal-1"
+	a.saved = &proposal
+	return proposal.ID, nil
+}
+
+func (a *assignmentRepoStub) GetProposal(ctx context.Context, proposalID string) (domain.AssignmentProposal, error) {
+	if a.saved == nil || a.saved.ID != proposalID {
+		return domain.AssignmentProposal{}, domain.ErrNotFound
+	}
+	return *a.saved, nil
+}
+
+func (a *assignmentRepoStub) ApplyProposal(ctx context.Context, proposalID string, appliedAt time.Time) error {
+	a.applied = true
+	a.saved.Status = domain.AssignmentProposalApplied
+	return nil
+}
+
+func weekdayAvailability(caregiverID string) []domain.CaregiverAvailability {
+	var windows []domain.CaregiverAvailability
+	for d := time.Monday; d <= time.Friday; d++ {
+		windows = append(windows, domain.CaregiverAvailability{CaregiverID: caregiverID, Weekday: d, StartMinute: 8 * 60, EndMinute: 18 * 60})
+	}
+	return windows
+}
+
+func TestAssignmentUsecaseProposePrefersContinuityAndRespectsConstraints(t *testing.T) {
+	// Wednesday 2025-01-15.
+	now := time.Date(2025, 1, 15, 6, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	openShifts := &openShiftRepoStub{shifts: []domain.OpenShift{
+		{ScheduleID: "visit-1", ClientID: "client-1", StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour), Latitude: 44.97, Longitude: -93.26},
+		{ScheduleID: "visit-2", ClientID: "client-2", StartTime: day.Add(10 * time.Hour), EndTime: day.Add(11 * time.Hour), Latitude: 44.98, Longitude: -93.27, RequiredQualifications: []string{"medication"}},
+		{ScheduleID: "visit-3", ClientID: "client-3", StartTime: day.Add(20 * time.Hour), EndTime: day.Add(21 * time.Hour), Latitude: 44.98, Longitude: -93.27},
+	}}
+	repo := &assignmentRepoStub{
+		caregivers: []domain.Caregiver{
+			{ID: "cg-a", Name: "Alice", Qualifications: []string{"medication"}},
+			{ID: "cg-b", Name: "Bob"},
+		},
+		availability: append(weekdayAvailability("cg-a"), weekdayAvailability("cg-b")...),
+		continuity:   []domain.ClientContinuity{{ClientID: "client-1", CaregiverID: "cg-b", Visits: 3}},
+	}
+
+	uc := NewAssignmentUsecase(repo, openShifts, time.UTC)
+	uc.WithNow(func() time.Time { return now })
+
+	proposal, err := uc.ProposeAssignments(context.Background(), "coordinator-1", day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proposal.ID != "proposal-1" || repo.saved == nil {
+		t.Fatalf("expected proposal to be saved")
+	}
+
+	got := map[string]string{}
+	for _, a := range proposal.Assignments {
+		got[a.ScheduleID] = a.CaregiverID
+		if len(a.Reasons) == 0 {
+			t.Fatalf("expected reasons for %s", a.ScheduleID)
+		}
+	}
+	if got["visit-1"] != "cg-b" {
+		t.Fatalf("expected continuity to favour cg-b for visit-1, got %q", got["visit-1"])
+	}
+	if got["visit-2"] != "cg-a" {
+		t.Fatalf("expected only qualified cg-a for visit-2, got %q", got["visit-2"])
+	}
+	if len(proposal.Unassigned) != 1 || proposal.Unassigned[0].ScheduleID != "visit-3" {
+		t.Fatalf("expected evening visit-3 unassigned, got %+v", proposal.Unassigned)
+	}
+	if !strings.Contains(strings.Join(proposal.Unassigned[0].Reasons, ";"), "not available") {
+		t.Fatalf("expected availability reason, got %v", proposal.Unassigned[0].Reasons)
+	}
+}
+
+func TestAssignmentSolverRejectsUnreachableConsecutiveVisits(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	in := assignmentInput{
+		visits: []domain.OpenShift{
+			// Roughly 100 km from the committed visit with only 15 minutes between them.
+			{ScheduleID: "visit-far", ClientID: "client-2", StartTime: day.Add(10*time.Hour + 15*time.Minute), EndTime: day.Add(11 * time.Hour), Latitude: 45.87, Longitude: -93.26},
+		},
+		caregivers:   []domain.Caregiver{{ID: "cg-a"}},
+		availability: map[string][]domain.CaregiverAvailability{"cg-a": weekdayAvailability("cg-a")},
+		committed: map[string][]domain.CommittedVisit{"cg-a": {
+			{ScheduleID: "booked", CaregiverID: "cg-a", StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour), Latitude: 44.97, Longitude: -93.26},
+		}},
+		loc: time.UTC,
+	}
+
+	assigned, unassigned := solveAssignments(in, defaultAssignmentWeights)
+	if len(assigned) != 0 {
+		t.Fatalf("expected no assignment, got %+v", assigned)
+	}
+	if len(unassigned) != 1 || !strings.Contains(unassigned[0].Reasons[0], "cannot travel") {
+		t.Fatalf("expected travel rejection, got %+v", unassigned)
+	}
+}
+
+func TestAssignmentUsecaseApplyProposalOnlyOnce(t *testing.T) {
+	repo := &assignmentRepoStub{saved: &domain.AssignmentProposal{ID: "proposal-1", Status: domain.AssignmentProposalDraft}}
+	uc := NewAssignmentUsecase(repo, &openShiftRepoStub{}, time.UTC)
+
+	applied, err := uc.ApplyProposal(context.Background(), "proposal-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied.Status != domain.AssignmentProposalApplied || applied.AppliedAt == nil || !repo.applied {
+		t.Fatalf("expected proposal to be applied, got %+v", applied)
+	}
+
+	if _, err := uc.ApplyProposal(context.Background(), "proposal-1"); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Fatalf("expected invalid transition on second apply, got %v", err)
+	}
+}
+
+func TestAssignmentUsecaseRejectsOversizedRange(t *testing.T) {
+	uc := NewAssignmentUsecase(&assignmentRepoStub{}, &openShiftRepoStub{}, time.UTC)
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := uc.ProposeAssignments(context.Background(), "coordinator-1", from, from.AddDate(0, 2, 0))
+	if !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure, got %v", err)
+	}
+}
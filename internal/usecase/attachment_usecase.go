@@ -0,0 +1,372 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+const (
+	// DefaultAttachmentMaxBytes bounds an uploaded file.
+	DefaultAttachmentMaxBytes = 10 << 20
+	// DefaultAttachmentURLTTL is how long a download link stays valid.
+	DefaultAttachmentURLTTL = 15 * time.Minute
+
+	maxAttachmentFileName = 255
+	maxAttachmentCaption  = 500
+)
+
+// BlobStore keeps attachment files. Open returns domain.ErrNotFound for a
+// missing key.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentPolicy bounds uploads and signs download links.
+type AttachmentPolicy struct {
+	MaxBytes int64
+	URLTTL   time.Duration
+	// URLSecret keys the HMAC of download links.
+	URLSecret string
+	// BaseURL prefixes download links; empty leaves them relative to the API host.
+	BaseURL string
+}
+
+// AttachmentUpload is a file a caregiver attaches to a visit or one of its tasks.
+type AttachmentUpload struct {
+	ScheduleID string
+	TaskID     *string
+	FileName   string
+	Caption    *string
+	Data       []byte
+}
+
+// SignedURL is a time-limited download link.
+type SignedURL struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+// AttachmentView is an attachment with download links for the caregiver who
+// asked for it.
+type AttachmentView struct {
+	Attachment   domain.Attachment
+	URL          SignedURL
+	ThumbnailURL *SignedURL
+}
+
+// AttachmentLink is the grant carried by a signed download link.
+type AttachmentLink struct {
+	AttachmentID string
+	Variant      domain.AttachmentVariant
+	CaregiverID  string
+	Expires      int64
+	Signature    string
+}
+
+// AttachmentUsecase stores photos and documents for visits and serves them
+// through signed links that only work while the caregiver owns the visit.
+type AttachmentUsecase struct {
+	attachments repository.AttachmentRepository
+	schedules   repository.ScheduleRepository
+	tasks       repository.TaskRepository
+	store       BlobStore
+	policy      AttachmentPolicy
+	now         func() time.Time
+}
+
+// NewAttachmentUsecase constructs an AttachmentUsecase; zero limits in policy
+// take their defaults.
+func NewAttachmentUsecase(
+	attachments repository.AttachmentRepository,
+	schedules repository.ScheduleRepository,
+	tasks repository.TaskRepository,
+	store BlobStore,
+	policy AttachmentPolicy,
+) *AttachmentUsecase {
+	if policy.MaxBytes <= 0 {
+		policy.MaxBytes = DefaultAttachmentMaxBytes
+	}
+	if policy.URLTTL <= 0 {
+		policy.URLTTL = DefaultAttachmentURLTTL
+	}
+	policy.BaseURL = strings.TrimSuffix(policy.BaseURL, "/")
+	return &AttachmentUsecase{
+		attachments: attachments,
+		schedules:   schedules,
+		tasks:       tasks,
+		store:       store,
+		policy:      policy,
+		now:         time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *AttachmentUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// MaxUploadBytes is the largest file Upload accepts.
+func (uc *AttachmentUsecase) MaxUploadBytes() int64 {
+	return uc.policy.MaxBytes
+}
+
+// Upload checks the file, removes its metadata, renders a thumbnail for images
+// and stores both. Only the caregiver assigned to the visit may attach to it.
+func (uc *AttachmentUsecase) Upload(ctx context.Context, caregiverID string, upload AttachmentUpload) (AttachmentView, error) {
+	if _, err := uc.schedules.GetScheduleForCaregiver(ctx, upload.ScheduleID, caregiverID); err != nil {
+		return AttachmentView{}, err
+	}
+	if upload.TaskID != nil {
+		if err := uc.requireTask(ctx, upload.ScheduleID, *upload.TaskID); err != nil {
+			return AttachmentView{}, err
+		}
+	}
+	if len(upload.Data) == 0 || int64(len(upload.Data)) > uc.policy.MaxBytes {
+		return AttachmentView{}, domain.ErrValidationFailure
+	}
+	caption, ok := attachmentCaption(upload.Caption)
+	if !ok {
+		return AttachmentView{}, domain.ErrValidationFailure
+	}
+	contentType := http.DetectContentType(upload.Data)
+	if !domain.AttachmentContentTypes[contentType] {
+		return AttachmentView{}, domain.ErrValidationFailure
+	}
+
+	data, err := stripMetadata(contentType, upload.Data)
+	if err != nil {
+		return AttachmentView{}, mediaError(err)
+	}
+	var thumbnail []byte
+	if strings.HasPrefix(contentType, "image/") {
+		if thumbnail, err = makeThumbnail(data); err != nil {
+			return AttachmentView{}, mediaError(err)
+		}
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return AttachmentView{}, err
+	}
+	sum := sha256.Sum256(data)
+	attachment := domain.Attachment{
+		ID:          id,
+		ScheduleID:  upload.ScheduleID,
+		TaskID:      upload.TaskID,
+		UploadedBy:  caregiverID,
+		FileName:    attachmentFileName(upload.FileName, contentType),
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		StorageKey:  "attachments/" + upload.ScheduleID + "/" + id + attachmentExtension(contentType),
+		Caption:     caption,
+		CreatedAt:   uc.now().UTC(),
+	}
+
+	if err := uc.store.Put(ctx, attachment.StorageKey, contentType, data); err != nil {
+		return AttachmentView{}, err
+	}
+	if thumbnail != nil {
+		key := "attachments/" + upload.ScheduleID + "/" + id + "-thumb.jpg"
+		if err := uc.store.Put(ctx, key, "image/jpeg", thumbnail); err != nil {
+			uc.discard(ctx, attachment)
+			return AttachmentView{}, err
+		}
+		attachment.ThumbnailKey = &key
+	}
+	saved, err := uc.attachments.Create(ctx, attachment)
+	if err != nil {
+		uc.discard(ctx, attachment)
+		return AttachmentView{}, err
+	}
+	return uc.view(saved, caregiverID), nil
+}
+
+// List returns a visit's attachments with fresh download links.
+func (uc *AttachmentUsecase) List(ctx context.Context, caregiverID, scheduleID string) ([]AttachmentView, error) {
+	if _, err := uc.schedules.GetScheduleForCaregiver(ctx, scheduleID, caregiverID); err != nil {
+		return nil, err
+	}
+	attachments, err := uc.attachments.ListBySchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]AttachmentView, len(attachments))
+	for i, attachment := range attachments {
+		views[i] = uc.view(attachment, caregiverID)
+	}
+	return views, nil
+}
+
+// SignURL issues a download link for one variant of an attachment on a visit
+// the caregiver owns.
+func (uc *AttachmentUsecase) SignURL(ctx context.Context, caregiverID, attachmentID string, variant domain.AttachmentVariant) (SignedURL, error) {
+	if !variant.Valid() {
+		return SignedURL{}, domain.ErrValidationFailure
+	}
+	attachment, err := uc.attachments.Get(ctx, attachmentID)
+	if err != nil {
+		return SignedURL{}, err
+	}
+	if _, err := uc.schedules.GetScheduleForCaregiver(ctx, attachment.ScheduleID, caregiverID); err != nil {
+		return SignedURL{}, err
+	}
+	if attachment.Key(variant) == "" {
+		return SignedURL{}, domain.ErrNotFound
+	}
+	return uc.sign(attachment.ID, variant, caregiverID), nil
+}
+
+// Open serves the file behind a signed link. The link must be unexpired and
+// its caregiver must still be assigned to the visit; otherwise
+// domain.ErrForbidden is returned.
+func (uc *AttachmentUsecase) Open(ctx context.Context, link AttachmentLink) (domain.Attachment, io.ReadCloser, error) {
+	if !link.Variant.Valid() || link.Expires < uc.now().Unix() {
+		return domain.Attachment{}, nil, domain.ErrForbidden
+	}
+	want := uc.signature(link.AttachmentID, link.Variant, link.CaregiverID, link.Expires)
+	if !hmac.Equal([]byte(want), []byte(link.Signature)) {
+		return domain.Attachment{}, nil, domain.ErrForbidden
+	}
+	attachment, err := uc.attachments.Get(ctx, link.AttachmentID)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	if _, err := uc.schedules.GetScheduleForCaregiver(ctx, attachment.ScheduleID, link.CaregiverID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Attachment{}, nil, domain.ErrForbidden
+		}
+		return domain.Attachment{}, nil, err
+	}
+	key := attachment.Key(link.Variant)
+	if key == "" {
+		return domain.Attachment{}, nil, domain.ErrNotFound
+	}
+	body, err := uc.store.Open(ctx, key)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	return attachment, body, nil
+}
+
+func (uc *AttachmentUsecase) requireTask(ctx context.Context, scheduleID, taskID string) error {
+	tasks, err := uc.tasks.ListBySchedule(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.ID == taskID {
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// discard removes the files of an attachment that could not be recorded.
+func (uc *AttachmentUsecase) discard(ctx context.Context, attachment domain.Attachment) {
+	_ = uc.store.Delete(ctx, attachment.StorageKey)
+	if attachment.ThumbnailKey != nil {
+		_ = uc.store.Delete(ctx, *attachment.ThumbnailKey)
+	}
+}
+
+func (uc *AttachmentUsecase) view(attachment domain.Attachment, caregiverID string) AttachmentView {
+	view := AttachmentView{
+		Attachment: attachment,
+		URL:        uc.sign(attachment.ID, domain.AttachmentOriginal, caregiverID),
+	}
+	if attachment.ThumbnailKey != nil {
+		thumbnail := uc.sign(attachment.ID, domain.AttachmentThumbnail, caregiverID)
+		view.ThumbnailURL = &thumbnail
+	}
+	return view
+}
+
+func (uc *AttachmentUsecase) sign(attachmentID string, variant domain.AttachmentVariant, caregiverID string) SignedURL {
+	expiresAt := uc.now().Add(uc.policy.URLTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	query := url.Values{}
+	query.Set("variant", string(variant))
+	query.Set("caregiver", caregiverID)
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", uc.signature(attachmentID, variant, caregiverID, expires))
+	return SignedURL{
+		URL:       fmt.Sprintf("%s/api/attachments/%s/content?%s", uc.policy.BaseURL, url.PathEscape(attachmentID), query.Encode()),
+		ExpiresAt: expiresAt.UTC(),
+	}
+}
+
+func (uc *AttachmentUsecase) signature(attachmentID string, variant domain.AttachmentVariant, caregiverID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(uc.policy.URLSecret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d", attachmentID, variant, caregiverID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// mediaError reports files that are not the image they claim to be as
+// invalid input.
+func mediaError(err error) error {
+	if errors.Is(err, errBadImage) {
+		return domain.ErrValidationFailure
+	}
+	return err
+}
+
+func attachmentCaption(caption *string) (*string, bool) {
+	if caption == nil {
+		return nil, true
+	}
+	trimmed := strings.TrimSpace(*caption)
+	if trimmed == "" {
+		return nil, true
+	}
+	return &trimmed, utf8.RuneCountInString(trimmed) <= maxAttachmentCaption
+}
+
+// attachmentFileName keeps the base name the client sent, without any
+// directories, or names the file after its type.
+func attachmentFileName(name, contentType string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment" + attachmentExtension(contentType)
+	}
+	if len(name) > maxAttachmentFileName {
+		cut := maxAttachmentFileName
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut]
+	}
+	return name
+}
+
+func attachmentExtension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "application/pdf":
+		return ".pdf"
+	default:
+		return ""
+	}
+}
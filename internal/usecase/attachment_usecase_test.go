@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var (
+	_ repository.AttachmentRepository = (*attachmentRepoStub)(nil)
+	_ BlobStore                       = (*blobStoreStub)(nil)
+)
+
+type attachmentRepoStub struct {
+	attachments []domain.Attachment
+}
+
+func (r *attachmentRepoStub) Create(ctx context.Context, attachment domain.Attachment) (domain.Attachment, error) {
+	r.attachments = append(r.attachments, attachment)
+	return attachment, nil
+}
+
+func (r *attachmentRepoStub) Get(ctx context.Context, attachmentID string) (domain.Attachment, error) {
+	for _, a := range r.attachments {
+		if a.ID == attachmentID {
+			return a, nil
+		}
+	}
+	return domain.Attachment{}, domain.ErrNotFound
+}
+
+func (r *attachmentRepoStub) ListBySchedule(ctx context.Context, scheduleID string) ([]domain.Attachment, error) {
+	var result []domain.Attachment
+	for _, a := range r.attachments {
+		if a.ScheduleID == scheduleID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+type blobStoreStub struct {
+	blobs map[string][]byte
+}
+
+func (s *blobStoreStub) Put(ctx context.Context, key, contentType string, data []byte) error {
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *blobStoreStub) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *blobStoreStub) Delete(ctx context.Context, key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+func newAttachmentFixture(now time.Time) (*AttachmentUsecase, *scheduleRepoStub, *attachmentRepoStub, *blobStoreStub) {
+	schedules := &scheduleRepoStub{schedule: domain.Schedule{ID: "sched-1", CaregiverID: "cg-1"}}
+	tasks := &taskRepoStub{tasks: map[string][]domain.Task{"sched-1": {{ID: "task-1", Title: "Wound care"}}}}
+	repo := &attachmentRepoStub{}
+	store := &blobStoreStub{blobs: map[string][]byte{}}
+	uc := NewAttachmentUsecase(repo, schedules, tasks, store, AttachmentPolicy{MaxBytes: 1 << 20, URLSecret: "secret"})
+	uc.WithNow(func() time.Time { return now })
+	return uc, schedules, repo, store
+}
+
+// jpegWithExif encodes a w×h JPEG carrying an EXIF block with the given
+// orientation and a GPS pointer.
+func jpegWithExif(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+	var plain bytes.Buffer
+	if err := jpeg.Encode(&plain, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	ifd := make([]byte, 2+2*12+4)
+	binary.LittleEndian.PutUint16(ifd[0:], 2)
+	binary.LittleEndian.PutUint16(ifd[2:], 0x0112)
+	binary.LittleEndian.PutUint16(ifd[4:], 3)
+	binary.LittleEndian.PutUint32(ifd[6:], 1)
+	binary.LittleEndian.PutUint16(ifd[10:], orientation)
+	binary.LittleEndian.PutUint16(ifd[14:], 0x8825) // GPS IFD pointer
+	binary.LittleEndian.PutUint16(ifd[16:], 4)
+	binary.LittleEndian.PutUint32(ifd[18:], 1)
+	payload := append([]byte("Exif\x00\x00"), append(tiff, ifd...)...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	data := plain.Bytes()
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func TestAttachmentUsecaseUploadStripsExifAndRotates(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	uc, _, repo, store := newAttachmentFixture(now)
+	taskID := "task-1"
+	caption := "  Dressing changed  "
+
+	view, err := uc.Upload(context.Background(), "cg-1", AttachmentUpload{
+		ScheduleID: "sched-1",
+		TaskID:     &taskID,
+		FileName:   `C:\photos\wound.jpg`,
+		Caption:    &caption,
+		Data:       jpegWithExif(t, 400, 200, 6),
+	})
+	if err != nil {
+		t.Fatalf("Upload error: %v", err)
+	}
+	a := view.Attachment
+	if a.ContentType != "image/jpeg" || a.FileName != "wound.jpg" || *a.Caption != "Dressing changed" || *a.TaskID != "task-1" {
+		t.Fatalf("unexpected attachment: %+v", a)
+	}
+	if len(repo.attachments) != 1 || a.ThumbnailKey == nil {
+		t.Fatalf("expected a recorded attachment with a thumbnail, got %+v", repo.attachments)
+	}
+
+	stored := store.blobs[a.StorageKey]
+	if bytes.Contains(stored, []byte("Exif\x00\x00")) {
+		t.Fatalf("expected exif removed from the stored file")
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(stored))
+	if err != nil {
+		t.Fatalf("stored file is not a jpeg: %v", err)
+	}
+	if cfg.Width != 200 || cfg.Height != 400 {
+		t.Fatalf("expected the photo turned upright to 200x400, got %dx%d", cfg.Width, cfg.Height)
+	}
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(store.blobs[*a.ThumbnailKey]))
+	if err != nil {
+		t.Fatalf("thumbnail is not a jpeg: %v", err)
+	}
+	if thumb.Width != 160 || thumb.Height != 320 {
+		t.Fatalf("expected a 160x320 thumbnail, got %dx%d", thumb.Width, thumb.Height)
+	}
+	if view.ThumbnailURL == nil || !view.URL.ExpiresAt.Equal(now.Add(DefaultAttachmentURLTTL)) {
+		t.Fatalf("expected signed links valid for the default ttl, got %+v", view)
+	}
+}
+
+func TestAttachmentUsecaseUploadStripsPNGText(t *testing.T) {
+	uc, _, _, store := newAttachmentFixture(time.Now())
+	var buf bytes.Buffer
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255})
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	data := buf.Bytes()
+	// Insert a tEXt chunk right after IHDR (8-byte signature + 25-byte IHDR).
+	text := []byte("Comment\x00taken at 12 Main St")
+	chunk := make([]byte, 8, 12+len(text))
+	binary.BigEndian.PutUint32(chunk, uint32(len(text)))
+	copy(chunk[4:], "tEXt")
+	chunk = append(chunk, text...)
+	chunk = append(chunk, 0, 0, 0, 0)
+	withText := append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+
+	view, err := uc.Upload(context.Background(), "cg-1", AttachmentUpload{ScheduleID: "sched-1", FileName: "meal.png", Data: withText})
+	if err != nil {
+		t.Fatalf("Upload error: %v", err)
+	}
+	stored := store.blobs[view.Attachment.StorageKey]
+	if bytes.Contains(stored, []byte("Main St")) {
+		t.Fatalf("expected png text metadata removed")
+	}
+	if _, err := png.Decode(bytes.NewReader(stored)); err != nil {
+		t.Fatalf("stored png no longer decodes: %v", err)
+	}
+}
+
+func TestAttachmentUsecaseUploadValidation(t *testing.T) {
+	uc, _, repo, _ := newAttachmentFixture(time.Now())
+	ctx := context.Background()
+	otherTask := "task-9"
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n")
+
+	view, err := uc.Upload(ctx, "cg-1", AttachmentUpload{ScheduleID: "sched-1", FileName: "care-plan.pdf", Data: pdf})
+	if err != nil {
+		t.Fatalf("Upload error: %v", err)
+	}
+	if view.Attachment.ContentType != "application/pdf" || view.ThumbnailURL != nil {
+		t.Fatalf("expected a pdf without thumbnail, got %+v", view)
+	}
+
+	cases := []struct {
+		name        string
+		caregiverID string
+		upload      AttachmentUpload
+		want        error
+	}{
+		{"not the caregiver's visit", "cg-2", AttachmentUpload{ScheduleID: "sched-1", Data: pdf}, domain.ErrNotFound},
+		{"task on another visit", "cg-1", AttachmentUpload{ScheduleID: "sched-1", TaskID: &otherTask, Data: pdf}, domain.ErrNotFound},
+		{"empty file", "cg-1", AttachmentUpload{ScheduleID: "sched-1"}, domain.ErrValidationFailure},
+		{"too large", "cg-1", AttachmentUpload{ScheduleID: "sched-1", Data: append(pdf, make([]byte, 1<<20)...)}, domain.ErrValidationFailure},
+		{"unsupported type", "cg-1", AttachmentUpload{ScheduleID: "sched-1", Data: []byte("<html><script>alert(1)</script>")}, domain.ErrValidationFailure},
+		{"truncated image", "cg-1", AttachmentUpload{ScheduleID: "sched-1", Data: []byte("\xFF\xD8\xFF\xE0\x00")}, domain.ErrValidationFailure},
+	}
+	for _, tc := range cases {
+		if _, err := uc.Upload(ctx, tc.caregiverID, tc.upload); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if len(repo.attachments) != 1 {
+		t.Fatalf("expected only the pdf recorded, got %d", len(repo.attachments))
+	}
+}
+
+func linkFromURL(t *testing.T, signed SignedURL) AttachmentLink {
+	t.Helper()
+	u, err := url.Parse(signed.URL)
+	if err != nil {
+		t.Fatalf("invalid url %q: %v", signed.URL, err)
+	}
+	parts := strings.Split(u.Path, "/")
+	expires, _ := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	return AttachmentLink{
+		AttachmentID: parts[3],
+		Variant:      domain.AttachmentVariant(u.Query().Get("variant")),
+		CaregiverID:  u.Query().Get("caregiver"),
+		Expires:      expires,
+		Signature:    u.Query().Get("signature"),
+	}
+}
+
+func TestAttachmentUsecaseSignedLinks(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	uc, schedules, _, _ := newAttachmentFixture(now)
+	ctx := context.Background()
+	pdf := []byte("%PDF-1.4\n%%EOF\n")
+	view, err := uc.Upload(ctx, "cg-1", AttachmentUpload{ScheduleID: "sched-1", Data: pdf})
+	if err != nil {
+		t.Fatalf("Upload error: %v", err)
+	}
+	if !strings.HasPrefix(view.URL.URL, "/api/attachments/"+view.Attachment.ID+"/content?") {
+		t.Fatalf("unexpected link %q", view.URL.URL)
+	}
+
+	if _, err := uc.SignURL(ctx, "cg-2", view.Attachment.ID, domain.AttachmentOriginal); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected another caregiver refused a link, got %v", err)
+	}
+	if _, err := uc.SignURL(ctx, "cg-1", view.Attachment.ID, domain.AttachmentThumbnail); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected no thumbnail link for a pdf, got %v", err)
+	}
+
+	link := linkFromURL(t, view.URL)
+	_, body, err := uc.Open(ctx, link)
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	if !bytes.Equal(got, pdf) {
+		t.Fatalf("expected the stored file, got %q", got)
+	}
+
+	tampered := link
+	tampered.CaregiverID = "cg-2"
+	if _, _, err := uc.Open(ctx, tampered); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected a tampered link refused, got %v", err)
+	}
+
+	uc.WithNow(func() time.Time { return now.Add(DefaultAttachmentURLTTL + time.Second) })
+	if _, _, err := uc.Open(ctx, link); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected an expired link refused, got %v", err)
+	}
+
+	// Reassigning the visit revokes links issued to the previous caregiver.
+	uc.WithNow(func() time.Time { return now })
+	schedules.schedule.CaregiverID = "cg-3"
+	if _, _, err := uc.Open(ctx, link); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected a link for a reassigned visit refused, got %v", err)
+	}
+}
+
+func TestOrientRotatesClockwise(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	red := color.RGBA{R: 255, A: 255}
+	src.Set(0, 0, red)
+
+	out := orient(src, 6)
+	if out.Bounds().Dx() != 2 || out.Bounds().Dy() != 4 {
+		t.Fatalf("expected 2x4, got %v", out.Bounds())
+	}
+	if out.At(1, 0) != red {
+		t.Fatalf("expected the top-left pixel at the top-right after rotating clockwise")
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+var attendanceCSVHeader = []string{
+	"work_date", "clock_in_at", "clock_in_lat", "clock_in_long", "clock_in_notes",
+	"clock_out_at", "clock_out_lat", "clock_out_long", "clock_out_notes",
+	"worked_minutes", "break_minutes", "breaks", "missed_break",
+}
+
+// encodeAttendanceCSV renders attendance sessions as a CSV file, one session
+// per row, with times in the caregiver's timezone. Open sessions leave the
+// clock-out columns empty.
+func encodeAttendanceCSV(days []AttendanceDay, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(attendanceCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		date := day.Date.Format("2006-01-02")
+		for _, session := range day.Sessions {
+			row := []string{date}
+			row = append(row, logColumns(&session.ClockIn, loc)...)
+			row = append(row, logColumns(session.ClockOut, loc)...)
+			row = append(row,
+				strconv.Itoa(session.WorkedMins), strconv.Itoa(session.BreakMins),
+				strconv.Itoa(len(session.Breaks)), strconv.FormatBool(session.MissedBreak),
+			)
+			if err := w.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// logColumns returns the time, coordinates and notes of a log, or empty
+// columns when there is none.
+func logColumns(log *domain.CaregiverLog, loc *time.Location) []string {
+	if log == nil {
+		return []string{"", "", "", ""}
+	}
+	notes := ""
+	if log.Notes != nil {
+		notes = *log.Notes
+	}
+	return []string{
+		log.Timestamp.In(loc).Format(time.RFC3339),
+		strconv.FormatFloat(log.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(log.Longitude, 'f', -1, 64),
+		notes,
+	}
+}
@@ -24,10 +24,15 @@ type TokenRequest struct {
 	Scope        string
 }
 
+// familyTokenType marks family portal access tokens so they are never
+// mistaken for caregiver tokens.
+const familyTokenType = "family"
+
 // AuthUsecase encapsulates token issuing and verification logic.
 type AuthUsecase struct {
 	clients    repository.AuthRepository
 	caregivers repository.CaregiverRepository
+	family     repository.FamilyRepository
 	cfg        config.AuthConfig
 	now        func() time.Time
 }
@@ -46,6 +51,11 @@ func NewAuthUsecase(
 	}
 }
 
+// WithFamilyMembers enables family portal sign-in.
+func (uc *AuthUsecase) WithFamilyMembers(family repository.FamilyRepository) {
+	uc.family = family
+}
+
 // IssueToken handles a simplified client credentials flow returning JWT tokens.
 func (uc *AuthUsecase) IssueToken(ctx context.Context, req TokenRequest) (domain.TokenPair, domain.Caregiver, error) {
 	if strings.ToLower(req.GrantType) != "client_credentials" {
@@ -56,7 +66,7 @@ func (uc *AuthUsecase) IssueToken(ctx context.Context, req TokenRequest) (domain
 		return domain.TokenPair{}, domain.Caregiver{}, err
 	}
 
-	if !uc.verifySecret(client.SecretHash, req.ClientSecret) {
+	if !verifySecret(client.SecretHash, req.ClientSecret) {
 		return domain.TokenPair{}, domain.Caregiver{}, domain.ErrUnauthorized
 	}
 
@@ -65,9 +75,9 @@ func (uc *AuthUsecase) IssueToken(ctx context.Context, req TokenRequest) (domain
 		return domain.TokenPair{}, domain.Caregiver{}, err
 	}
 
-	scope := req.Scope
-	if scope == "" {
-		scope = strings.Join(client.Scopes, " ")
+	scope, err := grantScope(client, req.Scope)
+	if err != nil {
+		return domain.TokenPair{}, domain.Caregiver{}, err
 	}
 
 	issuedAt := uc.now()
@@ -93,7 +103,78 @@ func (uc *AuthUsecase) IssueToken(ctx context.Context, req TokenRequest) (domain
 	return pair, caregiver, nil
 }
 
-func (uc *AuthUsecase) verifySecret(hash, secret string) bool {
+// IssueFamilyToken signs a family member in with their email and password and
+// returns an access token valid only for the family portal.
+func (uc *AuthUsecase) IssueFamilyToken(ctx context.Context, email, password string) (domain.TokenPair, domain.FamilyMember, error) {
+	if uc.family == nil {
+		return domain.TokenPair{}, domain.FamilyMember{}, domain.ErrUnauthorized
+	}
+	if strings.TrimSpace(email) == "" || password == "" {
+		return domain.TokenPair{}, domain.FamilyMember{}, domain.ErrValidationFailure
+	}
+	member, err := uc.family.GetMemberByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.TokenPair{}, domain.FamilyMember{}, domain.ErrUnauthorized
+		}
+		return domain.TokenPair{}, domain.FamilyMember{}, err
+	}
+	if !member.Active || !verifySecret(member.SecretHash, password) {
+		return domain.TokenPair{}, domain.FamilyMember{}, domain.ErrUnauthorized
+	}
+
+	issuedAt := uc.now()
+	claims := jwt.MapClaims{
+		"iss":  uc.cfg.Issuer,
+		"sub":  member.ID,
+		"aud":  uc.cfg.FamilyAudience,
+		"iat":  issuedAt.Unix(),
+		"exp":  issuedAt.Add(uc.cfg.FamilyTokenTTL).Unix(),
+		"typ":  familyTokenType,
+		"cli":  member.ClientID,
+		"name": member.Name,
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(uc.cfg.AccessTokenSecret))
+	if err != nil {
+		return domain.TokenPair{}, domain.FamilyMember{}, err
+	}
+
+	pair := domain.TokenPair{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(uc.cfg.FamilyTokenTTL.Seconds()),
+		IssuedAt:    issuedAt,
+	}
+	return pair, member, nil
+}
+
+// grantScope returns the scope to sign into the client's tokens: the
+// requested scopes, which must all be registered for the client, or every
+// registered scope when none are requested.
+func grantScope(client domain.AuthClient, requested string) (string, error) {
+	fields := strings.Fields(requested)
+	if len(fields) == 0 {
+		return strings.Join(client.Scopes, " "), nil
+	}
+	allowed := make(map[string]bool, len(client.Scopes))
+	for _, s := range client.Scopes {
+		allowed[s] = true
+	}
+	granted := make([]string, 0, len(fields))
+	seen := map[string]bool{}
+	for _, s := range fields {
+		if !allowed[s] {
+			return "", domain.ErrInvalidScope
+		}
+		if !seen[s] {
+			seen[s] = true
+			granted = append(granted, s)
+		}
+	}
+	return strings.Join(granted, " "), nil
+}
+
+func verifySecret(hash, secret string) bool {
 	if strings.HasPrefix(hash, "bcrypt$") {
 		encoded := strings.TrimPrefix(hash, "bcrypt$")
 		data, err := base64.StdEncoding.DecodeString(encoded)
@@ -142,13 +223,42 @@ func (uc *AuthUsecase) buildIDToken(client domain.AuthClient, caregiver domain.C
 }
 
 // ParseToken validates the incoming bearer token and returns the caregiver ID.
+// Family portal tokens are refused.
 func (uc *AuthUsecase) ParseToken(tokenStr string) (jwt.MapClaims, error) {
+	claims, err := uc.parse(tokenStr, uc.cfg.Audience)
+	if err != nil {
+		return nil, err
+	}
+	if typ, _ := claims["typ"].(string); typ == familyTokenType {
+		return nil, domain.ErrUnauthorized
+	}
+	return claims, nil
+}
+
+// ParseFamilyToken validates a family portal bearer token; its subject is the
+// family member ID.
+func (uc *AuthUsecase) ParseFamilyToken(tokenStr string) (jwt.MapClaims, error) {
+	claims, err := uc.parse(tokenStr, uc.cfg.FamilyAudience)
+	if err != nil {
+		return nil, err
+	}
+	if typ, _ := claims["typ"].(string); typ != familyTokenType {
+		return nil, domain.ErrUnauthorized
+	}
+	return claims, nil
+}
+
+func (uc *AuthUsecase) parse(tokenStr, audience string) (jwt.MapClaims, error) {
 	if tokenStr == "" {
 		return nil, domain.ErrUnauthorized
 	}
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name})}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
 	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
 		return []byte(uc.cfg.AccessTokenSecret), nil
-	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}))
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,6 +272,15 @@ func (uc *AuthUsecase) ParseToken(tokenStr string) (jwt.MapClaims, error) {
 	return claims, nil
 }
 
+// hashSecret hashes a password or PIN in the form verifySecret accepts.
+func hashSecret(secret string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return "bcrypt$" + base64.StdEncoding.EncodeToString(hash), nil
+}
+
 func subtleConstantTimeCompare(a, b string) bool {
 	if len(a) != len(b) {
 		return false
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"testing"
 	"time"
 
@@ -33,6 +34,10 @@ func (c *caregiverRepoStub) GetByID(ctx context.Context, caregiverID string) (do
 	return c.caregiver, nil
 }
 
+func (c *caregiverRepoStub) GetProfile(ctx context.Context, caregiverID string) (domain.Caregiver, error) {
+	return c.GetByID(ctx, caregiverID)
+}
+
 func TestAuthUsecaseIssueToken(t *testing.T) {
 	hashBytes, err := bcryptGenerate("secret")
 	if err != nil {
@@ -86,6 +91,46 @@ func TestAuthUsecaseIssueToken(t *testing.T) {
 	}
 }
 
+func TestAuthUsecaseIssueTokenGrantsOnlyRegisteredScopes(t *testing.T) {
+	hashBytes, err := bcryptGenerate("secret")
+	if err != nil {
+		t.Fatalf("hash error: %v", err)
+	}
+	authRepo := &authRepoStub{client: domain.AuthClient{
+		ID:          "caregiver-app",
+		SecretHash:  hashBytes,
+		CaregiverID: "care-1",
+		Scopes:      []string{"schedules.read", "schedules.write"},
+	}}
+	careRepo := &caregiverRepoStub{caregiver: domain.Caregiver{ID: "care-1", Name: "Louis"}}
+	uc := NewAuthUsecase(config.AuthConfig{AccessTokenSecret: "access-secret", IDTokenSecret: "id-secret"}, authRepo, careRepo)
+
+	for _, scope := range []string{"supervisor", "schedules.read supervisor", "schedules.read webhooks.manage"} {
+		_, _, err := uc.IssueToken(context.Background(), TokenRequest{
+			GrantType:    "client_credentials",
+			ClientID:     "caregiver-app",
+			ClientSecret: "secret",
+			Scope:        scope,
+		})
+		if err != domain.ErrInvalidScope {
+			t.Fatalf("scope %q: expected invalid scope, got %v", scope, err)
+		}
+	}
+
+	pair, _, err := uc.IssueToken(context.Background(), TokenRequest{
+		GrantType:    "client_credentials",
+		ClientID:     "caregiver-app",
+		ClientSecret: "secret",
+		Scope:        "schedules.read schedules.read",
+	})
+	if err != nil {
+		t.Fatalf("issue token error: %v", err)
+	}
+	if pair.Scope != "schedules.read" {
+		t.Fatalf("expected only the requested scope, got %q", pair.Scope)
+	}
+}
+
 func bcryptGenerate(secret string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
 	if err != nil {
@@ -93,3 +138,61 @@ func bcryptGenerate(secret string) (string, error) {
 	}
 	return "bcrypt$" + base64.StdEncoding.EncodeToString(hash), nil
 }
+
+func TestAuthUsecaseFamilyTokens(t *testing.T) {
+	hash, err := bcryptGenerate("family-pass")
+	if err != nil {
+		t.Fatalf("hash error: %v", err)
+	}
+	family := &familyRepoStub{members: map[string]domain.FamilyMember{
+		"member-1": {ID: "member-1", ClientID: "client-1", Name: "Anna", Email: "anna@example.com", SecretHash: hash, Active: true},
+		"member-2": {ID: "member-2", ClientID: "client-1", Name: "Ben", Email: "ben@example.com", SecretHash: hash},
+	}}
+	client := domain.AuthClient{ID: "client-1", SecretHash: hash, CaregiverID: "care-1"}
+	cfg := config.AuthConfig{
+		Issuer:            "http://localhost:8080",
+		Audience:          "caregiver-app",
+		AccessTokenSecret: "access-secret",
+		IDTokenSecret:     "id-secret",
+		AccessTokenTTL:    time.Minute,
+		IDTokenTTL:        time.Hour,
+		FamilyAudience:    "family-portal",
+		FamilyTokenTTL:    time.Hour,
+	}
+	uc := NewAuthUsecase(cfg, &authRepoStub{client: client}, &caregiverRepoStub{caregiver: domain.Caregiver{ID: "care-1"}})
+	uc.WithFamilyMembers(family)
+
+	pair, member, err := uc.IssueFamilyToken(context.Background(), " anna@example.com ", "family-pass")
+	if err != nil {
+		t.Fatalf("issue family token error: %v", err)
+	}
+	if member.ID != "member-1" || pair.ExpiresIn != 3600 {
+		t.Fatalf("unexpected member %+v or pair %+v", member, pair)
+	}
+	claims, err := uc.ParseFamilyToken(pair.AccessToken)
+	if err != nil || claims["sub"] != "member-1" || claims["cli"] != "client-1" {
+		t.Fatalf("unexpected family claims %v, %v", claims, err)
+	}
+
+	// The two kinds of token are not interchangeable.
+	if _, err := uc.ParseToken(pair.AccessToken); err == nil {
+		t.Fatal("expected a family token to be refused as a caregiver token")
+	}
+	caregiverPair, _, err := uc.IssueToken(context.Background(), TokenRequest{GrantType: "client_credentials", ClientID: "client-1", ClientSecret: "family-pass"})
+	if err != nil {
+		t.Fatalf("issue token error: %v", err)
+	}
+	if _, err := uc.ParseFamilyToken(caregiverPair.AccessToken); err == nil {
+		t.Fatal("expected a caregiver token to be refused as a family token")
+	}
+
+	for _, attempt := range []struct{ email, password string }{
+		{"anna@example.com", "wrong"},
+		{"ben@example.com", "family-pass"},
+		{"nobody@example.com", "family-pass"},
+	} {
+		if _, _, err := uc.IssueFamilyToken(context.Background(), attempt.email, attempt.password); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Fatalf("expected %s to be refused, got %v", attempt.email, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
@@ -11,17 +12,43 @@ import (
 // CaregiverAttendanceUsecase orchestrates caregiver attendance operations.
 type CaregiverAttendanceUsecase struct {
 	caregiverLogs repository.CaregiverLogRepository
+	schedules     repository.ScheduleRepository
+	zones         *TimezoneResolver
+	overnight     domain.OvernightRule
+	breakAfter    time.Duration
+	events        EventPublisher
 	now           func() time.Time
 }
 
+// overnightLookback bounds how far back a shift that is still running (or
+// ended today) can have started.
+const overnightLookback = 24 * time.Hour
+
+// DefaultBreakRequiredAfter is the longest a caregiver may work without a break
+// before the session is flagged.
+const DefaultBreakRequiredAfter = 6 * time.Hour
+
 // NewCaregiverAttendanceUsecase creates a CaregiverAttendanceUsecase instance.
-func NewCaregiverAttendanceUsecase(caregiverLogs repository.CaregiverLogRepository) *CaregiverAttendanceUsecase {
+// schedules may be nil, in which case visit utilisation is not reported.
+func NewCaregiverAttendanceUsecase(caregiverLogs repository.CaregiverLogRepository, schedules repository.ScheduleRepository, zones *TimezoneResolver) *CaregiverAttendanceUsecase {
 	return &CaregiverAttendanceUsecase{
 		caregiverLogs: caregiverLogs,
+		schedules:     schedules,
+		zones:         zones,
+		overnight:     domain.OvernightRuleSplit,
+		breakAfter:    DefaultBreakRequiredAfter,
+		events:        noEvents{},
 		now:           time.Now,
 	}
 }
 
+// WithEvents publishes clock and break logs to events.
+func (uc *CaregiverAttendanceUsecase) WithEvents(events EventPublisher) {
+	if events != nil {
+		uc.events = events
+	}
+}
+
 // WithNow allows injecting a deterministic clock for testing.
 func (uc *CaregiverAttendanceUsecase) WithNow(now func() time.Time) {
 	if now != nil {
@@ -29,112 +56,349 @@ func (uc *CaregiverAttendanceUsecase) WithNow(now func() time.Time) {
 	}
 }
 
-// ClockIn records a clock-in event for the caregiver.
-func (uc *CaregiverAttendanceUsecase) ClockIn(ctx context.Context, caregiverID string, latitude, longitude float64, notes *string) (domain.CaregiverLog, error) {
-	// Check if already clocked in today
-	hasClockedIn, err := uc.caregiverLogs.HasClockedInToday(ctx, caregiverID)
-	if err != nil {
-		return domain.CaregiverLog{}, err
+// WithBreakRequiredAfter sets how long a caregiver may work without a break.
+func (uc *CaregiverAttendanceUsecase) WithBreakRequiredAfter(limit time.Duration) {
+	if limit > 0 {
+		uc.breakAfter = limit
 	}
-	if hasClockedIn {
-		return domain.CaregiverLog{}, domain.ErrValidationFailure
+}
+
+// WithOvernightRule sets how shifts crossing midnight count towards a day.
+func (uc *CaregiverAttendanceUsecase) WithOvernightRule(rule domain.OvernightRule) {
+	if rule.Valid() {
+		uc.overnight = rule
 	}
+}
 
-	log := domain.CaregiverLog{
+// ClockIn records a clock-in event for the caregiver.
+func (uc *CaregiverAttendanceUsecase) ClockIn(ctx context.Context, caregiverID string, latitude, longitude float64, notes *string) (domain.CaregiverLog, error) {
+	// A new session can start whenever the previous one has been closed.
+	return uc.record(ctx, domain.CaregiverLog{
 		CaregiverID: caregiverID,
 		LogType:     domain.LogTypeClockIn,
 		Latitude:    latitude,
 		Longitude:   longitude,
-		Timestamp:   uc.now(),
 		Notes:       notes,
-	}
-
-	id, err := uc.caregiverLogs.CreateLog(ctx, log)
-	if err != nil {
-		return domain.CaregiverLog{}, err
-	}
-
-	log.ID = id
-	return log, nil
+	})
 }
 
 // ClockOut records a clock-out event for the caregiver.
 func (uc *CaregiverAttendanceUsecase) ClockOut(ctx context.Context, caregiverID string, latitude, longitude float64, notes *string) (domain.CaregiverLog, error) {
-	// Check if clocked in today
-	hasClockedIn, err := uc.caregiverLogs.HasClockedInToday(ctx, caregiverID)
-	if err != nil {
-		return domain.CaregiverLog{}, err
-	}
-	if !hasClockedIn {
-		return domain.CaregiverLog{}, domain.ErrValidationFailure
-	}
+	// Clock-out closes the open session, which must not be on a break.
+	return uc.record(ctx, domain.CaregiverLog{
+		CaregiverID: caregiverID,
+		LogType:     domain.LogTypeClockOut,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Notes:       notes,
+	})
+}
 
-	// Check if already clocked out today
-	hasClockedOut, err := uc.caregiverLogs.HasClockedOutToday(ctx, caregiverID)
-	if err != nil {
-		return domain.CaregiverLog{}, err
-	}
-	if hasClockedOut {
+// StartBreak records the start of a meal or rest break within the open session.
+func (uc *CaregiverAttendanceUsecase) StartBreak(ctx context.Context, caregiverID string, breakType domain.BreakType, latitude, longitude float64, notes *string) (domain.CaregiverLog, error) {
+	if !breakType.Valid() {
 		return domain.CaregiverLog{}, domain.ErrValidationFailure
 	}
+	return uc.record(ctx, domain.CaregiverLog{
+		CaregiverID: caregiverID,
+		LogType:     domain.LogTypeBreakStart,
+		BreakType:   &breakType,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Notes:       notes,
+	})
+}
 
-	log := domain.CaregiverLog{
+// EndBreak records the end of the running break.
+func (uc *CaregiverAttendanceUsecase) EndBreak(ctx context.Context, caregiverID string, latitude, longitude float64, notes *string) (domain.CaregiverLog, error) {
+	return uc.record(ctx, domain.CaregiverLog{
 		CaregiverID: caregiverID,
-		LogType:     domain.LogTypeClockOut,
+		LogType:     domain.LogTypeBreakEnd,
 		Latitude:    latitude,
 		Longitude:   longitude,
-		Timestamp:   uc.now(),
 		Notes:       notes,
-	}
+	})
+}
 
-	id, err := uc.caregiverLogs.CreateLog(ctx, log)
+// GetTodayStatus returns today's attendance sessions for the caregiver. The
+// clock-in/clock-out fields describe the latest session. "Today" is the current
+// calendar day in the caregiver's timezone. A shift carried over from the
+// previous night is listed and its minutes split at midnight under
+// OvernightRuleSplit; under OvernightRuleStartDay it stays with the day it
+// started, although OnShift still reports it while it is open.
+func (uc *CaregiverAttendanceUsecase) GetTodayStatus(ctx context.Context, caregiverID string) (TodayAttendanceStatus, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
 	if err != nil {
-		return domain.CaregiverLog{}, err
+		return TodayAttendanceStatus{}, err
 	}
 
-	log.ID = id
-	return log, nil
-}
-
-// GetTodayStatus returns today's attendance status for the caregiver.
-func (uc *CaregiverAttendanceUsecase) GetTodayStatus(ctx context.Context, caregiverID string) (TodayAttendanceStatus, error) {
-	todayLogs, err := uc.caregiverLogs.GetTodayLogs(ctx, caregiverID)
+	now := uc.now()
+	start, end := domain.DayBounds(now, loc)
+	// Read from the latest clock-in when it is older than the lookback, so a
+	// shift left open for days is still seen as open, as CreateLog sees it.
+	from := start.Add(-overnightLookback)
+	lastClockIn, err := uc.caregiverLogs.GetLastClockIn(ctx, caregiverID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return TodayAttendanceStatus{}, err
+	}
+	if err == nil && lastClockIn != nil && lastClockIn.Timestamp.Before(from) {
+		from = lastClockIn.Timestamp
+	}
+	logs, err := uc.caregiverLogs.GetLogsBetween(ctx, caregiverID, from, end)
 	if err != nil {
 		return TodayAttendanceStatus{}, err
 	}
 
 	status := TodayAttendanceStatus{
 		CaregiverID: caregiverID,
-		Date:        time.Date(uc.now().Year(), uc.now().Month(), uc.now().Day(), 0, 0, 0, 0, uc.now().Location()),
-		HasClockedIn: false,
-		HasClockedOut: false,
-	}
-
-	for _, log := range todayLogs {
-		if log.LogType == domain.LogTypeClockIn {
-			status.HasClockedIn = true
-			status.ClockInAt = &log.Timestamp
-			status.ClockInLat = &log.Latitude
-			status.ClockInLong = &log.Longitude
-		} else if log.LogType == domain.LogTypeClockOut {
+		Date:        start,
+		AsOf:        now,
+	}
+
+	all := domain.PairSessions(logs)
+	var today []domain.AttendanceSession
+	for _, session := range all {
+		if !session.ClockIn.Timestamp.Before(start) {
+			today = append(today, session)
+			continue
+		}
+		// Started before today: only relevant if it ran past midnight.
+		if uc.overnight == domain.OvernightRuleSplit && (session.Open() || session.ClockOut.Timestamp.After(start)) {
+			today = append(today, session)
+		}
+	}
+
+	for _, session := range today {
+		if uc.overnight == domain.OvernightRuleSplit {
+			status.TotalWorkedMinutes += session.MinutesWithin(start, end, now)
+		} else {
+			status.TotalWorkedMinutes += session.WorkedMinutes(now)
+		}
+		status.TotalBreakMinutes += session.BreakMinutes(now)
+
+		summary := uc.summarise(session, now)
+		if summary.MissedBreak {
+			status.MissedBreaks++
+		}
+		status.Sessions = append(status.Sessions, summary)
+	}
+	if n := len(all); n > 0 && all[n-1].Open() {
+		status.OnShift = true
+		status.OnBreak = all[n-1].OnBreak()
+	}
+
+	if uc.schedules != nil && status.TotalWorkedMinutes > 0 {
+		if err := uc.fillUtilisation(ctx, &status, today, start, end); err != nil {
+			return TodayAttendanceStatus{}, err
+		}
+	}
+
+	if n := len(status.Sessions); n > 0 {
+		latest := status.Sessions[n-1]
+		status.HasClockedIn = true
+		status.ClockInAt = &latest.ClockIn.Timestamp
+		status.ClockInLat = &latest.ClockIn.Latitude
+		status.ClockInLong = &latest.ClockIn.Longitude
+		if latest.ClockOut != nil {
 			status.HasClockedOut = true
-			status.ClockOutAt = &log.Timestamp
-			status.ClockOutLat = &log.Latitude
-			status.ClockOutLong = &log.Longitude
+			status.ClockOutAt = &latest.ClockOut.Timestamp
+			status.ClockOutLat = &latest.ClockOut.Latitude
+			status.ClockOutLong = &latest.ClockOut.Longitude
 		}
 	}
 
 	return status, nil
 }
 
-// GetAttendanceHistory retrieves attendance history for a caregiver.
-func (uc *CaregiverAttendanceUsecase) GetAttendanceHistory(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]domain.CaregiverLogSummary, error) {
+// GetAttendanceHistory retrieves attendance history for a caregiver, newest
+// first. StartDate and EndDate are inclusive calendar dates in the caregiver's
+// timezone; the page is continued by filter.After or Offset.
+func (uc *CaregiverAttendanceUsecase) GetAttendanceHistory(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]domain.CaregiverLogSummary, PageInfo, error) {
+	filter, _, err := uc.historyRange(ctx, caregiverID, filter)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+
 	// Set default limit if not provided
 	if filter.Limit == 0 {
 		filter.Limit = 100 // Default to last 100 logs
 	}
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	logs, err := uc.caregiverLogs.GetLogsByCaregiver(ctx, caregiverID, filter)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	n, hasMore := pageCut(len(logs), limit)
+	logs = logs[:n]
+	if !hasMore {
+		return logs, PageInfo{}, nil
+	}
+	last := logs[n-1]
+	return logs, nextPage(true, domain.Cursor{Time: last.Timestamp, ID: last.ID}), nil
+}
+
+// CountAttendanceHistory counts the logs GetAttendanceHistory pages through.
+func (uc *CaregiverAttendanceUsecase) CountAttendanceHistory(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) (int, error) {
+	filter, _, err := uc.historyRange(ctx, caregiverID, filter)
+	if err != nil {
+		return 0, err
+	}
+	return uc.caregiverLogs.CountLogs(ctx, caregiverID, filter)
+}
+
+// DefaultHistorySessions and MaxHistorySessions bound a page of the detailed
+// attendance history.
+const (
+	DefaultHistorySessions = 20
+	MaxHistorySessions     = 100
+)
+
+// maxExportDays bounds the date range of an attendance export.
+const maxExportDays = 366
+
+// AttendanceHistoryPage is one page of shift sessions, newest first, grouped
+// by the day they started on. Totals cover the sessions on the page.
+type AttendanceHistoryPage struct {
+	PageInfo
+	Days          []AttendanceDay
+	TotalSessions int
+	WorkedMinutes int
+	BreakMinutes  int
+	AsOf          time.Time
+}
+
+// AttendanceDay holds the sessions that started on Date in the caregiver's timezone.
+type AttendanceDay struct {
+	Date          time.Time
+	Sessions      []SessionSummary
+	WorkedMinutes int
+	BreakMinutes  int
+}
+
+// GetDetailedHistory returns full shift sessions for the caregiver, paged by
+// clock-in with filter.Limit and filter.After. StartDate and EndDate select
+// sessions by the calendar day they started on; LogType and Offset are ignored.
+func (uc *CaregiverAttendanceUsecase) GetDetailedHistory(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) (AttendanceHistoryPage, error) {
+	filter, loc, err := uc.historyRange(ctx, caregiverID, filter)
+	if err != nil {
+		return AttendanceHistoryPage{}, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistorySessions
+	}
+	if filter.Limit > MaxHistorySessions {
+		filter.Limit = MaxHistorySessions
+	}
+	limit := filter.Limit
+
+	clockIn := domain.LogTypeClockIn
+	filter.LogType = &clockIn
+	filter.Offset = 0
+
+	page := AttendanceHistoryPage{AsOf: uc.now()}
+	page.TotalSessions, err = uc.caregiverLogs.CountLogs(ctx, caregiverID, filter)
+	if err != nil {
+		return AttendanceHistoryPage{}, err
+	}
+
+	// One extra row tells whether another page follows.
+	filter.Limit = limit + 1
+	starts, err := uc.caregiverLogs.ListLogs(ctx, caregiverID, filter)
+	if err != nil {
+		return AttendanceHistoryPage{}, err
+	}
+	n, hasMore := pageCut(len(starts), limit)
+	starts = starts[:n]
+	if n == 0 {
+		return page, nil
+	}
+	last := starts[n-1]
+	page.PageInfo = nextPage(hasMore, domain.Cursor{Time: last.Timestamp, ID: last.ID})
+
+	logs, err := uc.caregiverLogs.GetSessionLogs(ctx, caregiverID, last.Timestamp, starts[0].Timestamp)
+	if err != nil {
+		return AttendanceHistoryPage{}, err
+	}
+	sessions := domain.PairSessions(logs)
+	// Newest first, like the clock-ins the page was cut from.
+	for i, j := 0, len(sessions)-1; i < j; i, j = i+1, j-1 {
+		sessions[i], sessions[j] = sessions[j], sessions[i]
+	}
+	page.Days = uc.groupByDay(sessions, loc, page.AsOf)
+	for _, day := range page.Days {
+		page.WorkedMinutes += day.WorkedMinutes
+		page.BreakMinutes += day.BreakMinutes
+	}
+	return page, nil
+}
+
+// ExportAttendanceHistory renders every session that started between the
+// inclusive StartDate and EndDate as CSV, oldest first. Both dates are required.
+func (uc *CaregiverAttendanceUsecase) ExportAttendanceHistory(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]byte, error) {
+	if filter.StartDate == nil || filter.EndDate == nil || filter.EndDate.Before(*filter.StartDate) {
+		return nil, domain.ErrValidationFailure
+	}
+	if filter.EndDate.Sub(*filter.StartDate) >= maxExportDays*24*time.Hour {
+		return nil, domain.ErrValidationFailure
+	}
+	filter, loc, err := uc.historyRange(ctx, caregiverID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := uc.caregiverLogs.GetSessionLogs(ctx, caregiverID, *filter.StartDate, filter.EndDate.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+	sessions := domain.PairSessions(logs)
+	return encodeAttendanceCSV(uc.groupByDay(sessions, loc, uc.now()), loc)
+}
+
+// historyRange resolves the caregiver's timezone and turns the filter's
+// inclusive calendar dates into [StartDate, EndDate) instants in it.
+func (uc *CaregiverAttendanceUsecase) historyRange(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) (repository.CaregiverLogFilter, *time.Location, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return filter, nil, err
+	}
+	if filter.StartDate != nil {
+		start := domain.CalendarDay(*filter.StartDate, loc)
+		filter.StartDate = &start
+	}
+	if filter.EndDate != nil {
+		end := domain.CalendarDay(*filter.EndDate, loc).AddDate(0, 0, 1)
+		filter.EndDate = &end
+	}
+	return filter, loc, nil
+}
+
+// groupByDay summarises sessions under the calendar day each one started on,
+// keeping their order. Sessions count wholly towards their start day.
+func (uc *CaregiverAttendanceUsecase) groupByDay(sessions []domain.AttendanceSession, loc *time.Location, now time.Time) []AttendanceDay {
+	var days []AttendanceDay
+	for _, session := range sessions {
+		date, _ := domain.DayBounds(session.ClockIn.Timestamp, loc)
+		if n := len(days); n == 0 || !days[n-1].Date.Equal(date) {
+			days = append(days, AttendanceDay{Date: date})
+		}
+		day := &days[len(days)-1]
+		summary := uc.summarise(session, now)
+		day.Sessions = append(day.Sessions, summary)
+		day.WorkedMinutes += summary.WorkedMins
+		day.BreakMinutes += summary.BreakMins
+	}
+	return days
+}
 
-	return uc.caregiverLogs.GetLogsByCaregiver(ctx, caregiverID, filter)
+func (uc *CaregiverAttendanceUsecase) summarise(session domain.AttendanceSession, now time.Time) SessionSummary {
+	return SessionSummary{
+		AttendanceSession: session,
+		WorkedMins:        session.WorkedMinutes(now),
+		BreakMins:         session.BreakMinutes(now),
+		MissedBreak:       session.LongestStretch(now) > uc.breakAfter,
+	}
 }
 
 // TodayAttendanceStatus represents the attendance status for today.
@@ -149,4 +413,104 @@ type TodayAttendanceStatus struct {
 	ClockOutAt    *time.Time
 	ClockOutLat   *float64
 	ClockOutLong  *float64
+
+	// OnShift is true while the latest session is still open.
+	OnShift            bool
+	Sessions           []SessionSummary
+	TotalWorkedMinutes int
+	// AsOf is the instant open sessions were measured up to.
+	AsOf time.Time
+
+	OnBreak           bool
+	TotalBreakMinutes int
+	// MissedBreaks counts sessions that ran past the break limit without a break.
+	MissedBreaks int
+
+	// VisitMinutes is the paid (worked) time spent clocked in to visits;
+	// NonVisitMinutes is the rest of the paid time, e.g. travel.
+	VisitMinutes    int
+	NonVisitMinutes int
+	// Utilisation is VisitMinutes / TotalWorkedMinutes; nil without paid time.
+	Utilisation *float64
+}
+
+// SessionSummary is a shift session with its totals as of TodayAttendanceStatus.AsOf.
+type SessionSummary struct {
+	domain.AttendanceSession
+	WorkedMins  int
+	BreakMins   int
+	MissedBreak bool
+}
+
+// fillUtilisation measures how much of today's paid time overlaps clocked visits.
+func (uc *CaregiverAttendanceUsecase) fillUtilisation(ctx context.Context, status *TodayAttendanceStatus, sessions []domain.AttendanceSession, start, end time.Time) error {
+	now := status.AsOf
+	visits, err := uc.schedules.ListVisitIntervals(ctx, status.CaregiverID, start.Add(-overnightLookback), end)
+	if err != nil {
+		return err
+	}
+
+	to := end
+	if uc.overnight == domain.OvernightRuleStartDay {
+		// Sessions belong wholly to the day they started in.
+		to = now
+	}
+	onVisits := visitTimeWithin(sessions, visits, start, to, now)
+
+	status.VisitMinutes = int(onVisits / time.Minute)
+	if status.VisitMinutes > status.TotalWorkedMinutes {
+		status.VisitMinutes = status.TotalWorkedMinutes
+	}
+	status.NonVisitMinutes = status.TotalWorkedMinutes - status.VisitMinutes
+	utilisation := float64(status.VisitMinutes) / float64(status.TotalWorkedMinutes)
+	status.Utilisation = &utilisation
+	return nil
+}
+
+// visitTimeWithin returns the worked time inside [from, to) during which the
+// caregiver was also clocked in to a visit.
+func visitTimeWithin(sessions []domain.AttendanceSession, visits []domain.VisitInterval, from, to, now time.Time) time.Duration {
+	var total time.Duration
+	for _, session := range sessions {
+		for _, visit := range visits {
+			visitFrom, visitTo := visit.Start, now
+			if visit.End != nil {
+				visitTo = *visit.End
+			}
+			if visitFrom.Before(from) {
+				visitFrom = from
+			}
+			if visitTo.After(to) {
+				visitTo = to
+			}
+			total += session.WorkedWithin(visitFrom, visitTo, now)
+		}
+	}
+	return total
+}
+
+// record stores log; the repository rejects it when it is not a valid next
+// step from the caregiver's latest log.
+func (uc *CaregiverAttendanceUsecase) record(ctx context.Context, log domain.CaregiverLog) (domain.CaregiverLog, error) {
+	log.Timestamp = uc.now()
+	id, err := uc.caregiverLogs.CreateLog(ctx, log)
+	if err != nil {
+		return domain.CaregiverLog{}, err
+	}
+
+	log.ID = id
+	data := map[string]interface{}{
+		"log_id":   log.ID,
+		"log_type": log.LogType,
+	}
+	if log.BreakType != nil {
+		data["break_type"] = *log.BreakType
+	}
+	uc.events.Publish(ctx, domain.Event{
+		Type:        domain.EventAttendanceLogged,
+		CaregiverID: log.CaregiverID,
+		OccurredAt:  log.Timestamp,
+		Data:        data,
+	})
+	return log, nil
 }
\ No newline at end of file
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,27 +14,40 @@ import (
 var _ repository.CaregiverLogRepository = (*caregiverLogRepoStub)(nil)
 
 type caregiverLogRepoStub struct {
-	logs       []domain.CaregiverLog
-	hasClocked bool
-}
-
-func (c *caregiverLogRepoStub) HasClockedInToday(ctx context.Context, caregiverID string) (bool, error) {
-	return c.hasClocked, nil
-}
-
-func (c *caregiverLogRepoStub) HasClockedOutToday(ctx context.Context, caregiverID string) (bool, error) {
-	// For testing, assume if there are 2 logs, they've clocked out
-	return len(c.logs) >= 2, nil
+	logs     []domain.CaregiverLog
+	from, to time.Time
+	filter   repository.CaregiverLogFilter
 }
 
 func (c *caregiverLogRepoStub) CreateLog(ctx context.Context, log domain.CaregiverLog) (string, error) {
+	var lastType domain.LogType
+	if len(c.logs) > 0 {
+		lastType = c.logs[len(c.logs)-1].LogType
+	}
+	if !log.LogType.CanFollow(lastType) {
+		return "", domain.ErrValidationFailure
+	}
 	log.ID = "log-123"
 	c.logs = append(c.logs, log)
 	return log.ID, nil
 }
 
-func (c *caregiverLogRepoStub) GetTodayLogs(ctx context.Context, caregiverID string) ([]domain.CaregiverLog, error) {
-	return c.logs, nil
+func (c *caregiverLogRepoStub) GetLogsBetween(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.CaregiverLog, error) {
+	c.from, c.to = from, to
+	var logs []domain.CaregiverLog
+	for _, log := range c.logs {
+		if !log.Timestamp.Before(from) && log.Timestamp.Before(to) {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (c *caregiverLogRepoStub) GetLastLog(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
+	if len(c.logs) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &c.logs[len(c.logs)-1], nil
 }
 
 func (c *caregiverLogRepoStub) GetLastClockIn(ctx context.Context, caregiverID string) (*domain.CaregiverLog, error) {
@@ -54,6 +69,7 @@ func (c *caregiverLogRepoStub) GetLastClockOut(ctx context.Context, caregiverID
 }
 
 func (c *caregiverLogRepoStub) GetLogsByCaregiver(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]domain.CaregiverLogSummary, error) {
+	c.filter = filter
 	// Always return a non-nil slice, even if empty
 	summaries := make([]domain.CaregiverLogSummary, 0)
 	for _, log := range c.logs {
@@ -67,11 +83,72 @@ func (c *caregiverLogRepoStub) GetLogsByCaregiver(ctx context.Context, caregiver
 	return summaries, nil
 }
 
+func (c *caregiverLogRepoStub) ListLogs(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) ([]domain.CaregiverLog, error) {
+	c.filter = filter
+	logs := c.matching(filter)
+	if filter.After != nil {
+		for i, log := range logs {
+			if log.Timestamp.Before(filter.After.Time) || (log.Timestamp.Equal(filter.After.Time) && log.ID < filter.After.ID) {
+				logs = logs[i:]
+				break
+			}
+			if i == len(logs)-1 {
+				logs = nil
+			}
+		}
+	}
+	if filter.Offset > 0 && filter.Offset < len(logs) {
+		logs = logs[filter.Offset:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(logs) {
+		logs = logs[:filter.Limit]
+	}
+	return logs, nil
+}
+
+func (c *caregiverLogRepoStub) CountLogs(ctx context.Context, caregiverID string, filter repository.CaregiverLogFilter) (int, error) {
+	return len(c.matching(filter)), nil
+}
+
+func (c *caregiverLogRepoStub) GetSessionLogs(ctx context.Context, caregiverID string, from, through time.Time) ([]domain.CaregiverLog, error) {
+	var logs []domain.CaregiverLog
+	for _, log := range c.logs {
+		if log.Timestamp.Before(from) {
+			continue
+		}
+		if log.LogType == domain.LogTypeClockIn && log.Timestamp.After(through) {
+			break
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// matching returns the stored logs passing the filter's type and date
+// conditions, newest first.
+func (c *caregiverLogRepoStub) matching(filter repository.CaregiverLogFilter) []domain.CaregiverLog {
+	var logs []domain.CaregiverLog
+	for i := len(c.logs) - 1; i >= 0; i-- {
+		log := c.logs[i]
+		if filter.LogType != nil && log.LogType != *filter.LogType {
+			continue
+		}
+		if filter.StartDate != nil && log.Timestamp.Before(*filter.StartDate) {
+			continue
+		}
+		if filter.EndDate != nil && !log.Timestamp.Before(*filter.EndDate) {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
+
 func TestCaregiverAttendanceUsecaseClockIn(t *testing.T) {
 	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
-	repo := &caregiverLogRepoStub{hasClocked: false}
+	repo := &caregiverLogRepoStub{}
 	
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 	uc.WithNow(func() time.Time { return now })
 
 	log, err := uc.ClockIn(context.Background(), "caregiver-1", 40.7128, -74.0060, nil)
@@ -97,8 +174,17 @@ func TestCaregiverAttendanceUsecaseClockIn(t *testing.T) {
 }
 
 func TestCaregiverAttendanceUsecaseClockInAlreadyClockedIn(t *testing.T) {
-	repo := &caregiverLogRepoStub{hasClocked: true}
-	uc := NewCaregiverAttendanceUsecase(repo)
+	repo := &caregiverLogRepoStub{
+		logs: []domain.CaregiverLog{
+			{
+				ID:          "log-1",
+				CaregiverID: "caregiver-1",
+				LogType:     domain.LogTypeClockIn,
+				Timestamp:   time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 
 	_, err := uc.ClockIn(context.Background(), "caregiver-1", 40.7128, -74.0060, nil)
 	if err != domain.ErrValidationFailure {
@@ -109,7 +195,6 @@ func TestCaregiverAttendanceUsecaseClockInAlreadyClockedIn(t *testing.T) {
 func TestCaregiverAttendanceUsecaseClockOut(t *testing.T) {
 	now := time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC)
 	repo := &caregiverLogRepoStub{
-		hasClocked: true,
 		logs: []domain.CaregiverLog{
 			{
 				ID:        "log-1",
@@ -120,7 +205,7 @@ func TestCaregiverAttendanceUsecaseClockOut(t *testing.T) {
 		},
 	}
 	
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 	uc.WithNow(func() time.Time { return now })
 
 	log, err := uc.ClockOut(context.Background(), "caregiver-1", 40.7128, -74.0060, nil)
@@ -143,8 +228,8 @@ func TestCaregiverAttendanceUsecaseClockOut(t *testing.T) {
 }
 
 func TestCaregiverAttendanceUsecaseClockOutNotClockedIn(t *testing.T) {
-	repo := &caregiverLogRepoStub{hasClocked: false}
-	uc := NewCaregiverAttendanceUsecase(repo)
+	repo := &caregiverLogRepoStub{}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 
 	_, err := uc.ClockOut(context.Background(), "caregiver-1", 40.7128, -74.0060, nil)
 	if err != domain.ErrValidationFailure {
@@ -155,7 +240,6 @@ func TestCaregiverAttendanceUsecaseClockOutNotClockedIn(t *testing.T) {
 func TestCaregiverAttendanceUsecaseClockOutAlreadyClockedOut(t *testing.T) {
 	now := time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC)
 	repo := &caregiverLogRepoStub{
-		hasClocked: true,
 		logs: []domain.CaregiverLog{
 			{
 				ID:        "log-1",
@@ -172,7 +256,7 @@ func TestCaregiverAttendanceUsecaseClockOutAlreadyClockedOut(t *testing.T) {
 		},
 	}
 	
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 
 	_, err := uc.ClockOut(context.Background(), "caregiver-1", 40.7128, -74.0060, nil)
 	if err != domain.ErrValidationFailure {
@@ -208,7 +292,7 @@ func TestCaregiverAttendanceUsecaseGetTodayStatus(t *testing.T) {
 		},
 	}
 	
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 	uc.WithNow(func() time.Time { return now })
 
 	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
@@ -243,7 +327,7 @@ func TestCaregiverAttendanceUsecaseGetTodayStatusNoLogs(t *testing.T) {
 	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
 	repo := &caregiverLogRepoStub{logs: []domain.CaregiverLog{}}
 	
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 	uc.WithNow(func() time.Time { return now })
 
 	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
@@ -268,6 +352,36 @@ func TestCaregiverAttendanceUsecaseGetTodayStatusNoLogs(t *testing.T) {
 	}
 }
 
+func TestCaregiverAttendanceUsecaseGetTodayStatusLongOpenSession(t *testing.T) {
+	now := time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)
+	clockInTime := now.Add(-30 * time.Hour)
+	repo := &caregiverLogRepoStub{
+		logs: []domain.CaregiverLog{
+			{ID: "log-1", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: clockInTime},
+		},
+	}
+
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithNow(func() time.Time { return now })
+
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !status.OnShift {
+		t.Fatalf("expected a shift opened 30h ago to be reported as on shift")
+	}
+	if !repo.from.Equal(clockInTime) {
+		t.Fatalf("expected logs to be read from the last clock in %v, got %v", clockInTime, repo.from)
+	}
+	if len(status.Sessions) != 1 || status.ClockInAt == nil || !status.ClockInAt.Equal(clockInTime) {
+		t.Fatalf("expected the open session to be listed, got %+v", status.Sessions)
+	}
+	if status.TotalWorkedMinutes != 2*60 {
+		t.Fatalf("expected 120 worked minutes today, got %d", status.TotalWorkedMinutes)
+	}
+}
+
 func TestCaregiverAttendanceUsecaseGetAttendanceHistory(t *testing.T) {
 	repo := &caregiverLogRepoStub{
 		logs: []domain.CaregiverLog{
@@ -286,9 +400,9 @@ func TestCaregiverAttendanceUsecaseGetAttendanceHistory(t *testing.T) {
 		},
 	}
 	
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 
-	history, err := uc.GetAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{})
+	history, _, err := uc.GetAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -306,10 +420,10 @@ func TestCaregiverAttendanceUsecaseGetAttendanceHistory(t *testing.T) {
 
 func TestCaregiverAttendanceUsecaseGetAttendanceHistoryWithDefaultLimit(t *testing.T) {
 	repo := &caregiverLogRepoStub{logs: []domain.CaregiverLog{}}
-	uc := NewCaregiverAttendanceUsecase(repo)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
 
 	filter := repository.CaregiverLogFilter{}
-	history, err := uc.GetAttendanceHistory(context.Background(), "caregiver-1", filter)
+	history, _, err := uc.GetAttendanceHistory(context.Background(), "caregiver-1", filter)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -326,4 +440,415 @@ func TestCaregiverAttendanceUsecaseGetAttendanceHistoryWithDefaultLimit(t *testi
 	if len(history) != 0 {
 		t.Fatalf("expected empty history, got %d items", len(history))
 	}
-}
\ No newline at end of file
+}
+
+func TestCaregiverAttendanceUsecaseSplitShift(t *testing.T) {
+	morningIn := time.Date(2025, 1, 15, 7, 0, 0, 0, time.UTC)
+	morningOut := time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)
+	repo := &caregiverLogRepoStub{
+		logs: []domain.CaregiverLog{
+			{ID: "log-1", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: morningIn},
+			{ID: "log-2", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockOut, Timestamp: morningOut},
+		},
+	}
+
+	now := time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithNow(func() time.Time { return now })
+
+	if _, err := uc.ClockIn(context.Background(), "caregiver-1", 40.7128, -74.0060, nil); err != nil {
+		t.Fatalf("expected evening clock-in to be allowed, got %v", err)
+	}
+
+	now = time.Date(2025, 1, 15, 18, 30, 0, 0, time.UTC)
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(status.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(status.Sessions))
+	}
+	if !status.OnShift || status.HasClockedOut {
+		t.Fatalf("expected the evening session to be open, got %+v", status)
+	}
+	if status.TotalWorkedMinutes != 4*60+90 {
+		t.Fatalf("expected 330 worked minutes, got %d", status.TotalWorkedMinutes)
+	}
+	if !status.ClockInAt.Equal(time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected latest session clock-in, got %v", status.ClockInAt)
+	}
+
+	if _, err := uc.ClockOut(context.Background(), "caregiver-1", 40.7128, -74.0060, nil); err != nil {
+		t.Fatalf("expected evening clock-out to be allowed, got %v", err)
+	}
+	if _, err := uc.ClockOut(context.Background(), "caregiver-1", 40.7128, -74.0060, nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected second clock-out to fail, got %v", err)
+	}
+}
+
+func TestPairSessionsIgnoresOrphanClockOut(t *testing.T) {
+	base := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	sessions := domain.PairSessions([]domain.CaregiverLog{
+		{LogType: domain.LogTypeClockOut, Timestamp: base.Add(1 * time.Hour)},
+		{LogType: domain.LogTypeClockIn, Timestamp: base.Add(8 * time.Hour)},
+		{LogType: domain.LogTypeClockOut, Timestamp: base.Add(12 * time.Hour)},
+	})
+	if len(sessions) != 1 || sessions[0].Open() || sessions[0].WorkedMinutes(base) != 240 {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseTodayStatusUsesCaregiverTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	repo := &caregiverLogRepoStub{
+		logs: []domain.CaregiverLog{
+			// 23:30 on 8 March in New York: yesterday for the caregiver.
+			{ID: "log-1", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: time.Date(2025, 3, 9, 4, 30, 0, 0, time.UTC)},
+			{ID: "log-2", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockOut, Timestamp: time.Date(2025, 3, 9, 4, 45, 0, 0, time.UTC)},
+			{ID: "log-3", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: time.Date(2025, 3, 9, 13, 0, 0, 0, time.UTC)},
+		},
+	}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "caregiver-1", Timezone: "America/New_York"}}, time.UTC)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, zones)
+	uc.WithNow(func() time.Time { return time.Date(2025, 3, 9, 20, 0, 0, 0, time.UTC) })
+
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Spring-forward day: midnight EST to midnight EDT is 23 hours. Logs are
+	// read from a day earlier to catch shifts carried over from the night.
+	if !repo.to.Equal(time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected upper bound %v", repo.to)
+	}
+	if status.Date.Format("2006-01-02") != "2025-03-09" || !status.Date.Equal(time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected status date %v", status.Date)
+	}
+	if len(status.Sessions) != 1 || status.Sessions[0].ClockIn.ID != "log-3" {
+		t.Fatalf("expected only today's session, got %+v", status.Sessions)
+	}
+	if !status.OnShift || status.TotalWorkedMinutes != 7*60 {
+		t.Fatalf("unexpected status: on_shift=%v worked=%d", status.OnShift, status.TotalWorkedMinutes)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseHistoryDatesUseCaregiverTimezone(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	repo := &caregiverLogRepoStub{}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "caregiver-1", Timezone: "America/New_York"}}, time.UTC)
+	uc := NewCaregiverAttendanceUsecase(repo, nil, zones)
+
+	day := time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC)
+	if _, _, err := uc.GetAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{StartDate: &day, EndDate: &day}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Fall-back day: midnight EDT to midnight EST is 25 hours.
+	if !repo.filter.StartDate.Equal(time.Date(2025, 11, 2, 4, 0, 0, 0, time.UTC)) || !repo.filter.EndDate.Equal(time.Date(2025, 11, 3, 5, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected range %v - %v", repo.filter.StartDate.UTC(), repo.filter.EndDate.UTC())
+	}
+}
+
+func overnightShiftLogs() []domain.CaregiverLog {
+	return []domain.CaregiverLog{
+		{ID: "log-1", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: time.Date(2025, 1, 14, 22, 0, 0, 0, time.UTC)},
+		{ID: "log-2", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockOut, Timestamp: time.Date(2025, 1, 15, 6, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestCaregiverAttendanceUsecaseClockOutAfterMidnight(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: overnightShiftLogs()[:1]}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithNow(func() time.Time { return time.Date(2025, 1, 15, 6, 0, 0, 0, time.UTC) })
+
+	if _, err := uc.ClockOut(context.Background(), "caregiver-1", 40.7128, -74.0060, nil); err != nil {
+		t.Fatalf("expected overnight clock-out to succeed, got %v", err)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseOvernightSplit(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: overnightShiftLogs()}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithNow(func() time.Time { return time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC) })
+
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(status.Sessions) != 1 || status.Sessions[0].ClockIn.ID != "log-1" {
+		t.Fatalf("expected carried-over session, got %+v", status.Sessions)
+	}
+	if status.TotalWorkedMinutes != 6*60 {
+		t.Fatalf("expected only the 6 hours after midnight, got %d", status.TotalWorkedMinutes)
+	}
+	if status.OnShift || !status.HasClockedOut {
+		t.Fatalf("expected the shift to be closed: %+v", status)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseOvernightStartDay(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: overnightShiftLogs()[:1]}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithOvernightRule(domain.OvernightRuleStartDay)
+	uc.WithNow(func() time.Time { return time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC) })
+
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(status.Sessions) != 0 || status.TotalWorkedMinutes != 0 {
+		t.Fatalf("expected the shift to stay with yesterday, got %+v", status)
+	}
+	if !status.OnShift {
+		t.Fatalf("expected caregiver to still be on shift")
+	}
+}
+
+func TestCaregiverAttendanceUsecaseBreakStateMachine(t *testing.T) {
+	repo := &caregiverLogRepoStub{}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	clock := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	uc.WithNow(func() time.Time { return clock })
+	ctx := context.Background()
+
+	if _, err := uc.StartBreak(ctx, "caregiver-1", domain.BreakTypeMeal, 1, 1, nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected break before clock-in to fail, got %v", err)
+	}
+	if _, err := uc.ClockIn(ctx, "caregiver-1", 1, 1, nil); err != nil {
+		t.Fatalf("clock in: %v", err)
+	}
+	if _, err := uc.EndBreak(ctx, "caregiver-1", 1, 1, nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected break end without break to fail, got %v", err)
+	}
+	if _, err := uc.StartBreak(ctx, "caregiver-1", "nap", 1, 1, nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected unknown break type to fail, got %v", err)
+	}
+
+	clock = clock.Add(4 * time.Hour)
+	log, err := uc.StartBreak(ctx, "caregiver-1", domain.BreakTypeMeal, 1, 1, nil)
+	if err != nil {
+		t.Fatalf("start break: %v", err)
+	}
+	if log.BreakType == nil || *log.BreakType != domain.BreakTypeMeal {
+		t.Fatalf("expected meal break to be recorded, got %+v", log)
+	}
+	if _, err := uc.ClockOut(ctx, "caregiver-1", 1, 1, nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected clock-out during break to fail, got %v", err)
+	}
+	if _, err := uc.StartBreak(ctx, "caregiver-1", domain.BreakTypeRest, 1, 1, nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected nested break to fail, got %v", err)
+	}
+
+	clock = clock.Add(30 * time.Minute)
+	if _, err := uc.EndBreak(ctx, "caregiver-1", 1, 1, nil); err != nil {
+		t.Fatalf("end break: %v", err)
+	}
+	clock = clock.Add(3*time.Hour + 30*time.Minute)
+	if _, err := uc.ClockOut(ctx, "caregiver-1", 1, 1, nil); err != nil {
+		t.Fatalf("clock out: %v", err)
+	}
+
+	status, err := uc.GetTodayStatus(ctx, "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.TotalWorkedMinutes != 7*60+30 || status.TotalBreakMinutes != 30 {
+		t.Fatalf("unexpected totals: worked=%d break=%d", status.TotalWorkedMinutes, status.TotalBreakMinutes)
+	}
+	if len(status.Sessions) != 1 || len(status.Sessions[0].Breaks) != 1 || status.Sessions[0].MissedBreak {
+		t.Fatalf("unexpected sessions: %+v", status.Sessions)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseFlagsMissedBreak(t *testing.T) {
+	meal := domain.BreakTypeMeal
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	repo := &caregiverLogRepoStub{logs: []domain.CaregiverLog{
+		{ID: "log-1", LogType: domain.LogTypeClockIn, Timestamp: day.Add(7 * time.Hour)},
+		{ID: "log-2", LogType: domain.LogTypeBreakStart, BreakType: &meal, Timestamp: day.Add(8 * time.Hour)},
+		{ID: "log-3", LogType: domain.LogTypeBreakEnd, Timestamp: day.Add(8*time.Hour + 15*time.Minute)},
+	}}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithBreakRequiredAfter(5 * time.Hour)
+	uc.WithNow(func() time.Time { return day.Add(13*time.Hour + 30*time.Minute) })
+
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 08:15 to 13:30 is 5h15m without a break.
+	if status.MissedBreaks != 1 || !status.Sessions[0].MissedBreak {
+		t.Fatalf("expected missed break flag: %+v", status)
+	}
+	if !status.OnShift || status.OnBreak {
+		t.Fatalf("expected to be on shift and not on break: %+v", status)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseUtilisation(t *testing.T) {
+	rest := domain.BreakTypeRest
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	logs := &caregiverLogRepoStub{logs: []domain.CaregiverLog{
+		{ID: "log-1", LogType: domain.LogTypeClockIn, Timestamp: day.Add(8 * time.Hour)},
+		{ID: "log-2", LogType: domain.LogTypeBreakStart, BreakType: &rest, Timestamp: day.Add(12 * time.Hour)},
+		{ID: "log-3", LogType: domain.LogTypeBreakEnd, Timestamp: day.Add(12*time.Hour + 30*time.Minute)},
+		{ID: "log-4", LogType: domain.LogTypeClockOut, Timestamp: day.Add(16 * time.Hour)},
+	}}
+	visitEnd := day.Add(11 * time.Hour)
+	schedules := &scheduleRepoStub{visits: []domain.VisitInterval{
+		{ScheduleID: "sched-1", Start: day.Add(9 * time.Hour), End: &visitEnd},
+		// Still clocked in to the visit; overlaps the break and runs past clock-out.
+		{ScheduleID: "sched-2", Start: day.Add(12 * time.Hour)},
+	}}
+	uc := NewCaregiverAttendanceUsecase(logs, schedules, nil)
+	uc.WithNow(func() time.Time { return day.Add(17 * time.Hour) })
+
+	status, err := uc.GetTodayStatus(context.Background(), "caregiver-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Paid: 8h less a 30 minute break. On visits: 2h + 3h30 after the break.
+	if status.TotalWorkedMinutes != 450 || status.VisitMinutes != 330 || status.NonVisitMinutes != 120 {
+		t.Fatalf("unexpected minutes: worked=%d visit=%d non-visit=%d", status.TotalWorkedMinutes, status.VisitMinutes, status.NonVisitMinutes)
+	}
+	if status.Utilisation == nil || *status.Utilisation < 0.73 || *status.Utilisation > 0.74 {
+		t.Fatalf("unexpected utilisation: %v", status.Utilisation)
+	}
+}
+
+func historyLogs() []domain.CaregiverLog {
+	notes := "gate code 1234"
+	rest := domain.BreakTypeRest
+	return []domain.CaregiverLog{
+		{ID: "log-1", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Latitude: 40.71, Longitude: -74.0, Notes: &notes, Timestamp: time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)},
+		{ID: "log-2", CaregiverID: "caregiver-1", LogType: domain.LogTypeBreakStart, BreakType: &rest, Timestamp: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{ID: "log-3", CaregiverID: "caregiver-1", LogType: domain.LogTypeBreakEnd, Timestamp: time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{ID: "log-4", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockOut, Latitude: 40.72, Longitude: -74.1, Timestamp: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{ID: "log-5", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{ID: "log-6", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockOut, Timestamp: time.Date(2025, 1, 15, 17, 0, 0, 0, time.UTC)},
+		{ID: "log-7", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockIn, Timestamp: time.Date(2025, 1, 16, 22, 0, 0, 0, time.UTC)},
+		{ID: "log-8", CaregiverID: "caregiver-1", LogType: domain.LogTypeClockOut, Timestamp: time.Date(2025, 1, 17, 2, 0, 0, 0, time.UTC)},
+	}
+}
+
+func TestCaregiverAttendanceUsecaseDetailedHistoryPages(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: historyLogs()}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+	uc.WithNow(func() time.Time { return time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC) })
+
+	page, err := uc.GetDetailedHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.TotalSessions != 3 || !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("unexpected paging: %+v", page)
+	}
+	if len(page.Days) != 2 {
+		t.Fatalf("expected two days, got %+v", page.Days)
+	}
+	// The overnight shift belongs to the day it started on.
+	if page.Days[0].Date.Format("2006-01-02") != "2025-01-16" || page.Days[0].WorkedMinutes != 240 {
+		t.Fatalf("unexpected first day: %+v", page.Days[0])
+	}
+	if len(page.Days[1].Sessions) != 1 || page.Days[1].Sessions[0].ClockIn.ID != "log-5" {
+		t.Fatalf("expected only the afternoon session on page one, got %+v", page.Days[1].Sessions)
+	}
+	if page.WorkedMinutes != 480 {
+		t.Fatalf("expected 480 worked minutes on the page, got %d", page.WorkedMinutes)
+	}
+
+	cursor := domain.Cursor{Time: time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC), ID: "log-5"}
+	if page.NextCursor != cursor.Encode() {
+		t.Fatalf("expected the cursor to point at the last session's clock-in, got %q", page.NextCursor)
+	}
+	next, err := uc.GetDetailedHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{Limit: 2, After: &cursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.HasMore || next.NextCursor != "" || len(next.Days) != 1 {
+		t.Fatalf("expected a final page, got %+v", next)
+	}
+	session := next.Days[0].Sessions[0]
+	if session.ClockIn.ID != "log-1" || session.ClockIn.Notes == nil || session.ClockOut == nil || session.ClockOut.Latitude != 40.72 {
+		t.Fatalf("expected full clock-in and clock-out records, got %+v", session)
+	}
+	if session.WorkedMins != 225 || session.BreakMins != 15 || len(session.Breaks) != 1 {
+		t.Fatalf("unexpected session totals: %+v", session)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseDetailedHistoryDateRange(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: historyLogs()}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	page, err := uc.GetDetailedHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{StartDate: &day, EndDate: &day})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.TotalSessions != 2 || page.HasMore || len(page.Days) != 1 || len(page.Days[0].Sessions) != 2 {
+		t.Fatalf("expected the two sessions of 15 January, got %+v", page)
+	}
+	if *repo.filter.LogType != domain.LogTypeClockIn || repo.filter.Limit != DefaultHistorySessions+1 {
+		t.Fatalf("expected clock-ins with the default page size, got %+v", repo.filter)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseExportAttendanceHistory(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: historyLogs()}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	payload, err := uc.ExportAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{StartDate: &from, EndDate: &to})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(payload)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected header and three sessions, got %q", payload)
+	}
+	if lines[1] != "2025-01-15,2025-01-15T08:00:00Z,40.71,-74,gate code 1234,2025-01-15T12:00:00Z,40.72,-74.1,,225,15,1,false" {
+		t.Fatalf("unexpected first row %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[3], "2025-01-16,2025-01-16T22:00:00Z,") || !strings.Contains(lines[3], "2025-01-17T02:00:00Z") {
+		t.Fatalf("expected the overnight shift in full, got %q", lines[3])
+	}
+
+	if _, err := uc.ExportAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{StartDate: &to, EndDate: &from}); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure for an inverted range, got %v", err)
+	}
+}
+
+func TestCaregiverAttendanceUsecaseGetAttendanceHistoryPageInfo(t *testing.T) {
+	repo := &caregiverLogRepoStub{logs: historyLogs()}
+	uc := NewCaregiverAttendanceUsecase(repo, nil, nil)
+
+	history, page, err := uc.GetAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 3 || !page.HasMore || repo.filter.Limit != 4 {
+		t.Fatalf("expected three logs with more to follow, got %d and %+v", len(history), page)
+	}
+	cursor := domain.Cursor{Time: history[2].Timestamp, ID: history[2].ID}
+	if page.NextCursor != cursor.Encode() {
+		t.Fatalf("expected the cursor to point at the last log, got %q", page.NextCursor)
+	}
+
+	// Exactly as many logs as the limit: the old len == limit guess said more.
+	_, page, err = uc.GetAttendanceHistory(context.Background(), "caregiver-1", repository.CaregiverLogFilter{Limit: len(repo.logs), After: &cursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.HasMore || page.NextCursor != "" {
+		t.Fatalf("expected no further page, got %+v", page)
+	}
+	if repo.filter.After == nil || repo.filter.After.ID != cursor.ID {
+		t.Fatalf("expected the cursor to reach the repository, got %+v", repo.filter.After)
+	}
+}
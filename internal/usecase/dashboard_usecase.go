@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// DashboardScope narrows the supervisor dashboard.
+type DashboardScope struct {
+	// Date is the calendar day to show; today when nil.
+	Date   *time.Time
+	Region string
+	Team   string
+}
+
+// DashboardUsecase builds the agency-wide operations dashboard for
+// supervisors. Days are cut in the agency timezone because the dashboard
+// spans caregivers in several zones.
+type DashboardUsecase struct {
+	dashboard repository.DashboardRepository
+	incidents repository.IncidentRepository
+	loc       *time.Location
+	now       func() time.Time
+}
+
+// NewDashboardUsecase constructs a DashboardUsecase; a nil loc means UTC.
+func NewDashboardUsecase(dashboard repository.DashboardRepository, incidents repository.IncidentRepository, loc *time.Location) *DashboardUsecase {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &DashboardUsecase{
+		dashboard: dashboard,
+		incidents: incidents,
+		loc:       loc,
+		now:       time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *DashboardUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// Summary counts the day's visits by state, open incidents and caregivers on shift.
+func (uc *DashboardUsecase) Summary(ctx context.Context, scope DashboardScope) (domain.DashboardSummary, error) {
+	filter := uc.filter(scope)
+	summary, err := uc.dashboard.GetSummary(ctx, filter)
+	if err != nil {
+		return domain.DashboardSummary{}, err
+	}
+	summary.Date = time.Date(filter.From.Year(), filter.From.Month(), filter.From.Day(), 0, 0, 0, 0, time.UTC)
+	return summary, nil
+}
+
+// Visits lists the day's visits in the state, earliest first.
+func (uc *DashboardUsecase) Visits(ctx context.Context, scope DashboardScope, state domain.DashboardVisitState) ([]domain.DashboardVisit, error) {
+	if !state.Valid() {
+		return nil, domain.ErrValidationFailure
+	}
+	return uc.dashboard.ListVisits(ctx, uc.filter(scope), state)
+}
+
+// Caregivers returns each caregiver's attendance and visit progress for the day.
+func (uc *DashboardUsecase) Caregivers(ctx context.Context, scope DashboardScope) ([]domain.CaregiverDayStatus, error) {
+	return uc.dashboard.ListCaregiverStatus(ctx, uc.filter(scope))
+}
+
+// OpenIncidents lists unresolved incidents, most severe first. They are not
+// limited to the day: an incident stays on the dashboard until it is resolved.
+func (uc *DashboardUsecase) OpenIncidents(ctx context.Context, scope DashboardScope) ([]domain.Incident, error) {
+	return uc.incidents.ListOpen(ctx, uc.filter(scope))
+}
+
+func (uc *DashboardUsecase) filter(scope DashboardScope) repository.DashboardFilter {
+	now := uc.now()
+	from, to := domain.DayBounds(now, uc.loc)
+	if scope.Date != nil {
+		from = domain.CalendarDay(*scope.Date, uc.loc)
+		to = from.AddDate(0, 0, 1)
+	}
+	return repository.DashboardFilter{
+		From:   from,
+		To:     to,
+		Now:    now,
+		Region: strings.TrimSpace(scope.Region),
+		Team:   strings.TrimSpace(scope.Team),
+	}
+}
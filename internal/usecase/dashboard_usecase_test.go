@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.DashboardRepository = (*dashboardRepoStub)(nil)
+
+type dashboardRepoStub struct {
+	filter repository.DashboardFilter
+	state  domain.DashboardVisitState
+}
+
+func (r *dashboardRepoStub) GetSummary(ctx context.Context, filter repository.DashboardFilter) (domain.DashboardSummary, error) {
+	r.filter = filter
+	return domain.DashboardSummary{Visits: 4, Late: 1}, nil
+}
+
+func (r *dashboardRepoStub) ListVisits(ctx context.Context, filter repository.DashboardFilter, state domain.DashboardVisitState) ([]domain.DashboardVisit, error) {
+	r.filter = filter
+	r.state = state
+	return nil, nil
+}
+
+func (r *dashboardRepoStub) ListCaregiverStatus(ctx context.Context, filter repository.DashboardFilter) ([]domain.CaregiverDayStatus, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func TestDashboardUsecaseUsesAgencyDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	repo := &dashboardRepoStub{}
+	uc := NewDashboardUsecase(repo, &incidentRepoStub{}, newYork)
+	// 03:30 UTC on 16 January is still the evening of 15 January in New York.
+	now := time.Date(2025, 1, 16, 3, 30, 0, 0, time.UTC)
+	uc.WithNow(func() time.Time { return now })
+
+	summary, err := uc.Summary(context.Background(), DashboardScope{Region: " Minneapolis ", Team: "North"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := summary.Date.Format("2006-01-02"); got != "2025-01-15" || summary.Late != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	filter := repo.filter
+	if !filter.From.Equal(time.Date(2025, 1, 15, 0, 0, 0, 0, newYork)) || !filter.To.Equal(time.Date(2025, 1, 16, 0, 0, 0, 0, newYork)) {
+		t.Fatalf("unexpected range %v - %v", filter.From, filter.To)
+	}
+	if !filter.Now.Equal(now) || filter.Region != "Minneapolis" || filter.Team != "North" {
+		t.Fatalf("unexpected filter %+v", filter)
+	}
+
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	if _, err := uc.Visits(context.Background(), DashboardScope{Date: &day}, domain.DashboardVisitMissed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.state != domain.DashboardVisitMissed || !repo.filter.From.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, newYork)) {
+		t.Fatalf("unexpected visits query %q from %v", repo.state, repo.filter.From)
+	}
+
+	if _, err := uc.Visits(context.Background(), DashboardScope{}, "overdue"); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure, got %v", err)
+	}
+}
+
+func TestAttendanceStateAfter(t *testing.T) {
+	cases := map[domain.LogType]domain.AttendanceState{
+		"":                       domain.AttendanceStateOff,
+		domain.LogTypeClockIn:    domain.AttendanceStateOnShift,
+		domain.LogTypeBreakStart: domain.AttendanceStateOnBreak,
+		domain.LogTypeBreakEnd:   domain.AttendanceStateOnShift,
+		domain.LogTypeClockOut:   domain.AttendanceStateOff,
+	}
+	for last, want := range cases {
+		if got := domain.AttendanceStateAfter(last); got != want {
+			t.Fatalf("after %q: expected %q, got %q", last, want, got)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// EventPublisher delivers domain events to real-time subscribers once the
+// change they describe has been stored. Delivery is best effort: Publish
+// must not block and failures never undo the change.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event)
+}
+
+// noEvents discards events; usecases use it until WithEvents is called.
+type noEvents struct{}
+
+func (noEvents) Publish(context.Context, domain.Event) {}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+var evvCSVHeader = []string{
+	"action", "visit_id", "service_code", "service_name",
+	"recipient_id", "recipient_medicaid_id", "recipient_name",
+	"caregiver_id", "caregiver_employee_id", "caregiver_name",
+	"service_date", "start_time", "start_latitude", "start_longitude",
+	"end_time", "end_latitude", "end_longitude", "manually_edited",
+	"client_signoff", "client_signoff_at", "client_signoff_hash", "visit_hash",
+}
+
+// encodeEVVCSV renders records as a flat CSV file, one visit per row.
+func encodeEVVCSV(records []domain.EVVRecord, loc *time.Location) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(evvCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		v := rec.Visit
+		row := []string{
+			string(rec.Action), v.ScheduleID, v.ServiceCode, v.ServiceName,
+			v.ClientID, v.ClientMedicaidID, v.ClientName,
+			v.CaregiverID, v.CaregiverEmployeeID, v.CaregiverName,
+			v.ClockInAt.In(loc).Format("2006-01-02"),
+			v.ClockInAt.UTC().Format(time.RFC3339), formatCoordinate(v.ClockInLat), formatCoordinate(v.ClockInLong),
+			v.ClockOutAt.UTC().Format(time.RFC3339), formatCoordinate(v.ClockOutLat), formatCoordinate(v.ClockOutLong),
+			strconv.FormatBool(v.ManuallyEdited),
+			string(v.SignoffMethod), formatOptionalUTC(v.SignoffAt), v.SignoffSummaryHash, rec.Hash,
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type evvJSONBatch struct {
+	BatchID     string         `json:"batch_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Period      evvJSONPeriod  `json:"period"`
+	RecordCount int            `json:"record_count"`
+	Visits      []evvJSONVisit `json:"visits"`
+}
+
+type evvJSONPeriod struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type evvJSONVisit struct {
+	Action         domain.EVVAction `json:"action"`
+	VisitID        string           `json:"visit_id"`
+	Service        evvJSONService   `json:"service"`
+	Recipient      evvJSONParty     `json:"recipient"`
+	Caregiver      evvJSONParty     `json:"caregiver"`
+	ServiceDate    string           `json:"service_date"`
+	Start          evvJSONPoint     `json:"start"`
+	End            evvJSONPoint     `json:"end"`
+	ManuallyEdited bool             `json:"manually_edited"`
+	ClientSignoff  *evvJSONSignoff  `json:"client_signoff,omitempty"`
+	Hash           string           `json:"hash"`
+}
+
+type evvJSONSignoff struct {
+	Method      domain.SignoffMethod `json:"method"`
+	CapturedAt  time.Time            `json:"captured_at"`
+	SummaryHash string               `json:"summary_hash"`
+}
+
+type evvJSONService struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type evvJSONParty struct {
+	ID         string `json:"id"`
+	MedicaidID string `json:"medicaid_id,omitempty"`
+	EmployeeID string `json:"employee_id,omitempty"`
+	Name       string `json:"name"`
+}
+
+type evvJSONPoint struct {
+	Time      time.Time `json:"time"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+}
+
+// encodeEVVJSON renders records as a single JSON document with batch metadata.
+func encodeEVVJSON(batch domain.EVVBatch, records []domain.EVVRecord, loc *time.Location) ([]byte, error) {
+	doc := evvJSONBatch{
+		BatchID:     batch.ID,
+		GeneratedAt: batch.CreatedAt,
+		Period: evvJSONPeriod{
+			From: batch.From.In(loc).Format("2006-01-02"),
+			To:   batch.To.In(loc).AddDate(0, 0, -1).Format("2006-01-02"),
+		},
+		RecordCount: len(records),
+		Visits:      make([]evvJSONVisit, len(records)),
+	}
+	for i, rec := range records {
+		v := rec.Visit
+		doc.Visits[i] = evvJSONVisit{
+			Action:         rec.Action,
+			VisitID:        v.ScheduleID,
+			Service:        evvJSONService{Code: v.ServiceCode, Name: v.ServiceName},
+			Recipient:      evvJSONParty{ID: v.ClientID, MedicaidID: v.ClientMedicaidID, Name: v.ClientName},
+			Caregiver:      evvJSONParty{ID: v.CaregiverID, EmployeeID: v.CaregiverEmployeeID, Name: v.CaregiverName},
+			ServiceDate:    v.ClockInAt.In(loc).Format("2006-01-02"),
+			Start:          evvJSONPoint{Time: v.ClockInAt.UTC(), Latitude: *v.ClockInLat, Longitude: *v.ClockInLong},
+			End:            evvJSONPoint{Time: v.ClockOutAt.UTC(), Latitude: *v.ClockOutLat, Longitude: *v.ClockOutLong},
+			ManuallyEdited: v.ManuallyEdited,
+			Hash:           rec.Hash,
+		}
+		if v.SignoffMethod != "" && v.SignoffAt != nil {
+			doc.Visits[i].ClientSignoff = &evvJSONSignoff{
+				Method:      v.SignoffMethod,
+				CapturedAt:  v.SignoffAt.UTC(),
+				SummaryHash: v.SignoffSummaryHash,
+			}
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func formatOptionalUTC(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatCoordinate(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', 6, 64)
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// maxEVVRangeDays bounds the visits covered by a single export batch.
+const maxEVVRangeDays = 31
+
+// EVVVisitReview is a completed visit with its submission status and validation issues.
+type EVVVisitReview struct {
+	Visit  domain.EVVVisit
+	Status domain.EVVVisitStatus
+	Issues []string
+}
+
+// EVVUsecase builds Electronic Visit Verification export batches for state aggregators.
+type EVVUsecase struct {
+	evv repository.EVVRepository
+	loc *time.Location
+	now func() time.Time
+}
+
+// NewEVVUsecase constructs an EVVUsecase. Date ranges are interpreted in loc.
+func NewEVVUsecase(evv repository.EVVRepository, loc *time.Location) *EVVUsecase {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &EVVUsecase{
+		evv: evv,
+		loc: loc,
+		now: time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *EVVUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// ListVisits returns completed visits between the from and to dates (inclusive)
+// with their EVV submission status and any missing data points.
+func (uc *EVVUsecase) ListVisits(ctx context.Context, fromDate, toDate time.Time) ([]EVVVisitReview, error) {
+	from, to, err := uc.dateRange(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	visits, err := uc.evv.ListVisits(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]EVVVisitReview, len(visits))
+	for i, v := range visits {
+		result[i] = EVVVisitReview{Visit: v, Status: v.Status(), Issues: v.Validate()}
+	}
+	return result, nil
+}
+
+// CreateExport generates a batch file for completed visits between the from and
+// to dates (inclusive). Visits never submitted are exported as creates, visits
+// whose data changed since submission as updates, and unchanged submissions are
+// skipped. Visits failing validation are reported as rejections instead of being
+// exported.
+func (uc *EVVUsecase) CreateExport(ctx context.Context, requestedBy string, fromDate, toDate time.Time, format domain.EVVFormat) (domain.EVVBatch, error) {
+	if format != domain.EVVFormatCSV && format != domain.EVVFormatJSON {
+		return domain.EVVBatch{}, domain.ErrValidationFailure
+	}
+	from, to, err := uc.dateRange(fromDate, toDate)
+	if err != nil {
+		return domain.EVVBatch{}, err
+	}
+
+	visits, err := uc.evv.ListVisits(ctx, from, to)
+	if err != nil {
+		return domain.EVVBatch{}, err
+	}
+
+	records := make([]domain.EVVRecord, 0, len(visits))
+	rejected := []domain.EVVRejection{}
+	for _, v := range visits {
+		var action domain.EVVAction
+		switch v.Status() {
+		case domain.EVVVisitSubmitted:
+			continue
+		case domain.EVVVisitCorrected:
+			action = domain.EVVActionUpdate
+		default:
+			action = domain.EVVActionCreate
+		}
+		if issues := v.Validate(); len(issues) > 0 {
+			rejected = append(rejected, domain.EVVRejection{ScheduleID: v.ScheduleID, Issues: issues})
+			continue
+		}
+		records = append(records, domain.EVVRecord{Visit: v, Action: action, Hash: v.Hash()})
+	}
+
+	id, err := newUUID()
+	if err != nil {
+		return domain.EVVBatch{}, err
+	}
+	batch := domain.EVVBatch{
+		ID:            id,
+		From:          from,
+		To:            to,
+		Format:        format,
+		RecordCount:   len(records),
+		RejectedCount: len(rejected),
+		Rejected:      rejected,
+		CreatedBy:     requestedBy,
+		CreatedAt:     uc.now().UTC(),
+	}
+
+	switch format {
+	case domain.EVVFormatJSON:
+		batch.Payload, err = encodeEVVJSON(batch, records, uc.loc)
+	default:
+		batch.Payload, err = encodeEVVCSV(records, uc.loc)
+	}
+	if err != nil {
+		return domain.EVVBatch{}, err
+	}
+
+	if err := uc.evv.SaveBatch(ctx, batch, records); err != nil {
+		return domain.EVVBatch{}, err
+	}
+	return batch, nil
+}
+
+// GetBatch returns a previously generated batch including its file payload.
+func (uc *EVVUsecase) GetBatch(ctx context.Context, batchID string) (domain.EVVBatch, error) {
+	return uc.evv.GetBatch(ctx, batchID)
+}
+
+func (uc *EVVUsecase) dateRange(fromDate, toDate time.Time) (time.Time, time.Time, error) {
+	from := time.Date(fromDate.Year(), fromDate.Month(), fromDate.Day(), 0, 0, 0, 0, uc.loc)
+	to := time.Date(toDate.Year(), toDate.Month(), toDate.Day(), 0, 0, 0, 0, uc.loc).AddDate(0, 0, 1)
+	if !to.After(from) || to.Sub(from) > maxEVVRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, domain.ErrValidationFailure
+	}
+	return from, to, nil
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.EVVRepository = (*evvRepoStub)(nil)
+
+type evvRepoStub struct {
+	visits  []domain.EVVVisit
+	saved   *domain.EVVBatch
+	records []domain.EVVRecord
+}
+
+func (e *evvRepoStub) ListVisits(ctx context.Context, from, to time.Time) ([]domain.EVVVisit, error) {
+	return e.visits, nil
+}
+
+func (e *evvRepoStub) SaveBatch(ctx context.Context, batch domain.EVVBatch, records []domain.EVVRecord) error {
+	e.saved = &batch
+	e.records = records
+	return nil
+}
+
+func (e *evvRepoStub) GetBatch(ctx context.Context, batchID string) (domain.EVVBatch, error) {
+	if e.saved == nil || e.saved.ID != batchID {
+		return domain.EVVBatch{}, domain.ErrNotFound
+	}
+	return *e.saved, nil
+}
+
+func completeEVVVisit(id string, start time.Time) domain.EVVVisit {
+	end := start.Add(time.Hour)
+	return domain.EVVVisit{
+		ScheduleID:          id,
+		ServiceCode:         "T1019",
+		ServiceName:         "Personal Care",
+		ClientID:            "client-1",
+		ClientName:          "Jane Doe",
+		ClientMedicaidID:    "MN123",
+		CaregiverID:         "cg-1",
+		CaregiverName:       "Louis",
+		CaregiverEmployeeID: "EMP-1",
+		ClockInAt:           &start,
+		ClockInLat:          floatPtr(44.97),
+		ClockInLong:         floatPtr(-93.26),
+		ClockOutAt:          &end,
+		ClockOutLat:         floatPtr(44.97),
+		ClockOutLong:        floatPtr(-93.26),
+	}
+}
+
+func TestEVVUsecaseCreateExportClassifiesVisits(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	fresh := completeEVVVisit("visit-new", day.Add(9*time.Hour))
+
+	submitted := completeEVVVisit("visit-sent", day.Add(11*time.Hour))
+	sentHash := submitted.Hash()
+	submitted.LastSubmittedHash = &sentHash
+
+	corrected := completeEVVVisit("visit-fixed", day.Add(13*time.Hour))
+	staleHash := "stale"
+	corrected.LastSubmittedHash = &staleHash
+
+	invalid := completeEVVVisit("visit-bad", day.Add(15*time.Hour))
+	invalid.ClientMedicaidID = ""
+	invalid.ClockOutLat = nil
+
+	repo := &evvRepoStub{visits: []domain.EVVVisit{fresh, submitted, corrected, invalid}}
+	uc := NewEVVUsecase(repo, time.UTC)
+	uc.WithNow(func() time.Time { return day.AddDate(0, 0, 1) })
+
+	batch, err := uc.CreateExport(context.Background(), "coordinator", day, day, domain.EVVFormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if batch.RecordCount != 2 || len(repo.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", batch.RecordCount)
+	}
+	if repo.records[0].Visit.ScheduleID != "visit-new" || repo.records[0].Action != domain.EVVActionCreate {
+		t.Fatalf("expected visit-new as create, got %+v", repo.records[0])
+	}
+	if repo.records[1].Visit.ScheduleID != "visit-fixed" || repo.records[1].Action != domain.EVVActionUpdate {
+		t.Fatalf("expected visit-fixed as update, got %+v", repo.records[1])
+	}
+	if batch.RejectedCount != 1 || batch.Rejected[0].ScheduleID != "visit-bad" || len(batch.Rejected[0].Issues) != 2 {
+		t.Fatalf("expected visit-bad rejected with 2 issues, got %+v", batch.Rejected)
+	}
+	if len(batch.ID) != 36 {
+		t.Fatalf("expected uuid batch id, got %q", batch.ID)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(batch.Payload)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header plus 2 rows, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], "create,visit-new,T1019,") || !strings.HasPrefix(lines[2], "update,visit-fixed,") {
+		t.Fatalf("unexpected csv rows: %v", lines[1:])
+	}
+}
+
+func TestEVVUsecaseCreateExportJSON(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	repo := &evvRepoStub{visits: []domain.EVVVisit{completeEVVVisit("visit-1", day.Add(9*time.Hour))}}
+	uc := NewEVVUsecase(repo, time.UTC)
+
+	batch, err := uc.CreateExport(context.Background(), "coordinator", day, day.AddDate(0, 0, 1), domain.EVVFormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		BatchID string `json:"batch_id"`
+		Period  struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"period"`
+		Visits []struct {
+			Action    string `json:"action"`
+			Recipient struct {
+				MedicaidID string `json:"medicaid_id"`
+			} `json:"recipient"`
+			ServiceDate string `json:"service_date"`
+		} `json:"visits"`
+	}
+	if err := json.Unmarshal(batch.Payload, &doc); err != nil {
+		t.Fatalf("payload is not valid json: %v", err)
+	}
+	if doc.BatchID != batch.ID || doc.Period.From != "2025-01-15" || doc.Period.To != "2025-01-16" {
+		t.Fatalf("unexpected batch header: %+v", doc)
+	}
+	if len(doc.Visits) != 1 || doc.Visits[0].Action != "create" || doc.Visits[0].Recipient.MedicaidID != "MN123" || doc.Visits[0].ServiceDate != "2025-01-15" {
+		t.Fatalf("unexpected visits: %+v", doc.Visits)
+	}
+}
+
+func TestEVVUsecaseCreateExportRejectsInvalidRange(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	uc := NewEVVUsecase(&evvRepoStub{}, time.UTC)
+
+	if _, err := uc.CreateExport(context.Background(), "coordinator", day, day.AddDate(0, 0, -1), domain.EVVFormatCSV); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure for reversed range, got %v", err)
+	}
+	if _, err := uc.CreateExport(context.Background(), "coordinator", day, day.AddDate(0, 0, 40), domain.EVVFormatCSV); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure for long range, got %v", err)
+	}
+}
+
+func TestEVVUsecaseCreateExportCarriesClientSignoff(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	signed := completeEVVVisit("visit-signed", day.Add(9*time.Hour))
+	signed.SignoffRequired = true
+	signed.SignoffMethod = domain.SignoffMethodPIN
+	signed.SignoffAt = signed.ClockOutAt
+	signed.SignoffSummaryHash = "abc123"
+
+	unsigned := completeEVVVisit("visit-unsigned", day.Add(11*time.Hour))
+	unsigned.SignoffRequired = true
+
+	repo := &evvRepoStub{visits: []domain.EVVVisit{signed, unsigned}}
+	uc := NewEVVUsecase(repo, time.UTC)
+
+	batch, err := uc.CreateExport(context.Background(), "coordinator", day, day, domain.EVVFormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if batch.RecordCount != 1 || batch.RejectedCount != 1 || batch.Rejected[0].Issues[0] != "missing client signoff" {
+		t.Fatalf("expected the unsigned visit rejected, got %+v", batch.Rejected)
+	}
+	lines := strings.Split(strings.TrimSpace(string(batch.Payload)), "\n")
+	if !strings.Contains(lines[1], ",pin,2025-01-15T10:00:00Z,abc123,") {
+		t.Fatalf("expected signoff columns in csv row, got %q", lines[1])
+	}
+}
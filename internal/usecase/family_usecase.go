@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+const (
+	defaultFamilyVisitLimit = 20
+	maxFamilyVisitLimit     = 100
+)
+
+// FamilyMemberInput describes a family member to add to a client.
+type FamilyMemberInput struct {
+	Name         string
+	Email        string
+	Relationship string
+}
+
+// FamilyConsentUpdate changes the consent flags that are not nil.
+type FamilyConsentUpdate struct {
+	ShareVisits        *bool
+	ShareTasks         *bool
+	ShareConfirmations *bool
+}
+
+// FamilyUsecase manages family members and client consent for coordinators,
+// and serves the read-only family portal within what each client shares.
+type FamilyUsecase struct {
+	family repository.FamilyRepository
+	now    func() time.Time
+}
+
+// NewFamilyUsecase constructs a FamilyUsecase.
+func NewFamilyUsecase(family repository.FamilyRepository) *FamilyUsecase {
+	return &FamilyUsecase{family: family, now: time.Now}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *FamilyUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// AddMember gives a family member portal access to the client. The returned
+// password is generated here and cannot be retrieved later.
+func (uc *FamilyUsecase) AddMember(ctx context.Context, clientID string, input FamilyMemberInput) (domain.FamilyMember, string, error) {
+	name := strings.TrimSpace(input.Name)
+	email := strings.TrimSpace(input.Email)
+	if name == "" {
+		return domain.FamilyMember{}, "", domain.ErrValidationFailure
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return domain.FamilyMember{}, "", domain.ErrValidationFailure
+	}
+	if _, err := uc.family.GetConsent(ctx, clientID); err != nil {
+		return domain.FamilyMember{}, "", err
+	}
+
+	password, err := newFamilyPassword()
+	if err != nil {
+		return domain.FamilyMember{}, "", err
+	}
+	hash, err := hashSecret(password)
+	if err != nil {
+		return domain.FamilyMember{}, "", err
+	}
+	member, err := uc.family.CreateMember(ctx, domain.FamilyMember{
+		ClientID:     clientID,
+		Name:         name,
+		Email:        email,
+		Relationship: strings.TrimSpace(input.Relationship),
+		SecretHash:   hash,
+		Active:       true,
+		CreatedAt:    uc.now().UTC(),
+	})
+	if err != nil {
+		return domain.FamilyMember{}, "", err
+	}
+	return member, password, nil
+}
+
+// ListMembers returns the client's family members.
+func (uc *FamilyUsecase) ListMembers(ctx context.Context, clientID string) ([]domain.FamilyMember, error) {
+	if _, err := uc.family.GetConsent(ctx, clientID); err != nil {
+		return nil, err
+	}
+	return uc.family.ListMembers(ctx, clientID)
+}
+
+// SetMemberActive grants or withdraws a family member's portal access.
+func (uc *FamilyUsecase) SetMemberActive(ctx context.Context, clientID, memberID string, active bool) (domain.FamilyMember, error) {
+	return uc.family.SetMemberActive(ctx, clientID, memberID, active)
+}
+
+// GetConsent returns what the client shares with their family.
+func (uc *FamilyUsecase) GetConsent(ctx context.Context, clientID string) (domain.FamilyConsent, error) {
+	return uc.family.GetConsent(ctx, clientID)
+}
+
+// UpdateConsent records a change to what the client shares.
+func (uc *FamilyUsecase) UpdateConsent(ctx context.Context, clientID, updaterID string, update FamilyConsentUpdate) (domain.FamilyConsent, error) {
+	consent, err := uc.family.GetConsent(ctx, clientID)
+	if err != nil {
+		return domain.FamilyConsent{}, err
+	}
+	if update.ShareVisits != nil {
+		consent.ShareVisits = *update.ShareVisits
+	}
+	if update.ShareTasks != nil {
+		consent.ShareTasks = *update.ShareTasks
+	}
+	if update.ShareConfirmations != nil {
+		consent.ShareConfirmations = *update.ShareConfirmations
+	}
+	now := uc.now().UTC()
+	consent.UpdatedBy = &updaterID
+	consent.UpdatedAt = &now
+	if err := uc.family.SaveConsent(ctx, consent); err != nil {
+		return domain.FamilyConsent{}, err
+	}
+	return consent, nil
+}
+
+// Me returns the signed-in family member and what their client shares.
+func (uc *FamilyUsecase) Me(ctx context.Context, memberID string) (domain.FamilyMember, domain.FamilyConsent, error) {
+	return uc.portalMember(ctx, memberID)
+}
+
+// ListVisits returns the client's upcoming or past visits, showing only what
+// the client shares.
+func (uc *FamilyUsecase) ListVisits(ctx context.Context, memberID string, upcoming bool, limit int) ([]domain.FamilyVisit, error) {
+	switch {
+	case limit < 0:
+		return nil, domain.ErrValidationFailure
+	case limit == 0:
+		limit = defaultFamilyVisitLimit
+	case limit > maxFamilyVisitLimit:
+		limit = maxFamilyVisitLimit
+	}
+	member, consent, err := uc.portalMember(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !consent.ShareVisits {
+		return nil, domain.ErrForbidden
+	}
+
+	visits, err := uc.family.ListVisits(ctx, member.ClientID, repository.FamilyVisitFilter{
+		Upcoming: upcoming,
+		Now:      uc.now(),
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uc.applyConsent(ctx, consent, visits)
+}
+
+// GetVisit returns one of the client's visits, showing only what the client shares.
+func (uc *FamilyUsecase) GetVisit(ctx context.Context, memberID, scheduleID string) (domain.FamilyVisit, error) {
+	member, consent, err := uc.portalMember(ctx, memberID)
+	if err != nil {
+		return domain.FamilyVisit{}, err
+	}
+	if !consent.ShareVisits {
+		return domain.FamilyVisit{}, domain.ErrForbidden
+	}
+
+	visit, err := uc.family.GetVisit(ctx, member.ClientID, scheduleID)
+	if err != nil {
+		return domain.FamilyVisit{}, err
+	}
+	visits, err := uc.applyConsent(ctx, consent, []domain.FamilyVisit{visit})
+	if err != nil {
+		return domain.FamilyVisit{}, err
+	}
+	return visits[0], nil
+}
+
+// portalMember loads a signed-in family member, refusing members whose access
+// was withdrawn after their token was issued.
+func (uc *FamilyUsecase) portalMember(ctx context.Context, memberID string) (domain.FamilyMember, domain.FamilyConsent, error) {
+	member, err := uc.family.GetMember(ctx, memberID)
+	if err != nil {
+		return domain.FamilyMember{}, domain.FamilyConsent{}, err
+	}
+	if !member.Active {
+		return domain.FamilyMember{}, domain.FamilyConsent{}, domain.ErrForbidden
+	}
+	consent, err := uc.family.GetConsent(ctx, member.ClientID)
+	if err != nil {
+		return domain.FamilyMember{}, domain.FamilyConsent{}, err
+	}
+	return member, consent, nil
+}
+
+// applyConsent removes confirmations and adds completed tasks as the consent allows.
+func (uc *FamilyUsecase) applyConsent(ctx context.Context, consent domain.FamilyConsent, visits []domain.FamilyVisit) ([]domain.FamilyVisit, error) {
+	var tasks map[string][]domain.FamilyTask
+	if consent.ShareTasks && len(visits) > 0 {
+		ids := make([]string, 0, len(visits))
+		for _, v := range visits {
+			ids = append(ids, v.ScheduleID)
+		}
+		var err error
+		if tasks, err = uc.family.CompletedTasks(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	for i := range visits {
+		if !consent.ShareConfirmations {
+			visits[i].Confirmation = nil
+		}
+		visits[i].Tasks = nil
+		if consent.ShareTasks {
+			visits[i].Tasks = tasks[visits[i].ScheduleID]
+			if visits[i].Tasks == nil {
+				visits[i].Tasks = []domain.FamilyTask{}
+			}
+		}
+	}
+	return visits, nil
+}
+
+func newFamilyPassword() (string, error) {
+	var raw [18]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.FamilyRepository = (*familyRepoStub)(nil)
+
+type familyRepoStub struct {
+	members  map[string]domain.FamilyMember
+	consents map[string]domain.FamilyConsent
+	visits   []domain.FamilyVisit
+	tasks    map[string][]domain.FamilyTask
+	filter   repository.FamilyVisitFilter
+}
+
+func (r *familyRepoStub) CreateMember(ctx context.Context, member domain.FamilyMember) (domain.FamilyMember, error) {
+	for _, existing := range r.members {
+		if strings.EqualFold(existing.Email, member.Email) {
+			return domain.FamilyMember{}, domain.ErrConflict
+		}
+	}
+	if r.members == nil {
+		r.members = map[string]domain.FamilyMember{}
+	}
+	member.ID = fmt.Sprintf("member-%d", len(r.members)+1)
+	r.members[member.ID] = member
+	return member, nil
+}
+
+func (r *familyRepoStub) GetMember(ctx context.Context, memberID string) (domain.FamilyMember, error) {
+	member, ok := r.members[memberID]
+	if !ok {
+		return domain.FamilyMember{}, domain.ErrNotFound
+	}
+	return member, nil
+}
+
+func (r *familyRepoStub) GetMemberByEmail(ctx context.Context, email string) (domain.FamilyMember, error) {
+	for _, member := range r.members {
+		if strings.EqualFold(member.Email, email) {
+			return member, nil
+		}
+	}
+	return domain.FamilyMember{}, domain.ErrNotFound
+}
+
+func (r *familyRepoStub) ListMembers(ctx context.Context, clientID string) ([]domain.FamilyMember, error) {
+	var members []domain.FamilyMember
+	for _, member := range r.members {
+		if member.ClientID == clientID {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (r *familyRepoStub) SetMemberActive(ctx context.Context, clientID, memberID string, active bool) (domain.FamilyMember, error) {
+	member, ok := r.members[memberID]
+	if !ok || member.ClientID != clientID {
+		return domain.FamilyMember{}, domain.ErrNotFound
+	}
+	member.Active = active
+	r.members[memberID] = member
+	return member, nil
+}
+
+func (r *familyRepoStub) GetConsent(ctx context.Context, clientID string) (domain.FamilyConsent, error) {
+	consent, ok := r.consents[clientID]
+	if !ok {
+		return domain.FamilyConsent{}, domain.ErrNotFound
+	}
+	return consent, nil
+}
+
+func (r *familyRepoStub) SaveConsent(ctx context.Context, consent domain.FamilyConsent) error {
+	r.consents[consent.ClientID] = consent
+	return nil
+}
+
+func (r *familyRepoStub) ListVisits(ctx context.Context, clientID string, filter repository.FamilyVisitFilter) ([]domain.FamilyVisit, error) {
+	r.filter = filter
+	return append([]domain.FamilyVisit(nil), r.visits...), nil
+}
+
+func (r *familyRepoStub) GetVisit(ctx context.Context, clientID, scheduleID string) (domain.FamilyVisit, error) {
+	for _, v := range r.visits {
+		if v.ScheduleID == scheduleID {
+			return v, nil
+		}
+	}
+	return domain.FamilyVisit{}, domain.ErrNotFound
+}
+
+func (r *familyRepoStub) CompletedTasks(ctx context.Context, scheduleIDs []string) (map[string][]domain.FamilyTask, error) {
+	return r.tasks, nil
+}
+
+func familyPortalRepo() *familyRepoStub {
+	clockIn := time.Date(2025, 1, 15, 9, 2, 0, 0, time.UTC)
+	atHome := true
+	return &familyRepoStub{
+		members: map[string]domain.FamilyMember{
+			"member-1": {ID: "member-1", ClientID: "client-1", Name: "Anna", Email: "anna@example.com", Active: true},
+		},
+		consents: map[string]domain.FamilyConsent{"client-1": {ClientID: "client-1"}},
+		visits: []domain.FamilyVisit{{
+			ScheduleID:   "sched-1",
+			ServiceName:  "Personal care",
+			Status:       domain.ScheduleStatusCompleted,
+			Confirmation: &domain.VisitConfirmation{ClockInAt: &clockIn, ClockInAtHome: &atHome},
+		}},
+		tasks: map[string][]domain.FamilyTask{"sched-1": {{Title: "Prepare breakfast", CompletedAt: clockIn.Add(30 * time.Minute)}}},
+	}
+}
+
+func TestFamilyUsecaseConsentFiltersPortal(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	repo := familyPortalRepo()
+	uc := NewFamilyUsecase(repo)
+	uc.WithNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	// Nothing is shared until the client consents.
+	if _, err := uc.ListVisits(ctx, "member-1", false, 0); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected visits to need consent, got %v", err)
+	}
+
+	share := true
+	if _, err := uc.UpdateConsent(ctx, "client-1", "coordinator-1", FamilyConsentUpdate{ShareVisits: &share}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	visits, err := uc.ListVisits(ctx, "member-1", false, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(visits) != 1 || visits[0].Confirmation != nil || visits[0].Tasks != nil {
+		t.Fatalf("expected visits without tasks or confirmations, got %+v", visits)
+	}
+	if repo.filter.Upcoming || repo.filter.Limit != defaultFamilyVisitLimit || !repo.filter.Now.Equal(now) {
+		t.Fatalf("unexpected filter %+v", repo.filter)
+	}
+
+	if _, err := uc.UpdateConsent(ctx, "client-1", "coordinator-1", FamilyConsentUpdate{ShareTasks: &share, ShareConfirmations: &share}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	visit, err := uc.GetVisit(ctx, "member-1", "sched-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if visit.Confirmation == nil || visit.Confirmation.ClockInAtHome == nil || len(visit.Tasks) != 1 {
+		t.Fatalf("expected tasks and confirmation once shared, got %+v", visit)
+	}
+	if consent := repo.consents["client-1"]; consent.UpdatedBy == nil || *consent.UpdatedBy != "coordinator-1" || !consent.ShareVisits {
+		t.Fatalf("expected the consent change to be recorded, got %+v", consent)
+	}
+
+	// Withdrawn access takes effect before the member's token expires.
+	if _, err := uc.SetMemberActive(ctx, "client-1", "member-1", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := uc.GetVisit(ctx, "member-1", "sched-1"); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected inactive members to be refused, got %v", err)
+	}
+}
+
+func TestFamilyUsecaseAddMember(t *testing.T) {
+	repo := familyPortalRepo()
+	uc := NewFamilyUsecase(repo)
+	ctx := context.Background()
+
+	member, password, err := uc.AddMember(ctx, "client-1", FamilyMemberInput{Name: " Ben ", Email: "ben@example.com", Relationship: "son"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if member.Name != "Ben" || !member.Active || len(password) < 20 {
+		t.Fatalf("unexpected member %+v with password %q", member, password)
+	}
+	if member.SecretHash == password || !strings.HasPrefix(member.SecretHash, "bcrypt$") {
+		t.Fatalf("expected the password to be stored hashed, got %q", member.SecretHash)
+	}
+
+	if _, _, err := uc.AddMember(ctx, "client-1", FamilyMemberInput{Name: "Anna", Email: "ANNA@example.com"}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected a taken email to conflict, got %v", err)
+	}
+	if _, _, err := uc.AddMember(ctx, "client-2", FamilyMemberInput{Name: "Cleo", Email: "cleo@example.com"}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected an unknown client to be not found, got %v", err)
+	}
+	for _, input := range []FamilyMemberInput{
+		{Name: " ", Email: "dan@example.com"},
+		{Name: "Dan", Email: "not-an-email"},
+		{Name: "Dan", Email: "Dan <dan@example.com>"},
+	} {
+		if _, _, err := uc.AddMember(ctx, "client-1", input); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("expected validation failure for %+v, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// IncidentReport is the input for reporting an incident.
+type IncidentReport struct {
+	// ScheduleID optionally ties the incident to one of the caregiver's visits.
+	ScheduleID  string
+	Category    string
+	Severity    domain.IncidentSeverity
+	Description string
+}
+
+// IncidentUsecase lets caregivers report incidents and supervisors resolve them.
+type IncidentUsecase struct {
+	incidents repository.IncidentRepository
+	schedules repository.ScheduleRepository
+	now       func() time.Time
+}
+
+// NewIncidentUsecase constructs an IncidentUsecase.
+func NewIncidentUsecase(incidents repository.IncidentRepository, schedules repository.ScheduleRepository) *IncidentUsecase {
+	return &IncidentUsecase{
+		incidents: incidents,
+		schedules: schedules,
+		now:       time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *IncidentUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// ReportIncident records an open incident for the caregiver. A visit, when
+// given, must be one of theirs.
+func (uc *IncidentUsecase) ReportIncident(ctx context.Context, caregiverID string, report IncidentReport) (domain.Incident, error) {
+	category := strings.TrimSpace(report.Category)
+	description := strings.TrimSpace(report.Description)
+	if category == "" || description == "" || !report.Severity.Valid() {
+		return domain.Incident{}, domain.ErrValidationFailure
+	}
+
+	incident := domain.Incident{
+		CaregiverID: caregiverID,
+		Category:    category,
+		Severity:    report.Severity,
+		Description: description,
+		Status:      domain.IncidentStatusOpen,
+		ReportedAt:  uc.now(),
+	}
+	if report.ScheduleID != "" {
+		if _, err := uc.schedules.GetScheduleForCaregiver(ctx, report.ScheduleID, caregiverID); err != nil {
+			return domain.Incident{}, err
+		}
+		incident.ScheduleID = &report.ScheduleID
+	}
+	return uc.incidents.Create(ctx, incident)
+}
+
+// GetIncident returns an incident to the caregiver who reported it or to a supervisor.
+func (uc *IncidentUsecase) GetIncident(ctx context.Context, incidentID, requesterID string, supervisor bool) (domain.Incident, error) {
+	incident, err := uc.incidents.GetByID(ctx, incidentID)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	if !supervisor && incident.CaregiverID != requesterID {
+		return domain.Incident{}, domain.ErrNotFound
+	}
+	return incident, nil
+}
+
+// ResolveIncident closes an open incident. A resolution note is required.
+func (uc *IncidentUsecase) ResolveIncident(ctx context.Context, incidentID, resolverID, resolution string) (domain.Incident, error) {
+	note := optionalComment(resolution)
+	if note == nil {
+		return domain.Incident{}, domain.ErrValidationFailure
+	}
+	incident, err := uc.incidents.GetByID(ctx, incidentID)
+	if err != nil {
+		return domain.Incident{}, err
+	}
+	if incident.Status != domain.IncidentStatusOpen {
+		return domain.Incident{}, domain.ErrConflict
+	}
+	if err := uc.incidents.Resolve(ctx, incidentID, resolverID, *note, uc.now()); err != nil {
+		return domain.Incident{}, err
+	}
+	return uc.incidents.GetByID(ctx, incidentID)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.IncidentRepository = (*incidentRepoStub)(nil)
+
+type incidentRepoStub struct {
+	incidents map[string]domain.Incident
+	filter    repository.DashboardFilter
+}
+
+func (r *incidentRepoStub) Create(ctx context.Context, incident domain.Incident) (domain.Incident, error) {
+	if r.incidents == nil {
+		r.incidents = map[string]domain.Incident{}
+	}
+	incident.ID = fmt.Sprintf("incident-%d", len(r.incidents)+1)
+	r.incidents[incident.ID] = incident
+	return incident, nil
+}
+
+func (r *incidentRepoStub) GetByID(ctx context.Context, incidentID string) (domain.Incident, error) {
+	incident, ok := r.incidents[incidentID]
+	if !ok {
+		return domain.Incident{}, domain.ErrNotFound
+	}
+	return incident, nil
+}
+
+func (r *incidentRepoStub) ListOpen(ctx context.Context, filter repository.DashboardFilter) ([]domain.Incident, error) {
+	r.filter = filter
+	var open []domain.Incident
+	for _, incident := range r.incidents {
+		if incident.Status == domain.IncidentStatusOpen {
+			open = append(open, incident)
+		}
+	}
+	return open, nil
+}
+
+func (r *incidentRepoStub) Resolve(ctx context.Context, incidentID, resolverID, resolution string, at time.Time) error {
+	incident, ok := r.incidents[incidentID]
+	if !ok || incident.Status != domain.IncidentStatusOpen {
+		return domain.ErrConflict
+	}
+	incident.Status = domain.IncidentStatusResolved
+	incident.ResolvedBy = &resolverID
+	incident.ResolvedAt = &at
+	incident.Resolution = &resolution
+	r.incidents[incidentID] = incident
+	return nil
+}
+
+func TestIncidentUsecaseReportIncident(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	repo := &incidentRepoStub{}
+	schedules := &scheduleRepoStub{schedule: domain.Schedule{ID: "sched-1", CaregiverID: "cg-1"}}
+	uc := NewIncidentUsecase(repo, schedules)
+	uc.WithNow(func() time.Time { return now })
+
+	incident, err := uc.ReportIncident(context.Background(), "cg-1", IncidentReport{
+		ScheduleID:  "sched-1",
+		Category:    " fall ",
+		Severity:    domain.IncidentSeverityHigh,
+		Description: "Client slipped in the bathroom.",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if incident.Status != domain.IncidentStatusOpen || incident.Category != "fall" || !incident.ReportedAt.Equal(now) {
+		t.Fatalf("unexpected incident %+v", incident)
+	}
+	if incident.ScheduleID == nil || *incident.ScheduleID != "sched-1" {
+		t.Fatalf("expected the visit to be linked, got %v", incident.ScheduleID)
+	}
+
+	// Another caregiver's visit cannot be referenced.
+	if _, err := uc.ReportIncident(context.Background(), "cg-2", IncidentReport{
+		ScheduleID:  "sched-1",
+		Category:    "fall",
+		Severity:    domain.IncidentSeverityLow,
+		Description: "Someone else's visit.",
+	}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	for _, report := range []IncidentReport{
+		{Category: "fall", Severity: "critical", Description: "Unknown severity."},
+		{Category: " ", Severity: domain.IncidentSeverityLow, Description: "No category."},
+		{Category: "fall", Severity: domain.IncidentSeverityLow},
+	} {
+		if _, err := uc.ReportIncident(context.Background(), "cg-1", report); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("expected validation failure for %+v, got %v", report, err)
+		}
+	}
+}
+
+func TestIncidentUsecaseResolveIncident(t *testing.T) {
+	repo := &incidentRepoStub{incidents: map[string]domain.Incident{
+		"incident-1": {ID: "incident-1", CaregiverID: "cg-1", Status: domain.IncidentStatusOpen},
+	}}
+	uc := NewIncidentUsecase(repo, &scheduleRepoStub{})
+
+	if _, err := uc.ResolveIncident(context.Background(), "incident-1", "sup-1", "  "); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected a resolution to be required, got %v", err)
+	}
+	incident, err := uc.ResolveIncident(context.Background(), "incident-1", "sup-1", "Family informed, GP visit booked.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if incident.Status != domain.IncidentStatusResolved || incident.ResolvedBy == nil || *incident.ResolvedBy != "sup-1" {
+		t.Fatalf("unexpected incident %+v", incident)
+	}
+	if _, err := uc.ResolveIncident(context.Background(), "incident-1", "sup-1", "Again."); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict when resolving twice, got %v", err)
+	}
+
+	if _, err := uc.GetIncident(context.Background(), "incident-1", "cg-2", false); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected other caregivers not to see the incident, got %v", err)
+	}
+	if _, err := uc.GetIncident(context.Background(), "incident-1", "sup-1", true); err != nil {
+		t.Fatalf("expected supervisors to see the incident, got %v", err)
+	}
+}
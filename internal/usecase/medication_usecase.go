@@ -0,0 +1,448 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// doseSweepWindow is how far either side of now FlagMissedDoses puts due
+// doses on visits' MARs.
+const doseSweepWindow = 24 * time.Hour
+
+const (
+	// maxMARLimit caps the doses returned by ClientRecord.
+	maxMARLimit              = 1000
+	maxMedicationNameLength  = 200
+	maxMedicationNoteLength  = 1000
+	maxMedicationTimesPerDay = 24
+)
+
+// MedicationOrderInput describes a new medication order.
+type MedicationOrderInput struct {
+	Drug         string
+	Dose         string
+	Route        domain.MedicationRoute
+	Times        []string
+	PRN          bool
+	Instructions *string
+	// StartDate defaults to today in the agency timezone.
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// MedicationOrderUpdate changes the fields that are set. Drug, dose, route and
+// times are fixed: a changed prescription is a new order.
+type MedicationOrderUpdate struct {
+	Instructions *string
+	EndDate      *time.Time
+	ClearEndDate bool
+	Active       *bool
+}
+
+// DoseRecord is a caregiver's account of a scheduled dose.
+type DoseRecord struct {
+	Status domain.AdministrationStatus
+	Reason *string
+	// AdministeredAt defaults to now.
+	AdministeredAt *time.Time
+}
+
+// PRNDose is a dose given as needed; Reason is why it was needed.
+type PRNDose struct {
+	OrderID        string
+	Reason         *string
+	AdministeredAt *time.Time
+}
+
+// VisitMAR is the medication record of a visit.
+type VisitMAR struct {
+	Doses []domain.MedicationAdministration
+	// PRNOrders are the client's as-needed medications that may be given today.
+	PRNOrders []domain.MedicationOrder
+}
+
+// MedicationUsecase keeps client medication orders and the medication
+// administration record (MAR): the doses due during each visit and what the
+// caregiver did about them.
+type MedicationUsecase struct {
+	meds      repository.MedicationRepository
+	schedules repository.ScheduleRepository
+	// loc is the agency timezone order times are read in.
+	loc *time.Location
+	now func() time.Time
+}
+
+// NewMedicationUsecase constructs a MedicationUsecase.
+func NewMedicationUsecase(meds repository.MedicationRepository, schedules repository.ScheduleRepository, loc *time.Location) *MedicationUsecase {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &MedicationUsecase{meds: meds, schedules: schedules, loc: loc, now: time.Now}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *MedicationUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// ListOrders returns the client's orders, active ones only unless includeInactive.
+func (uc *MedicationUsecase) ListOrders(ctx context.Context, clientID string, includeInactive bool) ([]domain.MedicationOrder, error) {
+	return uc.meds.ListOrders(ctx, clientID, !includeInactive)
+}
+
+// CreateOrder records a new order for the client.
+func (uc *MedicationUsecase) CreateOrder(ctx context.Context, clientID, creatorID string, input MedicationOrderInput) (domain.MedicationOrder, error) {
+	order := domain.MedicationOrder{
+		ClientID:     clientID,
+		Drug:         strings.TrimSpace(input.Drug),
+		Dose:         strings.TrimSpace(input.Dose),
+		Route:        input.Route,
+		PRN:          input.PRN,
+		Instructions: medicationNote(input.Instructions),
+		Active:       true,
+		CreatedBy:    creatorID,
+	}
+	if order.Drug == "" || order.Dose == "" || len(order.Drug) > maxMedicationNameLength || len(order.Dose) > maxMedicationNameLength {
+		return domain.MedicationOrder{}, domain.ErrValidationFailure
+	}
+	if !order.Route.Valid() || !validMedicationNote(order.Instructions) {
+		return domain.MedicationOrder{}, domain.ErrValidationFailure
+	}
+	times, ok := doseTimes(input.Times)
+	if !ok || order.PRN != (len(times) == 0) {
+		return domain.MedicationOrder{}, domain.ErrValidationFailure
+	}
+	order.Times = times
+
+	now := uc.now()
+	order.StartDate = domain.CalendarDay(now.In(uc.loc), time.UTC)
+	if input.StartDate != nil {
+		order.StartDate = domain.CalendarDay(*input.StartDate, time.UTC)
+	}
+	if input.EndDate != nil {
+		end := domain.CalendarDay(*input.EndDate, time.UTC)
+		if end.Before(order.StartDate) {
+			return domain.MedicationOrder{}, domain.ErrValidationFailure
+		}
+		order.EndDate = &end
+	}
+	order.CreatedAt = now.UTC()
+	order.UpdatedAt = order.CreatedAt
+	return uc.meds.CreateOrder(ctx, order)
+}
+
+// UpdateOrder changes the client's order. Discontinued orders put no further
+// doses on the MAR; doses already due stay there.
+func (uc *MedicationUsecase) UpdateOrder(ctx context.Context, clientID, orderID string, update MedicationOrderUpdate) (domain.MedicationOrder, error) {
+	if update.EndDate != nil && update.ClearEndDate {
+		return domain.MedicationOrder{}, domain.ErrValidationFailure
+	}
+	order, err := uc.meds.GetOrder(ctx, orderID)
+	if err != nil {
+		return domain.MedicationOrder{}, err
+	}
+	if order.ClientID != clientID {
+		return domain.MedicationOrder{}, domain.ErrNotFound
+	}
+	if update.Instructions != nil {
+		order.Instructions = medicationNote(update.Instructions)
+		if !validMedicationNote(order.Instructions) {
+			return domain.MedicationOrder{}, domain.ErrValidationFailure
+		}
+	}
+	if update.EndDate != nil {
+		end := domain.CalendarDay(*update.EndDate, time.UTC)
+		if end.Before(domain.CalendarDay(order.StartDate, time.UTC)) {
+			return domain.MedicationOrder{}, domain.ErrValidationFailure
+		}
+		order.EndDate = &end
+	}
+	if update.ClearEndDate {
+		order.EndDate = nil
+	}
+	if update.Active != nil {
+		order.Active = *update.Active
+	}
+	order.UpdatedAt = uc.now().UTC()
+	return uc.meds.UpdateOrder(ctx, order)
+}
+
+// ClientRecord returns the client's MAR, oldest dose first. filter.From and
+// filter.To are read as calendar dates in the agency timezone, To inclusive.
+func (uc *MedicationUsecase) ClientRecord(ctx context.Context, clientID string, filter repository.MARFilter) ([]domain.MedicationAdministration, error) {
+	if filter.From != nil {
+		from := domain.CalendarDay(*filter.From, uc.loc)
+		filter.From = &from
+	}
+	if filter.To != nil {
+		to := domain.CalendarDay(*filter.To, uc.loc).AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, domain.ErrValidationFailure
+	}
+	if filter.Limit <= 0 || filter.Limit > maxMARLimit {
+		filter.Limit = maxMARLimit
+	}
+	for _, status := range filter.Status {
+		if !status.Valid() {
+			return nil, domain.ErrValidationFailure
+		}
+	}
+	return uc.meds.ListByClient(ctx, clientID, filter)
+}
+
+// VisitRecord returns the MAR of the caregiver's visit, first putting the
+// doses due during an upcoming or active visit on it.
+func (uc *MedicationUsecase) VisitRecord(ctx context.Context, caregiverID, scheduleID string) (VisitMAR, error) {
+	schedule, err := uc.schedules.GetScheduleForCaregiver(ctx, scheduleID, caregiverID)
+	if err != nil {
+		return VisitMAR{}, err
+	}
+	orders, err := uc.meds.ListOrders(ctx, schedule.Client.ID, true)
+	if err != nil {
+		return VisitMAR{}, err
+	}
+	if schedule.Status == domain.ScheduleStatusScheduled || schedule.Status == domain.ScheduleStatusInProgress {
+		if _, err := uc.meds.AddDue(ctx, uc.dueDoses(schedule, orders)); err != nil {
+			return VisitMAR{}, err
+		}
+	}
+	doses, err := uc.meds.ListBySchedule(ctx, scheduleID)
+	if err != nil {
+		return VisitMAR{}, err
+	}
+
+	today := uc.now().In(uc.loc)
+	prn := []domain.MedicationOrder{}
+	for _, order := range orders {
+		if order.PRN && order.Covers(today) {
+			prn = append(prn, order)
+		}
+	}
+	return VisitMAR{Doses: doses, PRNOrders: prn}, nil
+}
+
+// RecordDose records whether a scheduled dose of the caregiver's active visit
+// was given, refused or omitted. Refused and omitted doses need a reason.
+// A dose may be recorded again to correct it until the visit ends.
+func (uc *MedicationUsecase) RecordDose(ctx context.Context, caregiverID, scheduleID, administrationID string, record DoseRecord) (domain.MedicationAdministration, error) {
+	if !record.Status.Recordable() {
+		return domain.MedicationAdministration{}, domain.ErrValidationFailure
+	}
+	reason := medicationNote(record.Reason)
+	if !validMedicationNote(reason) || (record.Status != domain.AdministrationGiven && reason == nil) {
+		return domain.MedicationAdministration{}, domain.ErrValidationFailure
+	}
+	schedule, err := uc.activeVisit(ctx, caregiverID, scheduleID)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	dose, err := uc.meds.GetAdministration(ctx, administrationID)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	if dose.ScheduleID != schedule.ID || dose.PRN {
+		return domain.MedicationAdministration{}, domain.ErrNotFound
+	}
+	if dose.Status == domain.AdministrationMissed {
+		return domain.MedicationAdministration{}, domain.ErrInvalidStatusTransition
+	}
+	at, err := uc.administeredAt(schedule, record.AdministeredAt)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+
+	now := uc.now().UTC()
+	dose.Status = record.Status
+	dose.Reason = reason
+	dose.AdministeredAt = &at
+	dose.RecordedBy = &caregiverID
+	dose.RecordedAt = &now
+	if err := uc.meds.Record(ctx, dose); err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	return uc.meds.GetAdministration(ctx, administrationID)
+}
+
+// GivePRN records an as-needed dose given during the caregiver's active visit.
+func (uc *MedicationUsecase) GivePRN(ctx context.Context, caregiverID, scheduleID string, dose PRNDose) (domain.MedicationAdministration, error) {
+	reason := medicationNote(dose.Reason)
+	if reason == nil || !validMedicationNote(reason) {
+		return domain.MedicationAdministration{}, domain.ErrValidationFailure
+	}
+	schedule, err := uc.activeVisit(ctx, caregiverID, scheduleID)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	order, err := uc.meds.GetOrder(ctx, dose.OrderID)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	if order.ClientID != schedule.Client.ID {
+		return domain.MedicationAdministration{}, domain.ErrNotFound
+	}
+	at, err := uc.administeredAt(schedule, dose.AdministeredAt)
+	if err != nil {
+		return domain.MedicationAdministration{}, err
+	}
+	if !order.PRN || !order.Active || !order.Covers(at.In(uc.loc)) {
+		return domain.MedicationAdministration{}, domain.ErrValidationFailure
+	}
+
+	now := uc.now().UTC()
+	return uc.meds.AddPRN(ctx, domain.MedicationAdministration{
+		OrderID:        order.ID,
+		ScheduleID:     schedule.ID,
+		Status:         domain.AdministrationGiven,
+		Reason:         reason,
+		AdministeredAt: &at,
+		RecordedBy:     &caregiverID,
+		RecordedAt:     &now,
+	})
+}
+
+// PrepareVisit puts the doses due during the visit on its MAR, each with a
+// visit task standing for it.
+func (uc *MedicationUsecase) PrepareVisit(ctx context.Context, schedule domain.Schedule) error {
+	orders, err := uc.meds.ListOrders(ctx, schedule.Client.ID, true)
+	if err != nil {
+		return err
+	}
+	_, err = uc.meds.AddDue(ctx, uc.dueDoses(schedule, orders))
+	return err
+}
+
+// CloseVisit flags the doses of a visit that is over which were never recorded.
+func (uc *MedicationUsecase) CloseVisit(ctx context.Context, scheduleID string) error {
+	_, err := uc.meds.MarkMissed(ctx, scheduleID, uc.now())
+	return err
+}
+
+// FlagMissedDoses puts the doses due on visits around now on their MARs, so
+// that visits nobody clocked in to are covered, then flags the doses still
+// due on visits that are over. It returns how many doses were flagged.
+func (uc *MedicationUsecase) FlagMissedDoses(ctx context.Context) (int, error) {
+	now := uc.now()
+	visits, err := uc.meds.ListVisitsForDoses(ctx, now.Add(-doseSweepWindow), now.Add(doseSweepWindow))
+	if err != nil {
+		return 0, err
+	}
+	orders := map[string][]domain.MedicationOrder{}
+	var doses []domain.MedicationAdministration
+	for _, visit := range visits {
+		clientOrders, ok := orders[visit.Client.ID]
+		if !ok {
+			clientOrders, err = uc.meds.ListOrders(ctx, visit.Client.ID, true)
+			if err != nil {
+				return 0, err
+			}
+			orders[visit.Client.ID] = clientOrders
+		}
+		doses = append(doses, uc.dueDoses(visit, clientOrders)...)
+	}
+	if _, err := uc.meds.AddDue(ctx, doses); err != nil {
+		return 0, err
+	}
+
+	missed, err := uc.meds.MarkMissed(ctx, "", now)
+	if err != nil {
+		return 0, err
+	}
+	return len(missed), nil
+}
+
+// dueDoses lists the doses of orders due during the visit. Doses due before an
+// order was created are left out.
+func (uc *MedicationUsecase) dueDoses(schedule domain.Schedule, orders []domain.MedicationOrder) []domain.MedicationAdministration {
+	now := uc.now().UTC()
+	var doses []domain.MedicationAdministration
+	for _, order := range orders {
+		for _, at := range order.DueTimes(schedule.StartTime, schedule.EndTime, uc.loc) {
+			if at.Before(order.CreatedAt) {
+				continue
+			}
+			due := at.UTC()
+			doses = append(doses, domain.MedicationAdministration{
+				OrderID:    order.ID,
+				ScheduleID: schedule.ID,
+				DueAt:      &due,
+				Status:     domain.AdministrationDue,
+				CreatedAt:  now,
+				Drug:       order.Drug,
+				Dose:       order.Dose,
+				Route:      order.Route,
+			})
+		}
+	}
+	sort.SliceStable(doses, func(i, j int) bool { return doses[i].DueAt.Before(*doses[j].DueAt) })
+	return doses
+}
+
+func (uc *MedicationUsecase) activeVisit(ctx context.Context, caregiverID, scheduleID string) (domain.Schedule, error) {
+	schedule, err := uc.schedules.GetScheduleForCaregiver(ctx, scheduleID, caregiverID)
+	if err != nil {
+		return domain.Schedule{}, err
+	}
+	if schedule.Status != domain.ScheduleStatusInProgress {
+		return domain.Schedule{}, domain.ErrInvalidStatusTransition
+	}
+	return schedule, nil
+}
+
+// administeredAt defaults to now and must fall between clock-in and now.
+func (uc *MedicationUsecase) administeredAt(schedule domain.Schedule, at *time.Time) (time.Time, error) {
+	now := uc.now().UTC()
+	if at == nil {
+		return now, nil
+	}
+	if at.After(now) || (schedule.ClockInAt != nil && at.Before(*schedule.ClockInAt)) {
+		return time.Time{}, domain.ErrValidationFailure
+	}
+	return at.UTC(), nil
+}
+
+// doseTimes validates an order's times of day and returns them sorted without
+// duplicates.
+func doseTimes(times []string) ([]string, bool) {
+	if len(times) > maxMedicationTimesPerDay {
+		return nil, false
+	}
+	seen := map[string]bool{}
+	result := []string{}
+	for _, t := range times {
+		t = strings.TrimSpace(t)
+		if !domain.ValidDoseTime(t) || len(t) != len("15:04") {
+			return nil, false
+		}
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	sort.Strings(result)
+	return result, true
+}
+
+// medicationNote trims a free-text note; a blank note is nil.
+func medicationNote(note *string) *string {
+	if note == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*note)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func validMedicationNote(note *string) bool {
+	return note == nil || len(*note) <= maxMedicationNoteLength
+}
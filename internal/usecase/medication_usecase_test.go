@@ -0,0 +1,482 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.MedicationRepository = (*medicationRepoStub)(nil)
+
+type medicationRepoStub struct {
+	orders   []domain.MedicationOrder
+	doses    []domain.MedicationAdministration
+	visits   []domain.Schedule
+	recorded []domain.MedicationAdministration
+}
+
+func (r *medicationRepoStub) CreateOrder(ctx context.Context, order domain.MedicationOrder) (domain.MedicationOrder, error) {
+	order.ID = fmt.Sprintf("order-%d", len(r.orders)+1)
+	r.orders = append(r.orders, order)
+	return order, nil
+}
+
+func (r *medicationRepoStub) GetOrder(ctx context.Context, orderID string) (domain.MedicationOrder, error) {
+	for _, order := range r.orders {
+		if order.ID == orderID {
+			return order, nil
+		}
+	}
+	return domain.MedicationOrder{}, domain.ErrNotFound
+}
+
+func (r *medicationRepoStub) UpdateOrder(ctx context.Context, order domain.MedicationOrder) (domain.MedicationOrder, error) {
+	for i := range r.orders {
+		if r.orders[i].ID == order.ID {
+			r.orders[i] = order
+			return order, nil
+		}
+	}
+	return domain.MedicationOrder{}, domain.ErrNotFound
+}
+
+func (r *medicationRepoStub) ListOrders(ctx context.Context, clientID string, activeOnly bool) ([]domain.MedicationOrder, error) {
+	var result []domain.MedicationOrder
+	for _, order := range r.orders {
+		if order.ClientID == clientID && (order.Active || !activeOnly) {
+			result = append(result, order)
+		}
+	}
+	return result, nil
+}
+
+func (r *medicationRepoStub) ListVisitsForDoses(ctx context.Context, from, to time.Time) ([]domain.Schedule, error) {
+	return r.visits, nil
+}
+
+func (r *medicationRepoStub) AddDue(ctx context.Context, doses []domain.MedicationAdministration) (int, error) {
+	added := 0
+	for _, dose := range doses {
+		exists := false
+		for _, d := range r.doses {
+			if d.OrderID == dose.OrderID && d.ScheduleID == dose.ScheduleID && d.DueAt.Equal(*dose.DueAt) {
+				exists = true
+			}
+		}
+		if exists {
+			continue
+		}
+		dose.ID = fmt.Sprintf("dose-%d", len(r.doses)+1)
+		taskID := "task-" + dose.ID
+		dose.TaskID = &taskID
+		r.doses = append(r.doses, dose)
+		added++
+	}
+	return added, nil
+}
+
+func (r *medicationRepoStub) AddPRN(ctx context.Context, dose domain.MedicationAdministration) (domain.MedicationAdministration, error) {
+	dose.ID = fmt.Sprintf("dose-%d", len(r.doses)+1)
+	dose.PRN = true
+	r.doses = append(r.doses, dose)
+	return dose, nil
+}
+
+func (r *medicationRepoStub) GetAdministration(ctx context.Context, administrationID string) (domain.MedicationAdministration, error) {
+	for _, dose := range r.doses {
+		if dose.ID == administrationID {
+			return dose, nil
+		}
+	}
+	return domain.MedicationAdministration{}, domain.ErrNotFound
+}
+
+func (r *medicationRepoStub) ListBySchedule(ctx context.Context, scheduleID string) ([]domain.MedicationAdministration, error) {
+	var result []domain.MedicationAdministration
+	for _, dose := range r.doses {
+		if dose.ScheduleID == scheduleID {
+			result = append(result, dose)
+		}
+	}
+	return result, nil
+}
+
+func (r *medicationRepoStub) ListByClient(ctx context.Context, clientID string, filter repository.MARFilter) ([]domain.MedicationAdministration, error) {
+	return r.doses, nil
+}
+
+func (r *medicationRepoStub) Record(ctx context.Context, dose domain.MedicationAdministration) error {
+	for i := range r.doses {
+		if r.doses[i].ID == dose.ID {
+			r.doses[i] = dose
+			r.recorded = append(r.recorded, dose)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (r *medicationRepoStub) MarkMissed(ctx context.Context, scheduleID string, before time.Time) ([]domain.MedicationAdministration, error) {
+	over := map[string]bool{scheduleID: scheduleID != ""}
+	if scheduleID == "" {
+		for _, visit := range r.visits {
+			if visit.Status != domain.ScheduleStatusInProgress && !visit.EndTime.After(before) {
+				over[visit.ID] = true
+			}
+		}
+	}
+	var missed []domain.MedicationAdministration
+	for i := range r.doses {
+		if over[r.doses[i].ScheduleID] && r.doses[i].Status == domain.AdministrationDue {
+			r.doses[i].Status = domain.AdministrationMissed
+			missed = append(missed, r.doses[i])
+		}
+	}
+	return missed, nil
+}
+
+// chicago stands in for the agency timezone without needing tzdata.
+var chicago = time.FixedZone("CST", -6*60*60)
+
+func medicationOrder(id string, times ...string) domain.MedicationOrder {
+	return domain.MedicationOrder{
+		ID:        id,
+		ClientID:  "client-1",
+		Drug:      "Lisinopril",
+		Dose:      "10 mg",
+		Route:     domain.MedicationRouteOral,
+		Times:     times,
+		PRN:       len(times) == 0,
+		StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Active:    true,
+		CreatedAt: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func dueTimes(doses []domain.MedicationAdministration) []time.Time {
+	var result []time.Time
+	for _, dose := range doses {
+		result = append(result, dose.DueAt.In(chicago))
+	}
+	return result
+}
+
+func TestMedicationUsecasePrepareVisitPutsDueDosesOnMAR(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	ended := time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)
+	tomorrow := medicationOrder("order-later", "08:00")
+	tomorrow.StartDate = time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)
+	stopped := medicationOrder("order-stopped", "08:00")
+	stopped.EndDate = &ended
+	// Created at 08:30 on the 15th: its first dose is the next morning.
+	newer := medicationOrder("order-new", "08:00")
+	newer.CreatedAt = time.Date(2025, 1, 15, 8, 30, 0, 0, chicago)
+
+	repo := &medicationRepoStub{orders: []domain.MedicationOrder{
+		medicationOrder("order-1", "08:00", "12:00", "20:00"),
+		medicationOrder("order-prn"),
+		tomorrow,
+		stopped,
+		newer,
+	}}
+	uc := NewMedicationUsecase(repo, &scheduleRepoStub{}, chicago)
+	uc.WithNow(func() time.Time { return now })
+
+	morning := domain.Schedule{
+		ID:        "sched-1",
+		Client:    domain.Client{ID: "client-1"},
+		StartTime: time.Date(2025, 1, 15, 7, 30, 0, 0, chicago),
+		EndTime:   time.Date(2025, 1, 15, 12, 30, 0, 0, chicago),
+	}
+	if err := uc.PrepareVisit(context.Background(), morning); err != nil {
+		t.Fatalf("PrepareVisit error: %v", err)
+	}
+	// Preparing again adds nothing.
+	if err := uc.PrepareVisit(context.Background(), morning); err != nil {
+		t.Fatalf("PrepareVisit error: %v", err)
+	}
+	want := []time.Time{
+		time.Date(2025, 1, 15, 8, 0, 0, 0, chicago),
+		time.Date(2025, 1, 15, 12, 0, 0, 0, chicago),
+	}
+	if got := dueTimes(repo.doses); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected doses at %v, got %v", want, got)
+	}
+	for _, dose := range repo.doses {
+		if dose.OrderID != "order-1" || dose.Status != domain.AdministrationDue || dose.TaskID == nil {
+			t.Fatalf("unexpected dose %+v", dose)
+		}
+	}
+
+	overnight := domain.Schedule{
+		ID:        "sched-2",
+		Client:    domain.Client{ID: "client-1"},
+		StartTime: time.Date(2025, 1, 15, 19, 0, 0, 0, chicago),
+		EndTime:   time.Date(2025, 1, 16, 9, 0, 0, 0, chicago),
+	}
+	repo.doses = nil
+	if err := uc.PrepareVisit(context.Background(), overnight); err != nil {
+		t.Fatalf("PrepareVisit error: %v", err)
+	}
+	want = []time.Time{
+		time.Date(2025, 1, 15, 20, 0, 0, 0, chicago),
+		time.Date(2025, 1, 16, 8, 0, 0, 0, chicago),
+		time.Date(2025, 1, 16, 8, 0, 0, 0, chicago),
+		time.Date(2025, 1, 16, 8, 0, 0, 0, chicago),
+	}
+	if got := dueTimes(repo.doses); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected overnight doses at %v, got %v", want, got)
+	}
+	for _, dose := range repo.doses {
+		if dose.OrderID == "order-stopped" || dose.OrderID == "order-prn" {
+			t.Fatalf("expected no doses of stopped or as-needed orders, got %+v", dose)
+		}
+	}
+}
+
+func newMedicationFixture(now time.Time) (*MedicationUsecase, *medicationRepoStub, *scheduleRepoStub) {
+	clockIn := now.Add(-time.Hour)
+	schedules := &scheduleRepoStub{schedule: domain.Schedule{
+		ID:          "sched-1",
+		CaregiverID: "cg-1",
+		Client:      domain.Client{ID: "client-1"},
+		Status:      domain.ScheduleStatusInProgress,
+		StartTime:   now.Add(-time.Hour),
+		EndTime:     now.Add(time.Hour),
+		ClockInAt:   &clockIn,
+	}}
+	due := now.Add(-30 * time.Minute)
+	other := medicationOrder("order-other", "08:00")
+	other.ClientID = "client-2"
+	other.PRN = true
+	other.Times = nil
+	repo := &medicationRepoStub{
+		orders: []domain.MedicationOrder{medicationOrder("order-1", "08:00"), medicationOrder("order-prn"), other},
+		doses: []domain.MedicationAdministration{
+			{ID: "dose-1", OrderID: "order-1", ScheduleID: "sched-1", DueAt: &due, Status: domain.AdministrationDue},
+			{ID: "dose-2", OrderID: "order-1", ScheduleID: "sched-9", DueAt: &due, Status: domain.AdministrationDue},
+			{ID: "dose-3", OrderID: "order-1", ScheduleID: "sched-1", DueAt: &due, Status: domain.AdministrationMissed},
+		},
+	}
+	uc := NewMedicationUsecase(repo, schedules, chicago)
+	uc.WithNow(func() time.Time { return now })
+	return uc, repo, schedules
+}
+
+func TestMedicationUsecaseRecordDose(t *testing.T) {
+	now := time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC)
+	uc, repo, schedules := newMedicationFixture(now)
+	ctx := context.Background()
+	refusal := "Client felt nauseous"
+	early := now.Add(-2 * time.Hour)
+	later := now.Add(time.Minute)
+
+	cases := []struct {
+		name   string
+		doseID string
+		record DoseRecord
+		want   error
+	}{
+		{"refused without reason", "dose-1", DoseRecord{Status: domain.AdministrationRefused}, domain.ErrValidationFailure},
+		{"unrecordable status", "dose-1", DoseRecord{Status: domain.AdministrationMissed}, domain.ErrValidationFailure},
+		{"given before clock-in", "dose-1", DoseRecord{Status: domain.AdministrationGiven, AdministeredAt: &early}, domain.ErrValidationFailure},
+		{"given in the future", "dose-1", DoseRecord{Status: domain.AdministrationGiven, AdministeredAt: &later}, domain.ErrValidationFailure},
+		{"dose of another visit", "dose-2", DoseRecord{Status: domain.AdministrationGiven}, domain.ErrNotFound},
+		{"missed dose", "dose-3", DoseRecord{Status: domain.AdministrationGiven}, domain.ErrInvalidStatusTransition},
+	}
+	for _, tc := range cases {
+		if _, err := uc.RecordDose(ctx, "cg-1", "sched-1", tc.doseID, tc.record); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	dose, err := uc.RecordDose(ctx, "cg-1", "sched-1", "dose-1", DoseRecord{Status: domain.AdministrationRefused, Reason: &refusal})
+	if err != nil {
+		t.Fatalf("RecordDose error: %v", err)
+	}
+	if dose.Status != domain.AdministrationRefused || *dose.Reason != refusal || !dose.AdministeredAt.Equal(now) || *dose.RecordedBy != "cg-1" {
+		t.Fatalf("unexpected dose %+v", dose)
+	}
+
+	// A recorded dose may be corrected while the visit is in progress.
+	given := now.Add(-10 * time.Minute)
+	dose, err = uc.RecordDose(ctx, "cg-1", "sched-1", "dose-1", DoseRecord{Status: domain.AdministrationGiven, AdministeredAt: &given})
+	if err != nil {
+		t.Fatalf("RecordDose error: %v", err)
+	}
+	if dose.Status != domain.AdministrationGiven || dose.Reason != nil || !dose.AdministeredAt.Equal(given) || len(repo.recorded) != 2 {
+		t.Fatalf("unexpected corrected dose %+v", dose)
+	}
+
+	schedules.schedule.Status = domain.ScheduleStatusCompleted
+	if _, err := uc.RecordDose(ctx, "cg-1", "sched-1", "dose-1", DoseRecord{Status: domain.AdministrationGiven}); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Fatalf("expected doses of finished visits locked, got %v", err)
+	}
+	if _, err := uc.RecordDose(ctx, "cg-2", "sched-1", "dose-1", DoseRecord{Status: domain.AdministrationGiven}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected another caregiver's visit hidden, got %v", err)
+	}
+}
+
+func TestMedicationUsecaseGivePRN(t *testing.T) {
+	now := time.Date(2025, 1, 15, 15, 0, 0, 0, time.UTC)
+	uc, repo, _ := newMedicationFixture(now)
+	ctx := context.Background()
+	pain := "Headache"
+
+	cases := []struct {
+		name string
+		dose PRNDose
+		want error
+	}{
+		{"no reason", PRNDose{OrderID: "order-prn"}, domain.ErrValidationFailure},
+		{"scheduled order", PRNDose{OrderID: "order-1", Reason: &pain}, domain.ErrValidationFailure},
+		{"another client's order", PRNDose{OrderID: "order-other", Reason: &pain}, domain.ErrNotFound},
+		{"unknown order", PRNDose{OrderID: "order-9", Reason: &pain}, domain.ErrNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := uc.GivePRN(ctx, "cg-1", "sched-1", tc.dose); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	dose, err := uc.GivePRN(ctx, "cg-1", "sched-1", PRNDose{OrderID: "order-prn", Reason: &pain})
+	if err != nil {
+		t.Fatalf("GivePRN error: %v", err)
+	}
+	if dose.Status != domain.AdministrationGiven || dose.DueAt != nil || *dose.Reason != pain || len(repo.doses) != 4 {
+		t.Fatalf("unexpected prn dose %+v", dose)
+	}
+
+	mar, err := uc.VisitRecord(ctx, "cg-1", "sched-1")
+	if err != nil {
+		t.Fatalf("VisitRecord error: %v", err)
+	}
+	if len(mar.PRNOrders) != 1 || mar.PRNOrders[0].ID != "order-prn" {
+		t.Fatalf("expected the client's prn order offered, got %+v", mar.PRNOrders)
+	}
+}
+
+func TestMedicationUsecaseCreateOrder(t *testing.T) {
+	now := time.Date(2025, 1, 16, 3, 0, 0, 0, time.UTC) // still the 15th in the agency timezone
+	repo := &medicationRepoStub{}
+	uc := NewMedicationUsecase(repo, &scheduleRepoStub{}, chicago)
+	uc.WithNow(func() time.Time { return now })
+	ctx := context.Background()
+	yesterday := time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)
+
+	valid := MedicationOrderInput{Drug: " Metformin ", Dose: "500 mg", Route: domain.MedicationRouteOral, Times: []string{"20:00", "08:00", "08:00"}}
+	invalid := []struct {
+		name  string
+		input func(MedicationOrderInput) MedicationOrderInput
+	}{
+		{"missing drug", func(in MedicationOrderInput) MedicationOrderInput { in.Drug = " "; return in }},
+		{"unknown route", func(in MedicationOrderInput) MedicationOrderInput { in.Route = "by mouth"; return in }},
+		{"no times", func(in MedicationOrderInput) MedicationOrderInput { in.Times = nil; return in }},
+		{"prn with times", func(in MedicationOrderInput) MedicationOrderInput { in.PRN = true; return in }},
+		{"bad time", func(in MedicationOrderInput) MedicationOrderInput { in.Times = []string{"8:00"}; return in }},
+		{"ends before it starts", func(in MedicationOrderInput) MedicationOrderInput { in.EndDate = &yesterday; return in }},
+	}
+	for _, tc := range invalid {
+		if _, err := uc.CreateOrder(ctx, "client-1", "coord-1", tc.input(valid)); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("%s: expected validation failure, got %v", tc.name, err)
+		}
+	}
+
+	order, err := uc.CreateOrder(ctx, "client-1", "coord-1", valid)
+	if err != nil {
+		t.Fatalf("CreateOrder error: %v", err)
+	}
+	if order.Drug != "Metformin" || !reflect.DeepEqual(order.Times, []string{"08:00", "20:00"}) || !order.Active || order.CreatedBy != "coord-1" {
+		t.Fatalf("unexpected order %+v", order)
+	}
+	if !order.StartDate.Equal(time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the order to start today in the agency timezone, got %v", order.StartDate)
+	}
+
+	stop := false
+	updated, err := uc.UpdateOrder(ctx, "client-1", order.ID, MedicationOrderUpdate{Active: &stop})
+	if err != nil {
+		t.Fatalf("UpdateOrder error: %v", err)
+	}
+	if updated.Active {
+		t.Fatalf("expected the order discontinued")
+	}
+	if _, err := uc.UpdateOrder(ctx, "client-2", order.ID, MedicationOrderUpdate{Active: &stop}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected another client's order hidden, got %v", err)
+	}
+}
+
+func TestMedicationUsecaseFlagMissedDoses(t *testing.T) {
+	now := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+	repo := &medicationRepoStub{
+		orders: []domain.MedicationOrder{medicationOrder("order-1", "08:00")},
+		visits: []domain.Schedule{
+			{
+				// Nobody clocked in to the morning visit.
+				ID:        "sched-1",
+				Client:    domain.Client{ID: "client-1"},
+				Status:    domain.ScheduleStatusScheduled,
+				StartTime: time.Date(2025, 1, 15, 7, 0, 0, 0, chicago),
+				EndTime:   time.Date(2025, 1, 15, 9, 0, 0, 0, chicago),
+			},
+			{
+				ID:        "sched-2",
+				Client:    domain.Client{ID: "client-1"},
+				Status:    domain.ScheduleStatusScheduled,
+				StartTime: time.Date(2025, 1, 16, 7, 0, 0, 0, chicago),
+				EndTime:   time.Date(2025, 1, 16, 9, 0, 0, 0, chicago),
+			},
+		},
+	}
+	uc := NewMedicationUsecase(repo, &scheduleRepoStub{}, chicago)
+	uc.WithNow(func() time.Time { return now })
+
+	flagged, err := uc.FlagMissedDoses(context.Background())
+	if err != nil {
+		t.Fatalf("FlagMissedDoses error: %v", err)
+	}
+	if flagged != 1 || len(repo.doses) != 2 {
+		t.Fatalf("expected one of two doses flagged, got %d of %+v", flagged, repo.doses)
+	}
+	if repo.doses[0].ScheduleID != "sched-1" || repo.doses[0].Status != domain.AdministrationMissed || repo.doses[1].Status != domain.AdministrationDue {
+		t.Fatalf("expected only the past visit's dose missed, got %+v", repo.doses)
+	}
+}
+
+func TestScheduleUsecaseKeepsMARWithClockEvents(t *testing.T) {
+	now := time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)
+	schedules := &scheduleRepoStub{schedule: domain.Schedule{
+		ID:          "sched-1",
+		CaregiverID: "cg-1",
+		Client:      domain.Client{ID: "client-1"},
+		Status:      domain.ScheduleStatusScheduled,
+		StartTime:   time.Date(2025, 1, 15, 7, 30, 0, 0, chicago),
+		EndTime:     time.Date(2025, 1, 15, 9, 0, 0, 0, chicago),
+	}}
+	repo := &medicationRepoStub{orders: []domain.MedicationOrder{medicationOrder("order-1", "08:00")}}
+	meds := NewMedicationUsecase(repo, schedules, chicago)
+	meds.WithNow(func() time.Time { return now })
+	uc := NewScheduleUsecase(schedules, &taskRepoStub{}, nil)
+	uc.WithNow(func() time.Time { return now })
+	uc.WithMedications(meds)
+	ctx := context.Background()
+
+	if _, err := uc.StartSchedule(ctx, "sched-1", "cg-1", domain.VisitEvent{}); err != nil {
+		t.Fatalf("StartSchedule error: %v", err)
+	}
+	if len(repo.doses) != 1 || repo.doses[0].Status != domain.AdministrationDue {
+		t.Fatalf("expected the 08:00 dose due at clock-in, got %+v", repo.doses)
+	}
+	if _, err := uc.EndSchedule(ctx, "sched-1", "cg-1", domain.VisitEvent{}); err != nil {
+		t.Fatalf("EndSchedule error: %v", err)
+	}
+	if repo.doses[0].Status != domain.AdministrationMissed {
+		t.Fatalf("expected the unrecorded dose missed at clock-out, got %+v", repo.doses[0])
+	}
+}
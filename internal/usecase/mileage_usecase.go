@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// MileageClaimRequest is the input for claiming a day's travel.
+type MileageClaimRequest struct {
+	Date time.Time
+	// ClaimedKm overrides the computed distance; Reason is then required.
+	ClaimedKm *float64
+	Reason    string
+}
+
+// MileageUsecase computes travel between visits and runs the reimbursement
+// claim workflow.
+type MileageUsecase struct {
+	mileage   repository.MileageRepository
+	zones     *TimezoneResolver
+	ratePerKm float64
+	// timesheets is set when locked timesheet periods must reject claims.
+	timesheets repository.TimesheetRepository
+	now        func() time.Time
+}
+
+// NewMileageUsecase constructs a MileageUsecase reimbursing ratePerKm per kilometre.
+func NewMileageUsecase(mileage repository.MileageRepository, zones *TimezoneResolver, ratePerKm float64) *MileageUsecase {
+	return &MileageUsecase{
+		mileage:   mileage,
+		zones:     zones,
+		ratePerKm: ratePerKm,
+		now:       time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *MileageUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// WithTimesheetLocks rejects claims for days in a locked timesheet period.
+func (uc *MileageUsecase) WithTimesheetLocks(timesheets repository.TimesheetRepository) {
+	uc.timesheets = timesheets
+}
+
+// DailyMileage returns the travel between the caregiver's visits on the calendar
+// date of day (today when nil) and any claim already made for it.
+func (uc *MileageUsecase) DailyMileage(ctx context.Context, caregiverID string, day *time.Time) (domain.MileageDay, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.MileageDay{}, err
+	}
+	start, _ := domain.DayBounds(uc.now(), loc)
+	if day != nil {
+		start = domain.CalendarDay(*day, loc)
+	}
+
+	result, err := uc.computeDay(ctx, caregiverID, start)
+	if err != nil {
+		return domain.MileageDay{}, err
+	}
+	claim, err := uc.mileage.FindByDate(ctx, caregiverID, start)
+	switch {
+	case err == nil:
+		result.Claim = &claim
+	case !errors.Is(err, domain.ErrNotFound):
+		return domain.MileageDay{}, err
+	}
+	return result, nil
+}
+
+// SubmitClaim claims reimbursement for a past or current day's travel. The
+// computed legs are stored with the claim so later visit corrections do not
+// change what was reviewed.
+func (uc *MileageUsecase) SubmitClaim(ctx context.Context, caregiverID string, req MileageClaimRequest) (domain.MileageClaim, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	now := uc.now()
+	day := domain.CalendarDay(req.Date, loc)
+	if day.After(now) {
+		return domain.MileageClaim{}, domain.ErrValidationFailure
+	}
+	if err := uc.ensureUnlocked(ctx, caregiverID, day); err != nil {
+		return domain.MileageClaim{}, err
+	}
+
+	computed, err := uc.computeDay(ctx, caregiverID, day)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	claim := domain.MileageClaim{
+		CaregiverID:      caregiverID,
+		ClaimDate:        day,
+		Legs:             computed.Legs,
+		ComputedKm:       computed.ComputedKm,
+		ClaimedKm:        computed.ComputedKm,
+		AdjustmentReason: optionalComment(req.Reason),
+		RatePerKm:        uc.ratePerKm,
+		SubmittedAt:      now,
+	}
+	if req.ClaimedKm != nil {
+		if *req.ClaimedKm < 0 {
+			return domain.MileageClaim{}, domain.ErrValidationFailure
+		}
+		claim.ClaimedKm = roundKm(*req.ClaimedKm)
+	}
+	if claim.ClaimedKm == 0 || (claim.Adjusted() && claim.AdjustmentReason == nil) {
+		return domain.MileageClaim{}, domain.ErrValidationFailure
+	}
+	claim.Amount = math.Round(claim.ClaimedKm*claim.RatePerKm*100) / 100
+
+	id, err := uc.mileage.SaveClaim(ctx, claim)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	return uc.mileage.GetClaim(ctx, id)
+}
+
+// GetClaim returns a claim to its caregiver or a reviewer.
+func (uc *MileageUsecase) GetClaim(ctx context.Context, claimID, requesterID string, reviewer bool) (domain.MileageClaim, error) {
+	claim, err := uc.mileage.GetClaim(ctx, claimID)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	if !reviewer && claim.CaregiverID != requesterID {
+		return domain.MileageClaim{}, domain.ErrNotFound
+	}
+	return claim, nil
+}
+
+// ListClaims returns the caregiver's claims, newest first.
+func (uc *MileageUsecase) ListClaims(ctx context.Context, caregiverID string) ([]domain.MileageClaim, error) {
+	return uc.mileage.ListByCaregiver(ctx, caregiverID)
+}
+
+// ListPending returns claims awaiting review, oldest first.
+func (uc *MileageUsecase) ListPending(ctx context.Context) ([]domain.MileageClaim, error) {
+	return uc.mileage.ListByStatus(ctx, domain.MileageClaimStatusSubmitted)
+}
+
+// ApproveClaim accepts a submitted claim for payment. Reviewers cannot approve
+// their own claims.
+func (uc *MileageUsecase) ApproveClaim(ctx context.Context, claimID, reviewerID, comment string) (domain.MileageClaim, error) {
+	claim, err := uc.pendingClaim(ctx, claimID, reviewerID)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	loc, err := uc.zones.Location(ctx, claim.CaregiverID)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	if err := uc.ensureUnlocked(ctx, claim.CaregiverID, domain.CalendarDay(claim.ClaimDate, loc)); err != nil {
+		return domain.MileageClaim{}, err
+	}
+	if err := uc.mileage.Approve(ctx, claimID, reviewerID, optionalComment(comment), uc.now()); err != nil {
+		return domain.MileageClaim{}, err
+	}
+	return uc.mileage.GetClaim(ctx, claimID)
+}
+
+// RejectClaim returns a submitted claim to its caregiver. A comment is required.
+func (uc *MileageUsecase) RejectClaim(ctx context.Context, claimID, reviewerID, comment string) (domain.MileageClaim, error) {
+	reason := optionalComment(comment)
+	if reason == nil {
+		return domain.MileageClaim{}, domain.ErrValidationFailure
+	}
+	if _, err := uc.pendingClaim(ctx, claimID, reviewerID); err != nil {
+		return domain.MileageClaim{}, err
+	}
+	if err := uc.mileage.Reject(ctx, claimID, reviewerID, reason, uc.now()); err != nil {
+		return domain.MileageClaim{}, err
+	}
+	return uc.mileage.GetClaim(ctx, claimID)
+}
+
+func (uc *MileageUsecase) computeDay(ctx context.Context, caregiverID string, day time.Time) (domain.MileageDay, error) {
+	stops, err := uc.mileage.ListVisitStops(ctx, caregiverID, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return domain.MileageDay{}, err
+	}
+	result := domain.MileageDay{Date: day, Legs: mileageLegs(stops)}
+	for _, leg := range result.Legs {
+		result.ComputedKm += leg.DistanceKm
+	}
+	result.ComputedKm = roundKm(result.ComputedKm)
+	return result, nil
+}
+
+func (uc *MileageUsecase) pendingClaim(ctx context.Context, claimID, reviewerID string) (domain.MileageClaim, error) {
+	claim, err := uc.mileage.GetClaim(ctx, claimID)
+	if err != nil {
+		return domain.MileageClaim{}, err
+	}
+	if claim.Status != domain.MileageClaimStatusSubmitted {
+		return domain.MileageClaim{}, domain.ErrInvalidStatusTransition
+	}
+	if claim.CaregiverID == reviewerID {
+		return domain.MileageClaim{}, domain.ErrForbidden
+	}
+	return claim, nil
+}
+
+// ensureUnlocked returns domain.ErrConflict when day falls in one of the
+// caregiver's locked timesheet periods.
+func (uc *MileageUsecase) ensureUnlocked(ctx context.Context, caregiverID string, day time.Time) error {
+	if uc.timesheets == nil {
+		return nil
+	}
+	locked, err := uc.timesheets.IsLocked(ctx, caregiverID, day)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// mileageLegs measures travel between consecutive stops, from where the caregiver
+// clocked out of one visit to where they clocked in to the next. Missing positions
+// fall back to the client's address; legs with no usable position are skipped.
+func mileageLegs(stops []domain.MileageStop) []domain.MileageLeg {
+	legs := make([]domain.MileageLeg, 0, len(stops))
+	for i := 1; i < len(stops); i++ {
+		from, to := stops[i-1], stops[i]
+		fromLat, fromLong, fromGPS := stopPosition(from.ClockOutLat, from.ClockOutLong, from)
+		toLat, toLong, toGPS := stopPosition(to.ClockInLat, to.ClockInLong, to)
+		if fromLat == nil || toLat == nil {
+			continue
+		}
+
+		source := domain.MileageSourceGPS
+		if !fromGPS || !toGPS {
+			source = domain.MileageSourceClient
+		}
+		legs = append(legs, domain.MileageLeg{
+			FromScheduleID: from.ScheduleID,
+			ToScheduleID:   to.ScheduleID,
+			DepartedAt:     from.ClockOutAt,
+			ArrivedAt:      to.ClockInAt,
+			DistanceKm:     roundKm(domain.DistanceKm(*fromLat, *fromLong, *toLat, *toLong)),
+			Source:         source,
+		})
+	}
+	return legs
+}
+
+// stopPosition prefers the recorded position and reports whether it was used.
+func stopPosition(lat, long *float64, stop domain.MileageStop) (*float64, *float64, bool) {
+	if lat != nil && long != nil {
+		return lat, long, true
+	}
+	if stop.ClientLat != nil && stop.ClientLong != nil {
+		return stop.ClientLat, stop.ClientLong, false
+	}
+	return nil, nil, false
+}
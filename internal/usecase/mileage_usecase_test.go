@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.MileageRepository = (*mileageRepoStub)(nil)
+
+type mileageRepoStub struct {
+	stops    []domain.MileageStop
+	from, to time.Time
+	claims   map[string]domain.MileageClaim
+}
+
+func (r *mileageRepoStub) ListVisitStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.MileageStop, error) {
+	r.from, r.to = from, to
+	return r.stops, nil
+}
+
+func (r *mileageRepoStub) SaveClaim(ctx context.Context, claim domain.MileageClaim) (string, error) {
+	id := fmt.Sprintf("claim-%d", len(r.claims)+1)
+	for existingID, existing := range r.claims {
+		if existing.CaregiverID == claim.CaregiverID && existing.ClaimDate.Equal(claim.ClaimDate) {
+			if existing.Status != domain.MileageClaimStatusRejected {
+				return "", domain.ErrConflict
+			}
+			id = existingID
+		}
+	}
+	claim.ID = id
+	claim.Status = domain.MileageClaimStatusSubmitted
+	r.claims[id] = claim
+	return id, nil
+}
+
+func (r *mileageRepoStub) GetClaim(ctx context.Context, claimID string) (domain.MileageClaim, error) {
+	claim, ok := r.claims[claimID]
+	if !ok {
+		return domain.MileageClaim{}, domain.ErrNotFound
+	}
+	return claim, nil
+}
+
+func (r *mileageRepoStub) FindByDate(ctx context.Context, caregiverID string, day time.Time) (domain.MileageClaim, error) {
+	for _, claim := range r.claims {
+		if claim.CaregiverID == caregiverID && claim.ClaimDate.Equal(day) {
+			return claim, nil
+		}
+	}
+	return domain.MileageClaim{}, domain.ErrNotFound
+}
+
+func (r *mileageRepoStub) ListByCaregiver(ctx context.Context, caregiverID string) ([]domain.MileageClaim, error) {
+	return nil, nil
+}
+
+func (r *mileageRepoStub) ListByStatus(ctx context.Context, status domain.MileageClaimStatus) ([]domain.MileageClaim, error) {
+	return nil, nil
+}
+
+func (r *mileageRepoStub) ListApproved(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.MileageClaim, error) {
+	var result []domain.MileageClaim
+	for _, claim := range r.claims {
+		date := claim.ClaimDate.Format("2006-01-02")
+		if claim.Status == domain.MileageClaimStatusApproved && date >= from.Format("2006-01-02") && date < to.Format("2006-01-02") {
+			result = append(result, claim)
+		}
+	}
+	return result, nil
+}
+
+func (r *mileageRepoStub) Approve(ctx context.Context, claimID, reviewerID string, comment *string, at time.Time) error {
+	claim := r.claims[claimID]
+	claim.Status = domain.MileageClaimStatusApproved
+	claim.ReviewedBy = &reviewerID
+	r.claims[claimID] = claim
+	return nil
+}
+
+func (r *mileageRepoStub) Reject(ctx context.Context, claimID, reviewerID string, comment *string, at time.Time) error {
+	claim := r.claims[claimID]
+	claim.Status = domain.MileageClaimStatusRejected
+	claim.ReviewComment = comment
+	r.claims[claimID] = claim
+	return nil
+}
+
+// mileageDay is the day used by the mileage fixtures.
+var mileageDay = time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+func mileageStops() []domain.MileageStop {
+	out1 := mileageDay.Add(10 * time.Hour)
+	out2 := mileageDay.Add(13 * time.Hour)
+	return []domain.MileageStop{
+		{
+			ScheduleID: "sched-1", ClockInAt: mileageDay.Add(9 * time.Hour), ClockOutAt: &out1,
+			ClockOutLat: floatPtr(44.9778), ClockOutLong: floatPtr(-93.2650),
+			ClientLat: floatPtr(44.9778), ClientLong: floatPtr(-93.2650),
+		},
+		{
+			ScheduleID: "sched-2", ClockInAt: mileageDay.Add(11 * time.Hour), ClockOutAt: &out2,
+			ClockInLat: floatPtr(44.9537), ClockInLong: floatPtr(-93.0900),
+			ClientLat: floatPtr(44.9537), ClientLong: floatPtr(-93.0900),
+		},
+		{
+			// No clock-in position recorded: measured to the client's address.
+			ScheduleID: "sched-3", ClockInAt: mileageDay.Add(14 * time.Hour),
+			ClientLat: floatPtr(45.0105), ClientLong: floatPtr(-93.0900),
+		},
+	}
+}
+
+func newMileageFixture() (*MileageUsecase, *mileageRepoStub) {
+	repo := &mileageRepoStub{stops: mileageStops(), claims: map[string]domain.MileageClaim{}}
+	uc := NewMileageUsecase(repo, nil, 0.4)
+	uc.WithNow(func() time.Time { return mileageDay.Add(18 * time.Hour) })
+	return uc, repo
+}
+
+func TestMileageUsecaseDailyMileage(t *testing.T) {
+	uc, repo := newMileageFixture()
+
+	day, err := uc.DailyMileage(context.Background(), "cg-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.from.Equal(mileageDay) || !repo.to.Equal(mileageDay.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected day bounds: %s - %s", repo.from, repo.to)
+	}
+	if len(day.Legs) != 2 || day.Claim != nil {
+		t.Fatalf("unexpected mileage day: %+v", day)
+	}
+	first, second := day.Legs[0], day.Legs[1]
+	if first.Source != domain.MileageSourceGPS || first.DistanceKm != 14.03 {
+		t.Fatalf("unexpected first leg: %+v", first)
+	}
+	if second.Source != domain.MileageSourceClient || second.FromScheduleID != "sched-2" || second.DepartedAt == nil {
+		t.Fatalf("unexpected second leg: %+v", second)
+	}
+	if day.ComputedKm != roundKm(first.DistanceKm+second.DistanceKm) {
+		t.Fatalf("unexpected total: %v", day.ComputedKm)
+	}
+}
+
+func TestMileageUsecaseClaimValidation(t *testing.T) {
+	uc, _ := newMileageFixture()
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		req  MileageClaimRequest
+	}{
+		{"future day", MileageClaimRequest{Date: mileageDay.AddDate(0, 0, 1)}},
+		{"negative distance", MileageClaimRequest{Date: mileageDay, ClaimedKm: floatPtr(-1), Reason: "typo"}},
+		{"adjustment without reason", MileageClaimRequest{Date: mileageDay, ClaimedKm: floatPtr(40)}},
+		{"nothing to claim", MileageClaimRequest{Date: mileageDay, ClaimedKm: floatPtr(0), Reason: "stayed home"}},
+	}
+	for _, tc := range cases {
+		if _, err := uc.SubmitClaim(ctx, "cg-1", tc.req); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("%s: expected validation failure, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestMileageUsecaseClaimWorkflow(t *testing.T) {
+	uc, _ := newMileageFixture()
+	ctx := context.Background()
+
+	claim, err := uc.SubmitClaim(ctx, "cg-1", MileageClaimRequest{
+		Date:      mileageDay,
+		ClaimedKm: floatPtr(25),
+		Reason:    "Road closure on the way to the second client",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claim.Status != domain.MileageClaimStatusSubmitted || !claim.Adjusted() || len(claim.Legs) != 2 || claim.Amount != 10 {
+		t.Fatalf("unexpected claim: %+v", claim)
+	}
+	if _, err := uc.SubmitClaim(ctx, "cg-1", MileageClaimRequest{Date: mileageDay}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for second claim, got %v", err)
+	}
+
+	if _, err := uc.ApproveClaim(ctx, claim.ID, "cg-1", ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected self-approval to be forbidden, got %v", err)
+	}
+	if _, err := uc.RejectClaim(ctx, claim.ID, "coordinator-1", ""); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected comment to be required, got %v", err)
+	}
+	if _, err := uc.RejectClaim(ctx, claim.ID, "coordinator-1", "Please claim the computed distance"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resubmitted, err := uc.SubmitClaim(ctx, "cg-1", MileageClaimRequest{Date: mileageDay})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resubmitted.ID != claim.ID || resubmitted.Adjusted() {
+		t.Fatalf("unexpected resubmission: %+v", resubmitted)
+	}
+	approved, err := uc.ApproveClaim(ctx, claim.ID, "coordinator-1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approved.Status != domain.MileageClaimStatusApproved {
+		t.Fatalf("unexpected approval: %+v", approved)
+	}
+	if _, err := uc.ApproveClaim(ctx, claim.ID, "coordinator-1", ""); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Fatalf("expected decided claim to be final, got %v", err)
+	}
+
+	if _, err := uc.GetClaim(ctx, claim.ID, "cg-2", false); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected other caregivers not to see the claim, got %v", err)
+	}
+}
+
+func TestMileageUsecaseRejectsLockedPeriod(t *testing.T) {
+	uc, _ := newMileageFixture()
+	uc.WithTimesheetLocks(&timesheetRepoStub{timesheets: map[string]domain.Timesheet{
+		"timesheet-1": {
+			ID:          "timesheet-1",
+			CaregiverID: "cg-1",
+			PeriodStart: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:   time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+			Status:      domain.TimesheetStatusLocked,
+		},
+	}})
+
+	if _, err := uc.SubmitClaim(context.Background(), "cg-1", MileageClaimRequest{Date: mileageDay}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for a locked period, got %v", err)
+	}
+}
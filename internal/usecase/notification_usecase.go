@@ -0,0 +1,422 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+	// clockOutNudgeWindow bounds how long after a visit's end a missing
+	// clock-out is still worth a nudge, so old visits are not picked up.
+	clockOutNudgeWindow = 12 * time.Hour
+)
+
+// NotificationSender delivers rendered messages over one channel, such as a
+// push provider, an SMS gateway or an email service.
+type NotificationSender interface {
+	Send(ctx context.Context, message domain.NotificationMessage) error
+}
+
+// NotificationTemplate is the text/template source for one kind of
+// notification. Subject is only used by email.
+type NotificationTemplate struct {
+	Subject string
+	Body    string
+}
+
+// DefaultNotificationTemplates are used for kinds without a template of their own.
+var DefaultNotificationTemplates = map[domain.NotificationKind]NotificationTemplate{
+	domain.NotificationVisitReminder: {
+		Subject: "Upcoming visit at {{.Start}}",
+		Body:    "Hi {{.CaregiverName}}, your {{.ServiceName}} visit with {{.ClientName}} starts at {{.Start}}{{if .Location}} at {{.Location}}{{end}}.",
+	},
+	domain.NotificationVisitAssigned: {
+		Subject: "New visit on {{.Date}}",
+		Body:    "Hi {{.CaregiverName}}, you have been assigned a {{.ServiceName}} visit with {{.ClientName}} on {{.Date}} from {{.Start}} to {{.End}}.",
+	},
+	domain.NotificationClockOutReminder: {
+		Subject: "Did you forget to clock out?",
+		Body:    "Hi {{.CaregiverName}}, your visit with {{.ClientName}} was due to end at {{.End}}. Please clock out if you have finished.",
+	},
+}
+
+// notificationData is what templates can refer to; times are in the
+// caregiver's timezone.
+type notificationData struct {
+	CaregiverName string
+	ClientName    string
+	ServiceName   string
+	Location      string
+	Date          string
+	Start         string
+	End           string
+}
+
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// NotificationPolicy sets when timed notifications go out.
+type NotificationPolicy struct {
+	// ReminderLead is how long before a visit starts its reminder is sent.
+	ReminderLead time.Duration
+	// ClockOutGrace is how long after a visit's end a missing clock-out is nudged.
+	ClockOutGrace time.Duration
+}
+
+// NotificationPreferencesUpdate changes the fields that are not nil. An empty
+// Phone, PushToken or Email clears it; QuietStart and QuietEnd ("HH:MM") are
+// set together, and both empty turns quiet hours off.
+type NotificationPreferencesUpdate struct {
+	Channels      *[]domain.NotificationChannel
+	DisabledKinds *[]domain.NotificationKind
+	Phone         *string
+	PushToken     *string
+	Email         *string
+	QuietStart    *string
+	QuietEnd      *string
+}
+
+// NotificationUsecase sends caregivers reminders and alerts over the channels
+// they prefer, and keeps a log of every notification.
+type NotificationUsecase struct {
+	notifications repository.NotificationRepository
+	zones         *TimezoneResolver
+	policy        NotificationPolicy
+	senders       map[domain.NotificationChannel]NotificationSender
+	templates     map[domain.NotificationKind]compiledTemplate
+	now           func() time.Time
+}
+
+// NewNotificationUsecase constructs a NotificationUsecase using the default
+// templates and no channels; add channels with WithSender.
+func NewNotificationUsecase(notifications repository.NotificationRepository, zones *TimezoneResolver, policy NotificationPolicy) *NotificationUsecase {
+	uc := &NotificationUsecase{
+		notifications: notifications,
+		zones:         zones,
+		policy:        policy,
+		senders:       map[domain.NotificationChannel]NotificationSender{},
+		templates:     map[domain.NotificationKind]compiledTemplate{},
+		now:           time.Now,
+	}
+	if err := uc.WithTemplates(DefaultNotificationTemplates); err != nil {
+		panic(err)
+	}
+	return uc
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *NotificationUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// WithSender delivers notifications for the channel through sender.
+func (uc *NotificationUsecase) WithSender(channel domain.NotificationChannel, sender NotificationSender) {
+	if sender != nil {
+		uc.senders[channel] = sender
+	}
+}
+
+// WithTemplates replaces the templates of the given kinds.
+func (uc *NotificationUsecase) WithTemplates(templates map[domain.NotificationKind]NotificationTemplate) error {
+	for kind, source := range templates {
+		subject, err := template.New(string(kind) + ".subject").Parse(source.Subject)
+		if err != nil {
+			return err
+		}
+		body, err := template.New(string(kind) + ".body").Parse(source.Body)
+		if err != nil {
+			return err
+		}
+		uc.templates[kind] = compiledTemplate{subject: subject, body: body}
+	}
+	return nil
+}
+
+// GetPreferences returns the caregiver's preferences, or the defaults when
+// they have not saved any.
+func (uc *NotificationUsecase) GetPreferences(ctx context.Context, caregiverID string) (domain.NotificationPreferences, error) {
+	prefs, err := uc.notifications.GetPreferences(ctx, caregiverID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.DefaultNotificationPreferences(caregiverID), nil
+	}
+	return prefs, err
+}
+
+// UpdatePreferences validates and saves a change to the caregiver's preferences.
+func (uc *NotificationUsecase) UpdatePreferences(ctx context.Context, caregiverID string, update NotificationPreferencesUpdate) (domain.NotificationPreferences, error) {
+	prefs, err := uc.GetPreferences(ctx, caregiverID)
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+
+	if update.Channels != nil {
+		channels := []domain.NotificationChannel{}
+		for _, c := range *update.Channels {
+			if !c.Valid() {
+				return domain.NotificationPreferences{}, domain.ErrValidationFailure
+			}
+			if !containsChannel(channels, c) {
+				channels = append(channels, c)
+			}
+		}
+		prefs.Channels = channels
+	}
+	if update.DisabledKinds != nil {
+		kinds := []domain.NotificationKind{}
+		for _, k := range *update.DisabledKinds {
+			if !k.Valid() {
+				return domain.NotificationPreferences{}, domain.ErrValidationFailure
+			}
+			kinds = append(kinds, k)
+		}
+		prefs.DisabledKinds = kinds
+	}
+	if update.Phone != nil {
+		prefs.Phone = optionalString(*update.Phone)
+	}
+	if update.PushToken != nil {
+		prefs.PushToken = optionalString(*update.PushToken)
+	}
+	if update.Email != nil {
+		email := optionalString(*update.Email)
+		if email != nil && !strings.Contains(*email, "@") {
+			return domain.NotificationPreferences{}, domain.ErrValidationFailure
+		}
+		prefs.Email = email
+	}
+	if update.QuietStart != nil || update.QuietEnd != nil {
+		if update.QuietStart == nil || update.QuietEnd == nil {
+			return domain.NotificationPreferences{}, domain.ErrValidationFailure
+		}
+		switch {
+		case *update.QuietStart == "" && *update.QuietEnd == "":
+			prefs.QuietHours = nil
+		default:
+			start, err := domain.ParseClock(*update.QuietStart)
+			if err != nil {
+				return domain.NotificationPreferences{}, domain.ErrValidationFailure
+			}
+			end, err := domain.ParseClock(*update.QuietEnd)
+			if err != nil || start == end {
+				return domain.NotificationPreferences{}, domain.ErrValidationFailure
+			}
+			prefs.QuietHours = &domain.QuietHours{Start: start, End: end}
+		}
+	}
+
+	prefs.CaregiverID = caregiverID
+	if err := uc.notifications.SavePreferences(ctx, prefs); err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	return uc.GetPreferences(ctx, caregiverID)
+}
+
+// ListNotifications returns the caregiver's delivery log, newest first.
+func (uc *NotificationUsecase) ListNotifications(ctx context.Context, caregiverID string, limit int) ([]domain.Notification, error) {
+	switch {
+	case limit < 0:
+		return nil, domain.ErrValidationFailure
+	case limit == 0:
+		limit = defaultNotificationLimit
+	case limit > maxNotificationLimit:
+		limit = maxNotificationLimit
+	}
+	return uc.notifications.ListByCaregiver(ctx, caregiverID, limit)
+}
+
+// SendDueReminders reminds caregivers of visits starting within the reminder
+// lead and nudges those who have not clocked out of a visit that ended more
+// than the grace period ago. It returns how many notifications were sent;
+// visits already notified are skipped.
+func (uc *NotificationUsecase) SendDueReminders(ctx context.Context) (int, error) {
+	now := uc.now()
+	sent := 0
+	var errs []error
+
+	upcoming, err := uc.notifications.ListVisitsStarting(ctx, now, now.Add(uc.policy.ReminderLead))
+	if err != nil {
+		return 0, err
+	}
+	for _, visit := range upcoming {
+		n, err := uc.notify(ctx, domain.NotificationVisitReminder, visit, "visit:"+visit.ScheduleID)
+		sent += n
+		errs = append(errs, err)
+	}
+
+	overdueBy := now.Add(-uc.policy.ClockOutGrace)
+	overdue, err := uc.notifications.ListVisitsNotClockedOut(ctx, overdueBy.Add(-clockOutNudgeWindow), overdueBy)
+	if err != nil {
+		return sent, err
+	}
+	for _, visit := range overdue {
+		n, err := uc.notify(ctx, domain.NotificationClockOutReminder, visit, "visit:"+visit.ScheduleID)
+		sent += n
+		errs = append(errs, err)
+	}
+	return sent, errors.Join(errs...)
+}
+
+// HandleEvent sends the notifications a domain event calls for.
+func (uc *NotificationUsecase) HandleEvent(ctx context.Context, event domain.Event) error {
+	if event.Type != domain.EventScheduleAssigned {
+		return nil
+	}
+	visit, err := uc.notifications.GetVisit(ctx, event.ScheduleID)
+	if err != nil {
+		return err
+	}
+	if visit.CaregiverID != event.CaregiverID {
+		// Assigned again since; the new caregiver has their own event.
+		return nil
+	}
+	_, err = uc.notify(ctx, domain.NotificationVisitAssigned, visit, "event:"+event.ID)
+	return err
+}
+
+// notify sends one notification about the visit on the first of the
+// caregiver's channels that works, recording every attempt. During quiet
+// hours the notification is recorded as suppressed instead. It returns 1 when
+// a notification was sent.
+func (uc *NotificationUsecase) notify(ctx context.Context, kind domain.NotificationKind, visit domain.NotificationVisit, dedupeKey string) (int, error) {
+	prefs, err := uc.GetPreferences(ctx, visit.CaregiverID)
+	if err != nil {
+		return 0, err
+	}
+	if !prefs.Wants(kind) {
+		return 0, nil
+	}
+	loc, err := uc.zones.Location(ctx, visit.CaregiverID)
+	if err != nil {
+		return 0, err
+	}
+	subject, body, err := uc.render(kind, visit, loc)
+	if err != nil {
+		return 0, err
+	}
+
+	now := uc.now()
+	quiet := prefs.QuietHours != nil && prefs.QuietHours.Contains(now.In(loc))
+	scheduleID := visit.ScheduleID
+	for _, channel := range domain.NotificationChannels {
+		sender := uc.senders[channel]
+		to := notificationAddress(prefs, visit, channel)
+		if sender == nil || to == "" || !prefs.Uses(channel) {
+			continue
+		}
+
+		entry := domain.Notification{
+			CaregiverID: visit.CaregiverID,
+			Kind:        kind,
+			Channel:     channel,
+			DedupeKey:   dedupeKey,
+			ScheduleID:  &scheduleID,
+			Recipient:   to,
+			Subject:     subject,
+			Body:        body,
+			Status:      domain.NotificationPending,
+			CreatedAt:   now,
+		}
+		if quiet {
+			entry.Status = domain.NotificationSuppressed
+		}
+		id, created, err := uc.notifications.Record(ctx, entry)
+		if err != nil {
+			return 0, err
+		}
+		if !created || quiet {
+			// Already handled, here or by another replica, or not to be sent.
+			return 0, nil
+		}
+
+		sendErr := sender.Send(ctx, domain.NotificationMessage{
+			Channel:     channel,
+			Kind:        kind,
+			CaregiverID: visit.CaregiverID,
+			To:          to,
+			Subject:     subject,
+			Body:        body,
+		})
+		if sendErr == nil {
+			return 1, uc.notifications.Finish(ctx, id, domain.NotificationSent, nil, uc.now())
+		}
+		reason := sendErr.Error()
+		if err := uc.notifications.Finish(ctx, id, domain.NotificationFailed, &reason, uc.now()); err != nil {
+			return 0, err
+		}
+	}
+	return 0, nil
+}
+
+func (uc *NotificationUsecase) render(kind domain.NotificationKind, visit domain.NotificationVisit, loc *time.Location) (string, string, error) {
+	tmpl, ok := uc.templates[kind]
+	if !ok {
+		return "", "", domain.ErrValidationFailure
+	}
+	data := notificationData{
+		CaregiverName: visit.CaregiverName,
+		ClientName:    visit.ClientName,
+		ServiceName:   visit.ServiceName,
+		Location:      visit.LocationLabel,
+		Date:          visit.StartTime.In(loc).Format("Mon 2 Jan"),
+		Start:         visit.StartTime.In(loc).Format("15:04"),
+		End:           visit.EndTime.In(loc).Format("15:04"),
+	}
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+// notificationAddress is where the channel reaches the caregiver, or empty.
+func notificationAddress(prefs domain.NotificationPreferences, visit domain.NotificationVisit, channel domain.NotificationChannel) string {
+	switch channel {
+	case domain.NotificationChannelPush:
+		if prefs.PushToken != nil {
+			return *prefs.PushToken
+		}
+	case domain.NotificationChannelSMS:
+		if prefs.Phone != nil {
+			return *prefs.Phone
+		}
+	case domain.NotificationChannelEmail:
+		if prefs.Email != nil {
+			return *prefs.Email
+		}
+		return visit.CaregiverEmail
+	}
+	return ""
+}
+
+func containsChannel(channels []domain.NotificationChannel, c domain.NotificationChannel) bool {
+	for _, existing := range channels {
+		if existing == c {
+			return true
+		}
+	}
+	return false
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.NotificationRepository = (*notificationRepoStub)(nil)
+
+type notificationRepoStub struct {
+	prefs    map[string]domain.NotificationPreferences
+	visits   map[string]domain.NotificationVisit
+	starting []domain.NotificationVisit
+	overdue  []domain.NotificationVisit
+	log      []domain.Notification
+}
+
+func (r *notificationRepoStub) GetPreferences(ctx context.Context, caregiverID string) (domain.NotificationPreferences, error) {
+	prefs, ok := r.prefs[caregiverID]
+	if !ok {
+		return domain.NotificationPreferences{}, domain.ErrNotFound
+	}
+	return prefs, nil
+}
+
+func (r *notificationRepoStub) SavePreferences(ctx context.Context, prefs domain.NotificationPreferences) error {
+	if r.prefs == nil {
+		r.prefs = map[string]domain.NotificationPreferences{}
+	}
+	r.prefs[prefs.CaregiverID] = prefs
+	return nil
+}
+
+func (r *notificationRepoStub) GetVisit(ctx context.Context, scheduleID string) (domain.NotificationVisit, error) {
+	visit, ok := r.visits[scheduleID]
+	if !ok {
+		return domain.NotificationVisit{}, domain.ErrNotFound
+	}
+	return visit, nil
+}
+
+func (r *notificationRepoStub) ListVisitsStarting(ctx context.Context, from, to time.Time) ([]domain.NotificationVisit, error) {
+	return r.starting, nil
+}
+
+func (r *notificationRepoStub) ListVisitsNotClockedOut(ctx context.Context, from, to time.Time) ([]domain.NotificationVisit, error) {
+	return r.overdue, nil
+}
+
+func (r *notificationRepoStub) Record(ctx context.Context, n domain.Notification) (string, bool, error) {
+	for _, existing := range r.log {
+		if existing.CaregiverID == n.CaregiverID && existing.Kind == n.Kind && existing.Channel == n.Channel && existing.DedupeKey == n.DedupeKey {
+			return "", false, nil
+		}
+	}
+	n.ID = fmt.Sprintf("n-%d", len(r.log)+1)
+	r.log = append(r.log, n)
+	return n.ID, true, nil
+}
+
+func (r *notificationRepoStub) Finish(ctx context.Context, notificationID string, status domain.NotificationStatus, failure *string, at time.Time) error {
+	for i := range r.log {
+		if r.log[i].ID == notificationID {
+			r.log[i].Status = status
+			r.log[i].Error = failure
+			r.log[i].SentAt = &at
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (r *notificationRepoStub) ListByCaregiver(ctx context.Context, caregiverID string, limit int) ([]domain.Notification, error) {
+	var out []domain.Notification
+	for i := len(r.log) - 1; i >= 0 && len(out) < limit; i-- {
+		if r.log[i].CaregiverID == caregiverID {
+			out = append(out, r.log[i])
+		}
+	}
+	return out, nil
+}
+
+type senderStub struct {
+	sent []domain.NotificationMessage
+	err  error
+}
+
+func (s *senderStub) Send(ctx context.Context, message domain.NotificationMessage) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func notificationVisit(scheduleID string, start time.Time) domain.NotificationVisit {
+	return domain.NotificationVisit{
+		ScheduleID:     scheduleID,
+		CaregiverID:    "cg-1",
+		CaregiverName:  "Maria",
+		CaregiverEmail: "maria@example.com",
+		ClientName:     "Mr Jones",
+		ServiceName:    "Personal care",
+		LocationLabel:  "12 High St",
+		StartTime:      start,
+		EndTime:        start.Add(time.Hour),
+	}
+}
+
+func TestNotificationUsecaseSendDueReminders(t *testing.T) {
+	// 14:00 UTC is 09:00 in New York.
+	now := time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC)
+	repo := &notificationRepoStub{
+		starting: []domain.NotificationVisit{notificationVisit("sched-1", now.Add(20*time.Minute))},
+		overdue:  []domain.NotificationVisit{notificationVisit("sched-2", now.Add(-2*time.Hour))},
+	}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1", Timezone: "America/New_York"}}, time.UTC)
+	uc := NewNotificationUsecase(repo, zones, NotificationPolicy{ReminderLead: 30 * time.Minute, ClockOutGrace: 15 * time.Minute})
+	uc.WithNow(func() time.Time { return now })
+	email := &senderStub{}
+	uc.WithSender(domain.NotificationChannelEmail, email)
+
+	sent, err := uc.SendDueReminders(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 2 || len(email.sent) != 2 {
+		t.Fatalf("expected a reminder and a clock-out nudge, got %d: %+v", sent, email.sent)
+	}
+	reminder := email.sent[0]
+	if reminder.Kind != domain.NotificationVisitReminder || reminder.To != "maria@example.com" {
+		t.Fatalf("unexpected reminder %+v", reminder)
+	}
+	if reminder.Subject != "Upcoming visit at 09:20" || !strings.Contains(reminder.Body, "Mr Jones starts at 09:20 at 12 High St") {
+		t.Fatalf("expected the reminder in the caregiver's timezone, got %q / %q", reminder.Subject, reminder.Body)
+	}
+	if email.sent[1].Kind != domain.NotificationClockOutReminder || !strings.Contains(email.sent[1].Body, "due to end at 08:00") {
+		t.Fatalf("unexpected clock-out nudge %+v", email.sent[1])
+	}
+	for _, entry := range repo.log {
+		if entry.Status != domain.NotificationSent {
+			t.Fatalf("expected every entry to be sent, got %+v", entry)
+		}
+	}
+
+	// The next scan finds the same visits but does not repeat itself.
+	if sent, err := uc.SendDueReminders(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected no repeats, got %d, %v", sent, err)
+	}
+	if len(email.sent) != 2 {
+		t.Fatalf("expected no further messages, got %d", len(email.sent))
+	}
+}
+
+func TestNotificationUsecaseChannelsAndQuietHours(t *testing.T) {
+	now := time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC)
+	phone := "+15550100"
+	repo := &notificationRepoStub{
+		prefs: map[string]domain.NotificationPreferences{"cg-1": {
+			CaregiverID: "cg-1",
+			Channels:    []domain.NotificationChannel{domain.NotificationChannelPush, domain.NotificationChannelSMS, domain.NotificationChannelEmail},
+			Phone:       &phone,
+		}},
+		starting: []domain.NotificationVisit{notificationVisit("sched-1", now.Add(20*time.Minute))},
+	}
+	uc := NewNotificationUsecase(repo, nil, NotificationPolicy{ReminderLead: 30 * time.Minute})
+	uc.WithNow(func() time.Time { return now })
+	push, sms, email := &senderStub{}, &senderStub{err: errors.New("gateway down")}, &senderStub{}
+	uc.WithSender(domain.NotificationChannelPush, push)
+	uc.WithSender(domain.NotificationChannelSMS, sms)
+	uc.WithSender(domain.NotificationChannelEmail, email)
+
+	// No push token, so SMS is tried first; it fails and email takes over.
+	sent, err := uc.SendDueReminders(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("expected one reminder, got %d, %v", sent, err)
+	}
+	if len(push.sent) != 0 || len(email.sent) != 1 {
+		t.Fatalf("expected email to be used, push=%d email=%d", len(push.sent), len(email.sent))
+	}
+	if len(repo.log) != 2 || repo.log[0].Status != domain.NotificationFailed || repo.log[0].Error == nil || repo.log[1].Status != domain.NotificationSent {
+		t.Fatalf("expected the failed SMS and the sent email to be logged, got %+v", repo.log)
+	}
+
+	// Quiet hours from 22:00 to 07:00 suppress the next one.
+	repo.log = nil
+	start, end := "22:00", "07:00"
+	if _, err := uc.UpdatePreferences(context.Background(), "cg-1", NotificationPreferencesUpdate{QuietStart: &start, QuietEnd: &end}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sms.err = nil
+	if sent, err := uc.SendDueReminders(context.Background()); err != nil || sent != 0 {
+		t.Fatalf("expected nothing to be sent in quiet hours, got %d, %v", sent, err)
+	}
+	if len(repo.log) != 1 || repo.log[0].Status != domain.NotificationSuppressed || len(sms.sent) != 0 {
+		t.Fatalf("expected one suppressed entry, got %+v", repo.log)
+	}
+
+	// Disabled kinds are not sent at all.
+	repo.log = nil
+	disabled := []domain.NotificationKind{domain.NotificationVisitReminder}
+	empty := ""
+	if _, err := uc.UpdatePreferences(context.Background(), "cg-1", NotificationPreferencesUpdate{DisabledKinds: &disabled, QuietStart: &empty, QuietEnd: &empty}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent, err := uc.SendDueReminders(context.Background()); err != nil || sent != 0 || len(repo.log) != 0 {
+		t.Fatalf("expected disabled reminders to be skipped, got %d, %v, %+v", sent, err, repo.log)
+	}
+}
+
+func TestNotificationUsecaseHandleEvent(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &notificationRepoStub{visits: map[string]domain.NotificationVisit{
+		"sched-1": notificationVisit("sched-1", time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC)),
+	}}
+	uc := NewNotificationUsecase(repo, nil, NotificationPolicy{})
+	uc.WithNow(func() time.Time { return now })
+	email := &senderStub{}
+	uc.WithSender(domain.NotificationChannelEmail, email)
+
+	event := domain.Event{ID: "evt-1", Type: domain.EventScheduleAssigned, CaregiverID: "cg-1", ScheduleID: "sched-1"}
+	if err := uc.HandleEvent(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The same event arriving again, say from another replica, is ignored.
+	if err := uc.HandleEvent(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(email.sent) != 1 || email.sent[0].Kind != domain.NotificationVisitAssigned {
+		t.Fatalf("expected one assignment alert, got %+v", email.sent)
+	}
+	if email.sent[0].Subject != "New visit on Fri 17 Jan" || !strings.Contains(email.sent[0].Body, "from 10:00 to 11:00") {
+		t.Fatalf("unexpected message %+v", email.sent[0])
+	}
+
+	// A visit since given to someone else is left to their own event.
+	stale := domain.Event{ID: "evt-2", Type: domain.EventScheduleAssigned, CaregiverID: "cg-2", ScheduleID: "sched-1"}
+	if err := uc.HandleEvent(context.Background(), stale); err != nil || len(email.sent) != 1 {
+		t.Fatalf("expected stale assignments to be skipped, got %v, %d", err, len(email.sent))
+	}
+}
+
+func TestNotificationUsecaseTemplates(t *testing.T) {
+	repo := &notificationRepoStub{visits: map[string]domain.NotificationVisit{
+		"sched-1": notificationVisit("sched-1", time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC)),
+	}}
+	uc := NewNotificationUsecase(repo, nil, NotificationPolicy{})
+	email := &senderStub{}
+	uc.WithSender(domain.NotificationChannelEmail, email)
+
+	if err := uc.WithTemplates(map[domain.NotificationKind]NotificationTemplate{
+		domain.NotificationVisitAssigned: {Subject: "{{.Date", Body: ""},
+	}); err == nil {
+		t.Fatal("expected a broken template to be rejected")
+	}
+	if err := uc.WithTemplates(map[domain.NotificationKind]NotificationTemplate{
+		domain.NotificationVisitAssigned: {Subject: "Visit", Body: "{{.ClientName}} at {{.Start}}"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	event := domain.Event{ID: "evt-1", Type: domain.EventScheduleAssigned, CaregiverID: "cg-1", ScheduleID: "sched-1"}
+	if err := uc.HandleEvent(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(email.sent) != 1 || email.sent[0].Body != "Mr Jones at 10:00" {
+		t.Fatalf("expected the custom template, got %+v", email.sent)
+	}
+}
+
+func TestNotificationUsecaseUpdatePreferences(t *testing.T) {
+	repo := &notificationRepoStub{}
+	uc := NewNotificationUsecase(repo, nil, NotificationPolicy{})
+
+	prefs, err := uc.GetPreferences(context.Background(), "cg-1")
+	if err != nil || len(prefs.Channels) != len(domain.NotificationChannels) || prefs.QuietHours != nil {
+		t.Fatalf("expected defaults, got %+v, %v", prefs, err)
+	}
+
+	channels := []domain.NotificationChannel{domain.NotificationChannelSMS, domain.NotificationChannelSMS}
+	phone := " +15550100 "
+	prefs, err = uc.UpdatePreferences(context.Background(), "cg-1", NotificationPreferencesUpdate{Channels: &channels, Phone: &phone})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prefs.Channels) != 1 || prefs.Phone == nil || *prefs.Phone != "+15550100" {
+		t.Fatalf("unexpected preferences %+v", prefs)
+	}
+
+	badChannel := []domain.NotificationChannel{"pager"}
+	badKind := []domain.NotificationKind{"birthday"}
+	badEmail := "not-an-address"
+	start, end, badClock := "22:00", "22:00", "25:00"
+	for _, update := range []NotificationPreferencesUpdate{
+		{Channels: &badChannel},
+		{DisabledKinds: &badKind},
+		{Email: &badEmail},
+		{QuietStart: &start},
+		{QuietStart: &start, QuietEnd: &end},
+		{QuietStart: &badClock, QuietEnd: &end},
+	} {
+		if _, err := uc.UpdatePreferences(context.Background(), "cg-1", update); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("expected validation failure for %+v, got %v", update, err)
+		}
+	}
+}
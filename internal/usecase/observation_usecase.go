@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+const (
+	// maxObservationsPerRequest caps the readings recorded at once.
+	maxObservationsPerRequest = 20
+	maxObservationNoteLength  = 500
+	// defaultTrendDays and maxTrendDays bound the trend window, in days.
+	defaultTrendDays = 30
+	maxTrendDays     = 366
+)
+
+// ObservationReading is a reading as the caregiver took it. Unit defaults to
+// the type's own; Diastolic is for blood pressure only.
+type ObservationReading struct {
+	Type      domain.ObservationType
+	Value     float64
+	Diastolic *float64
+	Unit      string
+	Note      *string
+}
+
+// TrendQuery selects a client's observation trends. From and To are calendar
+// dates in the agency timezone, To inclusive; the window defaults to the last
+// 30 days. Types defaults to every type.
+type TrendQuery struct {
+	From  *time.Time
+	To    *time.Time
+	Types []domain.ObservationType
+}
+
+// ObservationStats summarises the readings of a series.
+type ObservationStats struct {
+	Min  float64
+	Max  float64
+	Mean float64
+}
+
+// ObservationSeries is one observation type's readings over the trend window,
+// oldest first, ready to chart.
+type ObservationSeries struct {
+	Type   domain.ObservationType
+	Spec   domain.ObservationSpec
+	Points []domain.Observation
+	// Flagged counts readings outside the normal range.
+	Flagged int
+	// Value and Diastolic are nil without readings; Diastolic is for blood
+	// pressure only.
+	Value     *ObservationStats
+	Diastolic *ObservationStats
+}
+
+// ObservationTrends is a client's observations over [From, To).
+type ObservationTrends struct {
+	From   time.Time
+	To     time.Time
+	Series []ObservationSeries
+}
+
+// ObservationUsecase records structured observations, such as vital signs,
+// during visits and reports their trends per client.
+type ObservationUsecase struct {
+	observations repository.ObservationRepository
+	schedules    repository.ScheduleRepository
+	// loc is the agency timezone trend dates are read in.
+	loc *time.Location
+	now func() time.Time
+}
+
+// NewObservationUsecase constructs an ObservationUsecase.
+func NewObservationUsecase(observations repository.ObservationRepository, schedules repository.ScheduleRepository, loc *time.Location) *ObservationUsecase {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &ObservationUsecase{observations: observations, schedules: schedules, loc: loc, now: time.Now}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *ObservationUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// Record stores readings taken during the caregiver's in-progress visit,
+// converted to each type's unit. Implausible readings are rejected; readings
+// outside the normal range are flagged and raise an alert.
+func (uc *ObservationUsecase) Record(ctx context.Context, caregiverID, scheduleID string, readings []ObservationReading) ([]domain.Observation, error) {
+	if len(readings) == 0 || len(readings) > maxObservationsPerRequest {
+		return nil, domain.ErrValidationFailure
+	}
+	schedule, err := uc.schedules.GetScheduleForCaregiver(ctx, scheduleID, caregiverID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != domain.ScheduleStatusInProgress {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+
+	now := uc.now().UTC()
+	observations := make([]domain.Observation, len(readings))
+	for i, reading := range readings {
+		observation, ok := observationFromReading(reading)
+		if !ok {
+			return nil, domain.ErrValidationFailure
+		}
+		observation.ScheduleID = schedule.ID
+		observation.ClientID = schedule.Client.ID
+		observation.CaregiverID = caregiverID
+		observation.RecordedAt = now
+		observations[i] = observation
+	}
+	return uc.observations.Create(ctx, observations)
+}
+
+// ClientTrends returns one series per requested type over the query window.
+func (uc *ObservationUsecase) ClientTrends(ctx context.Context, clientID string, query TrendQuery) (ObservationTrends, error) {
+	to := domain.CalendarDay(uc.now().In(uc.loc), uc.loc).AddDate(0, 0, 1)
+	if query.To != nil {
+		to = domain.CalendarDay(*query.To, uc.loc).AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultTrendDays)
+	if query.From != nil {
+		from = domain.CalendarDay(*query.From, uc.loc)
+	}
+	if !to.After(from) || to.After(from.AddDate(0, 0, maxTrendDays)) {
+		return ObservationTrends{}, domain.ErrValidationFailure
+	}
+	types := query.Types
+	if len(types) == 0 {
+		types = domain.ObservationTypes
+	}
+	for _, t := range types {
+		if !t.Valid() {
+			return ObservationTrends{}, domain.ErrValidationFailure
+		}
+	}
+
+	observations, err := uc.observations.ListByClient(ctx, clientID, repository.ObservationFilter{
+		From:  from,
+		To:    to,
+		Types: query.Types,
+	})
+	if err != nil {
+		return ObservationTrends{}, err
+	}
+	byType := map[domain.ObservationType][]domain.Observation{}
+	for _, o := range observations {
+		byType[o.Type] = append(byType[o.Type], o)
+	}
+
+	trends := ObservationTrends{From: from, To: to, Series: make([]ObservationSeries, 0, len(types))}
+	for _, t := range types {
+		points := byType[t]
+		if points == nil {
+			points = []domain.Observation{}
+		}
+		series := ObservationSeries{Type: t, Spec: t.Spec(), Points: points}
+		for _, p := range points {
+			if p.Flag != nil {
+				series.Flagged++
+			}
+		}
+		series.Value = observationStats(points, func(o domain.Observation) *float64 { return &o.Value })
+		if t == domain.ObservationBloodPressure {
+			series.Diastolic = observationStats(points, func(o domain.Observation) *float64 { return o.Diastolic })
+		}
+		trends.Series = append(trends.Series, series)
+	}
+	return trends, nil
+}
+
+// observationFromReading converts the reading to its type's unit and checks it.
+func observationFromReading(reading ObservationReading) (domain.Observation, bool) {
+	if !reading.Type.Valid() {
+		return domain.Observation{}, false
+	}
+	spec := reading.Type.Spec()
+	unit := strings.TrimSpace(reading.Unit)
+	value, ok := spec.Canonical(reading.Value, unit)
+	if !ok {
+		return domain.Observation{}, false
+	}
+	observation := domain.Observation{
+		Type:  reading.Type,
+		Value: value,
+		Unit:  spec.Unit,
+	}
+	if reading.Note != nil {
+		observation.Note = optionalComment(*reading.Note)
+	}
+	if observation.Note != nil && len(*observation.Note) > maxObservationNoteLength {
+		return domain.Observation{}, false
+	}
+	if reading.Diastolic != nil {
+		diastolic, _ := spec.Canonical(*reading.Diastolic, unit)
+		observation.Diastolic = &diastolic
+	}
+	if !observation.Check() {
+		return domain.Observation{}, false
+	}
+	return observation, true
+}
+
+// observationStats summarises the values read from points, or returns nil
+// when there are none.
+func observationStats(points []domain.Observation, value func(domain.Observation) *float64) *ObservationStats {
+	var stats ObservationStats
+	count, sum := 0, 0.0
+	for _, p := range points {
+		v := value(p)
+		if v == nil {
+			continue
+		}
+		if count == 0 || *v < stats.Min {
+			stats.Min = *v
+		}
+		if count == 0 || *v > stats.Max {
+			stats.Max = *v
+		}
+		sum += *v
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	stats.Mean = math.Round(sum/float64(count)*10) / 10
+	return &stats
+}
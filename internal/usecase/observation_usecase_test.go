@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.ObservationRepository = (*observationRepoStub)(nil)
+
+type observationRepoStub struct {
+	created []domain.Observation
+	listed  []domain.Observation
+	filter  repository.ObservationFilter
+}
+
+func (r *observationRepoStub) Create(ctx context.Context, observations []domain.Observation) ([]domain.Observation, error) {
+	r.created = append(r.created, observations...)
+	return observations, nil
+}
+
+func (r *observationRepoStub) ListByClient(ctx context.Context, clientID string, filter repository.ObservationFilter) ([]domain.Observation, error) {
+	r.filter = filter
+	return r.listed, nil
+}
+
+func newObservationFixture(status domain.ScheduleStatus) (*ObservationUsecase, *observationRepoStub, time.Time) {
+	now := time.Date(2025, 1, 15, 16, 0, 0, 0, time.UTC)
+	repo := &observationRepoStub{}
+	schedules := &scheduleRepoStub{schedule: domain.Schedule{
+		ID:          "sched-1",
+		CaregiverID: "caregiver-1",
+		Client:      domain.Client{ID: "client-1"},
+		Status:      status,
+	}}
+	uc := NewObservationUsecase(repo, schedules, chicago)
+	uc.WithNow(func() time.Time { return now })
+	return uc, repo, now
+}
+
+func TestObservationUsecaseRecordConvertsAndFlags(t *testing.T) {
+	uc, repo, now := newObservationFixture(domain.ScheduleStatusInProgress)
+	diastolic := 82.0
+	note := "  after lunch "
+
+	observations, err := uc.Record(context.Background(), "caregiver-1", "sched-1", []ObservationReading{
+		{Type: domain.ObservationBloodPressure, Value: 152, Diastolic: &diastolic},
+		{Type: domain.ObservationTemperature, Value: 98.6, Unit: "F", Note: &note},
+		{Type: domain.ObservationOxygenSaturation, Value: 89},
+		{Type: domain.ObservationWeight, Value: 150, Unit: "lb"},
+	})
+	if err != nil {
+		t.Fatalf("Record error: %v", err)
+	}
+	if len(observations) != 4 || len(repo.created) != 4 {
+		t.Fatalf("expected 4 observations stored, got %d", len(repo.created))
+	}
+	for _, o := range observations {
+		if o.ScheduleID != "sched-1" || o.ClientID != "client-1" || o.CaregiverID != "caregiver-1" || !o.RecordedAt.Equal(now) {
+			t.Fatalf("unexpected observation context: %+v", o)
+		}
+	}
+
+	bp, temp, spo2, weight := observations[0], observations[1], observations[2], observations[3]
+	if bp.Flag == nil || *bp.Flag != domain.ObservationFlagHigh || bp.Unit != "mmHg" {
+		t.Fatalf("expected high blood pressure in mmHg, got %+v", bp)
+	}
+	if temp.Value != 37 || temp.Unit != "C" || temp.Flag != nil || temp.Note == nil || *temp.Note != "after lunch" {
+		t.Fatalf("expected 37 C unflagged with trimmed note, got %+v", temp)
+	}
+	if spo2.Flag == nil || *spo2.Flag != domain.ObservationFlagLow {
+		t.Fatalf("expected low oxygen saturation, got %+v", spo2)
+	}
+	if weight.Value != 68 || weight.Unit != "kg" || weight.Flag != nil {
+		t.Fatalf("expected 68 kg unflagged, got %+v", weight)
+	}
+}
+
+func TestObservationUsecaseRecordRejectsImplausibleReadings(t *testing.T) {
+	uc, repo, _ := newObservationFixture(domain.ScheduleStatusInProgress)
+	high := 130.0
+
+	cases := map[string]ObservationReading{
+		"out of range":             {Type: domain.ObservationHeartRate, Value: 400},
+		"unknown unit":             {Type: domain.ObservationTemperature, Value: 310, Unit: "K"},
+		"fractional mood":          {Type: domain.ObservationMood, Value: 2.5},
+		"missing diastolic":        {Type: domain.ObservationBloodPressure, Value: 120},
+		"diastolic above systolic": {Type: domain.ObservationBloodPressure, Value: 120, Diastolic: &high},
+		"diastolic on other type":  {Type: domain.ObservationHeartRate, Value: 70, Diastolic: &high},
+		"unknown type":             {Type: "glucose", Value: 5},
+	}
+	for name, reading := range cases {
+		_, err := uc.Record(context.Background(), "caregiver-1", "sched-1", []ObservationReading{
+			{Type: domain.ObservationMood, Value: 4},
+			reading,
+		})
+		if err != domain.ErrValidationFailure {
+			t.Fatalf("%s: expected validation failure, got %v", name, err)
+		}
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("expected nothing stored, got %+v", repo.created)
+	}
+}
+
+func TestObservationUsecaseRecordRequiresVisitInProgress(t *testing.T) {
+	uc, _, _ := newObservationFixture(domain.ScheduleStatusScheduled)
+	_, err := uc.Record(context.Background(), "caregiver-1", "sched-1", []ObservationReading{{Type: domain.ObservationMood, Value: 4}})
+	if err != domain.ErrInvalidStatusTransition {
+		t.Fatalf("expected invalid status transition, got %v", err)
+	}
+
+	uc, _, _ = newObservationFixture(domain.ScheduleStatusInProgress)
+	_, err = uc.Record(context.Background(), "caregiver-2", "sched-1", []ObservationReading{{Type: domain.ObservationMood, Value: 4}})
+	if err != domain.ErrNotFound {
+		t.Fatalf("expected not found for another caregiver's visit, got %v", err)
+	}
+	if _, err := uc.Record(context.Background(), "caregiver-1", "sched-1", nil); err != domain.ErrValidationFailure {
+		t.Fatalf("expected validation failure without readings, got %v", err)
+	}
+}
+
+func TestObservationUsecaseClientTrends(t *testing.T) {
+	uc, repo, _ := newObservationFixture(domain.ScheduleStatusInProgress)
+	high := domain.ObservationFlagHigh
+	d1, d2 := 80.0, 92.0
+	repo.listed = []domain.Observation{
+		{ID: "obs-1", Type: domain.ObservationBloodPressure, Value: 128, Diastolic: &d1},
+		{ID: "obs-2", Type: domain.ObservationTemperature, Value: 36.8},
+		{ID: "obs-3", Type: domain.ObservationBloodPressure, Value: 141, Diastolic: &d2, Flag: &high},
+	}
+
+	trends, err := uc.ClientTrends(context.Background(), "client-1", TrendQuery{})
+	if err != nil {
+		t.Fatalf("ClientTrends error: %v", err)
+	}
+	// The default window is the 30 days up to and including today in Chicago.
+	wantFrom := time.Date(2024, 12, 17, 0, 0, 0, 0, chicago)
+	wantTo := time.Date(2025, 1, 16, 0, 0, 0, 0, chicago)
+	if !repo.filter.From.Equal(wantFrom) || !repo.filter.To.Equal(wantTo) || len(repo.filter.Types) != 0 {
+		t.Fatalf("unexpected filter %+v", repo.filter)
+	}
+	if len(trends.Series) != len(domain.ObservationTypes) {
+		t.Fatalf("expected a series per type, got %d", len(trends.Series))
+	}
+
+	bp := trends.Series[0]
+	if bp.Type != domain.ObservationBloodPressure || len(bp.Points) != 2 || bp.Flagged != 1 {
+		t.Fatalf("unexpected blood pressure series %+v", bp)
+	}
+	if bp.Value == nil || bp.Value.Min != 128 || bp.Value.Max != 141 || bp.Value.Mean != 134.5 {
+		t.Fatalf("unexpected systolic stats %+v", bp.Value)
+	}
+	if bp.Diastolic == nil || bp.Diastolic.Mean != 86 {
+		t.Fatalf("unexpected diastolic stats %+v", bp.Diastolic)
+	}
+	heart := trends.Series[1]
+	if heart.Type != domain.ObservationHeartRate || len(heart.Points) != 0 || heart.Value != nil {
+		t.Fatalf("expected an empty heart rate series, got %+v", heart)
+	}
+
+	from := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)
+	trends, err = uc.ClientTrends(context.Background(), "client-1", TrendQuery{
+		From:  &from,
+		To:    &to,
+		Types: []domain.ObservationType{domain.ObservationTemperature},
+	})
+	if err != nil {
+		t.Fatalf("ClientTrends error: %v", err)
+	}
+	if !repo.filter.From.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, chicago)) || !repo.filter.To.Equal(time.Date(2025, 1, 13, 0, 0, 0, 0, chicago)) {
+		t.Fatalf("expected the inclusive window in Chicago, got %+v", repo.filter)
+	}
+	if len(trends.Series) != 1 || trends.Series[0].Type != domain.ObservationTemperature || len(trends.Series[0].Points) != 1 {
+		t.Fatalf("unexpected series %+v", trends.Series)
+	}
+
+	if _, err := uc.ClientTrends(context.Background(), "client-1", TrendQuery{From: &to, To: &from}); err != domain.ErrValidationFailure {
+		t.Fatalf("expected validation failure for a reversed window, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// DefaultOpenShiftRadiusKm bounds the open shift search when no radius is given.
+const DefaultOpenShiftRadiusKm = 25.0
+
+// OpenShiftUsecase exposes unassigned visits to eligible caregivers.
+type OpenShiftUsecase struct {
+	openShifts repository.OpenShiftRepository
+	caregivers repository.CaregiverRepository
+	now        func() time.Time
+}
+
+// NewOpenShiftUsecase constructs an OpenShiftUsecase.
+func NewOpenShiftUsecase(openShifts repository.OpenShiftRepository, caregivers repository.CaregiverRepository) *OpenShiftUsecase {
+	return &OpenShiftUsecase{
+		openShifts: openShifts,
+		caregivers: caregivers,
+		now:        time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *OpenShiftUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// ListOpenShifts returns upcoming open shifts the caregiver is qualified for,
+// free to work and that lie within radiusKm of their home, nearest first.
+// Caregivers without a home location see every such shift without distances.
+func (uc *OpenShiftUsecase) ListOpenShifts(ctx context.Context, caregiverID string, radiusKm float64) ([]domain.OpenShift, error) {
+	if radiusKm < 0 {
+		return nil, domain.ErrValidationFailure
+	}
+	if radiusKm == 0 {
+		radiusKm = DefaultOpenShiftRadiusKm
+	}
+
+	caregiver, err := uc.caregivers.GetProfile(ctx, caregiverID)
+	if err != nil {
+		return nil, err
+	}
+
+	qualifications := caregiver.Qualifications
+	if qualifications == nil {
+		qualifications = []string{}
+	}
+	shifts, err := uc.openShifts.ListOpenShifts(ctx, repository.OpenShiftFilter{
+		From:            uc.now(),
+		Qualifications:  qualifications,
+		FreeCaregiverID: caregiverID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if caregiver.HomeLatitude == nil || caregiver.HomeLongitude == nil {
+		return shifts, nil
+	}
+
+	result := make([]domain.OpenShift, 0, len(shifts))
+	for _, shift := range shifts {
+		distance := domain.DistanceKm(*caregiver.HomeLatitude, *caregiver.HomeLongitude, shift.Latitude, shift.Longitude)
+		if distance > radiusKm {
+			continue
+		}
+		shift.DistanceKm = &distance
+		result = append(result, shift)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return *result[i].DistanceKm < *result[j].DistanceKm
+	})
+	return result, nil
+}
+
+// ClaimOpenShift assigns an open shift to the caregiver on a first-come-first-served basis.
+// It returns domain.ErrConflict if the shift overlaps one of their committed visits.
+func (uc *OpenShiftUsecase) ClaimOpenShift(ctx context.Context, caregiverID, scheduleID string) (domain.OpenShift, error) {
+	shift, err := uc.openShifts.GetOpenShift(ctx, scheduleID)
+	if err != nil {
+		return domain.OpenShift{}, err
+	}
+	if !shift.StartTime.After(uc.now()) {
+		return domain.OpenShift{}, domain.ErrInvalidStatusTransition
+	}
+
+	caregiver, err := uc.caregivers.GetProfile(ctx, caregiverID)
+	if err != nil {
+		return domain.OpenShift{}, err
+	}
+	if !shift.HasQualifications(caregiver.Qualifications) {
+		return domain.OpenShift{}, domain.ErrForbidden
+	}
+
+	if err := uc.openShifts.ClaimOpenShift(ctx, scheduleID, caregiverID, uc.now()); err != nil {
+		return domain.OpenShift{}, err
+	}
+	return shift, nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.OpenShiftRepository = (*openShiftRepoStub)(nil)
+
+type openShiftRepoStub struct {
+	shifts    []domain.OpenShift
+	claimed   map[string]string
+	committed []domain.CommittedVisit
+	filter    repository.OpenShiftFilter
+}
+
+// busy reports whether shift overlaps a committed visit of the caregiver.
+func (o *openShiftRepoStub) busy(caregiverID string, shift domain.OpenShift) bool {
+	for _, v := range o.committed {
+		if v.CaregiverID == caregiverID && v.StartTime.Before(shift.EndTime) && v.EndTime.After(shift.StartTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *openShiftRepoStub) ListOpenShifts(ctx context.Context, filter repository.OpenShiftFilter) ([]domain.OpenShift, error) {
+	o.filter = filter
+	var result []domain.OpenShift
+	for _, s := range o.shifts {
+		if _, taken := o.claimed[s.ScheduleID]; taken {
+			continue
+		}
+		if filter.FreeCaregiverID != "" && o.busy(filter.FreeCaregiverID, s) {
+			continue
+		}
+		if filter.Qualifications == nil || s.HasQualifications(filter.Qualifications) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (o *openShiftRepoStub) GetOpenShift(ctx context.Context, scheduleID string) (domain.OpenShift, error) {
+	for _, s := range o.shifts {
+		if s.ScheduleID == scheduleID {
+			if _, taken := o.claimed[scheduleID]; taken {
+				return domain.OpenShift{}, domain.ErrNotFound
+			}
+			return s, nil
+		}
+	}
+	return domain.OpenShift{}, domain.ErrNotFound
+}
+
+func (o *openShiftRepoStub) ClaimOpenShift(ctx context.Context, scheduleID, caregiverID string, claimedAt time.Time) error {
+	if o.claimed == nil {
+		o.claimed = map[string]string{}
+	}
+	if _, taken := o.claimed[scheduleID]; taken {
+		return domain.ErrConflict
+	}
+	for _, s := range o.shifts {
+		if s.ScheduleID == scheduleID && o.busy(caregiverID, s) {
+			return domain.ErrConflict
+		}
+	}
+	o.claimed[scheduleID] = caregiverID
+	return nil
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestOpenShiftUsecaseListFiltersByDistanceAndQualification(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &openShiftRepoStub{shifts: []domain.OpenShift{
+		{ScheduleID: "far", Latitude: 45.5, Longitude: -93.2, StartTime: now.Add(time.Hour)},
+		{ScheduleID: "near", Latitude: 44.98, Longitude: -93.26, StartTime: now.Add(2 * time.Hour)},
+		{ScheduleID: "mid", Latitude: 45.05, Longitude: -93.26, StartTime: now.Add(3 * time.Hour)},
+		{ScheduleID: "skilled", Latitude: 44.98, Longitude: -93.26, RequiredQualifications: []string{"wound_care"}},
+	}}
+	caregivers := &caregiverRepoStub{caregiver: domain.Caregiver{
+		ID:             "cg-1",
+		HomeLatitude:   floatPtr(44.9778),
+		HomeLongitude:  floatPtr(-93.2650),
+		Qualifications: []string{"personal_care"},
+	}}
+
+	uc := NewOpenShiftUsecase(repo, caregivers)
+	uc.WithNow(func() time.Time { return now })
+
+	shifts, err := uc.ListOpenShifts(context.Background(), "cg-1", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(shifts) != 2 {
+		t.Fatalf("expected 2 shifts within radius, got %d", len(shifts))
+	}
+	if shifts[0].ScheduleID != "near" || shifts[1].ScheduleID != "mid" {
+		t.Fatalf("expected nearest first, got %s then %s", shifts[0].ScheduleID, shifts[1].ScheduleID)
+	}
+	if shifts[0].DistanceKm == nil {
+		t.Fatalf("expected distance to be populated")
+	}
+	if !repo.filter.From.Equal(now) {
+		t.Fatalf("expected listing from %v, got %v", now, repo.filter.From)
+	}
+}
+
+func TestOpenShiftUsecaseClaimFirstComeFirstServed(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &openShiftRepoStub{shifts: []domain.OpenShift{
+		{ScheduleID: "sched-1", StartTime: now.Add(time.Hour)},
+	}}
+	caregivers := &caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1"}}
+
+	uc := NewOpenShiftUsecase(repo, caregivers)
+	uc.WithNow(func() time.Time { return now })
+
+	if _, err := uc.ClaimOpenShift(context.Background(), "cg-1", "sched-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.claimed["sched-1"] != "cg-1" {
+		t.Fatalf("expected shift to be claimed by cg-1")
+	}
+
+	if _, err := uc.ClaimOpenShift(context.Background(), "cg-1", "sched-1"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found for already claimed shift, got %v", err)
+	}
+}
+
+func TestOpenShiftUsecaseClaimRequiresQualifications(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &openShiftRepoStub{shifts: []domain.OpenShift{
+		{ScheduleID: "sched-1", StartTime: now.Add(time.Hour), RequiredQualifications: []string{"medication"}},
+	}}
+	caregivers := &caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1", Qualifications: []string{"personal_care"}}}
+
+	uc := NewOpenShiftUsecase(repo, caregivers)
+	uc.WithNow(func() time.Time { return now })
+
+	_, err := uc.ClaimOpenShift(context.Background(), "cg-1", "sched-1")
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+	if len(repo.claimed) != 0 {
+		t.Fatalf("expected shift to remain open")
+	}
+}
+
+func TestOpenShiftUsecaseExcludesShiftsOverlappingCommittedVisits(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &openShiftRepoStub{
+		shifts: []domain.OpenShift{
+			{ScheduleID: "overlapping", StartTime: now.Add(2 * time.Hour), EndTime: now.Add(4 * time.Hour)},
+			{ScheduleID: "after", StartTime: now.Add(5 * time.Hour), EndTime: now.Add(6 * time.Hour)},
+		},
+		committed: []domain.CommittedVisit{
+			{ScheduleID: "assigned", CaregiverID: "cg-1", StartTime: now.Add(3 * time.Hour), EndTime: now.Add(5 * time.Hour)},
+		},
+	}
+	caregivers := &caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1"}}
+
+	uc := NewOpenShiftUsecase(repo, caregivers)
+	uc.WithNow(func() time.Time { return now })
+
+	shifts, err := uc.ListOpenShifts(context.Background(), "cg-1", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.filter.FreeCaregiverID != "cg-1" {
+		t.Fatalf("expected listing shifts cg-1 is free for, got %q", repo.filter.FreeCaregiverID)
+	}
+	if len(shifts) != 1 || shifts[0].ScheduleID != "after" {
+		t.Fatalf("expected only the shift after the committed visit, got %+v", shifts)
+	}
+
+	if _, err := uc.ClaimOpenShift(context.Background(), "cg-1", "overlapping"); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict claiming an overlapping shift, got %v", err)
+	}
+	if _, err := uc.ClaimOpenShift(context.Background(), "cg-1", "after"); err != nil {
+		t.Fatalf("expected the shift ending the visit to be claimable, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import "github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+
+// PageInfo tells a client whether a listing continues past the current page.
+// Listings fetch one row beyond the limit, so HasMore is exact; NextCursor
+// resumes after the last row returned and is empty on the final page.
+type PageInfo struct {
+	HasMore    bool
+	NextCursor string
+}
+
+// pageCut reports how many of n fetched rows belong on a page of limit rows
+// that was fetched with one extra row, and whether more rows follow.
+func pageCut(n, limit int) (int, bool) {
+	if limit > 0 && n > limit {
+		return limit, true
+	}
+	return n, false
+}
+
+// nextPage builds the PageInfo for a page ending at cursor.
+func nextPage(hasMore bool, cursor domain.Cursor) PageInfo {
+	if !hasMore {
+		return PageInfo{}
+	}
+	return PageInfo{HasMore: true, NextCursor: cursor.Encode()}
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"math"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+// averageTravelSpeedKmh converts straight-line distance into an estimated drive time.
+const averageTravelSpeedKmh = 30.0
+
+// maxExactRouteStops bounds the exhaustive search; larger days fall back to a greedy order.
+const maxExactRouteStops = 8
+
+// buildDailyRoute lays out stops chronologically with travel legs between them.
+func buildDailyRoute(day time.Time, stops []domain.RouteStop) domain.DailyRoute {
+	route := domain.DailyRoute{
+		Date:  day,
+		Stops: stops,
+		Legs:  make([]domain.RouteLeg, 0, len(stops)),
+	}
+	for i := 1; i < len(stops); i++ {
+		leg := planLeg(stops[i-1], stops[i])
+		route.TotalDistanceKm += leg.DistanceKm
+		if !leg.Feasible {
+			route.InfeasibleLegs++
+		}
+		route.Legs = append(route.Legs, leg)
+	}
+	route.TotalDistanceKm = roundKm(route.TotalDistanceKm)
+	return route
+}
+
+func planLeg(from, to domain.RouteStop) domain.RouteLeg {
+	km := domain.DistanceKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	travel := travelDuration(km, averageTravelSpeedKmh)
+	gap := to.StartTime.Sub(from.EndTime)
+	return domain.RouteLeg{
+		FromScheduleID: from.ScheduleID,
+		ToScheduleID:   to.ScheduleID,
+		DistanceKm:     roundKm(km),
+		TravelMins:     int(math.Ceil(travel.Minutes())),
+		AvailableMins:  int(gap.Minutes()),
+		Feasible:       travel <= gap,
+	}
+}
+
+// optimiseRoute searches for a shorter feasible order of the remaining scheduled
+// visits, shifting flexible visits within their windows. Visits already started
+// or finished keep their place and anchor the search. It returns false when no
+// flexible visit exists or no shorter feasible order was found.
+func optimiseRoute(stops []domain.RouteStop) ([]string, float64, bool) {
+	var fixed, pending []domain.RouteStop
+	flexible := false
+	for _, s := range stops {
+		if s.Status == domain.ScheduleStatusScheduled {
+			pending = append(pending, s)
+			if s.FlexibleWindowMins > 0 {
+				flexible = true
+			}
+			continue
+		}
+		fixed = append(fixed, s)
+	}
+	if !flexible || len(pending) < 2 {
+		return nil, 0, false
+	}
+
+	var anchor *domain.RouteStop
+	for i := range fixed {
+		if fixed[i].Status == domain.ScheduleStatusInProgress || fixed[i].Status == domain.ScheduleStatusCompleted {
+			anchor = &fixed[i]
+		}
+	}
+
+	baseline, baselineOK := simulateRoute(anchor, pending)
+	var best []domain.RouteStop
+	bestKm := math.Inf(1)
+	if baselineOK {
+		bestKm = baseline
+	}
+
+	if len(pending) <= maxExactRouteStops {
+		used := make([]bool, len(pending))
+		order := make([]domain.RouteStop, 0, len(pending))
+		var search func(prev *domain.RouteStop, clock time.Time, km float64)
+		search = func(prev *domain.RouteStop, clock time.Time, km float64) {
+			if km >= bestKm {
+				return
+			}
+			if len(order) == len(pending) {
+				bestKm = km
+				best = append(best[:0], order...)
+				return
+			}
+			for i := range pending {
+				if used[i] {
+					continue
+				}
+				end, legKm, ok := visitStop(prev, clock, pending[i])
+				if !ok {
+					continue
+				}
+				used[i] = true
+				order = append(order, pending[i])
+				search(&pending[i], end, km+legKm)
+				order = order[:len(order)-1]
+				used[i] = false
+			}
+		}
+		search(anchor, anchorClock(anchor), 0)
+	} else {
+		best = greedyRoute(anchor, pending)
+		if km, ok := simulateRoute(anchor, best); ok && km < bestKm {
+			bestKm = km
+		} else {
+			best = nil
+		}
+	}
+
+	if best == nil || (baselineOK && bestKm >= baseline-0.01) {
+		return nil, 0, false
+	}
+
+	ids := make([]string, 0, len(stops))
+	for _, s := range fixed {
+		ids = append(ids, s.ScheduleID)
+	}
+	for _, s := range best {
+		ids = append(ids, s.ScheduleID)
+	}
+
+	total := bestKm
+	for i := 1; i < len(fixed); i++ {
+		total += domain.DistanceKm(fixed[i-1].Latitude, fixed[i-1].Longitude, fixed[i].Latitude, fixed[i].Longitude)
+	}
+	return ids, roundKm(total), true
+}
+
+// visitStop schedules stop after prev, returning when it ends and the distance travelled.
+func visitStop(prev *domain.RouteStop, clock time.Time, stop domain.RouteStop) (time.Time, float64, bool) {
+	flex := time.Duration(stop.FlexibleWindowMins) * time.Minute
+	earliest, latest := stop.StartTime.Add(-flex), stop.StartTime.Add(flex)
+	begin := earliest
+	var km float64
+	if prev != nil {
+		km = domain.DistanceKm(prev.Latitude, prev.Longitude, stop.Latitude, stop.Longitude)
+		if arrival := clock.Add(travelDuration(km, averageTravelSpeedKmh)); arrival.After(begin) {
+			begin = arrival
+		}
+	}
+	if begin.After(latest) {
+		return time.Time{}, 0, false
+	}
+	return begin.Add(stop.EndTime.Sub(stop.StartTime)), km, true
+}
+
+func simulateRoute(anchor *domain.RouteStop, order []domain.RouteStop) (float64, bool) {
+	prev, clock := anchor, anchorClock(anchor)
+	var total float64
+	for i := range order {
+		end, km, ok := visitStop(prev, clock, order[i])
+		if !ok {
+			return 0, false
+		}
+		total += km
+		prev, clock = &order[i], end
+	}
+	return total, true
+}
+
+// greedyRoute repeatedly visits the nearest stop that can still be reached in its window.
+func greedyRoute(anchor *domain.RouteStop, pending []domain.RouteStop) []domain.RouteStop {
+	remaining := append([]domain.RouteStop(nil), pending...)
+	order := make([]domain.RouteStop, 0, len(pending))
+	prev, clock := anchor, anchorClock(anchor)
+	for len(remaining) > 0 {
+		pick, pickEnd, pickKm := -1, time.Time{}, math.Inf(1)
+		for i := range remaining {
+			end, km, ok := visitStop(prev, clock, remaining[i])
+			if ok && km < pickKm {
+				pick, pickEnd, pickKm = i, end, km
+			}
+		}
+		if pick < 0 {
+			return nil
+		}
+		order = append(order, remaining[pick])
+		prev, clock = &order[len(order)-1], pickEnd
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return order
+}
+
+func anchorClock(anchor *domain.RouteStop) time.Time {
+	if anchor == nil {
+		return time.Time{}
+	}
+	return anchor.EndTime
+}
+
+func roundKm(km float64) float64 {
+	return math.Round(km*100) / 100
+}
@@ -13,18 +13,45 @@ import (
 type ScheduleUsecase struct {
 	schedules repository.ScheduleRepository
 	tasks     repository.TaskRepository
-	now       func() time.Time
+	zones     *TimezoneResolver
+	overnight domain.OvernightRule
+	// shiftLogs is set when visits may only start during an open attendance shift.
+	shiftLogs repository.CaregiverLogRepository
+	// onTimeGrace is how late past its window a visit may start and still be on time.
+	onTimeGrace time.Duration
+	// signoffs is set when clients may acknowledge visits at clock-out.
+	signoffs repository.SignoffRepository
+	// medications is set when visits carry a medication administration record.
+	medications VisitMedications
+	events      EventPublisher
+	now         func() time.Time
 }
 
+// DefaultOnTimeGrace is how late a caregiver may clock in to a visit, beyond
+// its flexible window, and still arrive on time.
+const DefaultOnTimeGrace = 5 * time.Minute
+
+// maxSignoffPINFailures is how many wrong PINs lock a client's sign-off PIN
+// until a coordinator sets a new one.
+const maxSignoffPINFailures = 5
+
+// maxMetricsRangeDays bounds the range of a metrics trend.
+const maxMetricsRangeDays = 731
+
 // NewScheduleUsecase constructs a ScheduleUsecase with sane defaults.
 func NewScheduleUsecase(
 	schedules repository.ScheduleRepository,
 	tasks repository.TaskRepository,
+	zones *TimezoneResolver,
 ) *ScheduleUsecase {
 	return &ScheduleUsecase{
-		schedules: schedules,
-		tasks:     tasks,
-		now:       time.Now,
+		schedules:   schedules,
+		tasks:       tasks,
+		zones:       zones,
+		overnight:   domain.OvernightRuleSplit,
+		onTimeGrace: DefaultOnTimeGrace,
+		events:      noEvents{},
+		now:         time.Now,
 	}
 }
 
@@ -35,9 +62,109 @@ func (uc *ScheduleUsecase) WithNow(now func() time.Time) {
 	}
 }
 
+// WithEvents publishes visit status changes to events.
+func (uc *ScheduleUsecase) WithEvents(events EventPublisher) {
+	if events != nil {
+		uc.events = events
+	}
+}
+
+// WithOvernightRule sets how visits crossing midnight are counted in metrics.
+func (uc *ScheduleUsecase) WithOvernightRule(rule domain.OvernightRule) {
+	if rule.Valid() {
+		uc.overnight = rule
+	}
+}
+
+// WithOnTimeGrace sets how late a visit may be clocked in and still count as
+// on time in metrics.
+func (uc *ScheduleUsecase) WithOnTimeGrace(grace time.Duration) {
+	if grace >= 0 {
+		uc.onTimeGrace = grace
+	}
+}
+
+// WithShiftRequirement makes StartSchedule require an open attendance session
+// that is not on a break, as recorded in logs.
+func (uc *ScheduleUsecase) WithShiftRequirement(logs repository.CaregiverLogRepository) {
+	uc.shiftLogs = logs
+}
+
+// WithSignoffs lets clients acknowledge visits at clock-out, and makes
+// EndSchedule enforce each client's sign-off settings.
+func (uc *ScheduleUsecase) WithSignoffs(signoffs repository.SignoffRepository) {
+	uc.signoffs = signoffs
+}
+
+// VisitMedications keeps a visit's medication administration record in step
+// with its clock events; MedicationUsecase implements it.
+type VisitMedications interface {
+	PrepareVisit(ctx context.Context, schedule domain.Schedule) error
+	CloseVisit(ctx context.Context, scheduleID string) error
+}
+
+// WithMedications puts the doses due during a visit on its MAR at clock-in and
+// flags the ones never recorded as missed at clock-out.
+func (uc *ScheduleUsecase) WithMedications(medications VisitMedications) {
+	uc.medications = medications
+}
+
 // ListSchedules returns all schedules for a caregiver given a filter.
-func (uc *ScheduleUsecase) ListSchedules(ctx context.Context, caregiverID string, filter repository.ScheduleFilter) ([]domain.ScheduleSummary, error) {
-	return uc.schedules.ListSchedules(ctx, caregiverID, filter)
+// Date, From and To are read as calendar dates in the caregiver's timezone; a
+// single Date cannot be combined with a range. With a Limit, the page is
+// continued by filter.After or Offset.
+func (uc *ScheduleUsecase) ListSchedules(ctx context.Context, caregiverID string, filter repository.ScheduleFilter) ([]domain.ScheduleSummary, PageInfo, error) {
+	if !filter.Sort.Valid() {
+		return nil, PageInfo{}, domain.ErrValidationFailure
+	}
+	if filter.Date != nil && (filter.From != nil || filter.To != nil) {
+		return nil, PageInfo{}, domain.ErrValidationFailure
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, PageInfo{}, domain.ErrValidationFailure
+	}
+	for _, status := range filter.Status {
+		if !status.Valid() {
+			return nil, PageInfo{}, domain.ErrValidationFailure
+		}
+	}
+
+	if filter.Date != nil || filter.From != nil || filter.To != nil {
+		loc, err := uc.zones.Location(ctx, caregiverID)
+		if err != nil {
+			return nil, PageInfo{}, err
+		}
+		localise := func(date *time.Time) *time.Time {
+			if date == nil {
+				return nil
+			}
+			day := domain.CalendarDay(*date, loc)
+			return &day
+		}
+		filter.Date = localise(filter.Date)
+		filter.From = localise(filter.From)
+		filter.To = localise(filter.To)
+	}
+
+	limit := filter.Limit
+	if limit > 0 {
+		filter.Limit = limit + 1
+	}
+	summaries, err := uc.schedules.ListSchedules(ctx, caregiverID, filter)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	n, hasMore := pageCut(len(summaries), limit)
+	summaries = summaries[:n]
+	if !hasMore {
+		return summaries, PageInfo{}, nil
+	}
+	last := summaries[n-1]
+	cursor := domain.Cursor{Time: last.StartTime, ID: last.ID}
+	if filter.Sort == "" || filter.Sort == repository.ScheduleSortStatus {
+		cursor.Rank = last.Status.ListRank()
+	}
+	return summaries, nextPage(true, cursor), nil
 }
 
 // GetSchedule fetches a full schedule including tasks.
@@ -53,6 +180,16 @@ func (uc *ScheduleUsecase) GetSchedule(ctx context.Context, scheduleID, caregive
 	}
 	schedule.Tasks = tasks
 
+	if uc.signoffs != nil {
+		signoff, err := uc.signoffs.Get(ctx, scheduleID)
+		switch {
+		case err == nil:
+			schedule.Signoff = &signoff
+		case !errors.Is(err, domain.ErrNotFound):
+			return domain.Schedule{}, err
+		}
+	}
+
 	return schedule, nil
 }
 
@@ -65,6 +202,9 @@ func (uc *ScheduleUsecase) StartSchedule(ctx context.Context, scheduleID, caregi
 	if schedule.Status != domain.ScheduleStatusScheduled && schedule.Status != domain.ScheduleStatusMissed {
 		return domain.Schedule{}, domain.ErrInvalidStatusTransition
 	}
+	if err := uc.requireOpenShift(ctx, caregiverID); err != nil {
+		return domain.Schedule{}, err
+	}
 
 	event.Timestamp = uc.now()
 	if err := uc.schedules.LogClockIn(ctx, scheduleID, event); err != nil {
@@ -73,11 +213,18 @@ func (uc *ScheduleUsecase) StartSchedule(ctx context.Context, scheduleID, caregi
 	if err := uc.schedules.UpdateStatus(ctx, scheduleID, domain.ScheduleStatusInProgress); err != nil {
 		return domain.Schedule{}, err
 	}
+	if uc.medications != nil {
+		// The visit is already in progress; the next missed-dose sweep puts
+		// its due doses on the MAR if this fails.
+		_ = uc.medications.PrepareVisit(ctx, schedule)
+	}
+	uc.publishStatus(ctx, schedule, domain.ScheduleStatusInProgress, event.Timestamp)
 
 	return uc.GetSchedule(ctx, scheduleID, caregiverID)
 }
 
-// EndSchedule records a clock-out event and transitions status to completed.
+// EndSchedule records a clock-out event, with the client's sign-off, and
+// transitions status to completed.
 func (uc *ScheduleUsecase) EndSchedule(ctx context.Context, scheduleID, caregiverID string, event domain.VisitEvent) (domain.Schedule, error) {
 	schedule, err := uc.schedules.GetScheduleForCaregiver(ctx, scheduleID, caregiverID)
 	if err != nil {
@@ -91,20 +238,194 @@ func (uc *ScheduleUsecase) EndSchedule(ctx context.Context, scheduleID, caregive
 	if event.Notes != nil && len(*event.Notes) == 0 {
 		event.Notes = nil
 	}
-
-	if err := uc.schedules.LogClockOut(ctx, scheduleID, event); err != nil {
+	signoff, err := uc.takeSignoff(ctx, schedule, event)
+	if err != nil {
 		return domain.Schedule{}, err
 	}
-	if err := uc.schedules.UpdateStatus(ctx, scheduleID, domain.ScheduleStatusCompleted); err != nil {
+
+	if err := uc.schedules.CompleteVisit(ctx, scheduleID, event, signoff); err != nil {
 		return domain.Schedule{}, err
 	}
+	if uc.medications != nil {
+		// The visit is already completed; doses left due are flagged by the
+		// next missed-dose sweep if this fails.
+		_ = uc.medications.CloseVisit(ctx, scheduleID)
+	}
+	uc.publishStatus(ctx, schedule, domain.ScheduleStatusCompleted, event.Timestamp)
 
 	return uc.GetSchedule(ctx, scheduleID, caregiverID)
 }
 
-// GetMetrics returns dashboard counts for the given day.
+// takeSignoff checks the acknowledgement carried by a clock-out against the
+// client's settings and seals it with the visit summary as it stands at
+// event.Timestamp. It returns nil when no acknowledgement was given and none
+// is required.
+func (uc *ScheduleUsecase) takeSignoff(ctx context.Context, schedule domain.Schedule, event domain.VisitEvent) (*domain.VisitSignoff, error) {
+	if uc.signoffs == nil {
+		return nil, nil
+	}
+	settings, err := uc.signoffs.GetSettings(ctx, schedule.Client.ID)
+	if err != nil {
+		return nil, err
+	}
+	capture := event.Signoff
+	if capture == nil {
+		if settings.Required {
+			return nil, domain.ErrSignoffRequired
+		}
+		return nil, nil
+	}
+
+	if capture.SignerName == "" || !capture.SignerRole.Valid() {
+		return nil, domain.ErrValidationFailure
+	}
+	switch capture.Method {
+	case domain.SignoffMethodSignature:
+		if !domain.ValidSignature(capture.SignatureFormat, capture.Signature) {
+			return nil, domain.ErrValidationFailure
+		}
+	case domain.SignoffMethodPIN:
+		if capture.PIN == "" || capture.SignatureFormat != "" || len(capture.Signature) > 0 {
+			return nil, domain.ErrValidationFailure
+		}
+		if err := uc.checkSignoffPIN(ctx, schedule, settings, capture.PIN, event.Timestamp); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, domain.ErrValidationFailure
+	}
+
+	tasks, err := uc.tasks.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	schedule.Tasks = tasks
+	summary, hash, err := domain.NewVisitSummary(schedule, event.Timestamp, *capture).Encode()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.VisitSignoff{
+		ScheduleID:      schedule.ID,
+		Method:          capture.Method,
+		SignerName:      capture.SignerName,
+		SignerRole:      capture.SignerRole,
+		SignatureFormat: capture.SignatureFormat,
+		Signature:       capture.Signature,
+		Summary:         summary,
+		SummaryHash:     hash,
+		CapturedAt:      event.Timestamp,
+	}, nil
+}
+
+// checkSignoffPIN verifies pin against the client's PIN. Each attempt is
+// recorded as wrong before the PIN is checked, and cleared if it was right,
+// so once maxSignoffPINFailures have been entered since the PIN was set it is
+// locked and no longer checked, however many requests race.
+func (uc *ScheduleUsecase) checkSignoffPIN(ctx context.Context, schedule domain.Schedule, settings domain.ClientSignoffSettings, pin string, at time.Time) error {
+	if settings.PINHash == nil {
+		return domain.ErrForbidden
+	}
+	var since time.Time
+	if settings.PINSetAt != nil {
+		since = *settings.PINSetAt
+	}
+	attemptID, err := uc.signoffs.ReservePINAttempt(ctx, domain.SignoffPINFailure{
+		ClientID:    settings.ClientID,
+		ScheduleID:  schedule.ID,
+		CaregiverID: schedule.CaregiverID,
+		AttemptedAt: at,
+	}, since, maxSignoffPINFailures)
+	if err != nil {
+		return err
+	}
+	if !verifySecret(*settings.PINHash, pin) {
+		return domain.ErrForbidden
+	}
+	return uc.signoffs.ClearPINAttempt(ctx, attemptID)
+}
+
+// Today returns midnight of the current calendar day in the caregiver's timezone.
+func (uc *ScheduleUsecase) Today(ctx context.Context, caregiverID string) (time.Time, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	start, _ := domain.DayBounds(uc.now(), loc)
+	return start, nil
+}
+
+// GetMetrics returns dashboard counts for the calendar date of day in the
+// caregiver's timezone.
 func (uc *ScheduleUsecase) GetMetrics(ctx context.Context, caregiverID string, day time.Time) (domain.ScheduleMetrics, error) {
-	return uc.schedules.GetMetrics(ctx, caregiverID, day)
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.ScheduleMetrics{}, err
+	}
+	return uc.schedules.GetMetrics(ctx, caregiverID, domain.CalendarDay(day, loc), uc.overnight)
+}
+
+// GetMetricsRange returns visit metrics between the inclusive calendar dates
+// from and to in the caregiver's timezone, bucketed by granularity. Every
+// bucket touching the range is returned, empty ones included; visits count
+// towards the bucket of the day they start on.
+func (uc *ScheduleUsecase) GetMetricsRange(ctx context.Context, caregiverID string, from, to time.Time, granularity domain.MetricsGranularity) (domain.MetricsRange, error) {
+	if !granularity.Valid() || to.Before(from) || to.Sub(from) >= maxMetricsRangeDays*24*time.Hour {
+		return domain.MetricsRange{}, domain.ErrValidationFailure
+	}
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.MetricsRange{}, err
+	}
+
+	buckets, summary, err := uc.schedules.GetMetricsRange(ctx, caregiverID, repository.MetricsRangeQuery{
+		From:        domain.CalendarDay(from, loc),
+		To:          domain.CalendarDay(to, loc).AddDate(0, 0, 1),
+		Granularity: granularity,
+		TimeZone:    loc.String(),
+		OnTimeGrace: uc.onTimeGrace,
+	})
+	if err != nil {
+		return domain.MetricsRange{}, err
+	}
+
+	byStart := make(map[string]domain.VisitMetrics, len(buckets))
+	for _, bucket := range buckets {
+		byStart[bucket.Start.Format("2006-01-02")] = bucket.Metrics
+	}
+	first := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	result := domain.MetricsRange{From: first, To: last, Granularity: granularity, Summary: summary}
+	for start := granularity.BucketStart(first); !start.After(last); start = granularity.Next(start) {
+		result.Buckets = append(result.Buckets, domain.MetricsBucket{
+			Start:   start,
+			Metrics: byStart[start.Format("2006-01-02")],
+		})
+	}
+	return result, nil
+}
+
+// GetDailyRoute returns the caregiver's visits for the day in chronological order
+// with estimated travel legs. When optimise is set and some visits have flexible
+// windows, a shorter feasible visiting order is suggested as well.
+func (uc *ScheduleUsecase) GetDailyRoute(ctx context.Context, caregiverID string, day time.Time, optimise bool) (domain.DailyRoute, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.DailyRoute{}, err
+	}
+	start := domain.CalendarDay(day, loc)
+	stops, err := uc.schedules.ListRouteStops(ctx, caregiverID, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		return domain.DailyRoute{}, err
+	}
+
+	route := buildDailyRoute(start, stops)
+	if optimise {
+		if order, km, ok := optimiseRoute(stops); ok {
+			route.OptimisedOrder = order
+			route.OptimisedDistanceKm = &km
+		}
+	}
+	return route, nil
 }
 
 // UpdateScheduleStatus allows manual transitions for admin flows.
@@ -118,7 +439,32 @@ func (uc *ScheduleUsecase) UpdateScheduleStatus(ctx context.Context, scheduleID
 	default:
 		return domain.ErrValidationFailure
 	}
-	return uc.schedules.UpdateStatus(ctx, scheduleID, status)
+	schedule, err := uc.schedules.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if err := uc.schedules.UpdateStatus(ctx, scheduleID, status); err != nil {
+		return err
+	}
+	uc.publishStatus(ctx, schedule, status, uc.now())
+	return nil
+}
+
+// publishStatus announces that schedule, as it was before the change, moved to status.
+func (uc *ScheduleUsecase) publishStatus(ctx context.Context, schedule domain.Schedule, status domain.ScheduleStatus, at time.Time) {
+	if schedule.Status == status {
+		return
+	}
+	uc.events.Publish(ctx, domain.Event{
+		Type:        domain.EventScheduleStatusChanged,
+		CaregiverID: schedule.CaregiverID,
+		ScheduleID:  schedule.ID,
+		OccurredAt:  at,
+		Data: map[string]interface{}{
+			"from":   schedule.Status,
+			"status": status,
+		},
+	})
 }
 
 // EnsureScheduleOwnership verifies the caregiver relationship.
@@ -132,3 +478,22 @@ func (uc *ScheduleUsecase) EnsureScheduleOwnership(ctx context.Context, schedule
 	}
 	return nil
 }
+
+// requireOpenShift enforces WithShiftRequirement: the caregiver's latest log
+// must leave them on shift and working.
+func (uc *ScheduleUsecase) requireOpenShift(ctx context.Context, caregiverID string) error {
+	if uc.shiftLogs == nil {
+		return nil
+	}
+	last, err := uc.shiftLogs.GetLastLog(ctx, caregiverID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrValidationFailure
+		}
+		return err
+	}
+	if last.LogType != domain.LogTypeClockIn && last.LogType != domain.LogTypeBreakEnd {
+		return domain.ErrValidationFailure
+	}
+	return nil
+}
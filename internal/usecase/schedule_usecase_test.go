@@ -14,11 +14,26 @@ var _ repository.ScheduleRepository = (*scheduleRepoStub)(nil)
 var _ repository.TaskRepository = (*taskRepoStub)(nil)
 
 type scheduleRepoStub struct {
-	schedule domain.Schedule
+	schedule    domain.Schedule
+	summaries   []domain.ScheduleSummary
+	stops       []domain.RouteStop
+	filter      repository.ScheduleFilter
+	metricsDay  time.Time
+	metricsRule domain.OvernightRule
+	visits      []domain.VisitInterval
+	buckets     []domain.MetricsBucket
+	summary     domain.VisitMetrics
+	rangeQuery  repository.MetricsRangeQuery
+	// signoffs receives the sign-offs stored by CompleteVisit.
+	signoffs *signoffRepoStub
 }
 
 func (s *scheduleRepoStub) ListSchedules(ctx context.Context, caregiverID string, filter repository.ScheduleFilter) ([]domain.ScheduleSummary, error) {
-	return nil, nil
+	s.filter = filter
+	if filter.Limit > 0 && filter.Limit < len(s.summaries) {
+		return s.summaries[:filter.Limit], nil
+	}
+	return s.summaries, nil
 }
 
 func (s *scheduleRepoStub) GetSchedule(ctx context.Context, scheduleID string) (domain.Schedule, error) {
@@ -42,13 +57,20 @@ func (s *scheduleRepoStub) LogClockIn(ctx context.Context, scheduleID string, ev
 	return nil
 }
 
-func (s *scheduleRepoStub) LogClockOut(ctx context.Context, scheduleID string, event domain.VisitEvent) error {
+func (s *scheduleRepoStub) CompleteVisit(ctx context.Context, scheduleID string, event domain.VisitEvent, signoff *domain.VisitSignoff) error {
 	if s.schedule.ID != scheduleID {
 		return domain.ErrNotFound
 	}
+	if s.schedule.Status != domain.ScheduleStatusInProgress {
+		return domain.ErrInvalidStatusTransition
+	}
+	if signoff != nil && s.signoffs != nil {
+		s.signoffs.signoffs[scheduleID] = *signoff
+	}
 	s.schedule.ClockOutAt = &event.Timestamp
 	s.schedule.ClockOutLat = &event.Latitude
 	s.schedule.ClockOutLong = &event.Longitude
+	s.schedule.Status = domain.ScheduleStatusCompleted
 	return nil
 }
 
@@ -60,10 +82,25 @@ func (s *scheduleRepoStub) UpdateStatus(ctx context.Context, scheduleID string,
 	return nil
 }
 
-func (s *scheduleRepoStub) GetMetrics(ctx context.Context, caregiverID string, day time.Time) (domain.ScheduleMetrics, error) {
+func (s *scheduleRepoStub) GetMetrics(ctx context.Context, caregiverID string, day time.Time, rule domain.OvernightRule) (domain.ScheduleMetrics, error) {
+	s.metricsDay = day
+	s.metricsRule = rule
 	return domain.ScheduleMetrics{}, nil
 }
 
+func (s *scheduleRepoStub) ListRouteStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.RouteStop, error) {
+	return s.stops, nil
+}
+
+func (s *scheduleRepoStub) GetMetricsRange(ctx context.Context, caregiverID string, query repository.MetricsRangeQuery) ([]domain.MetricsBucket, domain.VisitMetrics, error) {
+	s.rangeQuery = query
+	return s.buckets, s.summary, nil
+}
+
+func (s *scheduleRepoStub) ListVisitIntervals(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.VisitInterval, error) {
+	return s.visits, nil
+}
+
 type taskRepoStub struct {
 	tasks map[string][]domain.Task
 }
@@ -104,7 +141,7 @@ func TestScheduleUsecaseStartSchedule(t *testing.T) {
 		},
 	}}
 
-	uc := NewScheduleUsecase(repo, tasks)
+	uc := NewScheduleUsecase(repo, tasks, nil)
 	uc.WithNow(func() time.Time { return now })
 
 	result, err := uc.StartSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1.23, Longitude: 4.56})
@@ -123,6 +160,43 @@ func TestScheduleUsecaseStartSchedule(t *testing.T) {
 	}
 }
 
+type visitMedicationsStub struct {
+	prepareErr error
+	prepared   []string
+}
+
+func (s *visitMedicationsStub) PrepareVisit(ctx context.Context, schedule domain.Schedule) error {
+	s.prepared = append(s.prepared, schedule.ID)
+	return s.prepareErr
+}
+
+func (s *visitMedicationsStub) CloseVisit(ctx context.Context, scheduleID string) error {
+	return nil
+}
+
+func TestScheduleUsecaseStartScheduleIgnoresPrepareVisitFailure(t *testing.T) {
+	repo := &scheduleRepoStub{schedule: domain.Schedule{
+		ID:          "sched-1",
+		CaregiverID: "cg-1",
+		Status:      domain.ScheduleStatusScheduled,
+	}}
+	tasks := &taskRepoStub{tasks: map[string][]domain.Task{"sched-1": {}}}
+	meds := &visitMedicationsStub{prepareErr: errors.New("mar unavailable")}
+
+	uc := NewScheduleUsecase(repo, tasks, nil)
+	uc.WithMedications(meds)
+	result, err := uc.StartSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(meds.prepared) != 1 {
+		t.Fatalf("expected the visit's MAR to be prepared once, got %v", meds.prepared)
+	}
+	if result.Status != domain.ScheduleStatusInProgress {
+		t.Fatalf("expected status in_progress, got %s", result.Status)
+	}
+}
+
 func TestScheduleUsecaseStartScheduleInvalidTransition(t *testing.T) {
 	repo := &scheduleRepoStub{schedule: domain.Schedule{
 		ID:          "sched-1",
@@ -131,7 +205,7 @@ func TestScheduleUsecaseStartScheduleInvalidTransition(t *testing.T) {
 	}}
 	tasks := &taskRepoStub{tasks: map[string][]domain.Task{"sched-1": {}}}
 
-	uc := NewScheduleUsecase(repo, tasks)
+	uc := NewScheduleUsecase(repo, tasks, nil)
 	_, err := uc.StartSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1})
 	if !errors.Is(err, domain.ErrInvalidStatusTransition) {
 		t.Fatalf("expected invalid transition error, got %v", err)
@@ -149,7 +223,7 @@ func TestScheduleUsecaseEndSchedule(t *testing.T) {
 	}}
 	tasks := &taskRepoStub{tasks: map[string][]domain.Task{"sched-1": {}}}
 
-	uc := NewScheduleUsecase(repo, tasks)
+	uc := NewScheduleUsecase(repo, tasks, nil)
 	uc.WithNow(func() time.Time { return now })
 
 	_, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1})
@@ -172,7 +246,7 @@ func TestTaskUsecaseRequiresReason(t *testing.T) {
 			{ID: "task-1", ScheduleID: "sched-1", Status: domain.TaskStatusPending},
 		},
 	}}
-	NewScheduleUsecase(repo, tasks)
+	NewScheduleUsecase(repo, tasks, nil)
 	taskUC := NewTaskUsecase(tasks, repo)
 
 	err := taskUC.UpdateTaskStatus(context.Background(), "cg-1", "sched-1", "task-1", domain.TaskStatusNotCompleted, nil)
@@ -190,3 +264,422 @@ func TestTaskUsecaseRequiresReason(t *testing.T) {
 		t.Fatalf("expected reason to be persisted")
 	}
 }
+
+func TestScheduleUsecaseGetDailyRouteFlagsInfeasibleLegs(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	repo := &scheduleRepoStub{stops: []domain.RouteStop{
+		{ScheduleID: "a", Status: domain.ScheduleStatusScheduled, StartTime: day.Add(9 * time.Hour), EndTime: day.Add(10 * time.Hour), Latitude: 44.97, Longitude: -93.26},
+		{ScheduleID: "b", Status: domain.ScheduleStatusScheduled, StartTime: day.Add(11 * time.Hour), EndTime: day.Add(12 * time.Hour), Latitude: 44.99, Longitude: -93.26},
+		// About 100 km away with only 10 minutes to get there.
+		{ScheduleID: "c", Status: domain.ScheduleStatusScheduled, StartTime: day.Add(12*time.Hour + 10*time.Minute), EndTime: day.Add(13 * time.Hour), Latitude: 45.89, Longitude: -93.26},
+	}}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+
+	route, err := uc.GetDailyRoute(context.Background(), "cg-1", day.Add(15*time.Hour), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !route.Date.Equal(day) {
+		t.Fatalf("expected route date %v, got %v", day, route.Date)
+	}
+	if len(route.Legs) != 2 {
+		t.Fatalf("expected 2 legs, got %d", len(route.Legs))
+	}
+	if !route.Legs[0].Feasible || route.Legs[1].Feasible {
+		t.Fatalf("expected only second leg to be infeasible: %+v", route.Legs)
+	}
+	if route.InfeasibleLegs != 1 {
+		t.Fatalf("expected 1 infeasible leg, got %d", route.InfeasibleLegs)
+	}
+	if route.OptimisedOrder != nil {
+		t.Fatalf("expected no optimised order when not requested")
+	}
+}
+
+func TestScheduleUsecaseGetDailyRouteOptimisesFlexibleVisits(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	// Chronological order zig-zags between two neighbourhoods; flexible windows
+	// allow visiting both nearby clients back to back.
+	repo := &scheduleRepoStub{stops: []domain.RouteStop{
+		{ScheduleID: "north-1", Status: domain.ScheduleStatusScheduled, StartTime: day.Add(9 * time.Hour), EndTime: day.Add(9*time.Hour + 30*time.Minute), Latitude: 45.05, Longitude: -93.26, FlexibleWindowMins: 120},
+		{ScheduleID: "south-1", Status: domain.ScheduleStatusScheduled, StartTime: day.Add(10 * time.Hour), EndTime: day.Add(10*time.Hour + 30*time.Minute), Latitude: 44.90, Longitude: -93.26, FlexibleWindowMins: 120},
+		{ScheduleID: "north-2", Status: domain.ScheduleStatusScheduled, StartTime: day.Add(11 * time.Hour), EndTime: day.Add(11*time.Hour + 30*time.Minute), Latitude: 45.051, Longitude: -93.26, FlexibleWindowMins: 120},
+	}}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+
+	route, err := uc.GetDailyRoute(context.Background(), "cg-1", day, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if route.OptimisedOrder == nil || route.OptimisedDistanceKm == nil {
+		t.Fatalf("expected an optimised order")
+	}
+	if *route.OptimisedDistanceKm >= route.TotalDistanceKm {
+		t.Fatalf("expected optimised distance %.2f to beat %.2f", *route.OptimisedDistanceKm, route.TotalDistanceKm)
+	}
+	pos := map[string]int{}
+	for i, id := range route.OptimisedOrder {
+		pos[id] = i
+	}
+	if d := pos["north-1"] - pos["north-2"]; d != 1 && d != -1 {
+		t.Fatalf("expected north visits back to back, got %v", route.OptimisedOrder)
+	}
+}
+
+func TestScheduleUsecaseDayBoundariesUseCaregiverTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	repo := &scheduleRepoStub{}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1", Timezone: "America/New_York"}}, time.UTC)
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, zones)
+	// 03:30 UTC on 9 March is still the evening of 8 March in New York.
+	uc.WithNow(func() time.Time { return time.Date(2025, 3, 9, 3, 30, 0, 0, time.UTC) })
+
+	today, err := uc.Today(context.Background(), "cg-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2025, 3, 8, 5, 0, 0, 0, time.UTC); !today.Equal(want) {
+		t.Fatalf("expected today to start at %v, got %v", want, today.UTC())
+	}
+
+	// 9 March is the spring-forward day: 23 hours long.
+	parsed := time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC)
+	if _, _, err := uc.ListSchedules(context.Background(), "cg-1", repository.ScheduleFilter{Date: &parsed}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start, end := domain.DayBounds(*repo.filter.Date, repo.filter.Date.Location())
+	if !start.Equal(time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected day bounds %v - %v", start.UTC(), end.UTC())
+	}
+	if end.Sub(start) != 23*time.Hour {
+		t.Fatalf("expected a 23 hour day, got %v", end.Sub(start))
+	}
+
+	if _, err := uc.GetMetrics(context.Background(), "cg-1", parsed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.metricsDay.Location().String() != newYork.String() || !repo.metricsDay.Equal(start) {
+		t.Fatalf("expected metrics for %v in New York, got %v", start, repo.metricsDay)
+	}
+}
+
+func TestTimezoneResolverFallsBack(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1", Timezone: "Mars/Olympus_Mons"}}, chicago)
+
+	for _, id := range []string{"cg-1", "unknown"} {
+		loc, err := zones.Location(context.Background(), id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if loc != chicago {
+			t.Fatalf("expected fallback zone for %s, got %v", id, loc)
+		}
+	}
+}
+
+func TestScheduleUsecaseMetricsUseOvernightRule(t *testing.T) {
+	repo := &scheduleRepoStub{}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	if _, err := uc.GetMetrics(context.Background(), "cg-1", day); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.metricsRule != domain.OvernightRuleSplit {
+		t.Fatalf("expected split by default, got %q", repo.metricsRule)
+	}
+
+	uc.WithOvernightRule("bogus")
+	uc.WithOvernightRule(domain.OvernightRuleStartDay)
+	if _, err := uc.GetMetrics(context.Background(), "cg-1", day); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.metricsRule != domain.OvernightRuleStartDay {
+		t.Fatalf("expected start_day, got %q", repo.metricsRule)
+	}
+}
+
+func TestScheduleUsecaseStartScheduleRequiresOpenShift(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		logs []domain.CaregiverLog
+		ok   bool
+	}{
+		{name: "never clocked in"},
+		{name: "clocked out", logs: []domain.CaregiverLog{{LogType: domain.LogTypeClockIn}, {LogType: domain.LogTypeClockOut}}},
+		{name: "on break", logs: []domain.CaregiverLog{{LogType: domain.LogTypeClockIn}, {LogType: domain.LogTypeBreakStart}}},
+		{name: "on shift", logs: []domain.CaregiverLog{{LogType: domain.LogTypeClockIn}}, ok: true},
+		{name: "back from break", logs: []domain.CaregiverLog{{LogType: domain.LogTypeClockIn}, {LogType: domain.LogTypeBreakStart}, {LogType: domain.LogTypeBreakEnd}}, ok: true},
+	}
+
+	for _, tc := range cases {
+		repo := &scheduleRepoStub{schedule: domain.Schedule{ID: "sched-1", CaregiverID: "cg-1", Status: domain.ScheduleStatusScheduled}}
+		uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+		uc.WithNow(func() time.Time { return now })
+		uc.WithShiftRequirement(&caregiverLogRepoStub{logs: tc.logs})
+
+		_, err := uc.StartSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1.23, Longitude: 4.56})
+		if tc.ok && err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("%s: expected validation failure, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestScheduleUsecaseListSchedulesPageInfo(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &scheduleRepoStub{summaries: []domain.ScheduleSummary{
+		{ID: "sched-1", Status: domain.ScheduleStatusInProgress, StartTime: start},
+		{ID: "sched-2", Status: domain.ScheduleStatusScheduled, StartTime: start.Add(2 * time.Hour)},
+		{ID: "sched-3", Status: domain.ScheduleStatusCompleted, StartTime: start.Add(-2 * time.Hour)},
+	}}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+
+	summaries, page, err := uc.ListSchedules(context.Background(), "cg-1", repository.ScheduleFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.filter.Limit != 3 {
+		t.Fatalf("expected one row past the limit to be fetched, got %d", repo.filter.Limit)
+	}
+	if len(summaries) != 2 || !page.HasMore {
+		t.Fatalf("expected a full page with more to follow, got %d items and %+v", len(summaries), page)
+	}
+	cursor := domain.Cursor{Rank: domain.ScheduleStatusScheduled.ListRank(), Time: start.Add(2 * time.Hour), ID: "sched-2"}
+	if page.NextCursor != cursor.Encode() {
+		t.Fatalf("expected the cursor to carry the last row's sort keys, got %q", page.NextCursor)
+	}
+
+	// A page that exactly exhausts the listing has nothing more to fetch.
+	summaries, page, err = uc.ListSchedules(context.Background(), "cg-1", repository.ScheduleFilter{Limit: 3, After: &cursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 3 || page.HasMore || page.NextCursor != "" {
+		t.Fatalf("expected the final page, got %d items and %+v", len(summaries), page)
+	}
+	if repo.filter.After == nil || repo.filter.After.ID != "sched-2" {
+		t.Fatalf("expected the cursor to reach the repository, got %+v", repo.filter.After)
+	}
+
+	if _, err := domain.ParseCursor("not-a-cursor"); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected a malformed cursor to fail validation, got %v", err)
+	}
+	// Cursors carry row ids, which are UUIDs.
+	if _, err := domain.ParseCursor(cursor.Encode()); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected a cursor without a uuid to fail validation, got %v", err)
+	}
+	cursor.ID = "7d3b6a52-3f0e-4c1b-9a55-2f7d0c7e9b10"
+	if parsed, err := domain.ParseCursor(cursor.Encode()); err != nil || parsed != cursor {
+		t.Fatalf("expected the cursor to round-trip, got %+v (%v)", parsed, err)
+	}
+}
+
+func TestScheduleUsecaseListSchedulesSearch(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	repo := &scheduleRepoStub{}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1", Timezone: "America/New_York"}}, time.UTC)
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, zones)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	filter := repository.ScheduleFilter{From: &from, To: &to, Search: "oak", Sort: repository.ScheduleSortStartAsc}
+	if _, _, err := uc.ListSchedules(context.Background(), "cg-1", filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !repo.filter.From.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, newYork)) || !repo.filter.To.Equal(time.Date(2025, 3, 7, 0, 0, 0, 0, newYork)) {
+		t.Fatalf("expected the range in the caregiver's timezone, got %v - %v", repo.filter.From, repo.filter.To)
+	}
+	if repo.filter.Search != "oak" || repo.filter.Sort != repository.ScheduleSortStartAsc {
+		t.Fatalf("expected search and sort to reach the repository, got %+v", repo.filter)
+	}
+
+	invalid := []repository.ScheduleFilter{
+		{From: &to, To: &from},
+		{Date: &from, To: &to},
+		{Sort: "client"},
+		{Status: []domain.ScheduleStatus{"paused"}},
+	}
+	for _, filter := range invalid {
+		if _, _, err := uc.ListSchedules(context.Background(), "cg-1", filter); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("expected validation failure for %+v, got %v", filter, err)
+		}
+	}
+}
+
+func TestScheduleUsecaseListSchedulesCursorFollowsSort(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &scheduleRepoStub{summaries: []domain.ScheduleSummary{
+		{ID: "sched-1", Status: domain.ScheduleStatusCompleted, StartTime: start},
+		{ID: "sched-2", Status: domain.ScheduleStatusScheduled, StartTime: start.Add(time.Hour)},
+	}}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+
+	_, page, err := uc.ListSchedules(context.Background(), "cg-1", repository.ScheduleFilter{Limit: 1, Sort: repository.ScheduleSortStartAsc})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Time sorts ignore the status rank.
+	if want := (domain.Cursor{Time: start, ID: "sched-1"}).Encode(); page.NextCursor != want {
+		t.Fatalf("unexpected cursor %q", page.NextCursor)
+	}
+}
+
+func TestScheduleUsecaseGetMetricsRangeFillsBuckets(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	repo := &scheduleRepoStub{
+		buckets: []domain.MetricsBucket{
+			{Start: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), Metrics: domain.VisitMetrics{Total: 3, Completed: 2}},
+		},
+		summary: domain.VisitMetrics{Total: 3, Completed: 2},
+	}
+	zones := NewTimezoneResolver(&caregiverRepoStub{caregiver: domain.Caregiver{ID: "cg-1", Timezone: "America/New_York"}}, time.UTC)
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, zones)
+	uc.WithOnTimeGrace(10 * time.Minute)
+
+	// Wednesday 8 to Monday 20 January touches three Monday-based weeks.
+	from := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	result, err := uc.GetMetricsRange(context.Background(), "cg-1", from, to, domain.MetricsGranularityWeek)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := repo.rangeQuery
+	if !query.From.Equal(time.Date(2025, 1, 8, 0, 0, 0, 0, newYork)) || !query.To.Equal(time.Date(2025, 1, 21, 0, 0, 0, 0, newYork)) {
+		t.Fatalf("unexpected range %v - %v", query.From, query.To)
+	}
+	if query.TimeZone != "America/New_York" || query.OnTimeGrace != 10*time.Minute || query.Granularity != domain.MetricsGranularityWeek {
+		t.Fatalf("unexpected query %+v", query)
+	}
+
+	if len(result.Buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %d", len(result.Buckets))
+	}
+	for i, day := range []int{6, 13, 20} {
+		if want := time.Date(2025, 1, day, 0, 0, 0, 0, time.UTC); !result.Buckets[i].Start.Equal(want) {
+			t.Fatalf("bucket %d: expected start %v, got %v", i, want, result.Buckets[i].Start)
+		}
+	}
+	if result.Buckets[0].Metrics.Total != 0 || result.Buckets[1].Metrics.Total != 3 {
+		t.Fatalf("unexpected bucket metrics %+v", result.Buckets)
+	}
+	if result.Summary.Completed != 2 {
+		t.Fatalf("unexpected summary %+v", result.Summary)
+	}
+}
+
+func TestScheduleUsecaseGetMetricsRangeValidation(t *testing.T) {
+	uc := NewScheduleUsecase(&scheduleRepoStub{}, &taskRepoStub{}, nil)
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name        string
+		from, to    time.Time
+		granularity domain.MetricsGranularity
+	}{
+		{"unknown granularity", day, day, "year"},
+		{"reversed", day, day.AddDate(0, 0, -1), domain.MetricsGranularityDay},
+		{"too long", day, day.AddDate(0, 0, maxMetricsRangeDays), domain.MetricsGranularityMonth},
+	}
+	for _, tc := range cases {
+		if _, err := uc.GetMetricsRange(context.Background(), "cg-1", tc.from, tc.to, tc.granularity); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("%s: expected validation failure, got %v", tc.name, err)
+		}
+	}
+
+	result, err := uc.GetMetricsRange(context.Background(), "cg-1", day, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), domain.MetricsGranularityMonth)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Buckets) != 3 || !result.Buckets[0].Start.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected month buckets %+v", result.Buckets)
+	}
+}
+
+type eventRecorder struct {
+	events []domain.Event
+}
+
+func (r *eventRecorder) Publish(ctx context.Context, event domain.Event) {
+	r.events = append(r.events, event)
+}
+
+func TestScheduleUsecaseStartSchedulePublishesStatusChange(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo := &scheduleRepoStub{schedule: domain.Schedule{ID: "sched-1", CaregiverID: "cg-1", Status: domain.ScheduleStatusScheduled}}
+	tasks := &taskRepoStub{tasks: map[string][]domain.Task{"sched-1": {}}}
+	recorder := &eventRecorder{}
+
+	uc := NewScheduleUsecase(repo, tasks, nil)
+	uc.WithNow(func() time.Time { return now })
+	uc.WithEvents(recorder)
+
+	if _, err := uc.StartSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.events) != 1 {
+		t.Fatalf("expected one event, got %d", len(recorder.events))
+	}
+	event := recorder.events[0]
+	if event.Type != domain.EventScheduleStatusChanged || event.CaregiverID != "cg-1" || event.ScheduleID != "sched-1" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if event.Data["from"] != domain.ScheduleStatusScheduled || event.Data["status"] != domain.ScheduleStatusInProgress {
+		t.Fatalf("unexpected event data %v", event.Data)
+	}
+	if !event.OccurredAt.Equal(now) {
+		t.Fatalf("expected event at %v, got %v", now, event.OccurredAt)
+	}
+
+	// A rejected transition stores nothing and announces nothing.
+	if _, err := uc.StartSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1}); err == nil {
+		t.Fatalf("expected second start to fail")
+	}
+	if len(recorder.events) != 1 {
+		t.Fatalf("expected no event for a failed start, got %d", len(recorder.events))
+	}
+}
+
+func TestTaskUsecasePublishesTaskEvents(t *testing.T) {
+	repo := &scheduleRepoStub{schedule: domain.Schedule{ID: "sched-1", CaregiverID: "cg-1", Status: domain.ScheduleStatusInProgress}}
+	tasks := &taskRepoStub{tasks: map[string][]domain.Task{
+		"sched-1": {{ID: "task-1", ScheduleID: "sched-1", Status: domain.TaskStatusPending}},
+	}}
+	recorder := &eventRecorder{}
+	taskUC := NewTaskUsecase(tasks, repo)
+	taskUC.WithEvents(recorder)
+
+	if err := taskUC.UpdateTaskStatus(context.Background(), "cg-1", "sched-1", "task-1", domain.TaskStatusCompleted, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := taskUC.AddTask(context.Background(), "cg-1", "sched-1", domain.Task{Title: "Extra"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("expected two events, got %d", len(recorder.events))
+	}
+	if recorder.events[0].Type != domain.EventTaskUpdated || recorder.events[0].Data["task_id"] != "task-1" {
+		t.Fatalf("unexpected update event %+v", recorder.events[0])
+	}
+	if recorder.events[1].Type != domain.EventTaskCreated || recorder.events[1].Data["task_id"] != "task-123" {
+		t.Fatalf("unexpected create event %+v", recorder.events[1])
+	}
+}
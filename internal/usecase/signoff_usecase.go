@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// clientPIN is the form of a client's sign-off PIN.
+var clientPIN = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+// SignoffSettingsUpdate changes the fields that are not nil. An empty PIN
+// removes the client's PIN.
+type SignoffSettingsUpdate struct {
+	Required *bool
+	PIN      *string
+}
+
+// SignoffUsecase lets coordinators configure how each client acknowledges visits.
+type SignoffUsecase struct {
+	signoffs repository.SignoffRepository
+	now      func() time.Time
+}
+
+// NewSignoffUsecase constructs a SignoffUsecase.
+func NewSignoffUsecase(signoffs repository.SignoffRepository) *SignoffUsecase {
+	return &SignoffUsecase{signoffs: signoffs, now: time.Now}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *SignoffUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// GetSettings returns the client's sign-off settings.
+func (uc *SignoffUsecase) GetSettings(ctx context.Context, clientID string) (domain.ClientSignoffSettings, error) {
+	return uc.signoffs.GetSettings(ctx, clientID)
+}
+
+// UpdateSettings records a change to the client's sign-off settings.
+func (uc *SignoffUsecase) UpdateSettings(ctx context.Context, clientID, updaterID string, update SignoffSettingsUpdate) (domain.ClientSignoffSettings, error) {
+	if update.PIN != nil && *update.PIN != "" && !clientPIN.MatchString(*update.PIN) {
+		return domain.ClientSignoffSettings{}, domain.ErrValidationFailure
+	}
+	settings, err := uc.signoffs.GetSettings(ctx, clientID)
+	if err != nil {
+		return domain.ClientSignoffSettings{}, err
+	}
+	if update.Required != nil {
+		settings.Required = *update.Required
+	}
+	now := uc.now().UTC()
+	if update.PIN != nil {
+		settings.PINHash = nil
+		settings.PINSetAt = nil
+		if *update.PIN != "" {
+			hash, err := hashSecret(*update.PIN)
+			if err != nil {
+				return domain.ClientSignoffSettings{}, err
+			}
+			settings.PINHash = &hash
+			settings.PINSetAt = &now
+		}
+	}
+	settings.UpdatedBy = &updaterID
+	settings.UpdatedAt = &now
+	if err := uc.signoffs.SaveSettings(ctx, settings); err != nil {
+		return domain.ClientSignoffSettings{}, err
+	}
+	return settings, nil
+}
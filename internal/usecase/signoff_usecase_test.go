@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.SignoffRepository = (*signoffRepoStub)(nil)
+
+type signoffRepoStub struct {
+	settings    map[string]domain.ClientSignoffSettings
+	signoffs    map[string]domain.VisitSignoff
+	mu          sync.Mutex
+	pinFailures []domain.SignoffPINFailure
+	pinIDs      []string
+	attempts    int
+}
+
+func newSignoffRepoStub(settings ...domain.ClientSignoffSettings) *signoffRepoStub {
+	stub := &signoffRepoStub{
+		settings: map[string]domain.ClientSignoffSettings{},
+		signoffs: map[string]domain.VisitSignoff{},
+	}
+	for _, s := range settings {
+		stub.settings[s.ClientID] = s
+	}
+	return stub
+}
+
+func (s *signoffRepoStub) GetSettings(ctx context.Context, clientID string) (domain.ClientSignoffSettings, error) {
+	if settings, ok := s.settings[clientID]; ok {
+		return settings, nil
+	}
+	return domain.ClientSignoffSettings{ClientID: clientID}, nil
+}
+
+func (s *signoffRepoStub) SaveSettings(ctx context.Context, settings domain.ClientSignoffSettings) error {
+	s.settings[settings.ClientID] = settings
+	return nil
+}
+
+func (s *signoffRepoStub) ReservePINAttempt(ctx context.Context, attempt domain.SignoffPINFailure, since time.Time, limit int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, f := range s.pinFailures {
+		if f.ClientID == attempt.ClientID && !f.AttemptedAt.Before(since) {
+			count++
+		}
+	}
+	if count >= limit {
+		return "", domain.ErrSignoffPINLocked
+	}
+	s.attempts++
+	id := fmt.Sprintf("attempt-%d", s.attempts)
+	s.pinFailures = append(s.pinFailures, attempt)
+	s.pinIDs = append(s.pinIDs, id)
+	return id, nil
+}
+
+func (s *signoffRepoStub) ClearPINAttempt(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, pinID := range s.pinIDs {
+		if pinID == id {
+			s.pinFailures = append(s.pinFailures[:i], s.pinFailures[i+1:]...)
+			s.pinIDs = append(s.pinIDs[:i], s.pinIDs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *signoffRepoStub) Save(ctx context.Context, signoff domain.VisitSignoff) error {
+	s.signoffs[signoff.ScheduleID] = signoff
+	return nil
+}
+
+func (s *signoffRepoStub) Get(ctx context.Context, scheduleID string) (domain.VisitSignoff, error) {
+	signoff, ok := s.signoffs[scheduleID]
+	if !ok {
+		return domain.VisitSignoff{}, domain.ErrNotFound
+	}
+	return signoff, nil
+}
+
+func signoffSchedule(clockIn time.Time) *scheduleRepoStub {
+	return &scheduleRepoStub{schedule: domain.Schedule{
+		ID:          "sched-1",
+		CaregiverID: "cg-1",
+		Client:      domain.Client{ID: "client-1"},
+		ServiceName: "Personal Care",
+		StartTime:   clockIn,
+		EndTime:     clockIn.Add(time.Hour),
+		Status:      domain.ScheduleStatusInProgress,
+		ClockInAt:   &clockIn,
+	}}
+}
+
+func TestScheduleUsecaseEndScheduleRequiresSignoff(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	repo := signoffSchedule(now.Add(-time.Hour))
+	signoffs := newSignoffRepoStub(domain.ClientSignoffSettings{ClientID: "client-1", Required: true})
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+	uc.WithNow(func() time.Time { return now })
+	uc.WithSignoffs(signoffs)
+	repo.signoffs = signoffs
+
+	_, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1})
+	if !errors.Is(err, domain.ErrSignoffRequired) {
+		t.Fatalf("expected signoff required, got %v", err)
+	}
+	if repo.schedule.Status != domain.ScheduleStatusInProgress || repo.schedule.ClockOutAt != nil {
+		t.Fatalf("expected the visit left open, got %+v", repo.schedule)
+	}
+
+	// A client without settings may be clocked out without acknowledgement.
+	repo.schedule.Client.ID = "client-2"
+	schedule, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedule.Signoff != nil || len(signoffs.signoffs) != 0 {
+		t.Fatalf("expected no signoff stored, got %+v", schedule.Signoff)
+	}
+}
+
+func TestScheduleUsecaseEndScheduleSealsSignature(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 500, time.UTC)
+	repo := signoffSchedule(now.Add(-time.Hour))
+	tasks := &taskRepoStub{tasks: map[string][]domain.Task{"sched-1": {
+		{ID: "task-1", Title: "Lunch", Status: domain.TaskStatusCompleted},
+	}}}
+	signoffs := newSignoffRepoStub(domain.ClientSignoffSettings{ClientID: "client-1", Required: true})
+	uc := NewScheduleUsecase(repo, tasks, nil)
+	uc.WithNow(func() time.Time { return now })
+	uc.WithSignoffs(signoffs)
+	repo.signoffs = signoffs
+
+	capture := &domain.SignoffCapture{
+		Method:          domain.SignoffMethodSignature,
+		SignerName:      "Jane Doe",
+		SignerRole:      domain.SignerRoleClient,
+		SignatureFormat: domain.SignatureFormatSVG,
+		Signature:       []byte("<svg onload=alert(1)>"),
+	}
+	_, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1, Signoff: capture})
+	if !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected markup rejected as a signature, got %v", err)
+	}
+
+	capture.Signature = []byte("M 10 10 L 20 25.5 C 30,40 50,60 70,80")
+	schedule, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1, Signoff: capture})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schedule.Signoff == nil || schedule.Signoff.Method != domain.SignoffMethodSignature || !schedule.Signoff.CapturedAt.Equal(now) {
+		t.Fatalf("expected the signoff in the visit detail, got %+v", schedule.Signoff)
+	}
+
+	stored := signoffs.signoffs["sched-1"]
+	sum := sha256.Sum256(stored.Summary)
+	if stored.SummaryHash != fmt.Sprintf("%x", sum[:]) {
+		t.Fatalf("expected summary hash to match the stored summary")
+	}
+	var summary domain.VisitSummary
+	if err := json.Unmarshal(stored.Summary, &summary); err != nil {
+		t.Fatalf("summary is not valid json: %v", err)
+	}
+	if !summary.ClockOutAt.Equal(now.Truncate(time.Second)) || len(summary.Tasks) != 1 || summary.Tasks[0].Status != domain.TaskStatusCompleted {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if summary.SignatureSHA256 == "" || summary.SignerName != "Jane Doe" {
+		t.Fatalf("expected the signature bound to the summary, got %+v", summary)
+	}
+}
+
+func TestScheduleUsecaseEndScheduleVerifiesPIN(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	repo := signoffSchedule(now.Add(-time.Hour))
+	signoffs := newSignoffRepoStub()
+	settingsUC := NewSignoffUsecase(signoffs)
+	pin := "4821"
+	if _, err := settingsUC.UpdateSettings(context.Background(), "client-1", "coordinator", SignoffSettingsUpdate{PIN: &pin}); err != nil {
+		t.Fatalf("UpdateSettings error: %v", err)
+	}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+	uc.WithNow(func() time.Time { return now })
+	uc.WithSignoffs(signoffs)
+	repo.signoffs = signoffs
+
+	capture := &domain.SignoffCapture{
+		Method:     domain.SignoffMethodPIN,
+		SignerName: "John Doe",
+		SignerRole: domain.SignerRoleRepresentative,
+		PIN:        "0000",
+	}
+	_, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1, Signoff: capture})
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected forbidden for a wrong pin, got %v", err)
+	}
+
+	capture.PIN = pin
+	if _, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Latitude: 1, Longitude: 1, Signoff: capture}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored := signoffs.signoffs["sched-1"]
+	if stored.Method != domain.SignoffMethodPIN || stored.SignerRole != domain.SignerRoleRepresentative || stored.Signature != nil {
+		t.Fatalf("unexpected stored signoff: %+v", stored)
+	}
+}
+
+func TestScheduleUsecaseEndScheduleLocksPINAfterFailures(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	repo := signoffSchedule(now.Add(-time.Hour))
+	signoffs := newSignoffRepoStub()
+	settingsUC := NewSignoffUsecase(signoffs)
+	settingsUC.WithNow(func() time.Time { return now.Add(-time.Hour) })
+	pin := "4821"
+	if _, err := settingsUC.UpdateSettings(context.Background(), "client-1", "coordinator", SignoffSettingsUpdate{PIN: &pin}); err != nil {
+		t.Fatalf("UpdateSettings error: %v", err)
+	}
+	uc := NewScheduleUsecase(repo, &taskRepoStub{}, nil)
+	uc.WithNow(func() time.Time { return now })
+	uc.WithSignoffs(signoffs)
+	repo.signoffs = signoffs
+
+	capture := &domain.SignoffCapture{
+		Method:     domain.SignoffMethodPIN,
+		SignerName: "John Doe",
+		SignerRole: domain.SignerRoleRepresentative,
+		PIN:        "0000",
+	}
+	for i := 0; i < maxSignoffPINFailures; i++ {
+		_, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Signoff: capture})
+		if !errors.Is(err, domain.ErrForbidden) {
+			t.Fatalf("attempt %d: expected forbidden for a wrong pin, got %v", i+1, err)
+		}
+	}
+	if len(signoffs.pinFailures) != maxSignoffPINFailures {
+		t.Fatalf("expected %d failures recorded, got %d", maxSignoffPINFailures, len(signoffs.pinFailures))
+	}
+	if f := signoffs.pinFailures[0]; f.ClientID != "client-1" || f.ScheduleID != "sched-1" || f.CaregiverID != "cg-1" || !f.AttemptedAt.Equal(now) {
+		t.Fatalf("unexpected failure record: %+v", f)
+	}
+
+	// Once locked, even the right PIN is refused and nothing more is recorded.
+	capture.PIN = pin
+	_, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Signoff: capture})
+	if !errors.Is(err, domain.ErrSignoffPINLocked) {
+		t.Fatalf("expected the pin locked, got %v", err)
+	}
+	if len(signoffs.pinFailures) != maxSignoffPINFailures || repo.schedule.Status != domain.ScheduleStatusInProgress {
+		t.Fatalf("expected the visit left open without further failures")
+	}
+
+	// Setting a new PIN unlocks it.
+	settingsUC.WithNow(func() time.Time { return now.Add(time.Minute) })
+	if _, err := settingsUC.UpdateSettings(context.Background(), "client-1", "coordinator", SignoffSettingsUpdate{PIN: &pin}); err != nil {
+		t.Fatalf("UpdateSettings error: %v", err)
+	}
+	if _, err := uc.EndSchedule(context.Background(), "sched-1", "cg-1", domain.VisitEvent{Signoff: capture}); err != nil {
+		t.Fatalf("expected the new pin accepted, got %v", err)
+	}
+	if len(signoffs.pinFailures) != maxSignoffPINFailures {
+		t.Fatalf("expected the right pin not recorded as a failure, got %d failures", len(signoffs.pinFailures))
+	}
+}
+
+func TestScheduleUsecaseCheckSignoffPINLocksConcurrentAttempts(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	hash, err := hashSecret("4821")
+	if err != nil {
+		t.Fatalf("hashSecret error: %v", err)
+	}
+	setAt := now.Add(-time.Hour)
+	settings := domain.ClientSignoffSettings{ClientID: "client-1", PINHash: &hash, PINSetAt: &setAt}
+	signoffs := newSignoffRepoStub(settings)
+	uc := NewScheduleUsecase(signoffSchedule(now.Add(-time.Hour)), &taskRepoStub{}, nil)
+	uc.WithSignoffs(signoffs)
+	schedule := domain.Schedule{ID: "sched-1", CaregiverID: "cg-1"}
+
+	const attempts = maxSignoffPINFailures * 3
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- uc.checkSignoffPIN(context.Background(), schedule, settings, fmt.Sprintf("%04d", i), now)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	checked, locked := 0, 0
+	for err := range errs {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			checked++
+		case errors.Is(err, domain.ErrSignoffPINLocked):
+			locked++
+		default:
+			t.Fatalf("unexpected result: %v", err)
+		}
+	}
+	if checked != maxSignoffPINFailures || locked != attempts-maxSignoffPINFailures {
+		t.Fatalf("expected %d pins checked and the rest locked, got %d checked and %d locked", maxSignoffPINFailures, checked, locked)
+	}
+	if len(signoffs.pinFailures) != maxSignoffPINFailures {
+		t.Fatalf("expected %d failures recorded, got %d", maxSignoffPINFailures, len(signoffs.pinFailures))
+	}
+}
+
+func TestSignoffUsecaseUpdateSettings(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	signoffs := newSignoffRepoStub()
+	uc := NewSignoffUsecase(signoffs)
+	uc.WithNow(func() time.Time { return now })
+	ctx := context.Background()
+
+	short := "12"
+	if _, err := uc.UpdateSettings(ctx, "client-1", "coordinator", SignoffSettingsUpdate{PIN: &short}); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure for a short pin, got %v", err)
+	}
+
+	required := true
+	pin := "123456"
+	settings, err := uc.UpdateSettings(ctx, "client-1", "coordinator", SignoffSettingsUpdate{Required: &required, PIN: &pin})
+	if err != nil {
+		t.Fatalf("UpdateSettings error: %v", err)
+	}
+	if !settings.Required || settings.PINHash == nil || *settings.PINHash == pin || !verifySecret(*settings.PINHash, pin) {
+		t.Fatalf("expected required with a hashed pin, got %+v", settings)
+	}
+	if settings.UpdatedAt == nil || !settings.UpdatedAt.Equal(now) {
+		t.Fatalf("expected updated_at %v, got %v", now, settings.UpdatedAt)
+	}
+
+	none := ""
+	settings, err = uc.UpdateSettings(ctx, "client-1", "coordinator", SignoffSettingsUpdate{PIN: &none})
+	if err != nil {
+		t.Fatalf("UpdateSettings error: %v", err)
+	}
+	if !settings.Required || settings.PINHash != nil {
+		t.Fatalf("expected the pin cleared and required kept, got %+v", settings)
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
 	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
@@ -11,6 +12,8 @@ import (
 type TaskUsecase struct {
 	tasks     repository.TaskRepository
 	schedules repository.ScheduleRepository
+	events    EventPublisher
+	now       func() time.Time
 }
 
 // NewTaskUsecase creates a TaskUsecase instance.
@@ -18,6 +21,15 @@ func NewTaskUsecase(tasks repository.TaskRepository, schedules repository.Schedu
 	return &TaskUsecase{
 		tasks:     tasks,
 		schedules: schedules,
+		events:    noEvents{},
+		now:       time.Now,
+	}
+}
+
+// WithEvents publishes task changes to events.
+func (uc *TaskUsecase) WithEvents(events EventPublisher) {
+	if events != nil {
+		uc.events = events
 	}
 }
 
@@ -41,7 +53,15 @@ func (uc *TaskUsecase) UpdateTaskStatus(ctx context.Context, caregiverID, schedu
 		reason = nil
 	}
 
-	return uc.tasks.UpdateTaskStatus(ctx, taskID, status, reason)
+	if err := uc.tasks.UpdateTaskStatus(ctx, taskID, status, reason); err != nil {
+		return err
+	}
+	data := map[string]interface{}{"task_id": taskID, "status": status}
+	if reason != nil {
+		data["reason"] = *reason
+	}
+	uc.publish(ctx, domain.EventTaskUpdated, caregiverID, scheduleID, data)
+	return nil
 }
 
 // AddTask appends a new task to the schedule.
@@ -54,7 +74,26 @@ func (uc *TaskUsecase) AddTask(ctx context.Context, caregiverID, scheduleID stri
 		return "", domain.ErrValidationFailure
 	}
 	task.Status = domain.TaskStatusPending
-	return uc.tasks.CreateTask(ctx, task)
+	id, err := uc.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return "", err
+	}
+	uc.publish(ctx, domain.EventTaskCreated, caregiverID, scheduleID, map[string]interface{}{
+		"task_id": id,
+		"title":   task.Title,
+		"status":  task.Status,
+	})
+	return id, nil
+}
+
+func (uc *TaskUsecase) publish(ctx context.Context, eventType domain.EventType, caregiverID, scheduleID string, data map[string]interface{}) {
+	uc.events.Publish(ctx, domain.Event{
+		Type:        eventType,
+		CaregiverID: caregiverID,
+		ScheduleID:  scheduleID,
+		OccurredAt:  uc.now(),
+		Data:        data,
+	})
 }
 
 func (uc *TaskUsecase) validateOwnership(ctx context.Context, caregiverID, scheduleID, taskID string) error {
@@ -71,6 +110,9 @@ func (uc *TaskUsecase) validateOwnership(ctx context.Context, caregiverID, sched
 	}
 	for _, t := range tasks {
 		if t.ID == taskID {
+			if t.MedicationAdministrationID != nil {
+				return domain.ErrRecordOnMAR
+			}
 			return nil
 		}
 	}
@@ -67,7 +67,7 @@ func (s *scheduleRepoStubForTask) LogClockIn(ctx context.Context, scheduleID str
 	return nil
 }
 
-func (s *scheduleRepoStubForTask) LogClockOut(ctx context.Context, scheduleID string, event domain.VisitEvent) error {
+func (s *scheduleRepoStubForTask) CompleteVisit(ctx context.Context, scheduleID string, event domain.VisitEvent, signoff *domain.VisitSignoff) error {
 	return nil
 }
 
@@ -75,10 +75,22 @@ func (s *scheduleRepoStubForTask) UpdateStatus(ctx context.Context, scheduleID s
 	return nil
 }
 
-func (s *scheduleRepoStubForTask) GetMetrics(ctx context.Context, caregiverID string, day time.Time) (domain.ScheduleMetrics, error) {
+func (s *scheduleRepoStubForTask) GetMetrics(ctx context.Context, caregiverID string, day time.Time, rule domain.OvernightRule) (domain.ScheduleMetrics, error) {
 	return domain.ScheduleMetrics{}, nil
 }
 
+func (s *scheduleRepoStubForTask) ListRouteStops(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.RouteStop, error) {
+	return nil, nil
+}
+
+func (s *scheduleRepoStubForTask) GetMetricsRange(ctx context.Context, caregiverID string, query repository.MetricsRangeQuery) ([]domain.MetricsBucket, domain.VisitMetrics, error) {
+	return nil, domain.VisitMetrics{}, nil
+}
+
+func (s *scheduleRepoStubForTask) ListVisitIntervals(ctx context.Context, caregiverID string, from, to time.Time) ([]domain.VisitInterval, error) {
+	return nil, nil
+}
+
 func TestTaskUsecaseUpdateTaskStatus(t *testing.T) {
 	scheduleRepo := &scheduleRepoStubForTask{
 		schedule: domain.Schedule{
@@ -371,4 +383,38 @@ func TestTaskUsecaseAddTaskEmptyTitle(t *testing.T) {
 	if err != domain.ErrValidationFailure {
 		t.Fatalf("expected validation failure error, got %v", err)
 	}
-}
\ No newline at end of file
+}
+func TestTaskUsecaseUpdateTaskStatusMedicationTask(t *testing.T) {
+	scheduleRepo := &scheduleRepoStubForTask{
+		schedule: domain.Schedule{
+			ID:          "sched-1",
+			CaregiverID: "caregiver-1",
+			Status:      domain.ScheduleStatusInProgress,
+		},
+	}
+
+	administrationID := "dose-1"
+	taskRepo := &taskRepoStubForTask{
+		tasks: map[string][]domain.Task{
+			"sched-1": {
+				{
+					ID:                         "task-1",
+					ScheduleID:                 "sched-1",
+					Title:                      "Lisinopril 10 mg (oral)",
+					Status:                     domain.TaskStatusPending,
+					MedicationAdministrationID: &administrationID,
+				},
+			},
+		},
+	}
+
+	uc := NewTaskUsecase(taskRepo, scheduleRepo)
+
+	err := uc.UpdateTaskStatus(context.Background(), "caregiver-1", "sched-1", "task-1", domain.TaskStatusCompleted, nil)
+	if err != domain.ErrRecordOnMAR {
+		t.Fatalf("expected record on MAR error, got %v", err)
+	}
+	if status := taskRepo.tasks["sched-1"][0].Status; status != domain.TaskStatusPending {
+		t.Fatalf("expected task to stay pending, got %s", status)
+	}
+}
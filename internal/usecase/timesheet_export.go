@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+)
+
+var timesheetCSVHeader = []string{
+	"timesheet_id", "caregiver_id", "status", "work_date",
+	"worked_hours", "regular_hours", "overtime_hours", "break_minutes",
+	"visit_minutes", "completed_visits", "mileage_km", "mileage_amount",
+}
+
+// encodeTimesheetCSV renders a timesheet as a CSV file, one calendar day per row,
+// with the approved mileage for each day.
+func encodeTimesheetCSV(timesheet domain.Timesheet, mileage []domain.MileageClaim, loc *time.Location) ([]byte, error) {
+	claims := mileageByDate(mileage)
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(timesheetCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, day := range timesheet.Days {
+		date := day.Date.Format("2006-01-02")
+		claim := claims[date]
+		row := []string{
+			timesheet.ID, timesheet.CaregiverID, string(timesheet.Status), date,
+			formatHours(day.WorkedMinutes), formatHours(day.RegularMinutes), formatHours(day.OvertimeMinutes),
+			strconv.Itoa(day.BreakMinutes), strconv.Itoa(day.VisitMinutes), strconv.Itoa(day.CompletedVisits),
+			formatAmount(claim.ClaimedKm), formatAmount(claim.Amount),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type timesheetPayroll struct {
+	TimesheetID string                 `json:"timesheet_id"`
+	CaregiverID string                 `json:"caregiver_id"`
+	Status      domain.TimesheetStatus `json:"status"`
+	Period      timesheetPayrollPeriod `json:"period"`
+	Earnings    []timesheetEarning     `json:"earnings"`
+	// Reimbursements are paid on top of earnings and are not hours.
+	Reimbursements []timesheetReimbursement `json:"reimbursements"`
+	Days           []timesheetPayrollDay    `json:"days"`
+	ApprovedBy     *string                  `json:"approved_by,omitempty"`
+	ApprovedAt     *time.Time               `json:"approved_at,omitempty"`
+	LockedAt       *time.Time               `json:"locked_at,omitempty"`
+}
+
+type timesheetPayrollPeriod struct {
+	Type domain.TimesheetPeriod `json:"type"`
+	From string                 `json:"from"`
+	To   string                 `json:"to"`
+}
+
+type timesheetEarning struct {
+	Code  string  `json:"code"`
+	Hours float64 `json:"hours"`
+}
+
+type timesheetReimbursement struct {
+	Code       string  `json:"code"`
+	DistanceKm float64 `json:"distance_km"`
+	Amount     float64 `json:"amount"`
+}
+
+type timesheetPayrollDay struct {
+	Date            string  `json:"date"`
+	RegularHours    float64 `json:"regular_hours"`
+	OvertimeHours   float64 `json:"overtime_hours"`
+	BreakMinutes    int     `json:"break_minutes"`
+	VisitMinutes    int     `json:"visit_minutes"`
+	CompletedVisits int     `json:"completed_visits"`
+	MileageKm       float64 `json:"mileage_km"`
+	MileageAmount   float64 `json:"mileage_amount"`
+}
+
+// encodeTimesheetPayrollJSON renders a timesheet as a payroll document with
+// regular (REG) and overtime (OT) earnings lines and a mileage (MILEAGE)
+// reimbursement line.
+func encodeTimesheetPayrollJSON(timesheet domain.Timesheet, mileage []domain.MileageClaim, loc *time.Location) ([]byte, error) {
+	claims := mileageByDate(mileage)
+	var mileageKm, mileageAmount float64
+	for _, claim := range mileage {
+		mileageKm += claim.ClaimedKm
+		mileageAmount += claim.Amount
+	}
+
+	doc := timesheetPayroll{
+		TimesheetID: timesheet.ID,
+		CaregiverID: timesheet.CaregiverID,
+		Status:      timesheet.Status,
+		Period: timesheetPayrollPeriod{
+			Type: timesheet.Period,
+			From: timesheet.PeriodStart.In(loc).Format("2006-01-02"),
+			To:   timesheet.PeriodEnd.In(loc).AddDate(0, 0, -1).Format("2006-01-02"),
+		},
+		Earnings: []timesheetEarning{
+			{Code: "REG", Hours: hours(timesheet.RegularMinutes)},
+			{Code: "OT", Hours: hours(timesheet.OvertimeMinutes)},
+		},
+		Reimbursements: []timesheetReimbursement{
+			{Code: "MILEAGE", DistanceKm: roundKm(mileageKm), Amount: math.Round(mileageAmount*100) / 100},
+		},
+		Days:     make([]timesheetPayrollDay, len(timesheet.Days)),
+		LockedAt: timesheet.LockedAt,
+	}
+	if timesheet.Status == domain.TimesheetStatusApproved || timesheet.Status == domain.TimesheetStatusLocked {
+		doc.ApprovedBy, doc.ApprovedAt = timesheet.ReviewedBy, timesheet.ReviewedAt
+	}
+	for i, day := range timesheet.Days {
+		date := day.Date.Format("2006-01-02")
+		doc.Days[i] = timesheetPayrollDay{
+			Date:            date,
+			RegularHours:    hours(day.RegularMinutes),
+			OvertimeHours:   hours(day.OvertimeMinutes),
+			BreakMinutes:    day.BreakMinutes,
+			VisitMinutes:    day.VisitMinutes,
+			CompletedVisits: day.CompletedVisits,
+			MileageKm:       claims[date].ClaimedKm,
+			MileageAmount:   claims[date].Amount,
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// hours converts minutes to hours rounded to two decimals.
+func hours(minutes int) float64 {
+	return math.Round(float64(minutes)/60*100) / 100
+}
+
+func formatHours(minutes int) string {
+	return strconv.FormatFloat(hours(minutes), 'f', 2, 64)
+}
+
+func formatAmount(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// mileageByDate indexes claims by their calendar date.
+func mileageByDate(claims []domain.MileageClaim) map[string]domain.MileageClaim {
+	byDate := make(map[string]domain.MileageClaim, len(claims))
+	for _, claim := range claims {
+		byDate[claim.ClaimDate.Format("2006-01-02")] = claim
+	}
+	return byDate
+}
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// TimesheetPolicy configures how pay periods are laid out and paid.
+type TimesheetPolicy struct {
+	Period domain.TimesheetPeriod
+	// Anchor is a calendar date on which a period starts; periods repeat every
+	// Period.Days() days before and after it.
+	Anchor    time.Time
+	Overtime  domain.OvertimeRules
+	Overnight domain.OvernightRule
+}
+
+// TimesheetExport is a rendered timesheet file.
+type TimesheetExport struct {
+	Filename    string
+	ContentType string
+	Payload     []byte
+}
+
+// TimesheetUsecase builds timesheets from attendance and visits and runs their
+// submit → approve → lock workflow.
+type TimesheetUsecase struct {
+	timesheets repository.TimesheetRepository
+	logs       repository.CaregiverLogRepository
+	schedules  repository.ScheduleRepository
+	zones      *TimezoneResolver
+	policy     TimesheetPolicy
+	// mileage is set when approved mileage claims are included in exports.
+	mileage repository.MileageRepository
+	now     func() time.Time
+}
+
+// NewTimesheetUsecase constructs a TimesheetUsecase. Unset policy fields default
+// to weekly periods starting on Mondays and the split overnight rule.
+func NewTimesheetUsecase(
+	timesheets repository.TimesheetRepository,
+	logs repository.CaregiverLogRepository,
+	schedules repository.ScheduleRepository,
+	zones *TimezoneResolver,
+	policy TimesheetPolicy,
+) *TimesheetUsecase {
+	if !policy.Period.Valid() {
+		policy.Period = domain.TimesheetPeriodWeekly
+	}
+	if policy.Anchor.IsZero() {
+		policy.Anchor = time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // a Monday
+	}
+	if !policy.Overnight.Valid() {
+		policy.Overnight = domain.OvernightRuleSplit
+	}
+	return &TimesheetUsecase{
+		timesheets: timesheets,
+		logs:       logs,
+		schedules:  schedules,
+		zones:      zones,
+		policy:     policy,
+		now:        time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *TimesheetUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// WithMileage includes approved mileage claims in timesheet exports.
+func (uc *TimesheetUsecase) WithMileage(mileage repository.MileageRepository) {
+	uc.mileage = mileage
+}
+
+// Current returns the caregiver's timesheet for the period containing date
+// (today when nil). Stored timesheets are returned as submitted; otherwise a
+// draft is computed from the attendance recorded so far.
+func (uc *TimesheetUsecase) Current(ctx context.Context, caregiverID string, date *time.Time) (domain.Timesheet, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	day := uc.now().In(loc)
+	if date != nil {
+		day = *date
+	}
+	start, end := uc.periodContaining(day, loc)
+
+	stored, err := uc.timesheets.FindByPeriod(ctx, caregiverID, start)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.Timesheet{}, err
+	}
+	if err == nil && !stored.Status.Editable() {
+		return stored, nil
+	}
+
+	draft, _, err := uc.build(ctx, caregiverID, start, end)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	if stored.ID != "" {
+		// A rejected timesheet keeps its id and review feedback until resubmitted.
+		draft.ID = stored.ID
+		draft.Status = stored.Status
+		draft.ReviewedBy, draft.ReviewedAt, draft.ReviewComment = stored.ReviewedBy, stored.ReviewedAt, stored.ReviewComment
+	}
+	return draft, nil
+}
+
+// Submit snapshots the period containing date (the last period that has
+// ended when nil) and submits it for approval. The period must have ended, so
+// no attendance or visit can still fall into it once it is locked, and no
+// attendance session in it may still be open.
+func (uc *TimesheetUsecase) Submit(ctx context.Context, caregiverID string, date *time.Time) (domain.Timesheet, error) {
+	loc, err := uc.zones.Location(ctx, caregiverID)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	now := uc.now()
+	var start, end time.Time
+	if date != nil {
+		start, end = uc.periodContaining(*date, loc)
+	} else {
+		current, _ := uc.periodContaining(now.In(loc), loc)
+		start, end = uc.periodContaining(current.AddDate(0, 0, -1), loc)
+	}
+	if end.After(now) {
+		return domain.Timesheet{}, domain.ErrValidationFailure
+	}
+
+	timesheet, open, err := uc.build(ctx, caregiverID, start, end)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	if open {
+		return domain.Timesheet{}, domain.ErrValidationFailure
+	}
+	timesheet.SubmittedAt = &now
+
+	id, err := uc.timesheets.SaveSubmission(ctx, timesheet)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	return uc.timesheets.GetTimesheet(ctx, id)
+}
+
+// GetTimesheet returns a stored timesheet to its caregiver or a reviewer.
+func (uc *TimesheetUsecase) GetTimesheet(ctx context.Context, timesheetID, requesterID string, reviewer bool) (domain.Timesheet, error) {
+	timesheet, err := uc.timesheets.GetTimesheet(ctx, timesheetID)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	if !reviewer && timesheet.CaregiverID != requesterID {
+		return domain.Timesheet{}, domain.ErrNotFound
+	}
+	return timesheet, nil
+}
+
+// ListTimesheets returns the caregiver's stored timesheets, newest first.
+func (uc *TimesheetUsecase) ListTimesheets(ctx context.Context, caregiverID string) ([]domain.Timesheet, error) {
+	return uc.timesheets.ListByCaregiver(ctx, caregiverID)
+}
+
+// ListForReview returns timesheets waiting to be approved or locked.
+func (uc *TimesheetUsecase) ListForReview(ctx context.Context) ([]domain.Timesheet, error) {
+	return uc.timesheets.ListByStatus(ctx, []domain.TimesheetStatus{domain.TimesheetStatusSubmitted, domain.TimesheetStatusApproved})
+}
+
+// Approve accepts a submitted timesheet. Reviewers cannot approve their own.
+func (uc *TimesheetUsecase) Approve(ctx context.Context, timesheetID, reviewerID, comment string) (domain.Timesheet, error) {
+	if err := uc.reviewable(ctx, timesheetID, reviewerID); err != nil {
+		return domain.Timesheet{}, err
+	}
+	if err := uc.timesheets.Approve(ctx, timesheetID, reviewerID, optionalComment(comment), uc.now()); err != nil {
+		return domain.Timesheet{}, err
+	}
+	return uc.timesheets.GetTimesheet(ctx, timesheetID)
+}
+
+// Reject returns a submitted timesheet to its caregiver. A comment is required.
+func (uc *TimesheetUsecase) Reject(ctx context.Context, timesheetID, reviewerID, comment string) (domain.Timesheet, error) {
+	reason := optionalComment(comment)
+	if reason == nil {
+		return domain.Timesheet{}, domain.ErrValidationFailure
+	}
+	if err := uc.reviewable(ctx, timesheetID, reviewerID); err != nil {
+		return domain.Timesheet{}, err
+	}
+	if err := uc.timesheets.Reject(ctx, timesheetID, reviewerID, reason, uc.now()); err != nil {
+		return domain.Timesheet{}, err
+	}
+	return uc.timesheets.GetTimesheet(ctx, timesheetID)
+}
+
+// Lock finalises an approved timesheet; after this it and the attendance and
+// visit times in its period cannot change.
+func (uc *TimesheetUsecase) Lock(ctx context.Context, timesheetID, actorID string) (domain.Timesheet, error) {
+	timesheet, err := uc.timesheets.GetTimesheet(ctx, timesheetID)
+	if err != nil {
+		return domain.Timesheet{}, err
+	}
+	if timesheet.Status != domain.TimesheetStatusApproved {
+		return domain.Timesheet{}, domain.ErrInvalidStatusTransition
+	}
+	if err := uc.timesheets.Lock(ctx, timesheetID, actorID, uc.now()); err != nil {
+		return domain.Timesheet{}, err
+	}
+	return uc.timesheets.GetTimesheet(ctx, timesheetID)
+}
+
+// Export renders a stored timesheet as "csv" or payroll "json", together with
+// the mileage approved for its days when WithMileage is set.
+func (uc *TimesheetUsecase) Export(ctx context.Context, timesheetID, requesterID string, reviewer bool, format string) (TimesheetExport, error) {
+	timesheet, err := uc.GetTimesheet(ctx, timesheetID, requesterID, reviewer)
+	if err != nil {
+		return TimesheetExport{}, err
+	}
+	loc, err := uc.zones.Location(ctx, timesheet.CaregiverID)
+	if err != nil {
+		return TimesheetExport{}, err
+	}
+	var mileage []domain.MileageClaim
+	if uc.mileage != nil {
+		mileage, err = uc.mileage.ListApproved(ctx, timesheet.CaregiverID, timesheet.PeriodStart.In(loc), timesheet.PeriodEnd.In(loc))
+		if err != nil {
+			return TimesheetExport{}, err
+		}
+	}
+
+	export := TimesheetExport{
+		Filename: fmt.Sprintf("timesheet-%s-%s.%s", timesheet.CaregiverID, timesheet.PeriodStart.In(loc).Format("2006-01-02"), strings.ToLower(format)),
+	}
+	switch strings.ToLower(format) {
+	case "csv":
+		export.ContentType = "text/csv"
+		export.Payload, err = encodeTimesheetCSV(timesheet, mileage, loc)
+	case "json":
+		export.ContentType = "application/json"
+		export.Payload, err = encodeTimesheetPayrollJSON(timesheet, mileage, loc)
+	default:
+		return TimesheetExport{}, domain.ErrValidationFailure
+	}
+	if err != nil {
+		return TimesheetExport{}, err
+	}
+	return export, nil
+}
+
+// periodContaining returns the [start, end) bounds of the pay period that
+// contains the calendar date of day, in loc.
+func (uc *TimesheetUsecase) periodContaining(day time.Time, loc *time.Location) (time.Time, time.Time) {
+	length := uc.policy.Period.Days()
+	// Count whole calendar days in UTC so DST changes do not skew the offset.
+	target := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	anchor := time.Date(uc.policy.Anchor.Year(), uc.policy.Anchor.Month(), uc.policy.Anchor.Day(), 0, 0, 0, 0, time.UTC)
+	offset := int(target.Sub(anchor).Hours()/24) % length
+	if offset < 0 {
+		offset += length
+	}
+	start := domain.CalendarDay(day, loc).AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, length)
+}
+
+// build computes the timesheet for [start, end) and reports whether any
+// attendance session in it is still open.
+func (uc *TimesheetUsecase) build(ctx context.Context, caregiverID string, start, end time.Time) (domain.Timesheet, bool, error) {
+	logs, err := uc.logs.GetLogsBetween(ctx, caregiverID, start.Add(-overnightLookback), end)
+	if err != nil {
+		return domain.Timesheet{}, false, err
+	}
+	visits, err := uc.schedules.ListVisitIntervals(ctx, caregiverID, start.Add(-overnightLookback), end)
+	if err != nil {
+		return domain.Timesheet{}, false, err
+	}
+
+	now := uc.now()
+	sessions := domain.PairSessions(logs)
+	open := false
+	for _, session := range sessions {
+		if session.Open() && session.ClockIn.Timestamp.Before(end) {
+			open = true
+		}
+	}
+
+	timesheet := domain.Timesheet{
+		CaregiverID: caregiverID,
+		Period:      uc.policy.Period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Status:      domain.TimesheetStatusDraft,
+	}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		timesheet.Days = append(timesheet.Days, uc.buildDay(day, day.AddDate(0, 0, 1), sessions, visits, now))
+	}
+	uc.policy.Overtime.Apply(timesheet.Days)
+	timesheet.Total()
+	return timesheet, open, nil
+}
+
+func (uc *TimesheetUsecase) buildDay(from, to time.Time, sessions []domain.AttendanceSession, visits []domain.VisitInterval, now time.Time) domain.TimesheetDay {
+	var worked, breaks, onVisits time.Duration
+	for _, session := range sessions {
+		if uc.policy.Overnight == domain.OvernightRuleSplit {
+			worked += session.WorkedWithin(from, to, now)
+			breaks += session.BreakWithin(from, to, now)
+			onVisits += visitTimeWithin([]domain.AttendanceSession{session}, visits, from, to, now)
+			continue
+		}
+		if in := session.ClockIn.Timestamp; in.Before(from) || !in.Before(to) {
+			continue
+		}
+		sessionStart, sessionEnd := session.ClockIn.Timestamp, session.End(now)
+		worked += session.WorkedWithin(sessionStart, sessionEnd, now)
+		breaks += session.BreakWithin(sessionStart, sessionEnd, now)
+		onVisits += visitTimeWithin([]domain.AttendanceSession{session}, visits, sessionStart, sessionEnd, now)
+	}
+
+	day := domain.TimesheetDay{
+		Date:          from,
+		WorkedMinutes: int(worked / time.Minute),
+		BreakMinutes:  int(breaks / time.Minute),
+		VisitMinutes:  int(onVisits / time.Minute),
+	}
+	for _, visit := range visits {
+		if visit.End != nil && !visit.Start.Before(from) && visit.Start.Before(to) {
+			day.CompletedVisits++
+		}
+	}
+	return day
+}
+
+func (uc *TimesheetUsecase) reviewable(ctx context.Context, timesheetID, reviewerID string) error {
+	timesheet, err := uc.timesheets.GetTimesheet(ctx, timesheetID)
+	if err != nil {
+		return err
+	}
+	if timesheet.Status != domain.TimesheetStatusSubmitted {
+		return domain.ErrInvalidStatusTransition
+	}
+	if timesheet.CaregiverID == reviewerID {
+		return domain.ErrForbidden
+	}
+	return nil
+}
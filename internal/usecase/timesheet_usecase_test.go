@@ -0,0 +1,412 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.TimesheetRepository = (*timesheetRepoStub)(nil)
+
+type timesheetRepoStub struct {
+	timesheets map[string]domain.Timesheet
+}
+
+func (r *timesheetRepoStub) SaveSubmission(ctx context.Context, timesheet domain.Timesheet) (string, error) {
+	id := fmt.Sprintf("timesheet-%d", len(r.timesheets)+1)
+	for existingID, existing := range r.timesheets {
+		if existing.CaregiverID == timesheet.CaregiverID && existing.PeriodStart.Equal(timesheet.PeriodStart) {
+			if existing.Status != domain.TimesheetStatusRejected {
+				return "", domain.ErrConflict
+			}
+			id = existingID
+		}
+	}
+	timesheet.ID = id
+	timesheet.Status = domain.TimesheetStatusSubmitted
+	timesheet.ReviewedBy, timesheet.ReviewedAt, timesheet.ReviewComment = nil, nil, nil
+	r.timesheets[id] = timesheet
+	return id, nil
+}
+
+func (r *timesheetRepoStub) GetTimesheet(ctx context.Context, timesheetID string) (domain.Timesheet, error) {
+	timesheet, ok := r.timesheets[timesheetID]
+	if !ok {
+		return domain.Timesheet{}, domain.ErrNotFound
+	}
+	return timesheet, nil
+}
+
+func (r *timesheetRepoStub) FindByPeriod(ctx context.Context, caregiverID string, periodStart time.Time) (domain.Timesheet, error) {
+	for _, timesheet := range r.timesheets {
+		if timesheet.CaregiverID == caregiverID && timesheet.PeriodStart.Equal(periodStart) {
+			return timesheet, nil
+		}
+	}
+	return domain.Timesheet{}, domain.ErrNotFound
+}
+
+func (r *timesheetRepoStub) ListByCaregiver(ctx context.Context, caregiverID string) ([]domain.Timesheet, error) {
+	var result []domain.Timesheet
+	for _, timesheet := range r.timesheets {
+		if timesheet.CaregiverID == caregiverID {
+			result = append(result, timesheet)
+		}
+	}
+	return result, nil
+}
+
+func (r *timesheetRepoStub) ListByStatus(ctx context.Context, statuses []domain.TimesheetStatus) ([]domain.Timesheet, error) {
+	var result []domain.Timesheet
+	for _, timesheet := range r.timesheets {
+		for _, status := range statuses {
+			if timesheet.Status == status {
+				result = append(result, timesheet)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *timesheetRepoStub) Approve(ctx context.Context, timesheetID, reviewerID string, comment *string, at time.Time) error {
+	return r.move(timesheetID, domain.TimesheetStatusSubmitted, domain.TimesheetStatusApproved, func(t *domain.Timesheet) {
+		t.ReviewedBy, t.ReviewedAt, t.ReviewComment = &reviewerID, &at, comment
+	})
+}
+
+func (r *timesheetRepoStub) Reject(ctx context.Context, timesheetID, reviewerID string, comment *string, at time.Time) error {
+	return r.move(timesheetID, domain.TimesheetStatusSubmitted, domain.TimesheetStatusRejected, func(t *domain.Timesheet) {
+		t.ReviewedBy, t.ReviewedAt, t.ReviewComment = &reviewerID, &at, comment
+	})
+}
+
+func (r *timesheetRepoStub) Lock(ctx context.Context, timesheetID, actorID string, at time.Time) error {
+	return r.move(timesheetID, domain.TimesheetStatusApproved, domain.TimesheetStatusLocked, func(t *domain.Timesheet) {
+		t.LockedBy, t.LockedAt = &actorID, &at
+	})
+}
+
+func (r *timesheetRepoStub) move(timesheetID string, from, to domain.TimesheetStatus, apply func(*domain.Timesheet)) error {
+	timesheet, ok := r.timesheets[timesheetID]
+	if !ok || timesheet.Status != from {
+		return domain.ErrConflict
+	}
+	timesheet.Status = to
+	apply(&timesheet)
+	r.timesheets[timesheetID] = timesheet
+	return nil
+}
+
+func (r *timesheetRepoStub) IsLocked(ctx context.Context, caregiverID string, at time.Time) (bool, error) {
+	for _, timesheet := range r.timesheets {
+		if timesheet.CaregiverID == caregiverID && timesheet.Status == domain.TimesheetStatusLocked &&
+			!at.Before(timesheet.PeriodStart) && at.Before(timesheet.PeriodEnd) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// timesheetWeek is the Monday of the week used by the timesheet fixtures.
+var timesheetWeek = time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+
+func newTimesheetFixture(logs []domain.CaregiverLog, visits []domain.VisitInterval) (*TimesheetUsecase, *timesheetRepoStub) {
+	repo := &timesheetRepoStub{timesheets: map[string]domain.Timesheet{}}
+	uc := NewTimesheetUsecase(repo, &caregiverLogRepoStub{logs: logs}, &scheduleRepoStub{visits: visits}, nil, TimesheetPolicy{
+		Period:   domain.TimesheetPeriodWeekly,
+		Anchor:   time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		Overtime: domain.OvertimeRules{Daily: 8 * time.Hour, Weekly: 20 * time.Hour},
+	})
+	uc.WithNow(func() time.Time { return timesheetWeek.AddDate(0, 0, 8).Add(12 * time.Hour) })
+	return uc, repo
+}
+
+func timesheetWeekLogs() []domain.CaregiverLog {
+	meal := domain.BreakTypeMeal
+	mon, tue, wed := timesheetWeek, timesheetWeek.AddDate(0, 0, 1), timesheetWeek.AddDate(0, 0, 2)
+	return []domain.CaregiverLog{
+		{ID: "log-1", CaregiverID: "cg-1", LogType: domain.LogTypeClockIn, Timestamp: mon.Add(8 * time.Hour)},
+		{ID: "log-2", CaregiverID: "cg-1", LogType: domain.LogTypeBreakStart, BreakType: &meal, Timestamp: mon.Add(12 * time.Hour)},
+		{ID: "log-3", CaregiverID: "cg-1", LogType: domain.LogTypeBreakEnd, Timestamp: mon.Add(12*time.Hour + 30*time.Minute)},
+		{ID: "log-4", CaregiverID: "cg-1", LogType: domain.LogTypeClockOut, Timestamp: mon.Add(18 * time.Hour)},
+		{ID: "log-5", CaregiverID: "cg-1", LogType: domain.LogTypeClockIn, Timestamp: tue.Add(8 * time.Hour)},
+		{ID: "log-6", CaregiverID: "cg-1", LogType: domain.LogTypeClockOut, Timestamp: tue.Add(17 * time.Hour)},
+		// Overnight: two hours on Wednesday, six on Thursday.
+		{ID: "log-7", CaregiverID: "cg-1", LogType: domain.LogTypeClockIn, Timestamp: wed.Add(22 * time.Hour)},
+		{ID: "log-8", CaregiverID: "cg-1", LogType: domain.LogTypeClockOut, Timestamp: wed.Add(30 * time.Hour)},
+	}
+}
+
+func TestOvertimeRulesApply(t *testing.T) {
+	days := []domain.TimesheetDay{{WorkedMinutes: 600}, {WorkedMinutes: 480}, {WorkedMinutes: 300}}
+	domain.OvertimeRules{Daily: 8 * time.Hour, Weekly: 16 * time.Hour}.Apply(days)
+
+	want := [][2]int{{480, 120}, {480, 0}, {0, 300}}
+	for i, day := range days {
+		if day.RegularMinutes != want[i][0] || day.OvertimeMinutes != want[i][1] {
+			t.Fatalf("day %d: expected %v, got regular=%d overtime=%d", i, want[i], day.RegularMinutes, day.OvertimeMinutes)
+		}
+	}
+
+	days = []domain.TimesheetDay{{WorkedMinutes: 900}}
+	domain.OvertimeRules{}.Apply(days)
+	if days[0].RegularMinutes != 900 || days[0].OvertimeMinutes != 0 {
+		t.Fatalf("expected disabled rules to leave all time regular: %+v", days[0])
+	}
+}
+
+func TestTimesheetUsecasePeriodFollowsAnchor(t *testing.T) {
+	uc := NewTimesheetUsecase(nil, nil, nil, nil, TimesheetPolicy{
+		Period: domain.TimesheetPeriodBiweekly,
+		Anchor: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+	})
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	cases := []struct {
+		date      time.Time
+		wantStart time.Time
+	}{
+		{time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 6, 0, 0, 0, 0, chicago)},
+		{time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 6, 0, 0, 0, 0, chicago)},
+		{time.Date(2025, 1, 21, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 20, 0, 0, 0, 0, chicago)},
+		{time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 23, 0, 0, 0, 0, chicago)},
+		// Spans the start of daylight saving time on 2025-03-09.
+		{time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 3, 0, 0, 0, 0, chicago)},
+	}
+	for _, tc := range cases {
+		start, end := uc.periodContaining(tc.date, chicago)
+		if !start.Equal(tc.wantStart) {
+			t.Fatalf("%s: expected period start %s, got %s", tc.date.Format("2006-01-02"), tc.wantStart, start)
+		}
+		if wantEnd := tc.wantStart.AddDate(0, 0, 14); !end.Equal(wantEnd) {
+			t.Fatalf("%s: expected period end %s, got %s", tc.date.Format("2006-01-02"), wantEnd, end)
+		}
+	}
+}
+
+func TestTimesheetUsecaseBuildsDaysWithOvertime(t *testing.T) {
+	visitEnd := timesheetWeek.Add(11 * time.Hour)
+	uc, _ := newTimesheetFixture(timesheetWeekLogs(), []domain.VisitInterval{
+		{ScheduleID: "sched-1", Start: timesheetWeek.Add(9 * time.Hour), End: &visitEnd},
+	})
+
+	date := timesheetWeek.AddDate(0, 0, 3)
+	timesheet, err := uc.Current(context.Background(), "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timesheet.Status != domain.TimesheetStatusDraft || !timesheet.PeriodStart.Equal(timesheetWeek) || len(timesheet.Days) != 7 {
+		t.Fatalf("unexpected timesheet: %+v", timesheet)
+	}
+
+	mon, wed, thu := timesheet.Days[0], timesheet.Days[2], timesheet.Days[3]
+	if mon.WorkedMinutes != 570 || mon.BreakMinutes != 30 || mon.VisitMinutes != 120 || mon.CompletedVisits != 1 {
+		t.Fatalf("unexpected monday: %+v", mon)
+	}
+	if wed.WorkedMinutes != 120 || thu.WorkedMinutes != 360 {
+		t.Fatalf("expected overnight shift split at midnight: wed=%d thu=%d", wed.WorkedMinutes, thu.WorkedMinutes)
+	}
+	// Daily overtime on Monday and Tuesday; the 20h weekly limit is reached on Thursday.
+	if thu.RegularMinutes != 120 || thu.OvertimeMinutes != 240 {
+		t.Fatalf("unexpected thursday overtime: %+v", thu)
+	}
+	if timesheet.WorkedMinutes != 1590 || timesheet.RegularMinutes != 1200 || timesheet.OvertimeMinutes != 390 {
+		t.Fatalf("unexpected totals: worked=%d regular=%d overtime=%d", timesheet.WorkedMinutes, timesheet.RegularMinutes, timesheet.OvertimeMinutes)
+	}
+
+	uc.policy.Overnight = domain.OvernightRuleStartDay
+	timesheet, err = uc.Current(context.Background(), "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timesheet.Days[2].WorkedMinutes != 480 || timesheet.Days[3].WorkedMinutes != 0 {
+		t.Fatalf("expected overnight shift on its start day: wed=%d thu=%d", timesheet.Days[2].WorkedMinutes, timesheet.Days[3].WorkedMinutes)
+	}
+}
+
+func TestTimesheetUsecaseSubmitApproveLock(t *testing.T) {
+	uc, repo := newTimesheetFixture(timesheetWeekLogs(), nil)
+	ctx := context.Background()
+	date := timesheetWeek.AddDate(0, 0, 1)
+
+	submitted, err := uc.Submit(ctx, "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if submitted.Status != domain.TimesheetStatusSubmitted || submitted.SubmittedAt == nil || submitted.WorkedMinutes != 1590 {
+		t.Fatalf("unexpected submission: %+v", submitted)
+	}
+	if _, err := uc.Submit(ctx, "cg-1", &date); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict on resubmission, got %v", err)
+	}
+
+	if _, err := uc.Approve(ctx, submitted.ID, "cg-1", ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected self-approval to be forbidden, got %v", err)
+	}
+	if _, err := uc.Lock(ctx, submitted.ID, "coordinator-1"); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Fatalf("expected lock before approval to fail, got %v", err)
+	}
+	approved, err := uc.Approve(ctx, submitted.ID, "coordinator-1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approved.Status != domain.TimesheetStatusApproved || approved.ReviewedBy == nil || *approved.ReviewedBy != "coordinator-1" {
+		t.Fatalf("unexpected approval: %+v", approved)
+	}
+
+	locked, err := uc.Lock(ctx, submitted.ID, "coordinator-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if locked.Status != domain.TimesheetStatusLocked || locked.LockedAt == nil {
+		t.Fatalf("unexpected lock: %+v", locked)
+	}
+	if isLocked, _ := repo.IsLocked(ctx, "cg-1", timesheetWeek.Add(time.Hour)); !isLocked {
+		t.Fatal("expected period to be locked")
+	}
+
+	current, err := uc.Current(ctx, "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current.ID != submitted.ID || current.Status != domain.TimesheetStatusLocked {
+		t.Fatalf("expected the locked timesheet, got %+v", current)
+	}
+	if _, err := uc.GetTimesheet(ctx, submitted.ID, "cg-2", false); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected other caregivers not to see the timesheet, got %v", err)
+	}
+}
+
+func TestTimesheetUsecaseRejectAndResubmit(t *testing.T) {
+	uc, _ := newTimesheetFixture(timesheetWeekLogs(), nil)
+	ctx := context.Background()
+	date := timesheetWeek
+
+	submitted, err := uc.Submit(ctx, "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := uc.Reject(ctx, submitted.ID, "coordinator-1", "  "); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected comment to be required, got %v", err)
+	}
+	if _, err := uc.Reject(ctx, submitted.ID, "coordinator-1", "Tuesday is missing a visit"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	draft, err := uc.Current(ctx, "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draft.ID != submitted.ID || draft.Status != domain.TimesheetStatusRejected || draft.ReviewComment == nil {
+		t.Fatalf("expected rejected draft with feedback, got %+v", draft)
+	}
+
+	resubmitted, err := uc.Submit(ctx, "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resubmitted.ID != submitted.ID || resubmitted.Status != domain.TimesheetStatusSubmitted {
+		t.Fatalf("unexpected resubmission: %+v", resubmitted)
+	}
+}
+
+func TestTimesheetUsecaseSubmitValidation(t *testing.T) {
+	logs := append(timesheetWeekLogs(), domain.CaregiverLog{
+		ID: "log-9", CaregiverID: "cg-1", LogType: domain.LogTypeClockIn, Timestamp: timesheetWeek.AddDate(0, 0, 5).Add(8 * time.Hour),
+	})
+	uc, _ := newTimesheetFixture(logs, nil)
+
+	date := timesheetWeek
+	if _, err := uc.Submit(context.Background(), "cg-1", &date); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected open shift to block submission, got %v", err)
+	}
+	future := timesheetWeek.AddDate(0, 0, 21)
+	if _, err := uc.Submit(context.Background(), "cg-1", &future); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected future period to be rejected, got %v", err)
+	}
+	current := timesheetWeek.AddDate(0, 0, 7)
+	if _, err := uc.Submit(context.Background(), "cg-1", &current); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected the period still running to be rejected, got %v", err)
+	}
+}
+
+func TestTimesheetUsecaseSubmitDefaultsToLastEndedPeriod(t *testing.T) {
+	uc, _ := newTimesheetFixture(timesheetWeekLogs(), nil)
+
+	submitted, err := uc.Submit(context.Background(), "cg-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !submitted.PeriodStart.Equal(timesheetWeek) {
+		t.Fatalf("expected the week of %v submitted, got %v", timesheetWeek, submitted.PeriodStart)
+	}
+}
+
+func TestTimesheetUsecaseExport(t *testing.T) {
+	uc, _ := newTimesheetFixture(timesheetWeekLogs(), nil)
+	uc.WithMileage(&mileageRepoStub{claims: map[string]domain.MileageClaim{
+		"claim-1": {ID: "claim-1", CaregiverID: "cg-1", ClaimDate: timesheetWeek, ClaimedKm: 12.5, Amount: 5, Status: domain.MileageClaimStatusApproved},
+		"claim-2": {ID: "claim-2", CaregiverID: "cg-1", ClaimDate: timesheetWeek.AddDate(0, 0, 1), ClaimedKm: 30, Amount: 12, Status: domain.MileageClaimStatusSubmitted},
+	}})
+	ctx := context.Background()
+	date := timesheetWeek
+	submitted, err := uc.Submit(ctx, "cg-1", &date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	csvExport, err := uc.Export(ctx, submitted.ID, "cg-1", false, "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(csvExport.Payload)), "\n")
+	if len(lines) != 8 || csvExport.Filename != "timesheet-cg-1-2025-01-13.csv" {
+		t.Fatalf("unexpected csv export %q: %d lines", csvExport.Filename, len(lines))
+	}
+	if want := submitted.ID + ",cg-1,submitted,2025-01-13,9.50,8.00,1.50,30,0,0,12.50,5.00"; lines[1] != want {
+		t.Fatalf("unexpected first row:\n got %s\nwant %s", lines[1], want)
+	}
+
+	jsonExport, err := uc.Export(ctx, submitted.ID, "coordinator-1", true, "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc struct {
+		Period   struct{ From, To string }
+		Earnings []struct {
+			Code  string
+			Hours float64
+		}
+		Reimbursements []struct {
+			Code   string
+			Amount float64
+		}
+	}
+	if err := json.Unmarshal(jsonExport.Payload, &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if doc.Period.From != "2025-01-13" || doc.Period.To != "2025-01-19" {
+		t.Fatalf("unexpected period: %+v", doc.Period)
+	}
+	if len(doc.Earnings) != 2 || doc.Earnings[0].Hours != 20 || doc.Earnings[1].Hours != 6.5 {
+		t.Fatalf("unexpected earnings: %+v", doc.Earnings)
+	}
+	// Only approved mileage is paid.
+	if len(doc.Reimbursements) != 1 || doc.Reimbursements[0].Code != "MILEAGE" || doc.Reimbursements[0].Amount != 5 {
+		t.Fatalf("unexpected reimbursements: %+v", doc.Reimbursements)
+	}
+
+	if _, err := uc.Export(ctx, submitted.ID, "cg-1", false, "xml"); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected unsupported format to fail, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// TimezoneResolver determines the zone a caregiver's calendar days are counted in.
+type TimezoneResolver struct {
+	caregivers repository.CaregiverRepository
+	fallback   *time.Location
+}
+
+// NewTimezoneResolver creates a resolver that falls back to the agency zone when
+// a caregiver has no (valid) timezone of their own.
+func NewTimezoneResolver(caregivers repository.CaregiverRepository, fallback *time.Location) *TimezoneResolver {
+	if fallback == nil {
+		fallback = time.UTC
+	}
+	return &TimezoneResolver{caregivers: caregivers, fallback: fallback}
+}
+
+// Location returns the caregiver's zone. A nil resolver resolves to UTC.
+func (r *TimezoneResolver) Location(ctx context.Context, caregiverID string) (*time.Location, error) {
+	if r == nil {
+		return time.UTC, nil
+	}
+	if r.caregivers == nil {
+		return r.fallback, nil
+	}
+
+	profile, err := r.caregivers.GetProfile(ctx, caregiverID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return r.fallback, nil
+		}
+		return nil, err
+	}
+	if profile.Timezone == "" {
+		return r.fallback, nil
+	}
+
+	loc, err := time.LoadLocation(profile.Timezone)
+	if err != nil {
+		return r.fallback, nil
+	}
+	return loc, nil
+}
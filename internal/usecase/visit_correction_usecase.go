@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+// CorrectionRequest is the input for proposing a visit correction.
+type CorrectionRequest struct {
+	ScheduleID string
+	Reason     domain.CorrectionReason
+	Comment    string
+	ClockInAt  *time.Time
+	ClockOutAt *time.Time
+}
+
+// VisitCorrectionUsecase runs the propose/approve workflow for adjusting visit clock times.
+type VisitCorrectionUsecase struct {
+	corrections repository.VisitCorrectionRepository
+	schedules   repository.ScheduleRepository
+	// timesheets is set when locked timesheet periods must reject corrections.
+	timesheets repository.TimesheetRepository
+	now        func() time.Time
+}
+
+// NewVisitCorrectionUsecase constructs a VisitCorrectionUsecase.
+func NewVisitCorrectionUsecase(corrections repository.VisitCorrectionRepository, schedules repository.ScheduleRepository) *VisitCorrectionUsecase {
+	return &VisitCorrectionUsecase{
+		corrections: corrections,
+		schedules:   schedules,
+		now:         time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *VisitCorrectionUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// WithTimesheetLocks rejects corrections touching a locked timesheet period.
+func (uc *VisitCorrectionUsecase) WithTimesheetLocks(timesheets repository.TimesheetRepository) {
+	uc.timesheets = timesheets
+}
+
+// RequestCorrection records a pending correction. Caregivers may only correct
+// their own visits; reviewers may propose corrections for any visit.
+func (uc *VisitCorrectionUsecase) RequestCorrection(ctx context.Context, requesterID string, reviewer bool, req CorrectionRequest) (domain.VisitCorrection, error) {
+	comment := strings.TrimSpace(req.Comment)
+	if !req.Reason.Valid() || (req.ClockInAt == nil && req.ClockOutAt == nil) {
+		return domain.VisitCorrection{}, domain.ErrValidationFailure
+	}
+	if req.Reason == domain.CorrectionReasonOther && comment == "" {
+		return domain.VisitCorrection{}, domain.ErrValidationFailure
+	}
+	now := uc.now()
+	if (req.ClockInAt != nil && req.ClockInAt.After(now)) || (req.ClockOutAt != nil && req.ClockOutAt.After(now)) {
+		return domain.VisitCorrection{}, domain.ErrValidationFailure
+	}
+
+	schedule, err := uc.loadSchedule(ctx, req.ScheduleID, requesterID, reviewer)
+	if err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	if schedule.Status == domain.ScheduleStatusCancelled {
+		return domain.VisitCorrection{}, domain.ErrInvalidStatusTransition
+	}
+	if _, _, err := correctedTimes(schedule, req.ClockInAt, req.ClockOutAt); err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	if err := uc.ensureUnlocked(ctx, schedule, req.ClockInAt, req.ClockOutAt); err != nil {
+		return domain.VisitCorrection{}, err
+	}
+
+	correction := domain.VisitCorrection{
+		ScheduleID:         req.ScheduleID,
+		RequestedBy:        requesterID,
+		Reason:             req.Reason,
+		ProposedClockInAt:  req.ClockInAt,
+		ProposedClockOutAt: req.ClockOutAt,
+		Status:             domain.CorrectionStatusPending,
+		CreatedAt:          now,
+	}
+	if comment != "" {
+		correction.Comment = &comment
+	}
+
+	id, err := uc.corrections.CreateCorrection(ctx, correction)
+	if err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	correction.ID = id
+	return correction, nil
+}
+
+// ListCorrections returns the correction history for a visit, including the
+// original values replaced by each approved correction.
+func (uc *VisitCorrectionUsecase) ListCorrections(ctx context.Context, scheduleID, requesterID string, reviewer bool) ([]domain.VisitCorrection, error) {
+	if _, err := uc.loadSchedule(ctx, scheduleID, requesterID, reviewer); err != nil {
+		return nil, err
+	}
+	return uc.corrections.ListBySchedule(ctx, scheduleID)
+}
+
+// ListPending returns corrections awaiting review, oldest first.
+func (uc *VisitCorrectionUsecase) ListPending(ctx context.Context) ([]domain.VisitCorrection, error) {
+	return uc.corrections.ListPending(ctx)
+}
+
+// ApproveCorrection applies a pending correction to its visit. Reviewers cannot
+// approve corrections they requested themselves.
+func (uc *VisitCorrectionUsecase) ApproveCorrection(ctx context.Context, correctionID, reviewerID, comment string) (domain.VisitCorrection, error) {
+	correction, err := uc.pendingCorrection(ctx, correctionID, reviewerID)
+	if err != nil {
+		return domain.VisitCorrection{}, err
+	}
+
+	schedule, err := uc.schedules.GetSchedule(ctx, correction.ScheduleID)
+	if err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	if schedule.Status == domain.ScheduleStatusCancelled {
+		return domain.VisitCorrection{}, domain.ErrInvalidStatusTransition
+	}
+	clockIn, clockOut, err := correctedTimes(schedule, correction.ProposedClockInAt, correction.ProposedClockOutAt)
+	if err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	if err := uc.ensureUnlocked(ctx, schedule, correction.ProposedClockInAt, correction.ProposedClockOutAt); err != nil {
+		return domain.VisitCorrection{}, err
+	}
+
+	status := schedule.Status
+	switch {
+	case clockIn != nil && clockOut != nil:
+		status = domain.ScheduleStatusCompleted
+	case clockIn != nil:
+		status = domain.ScheduleStatusInProgress
+	}
+
+	approval := domain.CorrectionApproval{
+		CorrectionID: correctionID,
+		ReviewerID:   reviewerID,
+		Comment:      optionalComment(comment),
+		ReviewedAt:   uc.now(),
+		ClockInAt:    clockIn,
+		ClockOutAt:   clockOut,
+		Status:       status,
+	}
+	if err := uc.corrections.ApproveCorrection(ctx, approval); err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	return uc.corrections.GetCorrection(ctx, correctionID)
+}
+
+// RejectCorrection closes a pending correction without touching the visit. A
+// comment explaining the rejection is required.
+func (uc *VisitCorrectionUsecase) RejectCorrection(ctx context.Context, correctionID, reviewerID, comment string) (domain.VisitCorrection, error) {
+	reason := optionalComment(comment)
+	if reason == nil {
+		return domain.VisitCorrection{}, domain.ErrValidationFailure
+	}
+	if _, err := uc.pendingCorrection(ctx, correctionID, reviewerID); err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	if err := uc.corrections.RejectCorrection(ctx, correctionID, reviewerID, reason, uc.now()); err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	return uc.corrections.GetCorrection(ctx, correctionID)
+}
+
+func (uc *VisitCorrectionUsecase) loadSchedule(ctx context.Context, scheduleID, requesterID string, reviewer bool) (domain.Schedule, error) {
+	if reviewer {
+		return uc.schedules.GetSchedule(ctx, scheduleID)
+	}
+	return uc.schedules.GetScheduleForCaregiver(ctx, scheduleID, requesterID)
+}
+
+func (uc *VisitCorrectionUsecase) pendingCorrection(ctx context.Context, correctionID, reviewerID string) (domain.VisitCorrection, error) {
+	correction, err := uc.corrections.GetCorrection(ctx, correctionID)
+	if err != nil {
+		return domain.VisitCorrection{}, err
+	}
+	if correction.Status != domain.CorrectionStatusPending {
+		return domain.VisitCorrection{}, domain.ErrInvalidStatusTransition
+	}
+	if correction.RequestedBy == reviewerID {
+		return domain.VisitCorrection{}, domain.ErrForbidden
+	}
+	return correction, nil
+}
+
+// ensureUnlocked returns domain.ErrConflict when the visit as scheduled or
+// recorded, or the proposed times, fall in one of the caregiver's locked
+// timesheet periods.
+func (uc *VisitCorrectionUsecase) ensureUnlocked(ctx context.Context, schedule domain.Schedule, proposed ...*time.Time) error {
+	if uc.timesheets == nil {
+		return nil
+	}
+	times := append([]*time.Time{&schedule.StartTime, schedule.ClockInAt, schedule.ClockOutAt}, proposed...)
+	for _, at := range times {
+		if at == nil {
+			continue
+		}
+		locked, err := uc.timesheets.IsLocked(ctx, schedule.CaregiverID, *at)
+		if err != nil {
+			return err
+		}
+		if locked {
+			return domain.ErrConflict
+		}
+	}
+	return nil
+}
+
+// correctedTimes overlays the proposed times on the visit's current ones and
+// checks the result is a coherent visit.
+func correctedTimes(schedule domain.Schedule, proposedIn, proposedOut *time.Time) (*time.Time, *time.Time, error) {
+	clockIn, clockOut := schedule.ClockInAt, schedule.ClockOutAt
+	if proposedIn != nil {
+		clockIn = proposedIn
+	}
+	if proposedOut != nil {
+		clockOut = proposedOut
+	}
+	if clockOut != nil && (clockIn == nil || !clockOut.After(*clockIn)) {
+		return nil, nil, domain.ErrValidationFailure
+	}
+	return clockIn, clockOut, nil
+}
+
+func optionalComment(comment string) *string {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil
+	}
+	return &comment
+}
@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.VisitCorrectionRepository = (*correctionRepoStub)(nil)
+
+type correctionRepoStub struct {
+	corrections map[string]domain.VisitCorrection
+	approval    *domain.CorrectionApproval
+	schedule    *scheduleRepoStub
+}
+
+func (r *correctionRepoStub) CreateCorrection(ctx context.Context, correction domain.VisitCorrection) (string, error) {
+	for _, existing := range r.corrections {
+		if existing.ScheduleID == correction.ScheduleID && existing.Status == domain.CorrectionStatusPending {
+			return "", domain.ErrConflict
+		}
+	}
+	correction.ID = fmt.Sprintf("correction-%d", len(r.corrections)+1)
+	r.corrections[correction.ID] = correction
+	return correction.ID, nil
+}
+
+func (r *correctionRepoStub) GetCorrection(ctx context.Context, correctionID string) (domain.VisitCorrection, error) {
+	correction, ok := r.corrections[correctionID]
+	if !ok {
+		return domain.VisitCorrection{}, domain.ErrNotFound
+	}
+	return correction, nil
+}
+
+func (r *correctionRepoStub) ListBySchedule(ctx context.Context, scheduleID string) ([]domain.VisitCorrection, error) {
+	var result []domain.VisitCorrection
+	for _, correction := range r.corrections {
+		if correction.ScheduleID == scheduleID {
+			result = append(result, correction)
+		}
+	}
+	return result, nil
+}
+
+func (r *correctionRepoStub) ListPending(ctx context.Context) ([]domain.VisitCorrection, error) {
+	return nil, nil
+}
+
+func (r *correctionRepoStub) ApproveCorrection(ctx context.Context, approval domain.CorrectionApproval) error {
+	correction := r.corrections[approval.CorrectionID]
+	sched := &r.schedule.schedule
+	originalStatus := sched.Status
+	correction.Status = domain.CorrectionStatusApproved
+	correction.OriginalClockInAt = sched.ClockInAt
+	correction.OriginalClockOutAt = sched.ClockOutAt
+	correction.OriginalStatus = &originalStatus
+	correction.ReviewedBy = &approval.ReviewerID
+	r.corrections[approval.CorrectionID] = correction
+
+	sched.ClockInAt = approval.ClockInAt
+	sched.ClockOutAt = approval.ClockOutAt
+	sched.Status = approval.Status
+	sched.ManuallyEdited = true
+	r.approval = &approval
+	return nil
+}
+
+func (r *correctionRepoStub) RejectCorrection(ctx context.Context, correctionID, reviewerID string, comment *string, reviewedAt time.Time) error {
+	correction := r.corrections[correctionID]
+	correction.Status = domain.CorrectionStatusRejected
+	correction.ReviewComment = comment
+	r.corrections[correctionID] = correction
+	return nil
+}
+
+func newCorrectionFixture(now time.Time) (*VisitCorrectionUsecase, *correctionRepoStub, *scheduleRepoStub) {
+	clockIn := now.Add(-26 * time.Hour)
+	schedules := &scheduleRepoStub{schedule: domain.Schedule{
+		ID:          "sched-1",
+		CaregiverID: "cg-1",
+		Status:      domain.ScheduleStatusInProgress,
+		ClockInAt:   &clockIn,
+	}}
+	repo := &correctionRepoStub{corrections: map[string]domain.VisitCorrection{}, schedule: schedules}
+	uc := NewVisitCorrectionUsecase(repo, schedules)
+	uc.WithNow(func() time.Time { return now })
+	return uc, repo, schedules
+}
+
+func TestVisitCorrectionForgotClockOutApproved(t *testing.T) {
+	now := time.Date(2025, 1, 16, 12, 0, 0, 0, time.UTC)
+	uc, repo, schedules := newCorrectionFixture(now)
+	originalIn := *schedules.schedule.ClockInAt
+	clockOut := originalIn.Add(2 * time.Hour)
+
+	correction, err := uc.RequestCorrection(context.Background(), "cg-1", false, CorrectionRequest{
+		ScheduleID: "sched-1",
+		Reason:     domain.CorrectionReasonForgotClockOut,
+		ClockOutAt: &clockOut,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if correction.Status != domain.CorrectionStatusPending {
+		t.Fatalf("expected pending correction, got %s", correction.Status)
+	}
+
+	if _, err := uc.RequestCorrection(context.Background(), "cg-1", false, CorrectionRequest{
+		ScheduleID: "sched-1",
+		Reason:     domain.CorrectionReasonForgotClockOut,
+		ClockOutAt: &clockOut,
+	}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict for second pending correction, got %v", err)
+	}
+
+	if _, err := uc.ApproveCorrection(context.Background(), correction.ID, "cg-1", ""); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected requester to be unable to approve, got %v", err)
+	}
+
+	approved, err := uc.ApproveCorrection(context.Background(), correction.ID, "supervisor-1", "confirmed with client")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approved.Status != domain.CorrectionStatusApproved {
+		t.Fatalf("expected approved correction, got %s", approved.Status)
+	}
+	if approved.OriginalClockOutAt != nil || approved.OriginalStatus == nil || *approved.OriginalStatus != domain.ScheduleStatusInProgress {
+		t.Fatalf("expected original values captured, got %+v", approved)
+	}
+	if repo.approval.Status != domain.ScheduleStatusCompleted || !repo.approval.ClockInAt.Equal(originalIn) {
+		t.Fatalf("expected visit completed with original clock-in kept, got %+v", repo.approval)
+	}
+	if !schedules.schedule.ManuallyEdited || !schedules.schedule.ClockOutAt.Equal(clockOut) {
+		t.Fatalf("expected visit flagged and clock-out set, got %+v", schedules.schedule)
+	}
+
+	if _, err := uc.ApproveCorrection(context.Background(), correction.ID, "supervisor-1", ""); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Fatalf("expected re-approval to fail, got %v", err)
+	}
+}
+
+func TestVisitCorrectionRequestValidation(t *testing.T) {
+	now := time.Date(2025, 1, 16, 12, 0, 0, 0, time.UTC)
+	uc, _, schedules := newCorrectionFixture(now)
+	beforeClockIn := schedules.schedule.ClockInAt.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	cases := []struct {
+		name string
+		req  CorrectionRequest
+		user string
+		want error
+	}{
+		{"unknown reason", CorrectionRequest{ScheduleID: "sched-1", Reason: "lost_phone", ClockOutAt: &now}, "cg-1", domain.ErrValidationFailure},
+		{"no times", CorrectionRequest{ScheduleID: "sched-1", Reason: domain.CorrectionReasonWrongTime}, "cg-1", domain.ErrValidationFailure},
+		{"other without comment", CorrectionRequest{ScheduleID: "sched-1", Reason: domain.CorrectionReasonOther, ClockOutAt: &now}, "cg-1", domain.ErrValidationFailure},
+		{"future time", CorrectionRequest{ScheduleID: "sched-1", Reason: domain.CorrectionReasonForgotClockOut, ClockOutAt: &future}, "cg-1", domain.ErrValidationFailure},
+		{"clock-out before clock-in", CorrectionRequest{ScheduleID: "sched-1", Reason: domain.CorrectionReasonForgotClockOut, ClockOutAt: &beforeClockIn}, "cg-1", domain.ErrValidationFailure},
+		{"someone else's visit", CorrectionRequest{ScheduleID: "sched-1", Reason: domain.CorrectionReasonForgotClockOut, ClockOutAt: &now}, "cg-2", domain.ErrNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := uc.RequestCorrection(context.Background(), tc.user, false, tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+
+	if _, err := uc.RequestCorrection(context.Background(), "coordinator-1", true, CorrectionRequest{
+		ScheduleID: "sched-1",
+		Reason:     domain.CorrectionReasonForgotClockOut,
+		ClockOutAt: &now,
+	}); err != nil {
+		t.Fatalf("expected reviewer to correct any visit, got %v", err)
+	}
+}
+
+func TestVisitCorrectionRejectRequiresComment(t *testing.T) {
+	now := time.Date(2025, 1, 16, 12, 0, 0, 0, time.UTC)
+	uc, _, schedules := newCorrectionFixture(now)
+
+	correction, err := uc.RequestCorrection(context.Background(), "cg-1", false, CorrectionRequest{
+		ScheduleID: "sched-1",
+		Reason:     domain.CorrectionReasonForgotClockOut,
+		ClockOutAt: &now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := uc.RejectCorrection(context.Background(), correction.ID, "supervisor-1", " "); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure without comment, got %v", err)
+	}
+	rejected, err := uc.RejectCorrection(context.Background(), correction.ID, "supervisor-1", "client says visit ended earlier")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rejected.Status != domain.CorrectionStatusRejected || schedules.schedule.ManuallyEdited {
+		t.Fatalf("expected rejection to leave visit untouched, got %+v / %+v", rejected, schedules.schedule)
+	}
+}
+
+func TestVisitCorrectionRejectsLockedTimesheetPeriod(t *testing.T) {
+	now := time.Date(2025, 1, 16, 12, 0, 0, 0, time.UTC)
+	uc, repo, schedules := newCorrectionFixture(now)
+	timesheets := &timesheetRepoStub{timesheets: map[string]domain.Timesheet{}}
+	uc.WithTimesheetLocks(timesheets)
+	clockOut := schedules.schedule.ClockInAt.Add(2 * time.Hour)
+
+	correction, err := uc.RequestCorrection(context.Background(), "cg-1", false, CorrectionRequest{
+		ScheduleID: "sched-1",
+		Reason:     domain.CorrectionReasonForgotClockOut,
+		ClockOutAt: &clockOut,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The period is locked while the correction waits for review.
+	timesheets.timesheets["timesheet-1"] = domain.Timesheet{
+		ID:          "timesheet-1",
+		CaregiverID: "cg-1",
+		PeriodStart: time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		Status:      domain.TimesheetStatusLocked,
+	}
+	if _, err := uc.ApproveCorrection(context.Background(), correction.ID, "coordinator-1", ""); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict approving into a locked period, got %v", err)
+	}
+	if repo.approval != nil {
+		t.Fatal("expected the visit to be left untouched")
+	}
+
+	delete(repo.corrections, correction.ID)
+	if _, err := uc.RequestCorrection(context.Background(), "cg-1", false, CorrectionRequest{
+		ScheduleID: "sched-1",
+		Reason:     domain.CorrectionReasonForgotClockOut,
+		ClockOutAt: &clockOut,
+	}); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("expected conflict requesting a correction in a locked period, got %v", err)
+	}
+}
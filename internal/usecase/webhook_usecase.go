@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// WebhookSubscriptionInput registers a subscriber. No event types means every
+// webhook event type.
+type WebhookSubscriptionInput struct {
+	URL         string
+	Description string
+	EventTypes  []domain.EventType
+}
+
+// WebhookSubscriptionUpdate changes the fields that are not nil.
+type WebhookSubscriptionUpdate struct {
+	URL         *string
+	Description *string
+	EventTypes  *[]domain.EventType
+	Active      *bool
+}
+
+// WebhookUsecase manages webhook subscriptions and replays their deliveries.
+type WebhookUsecase struct {
+	webhooks repository.WebhookRepository
+	now      func() time.Time
+}
+
+// NewWebhookUsecase constructs a WebhookUsecase.
+func NewWebhookUsecase(webhooks repository.WebhookRepository) *WebhookUsecase {
+	return &WebhookUsecase{
+		webhooks: webhooks,
+		now:      time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (uc *WebhookUsecase) WithNow(now func() time.Time) {
+	if now != nil {
+		uc.now = now
+	}
+}
+
+// CreateSubscription registers an active subscriber with a new signing secret.
+// Only events recorded after this point are delivered to it.
+func (uc *WebhookUsecase) CreateSubscription(ctx context.Context, input WebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	target, err := webhookURL(input.URL)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	types, err := webhookEventTypes(input.EventTypes)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return uc.webhooks.CreateSubscription(ctx, domain.WebhookSubscription{
+		URL:         target,
+		Description: strings.TrimSpace(input.Description),
+		Secret:      secret,
+		EventTypes:  types,
+		Active:      true,
+	})
+}
+
+// ListSubscriptions returns every subscription.
+func (uc *WebhookUsecase) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return uc.webhooks.ListSubscriptions(ctx)
+}
+
+// GetSubscription returns one subscription.
+func (uc *WebhookUsecase) GetSubscription(ctx context.Context, subscriptionID string) (domain.WebhookSubscription, error) {
+	return uc.webhooks.GetSubscription(ctx, subscriptionID)
+}
+
+// UpdateSubscription changes a subscription. Deactivated subscriptions stop
+// receiving new events; deliveries already queued are still attempted.
+func (uc *WebhookUsecase) UpdateSubscription(ctx context.Context, subscriptionID string, update WebhookSubscriptionUpdate) (domain.WebhookSubscription, error) {
+	subscription, err := uc.webhooks.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if update.URL != nil {
+		if subscription.URL, err = webhookURL(*update.URL); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+	}
+	if update.Description != nil {
+		subscription.Description = strings.TrimSpace(*update.Description)
+	}
+	if update.EventTypes != nil {
+		if subscription.EventTypes, err = webhookEventTypes(*update.EventTypes); err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+	}
+	if update.Active != nil {
+		subscription.Active = *update.Active
+	}
+	if err := uc.webhooks.UpdateSubscription(ctx, subscription); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return uc.webhooks.GetSubscription(ctx, subscriptionID)
+}
+
+// ListDeliveries returns recent deliveries, newest first.
+func (uc *WebhookUsecase) ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, domain.ErrValidationFailure
+	}
+	switch {
+	case filter.Limit < 0:
+		return nil, domain.ErrValidationFailure
+	case filter.Limit == 0:
+		filter.Limit = defaultDeliveryLimit
+	case filter.Limit > maxDeliveryLimit:
+		filter.Limit = maxDeliveryLimit
+	}
+	return uc.webhooks.ListDeliveries(ctx, filter)
+}
+
+// ReplayDelivery sends a delivery again, whatever its status, with a fresh
+// set of attempts.
+func (uc *WebhookUsecase) ReplayDelivery(ctx context.Context, deliveryID string) (domain.WebhookDelivery, error) {
+	if err := uc.webhooks.Replay(ctx, deliveryID, uc.now()); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return uc.webhooks.GetDelivery(ctx, deliveryID)
+}
+
+// ReplayDeadLetters requeues every delivery of the subscription that ran out
+// of attempts and returns how many were requeued.
+func (uc *WebhookUsecase) ReplayDeadLetters(ctx context.Context, subscriptionID string) (int, error) {
+	if _, err := uc.webhooks.GetSubscription(ctx, subscriptionID); err != nil {
+		return 0, err
+	}
+	return uc.webhooks.ReplayDead(ctx, subscriptionID, uc.now())
+}
+
+// webhookURL accepts absolute http and https URLs.
+func webhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", domain.ErrValidationFailure
+	}
+	return raw, nil
+}
+
+// webhookEventTypes rejects unknown types and drops duplicates.
+func webhookEventTypes(types []domain.EventType) ([]domain.EventType, error) {
+	seen := map[domain.EventType]bool{}
+	out := make([]domain.EventType, 0, len(types))
+	for _, t := range types {
+		if !domain.IsWebhookEvent(t) {
+			return nil, domain.ErrValidationFailure
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func newWebhookSecret() (string, error) {
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw[:]), nil
+}
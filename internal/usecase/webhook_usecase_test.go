@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+)
+
+var _ repository.WebhookRepository = (*webhookRepoStub)(nil)
+
+// webhookRepoStub implements the subscription half of the repository; the
+// dispatcher methods are not used by the usecase.
+type webhookRepoStub struct {
+	repository.WebhookRepository
+	subscriptions map[string]domain.WebhookSubscription
+	filter        repository.WebhookDeliveryFilter
+	replayedAt    time.Time
+}
+
+func (r *webhookRepoStub) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if r.subscriptions == nil {
+		r.subscriptions = map[string]domain.WebhookSubscription{}
+	}
+	subscription.ID = fmt.Sprintf("sub-%d", len(r.subscriptions)+1)
+	r.subscriptions[subscription.ID] = subscription
+	return subscription, nil
+}
+
+func (r *webhookRepoStub) GetSubscription(ctx context.Context, subscriptionID string) (domain.WebhookSubscription, error) {
+	subscription, ok := r.subscriptions[subscriptionID]
+	if !ok {
+		return domain.WebhookSubscription{}, domain.ErrNotFound
+	}
+	return subscription, nil
+}
+
+func (r *webhookRepoStub) UpdateSubscription(ctx context.Context, subscription domain.WebhookSubscription) error {
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (r *webhookRepoStub) ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func (r *webhookRepoStub) ReplayDead(ctx context.Context, subscriptionID string, now time.Time) (int, error) {
+	r.replayedAt = now
+	return 2, nil
+}
+
+func TestWebhookUsecaseCreateSubscription(t *testing.T) {
+	repo := &webhookRepoStub{}
+	uc := NewWebhookUsecase(repo)
+
+	subscription, err := uc.CreateSubscription(context.Background(), WebhookSubscriptionInput{
+		URL:        " https://billing.example.com/hooks ",
+		EventTypes: []domain.EventType{domain.EventScheduleStatusChanged, domain.EventIncidentReported, domain.EventScheduleStatusChanged},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if subscription.URL != "https://billing.example.com/hooks" || !subscription.Active {
+		t.Fatalf("unexpected subscription %+v", subscription)
+	}
+	if len(subscription.EventTypes) != 2 {
+		t.Fatalf("expected duplicate event types to be dropped, got %v", subscription.EventTypes)
+	}
+	if !strings.HasPrefix(subscription.Secret, "whsec_") || len(subscription.Secret) != len("whsec_")+64 {
+		t.Fatalf("expected a generated secret, got %q", subscription.Secret)
+	}
+}
+
+func TestWebhookUsecaseCreateSubscriptionValidation(t *testing.T) {
+	uc := NewWebhookUsecase(&webhookRepoStub{})
+	cases := []WebhookSubscriptionInput{
+		{URL: "ftp://billing.example.com/hooks"},
+		{URL: "/hooks"},
+		{URL: "https://billing.example.com/hooks", EventTypes: []domain.EventType{"visit.deleted"}},
+		// task.created is streamed but not recorded in the outbox.
+		{URL: "https://billing.example.com/hooks", EventTypes: []domain.EventType{domain.EventTaskCreated}},
+	}
+	for _, input := range cases {
+		if _, err := uc.CreateSubscription(context.Background(), input); !errors.Is(err, domain.ErrValidationFailure) {
+			t.Fatalf("%+v: expected validation failure, got %v", input, err)
+		}
+	}
+}
+
+func TestWebhookUsecaseUpdateSubscription(t *testing.T) {
+	repo := &webhookRepoStub{}
+	uc := NewWebhookUsecase(repo)
+	created, err := uc.CreateSubscription(context.Background(), WebhookSubscriptionInput{URL: "https://payroll.example.com/hooks"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	inactive := false
+	types := []domain.EventType{domain.EventAttendanceLogged}
+	updated, err := uc.UpdateSubscription(context.Background(), created.ID, WebhookSubscriptionUpdate{Active: &inactive, EventTypes: &types})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Active || len(updated.EventTypes) != 1 || updated.URL != created.URL || updated.Secret != created.Secret {
+		t.Fatalf("unexpected update %+v", updated)
+	}
+
+	badURL := "not a url"
+	if _, err := uc.UpdateSubscription(context.Background(), created.ID, WebhookSubscriptionUpdate{URL: &badURL}); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure, got %v", err)
+	}
+	if _, err := uc.UpdateSubscription(context.Background(), "missing", WebhookSubscriptionUpdate{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestWebhookUsecaseDeliveriesAndReplay(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	repo := &webhookRepoStub{subscriptions: map[string]domain.WebhookSubscription{"sub-1": {ID: "sub-1"}}}
+	uc := NewWebhookUsecase(repo)
+	uc.WithNow(func() time.Time { return now })
+
+	if _, err := uc.ListDeliveries(context.Background(), repository.WebhookDeliveryFilter{Limit: 1000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.filter.Limit != maxDeliveryLimit {
+		t.Fatalf("expected limit capped at %d, got %d", maxDeliveryLimit, repo.filter.Limit)
+	}
+	if _, err := uc.ListDeliveries(context.Background(), repository.WebhookDeliveryFilter{Status: "failed"}); !errors.Is(err, domain.ErrValidationFailure) {
+		t.Fatalf("expected validation failure for unknown status, got %v", err)
+	}
+
+	replayed, err := uc.ReplayDeadLetters(context.Background(), "sub-1")
+	if err != nil || replayed != 2 || !repo.replayedAt.Equal(now) {
+		t.Fatalf("unexpected replay result %d, %v at %v", replayed, err, repo.replayedAt)
+	}
+	if _, err := uc.ReplayDeadLetters(context.Background(), "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
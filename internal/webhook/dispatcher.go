@@ -0,0 +1,224 @@
+// Package webhook delivers outbox events to subscribers as signed HTTP POSTs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Request headers sent with every delivery. Receivers should verify
+// SignatureHeader and use EventIDHeader to ignore repeated deliveries.
+const (
+	EventIDHeader    = "X-Webhook-Id"
+	DeliveryHeader   = "X-Webhook-Delivery"
+	EventTypeHeader  = "X-Webhook-Event"
+	TimestampHeader  = "X-Webhook-Timestamp"
+	SignatureHeader  = "X-Webhook-Signature"
+	signaturePrefix  = "sha256="
+	maxErrorLength   = 500
+	maxResponseBytes = 64 << 10
+)
+
+// Config controls how often and how persistently deliveries are attempted.
+type Config struct {
+	// Interval is the pause between polls of the outbox and retry queue.
+	Interval time.Duration
+	// Timeout bounds a single POST.
+	Timeout time.Duration
+	// MaxAttempts is how many attempts a delivery gets before it is dead.
+	MaxAttempts int
+	// BackoffBase is the wait after the first failure; it doubles after each
+	// further failure up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// BatchSize is how many events or deliveries are taken per query.
+	BatchSize int
+}
+
+// Dispatcher moves outbox events into per-subscriber deliveries and sends
+// them, retrying failures with exponential backoff. Several replicas may run
+// one each.
+type Dispatcher struct {
+	webhooks repository.WebhookRepository
+	cfg      Config
+	client   *http.Client
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewDispatcher constructs a dispatcher.
+func NewDispatcher(webhooks repository.WebhookRepository, cfg Config, logger *zap.Logger) *Dispatcher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &Dispatcher{
+		webhooks: webhooks,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// WithNow allows injecting a deterministic clock for testing.
+func (d *Dispatcher) WithNow(now func() time.Time) {
+	if now != nil {
+		d.now = now
+	}
+}
+
+// Run dispatches every Interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("webhook dispatch failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce queues deliveries for every undispatched outbox event, then sends
+// the deliveries that are due.
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	for {
+		queued, err := d.webhooks.QueueOutbox(ctx, d.cfg.BatchSize, d.now())
+		if err != nil {
+			return fmt.Errorf("queue outbox: %w", err)
+		}
+		if queued < d.cfg.BatchSize {
+			break
+		}
+	}
+
+	// Claimed deliveries are hidden from other replicas until the lease ends,
+	// which outlasts the time spent sending them.
+	lease := d.cfg.Timeout*time.Duration(d.cfg.BatchSize) + time.Minute
+	messages, err := d.webhooks.ClaimDue(ctx, d.now(), d.cfg.BatchSize, lease)
+	if err != nil {
+		return fmt.Errorf("claim deliveries: %w", err)
+	}
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := d.deliver(ctx, msg); err != nil {
+			d.logger.Error("record webhook delivery", zap.String("delivery_id", msg.DeliveryID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// deliver sends one message and records the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, msg domain.WebhookMessage) error {
+	status, err := d.post(ctx, msg)
+	now := d.now()
+	if err == nil {
+		return d.webhooks.MarkDelivered(ctx, msg.DeliveryID, status, now)
+	}
+
+	var responseStatus *int
+	if status != 0 {
+		responseStatus = &status
+	}
+	reason := err.Error()
+	if len(reason) > maxErrorLength {
+		reason = reason[:maxErrorLength]
+	}
+	var retryAt *time.Time
+	if attempts := msg.Attempts + 1; attempts < d.cfg.MaxAttempts {
+		next := now.Add(domain.WebhookBackoff(attempts, d.cfg.BackoffBase, d.cfg.BackoffMax))
+		retryAt = &next
+	} else {
+		d.logger.Warn("webhook delivery dead-lettered",
+			zap.String("delivery_id", msg.DeliveryID),
+			zap.String("url", msg.URL),
+			zap.Int("attempts", attempts),
+			zap.String("error", reason))
+	}
+	return d.webhooks.MarkFailed(ctx, msg.DeliveryID, responseStatus, reason, retryAt, now)
+}
+
+type payload struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	CaregiverID string          `json:"caregiver_id,omitempty"`
+	ScheduleID  *string         `json:"schedule_id,omitempty"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// post sends the message and returns the response status; a non-2xx status
+// is an error.
+func (d *Dispatcher) post(ctx context.Context, msg domain.WebhookMessage) (int, error) {
+	data := json.RawMessage(msg.Event.Payload)
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	body, err := json.Marshal(payload{
+		ID:          msg.Event.ID,
+		Type:        string(msg.Event.Type),
+		OccurredAt:  msg.Event.OccurredAt.UTC(),
+		CaregiverID: msg.Event.CaregiverID,
+		ScheduleID:  msg.Event.ScheduleID,
+		Data:        data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, msg.Event.ID)
+	req.Header.Set(DeliveryHeader, msg.DeliveryID)
+	req.Header.Set(EventTypeHeader, string(msg.Event.Type))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, signaturePrefix+Sign(msg.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret.
+// Receivers recompute it to check a delivery came from this service and was
+// not altered; the timestamp lets them reject old, replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/domain"
+	"github.com/edwaldo/test_blue_horn_tech/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+var _ repository.WebhookRepository = (*webhookRepoStub)(nil)
+
+type failure struct {
+	status  *int
+	reason  string
+	retryAt *time.Time
+}
+
+type webhookRepoStub struct {
+	repository.WebhookRepository
+	queueCalls int
+	due        []domain.WebhookMessage
+	delivered  map[string]int
+	failed     map[string]failure
+}
+
+func (s *webhookRepoStub) QueueOutbox(ctx context.Context, limit int, now time.Time) (int, error) {
+	s.queueCalls++
+	return 0, nil
+}
+
+func (s *webhookRepoStub) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookMessage, error) {
+	due := s.due
+	s.due = nil
+	return due, nil
+}
+
+func (s *webhookRepoStub) MarkDelivered(ctx context.Context, deliveryID string, responseStatus int, at time.Time) error {
+	s.delivered[deliveryID] = responseStatus
+	return nil
+}
+
+func (s *webhookRepoStub) MarkFailed(ctx context.Context, deliveryID string, responseStatus *int, reason string, retryAt *time.Time, at time.Time) error {
+	s.failed[deliveryID] = failure{status: responseStatus, reason: reason, retryAt: retryAt}
+	return nil
+}
+
+func newRepoStub(messages ...domain.WebhookMessage) *webhookRepoStub {
+	return &webhookRepoStub{due: messages, delivered: map[string]int{}, failed: map[string]failure{}}
+}
+
+func testConfig() Config {
+	return Config{Interval: time.Second, Timeout: time.Second, MaxAttempts: 3, BackoffBase: 30 * time.Second, BackoffMax: time.Hour}
+}
+
+func message(deliveryID, url string, attempts int) domain.WebhookMessage {
+	scheduleID := "sched-1"
+	return domain.WebhookMessage{
+		DeliveryID: deliveryID,
+		Attempts:   attempts,
+		URL:        url,
+		Secret:     "whsec_test",
+		Event: domain.OutboxEvent{
+			ID:          "event-1",
+			Type:        domain.EventScheduleStatusChanged,
+			CaregiverID: "cg-1",
+			ScheduleID:  &scheduleID,
+			Payload:     []byte(`{"status":"completed"}`),
+			OccurredAt:  time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestDispatcherSignsAndDelivers(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 5, 0, time.UTC)
+	var received struct {
+		ID   string          `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if r.Header.Get(SignatureHeader) != "sha256="+Sign("whsec_test", timestamp, body) {
+			t.Errorf("signature %q does not match body", r.Header.Get(SignatureHeader))
+		}
+		if timestamp != now.Unix() || r.Header.Get(EventIDHeader) != "event-1" || r.Header.Get(DeliveryHeader) != "delivery-1" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := newRepoStub(message("delivery-1", server.URL, 0))
+	dispatcher := NewDispatcher(repo, testConfig(), zap.NewNop())
+	dispatcher.WithNow(func() time.Time { return now })
+
+	if err := dispatcher.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.queueCalls != 1 {
+		t.Fatalf("expected the outbox to be queued once, got %d", repo.queueCalls)
+	}
+	if repo.delivered["delivery-1"] != http.StatusNoContent {
+		t.Fatalf("expected delivery to be marked delivered, got %v / %v", repo.delivered, repo.failed)
+	}
+	if received.ID != "event-1" || received.Type != "schedule.status_changed" || string(received.Data) != `{"status":"completed"}` {
+		t.Fatalf("unexpected payload %+v", received)
+	}
+}
+
+func TestDispatcherRetriesWithBackoffThenDeadLetters(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := newRepoStub(
+		message("first-failure", server.URL, 0),
+		message("second-failure", server.URL, 1),
+		message("last-attempt", server.URL, 2),
+	)
+	dispatcher := NewDispatcher(repo, testConfig(), zap.NewNop())
+	dispatcher.WithNow(func() time.Time { return now })
+
+	if err := dispatcher.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]*time.Time{
+		"first-failure":  ptrTime(now.Add(30 * time.Second)),
+		"second-failure": ptrTime(now.Add(time.Minute)),
+		"last-attempt":   nil,
+	}
+	for id, want := range cases {
+		got, ok := repo.failed[id]
+		if !ok {
+			t.Fatalf("%s: expected a failed attempt", id)
+		}
+		if got.status == nil || *got.status != http.StatusServiceUnavailable {
+			t.Fatalf("%s: expected response status 503, got %v", id, got.status)
+		}
+		if (want == nil) != (got.retryAt == nil) || (want != nil && !want.Equal(*got.retryAt)) {
+			t.Fatalf("%s: expected retry at %v, got %v", id, want, got.retryAt)
+		}
+	}
+}
+
+func TestDispatcherRecordsConnectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	repo := newRepoStub(message("delivery-1", url, 0))
+	dispatcher := NewDispatcher(repo, testConfig(), zap.NewNop())
+	if err := dispatcher.RunOnce(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := repo.failed["delivery-1"]
+	if got.status != nil || got.reason == "" || got.retryAt == nil {
+		t.Fatalf("expected a retryable failure without status, got %+v", got)
+	}
+}
+
+func TestWebhookBackoffIsCapped(t *testing.T) {
+	if got := domain.WebhookBackoff(1, time.Minute, time.Hour); got != time.Minute {
+		t.Fatalf("expected first retry after a minute, got %v", got)
+	}
+	if got := domain.WebhookBackoff(4, time.Minute, time.Hour); got != 8*time.Minute {
+		t.Fatalf("expected fourth retry after 8 minutes, got %v", got)
+	}
+	if got := domain.WebhookBackoff(20, time.Minute, time.Hour); got != time.Hour {
+		t.Fatalf("expected backoff capped at an hour, got %v", got)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,33 @@
+-- +migrate Up
+ALTER TABLE schedules ALTER COLUMN caregiver_id DROP NOT NULL;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS required_qualifications TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[];
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMPTZ;
+
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS home_latitude DOUBLE PRECISION;
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS home_longitude DOUBLE PRECISION;
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS qualifications TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[];
+
+CREATE INDEX IF NOT EXISTS idx_schedules_open_start ON schedules (start_time)
+    WHERE caregiver_id IS NULL AND status = 'scheduled';
+
+UPDATE caregivers
+SET home_latitude = 44.9778,
+    home_longitude = -93.2650,
+    qualifications = ARRAY['personal_care','medication']
+WHERE id = 'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2';
+
+INSERT INTO schedules (id, caregiver_id, client_id, service_name, location_label, start_time, end_time, status, required_qualifications, notes)
+VALUES
+    ('5e0c9a43-2b7f-4c1e-8d3a-6f9b1c2d3e4f', NULL, '4f1bbd73-df5e-4f3a-a59c-2d1fe15f0aaf',
+     'Home Care Service', 'Casa Grande Apartment', NOW() + INTERVAL '1 day', NOW() + INTERVAL '1 day 2 hour',
+     'scheduled', ARRAY['personal_care'], 'Uncovered visit, original caregiver on leave.');
+
+-- +migrate Down
+DELETE FROM schedules WHERE caregiver_id IS NULL;
+DROP INDEX IF EXISTS idx_schedules_open_start;
+ALTER TABLE caregivers DROP COLUMN IF EXISTS qualifications;
+ALTER TABLE caregivers DROP COLUMN IF EXISTS home_longitude;
+ALTER TABLE caregivers DROP COLUMN IF EXISTS home_latitude;
+ALTER TABLE schedules DROP COLUMN IF EXISTS claimed_at;
+ALTER TABLE schedules DROP COLUMN IF EXISTS required_qualifications;
+ALTER TABLE schedules ALTER COLUMN caregiver_id SET NOT NULL;
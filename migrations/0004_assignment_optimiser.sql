@@ -0,0 +1,57 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS caregiver_availability (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    caregiver_id UUID NOT NULL REFERENCES caregivers(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_minute INT NOT NULL CHECK (start_minute BETWEEN 0 AND 1440),
+    end_minute INT NOT NULL CHECK (end_minute BETWEEN 0 AND 1440),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (start_minute < end_minute)
+);
+
+CREATE INDEX IF NOT EXISTS idx_caregiver_availability_caregiver ON caregiver_availability (caregiver_id);
+
+CREATE TABLE IF NOT EXISTS assignment_proposals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    range_from TIMESTAMPTZ NOT NULL,
+    range_to TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('draft','applied')),
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    applied_at TIMESTAMPTZ
+);
+
+-- caregiver_id is NULL for visits the optimiser could not assign.
+CREATE TABLE IF NOT EXISTS assignment_proposal_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    proposal_id UUID NOT NULL REFERENCES assignment_proposals(id) ON DELETE CASCADE,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    caregiver_id UUID REFERENCES caregivers(id),
+    start_time TIMESTAMPTZ NOT NULL,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    travel_km DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reasons TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[]
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_proposal_items_proposal ON assignment_proposal_items (proposal_id);
+CREATE INDEX IF NOT EXISTS idx_schedules_client_caregiver ON schedules (client_id, caregiver_id) WHERE status = 'completed';
+
+-- Default weekday availability 08:00-18:00 for the seeded caregiver.
+INSERT INTO caregiver_availability (caregiver_id, weekday, start_minute, end_minute)
+SELECT 'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2', d, 480, 1080
+FROM generate_series(1, 5) AS d;
+
+-- Coordinator console client; uses the same demo secret as caregiver-app.
+INSERT INTO auth_clients (id, secret_hash, description, caregiver_id, scopes)
+SELECT 'coordinator-console', secret_hash, 'Coordinator console client', caregiver_id,
+       ARRAY['schedules.read','assignments.write']
+FROM auth_clients
+WHERE id = 'caregiver-app'
+ON CONFLICT (id) DO NOTHING;
+
+-- +migrate Down
+DELETE FROM auth_clients WHERE id = 'coordinator-console';
+DROP INDEX IF EXISTS idx_schedules_client_caregiver;
+DROP TABLE IF EXISTS assignment_proposal_items;
+DROP TABLE IF EXISTS assignment_proposals;
+DROP TABLE IF EXISTS caregiver_availability;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Visits with a flexible window may start up to this many minutes before or after start_time.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS flexible_window_mins INT NOT NULL DEFAULT 0 CHECK (flexible_window_mins >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_caregiver_start ON schedules (caregiver_id, start_time);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_schedules_caregiver_start;
+ALTER TABLE schedules DROP COLUMN IF EXISTS flexible_window_mins;
@@ -0,0 +1,44 @@
+-- +migrate Up
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS medicaid_id TEXT;
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS employee_id TEXT;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS service_code TEXT;
+
+CREATE TABLE IF NOT EXISTS evv_batches (
+    id UUID PRIMARY KEY,
+    range_from TIMESTAMPTZ NOT NULL,
+    range_to TIMESTAMPTZ NOT NULL,
+    format TEXT NOT NULL CHECK (format IN ('csv','json')),
+    record_count INT NOT NULL DEFAULT 0,
+    rejected_count INT NOT NULL DEFAULT 0,
+    payload BYTEA NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One row per visit per batch; the latest row for a visit is its current submission.
+CREATE TABLE IF NOT EXISTS evv_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    batch_id UUID NOT NULL REFERENCES evv_batches(id) ON DELETE CASCADE,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    action TEXT NOT NULL CHECK (action IN ('create','update')),
+    visit_hash TEXT NOT NULL,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_evv_submissions_schedule ON evv_submissions (schedule_id, submitted_at DESC);
+
+UPDATE clients SET medicaid_id = 'MN000123456' WHERE id = '4f1bbd73-df5e-4f3a-a59c-2d1fe15f0aaf';
+UPDATE caregivers SET employee_id = 'EMP-0001' WHERE id = 'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2';
+UPDATE schedules SET service_code = 'T1019' WHERE service_code IS NULL;
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'evv.export')
+WHERE id = 'coordinator-console' AND NOT ('evv.export' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'evv.export') WHERE id = 'coordinator-console';
+DROP TABLE IF EXISTS evv_submissions;
+DROP TABLE IF EXISTS evv_batches;
+ALTER TABLE schedules DROP COLUMN IF EXISTS service_code;
+ALTER TABLE caregivers DROP COLUMN IF EXISTS employee_id;
+ALTER TABLE clients DROP COLUMN IF EXISTS medicaid_id;
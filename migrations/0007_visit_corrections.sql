@@ -0,0 +1,36 @@
+-- +migrate Up
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS manually_edited BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS visit_corrections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL,
+    reason_code TEXT NOT NULL CHECK (reason_code IN ('forgot_clock_in','forgot_clock_out','device_issue','wrong_time','other')),
+    comment TEXT,
+    proposed_clock_in_at TIMESTAMPTZ,
+    proposed_clock_out_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','approved','rejected')),
+    -- Snapshot of the visit taken when the correction is approved.
+    original_clock_in_at TIMESTAMPTZ,
+    original_clock_out_at TIMESTAMPTZ,
+    original_status TEXT,
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    review_comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (proposed_clock_in_at IS NOT NULL OR proposed_clock_out_at IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_visit_corrections_schedule ON visit_corrections (schedule_id, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_visit_corrections_one_pending
+    ON visit_corrections (schedule_id)
+    WHERE status = 'pending';
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'corrections.review')
+WHERE id = 'coordinator-console' AND NOT ('corrections.review' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'corrections.review') WHERE id = 'coordinator-console';
+DROP TABLE IF EXISTS visit_corrections;
+ALTER TABLE schedules DROP COLUMN IF EXISTS manually_edited;
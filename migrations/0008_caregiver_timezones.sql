@@ -0,0 +1,8 @@
+-- +migrate Up
+-- IANA zone name (e.g. 'America/Chicago'); NULL falls back to APP_TIMEZONE.
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS timezone TEXT;
+
+UPDATE caregivers SET timezone = 'America/Chicago' WHERE id = 'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2';
+
+-- +migrate Down
+ALTER TABLE caregivers DROP COLUMN IF EXISTS timezone;
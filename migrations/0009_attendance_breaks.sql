@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE logs_caregivers ALTER COLUMN log_type TYPE VARCHAR(20);
+ALTER TABLE logs_caregivers DROP CONSTRAINT IF EXISTS logs_caregivers_log_type_check;
+ALTER TABLE logs_caregivers ADD CONSTRAINT logs_caregivers_log_type_check
+    CHECK (log_type IN ('clock_in', 'clock_out', 'break_start', 'break_end'));
+
+-- Only break_start rows carry the kind of break.
+ALTER TABLE logs_caregivers ADD COLUMN IF NOT EXISTS break_type TEXT
+    CHECK (break_type IN ('meal', 'rest'));
+
+-- +migrate Down
+DELETE FROM logs_caregivers WHERE log_type IN ('break_start', 'break_end');
+ALTER TABLE logs_caregivers DROP COLUMN IF EXISTS break_type;
+ALTER TABLE logs_caregivers DROP CONSTRAINT IF EXISTS logs_caregivers_log_type_check;
+ALTER TABLE logs_caregivers ADD CONSTRAINT logs_caregivers_log_type_check
+    CHECK (log_type IN ('clock_in', 'clock_out'));
+ALTER TABLE logs_caregivers ALTER COLUMN log_type TYPE VARCHAR(10);
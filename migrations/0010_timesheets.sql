@@ -0,0 +1,88 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS timesheets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    caregiver_id UUID NOT NULL REFERENCES caregivers(id) ON DELETE CASCADE,
+    period TEXT NOT NULL CHECK (period IN ('weekly','biweekly')),
+    -- Instants bounding the period in the caregiver's timezone; period_end is exclusive.
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted','approved','rejected','locked')),
+    worked_minutes INTEGER NOT NULL DEFAULT 0,
+    break_minutes INTEGER NOT NULL DEFAULT 0,
+    visit_minutes INTEGER NOT NULL DEFAULT 0,
+    regular_minutes INTEGER NOT NULL DEFAULT 0,
+    overtime_minutes INTEGER NOT NULL DEFAULT 0,
+    completed_visits INTEGER NOT NULL DEFAULT 0,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    review_comment TEXT,
+    locked_by UUID,
+    locked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (caregiver_id, period_start),
+    CHECK (period_end > period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_timesheets_status ON timesheets (status, period_start);
+
+CREATE TABLE IF NOT EXISTS timesheet_days (
+    timesheet_id UUID NOT NULL REFERENCES timesheets(id) ON DELETE CASCADE,
+    work_date DATE NOT NULL,
+    worked_minutes INTEGER NOT NULL,
+    break_minutes INTEGER NOT NULL,
+    visit_minutes INTEGER NOT NULL,
+    regular_minutes INTEGER NOT NULL,
+    overtime_minutes INTEGER NOT NULL,
+    completed_visits INTEGER NOT NULL,
+    PRIMARY KEY (timesheet_id, work_date)
+);
+
+-- Locked timesheets are immutable, including their days.
+CREATE OR REPLACE FUNCTION timesheets_reject_locked() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD.status = 'locked' THEN
+        RAISE EXCEPTION 'timesheet % is locked', OLD.id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION timesheet_days_reject_locked() RETURNS TRIGGER AS $$
+DECLARE
+    sheet UUID := COALESCE(NEW.timesheet_id, OLD.timesheet_id);
+BEGIN
+    IF EXISTS (SELECT 1 FROM timesheets WHERE id = sheet AND status = 'locked') THEN
+        RAISE EXCEPTION 'timesheet % is locked', sheet;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_timesheets_locked ON timesheets;
+CREATE TRIGGER trg_timesheets_locked
+    BEFORE UPDATE OR DELETE ON timesheets
+    FOR EACH ROW EXECUTE FUNCTION timesheets_reject_locked();
+
+DROP TRIGGER IF EXISTS trg_timesheet_days_locked ON timesheet_days;
+CREATE TRIGGER trg_timesheet_days_locked
+    BEFORE INSERT OR UPDATE OR DELETE ON timesheet_days
+    FOR EACH ROW EXECUTE FUNCTION timesheet_days_reject_locked();
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'timesheets.approve')
+WHERE id = 'coordinator-console' AND NOT ('timesheets.approve' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'timesheets.approve') WHERE id = 'coordinator-console';
+DROP TABLE IF EXISTS timesheet_days;
+DROP TABLE IF EXISTS timesheets;
+DROP FUNCTION IF EXISTS timesheet_days_reject_locked();
+DROP FUNCTION IF EXISTS timesheets_reject_locked();
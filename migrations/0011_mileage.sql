@@ -0,0 +1,44 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS mileage_claims (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    caregiver_id UUID NOT NULL REFERENCES caregivers(id) ON DELETE CASCADE,
+    -- Calendar day of the claimed travel in the caregiver's timezone.
+    claim_date DATE NOT NULL,
+    computed_km NUMERIC(8,2) NOT NULL,
+    claimed_km NUMERIC(8,2) NOT NULL CHECK (claimed_km >= 0),
+    adjustment_reason TEXT,
+    rate_per_km NUMERIC(6,3) NOT NULL,
+    amount NUMERIC(10,2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted','approved','rejected')),
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_by UUID,
+    reviewed_at TIMESTAMPTZ,
+    review_comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (caregiver_id, claim_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mileage_claims_status ON mileage_claims (status, claim_date);
+
+-- Travel legs between consecutive visits as computed when the claim was submitted.
+CREATE TABLE IF NOT EXISTS mileage_legs (
+    claim_id UUID NOT NULL REFERENCES mileage_claims(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    from_schedule_id UUID NOT NULL REFERENCES schedules(id),
+    to_schedule_id UUID NOT NULL REFERENCES schedules(id),
+    departed_at TIMESTAMPTZ,
+    arrived_at TIMESTAMPTZ NOT NULL,
+    distance_km NUMERIC(8,2) NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('gps','client')),
+    PRIMARY KEY (claim_id, position)
+);
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'mileage.approve')
+WHERE id = 'coordinator-console' AND NOT ('mileage.approve' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'mileage.approve') WHERE id = 'coordinator-console';
+DROP TABLE IF EXISTS mileage_legs;
+DROP TABLE IF EXISTS mileage_claims;
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- Free-text search matches client names and location labels with ILIKE '%term%',
+-- which only trigram indexes can serve.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_clients_full_name_trgm ON clients USING gin (full_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_schedules_location_label_trgm ON schedules USING gin (location_label gin_trgm_ops);
+
+-- Client and service filters within a caregiver's visits.
+CREATE INDEX IF NOT EXISTS idx_schedules_caregiver_client_start ON schedules (caregiver_id, client_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_schedules_caregiver_service ON schedules (caregiver_id, LOWER(service_name));
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_schedules_caregiver_service;
+DROP INDEX IF EXISTS idx_schedules_caregiver_client_start;
+DROP INDEX IF EXISTS idx_schedules_location_label_trgm;
+DROP INDEX IF EXISTS idx_clients_full_name_trgm;
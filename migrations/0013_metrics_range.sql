@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Range metrics count each visit's tasks by status.
+CREATE INDEX IF NOT EXISTS idx_schedule_tasks_schedule_status ON schedule_tasks (schedule_id, status);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_schedule_tasks_schedule_status;
@@ -0,0 +1,43 @@
+-- +migrate Up
+-- Supervisors filter the agency dashboard by the region and team a caregiver works in.
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS region TEXT;
+ALTER TABLE caregivers ADD COLUMN IF NOT EXISTS team TEXT;
+CREATE INDEX IF NOT EXISTS idx_caregivers_region_team ON caregivers (region, team);
+
+CREATE TABLE IF NOT EXISTS incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    caregiver_id UUID NOT NULL REFERENCES caregivers(id) ON DELETE CASCADE,
+    schedule_id UUID REFERENCES schedules(id) ON DELETE SET NULL,
+    category TEXT NOT NULL,
+    severity TEXT NOT NULL CHECK (severity IN ('low','medium','high')),
+    description TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','resolved')),
+    reported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_by UUID,
+    resolved_at TIMESTAMPTZ,
+    resolution TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_open ON incidents (reported_at) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_schedules_start_time ON schedules (start_time);
+
+UPDATE caregivers SET region = 'Minneapolis', team = 'North'
+WHERE id = 'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2' AND region IS NULL;
+
+-- Supervisor console client; uses the same demo secret as caregiver-app.
+INSERT INTO auth_clients (id, secret_hash, description, caregiver_id, scopes)
+SELECT 'supervisor-console', secret_hash, 'Supervisor operations dashboard client', caregiver_id,
+       ARRAY['schedules.read','supervisor']
+FROM auth_clients
+WHERE id = 'caregiver-app'
+ON CONFLICT (id) DO NOTHING;
+
+-- +migrate Down
+DELETE FROM auth_clients WHERE id = 'supervisor-console';
+DROP INDEX IF EXISTS idx_schedules_start_time;
+DROP TABLE IF EXISTS incidents;
+DROP INDEX IF EXISTS idx_caregivers_region_team;
+ALTER TABLE caregivers DROP COLUMN IF EXISTS team;
+ALTER TABLE caregivers DROP COLUMN IF EXISTS region;
@@ -0,0 +1,40 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    caregiver_id UUID PRIMARY KEY REFERENCES caregivers(id) ON DELETE CASCADE,
+    channels TEXT[] NOT NULL DEFAULT ARRAY['push','sms','email']::TEXT[],
+    disabled_kinds TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    phone TEXT,
+    push_token TEXT,
+    email TEXT,
+    -- Minutes after midnight in the caregiver's timezone.
+    quiet_start SMALLINT CHECK (quiet_start BETWEEN 0 AND 1439),
+    quiet_end SMALLINT CHECK (quiet_end BETWEEN 0 AND 1439),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((quiet_start IS NULL) = (quiet_end IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS notification_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    caregiver_id UUID NOT NULL REFERENCES caregivers(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('visit_reminder','visit_assigned','clock_out_reminder')),
+    channel TEXT NOT NULL CHECK (channel IN ('push','sms','email')),
+    dedupe_key TEXT NOT NULL,
+    schedule_id UUID REFERENCES schedules(id) ON DELETE SET NULL,
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending','sent','failed','suppressed')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ,
+    -- One notification per reason and channel, however many replicas notice it.
+    UNIQUE (caregiver_id, kind, channel, dedupe_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_log_caregiver ON notification_log (caregiver_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_schedules_in_progress_end ON schedules (end_time) WHERE status = 'in_progress';
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_schedules_in_progress_end;
+DROP TABLE IF EXISTS notification_log;
+DROP TABLE IF EXISTS notification_preferences;
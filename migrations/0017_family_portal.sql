@@ -0,0 +1,38 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS family_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    relationship TEXT NOT NULL DEFAULT '',
+    secret_hash TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Family members sign in with their email, so it identifies one person.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_family_members_email ON family_members (lower(email));
+CREATE INDEX IF NOT EXISTS idx_family_members_client ON family_members (client_id);
+
+-- A missing row means nothing is shared.
+CREATE TABLE IF NOT EXISTS client_family_consent (
+    client_id UUID PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    share_visits BOOLEAN NOT NULL DEFAULT FALSE,
+    share_tasks BOOLEAN NOT NULL DEFAULT FALSE,
+    share_confirmations BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by UUID REFERENCES caregivers(id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_client_start ON schedules (client_id, start_time);
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'family.manage')
+WHERE id = 'coordinator-console' AND NOT ('family.manage' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'family.manage') WHERE id = 'coordinator-console';
+DROP INDEX IF EXISTS idx_schedules_client_start;
+DROP TABLE IF EXISTS client_family_consent;
+DROP TABLE IF EXISTS family_members;
@@ -0,0 +1,31 @@
+-- +migrate Up
+-- A missing row means acknowledgement is optional and no PIN is set.
+CREATE TABLE IF NOT EXISTS client_signoff_settings (
+    client_id UUID PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    pin_hash TEXT,
+    updated_by UUID REFERENCES caregivers(id),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The summary is kept verbatim so summary_hash can be recomputed from it.
+CREATE TABLE IF NOT EXISTS visit_signoffs (
+    schedule_id UUID PRIMARY KEY REFERENCES schedules(id) ON DELETE CASCADE,
+    method TEXT NOT NULL CHECK (method IN ('signature', 'pin')),
+    signer_name TEXT NOT NULL,
+    signer_role TEXT NOT NULL CHECK (signer_role IN ('client', 'representative')),
+    signature_format TEXT CHECK (signature_format IN ('svg', 'png')),
+    signature BYTEA,
+    summary TEXT NOT NULL,
+    summary_hash TEXT NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL
+);
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'signoffs.manage')
+WHERE id = 'coordinator-console' AND NOT ('signoffs.manage' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'signoffs.manage') WHERE id = 'coordinator-console';
+DROP TABLE IF EXISTS visit_signoffs;
+DROP TABLE IF EXISTS client_signoff_settings;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- Files live in the blob store; rows only point at them.
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    task_id UUID REFERENCES schedule_tasks(id) ON DELETE CASCADE,
+    uploaded_by UUID NOT NULL REFERENCES caregivers(id),
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT,
+    caption TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_schedule ON attachments (schedule_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS attachments;
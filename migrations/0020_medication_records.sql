@@ -0,0 +1,79 @@
+-- +migrate Up
+-- Times are "HH:MM" in the agency timezone; PRN orders have none.
+CREATE TABLE IF NOT EXISTS medication_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    drug TEXT NOT NULL,
+    dose TEXT NOT NULL,
+    route TEXT NOT NULL CHECK (route IN ('oral', 'sublingual', 'topical', 'transdermal', 'inhaled', 'nasal', 'ophthalmic', 'otic', 'rectal', 'injection')),
+    times TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+    prn BOOLEAN NOT NULL DEFAULT FALSE,
+    instructions TEXT,
+    start_date DATE NOT NULL,
+    end_date DATE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID REFERENCES caregivers(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (prn = (cardinality(times) = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_medication_orders_client ON medication_orders (client_id);
+
+-- Scheduled doses are unique per order, visit and due time; PRN doses have no
+-- due time and may be given several times.
+CREATE TABLE IF NOT EXISTS medication_administrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES medication_orders(id) ON DELETE CASCADE,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    due_at TIMESTAMPTZ,
+    status TEXT NOT NULL CHECK (status IN ('due', 'given', 'refused', 'omitted', 'missed')),
+    reason TEXT,
+    administered_at TIMESTAMPTZ,
+    recorded_by UUID REFERENCES caregivers(id),
+    recorded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, schedule_id, due_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_medication_administrations_schedule ON medication_administrations (schedule_id);
+CREATE INDEX IF NOT EXISTS idx_medication_administrations_due ON medication_administrations (schedule_id) WHERE status = 'due';
+
+-- A task standing for a scheduled dose is recorded through the MAR.
+ALTER TABLE schedule_tasks ADD COLUMN IF NOT EXISTS medication_administration_id UUID
+    REFERENCES medication_administrations(id) ON DELETE SET NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_schedule_tasks_medication ON schedule_tasks (medication_administration_id)
+    WHERE medication_administration_id IS NOT NULL;
+
+INSERT INTO medication_orders (id, client_id, drug, dose, route, times, prn, instructions, start_date)
+VALUES
+    ('6a0f3c2e-8b1d-4e57-9c3a-2f7e1d5b8a40', '4f1bbd73-df5e-4f3a-a59c-2d1fe15f0aaf', 'Lisinopril', '10 mg', 'oral',
+     ARRAY[to_char(NOW(), 'HH24:MI')], FALSE, 'Take with water.', CURRENT_DATE - 30),
+    ('c3e9a7d1-5f2b-4a86-b0d4-7e1c9f3a6b25', '4f1bbd73-df5e-4f3a-a59c-2d1fe15f0aaf', 'Paracetamol', '500 mg', 'oral',
+     ARRAY[]::TEXT[], TRUE, 'For pain, at most 4 doses in 24 hours.', CURRENT_DATE - 30)
+ON CONFLICT (id) DO NOTHING;
+
+-- The seeded in-progress visit's generic medication task becomes a MAR entry.
+INSERT INTO medication_administrations (id, order_id, schedule_id, due_at, status)
+VALUES ('e81d4c6b-2a9f-4f03-8d75-b6c2e0a19f37', '6a0f3c2e-8b1d-4e57-9c3a-2f7e1d5b8a40',
+        '9b8c7d6e-5f4a-3210-9876-543210fedcba', date_trunc('minute', NOW()), 'due')
+ON CONFLICT DO NOTHING;
+
+UPDATE schedule_tasks
+SET medication_administration_id = 'e81d4c6b-2a9f-4f03-8d75-b6c2e0a19f37',
+    title = 'Lisinopril 10 mg (oral)',
+    description = 'Take with water.'
+WHERE schedule_id = '9b8c7d6e-5f4a-3210-9876-543210fedcba' AND title = 'Medication Administration';
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'medications.manage')
+WHERE id = 'coordinator-console' AND NOT ('medications.manage' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'medications.manage') WHERE id = 'coordinator-console';
+UPDATE schedule_tasks SET title = 'Medication Administration', description = 'Administer morning medications as prescribed.'
+WHERE medication_administration_id = 'e81d4c6b-2a9f-4f03-8d75-b6c2e0a19f37';
+DROP INDEX IF EXISTS idx_schedule_tasks_medication;
+ALTER TABLE schedule_tasks DROP COLUMN IF EXISTS medication_administration_id;
+DROP TABLE IF EXISTS medication_administrations;
+DROP TABLE IF EXISTS medication_orders;
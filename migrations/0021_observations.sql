@@ -0,0 +1,38 @@
+-- +migrate Up
+-- Structured readings taken during visits, in the canonical unit of their
+-- type. Diastolic is set for blood pressure only; flag marks readings outside
+-- the normal range.
+CREATE TABLE IF NOT EXISTS observations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    caregiver_id UUID NOT NULL REFERENCES caregivers(id),
+    type TEXT NOT NULL CHECK (type IN ('blood_pressure', 'heart_rate', 'temperature', 'oxygen_saturation', 'weight', 'mood')),
+    value DOUBLE PRECISION NOT NULL,
+    diastolic DOUBLE PRECISION,
+    unit TEXT NOT NULL,
+    note TEXT,
+    flag TEXT CHECK (flag IN ('low', 'high')),
+    recorded_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((type = 'blood_pressure') = (diastolic IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_observations_client ON observations (client_id, recorded_at);
+CREATE INDEX IF NOT EXISTS idx_observations_schedule ON observations (schedule_id);
+
+INSERT INTO observations (id, schedule_id, client_id, caregiver_id, type, value, diastolic, unit, flag, recorded_at)
+VALUES
+    ('0b7e2d94-6c1a-4f38-a5e2-9d3c8b1f7a60', 'a8a6d494-3b35-4536-b4f4-8023c2f13914', '4f1bbd73-df5e-4f3a-a59c-2d1fe15f0aaf',
+     'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2', 'blood_pressure', 146, 88, 'mmHg', 'high', NOW() - INTERVAL '1 day'),
+    ('5d2a9f17-3e8b-4c60-b1d7-2a6f4e9c0b38', 'a8a6d494-3b35-4536-b4f4-8023c2f13914', '4f1bbd73-df5e-4f3a-a59c-2d1fe15f0aaf',
+     'c2d1bb61-8d67-4db5-9e59-4c2c16f7d4f2', 'temperature', 36.8, NULL, 'C', NULL, NOW() - INTERVAL '1 day')
+ON CONFLICT (id) DO NOTHING;
+
+UPDATE auth_clients
+SET scopes = array_append(scopes, 'observations.read')
+WHERE id = 'coordinator-console' AND NOT ('observations.read' = ANY(scopes));
+
+-- +migrate Down
+UPDATE auth_clients SET scopes = array_remove(scopes, 'observations.read') WHERE id = 'coordinator-console';
+DROP TABLE IF EXISTS observations;
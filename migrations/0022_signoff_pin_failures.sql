@@ -0,0 +1,19 @@
+-- +migrate Up
+-- Wrong PINs count against the PIN set at pin_set_at; setting a new PIN
+-- unlocks sign-off for the client.
+ALTER TABLE client_signoff_settings ADD COLUMN IF NOT EXISTS pin_set_at TIMESTAMPTZ;
+UPDATE client_signoff_settings SET pin_set_at = updated_at WHERE pin_hash IS NOT NULL AND pin_set_at IS NULL;
+
+-- Every wrong PIN entered at clock-out, kept for audit.
+CREATE TABLE IF NOT EXISTS signoff_pin_failures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    caregiver_id UUID REFERENCES caregivers(id),
+    attempted_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_signoff_pin_failures_client ON signoff_pin_failures (client_id, attempted_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS signoff_pin_failures;
+ALTER TABLE client_signoff_settings DROP COLUMN IF EXISTS pin_set_at;